@@ -1,12 +1,20 @@
 package main
 
 import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"maps"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -14,18 +22,41 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	zone "github.com/lrstanley/bubblezone"
 
+	"github.com/mikanfactory/yakumo/internal/agent"
+	"github.com/mikanfactory/yakumo/internal/audit"
 	"github.com/mikanfactory/yakumo/internal/branchname"
+	"github.com/mikanfactory/yakumo/internal/buildinfo"
 	"github.com/mikanfactory/yakumo/internal/claude"
+	"github.com/mikanfactory/yakumo/internal/clipboard"
 	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/devlog"
 	"github.com/mikanfactory/yakumo/internal/diffui"
+	"github.com/mikanfactory/yakumo/internal/doctor"
+	"github.com/mikanfactory/yakumo/internal/forge"
 	"github.com/mikanfactory/yakumo/internal/git"
 	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/gitlab"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/matrix"
 	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/notify"
+	"github.com/mikanfactory/yakumo/internal/ports"
+	"github.com/mikanfactory/yakumo/internal/prune"
+	"github.com/mikanfactory/yakumo/internal/release"
 	"github.com/mikanfactory/yakumo/internal/rename"
+	"github.com/mikanfactory/yakumo/internal/restore"
+	"github.com/mikanfactory/yakumo/internal/search"
 	"github.com/mikanfactory/yakumo/internal/setupspinner"
+	"github.com/mikanfactory/yakumo/internal/state"
+	"github.com/mikanfactory/yakumo/internal/statusline"
+	"github.com/mikanfactory/yakumo/internal/theme"
 	"github.com/mikanfactory/yakumo/internal/timeparse"
 	"github.com/mikanfactory/yakumo/internal/tmux"
+	"github.com/mikanfactory/yakumo/internal/trash"
 	"github.com/mikanfactory/yakumo/internal/tui"
+	"github.com/mikanfactory/yakumo/internal/tutorial"
+	"github.com/mikanfactory/yakumo/internal/wip"
 )
 
 const usage = `Usage: yakumo [command]
@@ -33,29 +64,85 @@ const usage = `Usage: yakumo [command]
 Commands:
   (default)         Launch worktree UI
   diff-ui           Launch diff/PR review UI
+  grep <pattern>    Search all worktrees of the current repository (--all: every repository)
+  exec -- <cmd>     Run a command in every worktree of a repository (--all-worktrees, --repo, --jobs N)
+  list              List the worktrees of every configured repository (--json for JSON)
+  add <repo>        Create a worktree of a repository and print its path (--from-url: for a PR, branch or issue URL)
+  archive <path>    Kill the worktree's session and move it to the trash
+  open <path>       Create or switch to the worktree's session, attaching outside tmux
+  popup             Open the worktree UI in a tmux popup that closes on selection (--width, --height)
   swap-center       Swap center pane with background
   swap-right-below  Swap right-below pane with background
   watch-rename      Watch for Claude prompt and rename branch
+  devlog-write <f>  Append stdin to a rotating dev-server log (run by tmux pipe-pane)
+  release           Tag a release from the primary worktree (--bump major|minor|patch)
+  purge-trash       Delete archived worktrees from the trash (--days N: only older ones)
+  prune             Prune stale worktree metadata and kill tmux sessions of deleted worktrees
+  restore           Recreate the worktree sessions lost to a reboot or tmux kill-server
+  status            Print agent counts, branch and uncommitted changes for a status bar (--format tmux, --path, --ttl, --json)
+  audit [query]     Show archives, renames, pushes and killed sessions (--op, --source, --since 7d, --json)
+  doctor            Check tmux, gh, claude, the config and worktree_base_path, printing how to fix problems
+  version           Print version, build info and detected integrations (--json for JSON)
+  tutorial          Walk through creating, launching and archiving a worktree in a throwaway repository
 
 Flags (worktree UI only):
   --config <path>   Path to config file
+  --profile <name>  Include only this profile's files from the config's profiles
+  --attach          Outside tmux, attach to the selected worktree's session instead of printing its path
 `
 
 func main() {
 	if len(os.Args) < 2 {
-		runWorktreeUI("")
+		runWorktreeUI("", false)
 		return
 	}
 
 	switch os.Args[1] {
 	case "diff-ui":
 		runDiffUI()
+	case "grep":
+		runGrep()
+	case "exec":
+		runExec()
+	case "list":
+		runList()
+	case "add":
+		runAdd()
+	case "archive":
+		runArchive()
+	case "open":
+		runOpen()
+	case "popup":
+		runPopup()
 	case "swap-center":
 		runSwapCenter()
 	case "swap-right-below":
 		runSwapRightBelow()
 	case "watch-rename":
 		runWatchRename()
+	case "devlog-write":
+		runDevLogWrite()
+	case "release":
+		runRelease()
+	case "purge-trash":
+		runPurgeTrash()
+	case "prune":
+		runPrune()
+	case "restore":
+		runRestore()
+	case "status":
+		runStatus()
+	case "audit":
+		runAudit()
+	case "doctor":
+		runDoctor()
+	case "version", "--version":
+		runVersion()
+	case "tutorial":
+		if err := runTutorial(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
 	case "--diff":
 		fmt.Fprintln(os.Stderr, "Warning: --diff is deprecated, use 'yakumo diff-ui' instead")
 		runDiffUI()
@@ -65,8 +152,11 @@ func main() {
 		fs := flag.NewFlagSet("yakumo", flag.ExitOnError)
 		fs.Usage = func() { fmt.Print(usage) }
 		configPath := fs.String("config", "", "path to config file")
+		profile := fs.String("profile", "", "include only this profile's files from the config's profiles")
+		attach := fs.Bool("attach", false, "attach to the selected worktree's tmux session when run outside tmux")
 		fs.Parse(os.Args[1:])
-		runWorktreeUI(*configPath)
+		config.SetProfile(*profile)
+		runWorktreeUI(*configPath, *attach)
 	}
 }
 
@@ -79,16 +169,45 @@ func runDiffUI() {
 		os.Exit(1)
 	}
 
+	cfg := loadOptionalConfig()
+	applyUserNamespace(cfg)
+	applyKeybindings(cfg)
+	applyTheme(cfg)
 	gitRunner := git.OSCommandRunner{}
-	if _, err := exec.LookPath("gh"); err != nil {
-		fmt.Fprintln(os.Stderr, "error: gh CLI is required for diff-ui")
+	ghRunner := newGitHubRunner(cfg.GitHubToken, gitRunner, exec.LookPath)
+
+	var configuredForge string
+	baseRef := cmp.Or(cfg.DefaultBaseRef, config.DefaultBaseRef)
+	if repoPath, err := git.MainRepoPath(gitRunner, dir); err == nil {
+		configuredForge = findRepoByPath(cfg, repoPath).Forge
+		baseRef = config.BaseRef(cfg, repoPath)
+	}
+	kind := forge.KindFor(configuredForge, gitRunner, dir)
+	glabRunner := newGitLabRunner(exec.LookPath)
+	switch {
+	case kind == forge.KindGitHub && ghRunner == nil:
+		fmt.Fprintln(os.Stderr, "error: diff-ui requires the gh CLI or a GitHub token (GH_TOKEN, GITHUB_TOKEN or github_token in config)")
+		os.Exit(1)
+	case kind == forge.KindGitLab && glabRunner == nil:
+		fmt.Fprintln(os.Stderr, "error: diff-ui requires the glab CLI for GitLab repositories")
+		os.Exit(1)
+	}
+	provider, err := forge.New(kind, forge.Options{GitHubRunner: ghRunner, GitLabRunner: glabRunner, GitRunner: gitRunner})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
-	ghRunner := github.OSRunner{}
 
-	baseRef := resolveBaseRef()
+	diffBase, _ := git.ParseDiffBase(cfg.DiffBase)
+	m := diffui.NewModel(dir, gitRunner, provider, baseRef).WithDiffBase(diffBase)
+	if path, err := state.DefaultPath("pr_selections.json"); err == nil {
+		m = m.WithPRSelectionStore(state.PRSelections{File: state.File{Path: path}})
+	}
+	if path, err := state.DefaultPath("todos.json"); err == nil {
+		m = m.WithTodoStore(state.Todos{File: state.File{Path: path}})
+	}
 	p := tea.NewProgram(
-		diffui.NewModel(dir, gitRunner, ghRunner, baseRef),
+		m,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
@@ -98,22 +217,39 @@ func runDiffUI() {
 	}
 }
 
-func setupDebugLog() {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return
+// setupDebugLog sends the log to debug.log, rotated at logging.MaxBytes,
+// and to tee as well when it is set. Without the file it goes to tee, if any.
+func setupDebugLog(tee io.Writer) {
+	var out io.Writer
+	if path, err := logging.Path(); err == nil {
+		if w, err := devlog.Open(path, logging.MaxBytes); err == nil {
+			out = w
+		}
 	}
-	logPath := filepath.Join(home, ".config", "yakumo", "debug.log")
-	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
-	if err != nil {
+	switch {
+	case out == nil && tee == nil:
 		return
+	case out == nil:
+		out = tee
+	case tee != nil:
+		out = io.MultiWriter(tee, out)
 	}
-	log.SetOutput(f)
-	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds)
+	logging.Setup(out)
 }
 
-func runWorktreeUI(configPath string) {
-	setupDebugLog()
+// diffStatTTL is how long a worktree's cached diff stat is trusted while its
+// HEAD and index stay the same. The base ref may move in the meantime.
+const diffStatTTL = 2 * time.Minute
+
+// updateCheckInterval is how long the newest release looked up for
+// check_for_updates is trusted before GitHub is asked again.
+const updateCheckInterval = 24 * time.Hour
+
+// runWorktreeUI runs the sidebar and sets up the session of the selected
+// worktree. Outside tmux the worktree's path is printed, or with attach its
+// session is created and the process becomes a tmux client attached to it.
+func runWorktreeUI(configPath string, attach bool) {
+	setupDebugLog(nil)
 	zone.NewGlobal()
 
 	cfg, err := config.Load(configPath)
@@ -121,26 +257,35 @@ func runWorktreeUI(configPath string) {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
+	logging.SetLevel(cfg.LogLevel)
+	applyUserNamespace(cfg)
+	applyKeybindings(cfg)
+	applyTheme(cfg)
 
-	resolvedConfigPath, err := config.ResolveConfigPath(configPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	// Loading succeeded, so there is no file only when the config came from
+	// $YAKUMO_REPOSITORIES; adding a repository then says so.
+	resolvedConfigPath, _ := config.ResolveConfigPath(configPath)
 
 	runner := git.OSCommandRunner{}
 
 	var tmuxRunner tmux.Runner
 	if tmux.IsInsideTmux() {
 		tmuxRunner = tmux.OSRunner{}
-		if err := tmux.EnsureMainSession(tmuxRunner); err != nil {
-			log.Printf("[main] EnsureMainSession failed (non-fatal): %v", err)
+		// There is nothing to show a confirmation in yet, so paranoid mode
+		// goes without the logo of a new main session.
+		var mainRunner tmux.Runner = tmuxRunner
+		if cfg.Paranoid {
+			mainRunner = tmux.ConfirmRunner{Runner: tmuxRunner, Confirm: func(string) bool { return false }}
+		}
+		if err := tmux.EnsureMainSession(mainRunner); err != nil {
+			logging.For("main").Warn("EnsureMainSession failed (non-fatal)", "err", err)
 		}
 	}
 
-	var ghRunner github.Runner
-	if _, err := exec.LookPath("gh"); err == nil {
-		ghRunner = github.OSRunner{}
+	forgeOpts := forge.Options{
+		GitHubRunner: newGitHubRunner(cfg.GitHubToken, runner, exec.LookPath),
+		GitLabRunner: newGitLabRunner(exec.LookPath),
+		GitRunner:    runner,
 	}
 
 	var claudeReader claude.Reader
@@ -151,20 +296,94 @@ func runWorktreeUI(configPath string) {
 			claudeReader = claude.OSReader{
 				HistoryPath: filepath.Join(home, ".claude", "history.jsonl"),
 			}
-			branchNameGen = branchname.CLIGenerator{
-				ClaudePath: claudePath,
-			}
+			branchNameGen = newBranchNamer(cfg, claudePath)
+		}
+	}
+
+	m := tui.NewModel(cfg, runner, resolvedConfigPath, tmuxRunner, forgeOpts, claudeReader, branchNameGen)
+	if path, err := state.DefaultPath("todos.json"); err == nil {
+		m = m.WithTodoStore(state.Todos{File: state.File{Path: path}})
+	}
+	if path, err := state.DefaultPath("collapsed_groups.json"); err == nil {
+		m = m.WithGroupStore(state.CollapsedGroups{File: state.File{Path: path}})
+	}
+	if path, err := state.DefaultPath("pinned_worktrees.json"); err == nil {
+		m = m.WithPinStore(state.PinnedWorktrees{File: state.File{Path: path}})
+	}
+	if store, ok := worktreeTemplates(); ok {
+		m = m.WithTemplateStore(store)
+	}
+	if path, err := state.DefaultPath("agent_history.json"); err == nil {
+		m = m.WithAgentHistory(state.AgentHistory{File: state.File{Path: path}})
+	}
+	autoWIP, _ := wip.ParseMode(cfg.AutoWIP)
+	m = m.WithAutoWIP(autoWIP)
+	var recent state.RecentWorktrees
+	if path, err := state.DefaultPath("recent_worktrees.json"); err == nil {
+		recent = state.RecentWorktrees{File: state.File{Path: path}}
+		m = m.WithRecent(recent.Get())
+	}
+	if store, ok := savedSessions(); ok && tmuxRunner != nil {
+		// Nothing can be confirmed under the sidebar, so paranoid mode
+		// recreates the sessions without starting their tools.
+		var launchRunner tmux.Runner = tmuxRunner
+		if cfg.Paranoid {
+			launchRunner = tmux.ConfirmRunner{Runner: tmuxRunner, Confirm: func(string) bool { return false }}
+		}
+		m = m.WithSessionRestore(func() (restore.Report, error) {
+			return restore.Run(launchRunner, store, restoreLauncher(cfg, launchRunner))
+		})
+	}
+	if store, ok := portBlocks(); ok && cfg.PortBase > 0 {
+		m = m.WithPorts(ports.Blocks(store, cfg.PortBlockSize))
+	}
+	if current := buildinfo.CurrentVersion(); cfg.CheckForUpdates && current != "dev" {
+		m = m.WithUpdateCheck(current, latestRelease)
+	}
+	if path, err := logging.Path(); err == nil {
+		m = m.WithDebugLog(path)
+	}
+	if resolvedConfigPath != "" {
+		if watcher, err := config.Watch(resolvedConfigPath); err == nil {
+			defer watcher.Close()
+			m = m.WithConfigReload(watcher.Changes())
+		} else {
+			logging.For("config").Warn("config changes will need a restart (non-fatal)", "err", err)
 		}
 	}
+	m = m.WithClipboard(clipboard.OSReader{})
+	m = m.WithAudit(auditLog())
+	if bin, err := trash.New(cfg.TrashDir); err == nil {
+		m = m.WithTrash(bin)
+	} else {
+		logging.For("main").Warn("trash disabled (non-fatal)", "err", err)
+	}
+	var diffStats state.DiffStats
+	var statCache *git.StatCache
+	if path, err := state.DefaultPath("diff_stats.json"); err == nil {
+		diffStats = state.DiffStats{File: state.File{Path: path}}
+		statCache = git.NewStatCache(diffStatTTL, diffStats.Get())
+		m = m.WithStatCache(statCache)
+	}
 
-	m := tui.NewModel(cfg, runner, resolvedConfigPath, tmuxRunner, ghRunner, claudeReader, branchNameGen)
+	pollInterval, _ := config.ParseAgentPollInterval(cfg.AgentPollInterval)
+	m = m.WithAgentPollInterval(pollInterval)
+	agentProfiles, _ := agent.ParseProfiles(cfg.Agents)
+	m = m.WithAgentProfiles(agentProfiles)
+	m = m.WithNotifier(notify.OSNotifier{})
 
-	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	// Focus reports let the sidebar pause agent polling in the background.
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithReportFocus())
 	result, err := p.Run()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
+	if statCache != nil {
+		if err := diffStats.Save(statCache.Entries()); err != nil {
+			logging.For("main").Warn("saving diff stats (non-fatal)", "err", err)
+		}
+	}
 
 	finalModel, ok := result.(tui.Model)
 	if !ok || finalModel.Selected() == "" {
@@ -172,12 +391,25 @@ func runWorktreeUI(configPath string) {
 	}
 
 	selected := finalModel.Selected()
+	if recent.File.Path != "" {
+		if err := recent.Add(selected); err != nil {
+			logging.For("main").Warn("recording recent worktree (non-fatal)", "err", err)
+		}
+	}
 
-	if tmux.IsInsideTmux() {
+	// Picked from search results: open the match in zed, as diff-ui does for changed files.
+	if file := finalModel.SelectedFile(); file != "" {
+		if err := exec.Command("zed", file).Start(); err != nil {
+			logging.For("grep").Error("opening in zed failed", "file", file, "err", err)
+		}
+	}
+
+	if inside := tmux.IsInsideTmux(); inside || attach {
 		spinnerModel := setupspinner.New("Setting up workspace...")
 		spinnerProg := tea.NewProgram(spinnerModel)
 
-		go runSessionSetup(spinnerProg, cfg, finalModel, selected)
+		sessionName := make(chan string, 1)
+		go runSessionSetup(spinnerProg, cfg, finalModel, selected, !inside, sessionName)
 
 		result, err := spinnerProg.Run()
 		if err != nil {
@@ -191,68 +423,206 @@ func runWorktreeUI(configPath string) {
 			}
 		}
 
+		// The name is only sent when the setup got to the end.
+		select {
+		case name := <-sessionName:
+			if !inside {
+				if err := tmux.AttachWorktree(name); err != nil {
+					fmt.Fprintf(os.Stderr, "tmux error: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		default:
+		}
 		return
 	}
 
 	fmt.Print(selected)
 }
 
-func runSessionSetup(prog *tea.Program, cfg model.Config, finalModel tui.Model, selected string) {
-	tmuxRunner := tmux.OSRunner{}
+// runSessionSetup creates the sessions of the selected worktrees and switches
+// the client to the selected one. With detached, as when yakumo runs outside
+// tmux, there is no client to switch: the session is only created. The name
+// of the selected worktree's session is sent on sessionName on success.
+func runSessionSetup(prog *tea.Program, cfg model.Config, finalModel tui.Model, selected string, detached bool, sessionName chan<- string) {
+	var tmuxRunner tmux.Runner = tmux.OSRunner{}
+	if cfg.Paranoid {
+		// Show every command about to be typed into a pane and wait for a y/n.
+		tmuxRunner = tmux.ConfirmRunner{
+			Runner:  tmuxRunner,
+			Confirm: func(command string) bool { return setupspinner.Confirm(prog, command) },
+		}
+	}
 	gitRunner := git.OSCommandRunner{}
-	getBranch := tmux.BranchGetter(func(worktreePath string) (string, error) {
-		out, err := gitRunner.Run(worktreePath, "symbolic-ref", "--short", "HEAD")
+	getBranch := branchGetter(gitRunner)
+
+	// Worktrees marked alongside the selection get their sessions in the
+	// background; only the selected one is switched to.
+	for _, extra := range finalModel.BackgroundSelected() {
+		prog.Send(setupspinner.StatusMsg(fmt.Sprintf("Creating session for %s...", filepath.Base(extra.WorktreePath))))
+		repo := worktreeRepo(cfg, extra.RepoPath, extra.WorktreePath)
+		layout, err := tmux.EnsureWorktreeSession(tmuxRunner, extra.WorktreePath, repo.StartupCommand, getBranch)
 		if err != nil {
-			return "", err
+			logging.For("setup").Error("session failed", "path", extra.WorktreePath, "err", err)
+			continue
 		}
-		return strings.TrimSpace(out), nil
-	})
+		saveSession(layout.SessionName, extra.WorktreePath, extra.RepoPath, repo.StartupCommand)
+		adoptSession(prog, tmuxRunner, layout, extra.WorktreePath)
+		setupSession(prog, tmuxRunner, cfg, finalModel, repo, layout, extra.WorktreePath)
+	}
+
+	if mode, _ := wip.ParseMode(cfg.AutoWIP); mode != wip.ModeOff && !detached {
+		prog.Send(setupspinner.StatusMsg("Saving work in progress..."))
+		saveLeavingWorktree(tmuxRunner, gitRunner, finalModel, mode, selected)
+	}
 
 	prog.Send(setupspinner.StatusMsg("Creating session..."))
-	repo := findRepoByPath(cfg, finalModel.SelectedRepoPath())
-	layout, err := tmux.SelectWorktreeSession(tmuxRunner, selected, repo.StartupCommand, getBranch)
+	repo := worktreeRepo(cfg, finalModel.SelectedRepoPath(), selected)
+	layout, err := tmux.EnsureWorktreeSession(tmuxRunner, selected, repo.StartupCommand, getBranch)
 	if err != nil {
 		prog.Send(setupspinner.DoneMsg{Err: fmt.Errorf("tmux error: %w", err)})
 		return
 	}
-
-	// Run additional commands only for newly created sessions
-	if layout.BottomRight1.PaneID != "" {
-		// Launch diff-ui in top-right pane
-		prog.Send(setupspinner.StatusMsg("Launching diff-ui..."))
-		if diffCmd := diffUICommand(); diffCmd != "" {
-			if err := tmux.SendKeys(tmuxRunner, layout.TopRight1.PaneID, diffCmd); err != nil {
-				log.Printf("[setup] diff-ui launch error: %v", err)
-			}
+	saveSession(layout.SessionName, selected, finalModel.SelectedRepoPath(), repo.StartupCommand)
+	// Asked before switching, while the spinner is still in view.
+	adoptSession(prog, tmuxRunner, layout, selected)
+	if !detached {
+		if err := tmux.SwitchToWorktree(tmuxRunner, layout.SessionName); err != nil {
+			prog.Send(setupspinner.DoneMsg{Err: fmt.Errorf("tmux error: %w", err)})
+			return
 		}
-
-		// Ensure claude trust and launch claude CLI in center pane
-		prog.Send(setupspinner.StatusMsg("Launching Claude..."))
-		if _, err := exec.LookPath("claude"); err == nil {
-			if home, err := os.UserHomeDir(); err == nil {
-				configPath := filepath.Join(home, ".claude.json")
-				if trustErr := claude.EnsureDirectoryTrusted(configPath, selected); trustErr != nil {
-					log.Printf("[setup] claude trust warning: %v", trustErr)
-				}
-			}
-			if err := tmux.SendKeys(tmuxRunner, layout.Center1.PaneID, "claude"); err != nil {
-				log.Printf("[setup] claude launch error: %v", err)
-			}
+	}
+	setupSession(prog, tmuxRunner, cfg, finalModel, repo, layout, selected)
+	if pane := finalModel.SelectedPane(); pane != "" {
+		if err := tmux.FocusPane(tmuxRunner, pane); err != nil {
+			logging.For("setup").Warn("focusing agent pane failed (non-fatal)", "pane", pane, "err", err)
 		}
+	}
 
-		// Focus center pane after all commands are sent
-		prog.Send(setupspinner.StatusMsg("Focusing workspace..."))
-		if err := tmux.SelectPane(tmuxRunner, layout.Center1.PaneID); err != nil {
-			log.Printf("[setup] select pane error: %v", err)
-		}
+	sessionName <- layout.SessionName
+	prog.Send(setupspinner.DoneMsg{})
+}
+
+// branchGetter names the session of a worktree after its branch, as the
+// sidebar does.
+func branchGetter(runner git.CommandRunner) tmux.BranchGetter {
+	return func(worktreePath string) (string, error) {
+		return git.CurrentBranch(runner, worktreePath)
+	}
+}
+
+// savedSessions returns the store of the sessions yakumo set up, which
+// restore recreates. ok is false when the state directory cannot be resolved.
+func savedSessions() (store state.SavedSessions, ok bool) {
+	path, err := state.DefaultPath("sessions.json")
+	if err != nil {
+		logging.For("main").Warn("saved sessions disabled (non-fatal)", "err", err)
+		return state.SavedSessions{}, false
+	}
+	return state.SavedSessions{File: state.File{Path: path}}, true
+}
+
+// saveSession remembers the session of a worktree under its current name so
+// restore can recreate it after the tmux server is gone.
+func saveSession(name, worktreePath, repoPath, startupCommand string) {
+	store, ok := savedSessions()
+	if !ok {
+		return
+	}
+	saved := state.SavedSession{Name: name, WorktreePath: worktreePath, RepoPath: repoPath, StartupCommand: startupCommand}
+	if err := store.Add(saved); err != nil {
+		logging.For("setup").Warn("saving session (non-fatal)", "session", name, "err", err)
+	}
+}
+
+// worktreeTemplates returns the store of the template each worktree was
+// created with. ok is false when the state directory cannot be resolved.
+func worktreeTemplates() (store state.WorktreeTemplates, ok bool) {
+	path, err := state.DefaultPath("worktree_templates.json")
+	if err != nil {
+		logging.For("main").Warn("worktree templates disabled (non-fatal)", "err", err)
+		return state.WorktreeTemplates{}, false
+	}
+	return state.WorktreeTemplates{File: state.File{Path: path}}, true
+}
+
+// worktreeRepo returns the repository at repoPath with the template the
+// worktree at worktreePath was created with applied, if any.
+func worktreeRepo(cfg model.Config, repoPath, worktreePath string) model.RepositoryDef {
+	repo := findRepoByPath(cfg, repoPath)
+	store, ok := worktreeTemplates()
+	if !ok {
+		return repo
+	}
+	if tmpl, ok := config.FindTemplate(cfg, store.Get()[worktreePath]); ok {
+		return config.ApplyTemplate(repo, tmpl)
+	}
+	return repo
+}
+
+// adoptSession offers to add the windows of yakumo's layout to an existing
+// session that lacks them, such as one created outside yakumo, which diff-ui
+// and the swap commands would otherwise fail on later.
+func adoptSession(prog *tea.Program, tmuxRunner tmux.Runner, layout tmux.SessionLayout, worktreePath string) {
+	if layout.Center1.PaneID != "" {
+		return
+	}
+	missing, err := tmux.MissingWindows(tmuxRunner, layout.SessionName)
+	if err != nil {
+		logging.For("setup").Warn("checking the layout failed (non-fatal)", "session", layout.SessionName, "err", err)
+		return
+	}
+	if len(missing) == 0 {
+		return
+	}
+	question := fmt.Sprintf("Session %s lacks yakumo's layout. Add the missing windows and panes?", layout.SessionName)
+	if !setupspinner.Ask(prog, question, strings.Join(missing, ", ")) {
+		return
+	}
+	prog.Send(setupspinner.StatusMsg("Adding missing windows..."))
+	if err := tmux.ReconcileSessionLayout(tmuxRunner, layout.SessionName, worktreePath); err != nil {
+		logging.For("setup").Warn("reconciling the layout failed (non-fatal)", "session", layout.SessionName, "err", err)
+	}
+}
+
+// saveLeavingWorktree snapshots the uncommitted work of the worktree whose
+// session the user is switching away from, unless it is the one selected.
+func saveLeavingWorktree(tmuxRunner tmux.Runner, gitRunner git.CommandRunner, finalModel tui.Model, mode wip.Mode, selected string) {
+	path, err := tmux.LeavingSessionPath(tmuxRunner)
+	if err != nil {
+		logging.For("wip").Warn("finding the session being left failed (non-fatal)", "err", err)
+		return
+	}
+	if path == "" || path == selected || !finalModel.HasWorktree(path) {
+		return
+	}
+	if _, err := wip.Save(gitRunner, path, mode, time.Now()); err != nil {
+		logging.For("wip").Warn("saving work failed (non-fatal)", "path", path, "err", err)
+	}
+}
+
+// setupSession starts the worktree's tools in a session created for it and
+// launches the branch rename watcher when a rename is pending.
+func setupSession(prog *tea.Program, tmuxRunner tmux.Runner, cfg model.Config, finalModel tui.Model, repo model.RepositoryDef, layout tmux.SessionLayout, selected string) {
+	// Run additional commands only for newly created sessions
+	if layout.BottomRight1.PaneID != "" {
+		launchWorktreeTools(tmuxRunner, cfg, repo, layout, selected, func(status string) {
+			prog.Send(setupspinner.StatusMsg(status))
+		})
 	}
 
 	// Launch rename watcher in a tmux background pane
 	if renameInfo := finalModel.PendingRename(selected); renameInfo != nil {
+		// In a new session, take the first background pane the panes
+		// config leaves free.
 		targetPane := ""
-		if layout.BottomRight2.PaneID != "" {
-			targetPane = layout.BottomRight2.PaneID
-		} else {
+		for _, name := range []string{"bottom_right_2", "bottom_right_3", "center_2", "center_3"} {
+			if id := layout.PaneID(name); id != "" && repo.Panes[name] == "" {
+				targetPane = id
+				break
+			}
+		}
+		if targetPane == "" {
 			paneID, err := findIdleBackgroundPane(tmuxRunner, layout.SessionName)
 			if err == nil {
 				targetPane = paneID
@@ -261,12 +631,245 @@ func runSessionSetup(prog *tea.Program, cfg model.Config, finalModel tui.Model,
 		if targetPane != "" {
 			if err := launchRenameWatcher(tmuxRunner, targetPane,
 				selected, renameInfo.OriginalBranch, layout.SessionName, renameInfo.CreatedAt); err != nil {
-				log.Printf("[branch-rename] watcher launch failed: %v", err)
+				logging.For("branch-rename").Error("watcher launch failed", "err", err)
 			}
 		}
 	}
+}
 
-	prog.Send(setupspinner.DoneMsg{})
+// launchWorktreeTools exports the worktree's ports and the repository's env
+// and starts diff-ui, the
+// agent and the configured pane commands in a session just created for the
+// worktree at worktreePath, reporting each step to status.
+func launchWorktreeTools(tmuxRunner tmux.Runner, cfg model.Config, repo model.RepositoryDef, layout tmux.SessionLayout, worktreePath string, status func(string)) {
+	// Export the ports and env before anything is started in the panes
+	if env := sessionEnv(cfg, repo, worktreePath); len(env) > 0 {
+		status("Exporting environment...")
+		if err := tmux.InjectEnv(tmuxRunner, layout, env); err != nil {
+			logging.For("setup").Error("env export failed", "err", err)
+		}
+	}
+
+	// Launch diff-ui, or the configured command, in top-right pane
+	status("Launching diff-ui...")
+	if diffCmd := cmp.Or(repo.Panes["top_right"], diffUICommand()); diffCmd != "" {
+		if err := tmux.SendKeys(tmuxRunner, layout.TopRight1.PaneID, diffCmd); err != nil {
+			logging.For("setup").Error("diff-ui launch failed", "err", err)
+		}
+	}
+
+	// Ensure claude trust and launch claude CLI, or the configured
+	// command, in center pane
+	status("Launching Claude...")
+	centerCmd := repo.Panes["center"]
+	if _, err := exec.LookPath("claude"); err == nil {
+		if home, err := os.UserHomeDir(); err == nil {
+			configPath := filepath.Join(home, ".claude.json")
+			if trustErr := claude.EnsureDirectoryTrusted(configPath, worktreePath); trustErr != nil {
+				logging.For("setup").Warn("claude trust check", "err", trustErr)
+			}
+		}
+		centerCmd = cmp.Or(centerCmd, "claude")
+	}
+	if centerCmd != "" {
+		if err := tmux.SendKeys(tmuxRunner, layout.Center1.PaneID, centerCmd); err != nil {
+			logging.For("setup").Error("claude launch failed", "err", err)
+		}
+	}
+
+	// Capture dev-server output before anything is started in the pane
+	if repo.DevLog {
+		status("Capturing dev-server log...")
+		if err := startDevLog(tmuxRunner, layout.BottomRight1.PaneID, worktreePath); err != nil {
+			logging.For("setup").Error("dev-server log failed", "err", err)
+		}
+	}
+
+	// Start the remaining configured commands once the log is capturing
+	status("Starting pane commands...")
+	sendPaneCommands(tmuxRunner, layout, repo.Panes, "bottom_right", "center_2", "center_3", "bottom_right_2", "bottom_right_3")
+
+	// Focus center pane after all commands are sent
+	status("Focusing workspace...")
+	if err := tmux.SelectPane(tmuxRunner, layout.Center1.PaneID); err != nil {
+		logging.For("setup").Error("selecting pane failed", "err", err)
+	}
+}
+
+// sessionEnv returns the variables to export into a new session of the
+// worktree at worktreePath: its block of ports when port_base is set, then
+// the repository's env, which may override them.
+func sessionEnv(cfg model.Config, repo model.RepositoryDef, worktreePath string) map[string]string {
+	env := map[string]string{}
+	if cfg.PortBase > 0 {
+		if store, ok := portBlocks(); ok {
+			block, err := ports.Assign(store, worktreePath, cfg.PortBase, cfg.PortBlockSize)
+			if err != nil {
+				logging.For("setup").Error("port allocation failed", "err", err)
+			} else {
+				maps.Copy(env, block.Env())
+			}
+		}
+	}
+	repoEnv, err := tmux.ExpandEnv(repo.Env, tmux.SessionNameData{Repo: repo.Name, Slug: filepath.Base(worktreePath)})
+	if err != nil {
+		logging.For("setup").Error("setting env failed", "err", err)
+	}
+	maps.Copy(env, repoEnv)
+	return env
+}
+
+// latestRelease returns the tag of the newest yakumo release, asking GitHub
+// at most once per updateCheckInterval.
+func latestRelease() (string, error) {
+	var cache state.LatestRelease
+	path, err := state.DefaultPath("latest_release.json")
+	if err == nil {
+		cache = state.LatestRelease{File: state.File{Path: path}}
+		if tag, ok := cache.Get(updateCheckInterval, time.Now()); ok {
+			return tag, nil
+		}
+	}
+	tag, err := buildinfo.LatestRelease(&http.Client{Timeout: 10 * time.Second}, buildinfo.LatestReleaseURL)
+	if err != nil {
+		return "", err
+	}
+	if cache.File.Path != "" {
+		if err := cache.Set(tag, time.Now()); err != nil {
+			logging.For("main").Warn("caching the latest release failed (non-fatal)", "err", err)
+		}
+	}
+	return tag, nil
+}
+
+// portBlocks returns the store of the port blocks assigned to worktrees. ok
+// is false when the state directory cannot be resolved.
+func portBlocks() (store state.PortBlocks, ok bool) {
+	path, err := state.DefaultPath("ports.json")
+	if err != nil {
+		logging.For("main").Warn("port allocation disabled (non-fatal)", "err", err)
+		return state.PortBlocks{}, false
+	}
+	return state.PortBlocks{File: state.File{Path: path}}, true
+}
+
+// runTutorial walks the user through a throwaway repository: the sidebar is
+// opened with a hint for each step, and reopened after a worktree is launched
+// so it can be archived. The sandbox and its session are removed at the end.
+func runTutorial() error {
+	setupDebugLog(nil)
+	zone.NewGlobal()
+
+	// Keys and colors follow the user's config, everything else the sandbox's.
+	userCfg := loadOptionalConfig()
+	logging.SetLevel(userCfg.LogLevel)
+	applyUserNamespace(userCfg)
+	applyKeybindings(userCfg)
+	applyTheme(userCfg)
+
+	dir, err := os.MkdirTemp("", "yakumo-tutorial-")
+	if err != nil {
+		return fmt.Errorf("creating sandbox: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	runner := git.OSCommandRunner{}
+	sandbox, err := tutorial.Setup(runner, dir)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadFromFile(sandbox.ConfigPath())
+	if err != nil {
+		return err
+	}
+
+	var tmuxRunner tmux.Runner
+	if tmux.IsInsideTmux() {
+		tmuxRunner = tmux.OSRunner{}
+	}
+	var sessions []string
+	defer func() {
+		// Archiving kills the session; these are the ones left behind by
+		// leaving the tutorial early.
+		for _, name := range sessions {
+			if exists, _ := tmux.HasWorktree(tmuxRunner, name); exists {
+				if err := tmux.KillWorktree(tmuxRunner, name); err != nil {
+					logging.For("tutorial").Error("killing session failed", "session", name, "err", err)
+				}
+			}
+		}
+	}()
+
+	in := bufio.NewReader(os.Stdin)
+	fmt.Printf("This tutorial runs yakumo on a throwaway repository in %s.\nFollow the hints at the top of the sidebar. Press enter to start.", sandbox.Dir)
+	in.ReadString('\n')
+
+	guide := tutorial.Guide{}
+	for {
+		m := tui.NewModel(cfg, runner, sandbox.ConfigPath(), tmuxRunner, forge.Options{GitRunner: runner}, nil, nil).WithHinter(guide)
+		result, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion()).Run()
+		if err != nil {
+			return err
+		}
+		finalModel, ok := result.(tui.Model)
+		if !ok || finalModel.Selected() == "" {
+			if guide.Launched {
+				fmt.Println("That's the tour. Run yakumo in tmux to get started for real.")
+			}
+			return nil
+		}
+		guide.Launched = true
+
+		sessionName := ""
+		if tmuxRunner != nil {
+			layout, err := tmux.EnsureWorktreeSession(tmuxRunner, finalModel.Selected(), "", nil)
+			if err != nil {
+				return fmt.Errorf("tmux error: %w", err)
+			}
+			sessionName = layout.SessionName
+			sessions = append(sessions, sessionName)
+		}
+		fmt.Print("\n" + tutorial.Layout(sessionName) + "\nPress enter to go back to the sidebar.")
+		in.ReadString('\n')
+	}
+}
+
+// runPopup opens the worktree UI in a popup over the current tmux client.
+// Selecting a worktree switches the client to its session as usual, and the
+// popup closes when the UI exits.
+func runPopup() {
+	fs := flag.NewFlagSet("popup", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to config file")
+	profile := fs.String("profile", "", "include only this profile's files from the config's profiles")
+	width := fs.String("width", "80%", "popup width in cells or percent of the terminal")
+	height := fs.String("height", "80%", "popup height in cells or percent of the terminal")
+	fs.Parse(os.Args[2:])
+
+	if !tmux.IsInsideTmux() {
+		fmt.Fprintln(os.Stderr, "error: popup requires running inside tmux")
+		os.Exit(1)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: resolving executable: %v\n", err)
+		os.Exit(1)
+	}
+	if err := tmux.DisplayPopup(tmux.OSRunner{}, *width, *height, popupCommand(exe, *configPath, *profile)); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// popupCommand is the shell command that runs the worktree UI in a popup.
+func popupCommand(exe, configPath, profile string) string {
+	cmd := shellEscape(exe)
+	if configPath != "" {
+		cmd += " --config " + shellEscape(configPath)
+	}
+	if profile != "" {
+		cmd += " --profile " + shellEscape(profile)
+	}
+	return cmd
 }
 
 func runSwapCenter() {
@@ -274,6 +877,7 @@ func runSwapCenter() {
 		fmt.Fprintln(os.Stderr, "error: swap-center requires running inside tmux")
 		os.Exit(1)
 	}
+	applyUserNamespace(loadOptionalConfig())
 	runner := tmux.OSRunner{}
 	if err := tmux.SwapCenter(runner); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -286,6 +890,7 @@ func runSwapRightBelow() {
 		fmt.Fprintln(os.Stderr, "error: swap-right-below requires running inside tmux")
 		os.Exit(1)
 	}
+	applyUserNamespace(loadOptionalConfig())
 	runner := tmux.OSRunner{}
 	if err := tmux.SwapRightBelow(runner); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -354,7 +959,7 @@ func resolveWatchRenameArgs(
 	if rawSessionName != "" {
 		args.sessionName = rawSessionName
 	} else if tmuxRunner != nil {
-		name, err := tmux.CurrentSessionName(tmuxRunner)
+		name, err := tmux.CurrentWorktreeName(tmuxRunner)
 		if err != nil {
 			return args, fmt.Errorf("resolving tmux session: %w", err)
 		}
@@ -364,83 +969,1033 @@ func resolveWatchRenameArgs(
 	return args, nil
 }
 
-func resolveBaseRef() string {
-	baseRef := config.DefaultBaseRef
-	path, err := config.ResolveConfigPath("")
-	if err != nil {
-		return baseRef
-	}
-	cfg, err := config.LoadFromFile(path)
-	if err != nil {
-		return baseRef
-	}
-	if cfg.DefaultBaseRef != "" {
-		baseRef = cfg.DefaultBaseRef
-	}
-	return baseRef
-}
-
-func runWatchRename() {
-	setupDebugLog()
+// grepMatchLimit caps the matches printed per worktree.
+const grepMatchLimit = 50
 
-	fs := flag.NewFlagSet("watch-rename", flag.ExitOnError)
-	wtPath := fs.String("path", "", "absolute path to the worktree (default: current directory)")
-	branch := fs.String("branch", "", "original branch name (default: current git branch)")
-	createdAtStr := fs.String("created-at", "", "unix millisecond timestamp or relative duration (e.g., 10m, 1h) (default: now)")
-	sessionName := fs.String("session-name", "", "tmux session name (default: current tmux session)")
+func runGrep() {
+	fs := flag.NewFlagSet("grep", flag.ExitOnError)
+	all := fs.Bool("all", false, "search every configured repository")
 	fs.Parse(os.Args[2:])
-
-	runner := git.OSCommandRunner{}
-
-	var tmuxRunner tmux.Runner
-	if tmux.IsInsideTmux() {
-		tmuxRunner = tmux.OSRunner{}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: yakumo grep [--all] <pattern>")
+		os.Exit(2)
 	}
 
-	resolved, err := resolveWatchRenameArgs(
-		*wtPath, *branch, *createdAtStr, *sessionName,
-		runner, tmuxRunner,
-		os.Getwd, func() int64 { return time.Now().UnixMilli() },
-	)
+	cfg, err := config.Load("")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
-
-	home, err := os.UserHomeDir()
+	dir, err := os.Getwd()
 	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 
-	claudePath, err := exec.LookPath("claude")
-	if err != nil {
+	runner := git.OSCommandRunner{}
+	var groups []model.RepoGroup
+	for _, repo := range grepRepos(cfg, runner, dir, *all) {
+		entries, err := git.ListWorktrees(runner, repo.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: listing worktrees of %s: %v\n", repo.Name, err)
+			continue
+		}
+		groups = append(groups, model.RepoGroup{Name: repo.Name, RootPath: repo.Path, Worktrees: git.ToWorktreeInfo(entries)})
+	}
+
+	results := search.Run(runner, search.TargetsFromGroups(groups), fs.Arg(0), grepMatchLimit)
+	if !printGrepResults(os.Stdout, results) {
 		os.Exit(1)
 	}
+}
 
-	reader := claude.OSReader{
-		HistoryPath: filepath.Join(home, ".claude", "history.jsonl"),
+// grepRepos returns the repository containing dir, or every configured
+// repository when all is set or dir is outside them.
+func grepRepos(cfg model.Config, runner git.CommandRunner, dir string, all bool) []model.RepositoryDef {
+	if !all {
+		if repoPath, err := git.MainRepoPath(runner, dir); err == nil {
+			if repo := findRepoByPath(cfg, repoPath); repo.Path != "" {
+				return []model.RepositoryDef{repo}
+			}
+		}
 	}
-	gen := branchname.CLIGenerator{ClaudePath: claudePath}
+	return cfg.Repositories
+}
 
-	cfg := rename.WatcherConfig{
-		WorktreePath: resolved.wtPath,
-		Branch:       resolved.branch,
-		SessionName:  resolved.sessionName,
+// printGrepResults writes results grouped by worktree and reports whether
+// anything matched.
+func printGrepResults(w io.Writer, results []search.Result) bool {
+	matched := false
+	for _, r := range results {
+		fmt.Fprintf(w, "%s %s  %s\n", r.Repo, r.Branch, r.Path)
+		if r.Err != nil {
+			fmt.Fprintf(w, "  error: %v\n", r.Err)
+			continue
+		}
+		matched = true
+		for _, m := range r.Matches {
+			fmt.Fprintf(w, "  %s:%d: %s\n", m.File, m.Line, strings.TrimSpace(m.Text))
+		}
+		if r.Truncated {
+			fmt.Fprintf(w, "  … more matches omitted\n")
+		}
+	}
+	return matched
+}
+
+const execUsage = "usage: yakumo exec [--repo <name>] --all-worktrees [--jobs N] -- <cmd> [args...]"
+
+// execOutputLines caps the output printed for each failing worktree.
+const execOutputLines = 20
+
+func runExec() {
+	fs := flag.NewFlagSet("exec", flag.ExitOnError)
+	repoName := fs.String("repo", "", "repository name from the config (default: the one containing the current directory)")
+	allWorktrees := fs.Bool("all-worktrees", false, "run in every worktree of the repository")
+	jobs := fs.Int("jobs", runtime.NumCPU(), "number of worktrees to run in at once")
+	fs.Parse(os.Args[2:])
+	if !*allWorktrees || fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, execUsage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	runner := git.OSCommandRunner{}
+	repo, err := execRepo(cfg, runner, dir, *repoName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
+	entries, err := git.ListWorktrees(runner, repo.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: listing worktrees of %s: %v\n", repo.Name, err)
+		os.Exit(1)
+	}
+
+	targets := matrix.Targets(model.RepoGroup{Name: repo.Name, RootPath: repo.Path, Worktrees: git.ToWorktreeInfo(entries)})
+	done := 0
+	results := matrix.Run(matrix.OSRunner, targets, fs.Args(), *jobs, func(r matrix.Result) {
+		done++
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s %s (%s)\n", done, len(targets), execStatus(r), cmp.Or(r.Branch, "detached"), r.Elapsed.Round(100*time.Millisecond))
+	})
+	if !printExecResults(os.Stdout, results) {
+		os.Exit(1)
+	}
+}
+
+// execRepo returns the configured repository called name, or the one
+// containing dir when name is empty.
+func execRepo(cfg model.Config, runner git.CommandRunner, dir, name string) (model.RepositoryDef, error) {
+	if name != "" {
+		for _, repo := range cfg.Repositories {
+			if repo.Name == name {
+				return repo, nil
+			}
+		}
+		return model.RepositoryDef{}, fmt.Errorf("no repository named %q in the config", name)
+	}
+	if repoPath, err := git.MainRepoPath(runner, dir); err == nil {
+		if repo := findRepoByPath(cfg, repoPath); repo.Path != "" {
+			return repo, nil
+		}
+	}
+	return model.RepositoryDef{}, fmt.Errorf("%s is not in a configured repository, pass --repo", dir)
+}
+
+func execStatus(r matrix.Result) string {
+	if r.Passed() {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+// printExecResults writes a pass/fail table of results followed by the tail
+// of each failure's output, and reports whether every worktree passed.
+func printExecResults(w io.Writer, results []matrix.Result) bool {
+	width := 0
+	for _, r := range results {
+		width = max(width, len(cmp.Or(r.Branch, "detached")))
+	}
+	failed := 0
+	for _, r := range results {
+		if !r.Passed() {
+			failed++
+		}
+		fmt.Fprintf(w, "%s  %-*s  %6s  %s\n", execStatus(r), width, cmp.Or(r.Branch, "detached"), r.Elapsed.Round(100*time.Millisecond), r.Path)
+	}
+
+	for _, r := range results {
+		if r.Passed() {
+			continue
+		}
+		fmt.Fprintf(w, "\n--- %s (%s): %v\n", cmp.Or(r.Branch, "detached"), r.Path, r.Err)
+		lines := strings.Split(strings.TrimRight(r.Output, "\n"), "\n")
+		if len(lines) > execOutputLines {
+			fmt.Fprintf(w, "… %d lines omitted\n", len(lines)-execOutputLines)
+			lines = lines[len(lines)-execOutputLines:]
+		}
+		if r.Output != "" {
+			fmt.Fprintln(w, strings.Join(lines, "\n"))
+		}
+	}
+	fmt.Fprintf(w, "\n%d passed, %d failed\n", len(results)-failed, failed)
+	return failed == 0
+}
+
+func runPurgeTrash() {
+	fs := flag.NewFlagSet("purge-trash", flag.ExitOnError)
+	days := fs.Int("days", 0, "only purge worktrees archived more than N days ago")
+	fs.Parse(os.Args[2:])
+
+	cfg := loadOptionalConfig()
+	applyUserNamespace(cfg)
+	bin, err := trash.New(cfg.TrashDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	cutoff := time.Now().AddDate(0, 0, -*days)
+	if err := purgeTrash(os.Stdout, git.OSCommandRunner{}, bin, auditLog().From(audit.SourceCLI), cutoff); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// purgeTrash purges the worktrees archived before cutoff, lists them on w and
+// records them in auditLog.
+func purgeTrash(w io.Writer, runner git.CommandRunner, bin trash.Trash, auditLog audit.Log, cutoff time.Time) error {
+	purged, err := bin.PurgeOlderThan(runner, cutoff)
+	for _, e := range purged {
+		auditLog.Record(audit.Event{Op: audit.OpPurge, Repo: e.RepoPath, Path: e.OriginalPath, Branch: e.Branch, Detail: bin.Path(e)}, nil)
+		fmt.Fprintf(w, "purged %s (%s)\n", e.OriginalPath, cmp.Or(e.Branch, "detached"))
+	}
+	if len(purged) == 0 && err == nil {
+		fmt.Fprintln(w, "Nothing to purge.")
+	}
+	return err
+}
+
+func runRelease() {
+	fs := flag.NewFlagSet("release", flag.ExitOnError)
+	bumpFlag := fs.String("bump", "", "version bump: major, minor or patch (prompted when omitted)")
+	fs.Parse(os.Args[2:])
+
+	if _, err := exec.LookPath("gh"); err != nil {
+		fmt.Fprintln(os.Stderr, "error: release requires the gh CLI")
+		os.Exit(1)
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg := loadOptionalConfig()
+	applyUserNamespace(cfg)
+	base := forge.BranchName(cmp.Or(cfg.DefaultBaseRef, config.DefaultBaseRef))
+	gitRunner := git.OSCommandRunner{}
+	plan, err := release.Prepare(gitRunner, github.OSRunner{}, dir, base)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	bump := release.Bump(*bumpFlag)
+	if bump == "" {
+		if bump, err = promptBump(in, os.Stdout, plan); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	} else if !slices.Contains(release.Bumps, bump) {
+		fmt.Fprintf(os.Stderr, "error: unknown bump %q (want major, minor or patch)\n", bump)
+		os.Exit(2)
+	}
+	tag := plan.Current.Bump(bump).String()
+
+	fmt.Printf("\n%s\n", plan.Notes(tag))
+	if !promptYes(in, os.Stdout, fmt.Sprintf("Create and push tag %s?", tag)) {
+		fmt.Println("Aborted.")
+		return
+	}
+	url, err := release.Publish(gitRunner, plan, tag)
+	auditLog().From(audit.SourceCLI).Record(audit.Event{Op: audit.OpPush, Repo: dir, Branch: base, Detail: "tag " + tag}, err)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Tagged %s. Publish the release at:\n  %s\n", tag, url)
+	if err := openURL(url); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: opening the browser: %v\n", err)
+	}
+}
+
+// promptBump offers the next version for each bump and reads the choice,
+// defaulting to patch.
+func promptBump(in *bufio.Reader, w io.Writer, plan release.Plan) (release.Bump, error) {
+	current := cmp.Or(plan.LastTag, "no release yet")
+	fmt.Fprintf(w, "%s: %s, %d PRs merged since\n", plan.Base, current, len(plan.PRs))
+	for i, b := range release.Bumps {
+		fmt.Fprintf(w, "  %d) %-5s  %s\n", i+1, b, plan.Current.Bump(b))
+	}
+	fmt.Fprint(w, "Bump [1]: ")
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("reading choice: %w", err)
+	}
+	choice := strings.TrimSpace(line)
+	if choice == "" {
+		return release.BumpPatch, nil
+	}
+	if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(release.Bumps) {
+		return release.Bumps[n-1], nil
+	}
+	if b := release.Bump(choice); slices.Contains(release.Bumps, b) {
+		return b, nil
+	}
+	return "", fmt.Errorf("unknown bump %q", choice)
+}
+
+// promptYes asks a yes/no question, defaulting to no.
+func promptYes(in *bufio.Reader, w io.Writer, question string) bool {
+	fmt.Fprintf(w, "%s [y/N]: ", question)
+	line, _ := in.ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
+
+// openURL opens url in the default browser without waiting for it.
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// loadOptionalConfig loads the default config file, or without one the
+// config from the environment, returning a zero Config when neither is valid.
+// Used by subcommands that work without config.
+func loadOptionalConfig() model.Config {
+	path, err := config.ResolveConfigPath("")
+	if err != nil {
+		cfg, _ := config.LoadFromEnv()
+		return cfg
+	}
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		return model.Config{}
+	}
+	return cfg
+}
+
+// applyKeybindings rebinds the keys of both UIs from the `keybindings`
+// config, exiting on unknown modes or actions and clashing keys.
+func applyKeybindings(cfg model.Config) {
+	if err := rebindKeys(cfg.Keybindings); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func rebindKeys(overrides map[string]map[string]model.KeyList) error {
+	keymaps := tui.Keymaps()
+	maps.Copy(keymaps, diffui.Keymaps())
+	return keyhelp.Rebind(keymaps, overrides)
+}
+
+// applyTheme derives the styles of both UIs and the setup spinner from the
+// `theme` config.
+func applyTheme(cfg model.Config) {
+	t, err := theme.Resolve(cfg.Theme)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	tui.ApplyTheme(t)
+	diffui.ApplyTheme(t)
+	setupspinner.ApplyTheme(t)
+}
+
+// applyUserNamespace prefixes tmux session names with cfg.SessionPrefix,
+// names them from cfg.SessionNameTemplate and picks sessions or windows for
+// worktrees from cfg.TmuxMode. State is kept per user by state.Dir whatever
+// the config.
+func applyUserNamespace(cfg model.Config) {
+	tmux.SetSessionPrefix(cfg.SessionPrefix)
+	if cfg.SessionNameTemplate != "" {
+		t, _ := tmux.ParseSessionTemplate(cfg.SessionNameTemplate)
+		tmux.SetSessionTemplate(t, func(worktreePath string) string { return repoNameOf(cfg, worktreePath) })
+	}
+	tmuxMode, _ := tmux.ParseMode(cfg.TmuxMode)
+	tmux.SetMode(tmuxMode)
+}
+
+// repoNameOf returns the configured name of the repository a worktree path
+// belongs to: the repository itself, or one of its worktrees under its
+// worktree_base_path/<name>. Other paths fall back to their parent directory.
+func repoNameOf(cfg model.Config, worktreePath string) string {
+	worktreePath = filepath.Clean(worktreePath)
+	for _, repo := range cfg.Repositories {
+		if worktreePath == filepath.Clean(repo.Path) || filepath.Dir(worktreePath) == filepath.Join(config.WorktreeBase(cfg, repo.Path), repo.Name) {
+			return repo.Name
+		}
+	}
+	return filepath.Base(filepath.Dir(worktreePath))
+}
+
+// newGitLabRunner returns the glab CLI runner, or nil when glab is not installed.
+func newGitLabRunner(lookPath func(string) (string, error)) gitlab.Runner {
+	if _, err := lookPath("glab"); err != nil {
+		return nil
+	}
+	return gitlab.OSRunner{}
+}
+
+// newGitHubRunner returns the gh CLI runner when gh is installed, otherwise a
+// REST API runner when a token is available. Returns nil when neither exists.
+func newGitHubRunner(configToken string, gitRunner git.CommandRunner, lookPath func(string) (string, error)) github.Runner {
+	if _, err := lookPath("gh"); err == nil {
+		return github.OSRunner{}
+	}
+	if token := github.ResolveToken(configToken); token != "" {
+		return github.NewAPIRunner(token, gitRunner)
+	}
+	return nil
+}
+
+// listedWorktree is a worktree as list prints it.
+type listedWorktree struct {
+	Repo    string `json:"repo"`
+	Path    string `json:"path"`
+	Branch  string `json:"branch"` // "(detached)" for a detached HEAD
+	Primary bool   `json:"primary"`
+}
+
+func runList() {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print as JSON")
+	fs.Parse(os.Args[2:])
+
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	applyUserNamespace(cfg)
+
+	worktrees, listErr := listWorktrees(git.OSCommandRunner{}, cfg)
+	if err := printWorktrees(os.Stdout, worktrees, *asJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if listErr != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", listErr)
+		os.Exit(1)
+	}
+}
+
+// listWorktrees returns the worktrees of every configured repository, those
+// in the trash left out. Repositories that cannot be listed are skipped and
+// reported in the error.
+func listWorktrees(runner git.CommandRunner, cfg model.Config) ([]listedWorktree, error) {
+	var worktrees []listedWorktree
+	var errs []error
+	for _, repo := range cfg.Repositories {
+		entries, err := git.ListWorktrees(runner, repo.Path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing worktrees of %s: %w", repo.Name, err))
+			continue
+		}
+		for _, wt := range git.ToWorktreeInfo(entries) {
+			if wt.IsBare {
+				continue
+			}
+			worktrees = append(worktrees, listedWorktree{Repo: repo.Name, Path: wt.Path, Branch: wt.Branch, Primary: wt.Path == repo.Path})
+		}
+	}
+	return worktrees, errors.Join(errs...)
+}
+
+// printWorktrees writes worktrees to w as a JSON array, or one per line as
+// aligned repository, branch and path columns.
+func printWorktrees(w io.Writer, worktrees []listedWorktree, asJSON bool) error {
+	if asJSON {
+		if worktrees == nil {
+			worktrees = []listedWorktree{} // [] rather than null
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(worktrees)
+	}
+	repoWidth, branchWidth := 0, 0
+	for _, wt := range worktrees {
+		repoWidth = max(repoWidth, len(wt.Repo))
+		branchWidth = max(branchWidth, len(wt.Branch))
+	}
+	for _, wt := range worktrees {
+		if _, err := fmt.Fprintf(w, "%-*s  %-*s  %s\n", repoWidth, wt.Repo, branchWidth, wt.Branch, wt.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const addUsage = "usage: yakumo add <repo> [--from-url <url>]"
+
+// runAdd creates a worktree as the sidebar's add prompt does and prints its
+// path, so a script can cd into it or open it.
+func runAdd() {
+	fs := flag.NewFlagSet("add", flag.ExitOnError)
+	fromURL := fs.String("from-url", "", "create the worktree for the branch of a PR or branch URL, or for a GitHub issue")
+	fs.Parse(os.Args[2:])
+	// The flag may follow the repository too.
+	name := fs.Arg(0)
+	if fs.NArg() > 0 {
+		fs.Parse(fs.Args()[1:])
+	}
+	if name == "" || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, addUsage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	applyUserNamespace(cfg)
+	runner := git.OSCommandRunner{}
+	repo, err := repoArg(cfg, runner, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
+
+	forgeOpts := forge.Options{
+		GitHubRunner: newGitHubRunner(cfg.GitHubToken, runner, exec.LookPath),
+		GitLabRunner: newGitLabRunner(exec.LookPath),
+		GitRunner:    runner,
+	}
+	added, err := tui.AddWorktree(cfg, runner, forgeOpts, repo.Path, *fromURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(added.WorktreePath)
+	if added.HookErr != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", added.HookErr)
+		os.Exit(1)
+	}
+}
+
+// repoArg returns the configured repository called arg, or the one whose
+// checkout contains the path arg.
+func repoArg(cfg model.Config, runner git.CommandRunner, arg string) (model.RepositoryDef, error) {
+	for _, repo := range cfg.Repositories {
+		if repo.Name == arg {
+			return repo, nil
+		}
+	}
+	if dir, err := filepath.Abs(arg); err == nil {
+		if repoPath, err := git.MainRepoPath(runner, dir); err == nil {
+			if repo := findRepoByPath(cfg, repoPath); repo.Path != "" {
+				return repo, nil
+			}
+		}
+	}
+	return model.RepositoryDef{}, fmt.Errorf("no repository named %q in the config", arg)
+}
+
+// worktreeArg resolves the path arg to the root of the worktree containing
+// it and the configured repository the worktree belongs to.
+func worktreeArg(cfg model.Config, runner git.CommandRunner, arg string) (model.RepositoryDef, string, error) {
+	dir, err := filepath.Abs(arg)
+	if err != nil {
+		return model.RepositoryDef{}, "", err
+	}
+	root, err := runner.Run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return model.RepositoryDef{}, "", fmt.Errorf("%s is not in a git worktree", arg)
+	}
+	repoPath, err := git.MainRepoPath(runner, dir)
+	if err != nil {
+		return model.RepositoryDef{}, "", err
+	}
+	repo := findRepoByPath(cfg, repoPath)
+	if repo.Path == "" {
+		return model.RepositoryDef{}, "", fmt.Errorf("%s is not a worktree of a configured repository", arg)
+	}
+	return repo, strings.TrimSpace(root), nil
+}
+
+// runArchive archives a worktree as d does in the sidebar.
+func runArchive() {
+	fs := flag.NewFlagSet("archive", flag.ExitOnError)
+	fs.Parse(os.Args[2:])
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: yakumo archive <path>")
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	applyUserNamespace(cfg)
+	runner := git.OSCommandRunner{}
+	repo, path, err := worktreeArg(cfg, runner, fs.Arg(0))
+	if err == nil && path == repo.Path {
+		err = fmt.Errorf("%s is the main worktree of %s", path, repo.Name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
+
+	bin, err := trash.New(cfg.TrashDir)
+	if err != nil {
+		logging.For("main").Warn("trash disabled (non-fatal)", "err", err)
+	}
+	if err := tui.ArchiveWorktree(runner, tmux.OSRunner{}, bin, auditLog().From(audit.SourceCLI), cfg, repo.Path, path); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("archived %s\n", path)
+}
+
+// runOpen creates the session of a worktree as selecting it in the sidebar
+// does and switches to it, or attaches to it outside tmux.
+func runOpen() {
+	fs := flag.NewFlagSet("open", flag.ExitOnError)
+	fs.Parse(os.Args[2:])
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: yakumo open <path>")
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	applyUserNamespace(cfg)
+	runner := git.OSCommandRunner{}
+	repo, path, err := worktreeArg(cfg, runner, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
+
+	var tmuxRunner tmux.Runner = tmux.OSRunner{}
+	if cfg.Paranoid {
+		in := bufio.NewReader(os.Stdin)
+		tmuxRunner = tmux.ConfirmRunner{
+			Runner:  tmuxRunner,
+			Confirm: func(command string) bool { return promptYes(in, os.Stdout, fmt.Sprintf("Run %q?", command)) },
+		}
+	}
+	name, err := openWorktree(tmuxRunner, runner, cfg, repo.Path, path)
+	if err == nil {
+		if tmux.IsInsideTmux() {
+			err = tmux.SwitchToWorktree(tmuxRunner, name)
+		} else {
+			err = tmux.AttachWorktree(name)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tmux error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// openWorktree returns the name of the session of the worktree at
+// worktreePath, creating it and starting the worktree's tools when it is not
+// running.
+func openWorktree(tmuxRunner tmux.Runner, gitRunner git.CommandRunner, cfg model.Config, repoPath, worktreePath string) (string, error) {
+	repo := worktreeRepo(cfg, repoPath, worktreePath)
+	layout, err := tmux.EnsureWorktreeSession(tmuxRunner, worktreePath, repo.StartupCommand, branchGetter(gitRunner))
+	if err != nil {
+		return "", err
+	}
+	saveSession(layout.SessionName, worktreePath, repoPath, repo.StartupCommand)
+	if layout.BottomRight1.PaneID != "" {
+		launchWorktreeTools(tmuxRunner, cfg, repo, layout, worktreePath, func(string) {})
+	}
+	return layout.SessionName, nil
+}
+
+func runPrune() {
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	applyUserNamespace(cfg)
+
+	repoPaths := make([]string, 0, len(cfg.Repositories))
+	for _, repo := range cfg.Repositories {
+		repoPaths = append(repoPaths, repo.Path)
+	}
+	report, err := prune.Run(git.OSCommandRunner{}, tmux.OSRunner{}, repoPaths, config.WorktreeBases(cfg), auditLog().From(audit.SourceCLI))
+	fmt.Println(report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runRestore() {
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	applyUserNamespace(cfg)
+
+	store, ok := savedSessions()
+	if !ok {
+		fmt.Fprintln(os.Stderr, "error: no state directory to read saved sessions from")
+		os.Exit(1)
+	}
+	var tmuxRunner tmux.Runner = tmux.OSRunner{}
+	if cfg.Paranoid {
+		in := bufio.NewReader(os.Stdin)
+		tmuxRunner = tmux.ConfirmRunner{
+			Runner:  tmuxRunner,
+			Confirm: func(command string) bool { return promptYes(in, os.Stdout, fmt.Sprintf("Run %q?", command)) },
+		}
+	}
+	report, err := restore.Run(tmuxRunner, store, restoreLauncher(cfg, tmuxRunner))
+	fmt.Println(report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// restoreLauncher starts the tools of each recreated session as the sidebar
+// does for a session it creates.
+func restoreLauncher(cfg model.Config, tmuxRunner tmux.Runner) restore.Launcher {
+	return func(layout tmux.SessionLayout, saved state.SavedSession) {
+		repo := worktreeRepo(cfg, saved.RepoPath, saved.WorktreePath)
+		launchWorktreeTools(tmuxRunner, cfg, repo, layout, saved.WorktreePath, func(string) {})
+	}
+}
+
+// runStatus prints the status line summary. The line is cached for --ttl per
+// format and directory so a tmux status bar can run it every few seconds
+// without polling every pane each time.
+func runStatus() {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	formatFlag := fs.String("format", "plain", "output format: plain or tmux")
+	path := fs.String("path", "", "directory whose branch and changes are shown (default: the current directory)")
+	ttl := fs.Duration("ttl", 5*time.Second, "how long a printed line is reused")
+	asJSON := fs.Bool("json", false, "print every repository and worktree with its diff stats, PR and agents as JSON")
+	fs.Parse(os.Args[2:])
+
+	if *asJSON {
+		runStatusJSON(*ttl)
+		return
+	}
+	format, ok := statusline.ParseFormat(*formatFlag)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "error: --format must be plain or tmux, got %q\n", *formatFlag)
+		os.Exit(2)
+	}
+	dir := *path
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		dir = wd
+	}
+
+	cfg := loadOptionalConfig()
+	applyUserNamespace(cfg)
+
+	key := string(format) + "\x00" + dir
+	now := time.Now()
+	var cache state.StatusLines
+	if path, err := state.DefaultPath("status_lines.json"); err == nil {
+		cache = state.StatusLines{File: state.File{Path: path}}
+		if line, ok := cache.Get(key, *ttl, now); ok {
+			fmt.Println(line)
+			return
+		}
+	}
+
+	profiles, _ := agent.ParseProfiles(cfg.Agents)
+	line := statusline.Collect(git.OSCommandRunner{}, tmux.OSRunner{}, profiles, dir).Render(format)
+	if cache.File.Path != "" {
+		if err := cache.Set(key, line, now); err != nil {
+			logging.For("status").Warn("caching the line (non-fatal)", "err", err)
+		}
+	}
+	fmt.Println(line)
+}
+
+// runStatusJSON prints the status report of every configured repository,
+// cached for ttl like the status line since it asks the forges for the PRs.
+func runStatusJSON(ttl time.Duration) {
+	cfg := loadOptionalConfig()
+	applyUserNamespace(cfg)
+
+	const key = "json"
+	now := time.Now()
+	var cache state.StatusLines
+	if path, err := state.DefaultPath("status_lines.json"); err == nil {
+		cache = state.StatusLines{File: state.File{Path: path}}
+		if out, ok := cache.Get(key, ttl, now); ok {
+			fmt.Println(out)
+			return
+		}
+	}
+
+	runner := git.OSCommandRunner{}
+	profiles, _ := agent.ParseProfiles(cfg.Agents)
+	forgeOpts := forge.Options{
+		GitHubRunner: newGitHubRunner(cfg.GitHubToken, runner, exec.LookPath),
+		GitLabRunner: newGitLabRunner(exec.LookPath),
+		GitRunner:    runner,
+	}
+	report := statusline.CollectReport(runner, tmux.OSRunner{}, forgeOpts, profiles, cfg.Repositories, cmp.Or(cfg.DefaultBaseRef, config.DefaultBaseRef))
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if cache.File.Path != "" {
+		if err := cache.Set(key, string(data), now); err != nil {
+			logging.For("status").Warn("caching the report (non-fatal)", "err", err)
+		}
+	}
+	fmt.Println(string(data))
+}
+
+func runAudit() {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	op := fs.String("op", "", "only this operation: archive, restore, purge, rename, push or kill-session")
+	source := fs.String("source", "", "only operations started from: tui, cleanup, watcher or cli")
+	since := fs.String("since", "", "only operations after a date (2006-01-02) or within a duration (7d, 12h)")
+	asJSON := fs.Bool("json", false, "print as JSON lines")
+	fs.Parse(os.Args[2:])
+
+	applyUserNamespace(loadOptionalConfig())
+	filter := audit.Filter{Op: audit.Op(*op), Source: audit.Source(*source), Query: fs.Arg(0)}
+	if *since != "" {
+		t, err := parseSince(*since, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --since: %v\n", err)
+			os.Exit(2)
+		}
+		filter.Since = t
+	}
+
+	events, err := auditLog().Read(filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := printAudit(os.Stdout, events, *asJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// auditLog returns the audit file in the state directory, or a disabled log
+// when the directory cannot be resolved.
+func auditLog() audit.Log {
+	path, err := state.DefaultPath("audit.jsonl")
+	if err != nil {
+		logging.For("main").Warn("audit log disabled (non-fatal)", "err", err)
+		return audit.Log{}
+	}
+	return audit.Log{Path: path}
+}
+
+// parseSince parses a date like 2025-03-04 or a look-back like 7d or 12h.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("invalid number of days %q", value)
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("want a date, days (7d) or a duration (12h), got %q", value)
+	}
+	return now.Add(-d), nil
+}
+
+// printAudit writes events to w, one per line.
+func printAudit(w io.Writer, events []audit.Event, asJSON bool) error {
+	if len(events) == 0 && !asJSON {
+		_, err := fmt.Fprintln(w, "No matching operations.")
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		var err error
+		if asJSON {
+			err = enc.Encode(e)
+		} else {
+			_, err = fmt.Fprintln(w, e)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runDoctor() {
+	configPath, err := config.DefaultPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	env := doctor.Env{
+		LookPath:   exec.LookPath,
+		Run:        buildinfo.OSRunner,
+		ConfigPath: configPath,
+		LoadConfig: func(path string) (model.Config, error) {
+			cfg, err := config.LoadFromFile(path)
+			if err == nil {
+				applyUserNamespace(cfg)
+			}
+			return cfg, err
+		},
+	}
+	if _, err := exec.LookPath("tmux"); err == nil {
+		env.TmuxRunner = tmux.OSRunner{}
+	}
+
+	checks := doctor.Run(env)
+	if err := doctor.Write(os.Stdout, checks); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if doctor.Failed(checks) {
+		os.Exit(1)
+	}
+}
+
+func runVersion() {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print as JSON")
+	fs.Parse(os.Args[2:])
+
+	info := buildinfo.Collect(exec.LookPath, buildinfo.OSRunner)
+	write := info.WriteText
+	if *asJSON {
+		write = info.WriteJSON
+	}
+	if err := write(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runWatchRename() {
+	// The pane the watcher runs in shows its log too.
+	setupDebugLog(os.Stdout)
+
+	fs := flag.NewFlagSet("watch-rename", flag.ExitOnError)
+	wtPath := fs.String("path", "", "absolute path to the worktree (default: current directory)")
+	branch := fs.String("branch", "", "original branch name (default: current git branch)")
+	createdAtStr := fs.String("created-at", "", "unix millisecond timestamp or relative duration (e.g., 10m, 1h) (default: now)")
+	sessionName := fs.String("session-name", "", "tmux session name (default: current tmux session)")
+	fs.Parse(os.Args[2:])
+
+	userCfg := loadOptionalConfig()
+	logging.SetLevel(userCfg.LogLevel)
+	applyUserNamespace(userCfg)
+	runner := git.OSCommandRunner{}
+
+	var tmuxRunner tmux.Runner
+	if tmux.IsInsideTmux() {
+		tmuxRunner = tmux.OSRunner{}
+	}
+
+	resolved, err := resolveWatchRenameArgs(
+		*wtPath, *branch, *createdAtStr, *sessionName,
+		runner, tmuxRunner,
+		os.Getwd, func() int64 { return time.Now().UnixMilli() },
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		os.Exit(1)
+	}
+
+	claudePath, _ := exec.LookPath("claude")
+	gen := newBranchNamer(userCfg, claudePath)
+
+	reader := claude.OSReader{
+		HistoryPath: filepath.Join(home, ".claude", "history.jsonl"),
+	}
+
+	namer, _ := branchname.NewNamer(userCfg.BranchNamer)
+	cfg := rename.WatcherConfig{
+		Namer:        namer,
+		WorktreePath: resolved.wtPath,
+		Branch:       resolved.branch,
+		SessionName:  resolved.sessionName,
 		CreatedAt:    resolved.createdAt,
 		PollInterval: 2 * time.Second,
 		Timeout:      10 * time.Minute,
 	}
 
-	// Create logger that writes to both stdout (visible in tmux pane) and debug.log
-	logger := log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lmicroseconds)
-
+	logger := logging.For("branch-rename")
 	w := rename.NewWatcher(cfg, reader, gen, runner, tmuxRunner)
 	w.SetLogger(logger)
+	w.SetAudit(auditLog())
 	if err := w.Run(); err != nil {
-		logger.Printf("[branch-rename] watcher exited with error: %v", err)
+		logger.Error("watcher exited", "err", err)
 		os.Exit(1)
 	}
-	logger.Printf("[branch-rename] watcher completed successfully")
+	logger.Info("watcher completed successfully")
+}
+
+// newBranchNamer returns the generator branch_namer selects, falling back
+// to keywords of the prompt when a call fails. When the selected one cannot
+// be used at all, such as without its API key, the reason is logged and
+// branches are named from keywords alone.
+func newBranchNamer(cfg model.Config, claudePath string) branchname.Generator {
+	heuristic := branchname.HeuristicGenerator{MaxLength: cfg.BranchNamer.MaxLength}
+	gen, err := branchname.New(cfg.BranchNamer, claudePath)
+	if err != nil {
+		logging.For("branch-rename").Warn("naming branches from prompt keywords instead (non-fatal)", "err", err)
+		return heuristic
+	}
+	return branchname.Fallback{Primary: gen, Heuristic: heuristic}
 }
 
 // launchRenameWatcher sends the watch-rename command to a tmux pane via SendKeys.
@@ -461,9 +2016,41 @@ func launchRenameWatcher(runner tmux.Runner, paneID, worktreePath, branch, sessi
 	return tmux.SendKeys(runner, paneID, cmd)
 }
 
+// startDevLog pipes the output of paneID into the worktree's rotating
+// dev-server log via `yakumo devlog-write`.
+func startDevLog(runner tmux.Runner, paneID, worktreePath string) error {
+	logPath, err := devlog.Path(worktreePath)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolving executable: %w", err)
+	}
+	cmd := fmt.Sprintf("%s devlog-write %s", shellEscape(exe), shellEscape(logPath))
+	return tmux.PipePane(runner, paneID, cmd)
+}
+
+func runDevLogWrite() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "usage: yakumo devlog-write <file>")
+		os.Exit(2)
+	}
+	w, err := devlog.Open(os.Args[2], devlog.MaxBytes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	defer w.Close()
+	if _, err := io.Copy(w, os.Stdin); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
 // findIdleBackgroundPane returns the pane ID of an idle shell pane in the background window.
 func findIdleBackgroundPane(runner tmux.Runner, sessionName string) (string, error) {
-	target := sessionName + ":background-window"
+	target := tmux.WorktreeBackgroundTarget(sessionName)
 	out, err := runner.Run("list-panes", "-t", target, "-F", "#{pane_id}\t#{pane_current_command}")
 	if err != nil {
 		return "", fmt.Errorf("listing background panes: %w", err)
@@ -487,6 +2074,20 @@ func findIdleBackgroundPane(runner tmux.Runner, sessionName string) (string, err
 	return "", fmt.Errorf("no idle background pane found in session %s", sessionName)
 }
 
+// sendPaneCommands types the command the panes config gives each of the
+// named panes into it. Failures are logged and the other panes still start.
+func sendPaneCommands(runner tmux.Runner, layout tmux.SessionLayout, panes map[string]string, names ...string) {
+	for _, name := range names {
+		command := panes[name]
+		if command == "" {
+			continue
+		}
+		if err := tmux.SendKeys(runner, layout.PaneID(name), command); err != nil {
+			logging.For("setup").Error("pane command failed", "pane", name, "err", err)
+		}
+	}
+}
+
 // shellEscape wraps a string in single quotes for safe shell usage.
 func shellEscape(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
@@ -1,13 +1,23 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/mikanfactory/yakumo/internal/audit"
 	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/matrix"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/release"
+	"github.com/mikanfactory/yakumo/internal/search"
 	"github.com/mikanfactory/yakumo/internal/tmux"
+	"github.com/mikanfactory/yakumo/internal/trash"
 )
 
 func TestLaunchRenameWatcher(t *testing.T) {
@@ -300,3 +310,404 @@ func TestShellEscape(t *testing.T) {
 		}
 	}
 }
+
+func TestPopupCommand(t *testing.T) {
+	if got := popupCommand("/usr/local/bin/yakumo", "", ""); got != "'/usr/local/bin/yakumo'" {
+		t.Errorf("popupCommand = %q", got)
+	}
+	want := "'/opt/my tools/yakumo' --config '/home/me/.config/yakumo/work.yaml'"
+	if got := popupCommand("/opt/my tools/yakumo", "/home/me/.config/yakumo/work.yaml", ""); got != want {
+		t.Errorf("popupCommand = %q, want %q", got, want)
+	}
+	if got := popupCommand("/usr/local/bin/yakumo", "", "work"); got != "'/usr/local/bin/yakumo' --profile 'work'" {
+		t.Errorf("popupCommand = %q", got)
+	}
+}
+
+func TestNewGitHubRunner(t *testing.T) {
+	found := func(string) (string, error) { return "/usr/bin/gh", nil }
+	missing := func(string) (string, error) { return "", fmt.Errorf("not found") }
+
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+
+	if _, ok := newGitHubRunner("", git.OSCommandRunner{}, found).(github.OSRunner); !ok {
+		t.Error("expected gh CLI runner when gh is installed")
+	}
+	if _, ok := newGitHubRunner("token", git.OSCommandRunner{}, missing).(*github.APIRunner); !ok {
+		t.Error("expected API runner when gh is missing and a token exists")
+	}
+	if r := newGitHubRunner("", git.OSCommandRunner{}, missing); r != nil {
+		t.Errorf("expected nil runner without gh or token, got %T", r)
+	}
+}
+
+func TestGrepRepos(t *testing.T) {
+	cfg := model.Config{Repositories: []model.RepositoryDef{
+		{Name: "a", Path: "/code/a"},
+		{Name: "b", Path: "/code/b"},
+	}}
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/a-wt:[rev-parse --path-format=absolute --git-common-dir]": "/code/a/.git\n",
+		},
+	}
+
+	if got := grepRepos(cfg, runner, "/code/a-wt", false); len(got) != 1 || got[0].Name != "a" {
+		t.Errorf("inside repo a: got %v, want only a", got)
+	}
+	if got := grepRepos(cfg, runner, "/code/a-wt", true); len(got) != 2 {
+		t.Errorf("--all: got %v, want both repos", got)
+	}
+	if got := grepRepos(cfg, runner, "/elsewhere", false); len(got) != 2 {
+		t.Errorf("outside any repo: got %v, want both repos", got)
+	}
+}
+
+func TestWorktreeRepo(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	cfg := model.Config{
+		Repositories: []model.RepositoryDef{{Name: "a", Path: "/code/a", StartupCommand: "nvim"}},
+		Templates:    []model.WorktreeTemplate{{Name: "experiment", StartupCommand: "make sandbox"}},
+	}
+	store, ok := worktreeTemplates()
+	if !ok {
+		t.Fatal("worktree templates store unavailable")
+	}
+	if err := store.Set("/wt/chile", "experiment"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := worktreeRepo(cfg, "/code/a", "/wt/chile").StartupCommand; got != "make sandbox" {
+		t.Errorf("StartupCommand of a templated worktree = %q, want the template's", got)
+	}
+	if got := worktreeRepo(cfg, "/code/a", "/wt/peru").StartupCommand; got != "nvim" {
+		t.Errorf("StartupCommand of a plain worktree = %q, want the repository's", got)
+	}
+}
+
+func TestRepoNameOf(t *testing.T) {
+	cfg := model.Config{
+		WorktreeBasePath: "/home/u/yakumo",
+		Repositories: []model.RepositoryDef{
+			{Name: "api", Path: "/code/api-server"},
+			{Name: "web", Path: "/code/web"},
+		},
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/code/api-server", "api"},
+		{"/home/u/yakumo/web/fix-login", "web"},
+		{"/elsewhere/tools/fix-login", "tools"},
+	}
+	for _, tt := range tests {
+		if got := repoNameOf(cfg, tt.path); got != tt.want {
+			t.Errorf("repoNameOf(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPrintGrepResults(t *testing.T) {
+	results := []search.Result{
+		{
+			Target:    search.Target{Repo: "a", Branch: "feat", Path: "/code/a-feat"},
+			Matches:   []git.GrepMatch{{File: "main.go", Line: 3, Text: "\t// TODO"}},
+			Truncated: true,
+		},
+		{Target: search.Target{Repo: "a", Branch: "old", Path: "/code/a-old"}, Err: fmt.Errorf("boom")},
+	}
+
+	var b strings.Builder
+	if !printGrepResults(&b, results) {
+		t.Error("expected matched=true")
+	}
+	want := "a feat  /code/a-feat\n  main.go:3: // TODO\n  … more matches omitted\na old  /code/a-old\n  error: boom\n"
+	if b.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	if printGrepResults(&b, nil) {
+		t.Error("expected matched=false with no results")
+	}
+}
+
+func TestExecRepo(t *testing.T) {
+	cfg := model.Config{Repositories: []model.RepositoryDef{
+		{Name: "a", Path: "/code/a"},
+		{Name: "b", Path: "/code/b"},
+	}}
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/a-wt:[rev-parse --path-format=absolute --git-common-dir]": "/code/a/.git\n",
+		},
+	}
+
+	if repo, err := execRepo(cfg, runner, "/code/a-wt", "b"); err != nil || repo.Name != "b" {
+		t.Errorf("--repo b: got %v, %v", repo, err)
+	}
+	if repo, err := execRepo(cfg, runner, "/code/a-wt", ""); err != nil || repo.Name != "a" {
+		t.Errorf("inside repo a: got %v, %v", repo, err)
+	}
+	if _, err := execRepo(cfg, runner, "/code/a-wt", "c"); err == nil {
+		t.Error("expected an error for an unknown repository")
+	}
+	if _, err := execRepo(cfg, runner, "/elsewhere", ""); err == nil {
+		t.Error("expected an error outside any repository")
+	}
+}
+
+func TestPrintExecResults(t *testing.T) {
+	results := []matrix.Result{
+		{Target: matrix.Target{Branch: "main", Path: "/code/a"}, Elapsed: 1200 * time.Millisecond},
+		{
+			Target:  matrix.Target{Branch: "feat", Path: "/code/a-feat"},
+			Output:  "a.go:3: undefined: Foo\n",
+			Err:     fmt.Errorf("exit status 1"),
+			Elapsed: 800 * time.Millisecond,
+		},
+	}
+
+	var b strings.Builder
+	if printExecResults(&b, results) {
+		t.Error("expected a failure to be reported")
+	}
+	want := "PASS  main    1.2s  /code/a\n" +
+		"FAIL  feat   800ms  /code/a-feat\n" +
+		"\n--- feat (/code/a-feat): exit status 1\n" +
+		"a.go:3: undefined: Foo\n" +
+		"\n1 passed, 1 failed\n"
+	if b.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	b.Reset()
+	if !printExecResults(&b, results[:1]) {
+		t.Error("expected every worktree to pass")
+	}
+}
+
+func TestListWorktrees(t *testing.T) {
+	cfg := model.Config{Repositories: []model.RepositoryDef{
+		{Name: "a", Path: "/code/a"},
+		{Name: "b", Path: "/code/b"},
+	}}
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/a:[worktree list --porcelain]": "worktree /code/a\nbranch refs/heads/main\n\n" +
+				"worktree /wt/a/feat\nbranch refs/heads/alice/feat\n\n" +
+				"worktree /wt/a/detached\ndetached\n",
+		},
+	}
+
+	worktrees, err := listWorktrees(runner, cfg)
+	if err == nil || !strings.Contains(err.Error(), "listing worktrees of b") {
+		t.Errorf("err = %v, want repository b reported", err)
+	}
+	var b strings.Builder
+	if err := printWorktrees(&b, worktrees, false); err != nil {
+		t.Fatal(err)
+	}
+	want := "a  main        /code/a\n" +
+		"a  alice/feat  /wt/a/feat\n" +
+		"a  (detached)  /wt/a/detached\n"
+	if b.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	b.Reset()
+	if err := printWorktrees(&b, worktrees[:1], true); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []listedWorktree
+	if err := json.Unmarshal([]byte(b.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || !decoded[0].Primary || decoded[0].Branch != "main" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+	b.Reset()
+	if err := printWorktrees(&b, nil, true); err != nil || strings.TrimSpace(b.String()) != "[]" {
+		t.Errorf("no worktrees: %q, %v; want []", b.String(), err)
+	}
+}
+
+func TestRepoArg(t *testing.T) {
+	cfg := model.Config{Repositories: []model.RepositoryDef{{Name: "a", Path: "/code/a"}}}
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/wt/a/feat:[rev-parse --path-format=absolute --git-common-dir]": "/code/a/.git\n",
+		},
+	}
+
+	if repo, err := repoArg(cfg, runner, "a"); err != nil || repo.Path != "/code/a" {
+		t.Errorf("by name: got %v, %v", repo, err)
+	}
+	if repo, err := repoArg(cfg, runner, "/wt/a/feat"); err != nil || repo.Name != "a" {
+		t.Errorf("by path: got %v, %v", repo, err)
+	}
+	if _, err := repoArg(cfg, runner, "c"); err == nil {
+		t.Error("expected an error for an unknown repository")
+	}
+}
+
+func TestWorktreeArg(t *testing.T) {
+	cfg := model.Config{Repositories: []model.RepositoryDef{{Name: "a", Path: "/code/a"}}}
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/wt/a/feat/sub:[rev-parse --show-toplevel]":                         "/wt/a/feat\n",
+			"/wt/a/feat/sub:[rev-parse --path-format=absolute --git-common-dir]": "/code/a/.git\n",
+			"/other:[rev-parse --show-toplevel]":                                 "/other\n",
+			"/other:[rev-parse --path-format=absolute --git-common-dir]":         "/other/.git\n",
+		},
+	}
+
+	repo, path, err := worktreeArg(cfg, runner, "/wt/a/feat/sub")
+	if err != nil || repo.Name != "a" || path != "/wt/a/feat" {
+		t.Errorf("got %v, %q, %v; want repository a and the worktree root", repo, path, err)
+	}
+	if _, _, err := worktreeArg(cfg, runner, "/other"); err == nil || !strings.Contains(err.Error(), "configured repository") {
+		t.Errorf("err = %v, want an unconfigured repository error", err)
+	}
+	if _, _, err := worktreeArg(cfg, runner, "/nowhere"); err == nil {
+		t.Error("expected an error outside a git worktree")
+	}
+}
+
+func TestRebindKeys(t *testing.T) {
+	// Rebinding to the default keys keeps the shared keymaps unchanged.
+	err := rebindKeys(map[string]map[string]model.KeyList{
+		"sidebar":      {"archive": {"d"}},
+		"diff_changes": {"fixup": {"f"}},
+	})
+	if err != nil {
+		t.Errorf("modes of both UIs should be accepted: %v", err)
+	}
+	if err := rebindKeys(map[string]map[string]model.KeyList{"sidebar": {"fixup": {"f"}}}); err == nil {
+		t.Error("expected an error for an action of another mode")
+	}
+	if err := rebindKeys(map[string]map[string]model.KeyList{"changes": {"fixup": {"f"}}}); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestPromptBump(t *testing.T) {
+	plan := release.Plan{Base: "main", LastTag: "v1.4.0", Current: release.Version{Prefix: "v", Major: 1, Minor: 4}}
+	tests := []struct {
+		input   string
+		want    release.Bump
+		wantErr bool
+	}{
+		{input: "\n", want: release.BumpPatch},
+		{input: "2\n", want: release.BumpMinor},
+		{input: "major\n", want: release.BumpMajor},
+		{input: "4\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		got, err := promptBump(bufio.NewReader(strings.NewReader(tt.input)), &out, plan)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("input %q: got %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+		if !strings.Contains(out.String(), "2) minor  v1.5.0") {
+			t.Errorf("prompt should offer the next versions, got:\n%s", out.String())
+		}
+	}
+}
+
+func TestPromptYes(t *testing.T) {
+	for input, want := range map[string]bool{"y\n": true, "Yes\n": true, "\n": false, "n\n": false, "": false} {
+		var out strings.Builder
+		if got := promptYes(bufio.NewReader(strings.NewReader(input)), &out, "Tag?"); got != want {
+			t.Errorf("promptYes(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestSendPaneCommands(t *testing.T) {
+	runner := &tmux.FakeRunner{Outputs: map[string]string{
+		"[send-keys -t %2 npm run dev Enter]": "",
+		"[send-keys -t %5 make watch Enter]":  "",
+	}}
+	layout := tmux.SessionLayout{
+		BottomRight1: tmux.Pane{PaneID: "%2"},
+		Center2:      tmux.Pane{PaneID: "%3"},
+		BottomRight2: tmux.Pane{PaneID: "%5"},
+	}
+	panes := map[string]string{
+		"top_right":      "lazygit",
+		"bottom_right":   "npm run dev",
+		"bottom_right_2": "make watch",
+	}
+
+	sendPaneCommands(runner, layout, panes, "bottom_right", "center_2", "bottom_right_2")
+
+	if len(runner.Calls) != 2 {
+		t.Errorf("expected commands for bottom_right and bottom_right_2 only, got %v", runner.Calls)
+	}
+}
+
+func TestStartDevLog(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/state")
+	t.Setenv("USER", "alice")
+	runner := &tmux.FakeRunner{}
+
+	// The fake has no output for the call, so only the recorded call is checked.
+	_ = startDevLog(runner, "%7", "/code/repo-feat")
+
+	if len(runner.Calls) != 1 {
+		t.Fatalf("expected 1 tmux call, got %d", len(runner.Calls))
+	}
+	call := runner.Calls[0]
+	if call[0] != "pipe-pane" || call[3] != "%7" {
+		t.Errorf("expected pipe-pane on %%7, got %v", call)
+	}
+	if !strings.Contains(call[4], "devlog-write '/tmp/state/yakumo/users/alice/logs/code-repo-feat.log'") {
+		t.Errorf("unexpected pipe command %q", call[4])
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	var out strings.Builder
+	if err := purgeTrash(&out, git.FakeCommandRunner{}, trash.Trash{Dir: t.TempDir()}, audit.Log{}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Nothing to purge.\n" {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	for value, want := range map[string]time.Time{
+		"7d":  now.AddDate(0, 0, -7),
+		"12h": now.Add(-12 * time.Hour),
+	} {
+		if got, err := parseSince(value, now); err != nil || !got.Equal(want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if got, err := parseSince("2025-03-04", now); err != nil || got.Day() != 4 {
+		t.Errorf("date = %v, %v", got, err)
+	}
+	for _, bad := range []string{"soon", "-3d", "xd"} {
+		if _, err := parseSince(bad, now); err == nil {
+			t.Errorf("parseSince(%q) should fail", bad)
+		}
+	}
+}
+
+func TestPrintAudit(t *testing.T) {
+	var out strings.Builder
+	if err := printAudit(&out, nil, false); err != nil || out.String() != "No matching operations.\n" {
+		t.Errorf("empty = %q, %v", out.String(), err)
+	}
+
+	out.Reset()
+	events := []audit.Event{{Op: audit.OpPush, Source: audit.SourceCLI, Detail: "tag v1.0.0"}}
+	if err := printAudit(&out, events, true); err != nil || !strings.Contains(out.String(), `"op":"push"`) {
+		t.Errorf("json = %q, %v", out.String(), err)
+	}
+}
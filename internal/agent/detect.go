@@ -1,9 +1,7 @@
 package agent
 
 import (
-	"regexp"
 	"strings"
-	"unicode/utf8"
 
 	"github.com/mikanfactory/yakumo/internal/model"
 	"github.com/mikanfactory/yakumo/internal/tmux"
@@ -16,65 +14,6 @@ type PaneInfo struct {
 	CurrentCommand string
 }
 
-var (
-	// Version pattern: e.g. "2.1.34", "10.0.1"
-	versionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
-
-	// Running patterns (tcmux-compatible)
-	runningPattern          = regexp.MustCompile(`(?m)^[✢✽✶✻·]\s+.+?…?\s*\([^)]*·\s*((?:\d+[smh]\s*)+)`)
-	runningPatternTimeFirst = regexp.MustCompile(`(?m)^[✢✽✶✻·]\s+.+?…?\s*\(((?:\d+[smh]\s*)+)\s*·`)
-	runningFallbackPattern  = regexp.MustCompile(`(?m)^[✢✽✶✻·]\s+.+?…?\s*\((esc|ctrl\+c) to interrupt`)
-
-	// Waiting patterns
-	waitingPatterns = []string{
-		"Yes, allow once",
-		"Yes, allow always",
-		"Yes, don't ask again",
-		"Do you trust",
-		"Run this command?",
-		"Continue?",
-		"(Y/n)",
-		"(y/N)",
-		"[Y/n]",
-		"[y/N]",
-		"(yes/no)",
-	}
-
-	// Idle pattern
-	idlePattern = regexp.MustCompile(`(?m)^\s*❯`)
-)
-
-// isClaudeProcess returns true if the pane_current_command indicates Claude Code.
-func isClaudeProcess(command string) bool {
-	cmd := strings.ToLower(command)
-	if cmd == "node" || cmd == "claude" {
-		return true
-	}
-	return versionPattern.MatchString(command)
-}
-
-// isClaudeTitle returns true if the pane title starts with Claude Code indicators.
-func isClaudeTitle(title string) bool {
-	if title == "" {
-		return false
-	}
-	r, _ := utf8.DecodeRuneInString(title)
-	// ✳ U+2733 sparkle (idle indicator)
-	if r == '\u2733' {
-		return true
-	}
-	// Braille pattern range U+2800-U+28FF (spinner)
-	if r >= '\u2800' && r <= '\u28FF' {
-		return true
-	}
-	return false
-}
-
-// isClaude returns true if either title or process detection indicates Claude Code.
-func isClaude(info PaneInfo) bool {
-	return isClaudeTitle(info.PaneTitle) || isClaudeProcess(info.CurrentCommand)
-}
-
 // parseAllPanes parses the output of list-panes with tab-separated format.
 func parseAllPanes(output string) []PaneInfo {
 	var panes []PaneInfo
@@ -114,77 +53,93 @@ func lastNonEmptyLines(lines []string, n int) []string {
 	return result
 }
 
-// DetectState reads pane content via capture-pane and determines agent state.
-func DetectState(runner tmux.Runner, paneID string) (model.AgentState, string, error) {
-	out, err := runner.Run("capture-pane", "-p", "-t", paneID)
+// DetectState reads pane content via capture-pane and determines the state
+// of the agent profile describes.
+func DetectState(runner tmux.Runner, profile Profile, paneID string) (model.AgentState, string, error) {
+	out, err := tmux.Query(runner, "capture-pane", "-p", "-t", paneID)
 	if err != nil {
 		return model.AgentStateNone, "", err
 	}
 
 	lines := strings.Split(out, "\n")
 	meaningful := lastNonEmptyLines(lines, 30)
-	content := strings.Join(meaningful, "\n")
-
-	// Check running patterns (highest priority after modes)
-	if matches := runningPattern.FindStringSubmatch(content); len(matches) > 1 {
-		return model.AgentStateRunning, strings.TrimSpace(matches[1]), nil
-	}
-
-	if matches := runningPatternTimeFirst.FindStringSubmatch(content); len(matches) > 1 {
-		return model.AgentStateRunning, strings.TrimSpace(matches[1]), nil
-	}
-
-	if runningFallbackPattern.MatchString(content) {
-		return model.AgentStateRunning, "", nil
-	}
-
-	// Check waiting patterns
-	for _, pattern := range waitingPatterns {
-		if strings.Contains(content, pattern) {
-			return model.AgentStateWaiting, "", nil
-		}
-	}
-
-	// Check idle pattern
-	if idlePattern.MatchString(content) {
-		return model.AgentStateIdle, "", nil
-	}
-
-	return model.AgentStateNone, "", nil
+	state, elapsed := profile.state(strings.Join(meaningful, "\n"))
+	return state, elapsed, nil
 }
 
-// DetectSessionAgents checks all panes in a tmux session for Claude Code instances.
+// DetectSessionAgents checks all panes in a tmux session, or window in
+// window mode, for the agents of the default profiles.
 // Returns nil if the session does not exist.
 func DetectSessionAgents(runner tmux.Runner, sessionName string) ([]model.AgentInfo, error) {
-	exists, _ := tmux.HasSession(runner, sessionName)
+	exists, err := tmux.HasWorktree(runner, sessionName)
+	if err != nil {
+		return nil, err
+	}
 	if !exists {
 		return nil, nil
 	}
 
-	out, err := runner.Run("list-panes", "-s", "-t", sessionName, "-F", "#{pane_id}\t#{pane_title}\t#{pane_current_command}")
+	out, err := tmux.ListPanesOfWorktree(runner, sessionName, "#{pane_id}\t#{pane_title}\t#{pane_current_command}")
 	if err != nil {
 		return nil, err
 	}
 
-	panes := parseAllPanes(out)
+	return detectAgents(runner, defaultProfiles, parseAllPanes(out)), nil
+}
+
+// detectAgents reads the state of the agents among panes.
+func detectAgents(runner tmux.Runner, profiles []Profile, panes []PaneInfo) []model.AgentInfo {
 	var agents []model.AgentInfo
 
 	for _, pane := range panes {
-		if !isClaude(pane) {
+		profile, ok := matchProfile(profiles, pane)
+		if !ok {
 			continue
 		}
 
-		state, elapsed, err := DetectState(runner, pane.PaneID)
+		state, elapsed, err := DetectState(runner, profile, pane.PaneID)
 		if err != nil {
 			continue
 		}
 
 		agents = append(agents, model.AgentInfo{
 			PaneID:  pane.PaneID,
+			Agent:   profile.Name,
 			State:   state,
 			Elapsed: elapsed,
 		})
 	}
 
+	return agents
+}
+
+// DetectAllAgents checks every pane on the tmux server for the agents of
+// profiles, or of the built-in ones when nil, with one list-panes call rather
+// than a has-session and list-panes per session. The result is keyed by
+// session name, or window name in window mode, and holds every session, with
+// nil for those without agents, so it also tells which sessions exist.
+func DetectAllAgents(runner tmux.Runner, profiles []Profile) (map[string][]model.AgentInfo, error) {
+	if profiles == nil {
+		profiles = defaultProfiles
+	}
+
+	out, err := tmux.ListWorktreePanes(runner, "#{pane_id}\t#{pane_title}\t#{pane_current_command}")
+	if err != nil {
+		return nil, err
+	}
+
+	panes := make(map[string][]PaneInfo)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		session, rest, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		panes[session] = append(panes[session], parseAllPanes(rest)...)
+	}
+
+	agents := make(map[string][]model.AgentInfo, len(panes))
+	for session, sessionPanes := range panes {
+		agents[session] = detectAgents(runner, profiles, sessionPanes)
+	}
 	return agents, nil
 }
@@ -8,7 +8,9 @@ import (
 	"github.com/mikanfactory/yakumo/internal/tmux"
 )
 
-func TestIsClaudeProcess(t *testing.T) {
+var claudeProfile = defaultProfiles[0]
+
+func TestClaudeProfile_Process(t *testing.T) {
 	tests := []struct {
 		command string
 		want    bool
@@ -25,15 +27,15 @@ func TestIsClaudeProcess(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.command, func(t *testing.T) {
-			got := isClaudeProcess(tt.command)
+			got := claudeProfile.matchesProcess(tt.command)
 			if got != tt.want {
-				t.Errorf("isClaudeProcess(%q) = %v, want %v", tt.command, got, tt.want)
+				t.Errorf("matchesProcess(%q) = %v, want %v", tt.command, got, tt.want)
 			}
 		})
 	}
 }
 
-func TestIsClaudeTitle(t *testing.T) {
+func TestClaudeProfile_Title(t *testing.T) {
 	tests := []struct {
 		name  string
 		title string
@@ -50,15 +52,15 @@ func TestIsClaudeTitle(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isClaudeTitle(tt.title)
+			got := claudeProfile.matchesTitle(tt.title)
 			if got != tt.want {
-				t.Errorf("isClaudeTitle(%q) = %v, want %v", tt.title, got, tt.want)
+				t.Errorf("matchesTitle(%q) = %v, want %v", tt.title, got, tt.want)
 			}
 		})
 	}
 }
 
-func TestIsClaude(t *testing.T) {
+func TestMatchProfile_Claude(t *testing.T) {
 	tests := []struct {
 		name string
 		info PaneInfo
@@ -88,9 +90,9 @@ func TestIsClaude(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isClaude(tt.info)
-			if got != tt.want {
-				t.Errorf("isClaude(%+v) = %v, want %v", tt.info, got, tt.want)
+			p, got := matchProfile(defaultProfiles, tt.info)
+			if got != tt.want || (got && p.Name != "claude") {
+				t.Errorf("matchProfile(%+v) = %q, %v, want claude, %v", tt.info, p.Name, got, tt.want)
 			}
 		})
 	}
@@ -141,7 +143,7 @@ func TestDetectState_Running(t *testing.T) {
 		},
 	}
 
-	state, elapsed, err := DetectState(runner, "%0")
+	state, elapsed, err := DetectState(runner, claudeProfile, "%0")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -165,7 +167,7 @@ func TestDetectState_RunningTimeFirst(t *testing.T) {
 		},
 	}
 
-	state, elapsed, err := DetectState(runner, "%0")
+	state, elapsed, err := DetectState(runner, claudeProfile, "%0")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -189,7 +191,7 @@ func TestDetectState_RunningFallback(t *testing.T) {
 		},
 	}
 
-	state, _, err := DetectState(runner, "%0")
+	state, _, err := DetectState(runner, claudeProfile, "%0")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -229,7 +231,7 @@ func TestDetectState_Waiting(t *testing.T) {
 				},
 			}
 
-			state, _, err := DetectState(runner, "%0")
+			state, _, err := DetectState(runner, claudeProfile, "%0")
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -251,7 +253,7 @@ func TestDetectState_Idle(t *testing.T) {
 		},
 	}
 
-	state, _, err := DetectState(runner, "%0")
+	state, _, err := DetectState(runner, claudeProfile, "%0")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -350,3 +352,44 @@ func TestDetectSessionAgents_MultipleClaude(t *testing.T) {
 		t.Errorf("agent[1] State = %v, want Running", agents[1].State)
 	}
 }
+
+// listAll is the key of the list-panes call DetectAllAgents makes.
+var listAll = fmt.Sprintf("%v", []string{"list-panes", "-a", "-F", "#{session_name}\t#{pane_id}\t#{pane_title}\t#{pane_current_command}"})
+
+func TestDetectAllAgents(t *testing.T) {
+	runner := &tmux.FakeRunner{
+		Outputs: map[string]string{
+			listAll: "alpha\t%0\t✳ claude\tnode\nalpha\t%1\tbash\tbash\nbeta\t%2\tbash\tbash\n",
+			fmt.Sprintf("%v", []string{"capture-pane", "-p", "-t", "%0"}): "  ❯ ",
+		},
+	}
+
+	agents, err := DetectAllAgents(runner, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(agents) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(agents))
+	}
+	if len(agents["alpha"]) != 1 || agents["alpha"][0].PaneID != "%0" {
+		t.Errorf("alpha agents = %+v, want one on %%0", agents["alpha"])
+	}
+	if _, ok := agents["beta"]; !ok || len(agents["beta"]) != 0 {
+		t.Errorf("beta should be present without agents, got %+v", agents["beta"])
+	}
+	if len(runner.Calls) != 2 {
+		t.Errorf("expected list-panes and one capture-pane, got %v", runner.Calls)
+	}
+}
+
+func TestDetectAllAgents_NoServer(t *testing.T) {
+	runner := &tmux.FakeRunner{
+		Errors: map[string]error{
+			listAll: tmux.ErrNoServer,
+		},
+	}
+
+	if _, err := DetectAllAgents(runner, nil); !tmux.IsUnavailable(err) {
+		t.Errorf("err = %v, want tmux unavailable", err)
+	}
+}
@@ -0,0 +1,223 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// Profile is a compiled AgentProfileDef: how to spot one coding agent in a
+// pane and read its state from the pane's content.
+type Profile struct {
+	Name    string
+	Process *regexp.Regexp // nil matches no process
+	Title   *regexp.Regexp // nil matches no title
+	Running []*regexp.Regexp
+	Waiting []*regexp.Regexp
+	Prompt  *regexp.Regexp
+}
+
+// builtinDefs are the agents detected without any config. Claude Code comes
+// first so it keeps the node panes no other profile claims by title.
+var builtinDefs = []model.AgentProfileDef{
+	{
+		Name:      "claude",
+		Processes: []string{"node", "claude", `\d+\.\d+\.\d+`}, // the binary reports its version
+		Title:     `^[\x{2733}\x{2800}-\x{28FF}]`,              // ✳ when idle, a braille spinner otherwise
+		Running: []string{
+			`(?m)^[✢✽✶✻·]\s+.+?…?\s*\([^)]*·\s*(?P<elapsed>(?:\d+[smh]\s*)+)`,
+			`(?m)^[✢✽✶✻·]\s+.+?…?\s*\((?P<elapsed>(?:\d+[smh]\s*)+)\s*·`,
+			`(?m)^[✢✽✶✻·]\s+.+?…?\s*\((?:esc|ctrl\+c) to interrupt`,
+		},
+		Waiting: []string{
+			`Yes, allow once`,
+			`Yes, allow always`,
+			`Yes, don't ask again`,
+			`Do you trust`,
+			`Run this command\?`,
+			`Continue\?`,
+			`\(Y/n\)`,
+			`\(y/N\)`,
+			`\[Y/n\]`,
+			`\[y/N\]`,
+			`\(yes/no\)`,
+		},
+		Prompt: `(?m)^\s*❯`,
+	},
+	{
+		Name:      "aider",
+		Processes: []string{"aider"},
+		Running:   []string{`Waiting for \S+`},
+		Waiting:   []string{`\(Y\)es/\(N\)o`},
+		Prompt:    `(?m)^(?:\w+)?> ?$`,
+	},
+	{
+		Name:      "codex",
+		Processes: []string{"codex"},
+		Running: []string{
+			`(?m)^\s*\S?\s*Working \((?P<elapsed>(?:\d+[smh]\s*)+)`,
+			`esc to interrupt`,
+		},
+		Waiting: []string{`Allow command\?`, `Would you like to run`, `Yes, proceed`},
+		Prompt:  `(?m)^\s*[›▌]`,
+	},
+	{
+		Name:      "gemini",
+		Processes: []string{"gemini"},
+		Title:     `^(?:Gemini\b|[◇✦] )`,
+		Running:   []string{`\(esc to cancel, (?P<elapsed>(?:\d+[smh]\s*)+)\)`, `esc to cancel`},
+		Waiting:   []string{`Allow execution`, `Apply this change\?`, `Yes, allow once`},
+		Prompt:    `(?m)^\s*[│|]?\s*> `,
+	},
+	{
+		Name:      "opencode",
+		Processes: []string{"opencode"},
+		Running:   []string{`esc (?:to )?interrupt`},
+		Waiting:   []string{`Permission required`, `Allow once`},
+		Prompt:    `enter send`,
+	},
+}
+
+// defaultProfiles are the built-in profiles: Claude Code, aider, codex CLI,
+// gemini-cli and opencode.
+var defaultProfiles = mustParseProfiles(builtinDefs)
+
+// ParseProfiles compiles the agents config into the profiles to detect.
+// Configured profiles are tried before the built-in ones, and replace the
+// built-in profile of the same name.
+func ParseProfiles(defs []model.AgentProfileDef) ([]Profile, error) {
+	profiles := make([]Profile, 0, len(defs)+len(defaultProfiles))
+	for i, def := range defs {
+		p, err := parseProfile(def)
+		if err != nil {
+			return nil, fmt.Errorf("agents[%d]: %w", i, err)
+		}
+		profiles = append(profiles, p)
+	}
+	for _, p := range defaultProfiles {
+		if !slices.ContainsFunc(defs, func(d model.AgentProfileDef) bool { return d.Name == p.Name }) {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles, nil
+}
+
+func mustParseProfiles(defs []model.AgentProfileDef) []Profile {
+	profiles := make([]Profile, len(defs))
+	for i, def := range defs {
+		p, err := parseProfile(def)
+		if err != nil {
+			panic(err)
+		}
+		profiles[i] = p
+	}
+	return profiles
+}
+
+func parseProfile(def model.AgentProfileDef) (Profile, error) {
+	if def.Name == "" {
+		return Profile{}, fmt.Errorf("agent profile needs a name")
+	}
+	if len(def.Processes) == 0 && def.Title == "" {
+		return Profile{}, fmt.Errorf("agent %q: needs processes or a title to match panes by", def.Name)
+	}
+
+	p := Profile{Name: def.Name}
+	var err error
+	if len(def.Processes) > 0 {
+		if p.Process, err = compile(def.Name, "processes", `(?i)^(?:`+strings.Join(def.Processes, "|")+`)$`); err != nil {
+			return Profile{}, err
+		}
+	}
+	if p.Title, err = compileOptional(def.Name, "title", def.Title); err != nil {
+		return Profile{}, err
+	}
+	if p.Prompt, err = compileOptional(def.Name, "prompt", def.Prompt); err != nil {
+		return Profile{}, err
+	}
+	for _, pattern := range def.Running {
+		re, err := compile(def.Name, "running", pattern)
+		if err != nil {
+			return Profile{}, err
+		}
+		p.Running = append(p.Running, re)
+	}
+	for _, pattern := range def.Waiting {
+		re, err := compile(def.Name, "waiting", pattern)
+		if err != nil {
+			return Profile{}, err
+		}
+		p.Waiting = append(p.Waiting, re)
+	}
+	return p, nil
+}
+
+func compile(name, field, pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("agent %q: %s: %w", name, field, err)
+	}
+	return re, nil
+}
+
+func compileOptional(name, field, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return compile(name, field, pattern)
+}
+
+func (p Profile) matchesProcess(command string) bool {
+	return p.Process != nil && p.Process.MatchString(command)
+}
+
+func (p Profile) matchesTitle(title string) bool {
+	return p.Title != nil && title != "" && p.Title.MatchString(title)
+}
+
+// matchProfile returns the profile of the agent running in pane. Titles are
+// checked before processes, since several agents run as node.
+func matchProfile(profiles []Profile, pane PaneInfo) (Profile, bool) {
+	for _, p := range profiles {
+		if p.matchesTitle(pane.PaneTitle) {
+			return p, true
+		}
+	}
+	for _, p := range profiles {
+		if p.matchesProcess(pane.CurrentCommand) {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// state reads the agent's state from the last lines of its pane, with the
+// elapsed time when a running pattern captures one.
+func (p Profile) state(content string) (model.AgentState, string) {
+	for _, re := range p.Running {
+		matches := re.FindStringSubmatch(content)
+		if matches == nil {
+			continue
+		}
+		elapsed := ""
+		if i := re.SubexpIndex("elapsed"); i >= 0 {
+			elapsed = strings.TrimSpace(matches[i])
+		}
+		return model.AgentStateRunning, elapsed
+	}
+
+	for _, re := range p.Waiting {
+		if re.MatchString(content) {
+			return model.AgentStateWaiting, ""
+		}
+	}
+
+	if p.Prompt != nil && p.Prompt.MatchString(content) {
+		return model.AgentStateIdle, ""
+	}
+
+	return model.AgentStateNone, ""
+}
@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestMatchProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		pane PaneInfo
+		want string
+	}{
+		{"aider process", PaneInfo{PaneTitle: "zsh", CurrentCommand: "aider"}, "aider"},
+		{"codex process", PaneInfo{PaneTitle: "", CurrentCommand: "codex"}, "codex"},
+		{"opencode process", PaneInfo{PaneTitle: "", CurrentCommand: "opencode"}, "opencode"},
+		{"gemini by title over node", PaneInfo{PaneTitle: "Gemini - yakumo", CurrentCommand: "node"}, "gemini"},
+		{"plain node is claude", PaneInfo{PaneTitle: "yakumo", CurrentCommand: "node"}, "claude"},
+		{"no agent", PaneInfo{PaneTitle: "vim", CurrentCommand: "vim"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := matchProfile(defaultProfiles, tt.pane)
+			if p.Name != tt.want {
+				t.Errorf("matchProfile(%+v) = %q, want %q", tt.pane, p.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileState(t *testing.T) {
+	tests := []struct {
+		name        string
+		agent       string
+		content     string
+		wantState   model.AgentState
+		wantElapsed string
+	}{
+		{"aider waiting", "aider", "Add main.go to the chat? (Y)es/(N)o [Yes]:", model.AgentStateWaiting, ""},
+		{"aider idle", "aider", "Tokens: 2.1k sent\n> ", model.AgentStateIdle, ""},
+		{"codex running", "codex", "• Working (12s • esc to interrupt)", model.AgentStateRunning, "12s"},
+		{"codex idle", "codex", "› Ask Codex to do anything", model.AgentStateIdle, ""},
+		{"gemini running", "gemini", "⠋ Thinking... (esc to cancel, 1m 5s)", model.AgentStateRunning, "1m 5s"},
+		{"claude running", "claude", "✻ Reading… (esc to interrupt · 2m 30s)", model.AgentStateRunning, "2m 30s"},
+		{"nothing matches", "opencode", "some output", model.AgentStateNone, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := profileIndex(defaultProfiles, tt.agent)
+			if i < 0 {
+				t.Fatalf("no built-in profile %q", tt.agent)
+			}
+			state, elapsed := defaultProfiles[i].state(tt.content)
+			if state != tt.wantState || elapsed != tt.wantElapsed {
+				t.Errorf("state() = %v, %q, want %v, %q", state, elapsed, tt.wantState, tt.wantElapsed)
+			}
+		})
+	}
+}
+
+func TestParseProfiles(t *testing.T) {
+	defs := []model.AgentProfileDef{
+		{Name: "mycli", Processes: []string{"mycli"}, Prompt: `^mycli>`},
+		{Name: "aider", Processes: []string{"aider", "python3"}},
+	}
+
+	profiles, err := ParseProfiles(defs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(profiles) != len(defaultProfiles)+1 {
+		t.Fatalf("got %d profiles, want %d", len(profiles), len(defaultProfiles)+1)
+	}
+	if profiles[0].Name != "mycli" || profiles[1].Name != "aider" {
+		t.Errorf("configured profiles should come first, got %q, %q", profiles[0].Name, profiles[1].Name)
+	}
+	if !profiles[1].matchesProcess("python3") {
+		t.Error("configured aider profile should replace the built-in one")
+	}
+	if profileIndex(profiles[2:], "aider") >= 0 {
+		t.Error("built-in aider profile should be dropped")
+	}
+	if state, _ := profiles[0].state("mycli> "); state != model.AgentStateIdle {
+		t.Errorf("mycli state = %v, want Idle", state)
+	}
+}
+
+func TestParseProfiles_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		def  model.AgentProfileDef
+		want string
+	}{
+		{"no name", model.AgentProfileDef{Processes: []string{"x"}}, "needs a name"},
+		{"nothing to match", model.AgentProfileDef{Name: "x"}, "needs processes or a title"},
+		{"bad title", model.AgentProfileDef{Name: "x", Title: "("}, "title"},
+		{"bad running", model.AgentProfileDef{Name: "x", Processes: []string{"x"}, Running: []string{"["}}, "running"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseProfiles([]model.AgentProfileDef{tt.def})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func profileIndex(profiles []Profile, name string) int {
+	for i, p := range profiles {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
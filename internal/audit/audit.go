@@ -0,0 +1,180 @@
+// Package audit keeps an append-only record of the destructive operations
+// yakumo performs — archives, purges, pruned worktrees, branch renames, pushes and killed tmux
+// sessions — and what started them.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/logging"
+)
+
+// Op names a destructive operation.
+type Op string
+
+const (
+	OpArchive     Op = "archive"
+	OpRestore     Op = "restore"
+	OpPurge       Op = "purge"
+	OpPrune       Op = "prune"
+	OpRename      Op = "rename"
+	OpPush        Op = "push"
+	OpKillSession Op = "kill-session"
+)
+
+// Source names what initiated an operation.
+type Source string
+
+const (
+	SourceTUI     Source = "tui"     // a key pressed in the sidebar
+	SourceCleanup Source = "cleanup" // the bulk clean-up view
+	SourceWatcher Source = "watcher" // automatic branch naming from the first prompt
+	SourceCLI     Source = "cli"     // a yakumo subcommand
+)
+
+// Event is one line of the audit file.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Op     Op        `json:"op"`
+	Source Source    `json:"source"`
+	Repo   string    `json:"repo,omitempty"`
+	Path   string    `json:"path,omitempty"`
+	Branch string    `json:"branch,omitempty"`
+	Detail string    `json:"detail,omitempty"` // e.g. the new branch name
+	Err    string    `json:"error,omitempty"`  // set when the operation failed
+}
+
+// String formats e as one line for `yakumo audit`.
+func (e Event) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %-12s %-8s", e.Time.Local().Format("2006-01-02 15:04:05"), e.Op, e.Source)
+	for _, field := range []string{e.Branch, e.Detail, e.Path} {
+		if field != "" {
+			b.WriteString("  " + field)
+		}
+	}
+	if e.Err != "" {
+		b.WriteString("  FAILED: " + e.Err)
+	}
+	return b.String()
+}
+
+// Log is the audit file at Path. The zero value records nothing.
+type Log struct {
+	Path   string
+	Source Source // stamped on events that do not set their own
+}
+
+// From returns a copy of l that stamps events with source.
+func (l Log) From(source Source) Log {
+	l.Source = source
+	return l
+}
+
+// Record appends e, filling in the time and source when unset. opErr, when
+// non-nil, marks the operation as failed. The audit file never gets in the
+// way of the operation itself, so write failures are only logged.
+func (l Log) Record(e Event, opErr error) {
+	if l.Path == "" {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Source == "" {
+		e.Source = l.Source
+	}
+	if opErr != nil {
+		e.Err = opErr.Error()
+	}
+	if err := l.append(e); err != nil {
+		logging.For("audit").Warn("recording failed (non-fatal)", "op", e.Op, "err", err)
+	}
+}
+
+func (l Log) append(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0o755); err != nil {
+		return fmt.Errorf("creating audit directory: %w", err)
+	}
+	f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening audit file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing audit file: %w", err)
+	}
+	return nil
+}
+
+// Filter selects events. Empty fields match everything.
+type Filter struct {
+	Op     Op
+	Source Source
+	Since  time.Time
+	Query  string // substring of the repo, path, branch or detail
+}
+
+// Match reports whether e passes f.
+func (f Filter) Match(e Event) bool {
+	if f.Op != "" && e.Op != f.Op {
+		return false
+	}
+	if f.Source != "" && e.Source != f.Source {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if f.Query != "" {
+		for _, field := range []string{e.Repo, e.Path, e.Branch, e.Detail} {
+			if strings.Contains(field, f.Query) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Read returns the events matching f, oldest first. A missing file has no
+// events; lines that do not parse, such as one cut short by a crash, are
+// skipped.
+func (l Log) Read(f Filter) ([]Event, error) {
+	file, err := os.Open(l.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening audit file: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if f.Match(e) {
+			events = append(events, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading audit file: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	l := Log{Path: filepath.Join(t.TempDir(), "state", "audit.jsonl")}.From(SourceTUI)
+	day := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	l.Record(Event{Time: day, Op: OpArchive, Repo: "/code/app", Branch: "alice/login"}, nil)
+	l.Record(Event{Time: day.Add(time.Hour), Op: OpRename, Source: SourceWatcher, Branch: "alice/tmp", Detail: "alice/fix-login"}, nil)
+	l.Record(Event{Time: day.Add(2 * time.Hour), Op: OpArchive, Branch: "bob/wip"}, errors.New("contains modified files"))
+
+	all, err := l.Read(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Source != SourceTUI || all[1].Source != SourceWatcher || all[2].Err != "contains modified files" {
+		t.Fatalf("events = %+v", all)
+	}
+
+	for name, tt := range map[string]struct {
+		filter Filter
+		want   int
+	}{
+		"op":     {Filter{Op: OpArchive}, 2},
+		"source": {Filter{Source: SourceWatcher}, 1},
+		"since":  {Filter{Since: day.Add(90 * time.Minute)}, 1},
+		"query":  {Filter{Query: "alice"}, 2},
+		"none":   {Filter{Op: OpPush}, 0},
+	} {
+		got, err := l.Read(tt.filter)
+		if err != nil || len(got) != tt.want {
+			t.Errorf("%s: got %d events (%v), want %d", name, len(got), err, tt.want)
+		}
+	}
+}
+
+func TestRead_SkipsTornLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	data := `{"time":"2025-03-04T00:00:00Z","op":"purge","source":"cli"}` + "\n" + `{"time":"2025-03-0`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := Log{Path: path}.Read(Filter{})
+	if err != nil || len(events) != 1 || events[0].Op != OpPurge {
+		t.Errorf("events = %+v, %v", events, err)
+	}
+	if events, err := (Log{Path: filepath.Join(t.TempDir(), "missing")}).Read(Filter{}); err != nil || events != nil {
+		t.Errorf("missing file = %+v, %v", events, err)
+	}
+}
+
+func TestRecord_ZeroLogIsDisabled(t *testing.T) {
+	Log{}.Record(Event{Op: OpArchive}, nil) // must not panic or write anywhere
+}
+
+func TestEventString(t *testing.T) {
+	e := Event{Time: time.Now(), Op: OpRename, Source: SourceTUI, Branch: "a", Detail: "b", Err: "boom"}
+	s := e.String()
+	for _, want := range []string{"rename", "tui", "a  b", "FAILED: boom"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("String() = %q, missing %q", s, want)
+		}
+	}
+}
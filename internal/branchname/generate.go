@@ -1,6 +1,7 @@
 package branchname
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"os/exec"
@@ -17,18 +18,23 @@ type Generator interface {
 
 // CLIGenerator calls the claude CLI to generate branch names.
 type CLIGenerator struct {
-	ClaudePath string
+	ClaudePath   string
+	Model        string // "haiku" when empty
+	SystemPrompt string // the zero Namer's when empty
 }
 
-const systemPrompt = `You are a git branch name generator. Given a task description, generate a concise kebab-case branch name that summarizes the task.
+const systemPrompt = `You are a git branch name generator. Given a task description, generate a concise branch name that summarizes the task.
 
 Rules:
-- Use lowercase kebab-case (e.g., "fix-login-redirect", "add-user-settings")
-- Maximum 30 characters
+- %s
+- Maximum %d characters
 - No prefixes like "feature/" or "fix/" -- just the descriptive part
 - Output ONLY the branch name, nothing else
 - No quotes, no explanation, just the raw branch name`
 
+// kebabCase is the style rule of systemPrompt without a charset.
+const kebabCase = `Use lowercase kebab-case (e.g., "fix-login-redirect", "add-user-settings")`
+
 const maxBranchNameLength = 30
 
 var validBranchChar = regexp.MustCompile(`[^a-z0-9-]`)
@@ -40,11 +46,11 @@ func (g CLIGenerator) GenerateBranchName(prompt string) (string, error) {
 		claudePath = "claude"
 	}
 
-	fullPrompt := systemPrompt + "\n\nTask description:\n" + prompt
+	fullPrompt := instructions(g.SystemPrompt) + "\n\nTask description:\n" + prompt
 
 	cmd := exec.Command(claudePath, "-p", fullPrompt,
 		"--output-format", "text",
-		"--model", "haiku",
+		"--model", cmp.Or(g.Model, "haiku"),
 		"--no-session-persistence",
 	)
 
@@ -60,7 +66,15 @@ func (g CLIGenerator) GenerateBranchName(prompt string) (string, error) {
 		return "", fmt.Errorf("empty output from claude CLI")
 	}
 
-	return SanitizeBranchName(raw), nil
+	return raw, nil
+}
+
+// instructions returns systemPrompt, or the zero Namer's when it is empty.
+func instructions(systemPrompt string) string {
+	if systemPrompt == "" {
+		return Namer{}.SystemPrompt()
+	}
+	return systemPrompt
 }
 
 // filterEnv returns a copy of env with the specified key removed.
@@ -97,6 +111,11 @@ func SlugFromBranch(branch string) string {
 
 // SanitizeBranchName ensures the name is kebab-case, lowercase, and within the max length.
 func SanitizeBranchName(name string) string {
+	return sanitize(name, maxBranchNameLength)
+}
+
+// sanitize is SanitizeBranchName with a maximum length of limit.
+func sanitize(name string, limit int) string {
 	result := git.Slugify(name)
 
 	// Additional cleanup
@@ -104,8 +123,8 @@ func SanitizeBranchName(name string) string {
 	result = multiHyphen.ReplaceAllString(result, "-")
 	result = strings.Trim(result, "-")
 
-	if len(result) > maxBranchNameLength {
-		result = result[:maxBranchNameLength]
+	if len(result) > limit {
+		result = result[:limit]
 		result = strings.TrimRight(result, "-")
 	}
 
@@ -0,0 +1,102 @@
+package branchname
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// HeuristicGenerator names branches from the words of the prompt, without
+// an LLM: the first keywords left after dropping filler words, in kebab-case
+// and within MaxLength, or the usual length when it is 0.
+type HeuristicGenerator struct {
+	MaxLength int
+}
+
+func (g HeuristicGenerator) GenerateBranchName(prompt string) (string, error) {
+	if name := heuristicName(prompt, Namer{maxLength: g.MaxLength}.limit()); name != "" {
+		return name, nil
+	}
+	return "", fmt.Errorf("no keywords in the prompt")
+}
+
+// Fallback tries Primary and, when it fails or answers nothing usable,
+// names the branch with Heuristic instead.
+type Fallback struct {
+	Primary   Generator
+	Heuristic HeuristicGenerator
+}
+
+func (g Fallback) GenerateBranchName(prompt string) (string, error) {
+	name, err := g.Primary.GenerateBranchName(prompt)
+	if err == nil && name != "" {
+		return name, nil
+	}
+	if fallback, ferr := g.Heuristic.GenerateBranchName(prompt); ferr == nil {
+		return fallback, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("empty branch name")
+	}
+	return "", err
+}
+
+var (
+	urlPattern  = regexp.MustCompile(`\S+://\S+`)
+	wordPattern = regexp.MustCompile(`[a-z0-9]+`)
+)
+
+// stopwords are dropped from prompts: articles, pronouns, politeness and
+// other words that say nothing about the task.
+var stopwords = map[string]bool{}
+
+func init() {
+	// s, t, don and the like are what is left of let's, don't and so on.
+	for _, w := range strings.Fields(`
+		a an the this that these those it its
+		i me my we us our you your they them their he she his her
+		please pls can could would will shall should may might must
+		let need needs want wants like just also really very
+		is are was were be been being am do does did done have has had
+		to of in on at by for from with without into onto about as so
+		and or but if then than when where which who what how why
+		there here some any all each every more most other such
+		make sure ok okay hi hey thanks thank
+		s t don doesn didn isn aren won`) {
+		stopwords[w] = true
+	}
+}
+
+// HeuristicName returns a branch name from the keywords of prompt, or ""
+// when it has none, such as a prompt in a script other than Latin. Words
+// are kept whole: as many of the first ones as fit in the length limit.
+func HeuristicName(prompt string) string {
+	return heuristicName(prompt, maxBranchNameLength)
+}
+
+// heuristicName is HeuristicName with a length limit of limit.
+func heuristicName(prompt string, limit int) string {
+	text := urlPattern.ReplaceAllString(strings.ToLower(prompt), " ")
+	var words []string
+	length := 0
+	for _, w := range wordPattern.FindAllString(text, -1) {
+		if stopwords[w] || slices.Contains(words, w) {
+			continue
+		}
+		next := length + len(w)
+		if len(words) > 0 {
+			next++ // the hyphen
+		}
+		if next > limit {
+			if len(words) == 0 {
+				// A single long word is cut rather than lost.
+				return sanitize(w, limit)
+			}
+			break
+		}
+		words = append(words, w)
+		length = next
+	}
+	return sanitize(strings.Join(words, "-"), limit)
+}
@@ -0,0 +1,54 @@
+package branchname
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHeuristicName(t *testing.T) {
+	tests := []struct {
+		prompt, want string
+	}{
+		{"Fix the login redirect when the session expires", "fix-login-redirect-session"},
+		{"Could you please add a dark mode toggle to the settings page?", "add-dark-mode-toggle-settings"},
+		{"let's refactor the API client, don't touch the tests", "refactor-api-client-touch"},
+		{"see https://github.com/acme/app/issues/42 and fix the crash", "see-fix-crash"},
+		{"bump bump deps deps", "bump-deps"},
+		{"internationalizationandlocalizationframework", "internationalizationandlocaliz"},
+		{"ログインのバグを直して", ""},
+		{"please do it", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prompt, func(t *testing.T) {
+			if got := HeuristicName(tt.prompt); got != tt.want {
+				t.Errorf("HeuristicName(%q) = %q, want %q", tt.prompt, got, tt.want)
+			}
+			if got := HeuristicName(tt.prompt); len(got) > maxBranchNameLength {
+				t.Errorf("len = %d, over %d", len(got), maxBranchNameLength)
+			}
+		})
+	}
+}
+
+func TestFallback(t *testing.T) {
+	prompt := "fix the flaky upload test"
+
+	name, err := Fallback{Primary: FakeGenerator{Result: "from-llm"}}.GenerateBranchName(prompt)
+	if err != nil || name != "from-llm" {
+		t.Errorf("a working LLM should be used: %q, %v", name, err)
+	}
+
+	name, err = Fallback{Primary: FakeGenerator{Err: errors.New("timeout")}}.GenerateBranchName(prompt)
+	if err != nil || name != "fix-flaky-upload-test" {
+		t.Errorf("a failing LLM should fall back to keywords: %q, %v", name, err)
+	}
+
+	name, err = Fallback{Primary: FakeGenerator{}}.GenerateBranchName(prompt)
+	if err != nil || name != "fix-flaky-upload-test" {
+		t.Errorf("an empty answer should fall back to keywords: %q, %v", name, err)
+	}
+
+	if _, err := (Fallback{Primary: FakeGenerator{Err: errors.New("timeout")}}).GenerateBranchName("バグを直して"); err == nil || err.Error() != "timeout" {
+		t.Errorf("without keywords the LLM's error should be kept, got %v", err)
+	}
+}
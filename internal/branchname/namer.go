@@ -0,0 +1,199 @@
+package branchname
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// Prefix strategies for the branch_namer config.
+const (
+	PrefixKeep = "keep" // the part before the first "/" of the branch renamed, like the user name
+	PrefixNone = "none"
+)
+
+// DefaultTicketPattern finds ticket IDs such as PROJ-123 in a prompt.
+const DefaultTicketPattern = `\b[A-Z][A-Z0-9]+-[0-9]+\b`
+
+// NameData is what a branch_namer template can use.
+type NameData struct {
+	Slug   string // the generated name, sanitized
+	Prefix string // the prefix kept from the branch renamed, or empty
+	Ticket string // the first ticket ID in the prompt, or empty
+}
+
+// Namer turns the name a Generator gives into the branch a worktree is
+// renamed to, as branch_namer sets it up. The zero Namer gives the
+// defaults: a kebab-case slug of at most 30 characters after the original
+// branch's prefix.
+type Namer struct {
+	maxLength  int
+	charset    string
+	disallowed *regexp.Regexp // characters dropped from the slug; nil for SanitizeBranchName's
+	keepCase   bool
+	separator  string // between words: "-", or "_" when the charset has no "-"
+	dropPrefix bool
+	ticket     *regexp.Regexp
+	tmpl       *template.Template
+	prompt     string
+}
+
+// NewNamer checks the naming settings of cfg and prepares them.
+func NewNamer(cfg model.BranchNamerConfig) (Namer, error) {
+	var n Namer
+	if cfg.MaxLength < 0 {
+		return Namer{}, fmt.Errorf("branch_namer.max_length %d: must not be negative", cfg.MaxLength)
+	}
+	n.maxLength = cfg.MaxLength
+
+	if cfg.Charset != "" {
+		re, err := regexp.Compile("[^" + cfg.Charset + "]")
+		if err != nil {
+			return Namer{}, fmt.Errorf("branch_namer.charset %q: %w", cfg.Charset, err)
+		}
+		n.charset = cfg.Charset
+		n.disallowed = re
+		n.keepCase = !re.MatchString("A")
+		switch {
+		case !re.MatchString("-"):
+			n.separator = "-"
+		case !re.MatchString("_"):
+			n.separator = "_"
+		}
+	}
+
+	switch cfg.Prefix {
+	case "", PrefixKeep:
+	case PrefixNone:
+		n.dropPrefix = true
+	default:
+		return Namer{}, fmt.Errorf("branch_namer.prefix %q: must be %q or %q", cfg.Prefix, PrefixKeep, PrefixNone)
+	}
+
+	pattern := cfg.TicketPattern
+	if pattern == "" {
+		pattern = DefaultTicketPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Namer{}, fmt.Errorf("branch_namer.ticket_pattern %q: %w", cfg.TicketPattern, err)
+	}
+	n.ticket = re
+
+	if cfg.Template != "" {
+		t, err := template.New("branch_namer.template").Option("missingkey=error").Parse(cfg.Template)
+		if err != nil {
+			return Namer{}, fmt.Errorf("branch_namer.template: %w", err)
+		}
+		// Executed once so that unknown fields fail here rather than while
+		// renaming a branch.
+		var b strings.Builder
+		if err := t.Execute(&b, NameData{Slug: "slug", Prefix: "user", Ticket: "PROJ-1"}); err != nil {
+			return Namer{}, fmt.Errorf("branch_namer.template: %w", err)
+		}
+		if !strings.Contains(cfg.Template, ".Slug") {
+			return Namer{}, fmt.Errorf("branch_namer.template %q: must use {{.Slug}}", cfg.Template)
+		}
+		n.tmpl = t
+	}
+
+	n.prompt = cfg.Prompt
+	return n, nil
+}
+
+// SystemPrompt returns the instructions sent to the LLM before the task:
+// those of the config, or the built-in ones asking for a name in the
+// charset's style within the maximum length.
+func (n Namer) SystemPrompt() string {
+	if n.prompt != "" {
+		return n.prompt
+	}
+	return fmt.Sprintf(systemPrompt, n.style(), n.limit())
+}
+
+// style returns the rule of the built-in prompt on how to write the name.
+func (n Namer) style() string {
+	if n.disallowed == nil {
+		return kebabCase
+	}
+	example := strings.Join([]string{"fix", "login", "redirect"}, n.separator)
+	words := fmt.Sprintf("words joined with %q", n.separator)
+	if n.separator == "" {
+		words = "words written together"
+	}
+	letters := "only"
+	if !n.keepCase {
+		letters = "lowercase and only"
+	}
+	return fmt.Sprintf("Use %s the characters [%s], %s (e.g., %q)", letters, n.charset, words, example)
+}
+
+func (n Namer) limit() int {
+	if n.maxLength == 0 {
+		return maxBranchNameLength
+	}
+	return n.maxLength
+}
+
+// Slug sanitizes a generated name: kebab-case, or words joined the way the
+// charset allows, with only the allowed characters and within the maximum
+// length. Whitespace, "-", "_" and runs of characters outside the charset
+// all end a word, so "fix-login" keeps its two words under a charset
+// without "-".
+func (n Namer) Slug(raw string) string {
+	if n.disallowed == nil {
+		return sanitize(raw, n.limit())
+	}
+	s := strings.TrimSpace(raw)
+	if !n.keepCase {
+		s = strings.ToLower(s)
+	}
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '-' || r == '_' || n.disallowed.MatchString(string(r))
+	})
+	s = strings.Join(words, n.separator)
+	if r := []rune(s); len(r) > n.limit() {
+		s = string(r[:n.limit()])
+		if n.separator != "" {
+			s = strings.TrimRight(s, n.separator)
+		}
+	}
+	return s
+}
+
+// BranchName returns the new name of originalBranch from the generated name
+// raw and the prompt it was generated from.
+func (n Namer) BranchName(originalBranch, prompt, raw string) (string, error) {
+	slug := n.Slug(raw)
+	if slug == "" {
+		return "", fmt.Errorf("generated branch name is empty")
+	}
+	data := NameData{Slug: slug}
+	if parts := strings.SplitN(originalBranch, "/", 2); len(parts) == 2 && !n.dropPrefix {
+		data.Prefix = parts[0]
+	}
+	if n.ticket != nil {
+		data.Ticket = n.ticket.FindString(prompt)
+	}
+
+	if n.tmpl == nil {
+		// Preserve username prefix: "shoji/south-korea" -> "shoji/fix-login"
+		if data.Prefix != "" {
+			return data.Prefix + "/" + slug, nil
+		}
+		return slug, nil
+	}
+	var b strings.Builder
+	if err := n.tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("branch_namer.template: %w", err)
+	}
+	name := strings.Trim(strings.TrimSpace(b.String()), "-/")
+	if name == "" {
+		return "", fmt.Errorf("branch_namer.template gives an empty branch name")
+	}
+	return name, nil
+}
@@ -0,0 +1,105 @@
+package branchname
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestNamer_BranchName(t *testing.T) {
+	tests := []struct {
+		name                  string
+		cfg                   model.BranchNamerConfig
+		original, prompt, raw string
+		want                  string
+	}{
+		{"default", model.BranchNamerConfig{}, "shoji/south-korea", "fix the login", "Fix Login", "shoji/fix-login"},
+		{"default without prefix", model.BranchNamerConfig{}, "south-korea", "fix the login", "fix-login", "fix-login"},
+		{"prefix none", model.BranchNamerConfig{Prefix: PrefixNone}, "shoji/south-korea", "fix the login", "fix-login", "fix-login"},
+		{"ticket", model.BranchNamerConfig{Template: "{{with .Ticket}}{{.}}-{{end}}{{.Slug}}", Prefix: PrefixNone},
+			"shoji/south-korea", "PROJ-123: fix the login", "fix-login", "PROJ-123-fix-login"},
+		{"no ticket in the prompt", model.BranchNamerConfig{Template: "{{with .Ticket}}{{.}}-{{end}}{{.Slug}}"},
+			"shoji/south-korea", "fix the login", "fix-login", "fix-login"},
+		{"template with prefix", model.BranchNamerConfig{Template: "{{.Prefix}}/feat/{{.Slug}}"},
+			"shoji/south-korea", "add a toggle", "dark-mode", "shoji/feat/dark-mode"},
+		{"ticket pattern", model.BranchNamerConfig{Template: "{{.Ticket}}/{{.Slug}}", TicketPattern: `#[0-9]+`},
+			"south-korea", "fix #42 now", "fix-crash", "#42/fix-crash"},
+		{"max length", model.BranchNamerConfig{MaxLength: 10}, "south-korea", "", "add-user-settings-page", "add-user-s"},
+		{"charset", model.BranchNamerConfig{Charset: "A-Za-z0-9_"}, "south-korea", "", "Fix Login Redirect!", "Fix_Login_Redirect"},
+		{"lowercase charset", model.BranchNamerConfig{Charset: "a-z-"}, "south-korea", "", "Fix -- Login 2", "fix-login"},
+		{"kebab under underscore charset", model.BranchNamerConfig{Charset: "a-z0-9_"}, "south-korea", "", "fix-login-redirect", "fix_login_redirect"},
+		{"underscores under kebab charset", model.BranchNamerConfig{Charset: "a-z0-9-"}, "south-korea", "", "fix_login__redirect", "fix-login-redirect"},
+		{"disallowed run", model.BranchNamerConfig{Charset: "a-z_"}, "south-korea", "", "fix.login/v2-redirect", "fix_login_v_redirect"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewNamer(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := n.BranchName(tt.original, tt.prompt, tt.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("BranchName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamer_BranchNameEmpty(t *testing.T) {
+	if _, err := (Namer{}).BranchName("shoji/south-korea", "", "!!!"); err == nil {
+		t.Error("an empty slug should be an error")
+	}
+}
+
+func TestNamer_SystemPrompt(t *testing.T) {
+	n, err := NewNamer(model.BranchNamerConfig{MaxLength: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := n.SystemPrompt(); !strings.Contains(got, "Maximum 50 characters") {
+		t.Errorf("SystemPrompt = %q, want the max length in it", got)
+	}
+
+	n, err = NewNamer(model.BranchNamerConfig{Charset: "a-z0-9_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := n.SystemPrompt(); strings.Contains(got, "kebab-case") || !strings.Contains(got, `"fix_login_redirect"`) || !strings.Contains(got, "[a-z0-9_]") {
+		t.Errorf("SystemPrompt = %q, want the charset and \"_\" in it instead of kebab-case", got)
+	}
+
+	n, err = NewNamer(model.BranchNamerConfig{Prompt: "Name the branch in snake_case."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := n.SystemPrompt(); got != "Name the branch in snake_case." {
+		t.Errorf("SystemPrompt = %q, want the configured prompt", got)
+	}
+}
+
+func TestNewNamer_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  model.BranchNamerConfig
+		want string
+	}{
+		{"max length", model.BranchNamerConfig{MaxLength: -1}, "branch_namer.max_length"},
+		{"charset", model.BranchNamerConfig{Charset: "z-a"}, "branch_namer.charset"},
+		{"prefix", model.BranchNamerConfig{Prefix: "always"}, "branch_namer.prefix"},
+		{"ticket pattern", model.BranchNamerConfig{TicketPattern: "(["}, "branch_namer.ticket_pattern"},
+		{"template syntax", model.BranchNamerConfig{Template: "{{.Slug"}, "branch_namer.template"},
+		{"template field", model.BranchNamerConfig{Template: "{{.Issue}}-{{.Slug}}"}, "branch_namer.template"},
+		{"template without slug", model.BranchNamerConfig{Template: "{{.Ticket}}"}, "{{.Slug}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewNamer(tt.cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want one about %s", err, tt.want)
+			}
+		})
+	}
+}
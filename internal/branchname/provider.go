@@ -0,0 +1,193 @@
+package branchname
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// Providers of branch names for the branch_namer config.
+const (
+	ProviderClaude = "claude"
+	ProviderOpenAI = "openai"
+	ProviderOllama = "ollama"
+)
+
+// Defaults for the providers reached over HTTP.
+const (
+	DefaultOpenAIEndpoint = "https://api.openai.com/v1"
+	DefaultOpenAIModel    = "gpt-4o-mini"
+	DefaultOpenAIKeyEnv   = "OPENAI_API_KEY"
+	DefaultOllamaEndpoint = "http://localhost:11434"
+	DefaultOllamaModel    = "llama3.2"
+)
+
+// requestTimeout bounds one request to an HTTP provider.
+const requestTimeout = 30 * time.Second
+
+// ParseProvider validates a branch_namer provider from the config. The empty
+// string selects ProviderClaude.
+func ParseProvider(s string) (string, bool) {
+	switch s {
+	case "", ProviderClaude:
+		return ProviderClaude, true
+	case ProviderOpenAI, ProviderOllama:
+		return s, true
+	}
+	return "", false
+}
+
+// New returns the generator cfg selects, asking with the Namer's system
+// prompt. claudePath is the claude CLI, or empty when it is not installed;
+// only ProviderClaude needs it.
+func New(cfg model.BranchNamerConfig, claudePath string) (Generator, error) {
+	provider, ok := ParseProvider(cfg.Provider)
+	if !ok {
+		return nil, fmt.Errorf("branch_namer.provider %q: must be %q, %q or %q", cfg.Provider, ProviderClaude, ProviderOpenAI, ProviderOllama)
+	}
+	namer, err := NewNamer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	system := namer.SystemPrompt()
+	client := &http.Client{Timeout: requestTimeout}
+	switch provider {
+	case ProviderOpenAI:
+		keyEnv := cmp.Or(cfg.APIKeyEnv, DefaultOpenAIKeyEnv)
+		key := os.Getenv(keyEnv)
+		// A local OpenAI-compatible server may need no key, but the
+		// default endpoint does.
+		if key == "" && (cfg.Endpoint == "" || cfg.APIKeyEnv != "") {
+			return nil, fmt.Errorf("branch_namer: $%s is not set", keyEnv)
+		}
+		return OpenAIGenerator{
+			Endpoint:     cmp.Or(cfg.Endpoint, DefaultOpenAIEndpoint),
+			Model:        cmp.Or(cfg.Model, DefaultOpenAIModel),
+			APIKey:       key,
+			SystemPrompt: system,
+			Client:       client,
+		}, nil
+	case ProviderOllama:
+		return OllamaGenerator{
+			Endpoint:     cmp.Or(cfg.Endpoint, DefaultOllamaEndpoint),
+			Model:        cmp.Or(cfg.Model, DefaultOllamaModel),
+			SystemPrompt: system,
+			Client:       client,
+		}, nil
+	}
+	if claudePath == "" {
+		return nil, fmt.Errorf("branch_namer: the claude CLI is not installed")
+	}
+	return CLIGenerator{ClaudePath: claudePath, Model: cfg.Model, SystemPrompt: system}, nil
+}
+
+// OpenAIGenerator asks an OpenAI-compatible chat completions API, which
+// llama.cpp, LM Studio and vLLM serve locally too.
+type OpenAIGenerator struct {
+	Endpoint string // base URL, e.g. https://api.openai.com/v1
+	Model    string
+	APIKey   string // sent as a bearer token when set
+	// SystemPrompt is the zero Namer's when empty.
+	SystemPrompt string
+	Client       *http.Client
+}
+
+func (g OpenAIGenerator) GenerateBranchName(prompt string) (string, error) {
+	body := map[string]any{
+		"model": g.Model,
+		"messages": []map[string]string{
+			{"role": "system", "content": instructions(g.SystemPrompt)},
+			{"role": "user", "content": "Task description:\n" + prompt},
+		},
+	}
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := postJSON(g.Client, strings.TrimSuffix(g.Endpoint, "/")+"/chat/completions", g.APIKey, body, &resp); err != nil {
+		return "", fmt.Errorf("openai: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("openai: no choices in the response")
+	}
+	return trimOutput(resp.Choices[0].Message.Content, "openai")
+}
+
+// OllamaGenerator asks a local Ollama server.
+type OllamaGenerator struct {
+	Endpoint string // base URL, e.g. http://localhost:11434
+	Model    string
+	// SystemPrompt is the zero Namer's when empty.
+	SystemPrompt string
+	Client       *http.Client
+}
+
+func (g OllamaGenerator) GenerateBranchName(prompt string) (string, error) {
+	body := map[string]any{
+		"model":  g.Model,
+		"stream": false,
+		"messages": []map[string]string{
+			{"role": "system", "content": instructions(g.SystemPrompt)},
+			{"role": "user", "content": "Task description:\n" + prompt},
+		},
+	}
+	var resp struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	if err := postJSON(g.Client, strings.TrimSuffix(g.Endpoint, "/")+"/api/chat", "", body, &resp); err != nil {
+		return "", fmt.Errorf("ollama: %w", err)
+	}
+	return trimOutput(resp.Message.Content, "ollama")
+}
+
+// postJSON posts body to url as JSON and decodes the response into out.
+func postJSON(client *http.Client, url, apiKey string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("parsing the response: %w", err)
+	}
+	return nil
+}
+
+// trimOutput returns the model's answer, which the Namer sanitizes.
+func trimOutput(raw, provider string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("empty output from %s", provider)
+	}
+	return raw, nil
+}
@@ -0,0 +1,159 @@
+package branchname
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// chatRequest is the part of a chat request both APIs share.
+type chatRequest struct {
+	Model    string `json:"model"`
+	Stream   *bool  `json:"stream"`
+	Messages []struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"messages"`
+}
+
+func decodeChat(t *testing.T, r *http.Request) chatRequest {
+	t.Helper()
+	var req chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		t.Errorf("decoding request: %v", err)
+	}
+	return req
+}
+
+func TestOpenAIGenerator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", got)
+		}
+		req := decodeChat(t, r)
+		if req.Model != "gpt-test" || len(req.Messages) != 2 || !strings.Contains(req.Messages[1].Content, "fix the login redirect") {
+			t.Errorf("request = %+v", req)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":" Fix Login Redirect\n"}}]}`))
+	}))
+	defer srv.Close()
+
+	gen := OpenAIGenerator{Endpoint: srv.URL + "/v1/", Model: "gpt-test", APIKey: "sk-test", Client: srv.Client()}
+	name, err := gen.GenerateBranchName("fix the login redirect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "Fix Login Redirect" {
+		t.Errorf("name = %q, want the trimmed answer", name)
+	}
+}
+
+func TestOpenAIGenerator_Errors(t *testing.T) {
+	tests := []struct {
+		name, body string
+		status     int
+		want       string
+	}{
+		{"status", `{}`, http.StatusUnauthorized, "401"},
+		{"no choices", `{"choices":[]}`, http.StatusOK, "no choices"},
+		{"empty answer", `{"choices":[{"message":{"content":"  "}}]}`, http.StatusOK, "empty output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			gen := OpenAIGenerator{Endpoint: srv.URL, Model: "m", Client: srv.Client()}
+			if _, err := gen.GenerateBranchName("task"); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want one with %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOllamaGenerator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("ollama takes no API key")
+		}
+		req := decodeChat(t, r)
+		if req.Model != "llama-test" || req.Stream == nil || *req.Stream {
+			t.Errorf("request = %+v, want a single response", req)
+		}
+		w.Write([]byte(`{"message":{"role":"assistant","content":"add-user-settings"},"done":true}`))
+	}))
+	defer srv.Close()
+
+	gen := OllamaGenerator{Endpoint: srv.URL, Model: "llama-test", Client: srv.Client()}
+	name, err := gen.GenerateBranchName("add a settings page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "add-user-settings" {
+		t.Errorf("name = %q", name)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("WORK_LLM_KEY", "sk-work")
+	system := Namer{}.SystemPrompt()
+
+	tests := []struct {
+		name    string
+		cfg     model.BranchNamerConfig
+		claude  string
+		want    Generator
+		wantErr string
+	}{
+		{"claude by default", model.BranchNamerConfig{}, "/usr/bin/claude", CLIGenerator{ClaudePath: "/usr/bin/claude", SystemPrompt: system}, ""},
+		{"claude missing", model.BranchNamerConfig{}, "", nil, "not installed"},
+		{"claude model", model.BranchNamerConfig{Provider: "claude", Model: "sonnet"}, "/usr/bin/claude", CLIGenerator{ClaudePath: "/usr/bin/claude", Model: "sonnet", SystemPrompt: system}, ""},
+		{"openai without a key", model.BranchNamerConfig{Provider: "openai"}, "", nil, "$OPENAI_API_KEY is not set"},
+		{"openai key env", model.BranchNamerConfig{Provider: "openai", APIKeyEnv: "WORK_LLM_KEY"}, "",
+			OpenAIGenerator{Endpoint: DefaultOpenAIEndpoint, Model: DefaultOpenAIModel, APIKey: "sk-work", SystemPrompt: system}, ""},
+		{"local openai-compatible server", model.BranchNamerConfig{Provider: "openai", Endpoint: "http://localhost:8080/v1", Model: "qwen"}, "",
+			OpenAIGenerator{Endpoint: "http://localhost:8080/v1", Model: "qwen", SystemPrompt: system}, ""},
+		{"ollama", model.BranchNamerConfig{Provider: "ollama"}, "", OllamaGenerator{Endpoint: DefaultOllamaEndpoint, Model: DefaultOllamaModel, SystemPrompt: system}, ""},
+		{"unknown", model.BranchNamerConfig{Provider: "gemini"}, "", nil, "branch_namer.provider"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen, err := New(tt.cfg, tt.claude)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want one with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The HTTP client is not compared.
+			switch g := gen.(type) {
+			case OpenAIGenerator:
+				g.Client = nil
+				gen = g
+			case OllamaGenerator:
+				g.Client = nil
+				gen = g
+			}
+			if gen != tt.want {
+				t.Errorf("New = %+v, want %+v", gen, tt.want)
+			}
+		})
+	}
+}
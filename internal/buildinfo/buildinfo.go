@@ -0,0 +1,160 @@
+// Package buildinfo reports the yakumo version and the external tools it
+// found on this machine, for `yakumo version` and bug reports.
+package buildinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Set at link time, e.g.
+//
+//	go build -ldflags "-X github.com/mikanfactory/yakumo/internal/buildinfo.Version=v0.3.0"
+//
+// Empty values fall back to the VCS data Go embeds in the binary.
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// Integration is an external tool yakumo talks to.
+type Integration struct {
+	Name    string `json:"name"`
+	Found   bool   `json:"found"`
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// Info is everything `yakumo version` prints.
+type Info struct {
+	Version      string        `json:"version"`
+	Commit       string        `json:"commit"`
+	Date         string        `json:"date"`
+	GoVersion    string        `json:"go_version"`
+	Platform     string        `json:"platform"`
+	Integrations []Integration `json:"integrations"`
+}
+
+// Tools lists the integrations probed by Collect with the flag that prints
+// their version.
+var Tools = []struct {
+	Name        string
+	VersionFlag string
+}{
+	{"git", "--version"},
+	{"tmux", "-V"},
+	{"gh", "--version"},
+	{"glab", "--version"},
+	{"claude", "--version"},
+}
+
+// Runner runs a tool and returns its stdout.
+type Runner func(name string, args ...string) (string, error)
+
+// OSRunner runs the tool via os/exec.
+func OSRunner(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).Output()
+	return string(out), err
+}
+
+// Collect gathers the build metadata and probes every tool in Tools.
+// A tool that is installed but fails to report a version is still Found.
+func Collect(lookPath func(string) (string, error), run Runner) Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	fillFromBuildInfo(&info)
+
+	for _, tool := range Tools {
+		integration := Integration{Name: tool.Name}
+		if path, err := lookPath(tool.Name); err == nil {
+			integration.Found = true
+			integration.Path = path
+			if out, err := run(path, tool.VersionFlag); err == nil {
+				integration.Version = firstLine(out)
+			}
+		}
+		info.Integrations = append(info.Integrations, integration)
+	}
+	return info
+}
+
+// fillFromBuildInfo fills fields not set via -ldflags from the module version
+// and VCS stamps recorded by the Go toolchain.
+func fillFromBuildInfo(info *Info) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = s.Value
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
+
+// WriteText prints the info in a human-readable layout suitable for pasting
+// into a bug report.
+func (i Info) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "yakumo %s\n", i.Version)
+	fmt.Fprintf(&b, "  commit:   %s\n", orUnknown(i.Commit))
+	fmt.Fprintf(&b, "  built:    %s\n", orUnknown(i.Date))
+	fmt.Fprintf(&b, "  go:       %s\n", i.GoVersion)
+	fmt.Fprintf(&b, "  platform: %s\n", i.Platform)
+	b.WriteString("\nIntegrations:\n")
+	for _, in := range i.Integrations {
+		switch {
+		case !in.Found:
+			fmt.Fprintf(&b, "  %-7s not found\n", in.Name)
+		case in.Version == "":
+			fmt.Fprintf(&b, "  %-7s %s (version unknown)\n", in.Name, in.Path)
+		default:
+			fmt.Fprintf(&b, "  %-7s %s (%s)\n", in.Name, in.Version, in.Path)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON prints the info as indented JSON.
+func (i Info) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(i)
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
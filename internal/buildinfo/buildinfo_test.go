@@ -0,0 +1,114 @@
+package buildinfo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func fakeLookPath(found ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, f := range found {
+			if f == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", fmt.Errorf("%s: not found", name)
+	}
+}
+
+func fakeRun(outputs map[string]string) Runner {
+	return func(name string, args ...string) (string, error) {
+		key := fmt.Sprintf("%s %v", name, args)
+		if out, ok := outputs[key]; ok {
+			return out, nil
+		}
+		return "", fmt.Errorf("no output for %q", key)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	run := fakeRun(map[string]string{
+		"/usr/bin/tmux [-V]":          "tmux 3.4\n",
+		"/usr/bin/gh [--version]":     "gh version 2.40.0 (2023-12-13)\nhttps://github.com/cli/cli/releases/tag/v2.40.0\n",
+		"/usr/bin/claude [--version]": "", // installed, prints nothing useful
+	})
+
+	info := Collect(fakeLookPath("tmux", "gh", "claude"), run)
+
+	if info.Version == "" {
+		t.Error("Version should fall back to a non-empty value")
+	}
+	tests := []struct {
+		name    string
+		found   bool
+		version string
+	}{
+		{"tmux", true, "tmux 3.4"},
+		{"gh", true, "gh version 2.40.0 (2023-12-13)"},
+		{"claude", true, ""},
+		{"glab", false, ""},
+	}
+	probed := map[string]Integration{}
+	for _, in := range info.Integrations {
+		probed[in.Name] = in
+	}
+	for _, tt := range tests {
+		in, ok := probed[tt.name]
+		if !ok {
+			t.Fatalf("integration %q missing", tt.name)
+		}
+		if in.Found != tt.found || in.Version != tt.version {
+			t.Errorf("%s = {Found: %v, Version: %q}, want {Found: %v, Version: %q}", tt.name, in.Found, in.Version, tt.found, tt.version)
+		}
+	}
+}
+
+func TestCollect_LinkerValues(t *testing.T) {
+	Version, Commit, Date = "v1.2.3", "abc123", "2025-01-02"
+	t.Cleanup(func() { Version, Commit, Date = "", "", "" })
+
+	info := Collect(fakeLookPath(), fakeRun(nil))
+	if info.Version != "v1.2.3" || info.Commit != "abc123" || info.Date != "2025-01-02" {
+		t.Errorf("got %q %q %q, want linker-provided values", info.Version, info.Commit, info.Date)
+	}
+}
+
+func TestInfo_WriteText(t *testing.T) {
+	info := Info{
+		Version: "v1.2.3",
+		Integrations: []Integration{
+			{Name: "tmux", Found: true, Path: "/usr/bin/tmux", Version: "tmux 3.4"},
+			{Name: "gh", Found: false},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := info.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"yakumo v1.2.3", "commit:   unknown", "tmux 3.4 (/usr/bin/tmux)", "gh      not found"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestInfo_WriteJSON(t *testing.T) {
+	info := Info{Version: "v1.2.3", Integrations: []Integration{{Name: "gh"}}}
+
+	var buf bytes.Buffer
+	if err := info.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Info
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Version != "v1.2.3" || len(decoded.Integrations) != 1 || decoded.Integrations[0].Found {
+		t.Errorf("round trip = %+v", decoded)
+	}
+}
@@ -0,0 +1,56 @@
+package buildinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/mikanfactory/yakumo/internal/release"
+)
+
+// LatestReleaseURL is the GitHub API endpoint of the newest yakumo release.
+const LatestReleaseURL = "https://api.github.com/repos/mikanfactory/yakumo/releases/latest"
+
+// CurrentVersion returns the version of this binary as `yakumo version`
+// prints it: "dev" for a build that is not a release.
+func CurrentVersion() string {
+	info := Info{Version: Version}
+	fillFromBuildInfo(&info)
+	return info.Version
+}
+
+// LatestRelease asks url, shaped like LatestReleaseURL, for the tag of the
+// newest release.
+func LatestRelease(client *http.Client, url string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("checking for a new release: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("checking for a new release: %s", resp.Status)
+	}
+	var body struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("parsing the latest release: %w", err)
+	}
+	return body.TagName, nil
+}
+
+// Newer reports whether latest is a later release than current. Builds
+// that are not releases, like "dev", are never behind.
+func Newer(current, latest string) bool {
+	cur, ok := release.ParseVersion(current)
+	if !ok {
+		return false
+	}
+	next, ok := release.ParseVersion(latest)
+	return ok && cur.Less(next)
+}
@@ -0,0 +1,52 @@
+package buildinfo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLatestRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/releases/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"tag_name": "v0.5.0", "name": "v0.5.0"}`))
+	}))
+	defer server.Close()
+
+	tag, err := LatestRelease(server.Client(), server.URL+"/releases/latest")
+	if err != nil || tag != "v0.5.0" {
+		t.Errorf("LatestRelease = %q, %v; want v0.5.0", tag, err)
+	}
+	if _, err := LatestRelease(server.Client(), server.URL+"/missing"); err == nil {
+		t.Error("a 404 should be an error")
+	}
+}
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            bool
+	}{
+		{"v0.4.0", "v0.5.0", true},
+		{"v0.5.0", "v0.5.0", false},
+		{"v0.6.0", "v0.5.0", false},
+		{"dev", "v0.5.0", false},
+		{"v0.4.0", "nightly", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.current, tt.latest); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestCurrentVersion(t *testing.T) {
+	Version = "v1.2.3"
+	t.Cleanup(func() { Version = "" })
+	if got := CurrentVersion(); got != "v1.2.3" {
+		t.Errorf("CurrentVersion = %q, want the linker-provided version", got)
+	}
+}
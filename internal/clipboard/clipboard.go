@@ -0,0 +1,59 @@
+// Package clipboard reads the system clipboard through whichever platform
+// tool is installed.
+package clipboard
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnavailable is returned when no clipboard tool is installed.
+var ErrUnavailable = errors.New("no clipboard tool available")
+
+// Reader abstracts clipboard access for testability.
+type Reader interface {
+	Read() (string, error)
+}
+
+// OSReader reads the clipboard with pbpaste on macOS, and wl-paste, xclip or
+// xsel elsewhere, using the first one found on PATH.
+type OSReader struct{}
+
+// candidates lists clipboard commands in order of preference for goos.
+func candidates(goos string) [][]string {
+	if goos == "darwin" {
+		return [][]string{{"pbpaste"}}
+	}
+	return [][]string{
+		{"wl-paste", "--no-newline"},
+		{"xclip", "-selection", "clipboard", "-o"},
+		{"xsel", "--clipboard", "--output"},
+	}
+}
+
+func (r OSReader) Read() (string, error) {
+	for _, args := range candidates(runtime.GOOS) {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		out, err := exec.Command(path, args[1:]...).Output()
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	return "", ErrUnavailable
+}
+
+// FakeReader is a test double.
+type FakeReader struct {
+	Text string
+	Err  error
+}
+
+func (r FakeReader) Read() (string, error) {
+	return r.Text, r.Err
+}
@@ -0,0 +1,13 @@
+package clipboard
+
+import "testing"
+
+func TestCandidates(t *testing.T) {
+	if got := candidates("darwin"); len(got) != 1 || got[0][0] != "pbpaste" {
+		t.Errorf("darwin candidates = %v, want pbpaste", got)
+	}
+	linux := candidates("linux")
+	if len(linux) != 3 || linux[0][0] != "wl-paste" {
+		t.Errorf("linux candidates = %v, want wl-paste first", linux)
+	}
+}
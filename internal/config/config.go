@@ -1,15 +1,34 @@
 package config
 
 import (
+	"bytes"
+	"cmp"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v3"
 
+	"github.com/mikanfactory/yakumo/internal/agent"
+	"github.com/mikanfactory/yakumo/internal/branchname"
+	"github.com/mikanfactory/yakumo/internal/forge"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/logging"
 	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/notify"
+	"github.com/mikanfactory/yakumo/internal/ports"
+	"github.com/mikanfactory/yakumo/internal/seed"
+	"github.com/mikanfactory/yakumo/internal/tasks"
+	"github.com/mikanfactory/yakumo/internal/theme"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+	"github.com/mikanfactory/yakumo/internal/wip"
 )
 
 const DefaultSidebarWidth = 30
@@ -18,18 +37,249 @@ const DefaultBaseRef = "origin/main"
 // MaxRbCommands is the maximum number of rb_commands per repository.
 const MaxRbCommands = 3
 
-// LoadFromFile reads and parses a YAML config file.
+// DefaultAgentPollInterval is how often the sidebar polls tmux for agent
+// status without an agent_poll_interval setting.
+const DefaultAgentPollInterval = 500 * time.Millisecond
+
+// minAgentPollInterval keeps agent_poll_interval from flooding tmux.
+const minAgentPollInterval = 100 * time.Millisecond
+
+// ParseAgentPollInterval parses an agent_poll_interval value such as "2s".
+// The empty string selects DefaultAgentPollInterval.
+func ParseAgentPollInterval(s string) (time.Duration, error) {
+	if s == "" {
+		return DefaultAgentPollInterval, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("agent_poll_interval %q: must be a duration such as \"2s\"", s)
+	}
+	if d < minAgentPollInterval {
+		return 0, fmt.Errorf("agent_poll_interval %q: must be at least %s", s, minAgentPollInterval)
+	}
+	return d, nil
+}
+
+// fileNames are the config files looked for in ~/.config/yakumo, in order
+// of precedence.
+var fileNames = []string{"config.yaml", "config.toml", "config.json"}
+
+// Config file formats, chosen by the file's extension.
+const (
+	formatYAML = "yaml"
+	formatTOML = "toml"
+	formatJSON = "json"
+)
+
+// formatOf returns the format of the config file at path: TOML for .toml,
+// JSON for .json and YAML otherwise.
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return formatTOML
+	case ".json":
+		return formatJSON
+	}
+	return formatYAML
+}
+
+// decode parses data in format into cfg. TOML and JSON are converted to YAML
+// first, so all three share the yaml keys of model.Config and its YAML
+// unmarshalers, such as a key list given as a single key.
+func decode(format string, data []byte, cfg *model.Config) error {
+	var v map[string]any
+	switch format {
+	case formatTOML:
+		if err := toml.Unmarshal(data, &v); err != nil {
+			return err
+		}
+	case formatJSON:
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+	default:
+		return yaml.Unmarshal(data, cfg)
+	}
+	converted, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(converted, cfg)
+}
+
+// editRepositories rewrites the config file at path with add appended to
+// its repositories and the one at remove, if any, left out. Nothing else
+// changes: the file is not decoded into a model.Config, which would write
+// back defaults and expanded paths, and a YAML file is edited node by node
+// so that its comments stay.
+func editRepositories(path string, add *model.RepositoryDef, remove string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	if format := formatOf(path); format == formatYAML {
+		data, err = editYAMLRepositories(data, add, remove)
+	} else {
+		data, err = editMapRepositories(format, data, add, remove)
+	}
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	return nil
+}
+
+func editYAMLRepositories(data []byte, add *model.RepositoryDef, remove string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the config is not a mapping")
+	}
+	var repos *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "repositories" {
+			repos = root.Content[i+1]
+		}
+	}
+	if repos == nil {
+		repos = &yaml.Node{}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "repositories"}, repos)
+	}
+	if repos.Kind != yaml.SequenceNode {
+		// A missing or empty `repositories:`.
+		*repos = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+
+	if remove != "" {
+		repos.Content = slices.DeleteFunc(repos.Content, func(n *yaml.Node) bool {
+			var repo model.RepositoryDef
+			return n.Decode(&repo) == nil && filepath.Clean(repo.Path) == filepath.Clean(remove)
+		})
+	}
+	if add != nil {
+		var n yaml.Node
+		if err := n.Encode(map[string]string{"name": add.Name, "path": add.Path}); err != nil {
+			return nil, err
+		}
+		repos.Content = append(repos.Content, &n)
+	}
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// editMapRepositories edits a TOML or JSON config as a generic map, which
+// keeps its values as written though not its comments or key order.
+func editMapRepositories(format string, data []byte, add *model.RepositoryDef, remove string) ([]byte, error) {
+	v := map[string]any{}
+	var err error
+	if format == formatTOML {
+		err = toml.Unmarshal(data, &v)
+	} else {
+		err = json.Unmarshal(data, &v)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []any
+	switch list := v["repositories"].(type) {
+	case []any:
+		repos = list
+	case []map[string]any: // TOML's array of tables
+		for _, repo := range list {
+			repos = append(repos, repo)
+		}
+	}
+	if remove != "" {
+		repos = slices.DeleteFunc(repos, func(repo any) bool {
+			m, _ := repo.(map[string]any)
+			path, _ := m["path"].(string)
+			return filepath.Clean(path) == filepath.Clean(remove)
+		})
+	}
+	if add != nil {
+		repos = append(repos, map[string]any{"name": add.Name, "path": add.Path})
+	}
+	v["repositories"] = repos
+
+	if format == formatJSON {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+	var b bytes.Buffer
+	if err := toml.NewEncoder(&b).Encode(v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// LoadFromFile reads and parses a config file in the format formatOf gives.
 func LoadFromFile(path string) (model.Config, error) {
+	return loadFile(path, true)
+}
+
+// LoadFromEnv builds the config from the environment alone, as when
+// $YAKUMO_REPOSITORIES is set and there is no config file.
+func LoadFromEnv() (model.Config, error) {
+	return parse(formatYAML, nil, true)
+}
+
+// loadFile reads and parses the config file at path, with the files it
+// includes, the environment overrides and the repositories found under
+// scan_paths when resolve is set. Without them it is the file alone, as
+// needed to write it back.
+func loadFile(path string, resolve bool) (model.Config, error) {
+	if resolve {
+		data, err := readMerged(path)
+		if err != nil {
+			return model.Config{}, err
+		}
+		return parse(formatYAML, data, true)
+	}
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return model.Config{}, fmt.Errorf("reading config file: %w", err)
 	}
 
+	return parse(formatOf(path), data, false)
+}
+
+// parse decodes data, applies the environment overrides and discovers the
+// repositories under scan_paths when resolve is set, applies the defaults,
+// and validates the result.
+func parse(format string, data []byte, resolve bool) (model.Config, error) {
 	var cfg model.Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if err := decode(format, data, &cfg); err != nil {
 		return model.Config{}, fmt.Errorf("parsing config file: %w", err)
 	}
 
+	if resolve {
+		if err := applyEnv(&cfg); err != nil {
+			return model.Config{}, err
+		}
+	}
+
 	if cfg.SidebarWidth == 0 {
 		cfg.SidebarWidth = DefaultSidebarWidth
 	}
@@ -60,22 +310,262 @@ func LoadFromFile(path string) (model.Config, error) {
 		cfg.WorktreeBasePath = filepath.Join(home, cfg.WorktreeBasePath[2:])
 	}
 
-	for _, repo := range cfg.Repositories {
+	for i, repo := range cfg.Repositories {
+		if strings.HasPrefix(repo.WorktreeBasePath, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return model.Config{}, fmt.Errorf("expanding home directory: %w", err)
+			}
+			cfg.Repositories[i].WorktreeBasePath = filepath.Join(home, repo.WorktreeBasePath[2:])
+		}
+	}
+
+	for i, path := range cfg.ScanPaths {
+		if strings.HasPrefix(path, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return model.Config{}, fmt.Errorf("expanding home directory: %w", err)
+			}
+			cfg.ScanPaths[i] = filepath.Join(home, path[2:])
+		}
+	}
+
+	if strings.HasPrefix(cfg.TrashDir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return model.Config{}, fmt.Errorf("expanding home directory: %w", err)
+		}
+		cfg.TrashDir = filepath.Join(home, cfg.TrashDir[2:])
+	}
+
+	cfg.SessionPrefix = strings.TrimSuffix(os.ExpandEnv(cfg.SessionPrefix), "/")
+	if strings.ContainsAny(cfg.SessionPrefix, ":.") {
+		return model.Config{}, fmt.Errorf("session_prefix %q: tmux session names cannot contain ':' or '.'", cfg.SessionPrefix)
+	}
+
+	if _, ok := tmux.ParseMode(cfg.TmuxMode); !ok {
+		return model.Config{}, fmt.Errorf("tmux_mode %q: must be %q or %q", cfg.TmuxMode, tmux.ModeSession, tmux.ModeWindow)
+	}
+
+	if cfg.SessionNameTemplate != "" {
+		if _, err := tmux.ParseSessionTemplate(cfg.SessionNameTemplate); err != nil {
+			return model.Config{}, fmt.Errorf("session_name_template %q: %w", cfg.SessionNameTemplate, err)
+		}
+	}
+
+	if cfg.PortBase < 0 || cfg.PortBase > 65535 {
+		return model.Config{}, fmt.Errorf("port_base %d: must be a port between 1 and 65535", cfg.PortBase)
+	}
+	if cfg.PortBlockSize < 0 {
+		return model.Config{}, fmt.Errorf("port_block_size %d: must not be negative", cfg.PortBlockSize)
+	}
+	if cfg.PortBase > 0 {
+		if cfg.PortBlockSize == 0 {
+			cfg.PortBlockSize = ports.DefaultBlockSize
+		}
+		if cfg.PortBase+cfg.PortBlockSize-1 > 65535 {
+			return model.Config{}, fmt.Errorf("port_base %d: a block of %d ports does not fit below 65536", cfg.PortBase, cfg.PortBlockSize)
+		}
+	}
+
+	if _, ok := git.ParseDiffBase(cfg.DiffBase); !ok {
+		return model.Config{}, fmt.Errorf("diff_base %q: must be %q or %q", cfg.DiffBase, git.DiffBaseMergeBase, git.DiffBaseRef)
+	}
+
+	if _, ok := wip.ParseMode(cfg.AutoWIP); !ok {
+		return model.Config{}, fmt.Errorf("auto_wip %q: must be %q or %q", cfg.AutoWIP, wip.ModeStash, wip.ModeCommit)
+	}
+
+	if _, err := logging.ParseLevel(cfg.LogLevel); err != nil {
+		return model.Config{}, fmt.Errorf("log_level: %w", err)
+	}
+
+	if _, err := theme.Resolve(cfg.Theme); err != nil {
+		return model.Config{}, err
+	}
+
+	if _, err := ParseAgentPollInterval(cfg.AgentPollInterval); err != nil {
+		return model.Config{}, err
+	}
+
+	if _, err := agent.ParseProfiles(cfg.Agents); err != nil {
+		return model.Config{}, err
+	}
+
+	if _, err := notify.ParseChannels(cfg.Notifications.Waiting); err != nil {
+		return model.Config{}, fmt.Errorf("notifications.waiting: %w", err)
+	}
+	if _, err := notify.ParseChannels(cfg.Notifications.Idle); err != nil {
+		return model.Config{}, fmt.Errorf("notifications.idle: %w", err)
+	}
+
+	if _, ok := branchname.ParseProvider(cfg.BranchNamer.Provider); !ok {
+		return model.Config{}, fmt.Errorf("branch_namer.provider %q: must be %q, %q or %q",
+			cfg.BranchNamer.Provider, branchname.ProviderClaude, branchname.ProviderOpenAI, branchname.ProviderOllama)
+	}
+	if _, err := branchname.NewNamer(cfg.BranchNamer); err != nil {
+		return model.Config{}, err
+	}
+
+	for i, repo := range cfg.Repositories {
+		if slices.ContainsFunc(cfg.Repositories[:i], func(r model.RepositoryDef) bool { return r.Name == repo.Name }) {
+			return model.Config{}, fmt.Errorf("repository %q: defined twice", repo.Name)
+		}
+		kind, err := forge.ParseKind(repo.Forge)
+		if err != nil {
+			return model.Config{}, fmt.Errorf("repository %q: %w", repo.Name, err)
+		}
+		cfg.Repositories[i].Forge = kind
 		if len(repo.RbCommands) > MaxRbCommands {
 			return model.Config{}, fmt.Errorf(
 				"repository %q: rb_commands has %d entries, max is %d",
 				repo.Name, len(repo.RbCommands), MaxRbCommands,
 			)
 		}
+		for name, command := range repo.Tasks {
+			if err := tasks.ValidateName(name); err != nil {
+				return model.Config{}, fmt.Errorf("repository %q: tasks: %w", repo.Name, err)
+			}
+			if strings.TrimSpace(command) == "" {
+				return model.Config{}, fmt.Errorf("repository %q: tasks: %q has no command", repo.Name, name)
+			}
+		}
+		for name := range repo.Panes {
+			if !slices.Contains(tmux.PaneNames, name) {
+				return model.Config{}, fmt.Errorf(
+					"repository %q: panes: unknown pane %q, must be one of %s",
+					repo.Name, name, strings.Join(tmux.PaneNames, ", "),
+				)
+			}
+		}
+		if _, err := tmux.ExpandEnv(repo.Env, tmux.SessionNameData{Repo: repo.Name, Slug: "slug"}); err != nil {
+			return model.Config{}, fmt.Errorf("repository %q: env: %w", repo.Name, err)
+		}
+		if err := seed.Validate(repo.CopyOnCreate); err != nil {
+			return model.Config{}, fmt.Errorf("repository %q: copy_on_create: %w", repo.Name, err)
+		}
+		if err := seed.Validate(repo.SymlinkOnCreate); err != nil {
+			return model.Config{}, fmt.Errorf("repository %q: symlink_on_create: %w", repo.Name, err)
+		}
 	}
 
-	if len(cfg.Repositories) == 0 {
+	for i, tmpl := range cfg.Templates {
+		if tmpl.Name == "" {
+			return model.Config{}, fmt.Errorf("templates[%d]: name is required", i)
+		}
+		if slices.ContainsFunc(cfg.Templates[:i], func(t model.WorktreeTemplate) bool { return t.Name == tmpl.Name }) {
+			return model.Config{}, fmt.Errorf("template %q: defined twice", tmpl.Name)
+		}
+		if err := validateTemplate(tmpl); err != nil {
+			return model.Config{}, fmt.Errorf("template %q: %w", tmpl.Name, err)
+		}
+	}
+
+	if len(cfg.Repositories) == 0 && !hasOtherRepositories(cfg) {
 		return model.Config{}, fmt.Errorf("config must have at least one repository")
 	}
 
+	if resolve && len(cfg.ScanPaths) > 0 {
+		discoverRepositories(&cfg)
+		if len(cfg.Repositories) == 0 {
+			return model.Config{}, fmt.Errorf("config must have at least one repository, and none were found under scan_paths")
+		}
+	}
+
 	return cfg, nil
 }
 
+// hasOtherRepositories reports whether repositories may come from elsewhere
+// than the repositories of cfg: scan_paths or included files.
+func hasOtherRepositories(cfg model.Config) bool {
+	return len(cfg.ScanPaths) > 0 || len(cfg.Include) > 0 || len(cfg.Profiles) > 0
+}
+
+func validateTemplate(tmpl model.WorktreeTemplate) error {
+	if tmpl.BranchPrefix != "" && branchname.SanitizeBranchName(tmpl.BranchPrefix) != tmpl.BranchPrefix {
+		return fmt.Errorf("branch_prefix %q: must be lowercase letters, digits and hyphens", tmpl.BranchPrefix)
+	}
+	for name := range tmpl.Panes {
+		if !slices.Contains(tmux.PaneNames, name) {
+			return fmt.Errorf("panes: unknown pane %q, must be one of %s", name, strings.Join(tmux.PaneNames, ", "))
+		}
+	}
+	if err := seed.Validate(tmpl.CopyOnCreate); err != nil {
+		return fmt.Errorf("copy_on_create: %w", err)
+	}
+	if err := seed.Validate(tmpl.SymlinkOnCreate); err != nil {
+		return fmt.Errorf("symlink_on_create: %w", err)
+	}
+	return nil
+}
+
+// WorktreeBase returns the directory new worktrees of the repository at
+// repoPath go under: its own worktree_base_path, or the top-level one.
+func WorktreeBase(cfg model.Config, repoPath string) string {
+	for _, repo := range cfg.Repositories {
+		if filepath.Clean(repo.Path) == filepath.Clean(repoPath) && repo.WorktreeBasePath != "" {
+			return repo.WorktreeBasePath
+		}
+	}
+	return cfg.WorktreeBasePath
+}
+
+// BaseRef returns the ref worktrees of the repository at repoPath branch off
+// and are compared with: its own base_ref, the top-level default_base_ref,
+// or DefaultBaseRef.
+func BaseRef(cfg model.Config, repoPath string) string {
+	for _, repo := range cfg.Repositories {
+		if filepath.Clean(repo.Path) == filepath.Clean(repoPath) && repo.BaseRef != "" {
+			return repo.BaseRef
+		}
+	}
+	return cmp.Or(cfg.DefaultBaseRef, DefaultBaseRef)
+}
+
+// WorktreeBases returns every directory worktrees are created under: the
+// top-level worktree_base_path and those of the repositories, without
+// duplicates.
+func WorktreeBases(cfg model.Config) []string {
+	var bases []string
+	if cfg.WorktreeBasePath != "" {
+		bases = append(bases, cfg.WorktreeBasePath)
+	}
+	for _, repo := range cfg.Repositories {
+		if repo.WorktreeBasePath != "" && !slices.Contains(bases, repo.WorktreeBasePath) {
+			bases = append(bases, repo.WorktreeBasePath)
+		}
+	}
+	return bases
+}
+
+// FindTemplate returns the worktree template called name.
+func FindTemplate(cfg model.Config, name string) (model.WorktreeTemplate, bool) {
+	for _, tmpl := range cfg.Templates {
+		if tmpl.Name == name {
+			return tmpl, true
+		}
+	}
+	return model.WorktreeTemplate{}, false
+}
+
+// ApplyTemplate returns repo with the settings of tmpl on top: its startup
+// command and panes replace the repository's, and its copy and symlink
+// patterns are added to them.
+func ApplyTemplate(repo model.RepositoryDef, tmpl model.WorktreeTemplate) model.RepositoryDef {
+	repo.StartupCommand = cmp.Or(tmpl.StartupCommand, repo.StartupCommand)
+	if len(tmpl.Panes) > 0 {
+		panes := maps.Clone(repo.Panes)
+		if panes == nil {
+			panes = map[string]string{}
+		}
+		maps.Copy(panes, tmpl.Panes)
+		repo.Panes = panes
+	}
+	repo.CopyOnCreate = slices.Concat(repo.CopyOnCreate, tmpl.CopyOnCreate)
+	repo.SymlinkOnCreate = slices.Concat(repo.SymlinkOnCreate, tmpl.SymlinkOnCreate)
+	return repo
+}
+
 // ResolveConfigPath determines the config file path from flag or default location.
 func ResolveConfigPath(flagPath string) (string, error) {
 	if flagPath != "" {
@@ -85,12 +575,10 @@ func ResolveConfigPath(flagPath string) (string, error) {
 		return flagPath, nil
 	}
 
-	home, err := os.UserHomeDir()
+	defaultPath, err := DefaultPath()
 	if err != nil {
-		return "", fmt.Errorf("getting home directory: %w", err)
+		return "", err
 	}
-
-	defaultPath := filepath.Join(home, ".config", "yakumo", "config.yaml")
 	if _, err := os.Stat(defaultPath); err != nil {
 		return "", fmt.Errorf("default config not found at %s: create it or use --config flag", defaultPath)
 	}
@@ -113,16 +601,34 @@ func detectGitRoot() (string, string, error) {
 // detectGitRootFn is a testable function variable for detectGitRoot.
 var detectGitRootFn = detectGitRoot
 
+// DefaultPath returns where the config is read from without --config:
+// $YAKUMO_CONFIG, or the first of fileNames that exists in ~/.config/yakumo,
+// or config.yaml when none does.
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	dir := filepath.Join(home, ".config", "yakumo")
+	for _, name := range fileNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return filepath.Join(dir, name), nil
+		}
+	}
+	return filepath.Join(dir, fileNames[0]), nil
+}
+
 // EnsureDefaultConfig creates the default config file if it doesn't exist.
 // Returns the config path, whether a file was created, and any error.
 func EnsureDefaultConfig() (string, bool, error) {
-	home, err := os.UserHomeDir()
+	configPath, err := DefaultPath()
 	if err != nil {
-		return "", false, fmt.Errorf("getting home directory: %w", err)
+		return "", false, err
 	}
-
-	configDir := filepath.Join(home, ".config", "yakumo")
-	configPath := filepath.Join(configDir, "config.yaml")
+	configDir := filepath.Dir(configPath)
 
 	if _, err := os.Stat(configPath); err == nil {
 		return configPath, false, nil
@@ -153,7 +659,10 @@ func EnsureDefaultConfig() (string, bool, error) {
 // AppendRepository adds a new repository to an existing config file.
 // Returns an error if the path is already registered.
 func AppendRepository(configPath, name, path string) error {
-	cfg, err := LoadFromFile(configPath)
+	if configPath == "" {
+		return fmt.Errorf("no config file to add the repository to")
+	}
+	cfg, err := loadFile(configPath, false)
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
@@ -164,25 +673,50 @@ func AppendRepository(configPath, name, path string) error {
 		}
 	}
 
-	cfg.Repositories = append(cfg.Repositories, model.RepositoryDef{
-		Name: name,
-		Path: path,
-	})
+	return editRepositories(configPath, &model.RepositoryDef{Name: name, Path: path}, "")
+}
 
-	data, err := yaml.Marshal(cfg)
+// RemoveRepository removes the repository at path from an existing config
+// file. The last repository cannot be removed, as a config needs one, unless
+// others may come from scan_paths or included files.
+func RemoveRepository(configPath, path string) error {
+	if configPath == "" {
+		return fmt.Errorf("no config file to remove the repository from")
+	}
+	cfg, err := loadFile(configPath, false)
 	if err != nil {
-		return fmt.Errorf("marshaling config: %w", err)
+		return fmt.Errorf("loading config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0o644); err != nil {
-		return fmt.Errorf("writing config: %w", err)
+	i := slices.IndexFunc(cfg.Repositories, func(repo model.RepositoryDef) bool {
+		return filepath.Clean(repo.Path) == filepath.Clean(path)
+	})
+	if i < 0 {
+		if underScanPath(cfg, path) {
+			return fmt.Errorf("repository %q is found under scan_paths, not listed in %s", path, configPath)
+		}
+		return fmt.Errorf("repository %q is not in %s", path, configPath)
 	}
-
-	return nil
+	if len(cfg.Repositories) == 1 && !hasOtherRepositories(cfg) {
+		return fmt.Errorf("repository %q is the only one, a config needs at least one", path)
+	}
+	return editRepositories(configPath, nil, path)
 }
 
-// Load resolves the config path and loads the config.
+// Load resolves the config path and loads the config. $YAKUMO_CONFIG is
+// treated like --config, and with $YAKUMO_REPOSITORIES and no config file
+// the config comes from the environment instead of a generated file.
 func Load(flagPath string) (model.Config, error) {
+	flagPath = cmp.Or(flagPath, os.Getenv(EnvConfig))
+	if flagPath == "" && os.Getenv(EnvRepositories) != "" {
+		path, err := DefaultPath()
+		if err != nil {
+			return model.Config{}, err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return LoadFromEnv()
+		}
+	}
 	if flagPath == "" {
 		createdPath, created, err := EnsureDefaultConfig()
 		if err != nil {
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mikanfactory/yakumo/internal/model"
 )
@@ -111,6 +113,613 @@ repositories: []
 	}
 }
 
+func TestLoadFromFile_SessionPrefix(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	t.Setenv("USER", "alice")
+
+	content := `session_prefix: ${USER}/
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if cfg.SessionPrefix != "alice" {
+		t.Errorf("SessionPrefix = %q, want %q", cfg.SessionPrefix, "alice")
+	}
+}
+
+func TestLoadFromFile_SessionPrefixInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `session_prefix: alice.dev
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil {
+		t.Error("expected error for session_prefix containing '.', got nil")
+	}
+}
+
+func TestLoadFromFile_DiffBaseInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `diff_base: tip
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil {
+		t.Error("expected error for unknown diff_base, got nil")
+	}
+}
+
+func TestLoadFromFile_AutoWIPInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `auto_wip: always
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil {
+		t.Error("expected error for unknown auto_wip, got nil")
+	}
+}
+
+func TestLoadFromFile_BranchNamer(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `branch_namer:
+  provider: ollama
+  model: qwen2.5
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BranchNamer.Provider != "ollama" || cfg.BranchNamer.Model != "qwen2.5" {
+		t.Errorf("BranchNamer = %+v", cfg.BranchNamer)
+	}
+
+	content = strings.Replace(content, "ollama", "gemini", 1)
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), "branch_namer.provider") {
+		t.Errorf("err = %v, want one about branch_namer.provider", err)
+	}
+
+	content = strings.Replace(content, "gemini", "ollama\n  template: \"{{.Issue}}-{{.Slug}}\"", 1)
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), "branch_namer.template") {
+		t.Errorf("err = %v, want one about branch_namer.template", err)
+	}
+}
+
+func TestLoadFromFile_Forge(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "repositories:\n  - name: myrepo\n    path: /home/user/myrepo\n    forge: GitHub\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Repositories[0].Forge; got != "github" {
+		t.Errorf("Forge = %q, want it normalized to github", got)
+	}
+
+	content = strings.Replace(content, "GitHub", "gitea", 1)
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), "unknown forge") {
+		t.Errorf("err = %v, want one about the unknown forge", err)
+	}
+}
+
+func TestLoadFromFile_LogLevelInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `log_level: verbose
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), "log_level") {
+		t.Errorf("expected log_level error, got %v", err)
+	}
+}
+
+func TestLoadFromFile_TmuxModeInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `tmux_mode: pane
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil {
+		t.Error("expected error for unknown tmux_mode, got nil")
+	}
+}
+
+func TestLoadFromFile_PortBase(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `port_base: 4000
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if cfg.PortBase != 4000 || cfg.PortBlockSize != 10 {
+		t.Errorf("PortBase, PortBlockSize = %d, %d, want 4000, 10", cfg.PortBase, cfg.PortBlockSize)
+	}
+
+	for _, ports := range []string{"port_base: 70000", "port_base: 65530", "port_base: 4000\nport_block_size: -1"} {
+		content := ports + `
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+		if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFromFile(cfgPath); err == nil {
+			t.Errorf("expected error for %q, got nil", ports)
+		}
+	}
+}
+
+func TestLoadFromFile_SessionNameTemplateInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `session_name_template: "{{.Repository}}-{{.Slug}}"
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil {
+		t.Error("expected error for unknown template field, got nil")
+	}
+}
+
+func TestParseAgentPollInterval(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", DefaultAgentPollInterval, false},
+		{"2s", 2 * time.Second, false},
+		{"250ms", 250 * time.Millisecond, false},
+		{"10ms", 0, true},
+		{"-1s", 0, true},
+		{"often", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAgentPollInterval(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseAgentPollInterval(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadFromFile_AgentPollIntervalInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `agent_poll_interval: 2
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil {
+		t.Error("expected error for a unitless agent_poll_interval, got nil")
+	}
+}
+
+func TestLoadFromFile_Agents(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `agents:
+  - name: mycli
+    processes: [mycli]
+    running: ['Thinking \((?P<elapsed>\d+s)\)']
+    prompt: '(?m)^mycli> '
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if len(cfg.Agents) != 1 || cfg.Agents[0].Name != "mycli" || len(cfg.Agents[0].Running) != 1 {
+		t.Errorf("Agents = %+v", cfg.Agents)
+	}
+}
+
+func TestLoadFromFile_AgentsInvalidPattern(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `agents:
+  - name: mycli
+    processes: [mycli]
+    prompt: '(unclosed'
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), "prompt") {
+		t.Errorf("expected an error naming the bad prompt pattern, got %v", err)
+	}
+}
+
+func TestLoadFromFile_Notifications(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `notifications:
+  waiting: [desktop, tmux]
+  idle: []
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if !slices.Equal(cfg.Notifications.Waiting, []string{"desktop", "tmux"}) {
+		t.Errorf("Notifications.Waiting = %v", cfg.Notifications.Waiting)
+	}
+	if cfg.Notifications.Idle == nil || len(cfg.Notifications.Idle) != 0 {
+		t.Errorf("Notifications.Idle = %#v, want an empty list that turns it off", cfg.Notifications.Idle)
+	}
+}
+
+func TestLoadFromFile_NotificationsUnknownChannel(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `notifications:
+  idle: [email]
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), "notifications.idle") {
+		t.Errorf("expected an error naming notifications.idle, got %v", err)
+	}
+}
+
+func TestLoadFromFile_Panes(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+    panes:
+      top_right: lazygit
+      bottom_right: npm run dev
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if got := cfg.Repositories[0].Panes["bottom_right"]; got != "npm run dev" {
+		t.Errorf("Panes[bottom_right] = %q, want %q", got, "npm run dev")
+	}
+}
+
+func TestLoadFromFile_InvalidEnv(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+    env:
+      DATABASE_URL: postgres://localhost/{{.Branch}}
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), `repository "myrepo": env:`) {
+		t.Errorf("expected an env error, got %v", err)
+	}
+}
+
+func TestLoadFromFile_CopyOnCreateOutsideRepo(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+    copy_on_create:
+      - .env
+      - ../secrets/.env
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), "copy_on_create") {
+		t.Errorf("expected a copy_on_create error, got %v", err)
+	}
+}
+
+func TestLoadFromFile_Templates(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+templates:
+  - name: bugfix
+    base_ref: origin/release
+    branch_prefix: fix
+  - name: experiment
+    panes:
+      bottom_right: npm run dev
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	tmpl, ok := FindTemplate(cfg, "bugfix")
+	if !ok || tmpl.BaseRef != "origin/release" || tmpl.BranchPrefix != "fix" {
+		t.Errorf("FindTemplate(bugfix) = %+v, %v", tmpl, ok)
+	}
+	if _, ok := FindTemplate(cfg, "missing"); ok {
+		t.Error("FindTemplate found a template that is not configured")
+	}
+}
+
+func TestLoadFromFile_TemplatesInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates string
+		want      string
+	}{
+		{"no name", "  - base_ref: origin/main\n", "name is required"},
+		{"duplicate", "  - name: a\n  - name: a\n", "defined twice"},
+		{"prefix", "  - name: a\n    branch_prefix: Fix/Me\n", "branch_prefix"},
+		{"pane", "  - name: a\n    panes:\n      left: vim\n", "unknown pane"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+			content := "repositories:\n  - name: myrepo\n    path: /home/user/myrepo\ntemplates:\n" + tt.templates
+			if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadFromFile error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTemplate(t *testing.T) {
+	repo := model.RepositoryDef{
+		Name:           "myrepo",
+		StartupCommand: "nvim",
+		Panes:          map[string]string{"top_right": "lazygit"},
+		CopyOnCreate:   []string{".env"},
+	}
+	got := ApplyTemplate(repo, model.WorktreeTemplate{
+		Panes:           map[string]string{"bottom_right": "npm run dev"},
+		CopyOnCreate:    []string{"config/master.key"},
+		SymlinkOnCreate: []string{"node_modules"},
+	})
+	if got.StartupCommand != "nvim" {
+		t.Errorf("StartupCommand = %q, want the repository's kept", got.StartupCommand)
+	}
+	if len(got.Panes) != 2 || got.Panes["top_right"] != "lazygit" || got.Panes["bottom_right"] != "npm run dev" {
+		t.Errorf("Panes = %v", got.Panes)
+	}
+	if len(repo.Panes) != 1 {
+		t.Errorf("the repository's panes changed: %v", repo.Panes)
+	}
+	if !slices.Equal(got.CopyOnCreate, []string{".env", "config/master.key"}) || !slices.Equal(got.SymlinkOnCreate, []string{"node_modules"}) {
+		t.Errorf("CopyOnCreate = %v, SymlinkOnCreate = %v", got.CopyOnCreate, got.SymlinkOnCreate)
+	}
+
+	if got := ApplyTemplate(repo, model.WorktreeTemplate{StartupCommand: "make sandbox"}); got.StartupCommand != "make sandbox" {
+		t.Errorf("StartupCommand = %q, want the template's", got.StartupCommand)
+	}
+}
+
+func TestLoadFromFile_PanesUnknownPane(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+    panes:
+      left: lazygit
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), `unknown pane "left"`) {
+		t.Errorf("expected an unknown pane error, got %v", err)
+	}
+}
+
+func TestLoadFromFile_Keybindings(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `keybindings:
+  sidebar:
+    archive: x
+    down: [down, n]
+    pin: []
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sidebar := cfg.Keybindings["sidebar"]
+	if !slices.Equal(sidebar["archive"], model.KeyList{"x"}) {
+		t.Errorf("archive = %q, want a single key to become a list", sidebar["archive"])
+	}
+	if !slices.Equal(sidebar["down"], model.KeyList{"down", "n"}) {
+		t.Errorf("down = %q", sidebar["down"])
+	}
+	if keys, ok := sidebar["pin"]; !ok || len(keys) != 0 {
+		t.Errorf("pin = %q, %v; want an empty list", keys, ok)
+	}
+}
+
+func TestLoadFromFile_Theme(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `theme:
+  preset: light
+  colors:
+    accent: "#ff8800"
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Theme.Preset != "light" || cfg.Theme.Colors["accent"] != "#ff8800" {
+		t.Errorf("Theme = %+v", cfg.Theme)
+	}
+}
+
+func TestLoadFromFile_ThemeInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `theme:
+  preset: solarized
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil {
+		t.Error("expected error for unknown theme preset, got nil")
+	}
+}
+
 func TestDetectGitRoot_InRepo(t *testing.T) {
 	name, root, err := detectGitRoot()
 	if err != nil {
@@ -140,6 +749,104 @@ func TestDetectGitRoot_NotInRepo(t *testing.T) {
 	}
 }
 
+func TestLoadFromFile_TOMLAndJSON(t *testing.T) {
+	files := map[string]string{
+		"config.toml": `sidebar_width = 35
+log_level = "debug"
+
+[keybindings.sidebar]
+archive = "x"
+down = ["down", "n"]
+
+[[repositories]]
+name = "myrepo"
+path = "/home/user/myrepo"
+
+[repositories.tasks]
+test = "go test ./..."
+`,
+		"config.json": `{
+  "sidebar_width": 35,
+  "log_level": "debug",
+  "keybindings": {"sidebar": {"archive": "x", "down": ["down", "n"]}},
+  "repositories": [
+    {"name": "myrepo", "path": "/home/user/myrepo", "tasks": {"test": "go test ./..."}}
+  ]
+}
+`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadFromFile(cfgPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.SidebarWidth != 35 || cfg.LogLevel != "debug" || cfg.DefaultBaseRef != DefaultBaseRef {
+				t.Errorf("cfg = %+v", cfg)
+			}
+			if len(cfg.Repositories) != 1 || cfg.Repositories[0].Tasks["test"] != "go test ./..." {
+				t.Errorf("Repositories = %+v", cfg.Repositories)
+			}
+			sidebar := cfg.Keybindings["sidebar"]
+			if !slices.Equal(sidebar["archive"], model.KeyList{"x"}) || !slices.Equal(sidebar["down"], model.KeyList{"down", "n"}) {
+				t.Errorf("keybindings = %q", sidebar)
+			}
+		})
+	}
+}
+
+func TestLoadFromFile_TOMLAndJSONValidated(t *testing.T) {
+	files := map[string]string{
+		"config.toml": "log_level = \"verbose\"\n[[repositories]]\nname = \"r\"\npath = \"/r\"\n",
+		"config.json": `{"log_level": "verbose", "repositories": [{"name": "r", "path": "/r"}]}`,
+	}
+	for name, content := range files {
+		cfgPath := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), "log_level") {
+			t.Errorf("%s: err = %v, want the log_level error", name, err)
+		}
+	}
+
+	cfgPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(cfgPath, []byte("sidebar_width = \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), "parsing config file") {
+		t.Errorf("err = %v, want a parse error", err)
+	}
+}
+
+func TestDefaultPath_Precedence(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	configDir := filepath.Join(tmpHome, ".config", "yakumo")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(configDir, "config.yaml")
+	if path, err := DefaultPath(); err != nil || path != want {
+		t.Errorf("DefaultPath() = %q, %v; want %q without a config", path, err, want)
+	}
+	for _, name := range []string{"config.json", "config.toml", "config.yaml"} {
+		if err := os.WriteFile(filepath.Join(configDir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		want := filepath.Join(configDir, name)
+		if path, err := DefaultPath(); err != nil || path != want {
+			t.Errorf("DefaultPath() = %q, %v; want %q", path, err, want)
+		}
+	}
+}
+
 func TestEnsureDefaultConfig_CreatesFile(t *testing.T) {
 	tmpHome := t.TempDir()
 	t.Setenv("HOME", tmpHome)
@@ -387,6 +1094,32 @@ repositories:
 	}
 }
 
+func TestAppendRepository_KeepsFormat(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.toml")
+	content := "[[repositories]]\nname = \"existing-repo\"\npath = \"/home/user/existing-repo\"\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AppendRepository(cfgPath, "new-repo", "/home/user/new-repo"); err != nil {
+		t.Fatalf("AppendRepository failed: %v", err)
+	}
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "[[repositories]]") {
+		t.Errorf("config should still be TOML:\n%s", data)
+	}
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Repositories) != 2 || cfg.Repositories[1].Name != "new-repo" {
+		t.Errorf("Repositories = %+v", cfg.Repositories)
+	}
+}
+
 func TestAppendRepository_Duplicate(t *testing.T) {
 	dir := t.TempDir()
 	cfgPath := filepath.Join(dir, "config.yaml")
@@ -408,6 +1141,98 @@ func TestAppendRepository_Duplicate(t *testing.T) {
 	}
 }
 
+func TestRemoveRepository(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := `sidebar_width: 35
+
+repositories:
+  - name: api
+    path: /home/user/api
+  - name: web
+    path: /home/user/web
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RemoveRepository(cfgPath, "/home/user/api/"); err != nil {
+		t.Fatalf("RemoveRepository failed: %v", err)
+	}
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Repositories) != 1 || cfg.Repositories[0].Name != "web" {
+		t.Errorf("Repositories = %+v, want only web", cfg.Repositories)
+	}
+	if cfg.SidebarWidth != 35 {
+		t.Errorf("SidebarWidth = %d, want 35", cfg.SidebarWidth)
+	}
+
+	if err := RemoveRepository(cfgPath, "/home/user/api"); err == nil || !strings.Contains(err.Error(), "not in") {
+		t.Errorf("removing it again: err = %v", err)
+	}
+	if err := RemoveRepository(cfgPath, "/home/user/web"); err == nil || !strings.Contains(err.Error(), "only one") {
+		t.Errorf("removing the last repository: err = %v", err)
+	}
+}
+
+func TestAppendAndRemoveRepository_KeepFileAsWritten(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := `# my yakumo config
+session_prefix: $USER-
+worktree_base_path: ~/wt # next to the code
+port_base: 4000
+
+repositories:
+  # the main one
+  - name: api
+    path: ~/code/api
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AppendRepository(cfgPath, "web", "/home/user/web"); err != nil {
+		t.Fatalf("AppendRepository failed: %v", err)
+	}
+	if err := RemoveRepository(cfgPath, "/home/user/web"); err != nil {
+		t.Fatalf("RemoveRepository failed: %v", err)
+	}
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Blank lines are all yaml.v3 drops.
+	if want := strings.ReplaceAll(content, "\n\n", "\n"); string(data) != want {
+		t.Errorf("adding and removing a repository should leave the file as written, got:\n%s", data)
+	}
+}
+
+func TestAppendRepository_JSONKeepsValues(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	content := `{"worktree_base_path": "~/wt", "repositories": [{"name": "api", "path": "/home/user/api"}]}`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AppendRepository(cfgPath, "web", "/home/user/web"); err != nil {
+		t.Fatalf("AppendRepository failed: %v", err)
+	}
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, unwanted := range []string{"sidebar_width", "default_base_ref", "/yakumo"} {
+		if strings.Contains(string(data), unwanted) {
+			t.Errorf("defaults should not be written back (%s):\n%s", unwanted, data)
+		}
+	}
+	if !strings.Contains(string(data), `"~/wt"`) || !strings.Contains(string(data), `"web"`) {
+		t.Errorf("config = %s", data)
+	}
+}
+
 func TestAppendRepository_FileNotFound(t *testing.T) {
 	err := AppendRepository("/nonexistent/config.yaml", "repo", "/path")
 	if err == nil {
@@ -481,6 +1306,50 @@ func TestLoadFromFile_RbCommandsExceedsMax(t *testing.T) {
 	}
 }
 
+func TestLoadFromFile_Tasks(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := `repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+    tasks:
+      test: "go test ./..."
+      lint: "golangci-lint run"
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if got := cfg.Repositories[0].Tasks["test"]; got != "go test ./..." {
+		t.Errorf("Tasks[test] = %q, want %q", got, "go test ./...")
+	}
+}
+
+func TestLoadFromFile_TasksInvalid(t *testing.T) {
+	for _, tasks := range []string{
+		`"rb_commands": "make"`,
+		`"../x": "make"`,
+		`"test": ""`,
+	} {
+		dir := t.TempDir()
+		cfgPath := filepath.Join(dir, "config.yaml")
+		content := "repositories:\n  - name: myrepo\n    path: /home/user/myrepo\n    tasks:\n      " + tasks + "\n"
+		if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := LoadFromFile(cfgPath)
+		if err == nil || !strings.Contains(err.Error(), "tasks") {
+			t.Errorf("tasks %s: err = %v, want a tasks error", tasks, err)
+		}
+	}
+}
+
 func TestLoadFromFile_WithoutCommands_BackwardCompat(t *testing.T) {
 	dir := t.TempDir()
 	cfgPath := filepath.Join(dir, "config.yaml")
@@ -515,6 +1384,7 @@ func TestLoadFromFile_TildeExpansion(t *testing.T) {
 	cfgPath := filepath.Join(dir, "config.yaml")
 
 	content := `worktree_base_path: ~/yakumo
+trash_dir: ~/.yakumo-trash
 repositories:
   - name: myrepo
     path: /home/user/myrepo
@@ -532,6 +1402,61 @@ repositories:
 	if cfg.WorktreeBasePath != want {
 		t.Errorf("WorktreeBasePath = %q, want %q", cfg.WorktreeBasePath, want)
 	}
+	if want := filepath.Join(tmpHome, ".yakumo-trash"); cfg.TrashDir != want {
+		t.Errorf("TrashDir = %q, want %q", cfg.TrashDir, want)
+	}
+}
+
+func TestWorktreeBase(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := `worktree_base_path: /srv/worktrees
+repositories:
+  - name: api
+    path: /code/api
+  - name: ml
+    path: /code/ml
+    worktree_base_path: ~/bigdisk/worktrees
+  - name: data
+    path: /code/data
+    worktree_base_path: /srv/worktrees
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bigdisk := filepath.Join(tmpHome, "bigdisk", "worktrees")
+	if got := WorktreeBase(cfg, "/code/ml/"); got != bigdisk {
+		t.Errorf("WorktreeBase(ml) = %q, want its own %q", got, bigdisk)
+	}
+	if got := WorktreeBase(cfg, "/code/api"); got != "/srv/worktrees" {
+		t.Errorf("WorktreeBase(api) = %q, want the top-level one", got)
+	}
+	if got := WorktreeBases(cfg); !slices.Equal(got, []string{"/srv/worktrees", bigdisk}) {
+		t.Errorf("WorktreeBases = %q", got)
+	}
+}
+
+func TestBaseRef(t *testing.T) {
+	cfg := model.Config{Repositories: []model.RepositoryDef{
+		{Name: "api", Path: "/code/api"},
+		{Name: "web", Path: "/code/web", BaseRef: "origin/develop"},
+	}}
+	if got := BaseRef(cfg, "/code/web/"); got != "origin/develop" {
+		t.Errorf("BaseRef(web) = %q, want its own origin/develop", got)
+	}
+	if got := BaseRef(cfg, "/code/api"); got != DefaultBaseRef {
+		t.Errorf("BaseRef(api) = %q, want %q", got, DefaultBaseRef)
+	}
+	cfg.DefaultBaseRef = "origin/trunk"
+	if got := BaseRef(cfg, "/code/api"); got != "origin/trunk" {
+		t.Errorf("BaseRef(api) = %q, want the top-level origin/trunk", got)
+	}
 }
 
 func TestLoadFromFile_TildeExpansion_AbsolutePathUnchanged(t *testing.T) {
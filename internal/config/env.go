@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// EnvConfig is the config file read without --config, instead of the one in
+// ~/.config/yakumo.
+const EnvConfig = "YAKUMO_CONFIG"
+
+// EnvRepositories lists the repositories to manage, separated like $PATH.
+// Each is named after its directory. It replaces the file's repositories,
+// and with it yakumo runs without a config file at all.
+const EnvRepositories = "YAKUMO_REPOSITORIES"
+
+// envOverride is an environment variable that replaces one config value.
+type envOverride struct {
+	name string
+	set  func(cfg *model.Config, value string) error
+}
+
+// envOverrides are applied over the config file, before the defaults and
+// validation, so a value from the environment is checked like one written
+// in the file.
+var envOverrides = []envOverride{
+	{"YAKUMO_SIDEBAR_WIDTH", func(cfg *model.Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		cfg.SidebarWidth = n
+		return nil
+	}},
+	{"YAKUMO_BASE_REF", func(cfg *model.Config, v string) error { cfg.DefaultBaseRef = v; return nil }},
+	{"YAKUMO_WORKTREE_BASE_PATH", func(cfg *model.Config, v string) error { cfg.WorktreeBasePath = v; return nil }},
+	{"YAKUMO_SESSION_PREFIX", func(cfg *model.Config, v string) error { cfg.SessionPrefix = v; return nil }},
+	{"YAKUMO_TRASH_DIR", func(cfg *model.Config, v string) error { cfg.TrashDir = v; return nil }},
+	{"YAKUMO_TMUX_MODE", func(cfg *model.Config, v string) error { cfg.TmuxMode = v; return nil }},
+	{"YAKUMO_DIFF_BASE", func(cfg *model.Config, v string) error { cfg.DiffBase = v; return nil }},
+	{"YAKUMO_AUTO_WIP", func(cfg *model.Config, v string) error { cfg.AutoWIP = v; return nil }},
+	{"YAKUMO_THEME", func(cfg *model.Config, v string) error { cfg.Theme.Preset = v; return nil }},
+	{"YAKUMO_CHECK_FOR_UPDATES", func(cfg *model.Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		cfg.CheckForUpdates = b
+		return nil
+	}},
+	{EnvRepositories, func(cfg *model.Config, v string) error {
+		cfg.Repositories = nil
+		for _, path := range filepath.SplitList(v) {
+			if path = strings.TrimSpace(path); path != "" {
+				cfg.Repositories = append(cfg.Repositories, model.RepositoryDef{Name: filepath.Base(path), Path: path})
+			}
+		}
+		return nil
+	}},
+}
+
+// applyEnv replaces the values of cfg that are set in the environment.
+func applyEnv(cfg *model.Config) error {
+	for _, o := range envOverrides {
+		v, ok := os.LookupEnv(o.name)
+		if !ok || v == "" {
+			continue
+		}
+		if err := o.set(cfg, v); err != nil {
+			return fmt.Errorf("%s %q: %w", o.name, v, err)
+		}
+	}
+	return nil
+}
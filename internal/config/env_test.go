@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFromFile_EnvOverrides(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := `sidebar_width: 35
+default_base_ref: origin/develop
+worktree_base_path: /srv/yakumo
+repositories:
+  - name: myrepo
+    path: /home/user/myrepo
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("YAKUMO_BASE_REF", "origin/main")
+	t.Setenv("YAKUMO_WORKTREE_BASE_PATH", "/tmp/worktrees")
+	t.Setenv("YAKUMO_CHECK_FOR_UPDATES", "true")
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DefaultBaseRef != "origin/main" || cfg.WorktreeBasePath != "/tmp/worktrees" || !cfg.CheckForUpdates {
+		t.Errorf("environment should override the file: %+v", cfg)
+	}
+	if cfg.SidebarWidth != 35 || len(cfg.Repositories) != 1 {
+		t.Errorf("unset variables should keep the file's values: %+v", cfg)
+	}
+}
+
+func TestLoadFromFile_EnvOverridesValidated(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "repositories:\n  - name: myrepo\n    path: /home/user/myrepo\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, value, want string
+	}{
+		{"YAKUMO_SIDEBAR_WIDTH", "wide", "YAKUMO_SIDEBAR_WIDTH"},
+		{"YAKUMO_CHECK_FOR_UPDATES", "sometimes", "YAKUMO_CHECK_FOR_UPDATES"},
+		{"YAKUMO_TMUX_MODE", "pane", "tmux_mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.name, tt.value)
+			if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want one about %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_FromEnvWithoutConfigFile(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv(EnvRepositories, "/code/api"+string(filepath.ListSeparator)+"/code/web")
+	t.Setenv("YAKUMO_BASE_REF", "origin/trunk")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Repositories) != 2 || cfg.Repositories[0].Name != "api" || cfg.Repositories[1].Path != "/code/web" {
+		t.Errorf("Repositories = %+v", cfg.Repositories)
+	}
+	if cfg.DefaultBaseRef != "origin/trunk" || cfg.SidebarWidth != DefaultSidebarWidth {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if _, err := os.Stat(filepath.Join(tmpHome, ".config", "yakumo", "config.yaml")); !os.IsNotExist(err) {
+		t.Errorf("no config file should be written, stat err = %v", err)
+	}
+}
+
+func TestLoad_EnvConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfgPath := filepath.Join(t.TempDir(), "ci.toml")
+	content := "[[repositories]]\nname = \"myrepo\"\npath = \"/home/user/myrepo\"\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvConfig, cfgPath)
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Repositories) != 1 || cfg.Repositories[0].Name != "myrepo" {
+		t.Errorf("Repositories = %+v", cfg.Repositories)
+	}
+	if path, err := DefaultPath(); err != nil || path != cfgPath {
+		t.Errorf("DefaultPath() = %q, %v; want %q", path, err, cfgPath)
+	}
+
+	t.Setenv(EnvConfig, filepath.Join(t.TempDir(), "missing.yaml"))
+	if _, err := Load(""); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("a missing %s should be an error like a missing --config, got %v", EnvConfig, err)
+	}
+}
+
+func TestAppendRepository_IgnoresEnv(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "repositories:\n  - name: existing-repo\n    path: /home/user/existing-repo\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvRepositories, "/code/from-env")
+	t.Setenv("YAKUMO_BASE_REF", "origin/trunk")
+
+	if err := AppendRepository(cfgPath, "new-repo", "/home/user/new-repo"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "from-env") || strings.Contains(string(data), "origin/trunk") {
+		t.Errorf("environment overrides should not be written to the file:\n%s", data)
+	}
+	if !strings.Contains(string(data), "existing-repo") || !strings.Contains(string(data), "new-repo") {
+		t.Errorf("file lacks the repositories:\n%s", data)
+	}
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvProfile selects the profile without --profile.
+const EnvProfile = "YAKUMO_PROFILE"
+
+// profile is the profile set with SetProfile.
+var profile string
+
+// SetProfile selects the profile whose files are included, as with
+// --profile. The empty string leaves it to $YAKUMO_PROFILE, and without that
+// the files of every profile are included.
+func SetProfile(name string) {
+	profile = name
+}
+
+// readMerged reads the config file at path with the files it includes, and
+// those of the selected profile, merged in. It returns the result as YAML.
+func readMerged(path string) ([]byte, error) {
+	v, err := readTree(path, true, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(v)
+}
+
+// readTree reads the config file at path and the files it includes. An
+// included file is read first and the including one merged over it: lists
+// such as repositories are joined, maps merged key by key, and any other
+// value is taken from the including file. Profiles are only looked up in the
+// top file. seen holds the files being read, to catch an include cycle. Each
+// file is added to read, when it is not nil, before it is opened.
+func readTree(path string, top bool, seen []string, read *[]string) (map[string]any, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(seen, abs) {
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(append(seen, abs), " -> "))
+	}
+	seen = append(seen, abs)
+	if read != nil {
+		*read = append(*read, abs)
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	self, err := toMap(formatOf(abs), data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", abs, err)
+	}
+
+	includes, err := stringList(self["include"])
+	if err != nil {
+		return nil, fmt.Errorf("%s: include: %w", abs, err)
+	}
+	if top {
+		files, err := profileFiles(self["profiles"])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", abs, err)
+		}
+		includes = append(includes, files...)
+	}
+	delete(self, "include")
+	delete(self, "profiles")
+
+	merged := map[string]any{}
+	for _, inc := range includes {
+		incPath, err := expandHome(inc)
+		if err != nil {
+			return nil, err
+		}
+		if !filepath.IsAbs(incPath) {
+			incPath = filepath.Join(filepath.Dir(abs), incPath)
+		}
+		v, err := readTree(incPath, false, seen, read)
+		if err != nil {
+			return nil, fmt.Errorf("include %s: %w", inc, err)
+		}
+		merge(merged, v)
+	}
+	merge(merged, self)
+	return merged, nil
+}
+
+// configFiles returns the config file at path and the files readMerged reads
+// with it, as far as they can be read. A file that does not exist yet, or
+// the one that fails to parse, is still listed.
+func configFiles(path string) []string {
+	var files []string
+	readTree(path, true, nil, &files)
+	return files
+}
+
+// profileFiles returns the files of the selected profile from the value of
+// profiles, or of every profile, in name order, when none is selected.
+func profileFiles(v any) ([]string, error) {
+	profiles, _ := v.(map[string]any)
+	name := cmp.Or(profile, os.Getenv(EnvProfile))
+	if name == "" {
+		var files []string
+		for _, name := range slices.Sorted(maps.Keys(profiles)) {
+			list, err := stringList(profiles[name])
+			if err != nil {
+				return nil, fmt.Errorf("profile %q: %w", name, err)
+			}
+			files = append(files, list...)
+		}
+		return files, nil
+	}
+	files, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q is not defined under profiles", name)
+	}
+	list, err := stringList(files)
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return list, nil
+}
+
+// merge merges src into dst as readTree describes.
+func merge(dst, src map[string]any) {
+	for k, sv := range src {
+		switch sv := sv.(type) {
+		case map[string]any:
+			if dv, ok := dst[k].(map[string]any); ok {
+				merge(dv, sv)
+				continue
+			}
+		case []any:
+			if dv, ok := dst[k].([]any); ok {
+				dst[k] = append(dv, sv...)
+				continue
+			}
+		}
+		dst[k] = sv
+	}
+}
+
+// toMap parses data in format the way decode does, but into a map.
+func toMap(format string, data []byte) (map[string]any, error) {
+	var v map[string]any
+	switch format {
+	case formatTOML:
+		if err := toml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+	case formatJSON:
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+	default:
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		return orEmpty(v), nil
+	}
+	// Round trip through YAML so the values have the types a YAML file
+	// gives, such as []any for TOML's arrays of tables.
+	converted, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	v = nil
+	if err := yaml.Unmarshal(converted, &v); err != nil {
+		return nil, err
+	}
+	return orEmpty(v), nil
+}
+
+// orEmpty returns v, or an empty map for an empty file.
+func orEmpty(v map[string]any) map[string]any {
+	if v == nil {
+		return map[string]any{}
+	}
+	return v
+}
+
+// stringList returns v, a list of file names, as strings.
+func stringList(v any) ([]string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	list, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("must be a list of files")
+	}
+	files := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok || s == "" {
+			return nil, fmt.Errorf("must be a list of files")
+		}
+		files = append(files, s)
+	}
+	return files, nil
+}
+
+// expandHome replaces a leading ~/ in path with the home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("expanding home directory: %w", err)
+	}
+	return filepath.Join(home, path[2:]), nil
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files, named relative to dir, with their contents.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadFromFile_Include(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": `include:
+  - shared.toml
+sidebar_width: 40
+repositories:
+  - name: api
+    path: /code/api
+`,
+		"shared.toml": `sidebar_width = 25
+default_base_ref = "origin/develop"
+
+[[repositories]]
+name = "dotfiles"
+path = "/code/dotfiles"
+`,
+	})
+
+	cfg, err := LoadFromFile(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SidebarWidth != 40 || cfg.DefaultBaseRef != "origin/develop" {
+		t.Errorf("the including file should win, the rest come from the included one: %+v", cfg)
+	}
+	if len(cfg.Repositories) != 2 || cfg.Repositories[0].Name != "dotfiles" || cfg.Repositories[1].Name != "api" {
+		t.Errorf("Repositories = %+v, want both files' joined", cfg.Repositories)
+	}
+	if len(cfg.Include) != 0 {
+		t.Errorf("Include = %q, want it resolved", cfg.Include)
+	}
+}
+
+func TestLoadFromFile_Profiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": `default_base_ref: origin/develop
+profiles:
+  work: [work.yaml]
+  personal: [personal.yaml]
+`,
+		"work.yaml":     "repositories:\n  - name: api\n    path: /work/api\n",
+		"personal.yaml": "repositories:\n  - name: blog\n    path: /home/me/blog\n",
+	})
+	cfgPath := filepath.Join(dir, "config.yaml")
+	t.Cleanup(func() { SetProfile("") })
+
+	names := func(t *testing.T) string {
+		t.Helper()
+		cfg, err := LoadFromFile(cfgPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.DefaultBaseRef != "origin/develop" {
+			t.Errorf("DefaultBaseRef = %q, want the shared one", cfg.DefaultBaseRef)
+		}
+		var names []string
+		for _, repo := range cfg.Repositories {
+			names = append(names, repo.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	if got := names(t); got != "blog,api" {
+		t.Errorf("without a profile: repositories %s, want every profile's", got)
+	}
+	SetProfile("work")
+	if got := names(t); got != "api" {
+		t.Errorf("--profile work: repositories %s", got)
+	}
+	SetProfile("")
+	t.Setenv(EnvProfile, "personal")
+	if got := names(t); got != "blog" {
+		t.Errorf("%s=personal: repositories %s", EnvProfile, got)
+	}
+
+	SetProfile("school")
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), `profile "school" is not defined`) {
+		t.Errorf("unknown profile: err = %v", err)
+	}
+}
+
+func TestLoadFromFile_IncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"cycle.yaml":   "include: [back.yaml]\nrepositories:\n  - name: api\n    path: /code/api\n",
+		"back.yaml":    "include: [cycle.yaml]\n",
+		"missing.yaml": "include: [nowhere.yaml]\nrepositories:\n  - name: api\n    path: /code/api\n",
+		"scalar.yaml":  "include: shared.yaml\nrepositories:\n  - name: api\n    path: /code/api\n",
+	})
+
+	tests := []struct {
+		file, want string
+	}{
+		{"cycle.yaml", "include cycle"},
+		{"missing.yaml", "include nowhere.yaml"},
+		{"scalar.yaml", "must be a list of files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if _, err := LoadFromFile(filepath.Join(dir, tt.file)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want one with %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendRepository_KeepsIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": "include: [shared.yaml]\nprofiles:\n  work: [work.yaml]\n",
+		"shared.yaml": "repositories:\n  - name: dotfiles\n    path: /code/dotfiles\n",
+		"work.yaml":   "repositories:\n  - name: api\n    path: /work/api\n",
+	})
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	if err := AppendRepository(cfgPath, "blog", "/home/me/blog"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "dotfiles") || strings.Contains(string(data), "/work/api") {
+		t.Errorf("included repositories should not be written to the file:\n%s", data)
+	}
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Repositories) != 3 {
+		t.Errorf("Repositories = %+v, want the includes kept", cfg.Repositories)
+	}
+	if err := RemoveRepository(cfgPath, "/home/me/blog"); err != nil {
+		t.Errorf("the last listed repository can go while files are included: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// maxScanDepth is how far below a scan path repositories are looked for:
+// enough for ~/code/repo as well as ghq's ~/ghq/github.com/owner/repo.
+const maxScanDepth = 3
+
+// discoverRepositories appends the git repositories found under the
+// scan_paths of cfg to its repositories, skipping those already listed. A
+// scan path that cannot be read is logged and skipped, so an unmounted disk
+// does not keep yakumo from starting.
+func discoverRepositories(cfg *model.Config) {
+	listed := func(path string) bool {
+		return slices.ContainsFunc(cfg.Repositories, func(repo model.RepositoryDef) bool {
+			return filepath.Clean(repo.Path) == path
+		})
+	}
+	var found []string
+	for _, root := range cfg.ScanPaths {
+		for _, path := range scanRepositories(root) {
+			if !listed(path) && !slices.Contains(found, path) {
+				found = append(found, path)
+			}
+		}
+	}
+	names := scannedNames(cfg.Repositories, found)
+	for i, path := range found {
+		cfg.Repositories = append(cfg.Repositories, model.RepositoryDef{Name: names[i], Path: path})
+	}
+}
+
+// scannedNames names the repositories at paths by their directory, adding
+// parent directories until no two share a name, nor any with a listed
+// repository: ghq's github.com/a/foo and github.com/b/foo become a/foo and
+// b/foo.
+func scannedNames(listed []model.RepositoryDef, paths []string) []string {
+	taken := make(map[string]bool, len(listed))
+	for _, repo := range listed {
+		taken[repo.Name] = true
+	}
+	parts := make([][]string, len(paths))
+	depths := make([]int, len(paths))
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		parts[i] = strings.Split(filepath.ToSlash(path), "/")
+		depths[i] = 1
+	}
+	for {
+		count := make(map[string]int, len(paths))
+		for i := range paths {
+			names[i] = strings.Join(parts[i][len(parts[i])-depths[i]:], "/")
+			count[names[i]]++
+		}
+		grew := false
+		for i := range paths {
+			if (count[names[i]] > 1 || taken[names[i]]) && depths[i] < len(parts[i]) {
+				depths[i]++
+				grew = true
+			}
+		}
+		if !grew {
+			return names
+		}
+	}
+}
+
+// scanRepositories returns the repositories under root in lexical order. A
+// repository is a directory with a .git directory, which leaves out linked
+// worktrees and submodules; its own subdirectories are not searched, nor
+// are hidden ones.
+func scanRepositories(root string) []string {
+	root = filepath.Clean(root)
+	// A symlinked root, such as ~/code pointing at another disk, is walked
+	// where it points, but the repositories are named by their path under it.
+	walkRoot := root
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		walkRoot = resolved
+	}
+	var repos []string
+	err := filepath.WalkDir(walkRoot, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if path == walkRoot {
+				return err
+			}
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != walkRoot && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if info, err := os.Stat(filepath.Join(path, ".git")); err == nil && info.IsDir() {
+			rel, _ := filepath.Rel(walkRoot, path)
+			repos = append(repos, filepath.Join(root, rel))
+			return filepath.SkipDir
+		}
+		if depth(walkRoot, path) >= maxScanDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		logging.For("config").Warn("scanning for repositories failed (non-fatal)", "path", root, "err", err)
+	}
+	return repos
+}
+
+// depth returns how many directories path is below root.
+func depth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// underScanPath reports whether path is within the depth scan_paths of cfg
+// are searched to.
+func underScanPath(cfg model.Config, path string) bool {
+	return slices.ContainsFunc(cfg.ScanPaths, func(root string) bool {
+		rel, err := filepath.Rel(root, path)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) &&
+			depth(root, path) <= maxScanDepth
+	})
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mkdirs creates the directories at paths under root.
+func mkdirs(t *testing.T, root string, paths ...string) {
+	t.Helper()
+	for _, p := range paths {
+		if err := os.MkdirAll(filepath.Join(root, p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScanRepositories(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root,
+		"api/.git",
+		"api/vendor/lib/.git", // inside a repository
+		"notes",
+		".cache/tool/.git", // hidden
+		"github.com/owner/web/.git",
+		"deep/a/b/c/.git", // below maxScanDepth
+	)
+	// A linked worktree has a .git file, not a directory.
+	mkdirs(t, root, "api-feature")
+	if err := os.WriteFile(filepath.Join(root, "api-feature", ".git"), []byte("gitdir: /elsewhere\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := scanRepositories(root)
+	want := []string{filepath.Join(root, "api"), filepath.Join(root, "github.com", "owner", "web")}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("scanRepositories = %q, want %q", got, want)
+	}
+
+	if got := scanRepositories(filepath.Join(root, "missing")); len(got) != 0 {
+		t.Errorf("a missing scan path should find nothing, got %q", got)
+	}
+}
+
+func TestScanRepositories_SymlinkedRoot(t *testing.T) {
+	target := t.TempDir()
+	mkdirs(t, target, "api/.git")
+	link := filepath.Join(t.TempDir(), "code")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks unsupported:", err)
+	}
+
+	got := scanRepositories(link)
+	if len(got) != 1 || got[0] != filepath.Join(link, "api") {
+		t.Errorf("scanRepositories = %q, want the repository under the link", got)
+	}
+}
+
+func TestLoadFromFile_ScanPaths(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	mkdirs(t, tmpHome, "code/api/.git", "code/web/.git")
+	api := filepath.Join(tmpHome, "code", "api")
+
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "scan_paths:\n  - ~/code\nrepositories:\n  - name: backend\n    path: " + api + "\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Repositories) != 2 || cfg.Repositories[0].Name != "backend" || cfg.Repositories[1].Name != "web" {
+		t.Errorf("a listed repository should keep its entry and the rest be added: %+v", cfg.Repositories)
+	}
+
+	// Discovered repositories are not written back with the file.
+	if err := AppendRepository(cfgPath, "other", "/home/user/other"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "name: web") {
+		t.Errorf("discovered repositories should not be written to the file:\n%s", data)
+	}
+}
+
+func TestLoadFromFile_ScanPathsSameName(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	mkdirs(t, tmpHome, "ghq/github.com/a/foo/.git", "ghq/github.com/b/foo/.git", "ghq/github.com/a/bar/.git", "code/web/.git")
+
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "scan_paths:\n  - ~/ghq\n  - ~/code\nrepositories:\n  - name: bar\n    path: /elsewhere/bar\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, repo := range cfg.Repositories {
+		names = append(names, repo.Name)
+	}
+	if want := "bar a/bar a/foo b/foo web"; strings.Join(names, " ") != want {
+		t.Errorf("names = %q, want %q", names, want)
+	}
+}
+
+func TestLoadFromFile_DuplicateRepositoryName(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "repositories:\n  - name: foo\n    path: /a/foo\n  - name: foo\n    path: /b/foo\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), "defined twice") {
+		t.Errorf("LoadFromFile error = %v, want one about the duplicate name", err)
+	}
+}
+
+func TestLoadFromFile_ScanPathsOnly(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte("scan_paths:\n  - ~/code\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil || !strings.Contains(err.Error(), "none were found under scan_paths") {
+		t.Errorf("err = %v, want one saying nothing was found", err)
+	}
+
+	mkdirs(t, tmpHome, "code/api/.git")
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Repositories) != 1 || cfg.Repositories[0].Path != filepath.Join(tmpHome, "code", "api") {
+		t.Errorf("Repositories = %+v", cfg.Repositories)
+	}
+}
+
+func TestRemoveRepository_ScanPaths(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	mkdirs(t, tmpHome, "code/web/.git")
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "scan_paths:\n  - ~/code\nrepositories:\n  - name: api\n    path: /home/user/api\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	web := filepath.Join(tmpHome, "code", "web")
+	if err := RemoveRepository(cfgPath, web); err == nil || !strings.Contains(err.Error(), "scan_paths") {
+		t.Errorf("removing a discovered repository: err = %v", err)
+	}
+	if err := RemoveRepository(cfgPath, "/home/user/api"); err != nil {
+		t.Errorf("the last listed repository can go while scan_paths remain: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/mikanfactory/yakumo/internal/logging"
+)
+
+// watchDebounce coalesces the several events one save by an editor makes.
+const watchDebounce = 100 * time.Millisecond
+
+// Watcher reports when a config file, or a file it includes, is written or
+// replaced.
+type Watcher struct {
+	fs      *fsnotify.Watcher
+	path    string
+	files   map[string]bool
+	dirs    map[string]bool
+	changes chan struct{}
+}
+
+// Watch starts watching the config file at path and the files it pulls in
+// with include and profiles. Their directories are watched rather than the
+// files, so saves that replace a file, as vim's and most atomic writes do,
+// are seen too. A symlinked file, such as one kept in a dotfiles repository,
+// is followed to the file it points to. The included files are looked up
+// again after each change, so a newly added include is watched as well.
+func Watch(path string) (*Watcher, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("watching config: %w", err)
+	}
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("watching config: %w", err)
+	}
+	w := &Watcher{fs: fsw, path: path, dirs: map[string]bool{}, changes: make(chan struct{}, 1)}
+	if err := w.watchFiles(); err != nil {
+		fsw.Close()
+		return nil, fmt.Errorf("watching config: %w", err)
+	}
+	go w.run()
+	return w, nil
+}
+
+// watchFiles looks up the config file and the files it includes, and watches
+// the directories of any new ones. Only a failure to watch the config file's
+// own directory is returned; an include in a directory that cannot be
+// watched is logged and skipped.
+func (w *Watcher) watchFiles() error {
+	files := map[string]bool{}
+	for i, file := range append([]string{w.path}, configFiles(w.path)...) {
+		if resolved, err := filepath.EvalSymlinks(file); err == nil {
+			file = resolved
+		}
+		files[file] = true
+		dir := filepath.Dir(file)
+		if w.dirs[dir] {
+			continue
+		}
+		if err := w.fs.Add(dir); err != nil {
+			if i == 0 {
+				return err
+			}
+			logging.For("config").Warn("watching an included config file failed (non-fatal)", "file", file, "err", err)
+			continue
+		}
+		w.dirs[dir] = true
+	}
+	w.files = files
+	return nil
+}
+
+// Changes receives once the file has settled after a change. It is closed
+// with the watcher.
+func (w *Watcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+func (w *Watcher) run() {
+	defer close(w.changes)
+	var settled <-chan time.Time
+	for {
+		select {
+		case ev, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if w.files[filepath.Clean(ev.Name)] && ev.Has(fsnotify.Write|fsnotify.Create) {
+				settled = time.After(watchDebounce)
+			}
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			logging.For("config").Warn("watching config failed (non-fatal)", "err", err)
+		case <-settled:
+			settled = nil
+			if err := w.watchFiles(); err != nil {
+				logging.For("config").Warn("watching config failed (non-fatal)", "err", err)
+			}
+			select {
+			case w.changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func waitForChange(t *testing.T, w *Watcher) {
+	t.Helper()
+	select {
+	case <-w.Changes():
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte("sidebar_width: 30\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := Watch(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// Other files in the directory are not the config.
+	if err := os.WriteFile(filepath.Join(dir, "debug.log"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.Changes():
+		t.Fatal("a change to another file was reported")
+	case <-time.After(3 * watchDebounce):
+	}
+
+	if err := os.WriteFile(cfgPath, []byte("sidebar_width: 40\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForChange(t, w)
+
+	// Saved the way editors do: written elsewhere, then renamed over it.
+	tmp := filepath.Join(dir, "config.yaml.tmp")
+	if err := os.WriteFile(tmp, []byte("sidebar_width: 50\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, cfgPath); err != nil {
+		t.Fatal(err)
+	}
+	waitForChange(t, w)
+
+	w.Close()
+	if _, ok := <-w.Changes(); ok {
+		t.Error("Changes should be closed with the watcher")
+	}
+}
+
+func TestWatch_Symlink(t *testing.T) {
+	dotfiles := t.TempDir()
+	target := filepath.Join(dotfiles, "yakumo.yaml")
+	if err := os.WriteFile(target, []byte("sidebar_width: 30\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	w, err := Watch(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(target, []byte("sidebar_width: 40\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForChange(t, w)
+}
+
+func TestWatch_Includes(t *testing.T) {
+	dir := t.TempDir()
+	shared := filepath.Join(t.TempDir(), "shared.yaml")
+	work := filepath.Join(t.TempDir(), "work.yaml")
+	for _, f := range []string{shared, work} {
+		if err := os.WriteFile(f, []byte("sidebar_width: 30\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfgPath := filepath.Join(dir, "config.yaml")
+	profiles := "profiles:\n  work:\n    - " + work + "\n"
+	cfg := "include:\n  - " + shared + "\n" + profiles
+	if err := os.WriteFile(cfgPath, []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := Watch(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(shared, []byte("sidebar_width: 40\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForChange(t, w)
+
+	if err := os.WriteFile(work, []byte("sidebar_width: 40\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForChange(t, w)
+
+	// An include added later is watched from the change that adds it.
+	added := filepath.Join(t.TempDir(), "added.yaml")
+	if err := os.WriteFile(added, []byte("sidebar_width: 30\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfgPath, []byte("include:\n  - "+shared+"\n  - "+added+"\n"+profiles), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForChange(t, w)
+	if err := os.WriteFile(added, []byte("sidebar_width: 40\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForChange(t, w)
+}
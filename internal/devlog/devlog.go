@@ -0,0 +1,145 @@
+// Package devlog captures the dev-server pane of a worktree session into a
+// size-rotated log file, so its output can be read after the pane has
+// scrolled it away.
+package devlog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/mikanfactory/yakumo/internal/state"
+)
+
+// MaxBytes is the size at which the log is rotated. One rotated file
+// (<path>.1) is kept, so a worktree uses at most twice this on disk.
+const MaxBytes = 5 << 20
+
+// Path returns the log file for worktreePath inside the yakumo state
+// directory.
+func Path(worktreePath string) (string, error) {
+	return state.DefaultPath(filepath.Join("logs", fileName(worktreePath)))
+}
+
+// fileName flattens a worktree path into a single file name, e.g.
+// "/home/me/yakumo/repo/fix" -> "home-me-yakumo-repo-fix.log".
+func fileName(worktreePath string) string {
+	name := strings.Trim(filepath.ToSlash(filepath.Clean(worktreePath)), "/")
+	return strings.ReplaceAll(name, "/", "-") + ".log"
+}
+
+// Writer appends to a log file, rotating it to <path>.1 once it would grow
+// past maxBytes.
+type Writer struct {
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+}
+
+// Open opens (or creates) the log at path for appending.
+func Open(path string, maxBytes int64) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+	w := &Writer{path: path, maxBytes: maxBytes}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log %s: %w", w.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("reading log %s: %w", w.path, err)
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if the file is non-empty and p would take
+// it past the size limit.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.size > 0 && w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("closing log %s: %w", w.path, err)
+	}
+	if err := os.Rename(w.path, w.path+".1"); err != nil {
+		return fmt.Errorf("rotating log %s: %w", w.path, err)
+	}
+	return w.open()
+}
+
+// Close closes the underlying file.
+func (w *Writer) Close() error {
+	return w.file.Close()
+}
+
+// Tail returns the last n lines of the log at path, reading the rotated file
+// first when the current one is shorter than n. Terminal escape sequences are
+// stripped. A log that was never written returns an error wrapping
+// os.ErrNotExist.
+func Tail(path string, n int) ([]string, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) < n {
+		if older, err := readLines(path + ".1"); err == nil {
+			lines = append(older, lines...)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no dev-server log yet: %w", err)
+		}
+		return nil, fmt.Errorf("reading log %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := ansi.Strip(scanner.Text())
+		// Progress bars redraw with \r; keep only what was last on screen.
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading log %s: %w", path, err)
+	}
+	return lines, nil
+}
@@ -0,0 +1,59 @@
+package devlog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileName(t *testing.T) {
+	if got := fileName("/home/me/yakumo/repo/fix/"); got != "home-me-yakumo-repo-fix.log" {
+		t.Errorf("fileName = %q", got)
+	}
+}
+
+func TestWriter_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "wt.log")
+	w, err := Open(path, 10)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	for _, chunk := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	current, _ := os.ReadFile(path)
+	rotated, _ := os.ReadFile(path + ".1")
+	if string(current) != "third\n" || string(rotated) != "second\n" {
+		t.Errorf("current = %q, rotated = %q", current, rotated)
+	}
+}
+
+func TestTail(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wt.log")
+	os.WriteFile(path+".1", []byte("a\nb\n"), 0o644)
+	os.WriteFile(path, []byte("\x1b[32mc\x1b[0m\n50%\r100%\n"), 0o644)
+
+	got, err := Tail(path, 3)
+	if err != nil {
+		t.Fatalf("Tail: %v", err)
+	}
+	if strings.Join(got, ",") != "b,c,100%" {
+		t.Errorf("Tail = %q, want [b c 100%%]", got)
+	}
+}
+
+func TestTail_Missing(t *testing.T) {
+	_, err := Tail(filepath.Join(t.TempDir(), "none.log"), 10)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("err = %v, want os.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,13 @@
+package diffui
+
+import (
+	"os"
+	"testing"
+
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestMain(m *testing.M) {
+	zone.NewGlobal()
+	os.Exit(m.Run())
+}
@@ -0,0 +1,47 @@
+package diffui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+)
+
+// updateHelp handles key input while the ? overlay is open.
+func (m Model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, globalKeys.ForceQuit):
+		m.quitting = true
+		return m, tea.Quit
+	case key.Matches(msg, helpKeys.Close):
+		m.showingHelp = false
+	case key.Matches(msg, helpKeys.Down):
+		m.helpScroll = min(m.helpScroll+1, max(len(helpLines())-1, 0))
+	case key.Matches(msg, helpKeys.Up):
+		m.helpScroll = max(m.helpScroll-1, 0)
+	}
+	return m, nil
+}
+
+func helpLines() []string {
+	sectionStyle := lipgloss.NewStyle().Foreground(colorSecondary).Bold(true)
+	return keyhelp.Lines(keySections(), sectionStyle, fileNameBoldStyle)
+}
+
+// renderHelp renders the keybinding list in place of the tab content.
+func (m Model) renderHelp(height int) string {
+	lines := helpLines()
+	start := min(m.helpScroll, max(len(lines)-1, 0))
+	end := len(lines)
+	if height > 0 && start+height < end {
+		end = start + height
+	}
+	var b strings.Builder
+	for _, l := range lines[start:end] {
+		b.WriteString("  " + l + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,143 @@
+package diffui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+)
+
+// The key bindings of each tab and prompt. Update matches keys against these
+// and the ? overlay lists them, so the overlay always shows the keys that
+// work. The label prompt edits text and keeps its own enter/esc handling.
+
+func newKey(help, desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(help, desc))
+}
+
+var (
+	keyUp     = newKey("↑/k", "up", "up", "k")
+	keyDown   = newKey("↓/j", "down", "down", "j")
+	keyTop    = newKey("g", "top", "g")
+	keyBottom = newKey("G", "bottom", "G")
+)
+
+var globalKeys = struct {
+	NextTab    key.Binding
+	PrevTab    key.Binding
+	ChangesTab key.Binding
+	ChecksTab  key.Binding
+	Help       key.Binding
+	Quit       key.Binding
+	ForceQuit  key.Binding
+}{
+	NextTab:    newKey("tab", "next tab", "tab"),
+	PrevTab:    newKey("shift+tab", "previous tab", "shift+tab"),
+	ChangesTab: newKey("1", "Changes tab", "1"),
+	ChecksTab:  newKey("2", "Checks tab", "2"),
+	Help:       newKey("?", "help", "?"),
+	Quit:       newKey("q", "quit", "q"),
+	ForceQuit:  newKey("ctrl+c", "quit, also from prompts", "ctrl+c"),
+}
+
+var changesKeys = struct {
+	Up         key.Binding
+	Down       key.Binding
+	Top        key.Binding
+	Bottom     key.Binding
+	Open       key.Binding
+	Fixup      key.Binding
+	Autosquash key.Binding
+	Tree       key.Binding
+	Fold       key.Binding
+	Wrap       key.Binding
+	Right      key.Binding
+	Left       key.Binding
+}{
+	Up:         keyUp,
+	Down:       keyDown,
+	Top:        keyTop,
+	Bottom:     keyBottom,
+	Open:       newKey("enter", "open in zed, or fold a directory", "enter"),
+	Fixup:      newKey("f", "fixup commit for the file", "f"),
+	Autosquash: newKey("A", "autosquash fixups", "A"),
+	Tree:       newKey("t", "tree / flat view", "t"),
+	Fold:       newKey("space", "fold directory", " "),
+	Wrap:       newKey("w", "wrap / truncate paths", "w"),
+	Right:      newKey("→/l", "scroll paths right", "right", "l"),
+	Left:       newKey("←/h", "scroll paths left", "left", "h"),
+}
+
+var checksKeys = struct {
+	Up           key.Binding
+	Down         key.Binding
+	Top          key.Binding
+	Bottom       key.Binding
+	OpenPR       key.Binding
+	PickPR       key.Binding
+	AddLabel     key.Binding
+	RemoveLabel  key.Binding
+	AllChecks    key.Binding
+	MoreComments key.Binding
+}{
+	Up:           keyUp,
+	Down:         keyDown,
+	Top:          keyTop,
+	Bottom:       keyBottom,
+	OpenPR:       newKey("o", "open PR, or draft one on GitHub", "o"),
+	PickPR:       newKey("p", "pick another PR", "p"),
+	AddLabel:     newKey("+", "add label", "+"),
+	RemoveLabel:  newKey("-", "remove label", "-"),
+	AllChecks:    newKey("a", "show all / fewer checks", "a"),
+	MoreComments: newKey("m", "load older comments", "m"),
+}
+
+var pickerKeys = struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Close  key.Binding
+}{
+	Up:     keyUp,
+	Down:   keyDown,
+	Select: newKey("enter", "select PR", "enter"),
+	Close:  newKey("esc/p", "cancel", "esc", "p"),
+}
+
+var helpKeys = struct {
+	Up    key.Binding
+	Down  key.Binding
+	Close key.Binding
+}{
+	Up:    keyUp,
+	Down:  keyDown,
+	Close: newKey("esc/q/?", "close", "esc", "q", "?"),
+}
+
+// Keymaps returns the keymap of each tab and prompt under its name in the
+// `keybindings` config, for keyhelp.Rebind.
+func Keymaps() map[string]any {
+	return map[string]any{
+		"diff":         &globalKeys,
+		"diff_changes": &changesKeys,
+		"diff_checks":  &checksKeys,
+		"diff_picker":  &pickerKeys,
+		"diff_help":    &helpKeys,
+	}
+}
+
+// keyHelp describes b in a help line with a desc that depends on the view,
+// such as "t: flat view" while the tree is shown.
+func keyHelp(b key.Binding, desc string) string {
+	return b.Help().Key + ": " + desc
+}
+
+// keySections lists the bindings of every tab and prompt for the ? overlay.
+func keySections() []keyhelp.Section {
+	return []keyhelp.Section{
+		{Title: "Everywhere", Keys: keyhelp.Bindings(globalKeys)},
+		{Title: "Changes tab", Keys: keyhelp.Bindings(changesKeys)},
+		{Title: "Checks tab", Keys: keyhelp.Bindings(checksKeys)},
+		{Title: "PR picker (p)", Keys: keyhelp.Bindings(pickerKeys)},
+		{Title: "Help (?)", Keys: keyhelp.Bindings(helpKeys)},
+	}
+}
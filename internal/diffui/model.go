@@ -1,18 +1,24 @@
 package diffui
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	zone "github.com/lrstanley/bubblezone"
 
+	"github.com/mikanfactory/yakumo/internal/forge"
 	"github.com/mikanfactory/yakumo/internal/git"
 	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/logging"
 )
 
 // === Tab ===
@@ -36,9 +42,22 @@ type ChangedFile struct {
 type CheckResult struct {
 	Name     string
 	Passed   bool
+	Pending  bool
 	Duration string
 }
 
+// Failed reports whether the check finished without passing.
+func (c CheckResult) Failed() bool {
+	return !c.Passed && !c.Pending
+}
+
+// PRCandidate is one of several open PRs that share the current branch.
+type PRCandidate struct {
+	Number int
+	Title  string
+	Base   string
+}
+
 type PRComment struct {
 	Author  string
 	Preview string
@@ -62,6 +81,14 @@ type ChecksDataErrMsg struct {
 	Err error
 }
 
+// OlderCommentsMsg carries a page of comments older than those shown, for
+// the PR numbered PRNumber.
+type OlderCommentsMsg struct {
+	PRNumber int
+	Page     github.CommentPage
+	Err      error
+}
+
 type OpenEditorResultMsg struct {
 	Err error
 }
@@ -70,6 +97,24 @@ type OpenPRResultMsg struct {
 	Err error
 }
 
+type LabelEditedMsg struct {
+	Err error
+}
+
+// FixupCommittedMsg is sent when a file's changes were committed as a
+// fixup! of Subject.
+type FixupCommittedMsg struct {
+	Subject string
+	Err     error
+}
+
+// AutosquashResultMsg is sent when the autosquash rebase finishes. Non-empty
+// Conflicts means it stopped and is still in progress.
+type AutosquashResultMsg struct {
+	Conflicts []string
+	Err       error
+}
+
 type TickMsg time.Time
 
 // === Sub-Models ===
@@ -80,20 +125,56 @@ type ChangesModel struct {
 	scrollOff int
 	loading   bool
 	err       error
+
+	// tree groups files under collapsible directory headers; collapsed holds
+	// the directories whose files are hidden.
+	tree      bool
+	collapsed map[string]bool
+
+	// wrap shows long paths in full across several lines; otherwise they are
+	// truncated in the middle, or scrolled sideways by hOffset columns.
+	wrap    bool
+	hOffset int
+}
+
+// changeRow is one line of the Changes tab: a file, or in tree mode a
+// directory header carrying the rolled-up stats of its files.
+type changeRow struct {
+	dir       string
+	file      int // index into files, or -1 for a directory header
+	count     int
+	additions int
+	deletions int
 }
 
 type ChecksModel struct {
+	prNumber      int
+	candidates    []PRCandidate
 	prTitle       string
 	prDescription string
 	prURL         string
+	labels        []string
+	reviewers     []string
+	assignees     []string
 	gitStatus     string
 	commitsBehind int
 	checks        []CheckResult
-	comments      []PRComment
-	todos         []string
-	scrollOff     int
-	loading       bool
-	err           error
+	baseBranch    string
+	baseState     string          // github.Rollup* of the base branch's latest CI run
+	baseFailing   map[string]bool // names of the checks failing on the base branch
+	comments      []PRComment     // oldest first
+	// commentsTotal counts every comment of the PR; on a large PR only the
+	// newest page is fetched on each poll, and older pages are loaded on
+	// request from commentsBefore, the cursor of the next older page.
+	commentsTotal   int
+	commentsBefore  string
+	olderComments   int // how many of comments were loaded as older pages
+	loadingComments bool
+	showAllChecks   bool
+	todos           []string
+	scrollOff       int
+	loading         bool
+	err             error
 }
 
 // === Main Model ===
@@ -111,6 +192,28 @@ func defaultCommandStarter(name string, args ...string) error {
 	return nil
 }
 
+// labelAction identifies whether the label input adds or removes a label.
+type labelAction int
+
+const (
+	labelActionNone labelAction = iota
+	labelActionAdd
+	labelActionRemove
+)
+
+// PRSelectionStore remembers which PR was picked for a worktree when its
+// branch has several open PRs.
+type PRSelectionStore interface {
+	Get(worktreePath string) int
+	Set(worktreePath string, number int) error
+}
+
+// TodoStore supplies the todos recorded for a worktree, such as the one
+// seeded when the worktree was created from an issue.
+type TodoStore interface {
+	Get(worktreePath string) []string
+}
+
 type Model struct {
 	activeTab Tab
 	width     int
@@ -119,28 +222,47 @@ type Model struct {
 
 	repoDir   string
 	gitRunner git.CommandRunner
-	ghRunner  github.Runner
+	provider  forge.Provider
 	baseRef   string
+	diffBase  git.DiffBase
 
 	editorStarter CommandStarter
 
 	statusMsg string
 
+	labelAction labelAction
+	labelInput  textinput.Model
+
+	prStore      PRSelectionStore
+	selectedPR   int
+	todos        []string
+	pickingPR    bool
+	pickerCursor int
+	showingHelp  bool
+	helpScroll   int
+
 	changes ChangesModel
 	checks  ChecksModel
 }
 
 // NewModel creates a new diff UI model.
-func NewModel(repoDir string, gitRunner git.CommandRunner, ghRunner github.Runner, baseRef string) Model {
+// provider may be nil, in which case the Checks tab reports that no forge is available.
+func NewModel(repoDir string, gitRunner git.CommandRunner, provider forge.Provider, baseRef string) Model {
+	ti := textinput.New()
+	ti.Placeholder = "label name"
+	ti.CharLimit = 100
+	ti.Width = 40
+
 	return Model{
 		activeTab:     TabChanges,
 		width:         80,
 		height:        24,
 		repoDir:       repoDir,
 		gitRunner:     gitRunner,
-		ghRunner:      ghRunner,
+		provider:      provider,
 		baseRef:       baseRef,
 		editorStarter: defaultCommandStarter,
+		labelInput:    ti,
 		changes: ChangesModel{
 			loading: true,
 		},
@@ -150,15 +272,54 @@ func NewModel(repoDir string, gitRunner git.CommandRunner, ghRunner github.Runne
 	}
 }
 
+// WithPRSelectionStore returns a copy of the model that remembers PR picks in
+// store and starts with the selection previously saved for repoDir.
+func (m Model) WithPRSelectionStore(store PRSelectionStore) Model {
+	m.prStore = store
+	if store != nil {
+		m.selectedPR = store.Get(m.repoDir)
+	}
+	return m
+}
+
+// WithDiffBase returns a copy of the model whose Changes tab compares the
+// branch against the commit chosen by strategy instead of the merge base.
+func (m Model) WithDiffBase(strategy git.DiffBase) Model {
+	m.diffBase = strategy
+	return m
+}
+
+// WithTodoStore returns a copy of the model that lists the todos saved for
+// repoDir in the Checks tab.
+func (m Model) WithTodoStore(store TodoStore) Model {
+	if store != nil {
+		m.todos = store.Get(m.repoDir)
+	}
+	m.checks.todos = m.todos
+	return m
+}
+
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
-		fetchChangesCmd(m.gitRunner, m.repoDir, m.baseRef),
-		fetchChecksCmd(m.ghRunner, m.gitRunner, m.repoDir, m.baseRef),
+		fetchChangesCmd(m.gitRunner, m.repoDir, m.baseRef, m.diffBase),
+		fetchChecksCmd(m.provider, m.gitRunner, m.repoDir, m.baseRef, m.selectedPR),
 		tickCmd(),
 	)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.labelAction != labelActionNone {
+		return m.updateLabelInput(keyMsg)
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.pickingPR {
+		return m.updatePRPicker(keyMsg)
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.showingHelp {
+		return m.updateHelp(keyMsg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -170,6 +331,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			files:     msg.Files,
 			cursor:    m.changes.cursor,
 			scrollOff: m.changes.scrollOff,
+			tree:      m.changes.tree,
+			collapsed: m.changes.collapsed,
+			wrap:      m.changes.wrap,
+			hOffset:   m.changes.hOffset,
 		}
 		return m, nil
 
@@ -180,9 +345,34 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case ChecksDataMsg:
 		msg.Checks.scrollOff = m.checks.scrollOff
+		msg.Checks.todos = m.todos
+		msg.Checks.showAllChecks = m.checks.showAllChecks
+		if n := m.checks.olderComments; n > 0 && msg.Checks.prNumber == m.checks.prNumber {
+			// A poll only refreshes the newest page; keep the older pages
+			// the user loaded above it.
+			msg.Checks.comments = append(slices.Clip(m.checks.comments[:n]), msg.Checks.comments...)
+			msg.Checks.olderComments = n
+			msg.Checks.commentsBefore = m.checks.commentsBefore
+		}
 		m.checks = msg.Checks
 		return m, nil
 
+	case OlderCommentsMsg:
+		if msg.PRNumber != m.checks.prNumber {
+			return m, nil
+		}
+		m.checks.loadingComments = false
+		if msg.Err != nil {
+			m.statusMsg = fmt.Sprintf("loading older comments: %v", msg.Err)
+			return m, nil
+		}
+		older := prComments(msg.Page.Comments)
+		m.checks.comments = append(older, m.checks.comments...)
+		m.checks.olderComments += len(older)
+		m.checks.commentsBefore = msg.Page.Before
+		m.checks.commentsTotal = msg.Page.TotalCount
+		return m, nil
+
 	case ChecksDataErrMsg:
 		m.checks.loading = false
 		m.checks.err = msg.Err
@@ -200,6 +390,32 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case LabelEditedMsg:
+		if msg.Err != nil {
+			m.statusMsg = msg.Err.Error()
+			return m, nil
+		}
+		return m, fetchChecksCmd(m.provider, m.gitRunner, m.repoDir, m.baseRef, m.selectedPR)
+
+	case FixupCommittedMsg:
+		if msg.Err != nil {
+			m.statusMsg = msg.Err.Error()
+			return m, nil
+		}
+		m.statusMsg = "committed fixup! " + msg.Subject + " (A: autosquash)"
+		return m, fetchChangesCmd(m.gitRunner, m.repoDir, m.baseRef, m.diffBase)
+
+	case AutosquashResultMsg:
+		switch {
+		case len(msg.Conflicts) > 0:
+			m.statusMsg = "autosquash stopped on conflicts in " + strings.Join(msg.Conflicts, ", ") + "; resolve them, then continue from the sidebar (R)"
+		case msg.Err != nil:
+			m.statusMsg = msg.Err.Error()
+		default:
+			m.statusMsg = "fixups squashed"
+		}
+		return m, fetchChangesCmd(m.gitRunner, m.repoDir, m.baseRef, m.diffBase)
+
 	case tea.MouseMsg:
 		if msg.Action == tea.MouseActionRelease && m.activeTab == TabChecks {
 			if zone.Get("open-pr").InBounds(msg) && m.checks.prURL != "" {
@@ -210,47 +426,119 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case TickMsg:
 		return m, tea.Batch(
-			fetchChangesCmd(m.gitRunner, m.repoDir, m.baseRef),
-			fetchChecksCmd(m.ghRunner, m.gitRunner, m.repoDir, m.baseRef),
+			fetchChangesCmd(m.gitRunner, m.repoDir, m.baseRef, m.diffBase),
+			fetchChecksCmd(m.provider, m.gitRunner, m.repoDir, m.baseRef, m.selectedPR),
 			tickCmd(),
 		)
 
 	case tea.KeyMsg:
 		m.statusMsg = ""
 
-		switch msg.String() {
-		case "ctrl+c", "q":
+		switch {
+		case key.Matches(msg, globalKeys.Quit, globalKeys.ForceQuit):
 			m.quitting = true
 			return m, tea.Quit
 
-		case "tab":
+		case key.Matches(msg, globalKeys.NextTab):
 			m.activeTab = (m.activeTab + 1) % tabCount
 			return m, tea.Batch(
-				fetchChangesCmd(m.gitRunner, m.repoDir, m.baseRef),
-				fetchChecksCmd(m.ghRunner, m.gitRunner, m.repoDir, m.baseRef),
+				fetchChangesCmd(m.gitRunner, m.repoDir, m.baseRef, m.diffBase),
+				fetchChecksCmd(m.provider, m.gitRunner, m.repoDir, m.baseRef, m.selectedPR),
 			)
 
-		case "shift+tab":
+		case key.Matches(msg, globalKeys.PrevTab):
 			m.activeTab = (m.activeTab + tabCount - 1) % tabCount
 			return m, tea.Batch(
-				fetchChangesCmd(m.gitRunner, m.repoDir, m.baseRef),
-				fetchChecksCmd(m.ghRunner, m.gitRunner, m.repoDir, m.baseRef),
+				fetchChangesCmd(m.gitRunner, m.repoDir, m.baseRef, m.diffBase),
+				fetchChecksCmd(m.provider, m.gitRunner, m.repoDir, m.baseRef, m.selectedPR),
 			)
 
-		case "1":
+		case key.Matches(msg, globalKeys.ChangesTab):
 			m.activeTab = TabChanges
 			return m, nil
 
-		case "2":
+		case key.Matches(msg, globalKeys.ChecksTab):
 			m.activeTab = TabChecks
 			return m, nil
 
-		case "enter":
-			if m.activeTab == TabChanges && len(m.changes.files) > 0 {
-				file := m.changes.files[m.changes.cursor]
+		case key.Matches(msg, checksKeys.AddLabel, checksKeys.RemoveLabel):
+			if m.activeTab != TabChecks || m.checks.prURL == "" || m.provider == nil {
+				return m, nil
+			}
+			m.labelAction = labelActionAdd
+			if key.Matches(msg, checksKeys.RemoveLabel) {
+				m.labelAction = labelActionRemove
+			}
+			m.labelInput.SetValue("")
+			return m, m.labelInput.Focus()
+
+		case key.Matches(msg, checksKeys.OpenPR):
+			if m.canDraftPR() {
+				return m, openDraftPRCmd(m.gitRunner, m.repoDir, m.baseRef)
+			}
+			if m.activeTab == TabChecks {
+				var cmd tea.Cmd
+				m.checks, cmd = m.checks.update(msg)
+				return m, cmd
+			}
+			return m, nil
+
+		case key.Matches(msg, checksKeys.MoreComments):
+			pager, ok := m.provider.(forge.CommentPager)
+			if m.activeTab != TabChecks || !ok || m.checks.commentsBefore == "" || m.checks.loadingComments {
+				return m, nil
+			}
+			m.checks.loadingComments = true
+			return m, fetchOlderCommentsCmd(pager, m.repoDir, m.checks.prNumber, m.checks.commentsBefore)
+
+		case key.Matches(msg, checksKeys.AllChecks):
+			if m.activeTab == TabChecks {
+				m.checks.showAllChecks = !m.checks.showAllChecks
+			}
+			return m, nil
+
+		case key.Matches(msg, checksKeys.PickPR):
+			if m.activeTab == TabChecks && len(m.checks.candidates) > 1 {
+				m.pickingPR = true
+				m.pickerCursor = 0
+				for i, c := range m.checks.candidates {
+					if c.Number == m.checks.prNumber {
+						m.pickerCursor = i
+					}
+				}
+			}
+			return m, nil
+
+		case key.Matches(msg, changesKeys.Fixup):
+			if m.activeTab != TabChanges {
+				return m, nil
+			}
+			if file, ok := m.changes.selectedFile(); ok {
+				return m, fixupCmd(m.gitRunner, m.repoDir, m.baseRef, file.Path)
+			}
+			return m, nil
+
+		case key.Matches(msg, changesKeys.Autosquash):
+			if m.activeTab != TabChanges {
+				return m, nil
+			}
+			m.statusMsg = "squashing fixups..."
+			return m, autosquashCmd(m.gitRunner, m.repoDir, m.baseRef)
+
+		case key.Matches(msg, changesKeys.Open):
+			if m.activeTab != TabChanges {
+				return m, nil
+			}
+			if file, ok := m.changes.selectedFile(); ok {
 				fullPath := filepath.Join(m.repoDir, file.Path)
 				return m, openZedCmd(m.editorStarter, fullPath)
 			}
+			m.changes = m.changes.toggleCollapsed()
+			return m, nil
+
+		case key.Matches(msg, globalKeys.Help):
+			m.showingHelp = true
+			m.helpScroll = 0
 			return m, nil
 
 		default:
@@ -270,42 +558,220 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updateLabelInput handles key input while the label add/remove prompt is open.
+func (m Model) updateLabelInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		m.labelAction = labelActionNone
+		m.labelInput.Blur()
+		return m, nil
+	case tea.KeyCtrlC:
+		m.quitting = true
+		return m, tea.Quit
+	case tea.KeyEnter:
+		label := strings.TrimSpace(m.labelInput.Value())
+		action := m.labelAction
+		m.labelAction = labelActionNone
+		m.labelInput.Blur()
+		if label == "" {
+			return m, nil
+		}
+		return m, editLabelCmd(m.provider, m.repoDir, m.checks.prNumber, label, action)
+	}
+
+	var cmd tea.Cmd
+	m.labelInput, cmd = m.labelInput.Update(msg)
+	return m, cmd
+}
+
+// updatePRPicker handles key input while the PR picker is open.
+func (m Model) updatePRPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, pickerKeys.Close):
+		m.pickingPR = false
+	case key.Matches(msg, globalKeys.ForceQuit):
+		m.quitting = true
+		return m, tea.Quit
+	case key.Matches(msg, pickerKeys.Up):
+		if m.pickerCursor > 0 {
+			m.pickerCursor--
+		}
+	case key.Matches(msg, pickerKeys.Down):
+		if m.pickerCursor < len(m.checks.candidates)-1 {
+			m.pickerCursor++
+		}
+	case key.Matches(msg, pickerKeys.Select):
+		m.pickingPR = false
+		if m.pickerCursor >= len(m.checks.candidates) {
+			return m, nil
+		}
+		m.selectedPR = m.checks.candidates[m.pickerCursor].Number
+		if m.prStore != nil {
+			if err := m.prStore.Set(m.repoDir, m.selectedPR); err != nil {
+				m.statusMsg = err.Error()
+			}
+		}
+		m.checks.loading = true
+		return m, fetchChecksCmd(m.provider, m.gitRunner, m.repoDir, m.baseRef, m.selectedPR)
+	}
+	return m, nil
+}
+
 // === Sub-Model Update Methods ===
 
 func (m ChangesModel) update(msg tea.KeyMsg) ChangesModel {
-	switch msg.String() {
-	case "up", "k":
+	rows := m.rows()
+	switch {
+	case key.Matches(msg, changesKeys.Up):
 		if m.cursor > 0 {
 			m.cursor--
 		}
-	case "down", "j":
-		if m.cursor < len(m.files)-1 {
+	case key.Matches(msg, changesKeys.Down):
+		if m.cursor < len(rows)-1 {
 			m.cursor++
 		}
-	case "g":
+	case key.Matches(msg, changesKeys.Top):
 		m.cursor = 0
-	case "G":
-		if len(m.files) > 0 {
-			m.cursor = len(m.files) - 1
+	case key.Matches(msg, changesKeys.Bottom):
+		if len(rows) > 0 {
+			m.cursor = len(rows) - 1
+		}
+	case key.Matches(msg, changesKeys.Tree):
+		m = m.toggleTree()
+	case key.Matches(msg, changesKeys.Fold):
+		m = m.toggleCollapsed()
+	case key.Matches(msg, changesKeys.Wrap):
+		m.wrap = !m.wrap
+		m.hOffset = 0
+	case key.Matches(msg, changesKeys.Right):
+		if !m.wrap {
+			m.hOffset = min(m.hOffset+hScrollStep, m.longestPath())
+		}
+	case key.Matches(msg, changesKeys.Left):
+		m.hOffset = max(m.hOffset-hScrollStep, 0)
+	}
+	return m
+}
+
+// hScrollStep is how many columns left/right scroll long paths.
+const hScrollStep = 8
+
+// longestPath returns the width of the longest path, bounding hOffset.
+func (m ChangesModel) longestPath() int {
+	longest := 0
+	for _, f := range m.files {
+		longest = max(longest, len(f.Path))
+	}
+	return longest
+}
+
+// rows returns the lines the cursor moves over. In flat mode that is one row
+// per file; in tree mode files are grouped under a header per directory, in
+// order of first appearance, and collapsed directories show only the header.
+func (m ChangesModel) rows() []changeRow {
+	if !m.tree {
+		rows := make([]changeRow, len(m.files))
+		for i := range m.files {
+			rows[i] = changeRow{file: i}
+		}
+		return rows
+	}
+
+	var dirs []string
+	byDir := make(map[string][]int)
+	for i, f := range m.files {
+		dir := filepath.Dir(f.Path)
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], i)
+	}
+
+	var rows []changeRow
+	for _, dir := range dirs {
+		header := changeRow{dir: dir, file: -1, count: len(byDir[dir])}
+		for _, i := range byDir[dir] {
+			header.additions += m.files[i].Additions
+			header.deletions += m.files[i].Deletions
+		}
+		rows = append(rows, header)
+		if m.collapsed[dir] {
+			continue
+		}
+		for _, i := range byDir[dir] {
+			rows = append(rows, changeRow{dir: dir, file: i})
+		}
+	}
+	return rows
+}
+
+// selectedFile returns the file under the cursor, or false when the cursor
+// is on a directory header or the list is empty.
+func (m ChangesModel) selectedFile() (ChangedFile, bool) {
+	rows := m.rows()
+	if m.cursor < 0 || m.cursor >= len(rows) || rows[m.cursor].file < 0 {
+		return ChangedFile{}, false
+	}
+	return m.files[rows[m.cursor].file], true
+}
+
+// toggleCollapsed folds or unfolds the directory of the row under the cursor,
+// leaving the cursor on that directory's header.
+func (m ChangesModel) toggleCollapsed() ChangesModel {
+	rows := m.rows()
+	if !m.tree || m.cursor >= len(rows) {
+		return m
+	}
+	dir := rows[m.cursor].dir
+	collapsed := make(map[string]bool, len(m.collapsed)+1)
+	for d, c := range m.collapsed {
+		collapsed[d] = c
+	}
+	collapsed[dir] = !collapsed[dir]
+	m.collapsed = collapsed
+
+	for i, r := range m.rows() {
+		if r.file < 0 && r.dir == dir {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
+
+// toggleTree switches between the flat and tree layouts, keeping the cursor
+// on the same file where it is still visible.
+func (m ChangesModel) toggleTree() ChangesModel {
+	rows := m.rows()
+	selected := -1
+	if m.cursor < len(rows) {
+		selected = rows[m.cursor].file
+	}
+	m.tree = !m.tree
+	m.cursor = 0
+	for i, r := range m.rows() {
+		if selected >= 0 && r.file == selected {
+			m.cursor = i
+			break
 		}
 	}
 	return m
 }
 
 func (m ChecksModel) update(msg tea.KeyMsg) (ChecksModel, tea.Cmd) {
-	switch msg.String() {
-	case "up", "k":
+	switch {
+	case key.Matches(msg, checksKeys.Up):
 		if m.scrollOff > 0 {
 			m.scrollOff--
 		}
-	case "down", "j":
+	case key.Matches(msg, checksKeys.Down):
 		m.scrollOff++
-	case "g":
+	case key.Matches(msg, checksKeys.Top):
 		m.scrollOff = 0
-	case "G":
+	case key.Matches(msg, checksKeys.Bottom):
 		// Let the view clamp this
 		m.scrollOff = 999
-	case "o":
+	case key.Matches(msg, checksKeys.OpenPR):
 		if m.prURL != "" {
 			return m, openPRInBrowserCmd(m.prURL)
 		}
@@ -342,12 +808,97 @@ func openPRInBrowserCmd(url string) tea.Cmd {
 	}
 }
 
+// canDraftPR reports whether the Checks tab is showing a GitHub branch with no
+// pull request, in which case "o" drafts one using the branch description.
+func (m Model) canDraftPR() bool {
+	return m.activeTab == TabChecks && m.checks.prURL == "" &&
+		errors.Is(m.checks.err, github.ErrNoPullRequest) &&
+		m.provider != nil && m.provider.Kind() == forge.KindGitHub
+}
+
+// draftPRURL builds the GitHub compare page for the branch checked out in
+// dir, using its branch description as the PR body draft.
+func draftPRURL(gitRunner git.CommandRunner, dir, baseRef string) (string, error) {
+	branch, err := git.CurrentBranch(gitRunner, dir)
+	if err != nil {
+		return "", fmt.Errorf("resolving current branch: %w", err)
+	}
+	remote, err := git.RemoteURL(gitRunner, dir)
+	if err != nil {
+		return "", fmt.Errorf("resolving origin remote: %w", err)
+	}
+	owner, repo, err := github.ParseRemoteURL(remote)
+	if err != nil {
+		return "", err
+	}
+	base := strings.TrimPrefix(normalizeBaseRef(baseRef), "origin/")
+	description := git.GetBranchDescriptions(gitRunner, dir)[branch]
+	return github.CompareURL(owner, repo, base, branch, description), nil
+}
+
+func openDraftPRCmd(gitRunner git.CommandRunner, dir, baseRef string) tea.Cmd {
+	return func() tea.Msg {
+		url, err := draftPRURL(gitRunner, dir, baseRef)
+		if err != nil {
+			return OpenPRResultMsg{Err: err}
+		}
+		return openPRInBrowserCmd(url)()
+	}
+}
+
+// === Fixup Commits ===
+
+// fixupCmd commits the uncommitted changes to path as a fixup of the branch
+// commit that last touched the changed lines.
+func fixupCmd(gitRunner git.CommandRunner, dir, baseRef, path string) tea.Cmd {
+	base := normalizeBaseRef(baseRef)
+	return func() tea.Msg {
+		target, subject, err := git.FixupTarget(gitRunner, dir, base, path)
+		if err != nil {
+			return FixupCommittedMsg{Err: err}
+		}
+		if err := git.CommitFixup(gitRunner, dir, path, target); err != nil {
+			return FixupCommittedMsg{Err: err}
+		}
+		return FixupCommittedMsg{Subject: subject}
+	}
+}
+
+func autosquashCmd(gitRunner git.CommandRunner, dir, baseRef string) tea.Cmd {
+	base := normalizeBaseRef(baseRef)
+	return func() tea.Msg {
+		err := git.AutosquashRebase(gitRunner, dir, base)
+		if errors.Is(err, git.ErrRebaseConflict) {
+			if files, ferr := git.ConflictedFiles(gitRunner, dir); ferr == nil {
+				return AutosquashResultMsg{Conflicts: files}
+			}
+		}
+		return AutosquashResultMsg{Err: err}
+	}
+}
+
+// === Edit PR Labels ===
+
+// editLabelCmd edits the labels of the PR the checks tab shows, which may be
+// one picked among several for the branch.
+func editLabelCmd(provider forge.Provider, dir string, number int, label string, action labelAction) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		if action == labelActionRemove {
+			err = provider.RemoveLabel(dir, number, label)
+		} else {
+			err = provider.AddLabel(dir, number, label)
+		}
+		return LabelEditedMsg{Err: err}
+	}
+}
+
 // === Data Fetching Commands ===
 
-func fetchChangesCmd(runner git.CommandRunner, dir, baseRef string) tea.Cmd {
+func fetchChangesCmd(runner git.CommandRunner, dir, baseRef string, strategy git.DiffBase) tea.Cmd {
 	base := normalizeBaseRef(baseRef)
 	return func() tea.Msg {
-		entries, err := git.GetAllChanges(runner, dir, base)
+		entries, err := git.GetChanges(runner, dir, base, strategy)
 		if err != nil {
 			return ChangesDataErrMsg{Err: err}
 		}
@@ -363,10 +914,43 @@ func fetchChangesCmd(runner git.CommandRunner, dir, baseRef string) tea.Cmd {
 	}
 }
 
-func fetchChecksCmd(ghRunner github.Runner, gitRunner git.CommandRunner, dir, baseRef string) tea.Cmd {
+// resolvePR returns the PR to display for dir. When the current branch has
+// several open PRs, the one numbered selected wins (falling back to the first)
+// and all of them are returned as candidates for the picker.
+func resolvePR(provider forge.Provider, gitRunner git.CommandRunner, dir string, selected int) (github.PRView, []PRCandidate, error) {
+	if provider == nil {
+		return github.PRView{}, nil, fmt.Errorf("no forge available: install gh or set a GitHub token")
+	}
+	if gitRunner != nil {
+		if branch, err := git.CurrentBranch(gitRunner, dir); err == nil {
+			prs, err := provider.FetchPRs(dir, branch)
+			if err == nil && len(prs) == 1 {
+				return prs[0], nil, nil
+			}
+			if err == nil && len(prs) > 1 {
+				candidates := make([]PRCandidate, len(prs))
+				chosen := prs[0]
+				for i, pr := range prs {
+					candidates[i] = PRCandidate{Number: pr.Number, Title: pr.Title, Base: pr.BaseRefName}
+					if pr.Number == selected {
+						chosen = pr
+					}
+				}
+				return chosen, candidates, nil
+			}
+		}
+	}
+
+	// No open PR for the branch (or listing failed): let the forge resolve it,
+	// which also finds merged and closed PRs.
+	pr, err := provider.FetchPR(dir)
+	return pr, nil, err
+}
+
+func fetchChecksCmd(provider forge.Provider, gitRunner git.CommandRunner, dir, baseRef string, selectedPR int) tea.Cmd {
 	base := normalizeBaseRef(baseRef)
 	return func() tea.Msg {
-		pr, err := github.FetchPR(ghRunner, dir)
+		pr, candidates, err := resolvePR(provider, gitRunner, dir, selectedPR)
 		if err != nil {
 			return ChecksDataErrMsg{Err: err}
 		}
@@ -378,15 +962,37 @@ func fetchChecksCmd(ghRunner github.Runner, gitRunner git.CommandRunner, dir, ba
 			checks[i] = CheckResult{
 				Name:     sc.CheckName(),
 				Passed:   sc.Passed(),
+				Pending:  !sc.Passed() && sc.Pending(),
 				Duration: sc.DurationString(),
 			}
 		}
 
-		comments := make([]PRComment, len(pr.Comments))
-		for i, c := range pr.Comments {
-			comments[i] = PRComment{
-				Author:  c.Author.Login,
-				Preview: c.Preview(80),
+		baseBranch := pr.BaseRefName
+		if baseBranch == "" {
+			baseBranch = forge.BranchName(base)
+		}
+		// Best effort: without base CI the tab still shows the PR's own checks.
+		baseChecks, err := provider.FetchBranchChecks(dir, baseBranch)
+		if err != nil {
+			logging.For("diffui").Warn("fetching base branch checks failed (non-fatal)", "branch", baseBranch, "err", err)
+		}
+		var baseFailing map[string]bool
+		for _, sc := range baseChecks {
+			if sc.Failed() {
+				if baseFailing == nil {
+					baseFailing = make(map[string]bool)
+				}
+				baseFailing[sc.CheckName()] = true
+			}
+		}
+
+		// Forges that page comments leave them out of the PR; fetch just
+		// the newest page so polling stays cheap on PRs with hundreds.
+		comments, commentsTotal, commentsBefore := prComments(pr.Comments), len(pr.Comments), ""
+		if pager, ok := provider.(forge.CommentPager); ok && pr.Number != 0 {
+			// Best effort, like the base branch checks.
+			if page, err := pager.FetchComments(dir, pr.Number, commentPageSize, ""); err == nil {
+				comments, commentsTotal, commentsBefore = prComments(page.Comments), page.TotalCount, page.Before
 			}
 		}
 
@@ -394,19 +1000,50 @@ func fetchChecksCmd(ghRunner github.Runner, gitRunner git.CommandRunner, dir, ba
 
 		return ChecksDataMsg{
 			Checks: ChecksModel{
-				prTitle:       pr.Title,
-				prDescription: pr.Body,
-				prURL:         pr.URL,
-				gitStatus:     gitStatus,
-				commitsBehind: commitsBehind,
-				checks:        checks,
-				comments:      comments,
-				todos:         []string{},
+				prNumber:       pr.Number,
+				candidates:     candidates,
+				prTitle:        pr.Title,
+				prDescription:  pr.Body,
+				prURL:          pr.URL,
+				labels:         pr.LabelNames(),
+				reviewers:      pr.ReviewerNames(),
+				assignees:      pr.AssigneeLogins(),
+				gitStatus:      gitStatus,
+				commitsBehind:  commitsBehind,
+				checks:         checks,
+				baseBranch:     baseBranch,
+				baseState:      github.PRView{StatusCheckRollup: baseChecks}.CheckRollup(),
+				baseFailing:    baseFailing,
+				comments:       comments,
+				commentsTotal:  commentsTotal,
+				commentsBefore: commentsBefore,
 			},
 		}
 	}
 }
 
+// commentPageSize is how many comments are fetched at a time: the newest
+// page on each poll, and each older page loaded with checksKeys.MoreComments.
+const commentPageSize = 20
+
+func fetchOlderCommentsCmd(pager forge.CommentPager, dir string, number int, before string) tea.Cmd {
+	return func() tea.Msg {
+		page, err := pager.FetchComments(dir, number, commentPageSize, before)
+		return OlderCommentsMsg{PRNumber: number, Page: page, Err: err}
+	}
+}
+
+func prComments(nodes []github.CommentNode) []PRComment {
+	comments := make([]PRComment, len(nodes))
+	for i, c := range nodes {
+		comments[i] = PRComment{
+			Author:  c.Author.Login,
+			Preview: c.Preview(80),
+		}
+	}
+	return comments
+}
+
 func tickCmd() tea.Cmd {
 	return tea.Tick(pollInterval, func(t time.Time) tea.Msg {
 		return TickMsg(t)
@@ -2,9 +2,16 @@ package diffui
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/mikanfactory/yakumo/internal/forge"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
 )
 
 func TestEnterOpensZedOnChangesTab(t *testing.T) {
@@ -178,3 +185,732 @@ func TestOKeyNoop_OnChangesTab(t *testing.T) {
 		t.Error("expected nil command when on Changes tab")
 	}
 }
+
+func TestPlusKeyOpensAddLabelInput(t *testing.T) {
+	m := NewModel("/repo", nil, forge.GitHub{Runner: &github.FakeRunner{}}, "origin/main")
+	m.activeTab = TabChecks
+	m.checks = ChecksModel{prURL: "https://github.com/owner/repo/pull/1"}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'+'}})
+	model := updated.(Model)
+
+	if model.labelAction != labelActionAdd {
+		t.Errorf("labelAction = %v, want labelActionAdd", model.labelAction)
+	}
+}
+
+func TestMinusKeyNoop_WithoutPR(t *testing.T) {
+	m := NewModel("/repo", nil, forge.GitHub{Runner: &github.FakeRunner{}}, "origin/main")
+	m.activeTab = TabChecks
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	model := updated.(Model)
+
+	if model.labelAction != labelActionNone {
+		t.Error("label input should not open when there is no PR")
+	}
+}
+
+func TestLabelInput_EnterRunsGhEdit(t *testing.T) {
+	runner := &github.FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[pr edit --remove-label bug]": "",
+		},
+	}
+	m := NewModel("/repo", nil, forge.GitHub{Runner: runner}, "origin/main")
+	m.activeTab = TabChecks
+	m.checks = ChecksModel{prURL: "https://github.com/owner/repo/pull/1"}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	m = updated.(Model)
+	m.labelInput.SetValue("bug")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.labelAction != labelActionNone {
+		t.Error("label input should close after enter")
+	}
+	if cmd == nil {
+		t.Fatal("expected a command, got nil")
+	}
+	msg, ok := cmd().(LabelEditedMsg)
+	if !ok {
+		t.Fatal("expected LabelEditedMsg")
+	}
+	if msg.Err != nil {
+		t.Errorf("unexpected error: %v", msg.Err)
+	}
+}
+
+func TestLabelInput_EscapeCancels(t *testing.T) {
+	m := NewModel("/repo", nil, forge.GitHub{Runner: &github.FakeRunner{}}, "origin/main")
+	m.labelAction = labelActionAdd
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	model := updated.(Model)
+
+	if model.labelAction != labelActionNone {
+		t.Error("escape should close the label input")
+	}
+	if cmd != nil {
+		t.Error("expected nil command on escape")
+	}
+}
+
+func TestLabelEditedMsg_ErrorSetsStatus(t *testing.T) {
+	m := Model{}
+
+	updated, _ := m.Update(LabelEditedMsg{Err: fmt.Errorf("label not found")})
+	model := updated.(Model)
+
+	if model.statusMsg != "label not found" {
+		t.Errorf("statusMsg = %q, want %q", model.statusMsg, "label not found")
+	}
+}
+
+func TestChecksView_ShowsMetadata(t *testing.T) {
+	m := ChecksModel{
+		prTitle:   "feat: x",
+		labels:    []string{"bug"},
+		reviewers: []string{"carol"},
+	}
+
+	view := m.view(80, 40)
+
+	for _, want := range []string{"Labels:", "bug", "Reviewers:", "carol", "Assignees:", "none"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should contain %q", want)
+		}
+	}
+}
+
+func TestChecksView_BaseBranchRed(t *testing.T) {
+	m := ChecksModel{
+		prTitle:     "feat: x",
+		checks:      []CheckResult{{Name: "CI"}, {Name: "Lint"}},
+		baseBranch:  "main",
+		baseState:   github.RollupFailure,
+		baseFailing: map[string]bool{"CI": true},
+	}
+
+	view := m.view(120, 40)
+
+	if !strings.Contains(view, "main is red") {
+		t.Errorf("view should flag the red base branch:\n%s", view)
+	}
+	if strings.Count(view, "also failing on main") != 1 {
+		t.Errorf("only CI should be marked as failing on main:\n%s", view)
+	}
+}
+
+func TestFetchChecksCmd_BaseBranchChecks(t *testing.T) {
+	gitRunner := git.FakeCommandRunner{
+		Outputs: map[string]string{"/repo:[symbolic-ref --short HEAD]": "feat\n"},
+	}
+	ghRunner := &github.FakeRunner{
+		Outputs: map[string]string{
+			prListKey("feat"): `[{"number": 1, "title": "one", "baseRefName": "develop", "statusCheckRollup": [{"name": "CI", "status": "COMPLETED", "conclusion": "FAILURE"}]}]`,
+			"/repo:[run list --branch develop --limit 50 --json headSha,workflowName,status,conclusion,startedAt,updatedAt]": `[{"headSha": "a", "workflowName": "CI", "status": "completed", "conclusion": "failure"}]`,
+		},
+	}
+
+	msg := fetchChecksCmd(forge.GitHub{Runner: ghRunner}, gitRunner, "/repo", "origin/main", 0)()
+	checks := msg.(ChecksDataMsg).Checks
+
+	if checks.baseBranch != "develop" || checks.baseState != github.RollupFailure || !checks.baseFailing["CI"] {
+		t.Errorf("base = %q %q %v, want develop failing CI", checks.baseBranch, checks.baseState, checks.baseFailing)
+	}
+}
+
+// fakeCommentPager serves pages of comments by cursor.
+type fakeCommentPager struct {
+	forge.GitHub
+	pages map[string]github.CommentPage
+	asked []string
+}
+
+func (f *fakeCommentPager) FetchComments(dir string, number, limit int, before string) (github.CommentPage, error) {
+	f.asked = append(f.asked, before)
+	return f.pages[before], nil
+}
+
+func comment(login string) github.CommentNode {
+	return github.CommentNode{Author: github.CommentAuthor{Login: login}, Body: "comment by " + login}
+}
+
+func TestFetchChecksCmd_FetchesNewestCommentPage(t *testing.T) {
+	gitRunner := git.FakeCommandRunner{
+		Outputs: map[string]string{"/repo:[symbolic-ref --short HEAD]": "feat\n"},
+	}
+	ghRunner := &github.FakeRunner{
+		Outputs: map[string]string{prListKey("feat"): `[{"number": 7, "title": "big one"}]`},
+	}
+	pager := &fakeCommentPager{
+		GitHub: forge.GitHub{Runner: ghRunner},
+		pages: map[string]github.CommentPage{
+			"": {Comments: []github.CommentNode{comment("erin")}, TotalCount: 150, Before: "c1"},
+		},
+	}
+
+	msg := fetchChecksCmd(pager, gitRunner, "/repo", "origin/main", 0)()
+	checks := msg.(ChecksDataMsg).Checks
+
+	if len(checks.comments) != 1 || checks.comments[0].Author != "erin" || checks.commentsTotal != 150 || checks.commentsBefore != "c1" {
+		t.Errorf("comments = %+v total %d before %q, want the newest page", checks.comments, checks.commentsTotal, checks.commentsBefore)
+	}
+	if view := ansi.Strip(checks.view(120, 60)); !strings.Contains(view, "↑ 149 older comments (m: load more)") {
+		t.Errorf("view should offer to load older comments:\n%s", view)
+	}
+}
+
+func TestMKeyLoadsOlderCommentsKeptAcrossPolls(t *testing.T) {
+	pager := &fakeCommentPager{pages: map[string]github.CommentPage{
+		"c1": {Comments: []github.CommentNode{comment("alice"), comment("bob")}, TotalCount: 3},
+	}}
+	m := Model{
+		activeTab: TabChecks,
+		provider:  pager,
+		checks: ChecksModel{
+			prNumber:       7,
+			comments:       []PRComment{{Author: "carol"}},
+			commentsTotal:  3,
+			commentsBefore: "c1",
+		},
+	}
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	m = result.(Model)
+	if cmd == nil || !m.checks.loadingComments {
+		t.Fatal("m should start loading the older page")
+	}
+	result, _ = m.Update(cmd())
+	m = result.(Model)
+
+	authors := func() string {
+		var names []string
+		for _, c := range m.checks.comments {
+			names = append(names, c.Author)
+		}
+		return strings.Join(names, ",")
+	}
+	if got := authors(); got != "alice,bob,carol" || m.checks.commentsBefore != "" {
+		t.Errorf("comments = %s before %q, want the older page above carol and no more pages", got, m.checks.commentsBefore)
+	}
+
+	// The next poll only brings the newest page, now with a new comment.
+	result, _ = m.Update(ChecksDataMsg{Checks: ChecksModel{
+		prNumber:       7,
+		comments:       []PRComment{{Author: "carol"}, {Author: "dave"}},
+		commentsTotal:  4,
+		commentsBefore: "c2",
+	}})
+	m = result.(Model)
+	if got := authors(); got != "alice,bob,carol,dave" || m.checks.commentsBefore != "" {
+		t.Errorf("after a poll comments = %s before %q, want the loaded pages kept", got, m.checks.commentsBefore)
+	}
+}
+
+func TestChecksView_FoldsPassedChecksOnLargePRs(t *testing.T) {
+	m := ChecksModel{prTitle: "huge"}
+	for i := range 40 {
+		m.checks = append(m.checks, CheckResult{Name: fmt.Sprintf("job-%02d", i), Passed: true})
+	}
+	m.checks[30] = CheckResult{Name: "lint", Passed: false}
+	m.checks[31] = CheckResult{Name: "e2e", Pending: true}
+
+	view := ansi.Strip(m.view(120, 80))
+	if !strings.Contains(view, "✓ 38 passed  ✗ 1 failed  ● 1 pending") {
+		t.Errorf("view should count checks per state:\n%s", view)
+	}
+	if !strings.Contains(view, "lint") || !strings.Contains(view, "e2e") || strings.Contains(view, "job-20") {
+		t.Errorf("failing and pending checks should be listed before passed ones are folded:\n%s", view)
+	}
+	if !strings.Contains(view, "… 25 more passed (a: show all)") {
+		t.Errorf("view should say how many passed checks are folded:\n%s", view)
+	}
+
+	result, _ := Model{activeTab: TabChecks, checks: m}.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if view := ansi.Strip(result.(Model).checks.view(120, 80)); !strings.Contains(view, "job-39") {
+		t.Errorf("a should list every check:\n%s", view)
+	}
+}
+
+func TestChecksView_ErrorBranches(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    string
+		notWant string
+	}{
+		{name: "no PR", err: fmt.Errorf("fetching PR: %w", github.ErrNoPullRequest), want: "No pull request for this branch", notWant: "Error:"},
+		{name: "not authenticated", err: github.ErrNotAuthenticated, want: "gh auth login"},
+		{name: "unclassified", err: fmt.Errorf("boom"), want: "Error: boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := ChecksModel{err: tt.err}.view(80, 20)
+			if !strings.Contains(view, tt.want) {
+				t.Errorf("view should contain %q, got %q", tt.want, view)
+			}
+			if tt.notWant != "" && strings.Contains(view, tt.notWant) {
+				t.Errorf("view should not contain %q", tt.notWant)
+			}
+		})
+	}
+}
+
+type fakePRStore struct {
+	selections map[string]int
+}
+
+func (s *fakePRStore) Get(worktreePath string) int { return s.selections[worktreePath] }
+
+func (s *fakePRStore) Set(worktreePath string, number int) error {
+	s.selections[worktreePath] = number
+	return nil
+}
+
+func prListKey(branch string) string {
+	return fmt.Sprintf("/repo:[pr list --head %s --state open --json baseRefName,number,title,body,state,mergeStateStatus,reviewDecision,statusCheckRollup,url,labels,assignees,reviewRequests,latestReviews]", branch)
+}
+
+func TestResolvePR_MultiplePRs_UsesSelection(t *testing.T) {
+	gitRunner := git.FakeCommandRunner{
+		Outputs: map[string]string{"/repo:[symbolic-ref --short HEAD]": "feat\n"},
+	}
+	ghRunner := &github.FakeRunner{
+		Outputs: map[string]string{
+			prListKey("feat"): `[{"number": 1, "title": "one", "baseRefName": "main"}, {"number": 2, "title": "two", "baseRefName": "release"}]`,
+		},
+	}
+
+	pr, candidates, err := resolvePR(forge.GitHub{Runner: ghRunner}, gitRunner, "/repo", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr.Number != 2 {
+		t.Errorf("pr.Number = %d, want 2", pr.Number)
+	}
+	if len(candidates) != 2 || candidates[1].Base != "release" {
+		t.Errorf("candidates = %+v, want 2 entries with release base", candidates)
+	}
+}
+
+func TestResolvePR_MultiplePRs_DefaultsToFirst(t *testing.T) {
+	gitRunner := git.FakeCommandRunner{
+		Outputs: map[string]string{"/repo:[symbolic-ref --short HEAD]": "feat\n"},
+	}
+	ghRunner := &github.FakeRunner{
+		Outputs: map[string]string{
+			prListKey("feat"): `[{"number": 1, "title": "one"}, {"number": 2, "title": "two"}]`,
+		},
+	}
+
+	pr, _, err := resolvePR(forge.GitHub{Runner: ghRunner}, gitRunner, "/repo", 99)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr.Number != 1 {
+		t.Errorf("pr.Number = %d, want 1", pr.Number)
+	}
+}
+
+func TestResolvePR_NoOpenPR_FallsBackToView(t *testing.T) {
+	gitRunner := git.FakeCommandRunner{
+		Outputs: map[string]string{"/repo:[symbolic-ref --short HEAD]": "feat\n"},
+	}
+	ghRunner := &github.FakeRunner{
+		Outputs: map[string]string{
+			prListKey("feat"): `[]`,
+			"/repo:[pr view --json number,title,body,state,mergeStateStatus,reviewDecision,statusCheckRollup,url,labels,assignees,reviewRequests,latestReviews]": `{"title": "merged one", "state": "MERGED"}`,
+		},
+	}
+
+	pr, candidates, err := resolvePR(forge.GitHub{Runner: ghRunner}, gitRunner, "/repo", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr.Title != "merged one" {
+		t.Errorf("pr.Title = %q, want %q", pr.Title, "merged one")
+	}
+	if candidates != nil {
+		t.Errorf("candidates = %v, want nil", candidates)
+	}
+}
+
+func TestPKeyOpensPicker_WithMultiplePRs(t *testing.T) {
+	m := Model{
+		activeTab: TabChecks,
+		checks: ChecksModel{
+			prNumber:   2,
+			candidates: []PRCandidate{{Number: 1}, {Number: 2}},
+		},
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	model := updated.(Model)
+
+	if !model.pickingPR {
+		t.Fatal("picker should open")
+	}
+	if model.pickerCursor != 1 {
+		t.Errorf("pickerCursor = %d, want 1 (current PR)", model.pickerCursor)
+	}
+}
+
+func TestPKeyNoop_WithSinglePR(t *testing.T) {
+	m := Model{activeTab: TabChecks, checks: ChecksModel{prNumber: 1}}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if updated.(Model).pickingPR {
+		t.Error("picker should not open for a single PR")
+	}
+}
+
+func TestPRPicker_EnterRemembersSelection(t *testing.T) {
+	store := &fakePRStore{selections: map[string]int{}}
+	m := Model{
+		activeTab:    TabChecks,
+		repoDir:      "/repo",
+		prStore:      store,
+		pickingPR:    true,
+		pickerCursor: 0,
+		checks: ChecksModel{
+			candidates: []PRCandidate{{Number: 1}, {Number: 2}},
+		},
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	updated, cmd := updated.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model := updated.(Model)
+
+	if model.pickingPR {
+		t.Error("picker should close after enter")
+	}
+	if model.selectedPR != 2 {
+		t.Errorf("selectedPR = %d, want 2", model.selectedPR)
+	}
+	if store.selections["/repo"] != 2 {
+		t.Errorf("store selection = %d, want 2", store.selections["/repo"])
+	}
+	if cmd == nil {
+		t.Error("expected a refetch command")
+	}
+}
+
+func TestWithPRSelectionStore_LoadsSelection(t *testing.T) {
+	store := &fakePRStore{selections: map[string]int{"/repo": 5}}
+
+	m := NewModel("/repo", nil, nil, "origin/main").WithPRSelectionStore(store)
+
+	if m.selectedPR != 5 {
+		t.Errorf("selectedPR = %d, want 5", m.selectedPR)
+	}
+}
+
+type fakeTodoStore map[string][]string
+
+func (s fakeTodoStore) Get(worktreePath string) []string { return s[worktreePath] }
+
+func TestWithTodoStore_ShowsTodos(t *testing.T) {
+	store := fakeTodoStore{"/repo": {"Resolve #12: Login redirect loops"}}
+	m := NewModel("/repo", nil, nil, "origin/main").WithTodoStore(store)
+
+	result, _ := m.Update(ChecksDataMsg{Checks: ChecksModel{prTitle: "feat"}})
+	m = result.(Model)
+	if len(m.checks.todos) != 1 {
+		t.Fatalf("todos = %v, want the stored todo", m.checks.todos)
+	}
+
+	m.checks = ChecksModel{err: github.ErrNoPullRequest, todos: m.todos}
+	view := m.checks.view(80, 20)
+	if !strings.Contains(view, "Resolve #12") {
+		t.Errorf("no-PR view should list todos, got %q", view)
+	}
+}
+
+func TestDraftPRURL(t *testing.T) {
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[symbolic-ref --short HEAD]":                     "feat/login\n",
+			"/repo:[remote get-url origin]":                         "git@github.com:owner/repo.git\n",
+			`/repo:[config --get-regexp ^branch\..*\.description$]`: "branch.feat/login.description Fix the login redirect\n",
+		},
+	}
+
+	got, err := draftPRURL(runner, "/repo", "origin/main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "https://github.com/owner/repo/compare/main...feat/login?body=Fix+the+login+redirect&expand=1"
+	if got != want {
+		t.Errorf("draftPRURL = %q, want %q", got, want)
+	}
+}
+
+func TestCanDraftPR(t *testing.T) {
+	m := NewModel("/repo", nil, forge.GitHub{}, "origin/main")
+	m.activeTab = TabChecks
+	m.checks = ChecksModel{err: fmt.Errorf("fetching PR: %w", github.ErrNoPullRequest)}
+	if !m.canDraftPR() {
+		t.Error("GitHub branch without a PR should offer a draft")
+	}
+
+	m.provider = &forge.Bitbucket{}
+	if m.canDraftPR() {
+		t.Error("drafting is GitHub-only")
+	}
+}
+
+func treeChanges() ChangesModel {
+	return ChangesModel{
+		tree: true,
+		files: []ChangedFile{
+			{Path: "pkg/a.go", Additions: 3, Deletions: 1},
+			{Path: "README.md", Additions: 2},
+			{Path: "pkg/b.go", Additions: 4, Deletions: 5},
+		},
+	}
+}
+
+func TestChangesRows_GroupsByDirectory(t *testing.T) {
+	rows := treeChanges().rows()
+
+	want := []changeRow{
+		{dir: "pkg", file: -1, count: 2, additions: 7, deletions: 6},
+		{dir: "pkg", file: 0},
+		{dir: "pkg", file: 2},
+		{dir: ".", file: -1, count: 1, additions: 2},
+		{dir: ".", file: 1},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestChangesTree_SpaceCollapsesDirectory(t *testing.T) {
+	m := treeChanges()
+	m.cursor = 2 // pkg/b.go
+
+	m = m.update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	if !m.collapsed["pkg"] {
+		t.Fatal("space should collapse the directory under the cursor")
+	}
+	if m.cursor != 0 {
+		t.Errorf("cursor = %d, want 0 (the pkg header)", m.cursor)
+	}
+	if rows := m.rows(); len(rows) != 3 {
+		t.Errorf("collapsed tree should have 3 rows, got %d", len(rows))
+	}
+
+	m = m.update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	if m.collapsed["pkg"] {
+		t.Error("second space should expand the directory again")
+	}
+}
+
+func TestChangesTree_ToggleKeepsSelectedFile(t *testing.T) {
+	m := treeChanges()
+	m.cursor = 4 // README.md
+
+	m = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if m.tree {
+		t.Fatal("t should switch to the flat layout")
+	}
+	if f, ok := m.selectedFile(); !ok || f.Path != "README.md" {
+		t.Errorf("selected = %+v, want README.md", f)
+	}
+}
+
+func TestEnterOnDirectoryHeader_TogglesInsteadOfOpening(t *testing.T) {
+	opened := false
+	m := Model{
+		activeTab:     TabChanges,
+		repoDir:       "/repo",
+		editorStarter: func(string, ...string) error { opened = true; return nil },
+		changes:       treeChanges(),
+	}
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		cmd()
+	}
+	if opened {
+		t.Error("enter on a directory header should not open the editor")
+	}
+	if !result.(Model).changes.collapsed["pkg"] {
+		t.Error("enter on a directory header should collapse it")
+	}
+}
+
+func TestChangesDataMsg_KeepsTreeState(t *testing.T) {
+	m := Model{changes: ChangesModel{tree: true, collapsed: map[string]bool{"pkg": true}, wrap: true}}
+
+	result, _ := m.Update(ChangesDataMsg{Files: []ChangedFile{{Path: "pkg/a.go"}}})
+	changes := result.(Model).changes
+	if !changes.tree || !changes.collapsed["pkg"] || !changes.wrap {
+		t.Errorf("refresh should keep the layout and folds, got %+v", changes)
+	}
+}
+
+func TestChangesView_TreeShowsRollup(t *testing.T) {
+	view := treeChanges().view(80, 10)
+	for _, want := range []string{"pkg/", "(2)", "+7", "-6", "a.go"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("tree view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestTruncatePathMiddle(t *testing.T) {
+	tests := []struct {
+		path  string
+		width int
+		want  string
+	}{
+		{"pkg/a.go", 20, "pkg/a.go"},
+		{"packages/web/src/components/Button.tsx", 30, "packages/web/src/…/Button.tsx"},
+		{"packages/web/src/components/Button.tsx", 14, "…/Button.tsx"},
+		{"packages/web/src/components/Button.tsx", 8, "…ton.tsx"},
+		{"AVeryLongFileNameIndeed.go", 10, "…Indeed.go"},
+	}
+	for _, tt := range tests {
+		got := truncatePathMiddle(tt.path, tt.width)
+		if got != tt.want || ansi.StringWidth(got) > tt.width {
+			t.Errorf("truncatePathMiddle(%q, %d) = %q, want %q", tt.path, tt.width, got, tt.want)
+		}
+	}
+}
+
+func longPathChanges() ChangesModel {
+	return ChangesModel{
+		files: []ChangedFile{
+			{Path: "services/billing/internal/adapters/stripe/webhooks/handler.go", Additions: 120, Deletions: 45},
+			{Path: "go.mod", Additions: 1},
+		},
+	}
+}
+
+func TestChangesView_LongPathKeepsStatsAligned(t *testing.T) {
+	const width = 50
+	lines := strings.Split(longPathChanges().view(width, 4), "\n")
+
+	if !strings.Contains(lines[0], "services/billing/…/handler.go") {
+		t.Errorf("long path should be truncated in the middle:\n%s", lines[0])
+	}
+	for _, line := range lines[:2] {
+		if w := lipgloss.Width(line); w != width-2 {
+			t.Errorf("line width = %d, want %d so stats stay right-aligned: %q", w, width-2, line)
+		}
+	}
+}
+
+func TestChangesView_WrapAndScroll(t *testing.T) {
+	m := longPathChanges()
+
+	m = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	view := m.view(50, 6)
+	if !strings.Contains(view, "services/billing/internal/") || !strings.Contains(view, "stripe/webhooks/handler.go") {
+		t.Errorf("wrap should show the full path over several lines:\n%s", view)
+	}
+	if lines := strings.Split(view, "\n"); !strings.Contains(lines[2], "go.mod") {
+		t.Errorf("the next file should follow the wrapped lines:\n%s", view)
+	}
+
+	m = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	m = m.update(tea.KeyMsg{Type: tea.KeyRight})
+	m = m.update(tea.KeyMsg{Type: tea.KeyRight})
+	if m.hOffset != 2*hScrollStep {
+		t.Fatalf("hOffset = %d", m.hOffset)
+	}
+	if line := strings.Split(m.view(50, 4), "\n")[0]; !strings.HasPrefix(strings.TrimSpace(line), "…internal/adapters") {
+		t.Errorf("scrolled path should start mid-way:\n%s", line)
+	}
+	m = m.update(tea.KeyMsg{Type: tea.KeyLeft})
+	m = m.update(tea.KeyMsg{Type: tea.KeyLeft})
+	m = m.update(tea.KeyMsg{Type: tea.KeyLeft})
+	if m.hOffset != 0 {
+		t.Errorf("hOffset = %d, want clamped to 0", m.hOffset)
+	}
+}
+
+func TestFKeyCommitsFixupForSelectedFile(t *testing.T) {
+	hash := "1111111111111111111111111111111111111111"
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[diff -U0 HEAD -- a.go]":                 "@@ -2 +2 @@\n-old\n+new\n",
+			"/repo:[rev-list origin/main..HEAD]":            hash + "\n",
+			"/repo:[blame --porcelain -L 2,2 HEAD -- a.go]": hash + " 2 2 1\n\told\n",
+			"/repo:[log -1 --format=%s " + hash + "]":       "add a\n",
+			"/repo:[commit --fixup=" + hash + " -- a.go]":   "",
+		},
+	}
+	m := Model{
+		activeTab: TabChanges,
+		repoDir:   "/repo",
+		gitRunner: runner,
+		changes:   ChangesModel{files: []ChangedFile{{Path: "a.go"}}},
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if cmd == nil {
+		t.Fatal("f should start a fixup commit")
+	}
+	msg, ok := cmd().(FixupCommittedMsg)
+	if !ok || msg.Err != nil || msg.Subject != "add a" {
+		t.Fatalf("got %#v, want a fixup of add a", msg)
+	}
+
+	result, _ := m.Update(msg)
+	if status := result.(Model).statusMsg; !strings.Contains(status, "fixup! add a") {
+		t.Errorf("statusMsg = %q", status)
+	}
+}
+
+func TestFKeyNoop_OnChecksTab(t *testing.T) {
+	m := Model{activeTab: TabChecks, changes: ChangesModel{files: []ChangedFile{{Path: "a.go"}}}}
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")}); cmd != nil {
+		t.Error("f should only act on the Changes tab")
+	}
+}
+
+func TestAutosquashResultMsg_Conflicts(t *testing.T) {
+	m := Model{activeTab: TabChanges}
+
+	result, _ := m.Update(AutosquashResultMsg{Conflicts: []string{"a.go", "b.go"}})
+	status := result.(Model).statusMsg
+	if !strings.Contains(status, "a.go, b.go") {
+		t.Errorf("statusMsg = %q, want the conflicted files", status)
+	}
+}
+
+func TestQuestionMarkShowsKeybindings(t *testing.T) {
+	m := Model{activeTab: TabChanges, width: 80, height: 40}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	model := updated.(Model)
+	if !model.showingHelp {
+		t.Fatal("? should open the keybinding help")
+	}
+	view := model.View()
+	for _, want := range []string{"Changes tab", "autosquash fixups", "Checks tab", "pick another PR"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("help should list %q:\n%s", want, view)
+		}
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	if updated.(Model).changes.tree {
+		t.Error("tab keys should be ignored while the help is open")
+	}
+	updated, _ = updated.(Model).Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if updated.(Model).showingHelp {
+		t.Error("esc should close the help")
+	}
+}
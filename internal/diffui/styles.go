@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/theme"
 )
 
 const pollInterval = 5 * time.Second
@@ -11,81 +13,122 @@ const pollInterval = 5 * time.Second
 // === Color Palette ===
 
 var (
-	colorSecondary = lipgloss.Color("212")
-	colorGreen     = lipgloss.Color("82")
-	colorRed       = lipgloss.Color("196")
-	colorDimmed    = lipgloss.Color("240")
-	colorWhite     = lipgloss.Color("255")
-	colorYellow    = lipgloss.Color("220")
+	colorSecondary lipgloss.Color
+	colorGreen     lipgloss.Color
+	colorRed       lipgloss.Color
+	colorDimmed    lipgloss.Color
+	colorWhite     lipgloss.Color
+	colorYellow    lipgloss.Color
+	colorSelection lipgloss.Color
 )
 
 // === Styles ===
 
 var (
+	activeTabStyle     lipgloss.Style
+	inactiveTabStyle   lipgloss.Style
+	cursorStyle        lipgloss.Style
+	fileStyle          lipgloss.Style
+	additionStyle      lipgloss.Style
+	deletionStyle      lipgloss.Style
+	filePathDimStyle   lipgloss.Style
+	fileNameBoldStyle  lipgloss.Style
+	prTitleStyle       lipgloss.Style
+	sectionHeaderStyle lipgloss.Style
+	passedStyle        lipgloss.Style
+	failedStyle        lipgloss.Style
+	commentAuthorStyle lipgloss.Style
+	helpStyle          lipgloss.Style
+	checkIconStyle     lipgloss.Style
+	yellowStyle        lipgloss.Style
+	selectedStyle      lipgloss.Style
+	statusMsgStyle     lipgloss.Style
+	labelStyle         lipgloss.Style
+	prURLButtonStyle   lipgloss.Style
+)
+
+func init() {
+	ApplyTheme(theme.Default())
+}
+
+// ApplyTheme sets the palette of diff-ui and rebuilds its styles from it. It
+// is called before the program starts, with the `theme` config.
+func ApplyTheme(t theme.Theme) {
+	colorSecondary = t.Secondary
+	colorGreen = t.Green
+	colorRed = t.Red
+	colorDimmed = t.FgDim
+	colorWhite = t.Fg
+	colorYellow = t.Yellow
+	colorSelection = t.Selection
+
 	activeTabStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorWhite).
-			Padding(0, 1).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(colorDimmed)
+		Bold(true).
+		Foreground(colorWhite).
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colorDimmed)
 
 	inactiveTabStyle = lipgloss.NewStyle().
-				Foreground(colorDimmed).
-				Padding(0, 1)
+		Foreground(colorDimmed).
+		Padding(0, 1)
 
 	cursorStyle = lipgloss.NewStyle().
-			Foreground(colorSecondary).
-			Bold(true)
+		Foreground(colorSecondary).
+		Bold(true)
 
 	fileStyle = lipgloss.NewStyle().
-			Foreground(colorWhite)
+		Foreground(colorWhite)
 
 	additionStyle = lipgloss.NewStyle().
-			Foreground(colorGreen)
+		Foreground(colorGreen)
 
 	deletionStyle = lipgloss.NewStyle().
-			Foreground(colorRed)
+		Foreground(colorRed)
 
 	filePathDimStyle = lipgloss.NewStyle().
-				Foreground(colorDimmed)
+		Foreground(colorDimmed)
 
 	fileNameBoldStyle = lipgloss.NewStyle().
-				Foreground(colorWhite).
-				Bold(true)
+		Foreground(colorWhite).
+		Bold(true)
 
 	prTitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorWhite)
+		Bold(true).
+		Foreground(colorWhite)
 
 	sectionHeaderStyle = lipgloss.NewStyle().
-				Foreground(colorDimmed)
+		Foreground(colorDimmed)
 
 	passedStyle = lipgloss.NewStyle().
-			Foreground(colorGreen)
+		Foreground(colorGreen)
 
 	failedStyle = lipgloss.NewStyle().
-			Foreground(colorRed)
+		Foreground(colorRed)
 
 	commentAuthorStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(colorWhite)
+		Bold(true).
+		Foreground(colorWhite)
 
 	helpStyle = lipgloss.NewStyle().
-			Foreground(colorDimmed)
+		Foreground(colorDimmed)
 
 	checkIconStyle = lipgloss.NewStyle().
-			Foreground(colorDimmed)
+		Foreground(colorDimmed)
 
 	yellowStyle = lipgloss.NewStyle().
-			Foreground(colorYellow)
+		Foreground(colorYellow)
 
 	selectedStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color("236"))
+		Background(colorSelection)
 
 	statusMsgStyle = lipgloss.NewStyle().
-			Foreground(colorRed)
+		Foreground(colorRed)
+
+	labelStyle = lipgloss.NewStyle().
+		Foreground(colorYellow)
 
 	prURLButtonStyle = lipgloss.NewStyle().
-				Foreground(colorSecondary).
-				Underline(true)
-)
+		Foreground(colorSecondary).
+		Underline(true)
+}
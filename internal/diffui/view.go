@@ -1,12 +1,17 @@
 package diffui
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
 )
 
 func (m Model) View() string {
@@ -19,11 +24,17 @@ func (m Model) View() string {
 	viewportHeight := m.height - 4 // tab bar + help line + margins
 
 	var content string
-	switch m.activeTab {
-	case TabChanges:
+	switch {
+	case m.showingHelp:
+		content = m.renderHelp(viewportHeight)
+	case m.activeTab == TabChanges:
 		content = m.changes.view(m.width, viewportHeight)
-	case TabChecks:
-		content = m.checks.view(m.width, viewportHeight)
+	case m.activeTab == TabChecks:
+		if m.pickingPR {
+			content = m.renderPRPicker(viewportHeight)
+		} else {
+			content = m.checks.view(m.width, viewportHeight)
+		}
 	}
 
 	var statusLine string
@@ -31,7 +42,41 @@ func (m Model) View() string {
 		statusLine = statusMsgStyle.Render("  " + m.statusMsg)
 	}
 
-	help := helpStyle.Render("  tab: switch pane  j/k: navigate  enter: open in zed  o: open PR  q: quit")
+	help := helpStyle.Render("  " + keyhelp.ShortHelp(globalKeys.NextTab, checksKeys.Up, checksKeys.Down, checksKeys.OpenPR,
+		checksKeys.AddLabel, checksKeys.RemoveLabel, checksKeys.PickPR, globalKeys.Help, globalKeys.Quit))
+	if m.activeTab == TabChanges {
+		parts := []string{keyhelp.ShortHelp(globalKeys.NextTab, changesKeys.Up, changesKeys.Down, changesKeys.Open, changesKeys.Fixup, changesKeys.Autosquash)}
+		if m.changes.tree {
+			parts = append(parts, keyhelp.ShortHelp(changesKeys.Fold), keyHelp(changesKeys.Tree, "flat view"))
+		} else {
+			parts = append(parts, keyHelp(changesKeys.Tree, "tree view"))
+		}
+		if m.changes.wrap {
+			parts = append(parts, keyHelp(changesKeys.Wrap, "truncate paths"))
+		} else {
+			parts = append(parts, keyHelp(changesKeys.Wrap, "wrap paths"), keyhelp.ShortHelp(changesKeys.Left, changesKeys.Right))
+		}
+		parts = append(parts, keyhelp.ShortHelp(globalKeys.Help, globalKeys.Quit))
+		help = helpStyle.Render("  " + strings.Join(parts, "  "))
+	}
+	if m.canDraftPR() {
+		help = helpStyle.Render("  " + keyhelp.ShortHelp(globalKeys.NextTab, checksKeys.Up, checksKeys.Down) + "  " +
+			keyHelp(checksKeys.OpenPR, "draft PR on GitHub") + "  " + keyhelp.ShortHelp(globalKeys.Help, globalKeys.Quit))
+	}
+	if m.labelAction != labelActionNone {
+		prompt := "  Add label: "
+		if m.labelAction == labelActionRemove {
+			prompt = "  Remove label: "
+		}
+		statusLine = prompt + m.labelInput.View()
+		help = helpStyle.Render("  enter: confirm  esc: cancel")
+	}
+	if m.pickingPR {
+		help = helpStyle.Render("  " + keyhelp.ShortHelp(keyhelp.Bindings(pickerKeys)...))
+	}
+	if m.showingHelp {
+		help = helpStyle.Render("  " + keyhelp.ShortHelp(keyhelp.Bindings(helpKeys)...))
+	}
 
 	return lipgloss.JoinVertical(lipgloss.Left, tabBar, content, statusLine, help)
 }
@@ -74,6 +119,23 @@ func adjustScroll(cursor, scrollOff, viewportHeight, totalItems int) int {
 	return scrollOff
 }
 
+// === PR Picker View ===
+
+func (m Model) renderPRPicker(height int) string {
+	lines := []string{sectionHeaderStyle.Render("Select a pull request"), ""}
+	for i, c := range m.checks.candidates {
+		line := fmt.Sprintf("  #%d  %s  %s", c.Number, fileStyle.Render(c.Title), filePathDimStyle.Render("→ "+c.Base))
+		if i == m.pickerCursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
 // === ChangesModel View ===
 
 func (m ChangesModel) view(width, height int) string {
@@ -87,60 +149,180 @@ func (m ChangesModel) view(width, height int) string {
 		return filePathDimStyle.Render("  No changes")
 	}
 
-	m.scrollOff = adjustScroll(m.cursor, m.scrollOff, height, len(m.files))
+	rows := m.rows()
+	rendered := make([][]string, len(rows))
+	for i, r := range rows {
+		rendered[i] = m.renderRow(r, width)
+		if i == m.cursor {
+			for j, line := range rendered[i] {
+				rendered[i][j] = selectedStyle.Render(line)
+			}
+		}
+	}
+
+	// Wrapped rows take several lines; scroll until the whole cursor row fits.
+	m.scrollOff = adjustScroll(m.cursor, m.scrollOff, height, len(rows))
+	for m.scrollOff < m.cursor && linesBetween(rendered, m.scrollOff, m.cursor) > height {
+		m.scrollOff++
+	}
 
 	var lines []string
-	end := m.scrollOff + height
-	if end > len(m.files) {
-		end = len(m.files)
+	for i := m.scrollOff; i < len(rows) && len(lines) < height; i++ {
+		lines = append(lines, rendered[i]...)
+	}
+	if height > 0 && len(lines) > height {
+		lines = lines[:height]
 	}
 
-	for i := m.scrollOff; i < end; i++ {
-		f := m.files[i]
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
 
-		dir := filepath.Dir(f.Path)
-		name := filepath.Base(f.Path)
+	return strings.Join(lines, "\n")
+}
 
-		var pathStr string
-		if dir != "." {
-			pathStr = filePathDimStyle.Render(dir+"/") + fileNameBoldStyle.Render(name)
-		} else {
-			pathStr = fileNameBoldStyle.Render(name)
-		}
+// linesBetween counts the lines of rendered rows from..to, inclusive.
+func linesBetween(rendered [][]string, from, to int) int {
+	n := 0
+	for i := from; i <= to; i++ {
+		n += len(rendered[i])
+	}
+	return n
+}
 
-		var statsStr string
-		if f.Additions > 0 {
-			statsStr += additionStyle.Render(fmt.Sprintf("+%d", f.Additions))
+// renderRow lays out one row in width columns with its stats right-aligned.
+// Paths too long for the space left are truncated in the middle, scrolled by
+// hOffset, or in wrap mode continued on following lines.
+func (m ChangesModel) renderRow(r changeRow, width int) []string {
+	var text, prefix, suffix, statsStr string
+	style := renderPath
+	switch {
+	case r.file < 0:
+		marker := "▾ "
+		if m.collapsed[r.dir] {
+			marker = "▸ "
 		}
-		if f.Deletions > 0 {
-			if statsStr != "" {
-				statsStr += " "
+		prefix = sectionHeaderStyle.Render(marker)
+		text = r.dir + "/"
+		suffix = filePathDimStyle.Render(fmt.Sprintf(" (%d)", r.count))
+		style = func(s string) string { return fileNameBoldStyle.Render(s) }
+		statsStr = renderStats(r.additions, r.deletions)
+	case m.tree:
+		f := m.files[r.file]
+		prefix = "  "
+		text = filepath.Base(f.Path)
+		style = func(s string) string { return fileStyle.Render(s) }
+		statsStr = renderStats(f.Additions, f.Deletions)
+	default:
+		f := m.files[r.file]
+		text = f.Path
+		statsStr = renderStats(f.Additions, f.Deletions)
+	}
+
+	prefixWidth := lipgloss.Width(prefix)
+	statsWidth := lipgloss.Width(statsStr)
+	// 4 for margins and 1 for the gap before the stats.
+	avail := max(width-4-1-statsWidth-prefixWidth-lipgloss.Width(suffix), 1)
+	var parts []string
+	switch {
+	case width <= 0:
+		parts = []string{text} // size unknown until the first WindowSizeMsg
+	case m.wrap:
+		parts = strings.Split(ansi.Wrap(text, avail, "/"), "\n")
+	case m.hOffset > 0:
+		parts = []string{scrollPath(text, m.hOffset, avail)}
+	default:
+		parts = []string{truncatePathMiddle(text, avail)}
+	}
+
+	lines := make([]string, len(parts))
+	for i, part := range parts {
+		if i > 0 {
+			// Continuation lines of a wrapped path line up under its start.
+			lines[i] = "  " + strings.Repeat(" ", prefixWidth) + style(part)
+			if i == len(parts)-1 {
+				lines[i] += suffix
 			}
-			statsStr += deletionStyle.Render(fmt.Sprintf("-%d", f.Deletions))
+			continue
+		}
+		pathStr := prefix + style(part)
+		if len(parts) == 1 {
+			pathStr += suffix
 		}
 
 		// Calculate padding for right alignment
-		pathWidth := lipgloss.Width(pathStr)
-		statsWidth := lipgloss.Width(statsStr)
-		padding := width - pathWidth - statsWidth - 4 // 4 for margins
+		padding := width - lipgloss.Width(pathStr) - statsWidth - 4 // 4 for margins
 		if padding < 1 {
 			padding = 1
 		}
+		lines[i] = fmt.Sprintf("  %s%s%s", pathStr, strings.Repeat(" ", padding), statsStr)
+	}
+	return lines
+}
 
-		line := fmt.Sprintf("  %s%s%s", pathStr, strings.Repeat(" ", padding), statsStr)
+// renderPath styles a file path with its directory dimmed and its name bold.
+func renderPath(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return fileNameBoldStyle.Render(path)
+	}
+	return filePathDimStyle.Render(path[:i+1]) + fileNameBoldStyle.Render(path[i+1:])
+}
 
-		if i == m.cursor {
-			line = selectedStyle.Render(line)
-		}
+// truncatePathMiddle shortens path to width columns by dropping directories
+// from the middle, keeping the filename and as many top directories as fit,
+// e.g. "packages/web/…/Button.tsx". A filename too long on its own keeps its
+// end.
+func truncatePathMiddle(path string, width int) string {
+	if ansi.StringWidth(path) <= width {
+		return path
+	}
+	dirs := strings.Split(path, "/")
+	name := dirs[len(dirs)-1]
+	dirs = dirs[:len(dirs)-1]
 
-		lines = append(lines, line)
+	tail := "…/" + name
+	if len(dirs) == 0 {
+		tail = name
 	}
+	if ansi.StringWidth(tail) > width {
+		return ansi.TruncateLeft(name, ansi.StringWidth(name)-width+1, "…")
+	}
+	head := ""
+	for _, dir := range dirs {
+		if ansi.StringWidth(head+dir+"/"+tail) > width {
+			break
+		}
+		head += dir + "/"
+	}
+	return head + tail
+}
 
-	for len(lines) < height {
-		lines = append(lines, "")
+// scrollPath shows path from column offset onward, marking cut ends with "…".
+func scrollPath(path string, offset, width int) string {
+	total := ansi.StringWidth(path)
+	offset = min(offset, max(total-width, 0))
+	if offset == 0 {
+		return truncatePathMiddle(path, width)
 	}
+	visible := ansi.TruncateLeft(path, offset+1, "…")
+	return ansi.Truncate(visible, width, "…")
+}
 
-	return strings.Join(lines, "\n")
+// renderStats renders the "+N -M" summary of a file or directory, omitting
+// zero counts.
+func renderStats(additions, deletions int) string {
+	var stats string
+	if additions > 0 {
+		stats += additionStyle.Render(fmt.Sprintf("+%d", additions))
+	}
+	if deletions > 0 {
+		if stats != "" {
+			stats += " "
+		}
+		stats += deletionStyle.Render(fmt.Sprintf("-%d", deletions))
+	}
+	return stats
 }
 
 // === ChecksModel View ===
@@ -150,17 +332,34 @@ func (m ChecksModel) view(width, height int) string {
 		return filePathDimStyle.Render("  Loading PR data...")
 	}
 	if m.err != nil {
-		return filePathDimStyle.Render(fmt.Sprintf("  Error: %s", m.err.Error()))
+		if errors.Is(m.err, github.ErrNoPullRequest) {
+			lines := []string{filePathDimStyle.Render("  No pull request for this branch"), ""}
+			if len(m.todos) > 0 {
+				lines = append(lines, renderTodos(m.todos)...)
+			}
+			return strings.Join(lines, "\n")
+		}
+		view := filePathDimStyle.Render(fmt.Sprintf("  Error: %s", m.err.Error()))
+		if hint := checksErrorHint(m.err); hint != "" {
+			view += "\n" + helpStyle.Render("  "+hint)
+		}
+		return view
 	}
 
 	var allLines []string
 
 	// PR Title
 	allLines = append(allLines, prTitleStyle.Render(m.prTitle))
+	if len(m.candidates) > 1 {
+		allLines = append(allLines, filePathDimStyle.Render(fmt.Sprintf("PR #%d (%d open PRs for this branch, p: switch)", m.prNumber, len(m.candidates))))
+	}
 	if m.prURL != "" {
 		button := zone.Mark("open-pr", prURLButtonStyle.Render("[Open in Browser]"))
 		allLines = append(allLines, filePathDimStyle.Render(m.prURL)+" "+button)
 	}
+	allLines = append(allLines, renderMetadataLine("Labels", m.labels, labelStyle))
+	allLines = append(allLines, renderMetadataLine("Reviewers", m.reviewers, fileStyle))
+	allLines = append(allLines, renderMetadataLine("Assignees", m.assignees, fileStyle))
 	allLines = append(allLines, "")
 
 	// PR Description
@@ -190,18 +389,35 @@ func (m ChecksModel) view(width, height int) string {
 	// Checks
 	allLines = append(allLines, sectionHeaderStyle.Render("Checks"))
 	allLines = append(allLines, "")
-	for _, check := range m.checks {
+	if line := m.renderBaseChecks(); line != "" {
+		allLines = append(allLines, line, "")
+	}
+	if len(m.checks) > checkListLimit {
+		allLines = append(allLines, "  "+m.renderCheckCounts(), "")
+	}
+	checks, hidden := m.visibleChecks()
+	for _, check := range checks {
 		var icon string
-		if check.Passed {
+		switch {
+		case check.Passed:
 			icon = passedStyle.Render("✓")
-		} else {
+		case check.Pending:
+			icon = yellowStyle.Render("●")
+		default:
 			icon = failedStyle.Render("✗")
 		}
-		allLines = append(allLines, fmt.Sprintf("  %s %s  %s  %s",
+		line := fmt.Sprintf("  %s %s  %s  %s",
 			icon,
 			checkIconStyle.Render("⊙"),
 			fileStyle.Render(check.Name),
-			filePathDimStyle.Render(check.Duration)))
+			filePathDimStyle.Render(check.Duration))
+		if check.Failed() && m.baseFailing[check.Name] {
+			line += yellowStyle.Render("  also failing on " + m.baseBranch)
+		}
+		allLines = append(allLines, line)
+	}
+	if hidden > 0 {
+		allLines = append(allLines, filePathDimStyle.Render(fmt.Sprintf("  … %d more passed (%s)", hidden, keyHelp(checksKeys.AllChecks, "show all"))))
 	}
 	allLines = append(allLines, "")
 
@@ -211,6 +427,13 @@ func (m ChecksModel) view(width, height int) string {
 	if len(m.comments) == 0 {
 		allLines = append(allLines, filePathDimStyle.Render("  No comments yet"))
 	}
+	if older := m.commentsTotal - len(m.comments); older > 0 && m.commentsBefore != "" {
+		more := keyHelp(checksKeys.MoreComments, "load more")
+		if m.loadingComments {
+			more = "loading..."
+		}
+		allLines = append(allLines, filePathDimStyle.Render(fmt.Sprintf("  ↑ %d older comments (%s)", older, more)))
+	}
 	for _, c := range m.comments {
 		allLines = append(allLines, fmt.Sprintf("  %s  %s  %s",
 			checkIconStyle.Render("○"),
@@ -220,14 +443,7 @@ func (m ChecksModel) view(width, height int) string {
 	allLines = append(allLines, "")
 
 	// Your todos
-	allLines = append(allLines, sectionHeaderStyle.Render("Your todos"))
-	allLines = append(allLines, "")
-	if len(m.todos) == 0 {
-		allLines = append(allLines, filePathDimStyle.Render("  No todos yet"))
-	}
-	for _, todo := range m.todos {
-		allLines = append(allLines, fmt.Sprintf("  [ ] %s", fileStyle.Render(todo)))
-	}
+	allLines = append(allLines, renderTodos(m.todos)...)
 
 	// Clamp scroll offset
 	maxScroll := len(allLines) - height
@@ -253,3 +469,107 @@ func (m ChecksModel) view(width, height int) string {
 
 	return zone.Scan(strings.Join(visible, "\n"))
 }
+
+// checkListLimit is how many checks are listed before the passing ones are
+// folded behind checksKeys.AllChecks.
+const checkListLimit = 15
+
+// visibleChecks returns the checks to list and how many passing ones are
+// left out. On a PR with more than checkListLimit checks, failing and
+// pending checks are all listed first, then passing ones up to the limit.
+func (m ChecksModel) visibleChecks() ([]CheckResult, int) {
+	if m.showAllChecks || len(m.checks) <= checkListLimit {
+		return m.checks, 0
+	}
+	var visible, passed []CheckResult
+	for _, c := range m.checks {
+		if c.Passed {
+			passed = append(passed, c)
+		} else {
+			visible = append(visible, c)
+		}
+	}
+	shown := min(len(passed), max(checkListLimit-len(visible), 0))
+	return append(visible, passed[:shown]...), len(passed) - shown
+}
+
+// renderCheckCounts summarizes the checks by state, e.g. "✓ 120 passed
+// ✗ 2 failed  ● 5 pending".
+func (m ChecksModel) renderCheckCounts() string {
+	var passed, failed, pending int
+	for _, c := range m.checks {
+		switch {
+		case c.Passed:
+			passed++
+		case c.Pending:
+			pending++
+		default:
+			failed++
+		}
+	}
+	parts := []string{passedStyle.Render(fmt.Sprintf("✓ %d passed", passed))}
+	if failed > 0 {
+		parts = append(parts, failedStyle.Render(fmt.Sprintf("✗ %d failed", failed)))
+	}
+	if pending > 0 {
+		parts = append(parts, yellowStyle.Render(fmt.Sprintf("● %d pending", pending)))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// renderBaseChecks summarizes the latest CI run of the base branch, so
+// failures inherited from it are not mistaken for the PR's own. Returns ""
+// when the base branch has no CI results.
+func (m ChecksModel) renderBaseChecks() string {
+	switch m.baseState {
+	case github.RollupFailure:
+		return failedStyle.Render(fmt.Sprintf("  ✗ %s is red: its failing checks are not from this PR", m.baseBranch))
+	case github.RollupPending:
+		return filePathDimStyle.Render(fmt.Sprintf("  ● %s checks running", m.baseBranch))
+	case github.RollupSuccess:
+		return filePathDimStyle.Render(fmt.Sprintf("  ✓ %s is green", m.baseBranch))
+	}
+	return ""
+}
+
+// renderTodos renders the "Your todos" section.
+func renderTodos(todos []string) []string {
+	lines := []string{sectionHeaderStyle.Render("Your todos"), ""}
+	if len(todos) == 0 {
+		lines = append(lines, filePathDimStyle.Render("  No todos yet"))
+	}
+	for _, todo := range todos {
+		lines = append(lines, fmt.Sprintf("  [ ] %s", fileStyle.Render(todo)))
+	}
+	return lines
+}
+
+// renderMetadataLine renders a "Name: a, b" header line for PR metadata.
+func renderMetadataLine(name string, values []string, style lipgloss.Style) string {
+	label := filePathDimStyle.Render(name + ": ")
+	if len(values) == 0 {
+		return label + filePathDimStyle.Render("none")
+	}
+	rendered := make([]string, len(values))
+	for i, v := range values {
+		rendered[i] = style.Render(v)
+	}
+	return label + strings.Join(rendered, filePathDimStyle.Render(", "))
+}
+
+// checksErrorHint returns a suggestion for Checks tab errors the user can act
+// on, or "" when the error is not classified.
+func checksErrorHint(err error) string {
+	switch {
+	case errors.Is(err, github.ErrNotAuthenticated):
+		return "Run `gh auth login` or set GH_TOKEN."
+	case errors.Is(err, github.ErrGhNotFound):
+		return "Install gh or set GH_TOKEN."
+	case errors.Is(err, github.ErrRateLimited):
+		return "GitHub rate limit reached; retrying on the next refresh."
+	case errors.Is(err, github.ErrPermissionDenied):
+		return "Your token cannot access this repository."
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,258 @@
+// Package doctor checks what yakumo needs from the machine it runs on for
+// `yakumo doctor`: tmux, gh and claude, the config, the worktree directory
+// and the sessions deleted worktrees left behind. Each problem comes with
+// the fix for it.
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mikanfactory/yakumo/internal/buildinfo"
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/prune"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+// minTmux is the oldest tmux every feature works with: popup needs
+// display-popup -E.
+var minTmux = [2]int{3, 2}
+
+// Status is how a check went.
+type Status int
+
+const (
+	OK Status = iota
+	Warn
+	Fail
+)
+
+func (s Status) String() string {
+	switch s {
+	case Warn:
+		return "warn"
+	case Fail:
+		return "FAIL"
+	default:
+		return "ok"
+	}
+}
+
+// Check is the outcome of one check.
+type Check struct {
+	Name   string
+	Status Status
+	Detail string
+	Fix    string // what to do about a warning or failure
+}
+
+// Env is what the checks look at. LoadConfig is only called when the config
+// file exists.
+type Env struct {
+	LookPath   func(string) (string, error)
+	Run        buildinfo.Runner
+	ConfigPath string
+	LoadConfig func(path string) (model.Config, error)
+	TmuxRunner tmux.Runner
+}
+
+// Run runs every check in order.
+func Run(env Env) []Check {
+	checks := []Check{checkTmux(env), checkTool(env, "git", "install git")}
+	cfg, configCheck := checkConfig(env)
+	checks = append(checks, checkGitHub(env, cfg), checkClaude(env), configCheck)
+	if configCheck.Status != Fail {
+		checks = append(checks, checkRepositories(cfg)...)
+		if cfg.WorktreeBasePath != "" { // without a config there is none to check
+			checks = append(checks, checkWorktreeBase("worktree_base_path", cfg.WorktreeBasePath))
+		}
+		for _, repo := range cfg.Repositories {
+			if repo.WorktreeBasePath != "" {
+				checks = append(checks, checkWorktreeBase(repo.Name+": worktree_base_path", repo.WorktreeBasePath))
+			}
+		}
+		checks = append(checks, checkStaleSessions(env, config.WorktreeBases(cfg)))
+	}
+	return checks
+}
+
+// Failed reports whether any check failed.
+func Failed(checks []Check) bool {
+	for _, c := range checks {
+		if c.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// Write prints checks one per line, with the fix under each problem.
+func Write(w io.Writer, checks []Check) error {
+	width := 0
+	for _, c := range checks {
+		width = max(width, len(c.Name))
+	}
+	var b strings.Builder
+	for _, c := range checks {
+		fmt.Fprintf(&b, "%-4s  %-*s  %s\n", c.Status, width, c.Name, c.Detail)
+		if c.Status != OK && c.Fix != "" {
+			fmt.Fprintf(&b, "      %*s  fix: %s\n", width, "", c.Fix)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func checkTool(env Env, name, fix string) Check {
+	path, err := env.LookPath(name)
+	if err != nil {
+		return Check{Name: name, Status: Fail, Detail: "not found", Fix: fix}
+	}
+	return Check{Name: name, Detail: path}
+}
+
+var versionNumber = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+func checkTmux(env Env) Check {
+	check := checkTool(env, "tmux", "install tmux 3.2 or later, e.g. brew install tmux or apt install tmux")
+	if check.Status != OK {
+		return check
+	}
+	out, err := env.Run(check.Detail, "-V")
+	if err != nil {
+		return check
+	}
+	version := strings.TrimSpace(out)
+	check.Detail = version
+	m := versionNumber.FindStringSubmatch(version)
+	if m == nil {
+		return check // e.g. a build from master
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	if major < minTmux[0] || major == minTmux[0] && minor < minTmux[1] {
+		check.Status = Warn
+		check.Detail += fmt.Sprintf(" is older than %d.%d; yakumo popup does not work", minTmux[0], minTmux[1])
+		check.Fix = "upgrade tmux"
+	}
+	return check
+}
+
+func checkGitHub(env Env, cfg model.Config) Check {
+	path, err := env.LookPath("gh")
+	if err != nil {
+		if github.ResolveToken(cfg.GitHubToken) != "" {
+			return Check{Name: "gh", Detail: "not found; using the GitHub token"}
+		}
+		return Check{Name: "gh", Status: Warn, Detail: "not found; PR badges and worktrees from issues are off",
+			Fix: "install gh and run gh auth login, or set GH_TOKEN or github_token"}
+	}
+	if _, err := env.Run(path, "auth", "status"); err != nil {
+		return Check{Name: "gh", Status: Warn, Detail: "not logged in", Fix: "run gh auth login"}
+	}
+	return Check{Name: "gh", Detail: "logged in"}
+}
+
+func checkClaude(env Env) Check {
+	path, err := env.LookPath("claude")
+	if err != nil {
+		return Check{Name: "claude", Status: Warn, Detail: "not found; branches are not renamed and no agent starts in new sessions",
+			Fix: "install the Claude CLI: npm install -g @anthropic-ai/claude-code"}
+	}
+	return Check{Name: "claude", Detail: path}
+}
+
+func checkConfig(env Env) (model.Config, Check) {
+	check := Check{Name: "config", Detail: env.ConfigPath}
+	if _, err := os.Stat(env.ConfigPath); errors.Is(err, os.ErrNotExist) {
+		check.Status = Warn
+		check.Detail = "no config at " + env.ConfigPath
+		check.Fix = "run yakumo inside a git repository to create one"
+		return model.Config{}, check
+	}
+	cfg, err := env.LoadConfig(env.ConfigPath)
+	if err != nil {
+		check.Status = Fail
+		check.Detail = err.Error()
+		check.Fix = "correct " + env.ConfigPath
+		return model.Config{}, check
+	}
+	return cfg, check
+}
+
+// checkRepositories reports the configured repositories that are gone.
+func checkRepositories(cfg model.Config) []Check {
+	var checks []Check
+	for _, repo := range cfg.Repositories {
+		if _, err := os.Stat(filepath.Join(repo.Path, ".git")); err != nil {
+			checks = append(checks, Check{
+				Name:   "repository " + repo.Name,
+				Status: Warn,
+				Detail: repo.Path + " is not a git repository",
+				Fix:    "correct its path or remove it from the config",
+			})
+		}
+	}
+	return checks
+}
+
+// checkWorktreeBase checks that worktrees can be created in dir, or in the
+// nearest directory above it that exists, where dir would be created.
+func checkWorktreeBase(name, dir string) Check {
+	check := Check{Name: name, Detail: dir}
+	existing := dir
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	f, err := os.CreateTemp(existing, ".yakumo-doctor-")
+	if err != nil {
+		check.Status = Fail
+		check.Detail = fmt.Sprintf("%s is not writable: %v", existing, err)
+		check.Fix = "fix the permissions of " + existing + " or set worktree_base_path to a writable directory"
+		return check
+	}
+	f.Close()
+	os.Remove(f.Name())
+	if existing != dir {
+		check.Detail += " (created on the first worktree)"
+	}
+	return check
+}
+
+func checkStaleSessions(env Env, worktreeBases []string) Check {
+	check := Check{Name: "sessions"}
+	if env.TmuxRunner == nil {
+		check.Detail = "tmux is not available"
+		return check
+	}
+	names, err := prune.StaleSessions(env.TmuxRunner, worktreeBases)
+	switch {
+	case tmux.IsUnavailable(err):
+		check.Detail = "no tmux server running"
+	case err != nil:
+		check.Status = Warn
+		check.Detail = "listing sessions failed: " + err.Error()
+	case len(names) > 0:
+		check.Status = Warn
+		check.Detail = fmt.Sprintf("%d of deleted worktrees: %s", len(names), strings.Join(names, ", "))
+		check.Fix = "run yakumo prune"
+	default:
+		check.Detail = "none of deleted worktrees"
+	}
+	return check
+}
@@ -0,0 +1,197 @@
+package doctor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+func fakeLookPath(found ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, f := range found {
+			if f == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func fakeRun(outputs map[string]string) func(string, ...string) (string, error) {
+	return func(name string, args ...string) (string, error) {
+		key := filepath.Base(name) + " " + strings.Join(args, " ")
+		out, ok := outputs[key]
+		if !ok {
+			return "", errors.New("exit status 1")
+		}
+		return out, nil
+	}
+}
+
+func byName(checks []Check) map[string]Check {
+	m := make(map[string]Check, len(checks))
+	for _, c := range checks {
+		m[c.Name] = c
+	}
+	return m
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.Join(dir, "worktrees")
+	cfg := model.Config{
+		WorktreeBasePath: base,
+		Repositories: []model.RepositoryDef{
+			{Name: "repo", Path: repo},
+			{Name: "gone", Path: filepath.Join(dir, "gone")},
+		},
+	}
+
+	checks := Run(Env{
+		LookPath:   fakeLookPath("tmux", "git", "gh"),
+		Run:        fakeRun(map[string]string{"tmux -V": "tmux 3.1c\n"}),
+		ConfigPath: configPath,
+		LoadConfig: func(string) (model.Config, error) { return cfg, nil },
+		TmuxRunner: &tmux.FakeRunner{Outputs: map[string]string{
+			"[list-sessions -F #{session_name}\t#{session_path}]": "old\t" + filepath.Join(base, "old"),
+		}},
+	})
+	got := byName(checks)
+
+	tests := []struct {
+		name   string
+		status Status
+		detail string
+		fix    string
+	}{
+		{"tmux", Warn, "tmux 3.1c is older than 3.2", "upgrade tmux"},
+		{"git", OK, "/usr/bin/git", ""},
+		{"gh", Warn, "not logged in", "gh auth login"},
+		{"claude", Warn, "not found", "install the Claude CLI"},
+		{"config", OK, configPath, ""},
+		{"repository gone", Warn, "is not a git repository", "remove it from the config"},
+		{"worktree_base_path", OK, "created on the first worktree", ""},
+		{"sessions", Warn, "old", "yakumo prune"},
+	}
+	for _, tt := range tests {
+		c, ok := got[tt.name]
+		if !ok {
+			t.Errorf("no %s check in %+v", tt.name, checks)
+			continue
+		}
+		if c.Status != tt.status || !strings.Contains(c.Detail, tt.detail) || !strings.Contains(c.Fix, tt.fix) {
+			t.Errorf("%s = %+v, want %s with %q and fix %q", tt.name, c, tt.status, tt.detail, tt.fix)
+		}
+	}
+	if _, ok := got["repository repo"]; ok {
+		t.Error("a repository that exists should not be reported")
+	}
+	if Failed(checks) {
+		t.Error("warnings alone should not fail")
+	}
+}
+
+func TestRun_Failures(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := Run(Env{
+		LookPath:   fakeLookPath("git"),
+		Run:        fakeRun(nil),
+		ConfigPath: configPath,
+		LoadConfig: func(string) (model.Config, error) { return model.Config{}, errors.New("parsing config file: bad") },
+	})
+	got := byName(checks)
+
+	if c := got["tmux"]; c.Status != Fail || !strings.Contains(c.Fix, "install tmux") {
+		t.Errorf("tmux = %+v, want a failure with how to install it", c)
+	}
+	if c := got["config"]; c.Status != Fail || !strings.Contains(c.Detail, "bad") {
+		t.Errorf("config = %+v, want the load error", c)
+	}
+	if _, ok := got["worktree_base_path"]; ok {
+		t.Error("checks needing the config should be skipped when it does not load")
+	}
+	if !Failed(checks) {
+		t.Error("Failed = false, want true")
+	}
+}
+
+func TestRun_NoConfig(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	checks := Run(Env{
+		LookPath:   fakeLookPath("tmux", "git", "claude"),
+		Run:        fakeRun(map[string]string{"tmux -V": "tmux 3.4\n"}),
+		ConfigPath: filepath.Join(t.TempDir(), "config.yaml"),
+		LoadConfig: func(string) (model.Config, error) {
+			t.Error("a missing config should not be loaded")
+			return model.Config{}, nil
+		},
+	})
+	got := byName(checks)
+
+	if c := got["tmux"]; c.Status != OK || c.Detail != "tmux 3.4" {
+		t.Errorf("tmux = %+v, want ok", c)
+	}
+	if c := got["config"]; c.Status != Warn || !strings.Contains(c.Detail, "no config") {
+		t.Errorf("config = %+v, want a warning", c)
+	}
+	if c := got["gh"]; c.Status != Warn || !strings.Contains(c.Fix, "GH_TOKEN") {
+		t.Errorf("gh = %+v, want a warning without gh or a token", c)
+	}
+	if _, ok := got["worktree_base_path"]; ok {
+		t.Error("worktree_base_path should not be checked without a config")
+	}
+	if c := got["sessions"]; c.Status != OK {
+		t.Errorf("sessions = %+v, want ok without tmux runner", c)
+	}
+}
+
+func TestCheckWorktreeBase_NotWritable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root writes anywhere")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(dir, 0o755) })
+
+	if c := checkWorktreeBase("worktree_base_path", filepath.Join(dir, "worktrees")); c.Status != Fail || !strings.Contains(c.Detail, "not writable") {
+		t.Errorf("check = %+v, want a failure", c)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var b strings.Builder
+	err := Write(&b, []Check{
+		{Name: "git", Detail: "/usr/bin/git"},
+		{Name: "claude", Status: Warn, Detail: "not found", Fix: "install it"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "ok    git     /usr/bin/git\n" +
+		"warn  claude  not found\n" +
+		"              fix: install it\n"
+	if b.String() != want {
+		t.Errorf("Write =\n%s\nwant\n%s", b.String(), want)
+	}
+}
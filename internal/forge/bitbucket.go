@@ -0,0 +1,436 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+)
+
+// DefaultBitbucketAPIBaseURL is the Bitbucket Cloud REST API endpoint.
+const DefaultBitbucketAPIBaseURL = "https://api.bitbucket.org/2.0"
+
+// Bitbucket is the Provider for Bitbucket Cloud. It authenticates with
+// $BITBUCKET_TOKEN (access token) or $BITBUCKET_USERNAME and
+// $BITBUCKET_APP_PASSWORD, and works anonymously for public repositories.
+type Bitbucket struct {
+	Token       string
+	Username    string
+	AppPassword string
+	BaseURL     string
+	Client      *http.Client
+	Git         git.CommandRunner
+}
+
+// NewBitbucket creates a Bitbucket provider with credentials from the environment.
+func NewBitbucket(gitRunner git.CommandRunner) *Bitbucket {
+	return &Bitbucket{
+		Token:       strings.TrimSpace(os.Getenv("BITBUCKET_TOKEN")),
+		Username:    strings.TrimSpace(os.Getenv("BITBUCKET_USERNAME")),
+		AppPassword: strings.TrimSpace(os.Getenv("BITBUCKET_APP_PASSWORD")),
+		BaseURL:     DefaultBitbucketAPIBaseURL,
+		Client:      &http.Client{Timeout: 15 * time.Second},
+		Git:         gitRunner,
+	}
+}
+
+func (b *Bitbucket) Kind() string { return KindBitbucket }
+
+type bbUser struct {
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+}
+
+func (u bbUser) login() string {
+	if u.Nickname != "" {
+		return u.Nickname
+	}
+	return u.DisplayName
+}
+
+type bbPullRequest struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+	Links       struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+	Source struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+	} `json:"source"`
+	Destination struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+	} `json:"destination"`
+	Reviewers    []bbUser `json:"reviewers"`
+	Participants []struct {
+		User     bbUser `json:"user"`
+		Role     string `json:"role"`
+		Approved bool   `json:"approved"`
+		State    string `json:"state"`
+	} `json:"participants"`
+}
+
+type bbComment struct {
+	User    bbUser `json:"user"`
+	Deleted bool   `json:"deleted"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	CreatedOn time.Time `json:"created_on"`
+}
+
+type bbStatus struct {
+	Key       string    `json:"key"`
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	CreatedOn time.Time `json:"created_on"`
+	UpdatedOn time.Time `json:"updated_on"`
+}
+
+type bbPage[T any] struct {
+	Values []T `json:"values"`
+}
+
+func (b *Bitbucket) FetchPR(dir string) (github.PRView, error) {
+	workspace, repo, err := b.repoSlug(dir)
+	if err != nil {
+		return github.PRView{}, err
+	}
+	branch, err := git.CurrentBranch(b.Git, dir)
+	if err != nil {
+		return github.PRView{}, fmt.Errorf("resolving current branch: %w", err)
+	}
+
+	pulls, err := b.listPullRequests(workspace, repo, branch, "OPEN", "MERGED", "DECLINED")
+	if err != nil {
+		return github.PRView{}, err
+	}
+	if len(pulls) == 0 {
+		return github.PRView{}, fmt.Errorf("%w for branch %q", github.ErrNoPullRequest, branch)
+	}
+	return b.buildPR(workspace, repo, pulls[0].ID)
+}
+
+func (b *Bitbucket) FetchPRs(dir, branch string) ([]github.PRView, error) {
+	workspace, repo, err := b.repoSlug(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pulls, err := b.listPullRequests(workspace, repo, branch, "OPEN")
+	if err != nil {
+		return nil, err
+	}
+
+	prs := make([]github.PRView, 0, len(pulls))
+	for _, pull := range pulls {
+		pr, err := b.buildPR(workspace, repo, pull.ID)
+		if err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+// FetchBranchChecks returns the commit statuses of the tip of branch.
+func (b *Bitbucket) FetchBranchChecks(dir, branch string) ([]github.StatusCheckNode, error) {
+	workspace, repo, err := b.repoSlug(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses bbPage[bbStatus]
+	if err := b.get(fmt.Sprintf("/repositories/%s/%s/commit/%s/statuses?pagelen=100", workspace, repo, url.PathEscape(branch)), &statuses); err != nil {
+		return nil, err
+	}
+	checks := make([]github.StatusCheckNode, len(statuses.Values))
+	for i, s := range statuses.Values {
+		checks[i] = bitbucketStatusCheck(s)
+	}
+	return checks, nil
+}
+
+func (b *Bitbucket) ResolveBranch(dir, rawURL string) (string, error) {
+	info, err := ParseBitbucketURL(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if info.Type == github.URLTypeBranch {
+		return info.Branch, nil
+	}
+
+	var pull bbPullRequest
+	if err := b.get(fmt.Sprintf("/repositories/%s/%s/pullrequests/%s", info.Owner, info.Repo, info.PRNumber), &pull); err != nil {
+		return "", fmt.Errorf("resolving PR branch: %w", err)
+	}
+	if pull.Source.Branch.Name == "" {
+		return "", fmt.Errorf("PR has no head branch")
+	}
+	return pull.Source.Branch.Name, nil
+}
+
+func (b *Bitbucket) AddLabel(dir string, number int, label string) error {
+	return fmt.Errorf("bitbucket labels: %w", ErrUnsupported)
+}
+
+func (b *Bitbucket) RemoveLabel(dir string, number int, label string) error {
+	return fmt.Errorf("bitbucket labels: %w", ErrUnsupported)
+}
+
+func (b *Bitbucket) listPullRequests(workspace, repo, branch string, states ...string) ([]bbPullRequest, error) {
+	q := url.Values{}
+	q.Set("q", fmt.Sprintf("source.branch.name=%q", branch))
+	q.Set("pagelen", "50")
+	for _, s := range states {
+		q.Add("state", s)
+	}
+	var page bbPage[bbPullRequest]
+	if err := b.get(fmt.Sprintf("/repositories/%s/%s/pullrequests?%s", workspace, repo, q.Encode()), &page); err != nil {
+		return nil, err
+	}
+	return page.Values, nil
+}
+
+// buildPR converts a Bitbucket pull request, its comments and its commit
+// statuses (pipelines and external builds) into a github.PRView.
+func (b *Bitbucket) buildPR(workspace, repo string, id int) (github.PRView, error) {
+	base := fmt.Sprintf("/repositories/%s/%s/pullrequests/%d", workspace, repo, id)
+
+	var pull bbPullRequest
+	if err := b.get(base, &pull); err != nil {
+		return github.PRView{}, err
+	}
+
+	pr := github.PRView{
+		Number:      pull.ID,
+		BaseRefName: pull.Destination.Branch.Name,
+		Title:       pull.Title,
+		Body:        pull.Description,
+		State:       bitbucketPRState(pull.State),
+		URL:         pull.Links.HTML.Href,
+	}
+
+	requested := make(map[string]bool)
+	for _, r := range pull.Reviewers {
+		requested[r.login()] = true
+	}
+	changesRequested := false
+	for _, p := range pull.Participants {
+		switch {
+		case p.State == "changes_requested":
+			changesRequested = true
+			pr.LatestReviews = append(pr.LatestReviews, github.ReviewNode{Author: github.CommentAuthor{Login: p.User.login()}, State: "CHANGES_REQUESTED"})
+		case p.Approved:
+			pr.LatestReviews = append(pr.LatestReviews, github.ReviewNode{Author: github.CommentAuthor{Login: p.User.login()}, State: "APPROVED"})
+		default:
+			continue
+		}
+		delete(requested, p.User.login())
+	}
+	for _, r := range pull.Reviewers {
+		if requested[r.login()] {
+			pr.ReviewRequests = append(pr.ReviewRequests, github.ReviewRequest{Login: r.login()})
+		}
+	}
+
+	var comments bbPage[bbComment]
+	if err := b.get(base+"/comments?pagelen=100", &comments); err != nil {
+		return github.PRView{}, err
+	}
+	for _, c := range comments.Values {
+		if c.Deleted {
+			continue
+		}
+		pr.Comments = append(pr.Comments, github.CommentNode{
+			Author:    github.CommentAuthor{Login: c.User.login()},
+			Body:      c.Content.Raw,
+			CreatedAt: c.CreatedOn,
+		})
+	}
+
+	var statuses bbPage[bbStatus]
+	if err := b.get(base+"/statuses?pagelen=100", &statuses); err != nil {
+		return github.PRView{}, err
+	}
+	failing := false
+	for _, s := range statuses.Values {
+		node := bitbucketStatusCheck(s)
+		if node.State == "FAILURE" {
+			failing = true
+		}
+		pr.StatusCheckRollup = append(pr.StatusCheckRollup, node)
+	}
+
+	if pr.State == "OPEN" {
+		switch {
+		case changesRequested:
+			pr.MergeStateStatus = "BLOCKED"
+			pr.ReviewDecision = "CHANGES_REQUESTED"
+		case failing:
+			pr.MergeStateStatus = "UNSTABLE"
+		default:
+			pr.MergeStateStatus = "CLEAN"
+		}
+	}
+
+	return pr, nil
+}
+
+func bitbucketPRState(state string) string {
+	switch state {
+	case "DECLINED", "SUPERSEDED":
+		return "CLOSED"
+	default:
+		return state
+	}
+}
+
+func bitbucketStatusCheck(s bbStatus) github.StatusCheckNode {
+	node := github.StatusCheckNode{
+		Name:      s.Name,
+		Context:   s.Key,
+		State:     bitbucketStatusState(s.State),
+		StartedAt: s.CreatedOn,
+	}
+	if s.State != "INPROGRESS" {
+		node.CompletedAt = s.UpdatedOn
+	}
+	return node
+}
+
+func bitbucketStatusState(state string) string {
+	switch state {
+	case "SUCCESSFUL":
+		return "SUCCESS"
+	case "FAILED", "STOPPED":
+		return "FAILURE"
+	default:
+		return "PENDING"
+	}
+}
+
+// repoSlug resolves workspace and repository from the origin remote of dir.
+func (b *Bitbucket) repoSlug(dir string) (string, string, error) {
+	remote, err := git.RemoteURL(b.Git, dir)
+	if err != nil {
+		return "", "", fmt.Errorf("resolving origin remote: %w", err)
+	}
+	return ParseBitbucketRemote(remote)
+}
+
+func (b *Bitbucket) get(path string, v any) error {
+	baseURL := b.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBitbucketAPIBaseURL
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(baseURL, "/")+path, nil)
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case b.Token != "":
+		req.Header.Set("Authorization", "Bearer "+b.Token)
+	case b.Username != "" && b.AppPassword != "":
+		req.SetBasicAuth(b.Username, b.AppPassword)
+	}
+
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("bitbucket api GET %s failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading bitbucket api response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("bitbucket api GET %s failed: %s: %s", path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing bitbucket api response: %w", err)
+	}
+	return nil
+}
+
+// ParseBitbucketURL parses a Bitbucket Cloud branch URL
+// (/workspace/repo/branch/name) or PR URL (/workspace/repo/pull-requests/12).
+func ParseBitbucketURL(rawURL string) (github.URLInfo, error) {
+	if rawURL == "" {
+		return github.URLInfo{}, fmt.Errorf("empty URL")
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return github.URLInfo{}, fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Host != "bitbucket.org" {
+		return github.URLInfo{}, fmt.Errorf("not a Bitbucket URL: %s", parsed.Host)
+	}
+
+	path := strings.Trim(parsed.Path, "/")
+	segments := strings.SplitN(path, "/", 4)
+	if len(segments) < 4 || segments[3] == "" {
+		return github.URLInfo{}, fmt.Errorf("unsupported Bitbucket URL format: need /workspace/repo/branch|pull-requests/...")
+	}
+
+	info := github.URLInfo{Owner: segments[0], Repo: segments[1]}
+	switch segments[2] {
+	case "branch":
+		info.Type = github.URLTypeBranch
+		info.Branch = segments[3]
+	case "pull-requests":
+		number := strings.SplitN(segments[3], "/", 2)[0]
+		if _, err := strconv.Atoi(number); err != nil {
+			return github.URLInfo{}, fmt.Errorf("invalid PR number: %q", number)
+		}
+		info.Type = github.URLTypePR
+		info.PRNumber = number
+	default:
+		return github.URLInfo{}, fmt.Errorf("unsupported Bitbucket URL type: %q (expected branch or pull-requests)", segments[2])
+	}
+	return info, nil
+}
+
+// ParseBitbucketRemote extracts workspace and repo from a Bitbucket remote
+// URL in either SSH ("git@bitbucket.org:ws/repo.git") or HTTPS form.
+func ParseBitbucketRemote(remote string) (string, string, error) {
+	path := ""
+	if rest, ok := strings.CutPrefix(remote, "git@bitbucket.org:"); ok {
+		path = rest
+	} else {
+		parsed, err := url.Parse(remote)
+		if err != nil || parsed.Hostname() != "bitbucket.org" {
+			return "", "", fmt.Errorf("not a Bitbucket remote: %s", remote)
+		}
+		path = strings.TrimPrefix(parsed.Path, "/")
+	}
+
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+	segments := strings.Split(path, "/")
+	if len(segments) != 2 || segments[0] == "" || segments[1] == "" {
+		return "", "", fmt.Errorf("unsupported Bitbucket remote format: %s", remote)
+	}
+	return segments[0], segments[1], nil
+}
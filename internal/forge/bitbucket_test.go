@@ -0,0 +1,217 @@
+package forge
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+)
+
+func newBitbucketTestProvider(t *testing.T, routes map[string]string) *Bitbucket {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "secret" {
+			t.Errorf("expected basic auth me:secret, got %q:%q", user, pass)
+		}
+		body, ok := routes[r.URL.RequestURI()]
+		if !ok {
+			http.Error(w, `{"error":{"message":"Not found"}}`, http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return &Bitbucket{
+		Username:    "me",
+		AppPassword: "secret",
+		BaseURL:     server.URL,
+		Git: git.FakeCommandRunner{
+			Outputs: map[string]string{
+				"/repo:[remote get-url origin]":     "git@bitbucket.org:team/repo.git\n",
+				"/repo:[symbolic-ref --short HEAD]": "feat\n",
+			},
+		},
+	}
+}
+
+const bbPullJSON = `{
+	"id": 5, "title": "feat: bb", "description": "desc", "state": "OPEN",
+	"links": {"html": {"href": "https://bitbucket.org/team/repo/pull-requests/5"}},
+	"source": {"branch": {"name": "feat"}}, "destination": {"branch": {"name": "main"}},
+	"reviewers": [{"nickname": "bob"}, {"nickname": "carol"}],
+	"participants": [
+		{"user": {"nickname": "carol"}, "role": "REVIEWER", "approved": true, "state": "approved"},
+		{"user": {"nickname": "dave"}, "role": "PARTICIPANT", "approved": false, "state": null}
+	]
+}`
+
+func bbDetailRoutes() map[string]string {
+	return map[string]string{
+		"/repositories/team/repo/pullrequests/5": bbPullJSON,
+		"/repositories/team/repo/pullrequests/5/comments?pagelen=100": `{"values": [
+			{"user": {"nickname": "dave"}, "content": {"raw": "nice"}, "created_on": "2025-01-01T00:00:00Z"},
+			{"user": {"nickname": "eve"}, "deleted": true, "content": {"raw": ""}}
+		]}`,
+		"/repositories/team/repo/pullrequests/5/statuses?pagelen=100": `{"values": [
+			{"key": "pipeline", "name": "Pipeline #12", "state": "FAILED", "created_on": "2025-01-01T00:00:00Z", "updated_on": "2025-01-01T00:02:00Z"}
+		]}`,
+	}
+}
+
+func TestBitbucket_FetchPR(t *testing.T) {
+	routes := bbDetailRoutes()
+	routes["/repositories/team/repo/pullrequests?pagelen=50&q=source.branch.name%3D%22feat%22&state=OPEN&state=MERGED&state=DECLINED"] = `{"values": [{"id": 5}]}`
+	b := newBitbucketTestProvider(t, routes)
+
+	pr, err := b.FetchPR("/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if pr.Number != 5 || pr.Title != "feat: bb" || pr.BaseRefName != "main" || pr.State != "OPEN" {
+		t.Errorf("unexpected PR header: %+v", pr)
+	}
+	if got := strings.Join(pr.ReviewerNames(), ","); got != "carol,bob" {
+		t.Errorf("reviewers = %q, want carol,bob", got)
+	}
+	if len(pr.Comments) != 1 || pr.Comments[0].Author.Login != "dave" {
+		t.Errorf("comments = %+v, want one from dave", pr.Comments)
+	}
+	if len(pr.StatusCheckRollup) != 1 || pr.StatusCheckRollup[0].Passed() {
+		t.Errorf("checks = %+v, want one failing pipeline", pr.StatusCheckRollup)
+	}
+	if got := github.MapMergeStateStatus(pr.MergeStateStatus, pr.ReviewDecision); got != "Checks failing" {
+		t.Errorf("merge status = %q, want Checks failing", got)
+	}
+}
+
+func TestBitbucket_FetchPRs(t *testing.T) {
+	routes := bbDetailRoutes()
+	routes["/repositories/team/repo/pullrequests?pagelen=50&q=source.branch.name%3D%22feat%22&state=OPEN"] = `{"values": [{"id": 5}]}`
+	b := newBitbucketTestProvider(t, routes)
+
+	prs, err := b.FetchPRs("/repo", "feat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs) != 1 || prs[0].Number != 5 {
+		t.Errorf("prs = %+v, want #5", prs)
+	}
+}
+
+func TestBitbucket_FetchBranchChecks(t *testing.T) {
+	b := newBitbucketTestProvider(t, map[string]string{
+		"/repositories/team/repo/commit/main/statuses?pagelen=100": `{"values": [
+			{"key": "build", "name": "Build", "state": "SUCCESSFUL"},
+			{"key": "deploy", "name": "Deploy", "state": "INPROGRESS"}
+		]}`,
+	})
+
+	checks, err := b.FetchBranchChecks("/repo", "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := (github.PRView{StatusCheckRollup: checks}).CheckRollup(); len(checks) != 2 || got != github.RollupPending {
+		t.Errorf("checks = %+v (rollup %q), want 2 with one still running", checks, got)
+	}
+}
+
+func TestBitbucket_ResolveBranch(t *testing.T) {
+	b := newBitbucketTestProvider(t, map[string]string{
+		"/repositories/team/repo/pullrequests/5": bbPullJSON,
+	})
+
+	branch, err := b.ResolveBranch("/repo", "https://bitbucket.org/team/repo/pull-requests/5/diff")
+	if err != nil || branch != "feat" {
+		t.Errorf("PR URL = %q, %v; want feat", branch, err)
+	}
+
+	branch, err = b.ResolveBranch("/repo", "https://bitbucket.org/team/repo/branch/feature/x")
+	if err != nil || branch != "feature/x" {
+		t.Errorf("branch URL = %q, %v; want feature/x", branch, err)
+	}
+}
+
+func TestBitbucket_LabelsUnsupported(t *testing.T) {
+	b := &Bitbucket{}
+	if err := b.AddLabel("/repo", 0, "bug"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("AddLabel error = %v, want ErrUnsupported", err)
+	}
+	if err := b.RemoveLabel("/repo", 0, "bug"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("RemoveLabel error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestParseBitbucketURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    github.URLInfo
+		wantErr bool
+	}{
+		{
+			name: "branch",
+			url:  "https://bitbucket.org/team/repo/branch/feature/x",
+			want: github.URLInfo{Type: github.URLTypeBranch, Owner: "team", Repo: "repo", Branch: "feature/x"},
+		},
+		{
+			name: "pull request",
+			url:  "https://bitbucket.org/team/repo/pull-requests/12/overview",
+			want: github.URLInfo{Type: github.URLTypePR, Owner: "team", Repo: "repo", PRNumber: "12"},
+		},
+		{name: "github host", url: "https://github.com/team/repo/pull/1", wantErr: true},
+		{name: "bad number", url: "https://bitbucket.org/team/repo/pull-requests/abc", wantErr: true},
+		{name: "unsupported kind", url: "https://bitbucket.org/team/repo/src/main", wantErr: true},
+		{name: "empty", url: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBitbucketURL(tt.url)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseBitbucketURL() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBitbucketRemote(t *testing.T) {
+	tests := []struct {
+		remote    string
+		wantWS    string
+		wantRepo  string
+		wantError bool
+	}{
+		{remote: "git@bitbucket.org:team/repo.git", wantWS: "team", wantRepo: "repo"},
+		{remote: "https://me@bitbucket.org/team/repo.git", wantWS: "team", wantRepo: "repo"},
+		{remote: "git@github.com:team/repo.git", wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			ws, repo, err := ParseBitbucketRemote(tt.remote)
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil || ws != tt.wantWS || repo != tt.wantRepo {
+				t.Errorf("ParseBitbucketRemote() = %q, %q, %v; want %q, %q", ws, repo, err, tt.wantWS, tt.wantRepo)
+			}
+		})
+	}
+}
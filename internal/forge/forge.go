@@ -0,0 +1,136 @@
+// Package forge abstracts the code-hosting service (GitHub, GitLab, Bitbucket)
+// that serves pull request and CI data for a repository.
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/gitlab"
+)
+
+// Supported forge kinds, as written in the `forge` field of a repository.
+const (
+	KindGitHub    = "github"
+	KindBitbucket = "bitbucket"
+	KindGitLab    = "gitlab"
+)
+
+// ErrUnsupported is returned for operations a forge has no equivalent for,
+// e.g. PR labels on Bitbucket.
+var ErrUnsupported = errors.New("not supported by this forge")
+
+// Provider serves pull request data for repositories hosted on one forge.
+// PRs are returned as github.PRView, the common shape rendered by diff-ui.
+type Provider interface {
+	// Kind returns the forge kind, e.g. KindGitHub.
+	Kind() string
+	// FetchPR returns the PR for the branch checked out in dir.
+	FetchPR(dir string) (github.PRView, error)
+	// FetchPRs returns the open PRs whose head is branch.
+	FetchPRs(dir, branch string) ([]github.PRView, error)
+	// FetchBranchChecks returns the CI checks of the latest commit on branch
+	// that has any, e.g. to tell whether the base branch itself is failing.
+	FetchBranchChecks(dir, branch string) ([]github.StatusCheckNode, error)
+	// ResolveBranch returns the branch referenced by a branch or PR URL.
+	ResolveBranch(dir, rawURL string) (string, error)
+	// AddLabel and RemoveLabel edit the labels of PR number, or of the PR
+	// for the current branch when number is 0.
+	AddLabel(dir string, number int, label string) error
+	RemoveLabel(dir string, number int, label string) error
+}
+
+// CommentPager is implemented by providers whose FetchPR leaves comments out
+// so that they can be fetched a page at a time, newest page first.
+type CommentPager interface {
+	// FetchComments returns up to limit comments of PR number that come
+	// before the cursor before, or the newest ones when before is "".
+	FetchComments(dir string, number, limit int, before string) (github.CommentPage, error)
+}
+
+// OpenPRLister is implemented by providers that can list every open PR of a
+// repository in one call, rather than one call per branch with FetchPRs.
+// The PRs carry just the number, head branch and checks.
+type OpenPRLister interface {
+	FetchOpenPRs(dir string) ([]github.PRView, error)
+}
+
+// Options carries the dependencies used to construct providers.
+type Options struct {
+	// GitHubRunner may be nil when neither gh nor a token is available;
+	// GitHub branch URLs still resolve without it.
+	GitHubRunner github.Runner
+	// GitLabRunner may be nil when glab is not installed.
+	GitLabRunner gitlab.Runner
+	GitRunner    git.CommandRunner
+}
+
+// ParseKind normalizes a configured forge such as "GitHub" to its kind and
+// checks it is known. An empty string stays empty, for detecting the forge.
+func ParseKind(s string) (string, error) {
+	kind := strings.ToLower(strings.TrimSpace(s))
+	switch kind {
+	case "", KindGitHub, KindBitbucket, KindGitLab:
+		return kind, nil
+	}
+	return "", fmt.Errorf("unknown forge %q, must be %q, %q or %q", s, KindGitHub, KindGitLab, KindBitbucket)
+}
+
+// New returns the provider for kind. An empty kind means GitHub.
+func New(kind string, opts Options) (Provider, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", KindGitHub:
+		return GitHub{Runner: opts.GitHubRunner}, nil
+	case KindBitbucket:
+		return NewBitbucket(opts.GitRunner), nil
+	case KindGitLab:
+		return GitLab{Runner: opts.GitLabRunner}, nil
+	default:
+		return nil, fmt.Errorf("unknown forge %q", kind)
+	}
+}
+
+// DetectKind guesses the forge from a git remote URL, defaulting to GitHub.
+func DetectKind(remote string) string {
+	host := remote
+	if rest, ok := strings.CutPrefix(remote, "git@"); ok {
+		host, _, _ = strings.Cut(rest, ":")
+	} else if parsed, err := url.Parse(remote); err == nil {
+		host = parsed.Hostname()
+	}
+
+	switch {
+	case strings.HasSuffix(host, "bitbucket.org"):
+		return KindBitbucket
+	case strings.Contains(host, "gitlab"):
+		return KindGitLab
+	default:
+		return KindGitHub
+	}
+}
+
+// BranchName returns the branch a base ref such as "origin/main" tracks on
+// the forge.
+func BranchName(baseRef string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(baseRef, "refs/remotes/"), "origin/")
+}
+
+// KindFor returns the configured forge kind, normalized like ParseKind,
+// falling back to detecting it from the origin remote of dir.
+func KindFor(configured string, runner git.CommandRunner, dir string) string {
+	if kind := strings.ToLower(strings.TrimSpace(configured)); kind != "" {
+		return kind
+	}
+	if runner == nil {
+		return KindGitHub
+	}
+	remote, err := git.RemoteURL(runner, dir)
+	if err != nil {
+		return KindGitHub
+	}
+	return DetectKind(remote)
+}
@@ -0,0 +1,131 @@
+package forge
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		kind    string
+		want    string
+		wantErr bool
+	}{
+		{kind: "", want: KindGitHub},
+		{kind: "GitHub", want: KindGitHub},
+		{kind: "bitbucket", want: KindBitbucket},
+		{kind: "gitlab", want: KindGitLab},
+		{kind: "svn", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			p, err := New(tt.kind, Options{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Kind() != tt.want {
+				t.Errorf("Kind() = %q, want %q", p.Kind(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectKind(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"git@github.com:owner/repo.git", KindGitHub},
+		{"https://github.com/owner/repo", KindGitHub},
+		{"git@bitbucket.org:team/repo.git", KindBitbucket},
+		{"https://user@bitbucket.org/team/repo.git", KindBitbucket},
+		{"https://bitbucket.org/team/repo/pull-requests/3", KindBitbucket},
+		{"git@gitlab.com:group/repo.git", KindGitLab},
+		{"https://gitlab.example.com/group/sub/repo.git", KindGitLab},
+		{"not a url", KindGitHub},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			if got := DetectKind(tt.remote); got != tt.want {
+				t.Errorf("DetectKind(%q) = %q, want %q", tt.remote, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	for ref, want := range map[string]string{
+		"origin/main":                 "main",
+		"refs/remotes/origin/develop": "develop",
+		"main":                        "main",
+	} {
+		if got := BranchName(ref); got != want {
+			t.Errorf("BranchName(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	if got, err := ParseKind("GitLab"); err != nil || got != KindGitLab {
+		t.Errorf("ParseKind(GitLab) = %q, %v, want %q", got, err, KindGitLab)
+	}
+	if got, err := ParseKind(""); err != nil || got != "" {
+		t.Errorf("ParseKind(\"\") = %q, %v, want empty", got, err)
+	}
+	if _, err := ParseKind("gitea"); err == nil {
+		t.Error("ParseKind(gitea) should fail")
+	}
+}
+
+func TestKindFor(t *testing.T) {
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[remote get-url origin]": "git@bitbucket.org:team/repo.git\n",
+		},
+	}
+
+	if got := KindFor("github", runner, "/repo"); got != KindGitHub {
+		t.Errorf("configured kind = %q, want %q", got, KindGitHub)
+	}
+	if got := KindFor(" GitHub", runner, "/repo"); got != KindGitHub {
+		t.Errorf("configured kind = %q, want it normalized to %q", got, KindGitHub)
+	}
+	if got := KindFor("", runner, "/repo"); got != KindBitbucket {
+		t.Errorf("detected kind = %q, want %q", got, KindBitbucket)
+	}
+	if got := KindFor("", runner, "/other"); got != KindGitHub {
+		t.Errorf("fallback kind = %q, want %q", got, KindGitHub)
+	}
+}
+
+func TestGitHub_ResolveBranch(t *testing.T) {
+	prURL := "https://github.com/owner/repo/pull/42"
+	runner := &github.FakeRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("/repo:%v", []string{"pr", "view", prURL, "--json", "headRefName"}): `{"headRefName":"feature/from-pr"}`,
+		},
+	}
+
+	branch, err := GitHub{}.ResolveBranch("/repo", "https://github.com/owner/repo/tree/feature/x")
+	if err != nil || branch != "feature/x" {
+		t.Errorf("branch URL = %q, %v; want feature/x", branch, err)
+	}
+
+	if _, err := (GitHub{}).ResolveBranch("/repo", prURL); err == nil {
+		t.Error("expected error resolving PR URL without a runner")
+	}
+
+	branch, err = GitHub{Runner: runner}.ResolveBranch("/repo", prURL)
+	if err != nil || branch != "feature/from-pr" {
+		t.Errorf("PR URL = %q, %v; want feature/from-pr", branch, err)
+	}
+}
@@ -0,0 +1,86 @@
+package forge
+
+import (
+	"fmt"
+
+	"github.com/mikanfactory/yakumo/internal/github"
+)
+
+// GitHub is the Provider for github.com, backed by a github.Runner.
+type GitHub struct {
+	Runner github.Runner
+}
+
+func (g GitHub) Kind() string { return KindGitHub }
+
+func (g GitHub) FetchPR(dir string) (github.PRView, error) {
+	if g.Runner == nil {
+		return github.PRView{}, errNoGitHubRunner
+	}
+	return github.FetchPR(g.Runner, dir)
+}
+
+func (g GitHub) FetchPRs(dir, branch string) ([]github.PRView, error) {
+	if g.Runner == nil {
+		return nil, errNoGitHubRunner
+	}
+	return github.FetchPRs(g.Runner, dir, branch)
+}
+
+func (g GitHub) FetchOpenPRs(dir string) ([]github.PRView, error) {
+	if g.Runner == nil {
+		return nil, errNoGitHubRunner
+	}
+	return github.FetchOpenPRs(g.Runner, dir)
+}
+
+func (g GitHub) FetchComments(dir string, number, limit int, before string) (github.CommentPage, error) {
+	if g.Runner == nil {
+		return github.CommentPage{}, errNoGitHubRunner
+	}
+	return github.FetchPRComments(g.Runner, dir, number, limit, before)
+}
+
+func (g GitHub) FetchBranchChecks(dir, branch string) ([]github.StatusCheckNode, error) {
+	if g.Runner == nil {
+		return nil, errNoGitHubRunner
+	}
+	return github.FetchBranchChecks(g.Runner, dir, branch)
+}
+
+func (g GitHub) ResolveBranch(dir, rawURL string) (string, error) {
+	info, err := github.ParseGitHubURL(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	switch info.Type {
+	case github.URLTypeBranch:
+		return info.Branch, nil
+	case github.URLTypeIssue:
+		return "", fmt.Errorf("issue URLs do not name a branch: %s", rawURL)
+	}
+	if g.Runner == nil {
+		return "", fmt.Errorf("cannot resolve PR URL: %w", errNoGitHubRunner)
+	}
+	branch, err := github.FetchPRBranch(g.Runner, dir, rawURL)
+	if err != nil {
+		return "", fmt.Errorf("resolving PR branch: %w", err)
+	}
+	return branch, nil
+}
+
+func (g GitHub) AddLabel(dir string, number int, label string) error {
+	if g.Runner == nil {
+		return errNoGitHubRunner
+	}
+	return github.AddLabel(g.Runner, dir, number, label)
+}
+
+func (g GitHub) RemoveLabel(dir string, number int, label string) error {
+	if g.Runner == nil {
+		return errNoGitHubRunner
+	}
+	return github.RemoveLabel(g.Runner, dir, number, label)
+}
+
+var errNoGitHubRunner = fmt.Errorf("gh CLI is not available and no GitHub token is set")
@@ -0,0 +1,249 @@
+package forge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/gitlab"
+)
+
+// GitLab is the Provider for GitLab merge requests, backed by the glab CLI.
+type GitLab struct {
+	Runner gitlab.Runner
+}
+
+func (g GitLab) Kind() string { return KindGitLab }
+
+func (g GitLab) FetchPR(dir string) (github.PRView, error) {
+	if g.Runner == nil {
+		return github.PRView{}, errNoGitLabRunner
+	}
+	mr, err := gitlab.FetchMR(g.Runner, dir)
+	if err != nil {
+		return github.PRView{}, err
+	}
+	return g.buildPR(dir, mr)
+}
+
+func (g GitLab) FetchPRs(dir, branch string) ([]github.PRView, error) {
+	if g.Runner == nil {
+		return nil, errNoGitLabRunner
+	}
+	mrs, err := gitlab.FetchMRs(g.Runner, dir, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	prs := make([]github.PRView, 0, len(mrs))
+	for _, mr := range mrs {
+		// List output omits the pipeline; fetch the full MR for each.
+		full, err := gitlab.FetchMRByIID(g.Runner, dir, "", mr.IID)
+		if err != nil {
+			return nil, err
+		}
+		pr, err := g.buildPR(dir, full)
+		if err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+func (g GitLab) ResolveBranch(dir, rawURL string) (string, error) {
+	info, err := gitlab.ParseGitLabURL(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if !info.IsMR() {
+		return info.Branch, nil
+	}
+	if g.Runner == nil {
+		return "", fmt.Errorf("cannot resolve MR URL: %w", errNoGitLabRunner)
+	}
+	mr, err := gitlab.FetchMRByIID(g.Runner, dir, info.Project, info.MRIID)
+	if err != nil {
+		return "", fmt.Errorf("resolving MR branch: %w", err)
+	}
+	if mr.SourceBranch == "" {
+		return "", fmt.Errorf("MR has no source branch")
+	}
+	return mr.SourceBranch, nil
+}
+
+func (g GitLab) FetchBranchChecks(dir, branch string) ([]github.StatusCheckNode, error) {
+	if g.Runner == nil {
+		return nil, errNoGitLabRunner
+	}
+	pipeline, err := gitlab.FetchLatestPipeline(g.Runner, dir, branch)
+	if err != nil || pipeline == nil {
+		return nil, err
+	}
+	jobs, err := gitlab.FetchJobs(g.Runner, dir, pipeline.ID)
+	if err != nil {
+		return nil, err
+	}
+	checks := make([]github.StatusCheckNode, len(jobs))
+	for i, j := range jobs {
+		checks[i] = gitlabJobCheck(j)
+	}
+	return checks, nil
+}
+
+func (g GitLab) AddLabel(dir string, number int, label string) error {
+	if g.Runner == nil {
+		return errNoGitLabRunner
+	}
+	iid, err := g.labelTarget(dir, number)
+	if err != nil {
+		return err
+	}
+	return gitlab.AddLabel(g.Runner, dir, iid, label)
+}
+
+func (g GitLab) RemoveLabel(dir string, number int, label string) error {
+	if g.Runner == nil {
+		return errNoGitLabRunner
+	}
+	iid, err := g.labelTarget(dir, number)
+	if err != nil {
+		return err
+	}
+	return gitlab.RemoveLabel(g.Runner, dir, iid, label)
+}
+
+// labelTarget returns number, or the IID of the current branch's MR when
+// number is 0.
+func (g GitLab) labelTarget(dir string, number int) (int, error) {
+	if number != 0 {
+		return number, nil
+	}
+	mr, err := gitlab.FetchMR(g.Runner, dir)
+	if err != nil {
+		return 0, err
+	}
+	return mr.IID, nil
+}
+
+// buildPR converts an MR plus its notes, approvals and pipeline jobs into a
+// github.PRView.
+func (g GitLab) buildPR(dir string, mr gitlab.MRView) (github.PRView, error) {
+	pr := github.PRView{
+		Number:      mr.IID,
+		BaseRefName: mr.TargetBranch,
+		Title:       mr.Title,
+		Body:        mr.Description,
+		State:       gitlabMRState(mr.State),
+		URL:         mr.WebURL,
+	}
+	pr.MergeStateStatus, pr.ReviewDecision = gitlabMergeStatus(mr)
+
+	for _, l := range mr.Labels {
+		pr.Labels = append(pr.Labels, github.LabelNode{Name: l})
+	}
+	for _, a := range mr.Assignees {
+		pr.Assignees = append(pr.Assignees, github.UserNode{Login: a.Username})
+	}
+
+	approvals, err := gitlab.FetchApprovals(g.Runner, dir, mr.IID)
+	if err != nil {
+		return github.PRView{}, err
+	}
+	approved := make(map[string]bool)
+	for _, a := range approvals.ApprovedBy {
+		approved[a.User.Username] = true
+		pr.LatestReviews = append(pr.LatestReviews, github.ReviewNode{
+			Author: github.CommentAuthor{Login: a.User.Username},
+			State:  "APPROVED",
+		})
+	}
+	for _, r := range mr.Reviewers {
+		if !approved[r.Username] {
+			pr.ReviewRequests = append(pr.ReviewRequests, github.ReviewRequest{Login: r.Username})
+		}
+	}
+
+	notes, err := gitlab.FetchNotes(g.Runner, dir, mr.IID)
+	if err != nil {
+		return github.PRView{}, err
+	}
+	for _, n := range notes {
+		pr.Comments = append(pr.Comments, github.CommentNode{
+			Author:    github.CommentAuthor{Login: n.Author.Username},
+			Body:      n.Body,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+
+	if mr.HeadPipeline != nil {
+		jobs, err := gitlab.FetchJobs(g.Runner, dir, mr.HeadPipeline.ID)
+		if err != nil {
+			return github.PRView{}, err
+		}
+		for _, j := range jobs {
+			pr.StatusCheckRollup = append(pr.StatusCheckRollup, gitlabJobCheck(j))
+		}
+	}
+
+	return pr, nil
+}
+
+func gitlabMRState(state string) string {
+	switch state {
+	case "opened":
+		return "OPEN"
+	case "merged":
+		return "MERGED"
+	default:
+		return "CLOSED"
+	}
+}
+
+// gitlabMergeStatus maps detailed_merge_status onto GitHub's
+// mergeStateStatus and reviewDecision so MapMergeStateStatus can label it.
+func gitlabMergeStatus(mr gitlab.MRView) (string, string) {
+	if mr.HasConflicts {
+		return "DIRTY", ""
+	}
+	switch mr.DetailedMergeStatus {
+	case "mergeable":
+		return "CLEAN", ""
+	case "conflict":
+		return "DIRTY", ""
+	case "need_rebase":
+		return "BEHIND", ""
+	case "ci_must_pass", "ci_still_running":
+		return "UNSTABLE", ""
+	case "requested_changes":
+		return "BLOCKED", "CHANGES_REQUESTED"
+	case "not_approved", "discussions_not_resolved", "draft_status", "blocked_status", "not_open":
+		return "BLOCKED", ""
+	default:
+		return strings.ToUpper(mr.DetailedMergeStatus), ""
+	}
+}
+
+func gitlabJobCheck(j gitlab.Job) github.StatusCheckNode {
+	node := github.StatusCheckNode{
+		Name:        j.Name,
+		Context:     j.Stage,
+		StartedAt:   j.StartedAt,
+		CompletedAt: j.FinishedAt,
+	}
+	switch j.Status {
+	case "success":
+		node.Conclusion = "SUCCESS"
+	case "failed":
+		node.Conclusion = "FAILURE"
+	case "canceled":
+		node.Conclusion = "CANCELLED"
+	case "skipped", "manual":
+		node.Conclusion = "SKIPPED"
+	default:
+		node.State = "PENDING"
+	}
+	return node
+}
+
+var errNoGitLabRunner = fmt.Errorf("glab CLI is not available")
@@ -0,0 +1,161 @@
+package forge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/gitlab"
+)
+
+func gitlabDetailOutputs() map[string]string {
+	return map[string]string{
+		"/repo:[mr view --output json]": `{
+			"iid": 3, "title": "feat: mr", "description": "desc", "state": "opened",
+			"web_url": "https://gitlab.com/group/repo/-/merge_requests/3",
+			"source_branch": "feat", "target_branch": "main",
+			"labels": ["bug"], "assignees": [{"username": "alice"}],
+			"reviewers": [{"username": "bob"}, {"username": "carol"}],
+			"detailed_merge_status": "requested_changes",
+			"head_pipeline": {"id": 99, "status": "failed"}
+		}`,
+		"/repo:[api projects/:id/merge_requests/3/approvals]":      `{"approved_by": [{"user": {"username": "carol"}}]}`,
+		"/repo:[api projects/:id/merge_requests/3/notes?sort=asc]": `[{"author": {"username": "dave"}, "body": "nice"}]`,
+		"/repo:[api projects/:id/pipelines/99/jobs]": `[
+			{"name": "test", "stage": "test", "status": "failed"},
+			{"name": "lint", "stage": "test", "status": "success"},
+			{"name": "deploy", "stage": "deploy", "status": "running"}
+		]`,
+	}
+}
+
+func TestGitLab_FetchPR(t *testing.T) {
+	g := GitLab{Runner: &gitlab.FakeRunner{Outputs: gitlabDetailOutputs()}}
+
+	pr, err := g.FetchPR("/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if pr.Number != 3 || pr.State != "OPEN" || pr.BaseRefName != "main" {
+		t.Errorf("unexpected PR header: %+v", pr)
+	}
+	if got := github.MapMergeStateStatus(pr.MergeStateStatus, pr.ReviewDecision); got != "Changes requested" {
+		t.Errorf("merge status = %q, want Changes requested", got)
+	}
+	if got := strings.Join(pr.LabelNames(), ","); got != "bug" {
+		t.Errorf("labels = %q, want bug", got)
+	}
+	if got := strings.Join(pr.ReviewerNames(), ","); got != "carol,bob" {
+		t.Errorf("reviewers = %q, want carol,bob", got)
+	}
+	if len(pr.Comments) != 1 || pr.Comments[0].Author.Login != "dave" {
+		t.Errorf("comments = %+v, want one from dave", pr.Comments)
+	}
+	if len(pr.StatusCheckRollup) != 3 {
+		t.Fatalf("expected 3 checks, got %d", len(pr.StatusCheckRollup))
+	}
+	passed := []bool{false, true, false}
+	for i, want := range passed {
+		if got := pr.StatusCheckRollup[i].Passed(); got != want {
+			t.Errorf("check %d Passed() = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestGitLab_FetchPRs(t *testing.T) {
+	outputs := gitlabDetailOutputs()
+	outputs["/repo:[mr list --source-branch feat --output json]"] = `[{"iid": 3}]`
+	outputs["/repo:[mr view 3 --output json]"] = outputs["/repo:[mr view --output json]"]
+	g := GitLab{Runner: &gitlab.FakeRunner{Outputs: outputs}}
+
+	prs, err := g.FetchPRs("/repo", "feat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs) != 1 || prs[0].Number != 3 || len(prs[0].StatusCheckRollup) != 3 {
+		t.Errorf("prs = %+v, want !3 with pipeline jobs", prs)
+	}
+}
+
+func TestGitLab_FetchBranchChecks(t *testing.T) {
+	outputs := gitlabDetailOutputs()
+	outputs["/repo:[api projects/:id/pipelines?per_page=1&ref=main]"] = `[{"id": 99, "status": "failed"}]`
+	g := GitLab{Runner: &gitlab.FakeRunner{Outputs: outputs}}
+
+	checks, err := g.FetchBranchChecks("/repo", "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := (github.PRView{StatusCheckRollup: checks}).CheckRollup(); len(checks) != 3 || got != github.RollupFailure {
+		t.Errorf("checks = %+v (rollup %q), want the pipeline's 3 jobs failing", checks, got)
+	}
+}
+
+func TestGitLab_ResolveBranch(t *testing.T) {
+	runner := &gitlab.FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[mr view 12 --repo group/repo --output json]": `{"iid": 12, "source_branch": "feature/from-mr"}`,
+		},
+	}
+
+	branch, err := GitLab{}.ResolveBranch("/repo", "https://gitlab.com/group/repo/-/tree/feature/x")
+	if err != nil || branch != "feature/x" {
+		t.Errorf("branch URL = %q, %v; want feature/x", branch, err)
+	}
+
+	if _, err := (GitLab{}).ResolveBranch("/repo", "https://gitlab.com/group/repo/-/merge_requests/12"); err == nil {
+		t.Error("expected error resolving MR URL without glab")
+	}
+
+	branch, err = GitLab{Runner: runner}.ResolveBranch("/repo", "https://gitlab.com/group/repo/-/merge_requests/12")
+	if err != nil || branch != "feature/from-mr" {
+		t.Errorf("MR URL = %q, %v; want feature/from-mr", branch, err)
+	}
+}
+
+func TestGitLab_AddLabel(t *testing.T) {
+	outputs := gitlabDetailOutputs()
+	outputs["/repo:[mr update 3 --label ui]"] = ""
+	runner := &gitlab.FakeRunner{Outputs: outputs}
+
+	if err := (GitLab{Runner: runner}).AddLabel("/repo", 0, "ui"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := runner.Calls[len(runner.Calls)-1]
+	if strings.Join(last, " ") != "/repo mr update 3 --label ui" {
+		t.Errorf("last call = %v, want mr update 3 --label ui", last)
+	}
+}
+
+func TestGitLab_RemoveLabelFromMR(t *testing.T) {
+	runner := &gitlab.FakeRunner{Outputs: map[string]string{"/repo:[mr update 8 --unlabel ui]": ""}}
+
+	if err := (GitLab{Runner: runner}).RemoveLabel("/repo", 8, "ui"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runner.Calls) != 1 {
+		t.Errorf("calls = %v, want only the edit of !8", runner.Calls)
+	}
+}
+
+func TestGitlabMergeStatus(t *testing.T) {
+	tests := []struct {
+		mr   gitlab.MRView
+		want string
+	}{
+		{gitlab.MRView{DetailedMergeStatus: "mergeable"}, "Ready to merge"},
+		{gitlab.MRView{DetailedMergeStatus: "need_rebase"}, "Behind base branch"},
+		{gitlab.MRView{DetailedMergeStatus: "ci_must_pass"}, "Checks failing"},
+		{gitlab.MRView{DetailedMergeStatus: "not_approved"}, "Blocked"},
+		{gitlab.MRView{DetailedMergeStatus: "mergeable", HasConflicts: true}, "Merge conflicts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mr.DetailedMergeStatus, func(t *testing.T) {
+			state, decision := gitlabMergeStatus(tt.mr)
+			if got := github.MapMergeStateStatus(state, decision); got != tt.want {
+				t.Errorf("status = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package git
+
+import "strings"
+
+// Branch is a local or remote-tracking branch that can be checked out into a
+// worktree.
+type Branch struct {
+	Name   string // branch name without the remote, e.g. "fix-login"
+	Remote string // remote name for remote-tracking branches, "" for local ones
+}
+
+// String returns the ref as git shows it, e.g. "origin/fix-login".
+func (b Branch) String() string {
+	if b.Remote == "" {
+		return b.Name
+	}
+	return b.Remote + "/" + b.Name
+}
+
+// ListBranches returns the local branches of the repository followed by its
+// remote-tracking branches, each most recently committed first. Remote
+// branches that also exist locally are omitted, as are the remotes' symbolic
+// HEAD refs.
+func ListBranches(runner CommandRunner, repoPath string) ([]Branch, error) {
+	out, err := runWithRetry(runner, repoPath, "for-each-ref", "--sort=-committerdate", "--format=%(refname)\t%(symref)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	return parseBranches(out), nil
+}
+
+// FindBranch looks name up as a local branch, then as a branch of origin.
+// It reports false when neither exists.
+func FindBranch(runner CommandRunner, repoPath, name string) (Branch, bool) {
+	if _, err := runner.Run(repoPath, "show-ref", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+		return Branch{Name: name}, true
+	}
+	if _, err := runner.Run(repoPath, "show-ref", "--verify", "--quiet", "refs/remotes/origin/"+name); err == nil {
+		return Branch{Name: name, Remote: "origin"}, true
+	}
+	return Branch{}, false
+}
+
+func parseBranches(output string) []Branch {
+	var local, remote []Branch
+	for _, line := range strings.Split(output, "\n") {
+		ref, symref, _ := strings.Cut(strings.TrimSpace(line), "\t")
+		if ref == "" || symref != "" {
+			continue
+		}
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			local = append(local, Branch{Name: name})
+			continue
+		}
+		if rest, ok := strings.CutPrefix(ref, "refs/remotes/"); ok {
+			if r, name, ok := strings.Cut(rest, "/"); ok && name != "" {
+				remote = append(remote, Branch{Name: name, Remote: r})
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(local))
+	for _, b := range local {
+		seen[b.Name] = true
+	}
+	branches := local
+	for _, b := range remote {
+		if !seen[b.Name] {
+			seen[b.Name] = true
+			branches = append(branches, b)
+		}
+	}
+	return branches
+}
+
+// MergedBranches returns the local branches whose commits have all reached
+// base. Branches pointing at a commit on base's first-parent history are left
+// out: they were forked and never committed to rather than merged (a
+// fast-forward merge looks the same and is missed too).
+func MergedBranches(runner CommandRunner, repoPath, base string) (map[string]bool, error) {
+	out, err := runner.Run(repoPath, "for-each-ref", "--merged", base, "--format=%(refname:short)\t%(objectname)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	tips := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		name, hash, ok := strings.Cut(line, "\t")
+		if ok && name != "" {
+			tips[name] = hash
+		}
+	}
+	merged := make(map[string]bool)
+	if len(tips) == 0 {
+		return merged, nil
+	}
+
+	out, err = runner.Run(repoPath, "rev-list", "--first-parent", base)
+	if err != nil {
+		return nil, err
+	}
+	mainline := make(map[string]bool)
+	for _, hash := range strings.Fields(out) {
+		mainline[hash] = true
+	}
+	for name, hash := range tips {
+		if !mainline[hash] {
+			merged[name] = true
+		}
+	}
+	return merged, nil
+}
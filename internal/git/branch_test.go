@@ -0,0 +1,88 @@
+package git
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestListBranches(t *testing.T) {
+	output := "refs/heads/fix-login\t\n" +
+		"refs/remotes/origin/HEAD\trefs/remotes/origin/main\n" +
+		"refs/remotes/origin/feature/search\t\n" +
+		"refs/heads/main\t\n" +
+		"refs/remotes/origin/main\t\n" +
+		"refs/remotes/upstream/feature/search\t\n"
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[for-each-ref --sort=-committerdate --format=%(refname)\t%(symref) refs/heads refs/remotes]": output,
+		},
+	}
+
+	got, err := ListBranches(runner, "/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Branch{
+		{Name: "fix-login"},
+		{Name: "main"},
+		{Name: "feature/search", Remote: "origin"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListBranches = %+v, want %+v", got, want)
+	}
+}
+
+func TestListBranches_Error(t *testing.T) {
+	runner := FakeCommandRunner{
+		Errors: map[string]error{
+			"/repo:[for-each-ref --sort=-committerdate --format=%(refname)\t%(symref) refs/heads refs/remotes]": fmt.Errorf("not a git repository"),
+		},
+	}
+
+	if _, err := ListBranches(runner, "/repo"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestBranch_String(t *testing.T) {
+	if got := (Branch{Name: "fix", Remote: "origin"}).String(); got != "origin/fix" {
+		t.Errorf("String() = %q, want %q", got, "origin/fix")
+	}
+	if got := (Branch{Name: "fix"}).String(); got != "fix" {
+		t.Errorf("String() = %q, want %q", got, "fix")
+	}
+}
+
+func TestMergedBranches(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[for-each-ref --merged origin/main --format=%(refname:short)\t%(objectname) refs/heads]": "main\taaa\nfresh\tbbb\nshipped\tccc\n",
+			"/repo:[rev-list --first-parent origin/main]":                                                   "ddd\naaa\nbbb\n",
+		},
+	}
+
+	merged, err := MergedBranches(runner, "/repo", "origin/main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 1 || !merged["shipped"] {
+		t.Errorf("merged = %v, want only shipped", merged)
+	}
+}
+
+func TestMergedBranches_RealRepo(t *testing.T) {
+	dir, runner := gitRepo(t)
+	commitFile(t, runner, dir, "a.txt", "a\n", "add a")
+	mustGit(t, runner, dir, "checkout", "-q", "main")
+	mustGit(t, runner, dir, "branch", "fresh")
+	mustGit(t, runner, dir, "merge", "-q", "--no-ff", "-m", "merge feature", "feature")
+
+	merged, err := MergedBranches(runner, dir, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 1 || !merged["feature"] {
+		t.Errorf("merged = %v, want only feature", merged)
+	}
+}
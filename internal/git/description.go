@@ -0,0 +1,59 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetBranchDescriptions returns every branch.<name>.description set in the
+// repository, keyed by branch name. Worktrees share the repository config, so
+// one call covers all of them. Descriptions are cosmetic, so failures
+// (including git config's exit status 1 when nothing matches) yield an empty
+// map.
+func GetBranchDescriptions(runner CommandRunner, repoPath string) map[string]string {
+	out, err := runner.Run(repoPath, "config", "--get-regexp", `^branch\..*\.description$`)
+	if err != nil {
+		return map[string]string{}
+	}
+	return parseBranchDescriptions(out)
+}
+
+// parseBranchDescriptions parses `git config --get-regexp` output of the form
+// "branch.<name>.description <value>" per line. Only the first line of a
+// multi-line description is kept.
+func parseBranchDescriptions(output string) map[string]string {
+	descriptions := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		name, ok := strings.CutPrefix(key, "branch.")
+		if !ok {
+			continue
+		}
+		name, ok = strings.CutSuffix(name, ".description")
+		if !ok || name == "" {
+			continue
+		}
+		descriptions[name] = strings.TrimSpace(value)
+	}
+	return descriptions
+}
+
+// SetBranchDescription stores a one-line purpose for branch, as
+// `git branch --edit-description` does. An empty description removes it.
+func SetBranchDescription(runner CommandRunner, dir, branch, description string) error {
+	key := "branch." + branch + ".description"
+	description = strings.TrimSpace(description)
+	if description == "" {
+		if _, err := runner.Run(dir, "config", "--unset", key); err != nil {
+			return fmt.Errorf("clearing description of %s: %w", branch, err)
+		}
+		return nil
+	}
+	if _, err := runner.Run(dir, "config", key, description); err != nil {
+		return fmt.Errorf("setting description of %s: %w", branch, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetBranchDescriptions(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			`/repo:[config --get-regexp ^branch\..*\.description$]`: "branch.feat/login.description Fix the login redirect\n" +
+				"branch.v1.2.description Release branch\nsecond line of v1.2\n",
+		},
+	}
+
+	got := GetBranchDescriptions(runner, "/repo")
+	want := map[string]string{
+		"feat/login": "Fix the login redirect",
+		"v1.2":       "Release branch",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("got[%q] = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestGetBranchDescriptions_NoneSet(t *testing.T) {
+	runner := FakeCommandRunner{
+		Errors: map[string]error{
+			`/repo:[config --get-regexp ^branch\..*\.description$]`: fmt.Errorf("exit status 1"),
+		},
+	}
+
+	if got := GetBranchDescriptions(runner, "/repo"); len(got) != 0 {
+		t.Errorf("got %v, want empty", got)
+	}
+}
+
+func TestSetBranchDescription(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		runner := FakeCommandRunner{
+			Outputs: map[string]string{
+				"/wt:[config branch.feat.description Fix login]": "",
+			},
+		}
+		if err := SetBranchDescription(runner, "/wt", "feat", "  Fix login \n"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("empty clears", func(t *testing.T) {
+		runner := FakeCommandRunner{
+			Outputs: map[string]string{
+				"/wt:[config --unset branch.feat.description]": "",
+			},
+		}
+		if err := SetBranchDescription(runner, "/wt", "feat", ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 )
@@ -70,6 +71,19 @@ func GetAllChanges(runner CommandRunner, dir string, base string) ([]DiffEntry,
 	return mergeEntries(committed, uncommitted), nil
 }
 
+// GetChanges returns the changed files in dir relative to base, as chosen by
+// strategy, including uncommitted changes.
+func GetChanges(runner CommandRunner, dir string, base string, strategy DiffBase) ([]DiffEntry, error) {
+	if strategy != DiffBaseRef {
+		return GetAllChanges(runner, dir, base)
+	}
+	out, err := runner.Run(dir, "diff", base, "--numstat")
+	if err != nil {
+		return nil, err
+	}
+	return parseDiffNumstat(out), nil
+}
+
 // mergeEntries merges two slices of DiffEntry by path. When both contain the
 // same path, additions and deletions are summed. Order follows committed first,
 // then any uncommitted-only entries appended.
@@ -111,3 +125,45 @@ func GetCommitsBehind(runner CommandRunner, dir string, base string) (int, error
 	}
 	return n, nil
 }
+
+// GetAheadBehind returns how many commits HEAD is ahead of and behind the
+// given base ref, counted from their merge base.
+func GetAheadBehind(runner CommandRunner, dir string, base string) (int, int, error) {
+	out, err := runner.Run(dir, "rev-list", "--left-right", "--count", base+"...HEAD")
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output %q", strings.TrimSpace(out))
+	}
+	behind, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	ahead, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
+
+// GetBranchDiff returns the `--stat` summary and the full patch of everything
+// on the branch in dir relative to base, as chosen by strategy, including
+// uncommitted changes in the working tree.
+func GetBranchDiff(runner CommandRunner, dir string, base string, strategy DiffBase) (string, string, error) {
+	forkPoint, err := strategy.Resolve(runner, dir, base)
+	if err != nil {
+		return "", "", err
+	}
+
+	stat, err := runner.Run(dir, "diff", "--stat", forkPoint)
+	if err != nil {
+		return "", "", err
+	}
+	patch, err := runner.Run(dir, "diff", forkPoint)
+	if err != nil {
+		return "", "", err
+	}
+	return stat, patch, nil
+}
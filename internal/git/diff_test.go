@@ -245,3 +245,116 @@ func TestGetCommitsBehind_Error(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestGetAheadBehind(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[rev-list --left-right --count origin/main...HEAD]": "2\t5\n",
+		},
+	}
+
+	ahead, behind, err := GetAheadBehind(runner, "/repo", "origin/main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ahead != 5 || behind != 2 {
+		t.Errorf("got ahead=%d behind=%d, want ahead=5 behind=2", ahead, behind)
+	}
+}
+
+func TestGetAheadBehind_UnexpectedOutput(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[rev-list --left-right --count origin/main...HEAD]": "garbage\n",
+		},
+	}
+
+	if _, _, err := GetAheadBehind(runner, "/repo", "origin/main"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestGetBranchDiff(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[merge-base origin/main HEAD]": "abc123\n",
+			"/repo:[diff --stat abc123]":          " main.go | 2 +-\n",
+			"/repo:[diff abc123]":                 "diff --git a/main.go b/main.go\n",
+		},
+	}
+
+	stat, patch, err := GetBranchDiff(runner, "/repo", "origin/main", DiffBaseMergeBase)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stat != " main.go | 2 +-\n" {
+		t.Errorf("stat = %q", stat)
+	}
+	if patch != "diff --git a/main.go b/main.go\n" {
+		t.Errorf("patch = %q", patch)
+	}
+}
+
+func TestGetBranchDiff_MergeBaseError(t *testing.T) {
+	runner := FakeCommandRunner{
+		Errors: map[string]error{
+			"/repo:[merge-base origin/main HEAD]": fmt.Errorf("no merge base"),
+		},
+	}
+
+	if _, _, err := GetBranchDiff(runner, "/repo", "origin/main", DiffBaseMergeBase); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestGetBranchDiff_RefStrategy(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[diff --stat origin/main]": " main.go | 2 +-\n",
+			"/repo:[diff origin/main]":        "diff --git a/main.go b/main.go\n",
+		},
+	}
+
+	stat, _, err := GetBranchDiff(runner, "/repo", "origin/main", DiffBaseRef)
+	if err != nil || stat != " main.go | 2 +-\n" {
+		t.Errorf("stat = %q, err = %v; want the diff against the ref tip without a merge-base lookup", stat, err)
+	}
+}
+
+func TestGetChanges(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[diff origin/main...HEAD --numstat]": "3\t1\tmain.go\n",
+			"/repo:[diff HEAD --numstat]":               "1\t0\tREADME.md\n",
+			"/repo:[diff origin/main --numstat]":        "3\t1\tmain.go\n1\t0\tREADME.md\n7\t0\tupstream.go\n",
+		},
+	}
+
+	mergeBase, err := GetChanges(runner, "/repo", "origin/main", DiffBaseMergeBase)
+	if err != nil || len(mergeBase) != 2 {
+		t.Errorf("merge-base changes = %+v, %v; want the branch's own files", mergeBase, err)
+	}
+	ref, err := GetChanges(runner, "/repo", "origin/main", DiffBaseRef)
+	if err != nil || len(ref) != 3 || ref[2].Path != "upstream.go" {
+		t.Errorf("ref changes = %+v, %v; want upstream files included", ref, err)
+	}
+}
+
+func TestParseDiffBase(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   DiffBase
+		wantOK bool
+	}{
+		{"", DiffBaseMergeBase, true},
+		{"merge-base", DiffBaseMergeBase, true},
+		{"ref", DiffBaseRef, true},
+		{"tip", DiffBaseMergeBase, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseDiffBase(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseDiffBase(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
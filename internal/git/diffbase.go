@@ -0,0 +1,40 @@
+package git
+
+import "strings"
+
+// DiffBase picks the commit a branch's changes are compared against.
+type DiffBase string
+
+const (
+	// DiffBaseMergeBase compares against the point where the branch forked
+	// from the base ref, so only the branch's own work shows up.
+	DiffBaseMergeBase DiffBase = "merge-base"
+	// DiffBaseRef compares against the base ref's current tip, so upstream
+	// commits made since the fork show up as reverted.
+	DiffBaseRef DiffBase = "ref"
+)
+
+// ParseDiffBase validates a `diff_base` value from the config. The empty
+// string selects DiffBaseMergeBase.
+func ParseDiffBase(s string) (DiffBase, bool) {
+	switch DiffBase(s) {
+	case "", DiffBaseMergeBase:
+		return DiffBaseMergeBase, true
+	case DiffBaseRef:
+		return DiffBaseRef, true
+	}
+	return DiffBaseMergeBase, false
+}
+
+// Resolve returns the commit that the working tree in dir is compared
+// against for the base ref base.
+func (d DiffBase) Resolve(runner CommandRunner, dir, base string) (string, error) {
+	if d == DiffBaseRef {
+		return base, nil
+	}
+	out, err := runner.Run(dir, "merge-base", base, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Sentinel errors classifying git failures. Use errors.Is to test for them;
+// errors from OSCommandRunner wrap at most one of these.
+var (
+	ErrGitNotFound      = errors.New("git executable not found")
+	ErrNotARepository   = errors.New("not a git repository")
+	ErrBranchExists     = errors.New("branch already exists")
+	ErrIndexLocked      = errors.New("git index is locked")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrNetwork          = errors.New("network error talking to remote")
+)
+
+// IsTransient reports whether err is likely to succeed if the command is
+// simply run again.
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrIndexLocked) || errors.Is(err, ErrNetwork)
+}
+
+// CommandError is returned by OSCommandRunner when git exits with an error.
+type CommandError struct {
+	Args   []string
+	Stderr string
+	Kind   error // one of the sentinel errors above, or nil
+	Err    error // underlying exec error
+}
+
+func (e *CommandError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("git %v failed: %s", e.Args, e.Stderr)
+	}
+	return fmt.Sprintf("git %v failed: %v", e.Args, e.Err)
+}
+
+func (e *CommandError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// newCommandError builds a CommandError, classifying it from the exec error
+// and the stderr git printed.
+func newCommandError(args []string, stderr string, err error) *CommandError {
+	return &CommandError{Args: args, Stderr: stderr, Kind: classify(stderr, err), Err: err}
+}
+
+func classify(stderr string, err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrGitNotFound
+	}
+	lower := strings.ToLower(stderr)
+	switch {
+	case strings.Contains(lower, "not a git repository"):
+		return ErrNotARepository
+	case strings.Contains(lower, "index.lock"):
+		return ErrIndexLocked
+	case isBranchExistsMessage(lower):
+		return ErrBranchExists
+	case strings.Contains(lower, "permission denied"):
+		return ErrPermissionDenied
+	case strings.Contains(lower, "could not resolve host"),
+		strings.Contains(lower, "connection timed out"),
+		strings.Contains(lower, "connection reset"),
+		strings.Contains(lower, "early eof"),
+		strings.Contains(lower, "remote end hung up"):
+		return ErrNetwork
+	default:
+		return nil
+	}
+}
+
+// isBranchExistsMessage reports whether the lowercased stderr is git's
+// "a branch named '<name>' already exists", as opposed to the other things
+// git says already exist, such as a worktree path or a remote.
+func isBranchExistsMessage(lower string) bool {
+	_, rest, ok := strings.Cut(lower, "a branch named '")
+	return ok && strings.Contains(rest, "' already exists")
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/retry"
+)
+
+func TestCommandError_Classification(t *testing.T) {
+	exitErr := fmt.Errorf("exit status 128")
+	tests := []struct {
+		name   string
+		stderr string
+		err    error
+		want   error
+	}{
+		{name: "not a repo", stderr: "fatal: not a git repository (or any of the parent directories): .git", err: exitErr, want: ErrNotARepository},
+		{name: "branch exists", stderr: "fatal: a branch named 'user/japan' already exists", err: exitErr, want: ErrBranchExists},
+		{name: "worktree path exists", stderr: "fatal: '/wt/repo/japan' already exists", err: exitErr, want: nil},
+		{name: "remote exists", stderr: "error: remote origin already exists.", err: exitErr, want: nil},
+		{name: "index locked", stderr: "fatal: Unable to create '/repo/.git/index.lock': File exists.", err: exitErr, want: ErrIndexLocked},
+		{name: "permission denied", stderr: "error: could not lock config file: Permission denied", err: exitErr, want: ErrPermissionDenied},
+		{name: "git missing", err: exec.ErrNotFound, want: ErrGitNotFound},
+		{name: "unclassified", stderr: "fatal: bad revision", err: exitErr, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newCommandError([]string{"status"}, tt.stderr, tt.err)
+			if err.Kind != tt.want {
+				t.Errorf("Kind = %v, want %v", err.Kind, tt.want)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected error to wrap the exec error")
+			}
+		})
+	}
+}
+
+func TestCommandError_Message(t *testing.T) {
+	err := newCommandError([]string{"status"}, "fatal: boom", fmt.Errorf("exit status 1"))
+	if got, want := err.Error(), "git [status] failed: fatal: boom"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestIsBranchExistsError_Typed(t *testing.T) {
+	err := fmt.Errorf("creating worktree: %w", newCommandError(nil, "fatal: a branch named 'x' already exists", fmt.Errorf("exit status 128")))
+	if !IsBranchExistsError(err) {
+		t.Error("expected wrapped CommandError to be detected")
+	}
+}
+
+// flakyRunner fails with err for the first failures calls, then succeeds.
+type flakyRunner struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (r *flakyRunner) Run(dir string, args ...string) (string, error) {
+	r.calls++
+	if r.calls <= r.failures {
+		return "", r.err
+	}
+	return "", nil
+}
+
+func TestFetchBranch_RetriesTransientFailures(t *testing.T) {
+	defer func(p retry.Policy) { retry.Default = p }(retry.Default)
+	retry.Default.Sleep = func(time.Duration) {}
+
+	runner := &flakyRunner{failures: 2, err: newCommandError(nil, "fatal: unable to access: Could not resolve host: github.com", fmt.Errorf("exit status 128"))}
+	if err := FetchBranch(runner, "/repo", "feat"); err != nil {
+		t.Fatalf("FetchBranch failed: %v", err)
+	}
+	if runner.calls != 3 {
+		t.Errorf("calls = %d, want 3", runner.calls)
+	}
+}
@@ -2,7 +2,13 @@ package git
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/retry"
 )
 
 // CommandRunner abstracts shell command execution for testability.
@@ -10,22 +16,48 @@ type CommandRunner interface {
 	Run(dir string, args ...string) (string, error)
 }
 
+// EnvRunner is a CommandRunner that can also add environment variables to a
+// single command, for git features driven by the environment such as
+// GIT_SEQUENCE_EDITOR.
+type EnvRunner interface {
+	CommandRunner
+	RunEnv(dir string, env []string, args ...string) (string, error)
+}
+
 // OSCommandRunner executes real git commands via os/exec.
 type OSCommandRunner struct{}
 
 func (r OSCommandRunner) Run(dir string, args ...string) (string, error) {
+	return r.RunEnv(dir, nil, args...)
+}
+
+// RunEnv runs git with env ("KEY=value") appended to the current environment.
+func (r OSCommandRunner) RunEnv(dir string, env []string, args ...string) (string, error) {
 	cmd := exec.Command("git", args...)
 	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	start := time.Now()
 	out, err := cmd.Output()
+	logging.For("git").Debug("ran git", "dir", dir, "args", strings.Join(args, " "), "took", time.Since(start), "err", err)
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("git %v failed: %s", args, string(exitErr.Stderr))
+			return "", newCommandError(args, string(exitErr.Stderr), err)
 		}
-		return "", fmt.Errorf("git %v failed: %w", args, err)
+		return "", newCommandError(args, "", err)
 	}
 	return string(out), nil
 }
 
+// runWithRetry runs a git command that is safe to repeat, retrying transient
+// failures with backoff.
+func runWithRetry(runner CommandRunner, dir string, args ...string) (string, error) {
+	return retry.Do(retry.Default, IsTransient, func() (string, error) {
+		return runner.Run(dir, args...)
+	})
+}
+
 // FakeCommandRunner is a test double that returns preset output.
 type FakeCommandRunner struct {
 	Outputs map[string]string
@@ -46,3 +78,8 @@ func (r FakeCommandRunner) Run(dir string, args ...string) (string, error) {
 	}
 	return "", fmt.Errorf("FakeCommandRunner: no output for key %q", key)
 }
+
+// RunEnv ignores env and behaves like Run.
+func (r FakeCommandRunner) RunEnv(dir string, env []string, args ...string) (string, error) {
+	return r.Run(dir, args...)
+}
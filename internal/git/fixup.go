@@ -0,0 +1,162 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNoFixupTarget is returned when no commit on the branch last touched the
+// lines changed in a file, e.g. a new file or lines that predate the branch.
+var ErrNoFixupTarget = errors.New("no commit on this branch touched the changed lines")
+
+// lineRange is an inclusive range of line numbers in HEAD's version of a file.
+type lineRange struct {
+	start, end int
+}
+
+// FixupTarget finds the branch commit that last touched the lines changed in
+// path, by blaming HEAD's version of every line the uncommitted changes
+// modify or delete. Pure insertions blame the line above them. When several
+// commits are involved the one owning the most lines wins, ties going to the
+// newest. Only commits since base are considered so the fixup can be
+// autosquashed. Returns the full hash and the subject of the target.
+func FixupTarget(runner CommandRunner, dir, base, path string) (string, string, error) {
+	diff, err := runner.Run(dir, "diff", "-U0", "HEAD", "--", path)
+	if err != nil {
+		return "", "", fmt.Errorf("diffing %s: %w", path, err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", "", fmt.Errorf("%s has no uncommitted changes", path)
+	}
+	ranges := changedLines(diff)
+	if len(ranges) == 0 {
+		return "", "", fmt.Errorf("%s: %w", path, ErrNoFixupTarget)
+	}
+
+	out, err := runner.Run(dir, "rev-list", base+"..HEAD")
+	if err != nil {
+		return "", "", fmt.Errorf("listing commits since %s: %w", base, err)
+	}
+	order := make(map[string]int) // newest first
+	for i, hash := range strings.Fields(out) {
+		order[hash] = i
+	}
+
+	args := []string{"blame", "--porcelain"}
+	for _, r := range ranges {
+		args = append(args, "-L", fmt.Sprintf("%d,%d", r.start, r.end))
+	}
+	args = append(args, "HEAD", "--", path)
+	out, err = runner.Run(dir, args...)
+	if err != nil {
+		return "", "", fmt.Errorf("blaming %s: %w", path, err)
+	}
+
+	best, bestLines := "", 0
+	for hash, lines := range blamedLines(out) {
+		i, onBranch := order[hash]
+		if !onBranch {
+			continue
+		}
+		if lines > bestLines || (lines == bestLines && i < order[best]) {
+			best, bestLines = hash, lines
+		}
+	}
+	if best == "" {
+		return "", "", fmt.Errorf("%s: %w", path, ErrNoFixupTarget)
+	}
+
+	subject, err := runner.Run(dir, "log", "-1", "--format=%s", best)
+	if err != nil {
+		return "", "", err
+	}
+	return best, strings.TrimSpace(subject), nil
+}
+
+// changedLines returns the ranges of HEAD's file touched by a `git diff -U0`
+// patch, from the old side of each hunk header "@@ -start[,count] ...".
+func changedLines(diff string) []lineRange {
+	var ranges []lineRange
+	for _, line := range strings.Split(diff, "\n") {
+		rest, ok := strings.CutPrefix(line, "@@ -")
+		if !ok {
+			continue
+		}
+		old, _, _ := strings.Cut(rest, " ")
+		startStr, countStr, hasCount := strings.Cut(old, ",")
+		start, err := strconv.Atoi(startStr)
+		if err != nil {
+			continue
+		}
+		count := 1
+		if hasCount {
+			if count, err = strconv.Atoi(countStr); err != nil {
+				continue
+			}
+		}
+		switch {
+		case count > 0:
+			ranges = append(ranges, lineRange{start, start + count - 1})
+		case start > 0:
+			// Lines inserted after line start: blame their neighbour.
+			ranges = append(ranges, lineRange{start, start})
+		}
+	}
+	return ranges
+}
+
+// blamedLines counts the lines attributed to each commit in
+// `git blame --porcelain` output. Every blamed line starts with a header
+// "<hash> <orig-line> <final-line>[ <group-size>]" followed by metadata and a
+// tab-prefixed copy of the line.
+func blamedLines(output string) map[string]int {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if strings.HasPrefix(line, "\t") || len(fields) < 3 || !isHash(fields[0]) {
+			continue
+		}
+		counts[fields[0]]++
+	}
+	return counts
+}
+
+func isHash(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// CommitFixup commits the current changes to path as a `fixup!` of target,
+// leaving other staged or unstaged changes alone.
+func CommitFixup(runner CommandRunner, dir, path, target string) error {
+	if _, err := runner.Run(dir, "commit", "--fixup="+target, "--", path); err != nil {
+		return fmt.Errorf("committing fixup: %w", err)
+	}
+	return nil
+}
+
+// AutosquashRebase folds the branch's fixup! and squash! commits into their
+// targets without moving the branch off its fork point with base. Like
+// RunRebase, a stop on conflicts wraps ErrRebaseConflict.
+func AutosquashRebase(runner CommandRunner, dir, base string) error {
+	envRunner, ok := runner.(EnvRunner)
+	if !ok {
+		return fmt.Errorf("autosquash needs a git runner that can set GIT_SEQUENCE_EDITOR")
+	}
+	out, err := runner.Run(dir, "merge-base", base, "HEAD")
+	if err != nil {
+		return fmt.Errorf("finding the fork point with %s: %w", base, err)
+	}
+	env := []string{"GIT_SEQUENCE_EDITOR=true", "GIT_EDITOR=true"}
+	_, err = envRunner.RunEnv(dir, env, "rebase", "-i", "--autosquash", "--autostash", strings.TrimSpace(out))
+	return rebaseResult(runner, dir, err)
+}
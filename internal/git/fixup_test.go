@@ -0,0 +1,98 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChangedLines(t *testing.T) {
+	diff := "diff --git a/f b/f\n" +
+		"@@ -3,2 +3,2 @@ func x\n" + // two lines modified
+		"@@ -7 +7 @@\n" + // one line, count omitted
+		"@@ -10,0 +11,3 @@\n" + // insertion after line 10
+		"@@ -0,0 +1 @@\n" // insertion at the top: nothing to blame
+
+	got := changedLines(diff)
+	want := []lineRange{{3, 4}, {7, 7}, {10, 10}}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("range %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestBlamedLines(t *testing.T) {
+	a := "1111111111111111111111111111111111111111"
+	b := "2222222222222222222222222222222222222222"
+	out := a + " 3 3 2\nauthor x\nsummary add thing\n\tline three\n" +
+		a + " 4 4\n\tline four\n" +
+		b + " 1 7 1\nboundary\n\tline seven\n"
+
+	got := blamedLines(out)
+	if got[a] != 2 || got[b] != 1 || len(got) != 2 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestFixupTarget_PicksBranchCommit(t *testing.T) {
+	dir, runner := gitRepo(t)
+	commitFile(t, runner, dir, "a.txt", "one\ntwo\nthree\n", "add a")
+	commitFile(t, runner, dir, "b.txt", "b\n", "add b")
+
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\nTWO\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	hash, subject, err := FixupTarget(runner, dir, "main", "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "add a" || len(hash) != 40 {
+		t.Errorf("target = %s %q, want add a", hash, subject)
+	}
+
+	if err := CommitFixup(runner, dir, "a.txt", hash); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := subjects(t, runner, dir), "add a\nadd b\nfixup! add a"; got != want {
+		t.Fatalf("commits =\n%s\nwant\n%s", got, want)
+	}
+
+	if err := AutosquashRebase(runner, dir, "main"); err != nil {
+		t.Fatalf("AutosquashRebase: %v", err)
+	}
+	if got, want := subjects(t, runner, dir), "add a\nadd b"; got != want {
+		t.Errorf("commits after autosquash =\n%s\nwant\n%s", got, want)
+	}
+	if out := mustGit(t, runner, dir, "show", "HEAD~1:a.txt"); out != "one\nTWO\nthree\n" {
+		t.Errorf("a.txt in add a = %q, want the fixup folded in", out)
+	}
+}
+
+func TestFixupTarget_LinesFromBeforeTheBranch(t *testing.T) {
+	dir, runner := gitRepo(t)
+	commitFile(t, runner, dir, "b.txt", "b\n", "add b")
+
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := FixupTarget(runner, dir, "main", "README")
+	if !errors.Is(err, ErrNoFixupTarget) {
+		t.Errorf("err = %v, want ErrNoFixupTarget", err)
+	}
+}
+
+func TestFixupTarget_NoChanges(t *testing.T) {
+	dir, runner := gitRepo(t)
+	commitFile(t, runner, dir, "b.txt", "b\n", "add b")
+
+	if _, _, err := FixupTarget(runner, dir, "main", "b.txt"); err == nil {
+		t.Error("expected an error for a file without uncommitted changes")
+	}
+}
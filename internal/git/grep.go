@@ -0,0 +1,47 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GrepMatch is a single line matched by `git grep`.
+type GrepMatch struct {
+	File string
+	Line int
+	Text string
+}
+
+// Grep runs `git grep` for pattern in the working tree at dir. Paths are
+// relative to the worktree root. A pattern with no matches is not an error.
+func Grep(runner CommandRunner, dir, pattern string) ([]GrepMatch, error) {
+	out, err := runner.Run(dir, "grep", "-z", "-n", "-I", "--no-color", "--full-name", "-e", pattern)
+	if err != nil {
+		// git grep exits 1 without output when nothing matches.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseGrep(out), nil
+}
+
+// parseGrep parses `git grep -z -n` output: "<file>\0<line>\0<text>" per line.
+func parseGrep(output string) []GrepMatch {
+	var matches []GrepMatch
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		matches = append(matches, GrepMatch{File: parts[0], Line: n, Text: parts[2]})
+	}
+	return matches
+}
@@ -0,0 +1,44 @@
+package git
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGrep(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/wt:[grep -z -n -I --no-color --full-name -e TODO]": "main.go\x0012\x00// TODO: fix\n" +
+				"internal/a.go\x003\x00x := 1 // TODO\n",
+		},
+	}
+
+	got, err := Grep(runner, "/wt", "TODO")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []GrepMatch{
+		{File: "main.go", Line: 12, Text: "// TODO: fix"},
+		{File: "internal/a.go", Line: 3, Text: "x := 1 // TODO"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d matches, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("match[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGrep_Error(t *testing.T) {
+	runner := FakeCommandRunner{
+		Errors: map[string]error{
+			"/wt:[grep -z -n -I --no-color --full-name -e (]": fmt.Errorf("fatal: unmatched ("),
+		},
+	}
+
+	if _, err := Grep(runner, "/wt", "("); err == nil {
+		t.Fatal("expected error")
+	}
+}
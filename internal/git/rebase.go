@@ -0,0 +1,188 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RebaseAction is what an interactive rebase does with one commit.
+type RebaseAction string
+
+const (
+	RebasePick   RebaseAction = "pick"
+	RebaseReword RebaseAction = "reword"
+	RebaseSquash RebaseAction = "squash"
+	RebaseFixup  RebaseAction = "fixup"
+	RebaseDrop   RebaseAction = "drop"
+)
+
+// RebaseStep is one line of an interactive rebase plan. Message is the new
+// subject of a reword step.
+type RebaseStep struct {
+	Action  RebaseAction
+	Hash    string
+	Subject string
+	Message string
+}
+
+// ErrRebaseConflict is returned when a rebase stops on conflicting changes.
+// The rebase is left in progress so it can be continued or aborted.
+var ErrRebaseConflict = errors.New("rebase stopped on conflicts")
+
+// RebasePlan lists the commits of the branch checked out in dir since it
+// forked from base, oldest first, all picked. The second value is the fork
+// point the plan is replayed onto.
+func RebasePlan(runner CommandRunner, dir, base string) ([]RebaseStep, string, error) {
+	out, err := runner.Run(dir, "merge-base", base, "HEAD")
+	if err != nil {
+		return nil, "", fmt.Errorf("finding the fork point with %s: %w", base, err)
+	}
+	onto := strings.TrimSpace(out)
+
+	out, err = runner.Run(dir, "log", "--reverse", "--no-merges", "--format=%H%x09%s", onto+"..HEAD")
+	if err != nil {
+		return nil, "", fmt.Errorf("listing commits since %s: %w", base, err)
+	}
+	return parseRebasePlan(out), onto, nil
+}
+
+func parseRebasePlan(output string) []RebaseStep {
+	var steps []RebaseStep
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		hash, subject, ok := strings.Cut(line, "\t")
+		if !ok || hash == "" {
+			continue
+		}
+		steps = append(steps, RebaseStep{Action: RebasePick, Hash: hash, Subject: subject})
+	}
+	return steps
+}
+
+// RebaseTodo renders steps as a git-rebase-todo file. A reword becomes a pick
+// followed by an amend that replaces the subject and keeps the body, so no
+// editor is needed.
+func RebaseTodo(steps []RebaseStep) (string, error) {
+	var b strings.Builder
+	kept := false
+	for _, s := range steps {
+		switch s.Action {
+		case RebasePick, RebaseDrop:
+			fmt.Fprintf(&b, "%s %s %s\n", s.Action, s.Hash, s.Subject)
+		case RebaseReword:
+			fmt.Fprintf(&b, "pick %s %s\n", s.Hash, s.Subject)
+			fmt.Fprintf(&b, "exec git commit --amend --only --allow-empty -m %s -m \"$(git log -1 --format=%%b)\"\n", shellQuote(s.Message))
+		case RebaseSquash, RebaseFixup:
+			if !kept {
+				return "", fmt.Errorf("cannot %s %.7s: there is no earlier commit to fold it into", s.Action, s.Hash)
+			}
+			fmt.Fprintf(&b, "%s %s %s\n", s.Action, s.Hash, s.Subject)
+		default:
+			return "", fmt.Errorf("unknown rebase action %q", s.Action)
+		}
+		if s.Action != RebaseDrop {
+			kept = true
+		}
+	}
+	return b.String(), nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// RunRebase replays steps onto onto with `git rebase -i`, supplying the todo
+// list through GIT_SEQUENCE_EDITOR and accepting squash messages unedited.
+// Uncommitted changes are stashed around the rebase. If it stops on
+// conflicts the error wraps ErrRebaseConflict.
+func RunRebase(runner CommandRunner, dir, onto string, steps []RebaseStep) error {
+	todo, err := RebaseTodo(steps)
+	if err != nil {
+		return err
+	}
+	envRunner, ok := runner.(EnvRunner)
+	if !ok {
+		return fmt.Errorf("interactive rebase needs a git runner that can set GIT_SEQUENCE_EDITOR")
+	}
+
+	tmp, err := os.MkdirTemp("", "yakumo-rebase-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	todoPath := filepath.Join(tmp, "git-rebase-todo")
+	if err := os.WriteFile(todoPath, []byte(todo), 0o600); err != nil {
+		return err
+	}
+
+	env := []string{"GIT_SEQUENCE_EDITOR=cp " + shellQuote(todoPath), "GIT_EDITOR=true"}
+	_, err = envRunner.RunEnv(dir, env, "rebase", "-i", "--autostash", onto)
+	return rebaseResult(runner, dir, err)
+}
+
+// ContinueRebase resumes a rebase stopped on conflicts once they are resolved
+// and staged.
+func ContinueRebase(runner CommandRunner, dir string) error {
+	envRunner, ok := runner.(EnvRunner)
+	if !ok {
+		return fmt.Errorf("continuing a rebase needs a git runner that can set GIT_EDITOR")
+	}
+	_, err := envRunner.RunEnv(dir, []string{"GIT_EDITOR=true"}, "rebase", "--continue")
+	return rebaseResult(runner, dir, err)
+}
+
+// AbortRebase gives up a rebase in progress, restoring the branch.
+func AbortRebase(runner CommandRunner, dir string) error {
+	if _, err := runner.Run(dir, "rebase", "--abort"); err != nil {
+		return fmt.Errorf("aborting rebase: %w", err)
+	}
+	return nil
+}
+
+// rebaseResult classifies the error of a rebase command: a stop with
+// unmerged paths becomes ErrRebaseConflict.
+func rebaseResult(runner CommandRunner, dir string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if files, ferr := ConflictedFiles(runner, dir); ferr == nil && len(files) > 0 {
+		return fmt.Errorf("%w: %w", ErrRebaseConflict, err)
+	}
+	return fmt.Errorf("rebase failed: %w", err)
+}
+
+// RebaseInProgress reports whether the worktree in dir is in the middle of a
+// rebase.
+func RebaseInProgress(runner CommandRunner, dir string) (bool, error) {
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		out, err := runner.Run(dir, "rev-parse", "--git-path", name)
+		if err != nil {
+			return false, err
+		}
+		path := strings.TrimSpace(out)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ConflictedFiles lists the paths with unresolved merge conflicts in dir.
+func ConflictedFiles(runner CommandRunner, dir string) ([]string, error) {
+	out, err := runner.Run(dir, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,206 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRebasePlan(t *testing.T) {
+	steps := parseRebasePlan("aaa\tfirst\nbbb\tsecond: with\ttab\n\n")
+
+	if len(steps) != 2 {
+		t.Fatalf("got %d steps, want 2", len(steps))
+	}
+	if steps[0] != (RebaseStep{Action: RebasePick, Hash: "aaa", Subject: "first"}) {
+		t.Errorf("steps[0] = %+v", steps[0])
+	}
+	if steps[1].Subject != "second: with\ttab" {
+		t.Errorf("steps[1].Subject = %q", steps[1].Subject)
+	}
+}
+
+func TestRebaseTodo(t *testing.T) {
+	todo, err := RebaseTodo([]RebaseStep{
+		{Action: RebaseDrop, Hash: "aaa", Subject: "wip"},
+		{Action: RebaseReword, Hash: "bbb", Subject: "add thing", Message: "Add Bob's thing"},
+		{Action: RebaseFixup, Hash: "ccc", Subject: "fix typo"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "drop aaa wip\n" +
+		"pick bbb add thing\n" +
+		"exec git commit --amend --only --allow-empty -m 'Add Bob'\\''s thing' -m \"$(git log -1 --format=%b)\"\n" +
+		"fixup ccc fix typo\n"
+	if todo != want {
+		t.Errorf("todo =\n%s\nwant\n%s", todo, want)
+	}
+}
+
+func TestRebaseTodo_SquashWithoutEarlierCommit(t *testing.T) {
+	_, err := RebaseTodo([]RebaseStep{
+		{Action: RebaseDrop, Hash: "aaa"},
+		{Action: RebaseSquash, Hash: "bbb"},
+	})
+	if err == nil {
+		t.Error("expected an error for a squash with nothing to fold into")
+	}
+}
+
+func TestRunRebase_RequiresEnvRunner(t *testing.T) {
+	err := RunRebase(&flakyRunner{}, "/repo", "abc", []RebaseStep{{Action: RebasePick, Hash: "aaa"}})
+	if err == nil || !strings.Contains(err.Error(), "GIT_SEQUENCE_EDITOR") {
+		t.Errorf("err = %v, want a GIT_SEQUENCE_EDITOR error", err)
+	}
+}
+
+// gitRepo creates a repository with an initial commit on main and returns
+// its path. Author and committer come from the environment so commits made
+// by git itself (rebase, amend) work without user config.
+func gitRepo(t *testing.T) (string, OSCommandRunner) {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	dir := t.TempDir()
+	runner := OSCommandRunner{}
+	mustGit(t, runner, dir, "init", "-q", "-b", "main")
+	commitFile(t, runner, dir, "README", "hello\n", "initial")
+	mustGit(t, runner, dir, "checkout", "-q", "-b", "feature")
+	return dir, runner
+}
+
+func mustGit(t *testing.T, runner OSCommandRunner, dir string, args ...string) string {
+	t.Helper()
+	out, err := runner.Run(dir, args...)
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return out
+}
+
+func commitFile(t *testing.T, runner OSCommandRunner, dir, name, content, subject string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mustGit(t, runner, dir, "add", name)
+	mustGit(t, runner, dir, "commit", "-q", "-m", subject)
+}
+
+func subjects(t *testing.T, runner OSCommandRunner, dir string) string {
+	t.Helper()
+	return strings.TrimSpace(mustGit(t, runner, dir, "log", "--reverse", "--format=%s", "main..HEAD"))
+}
+
+func TestRunRebase_ExecutesPlan(t *testing.T) {
+	dir, runner := gitRepo(t)
+	commitFile(t, runner, dir, "a.txt", "a\n", "add a")
+	commitFile(t, runner, dir, "b.txt", "b\n", "add b\n\nWhy b is needed.")
+	commitFile(t, runner, dir, "a.txt", "a fixed\n", "fix a")
+	commitFile(t, runner, dir, "wip.txt", "wip\n", "wip")
+
+	steps, onto, err := RebasePlan(runner, dir, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 4 {
+		t.Fatalf("got %d steps, want 4", len(steps))
+	}
+
+	// Reorder "fix a" under "add a", fold it in, reword "add b", drop "wip".
+	plan := []RebaseStep{steps[0], steps[2], steps[1], steps[3]}
+	plan[1].Action = RebaseFixup
+	plan[2].Action = RebaseReword
+	plan[2].Message = "Add b, properly"
+	plan[3].Action = RebaseDrop
+
+	if err := RunRebase(runner, dir, onto, plan); err != nil {
+		t.Fatalf("RunRebase: %v", err)
+	}
+
+	if got, want := subjects(t, runner, dir), "add a\nAdd b, properly"; got != want {
+		t.Errorf("commits =\n%s\nwant\n%s", got, want)
+	}
+	if got, want := strings.TrimSpace(mustGit(t, runner, dir, "log", "-1", "--format=%B")), "Add b, properly\n\nWhy b is needed."; got != want {
+		t.Errorf("reworded message = %q, want %q with the body kept", got, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(data) != "a fixed\n" {
+		t.Errorf("a.txt = %q, want the fixup applied", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "wip.txt")); !os.IsNotExist(err) {
+		t.Error("wip.txt should be gone with the dropped commit")
+	}
+}
+
+func TestRunRebase_ConflictThenAbort(t *testing.T) {
+	dir, runner := gitRepo(t)
+	commitFile(t, runner, dir, "a.txt", "one\n", "add a")
+	commitFile(t, runner, dir, "a.txt", "two\n", "change a")
+
+	steps, onto, err := RebasePlan(runner, dir, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Swapping the commits makes "change a" apply before the file exists.
+	err = RunRebase(runner, dir, onto, []RebaseStep{steps[1], steps[0]})
+	if !errors.Is(err, ErrRebaseConflict) {
+		t.Fatalf("err = %v, want ErrRebaseConflict", err)
+	}
+
+	if inProgress, err := RebaseInProgress(runner, dir); err != nil || !inProgress {
+		t.Errorf("RebaseInProgress = %v, %v; want true", inProgress, err)
+	}
+	files, err := ConflictedFiles(runner, dir)
+	if err != nil || len(files) != 1 || files[0] != "a.txt" {
+		t.Errorf("ConflictedFiles = %v, %v; want [a.txt]", files, err)
+	}
+
+	if err := AbortRebase(runner, dir); err != nil {
+		t.Fatal(err)
+	}
+	if inProgress, _ := RebaseInProgress(runner, dir); inProgress {
+		t.Error("rebase should no longer be in progress after abort")
+	}
+	if got, want := subjects(t, runner, dir), "add a\nchange a"; got != want {
+		t.Errorf("commits after abort =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestContinueRebase_AfterResolving(t *testing.T) {
+	dir, runner := gitRepo(t)
+	commitFile(t, runner, dir, "a.txt", "one\n", "add a")
+	commitFile(t, runner, dir, "a.txt", "two\n", "change a")
+
+	steps, onto, _ := RebasePlan(runner, dir, "main")
+	if err := RunRebase(runner, dir, onto, []RebaseStep{steps[1], steps[0]}); !errors.Is(err, ErrRebaseConflict) {
+		t.Fatalf("err = %v, want ErrRebaseConflict", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mustGit(t, runner, dir, "add", "a.txt")
+	err := ContinueRebase(runner, dir)
+	// "add a" now conflicts with the resolved file in turn.
+	if !errors.Is(err, ErrRebaseConflict) {
+		t.Fatalf("err = %v, want the second commit to conflict", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mustGit(t, runner, dir, "add", "a.txt")
+	if err := ContinueRebase(runner, dir); err != nil {
+		t.Fatalf("ContinueRebase: %v", err)
+	}
+	if inProgress, _ := RebaseInProgress(runner, dir); inProgress {
+		t.Error("rebase should be finished")
+	}
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// StatCache remembers the diff stats of worktrees so refreshes skip
+// `git diff` for those whose HEAD and index have not changed. Entries also
+// expire after a TTL, since the base ref can move without either changing.
+// A nil *StatCache caches nothing. It is safe for concurrent use.
+type StatCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]StatCacheEntry
+}
+
+// StatCacheEntry is the cached diff stat of one worktree, with what it was
+// computed from. Entries are kept between runs by state.DiffStats.
+type StatCacheEntry struct {
+	Head    string           `json:"head"`
+	Index   time.Time        `json:"index"` // mtime of the worktree's index
+	BaseRef string           `json:"base_ref"`
+	Status  model.StatusInfo `json:"status"`
+	At      time.Time        `json:"at"`
+}
+
+// NewStatCache returns a cache whose entries are reused for up to ttl,
+// starting from entries keyed by worktree path, as returned by Entries.
+func NewStatCache(ttl time.Duration, entries map[string]StatCacheEntry) *StatCache {
+	c := &StatCache{ttl: ttl, now: time.Now, entries: make(map[string]StatCacheEntry, len(entries))}
+	maps.Copy(c.entries, entries)
+	return c
+}
+
+// DiffStat returns GetBranchDiffStat for the worktree at worktreePath, which
+// has head checked out, reusing the last result while it is still valid. An
+// empty head, as for a bare repository, is never cached.
+func (c *StatCache) DiffStat(runner CommandRunner, worktreePath, head, baseRef string) (model.StatusInfo, error) {
+	if c == nil || head == "" {
+		return GetBranchDiffStat(runner, worktreePath, baseRef)
+	}
+	index := IndexModTime(worktreePath)
+
+	c.mu.Lock()
+	entry, ok := c.entries[worktreePath]
+	c.mu.Unlock()
+	if ok && entry.Head == head && entry.Index.Equal(index) && entry.BaseRef == baseRef && c.fresh(entry) {
+		return entry.Status, nil
+	}
+
+	status, err := GetBranchDiffStat(runner, worktreePath, baseRef)
+	if err != nil {
+		return status, err
+	}
+	c.mu.Lock()
+	c.entries[worktreePath] = StatCacheEntry{Head: head, Index: index, BaseRef: baseRef, Status: status, At: c.now()}
+	c.mu.Unlock()
+	return status, nil
+}
+
+func (c *StatCache) fresh(entry StatCacheEntry) bool {
+	return c.now().Sub(entry.At) < c.ttl
+}
+
+// Entries returns the entries that have not expired, keyed by worktree path.
+func (c *StatCache) Entries() map[string]StatCacheEntry {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make(map[string]StatCacheEntry, len(c.entries))
+	for path, entry := range c.entries {
+		if c.fresh(entry) {
+			entries[path] = entry
+		}
+	}
+	return entries
+}
+
+// Clear drops every entry, for when the base refs may have moved, such as
+// after a fetch.
+func (c *StatCache) Clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	clear(c.entries)
+	c.mu.Unlock()
+}
+
+// IndexModTime returns when the index of the worktree at worktreePath was
+// last written, or the zero time when it cannot be found. Linked worktrees
+// keep their index in the git directory their .git file points to.
+func IndexModTime(worktreePath string) time.Time {
+	gitDir := filepath.Join(worktreePath, ".git")
+	if info, err := os.Stat(gitDir); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(gitDir)
+		if err != nil {
+			return time.Time{}
+		}
+		dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+		if !ok {
+			return time.Time{}
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(worktreePath, dir)
+		}
+		gitDir = dir
+	}
+	info, err := os.Stat(filepath.Join(gitDir, "index"))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,145 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// countingRunner counts the commands run through it.
+type countingRunner struct {
+	CommandRunner
+	runs int
+}
+
+func (r *countingRunner) Run(dir string, args ...string) (string, error) {
+	r.runs++
+	return r.CommandRunner.Run(dir, args...)
+}
+
+func statRunner(dir, numstat string) *countingRunner {
+	return &countingRunner{CommandRunner: FakeCommandRunner{Outputs: map[string]string{
+		dir + ":[diff origin/main...HEAD --numstat]":                numstat,
+		dir + ":[rev-list --left-right --count origin/main...HEAD]": "0\t2\n",
+	}}}
+}
+
+func TestStatCache_ReusesUnchangedWorktrees(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	index := filepath.Join(dir, ".git", "index")
+	if err := os.WriteFile(index, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cache := NewStatCache(time.Minute, nil)
+	cache.now = func() time.Time { return now }
+	runner := statRunner(dir, "5\t1\tmain.go\n")
+
+	stat := func(head, baseRef string) {
+		t.Helper()
+		got, err := cache.DiffStat(runner, dir, head, baseRef)
+		if err != nil {
+			t.Fatalf("DiffStat error: %v", err)
+		}
+		if got.Insertions != 5 || got.Ahead != 2 {
+			t.Errorf("DiffStat = %+v, want +5 ↑2", got)
+		}
+	}
+
+	stat("abc", "origin/main")
+	stat("abc", "origin/main")
+	if runner.runs != 2 {
+		t.Errorf("git ran %d times, want the second call served from cache", runner.runs)
+	}
+
+	stat("def", "origin/main")
+	if runner.runs != 4 {
+		t.Errorf("git ran %d times, want a new HEAD to miss the cache", runner.runs)
+	}
+
+	later := now.Add(time.Hour)
+	if err := os.Chtimes(index, later, later); err != nil {
+		t.Fatal(err)
+	}
+	stat("def", "origin/main")
+	if runner.runs != 6 {
+		t.Errorf("git ran %d times, want a rewritten index to miss the cache", runner.runs)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if len(cache.Entries()) != 0 {
+		t.Error("Entries should leave out expired entries")
+	}
+	stat("def", "origin/main")
+	if runner.runs != 8 {
+		t.Errorf("git ran %d times, want an expired entry to miss the cache", runner.runs)
+	}
+
+	cache.Clear()
+	stat("def", "origin/main")
+	if runner.runs != 10 {
+		t.Errorf("git ran %d times, want Clear to drop the entry", runner.runs)
+	}
+}
+
+func TestStatCache_RestoredEntries(t *testing.T) {
+	dir := t.TempDir()
+	warm := NewStatCache(time.Minute, nil)
+	if _, err := warm.DiffStat(statRunner(dir, "1\t0\tx.go\n"), dir, "abc", "origin/main"); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := statRunner(dir, "1\t0\tx.go\n")
+	cache := NewStatCache(time.Minute, warm.Entries())
+	if got, _ := cache.DiffStat(runner, dir, "abc", "origin/main"); got.Insertions != 1 || runner.runs != 0 {
+		t.Errorf("DiffStat = %+v after %d git runs, want the restored entry", got, runner.runs)
+	}
+}
+
+func TestStatCache_NilAndBare(t *testing.T) {
+	dir := t.TempDir()
+	runner := statRunner(dir, "")
+	var nilCache *StatCache
+	if _, err := nilCache.DiffStat(runner, dir, "abc", "origin/main"); err != nil || runner.runs != 2 {
+		t.Errorf("a nil cache should run git, err = %v", err)
+	}
+	cache := NewStatCache(time.Minute, nil)
+	cache.DiffStat(runner, dir, "", "origin/main")
+	cache.DiffStat(runner, dir, "", "origin/main")
+	if runner.runs != 6 {
+		t.Errorf("git ran %d times, want worktrees without a HEAD never cached", runner.runs)
+	}
+}
+
+func TestIndexModTime_LinkedWorktree(t *testing.T) {
+	root := t.TempDir()
+	gitDir := filepath.Join(root, "repo", ".git", "worktrees", "feat")
+	wt := filepath.Join(root, "feat")
+	for _, d := range []string{gitDir, wt} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(wt, ".git"), []byte("gitdir: "+gitDir+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	index := filepath.Join(gitDir, "index")
+	if err := os.WriteFile(index, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(index, at, at); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := IndexModTime(wt); !got.Equal(at) {
+		t.Errorf("IndexModTime = %v, want %v", got, at)
+	}
+	if got := IndexModTime(filepath.Join(root, "missing")); !got.IsZero() {
+		t.Errorf("IndexModTime of a missing worktree = %v, want zero", got)
+	}
+}
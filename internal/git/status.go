@@ -3,7 +3,8 @@ package git
 import "github.com/mikanfactory/yakumo/internal/model"
 
 // GetBranchDiffStat runs `git diff <base>...HEAD --numstat` and returns
-// aggregated line insertion/deletion counts for the branch.
+// aggregated line insertion/deletion counts for the branch, along with the
+// commits it is ahead of and behind base.
 func GetBranchDiffStat(runner CommandRunner, worktreePath, baseRef string) (model.StatusInfo, error) {
 	entries, err := GetDiffNumstat(runner, worktreePath, baseRef)
 	if err != nil {
@@ -15,5 +16,25 @@ func GetBranchDiffStat(runner CommandRunner, worktreePath, baseRef string) (mode
 		info.Insertions += e.Additions
 		info.Deletions += e.Deletions
 	}
+
+	info.Ahead, info.Behind, err = GetAheadBehind(runner, worktreePath, baseRef)
+	if err != nil {
+		return model.StatusInfo{}, err
+	}
+	return info, nil
+}
+
+// GetUncommittedStat runs `git diff HEAD --numstat` and returns the line
+// insertion/deletion counts of the staged and unstaged changes in dir.
+func GetUncommittedStat(runner CommandRunner, dir string) (model.StatusInfo, error) {
+	out, err := runner.Run(dir, "diff", "HEAD", "--numstat")
+	if err != nil {
+		return model.StatusInfo{}, err
+	}
+	var info model.StatusInfo
+	for _, e := range parseDiffNumstat(out) {
+		info.Insertions += e.Additions
+		info.Deletions += e.Deletions
+	}
 	return info, nil
 }
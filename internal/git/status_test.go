@@ -49,7 +49,8 @@ func TestGetBranchDiffStat(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			runner := FakeCommandRunner{
 				Outputs: map[string]string{
-					"/repo:[diff origin/main...HEAD --numstat]": tt.output,
+					"/repo:[diff origin/main...HEAD --numstat]":                tt.output,
+					"/repo:[rev-list --left-right --count origin/main...HEAD]": "0\t0\n",
 				},
 			}
 
@@ -65,6 +66,24 @@ func TestGetBranchDiffStat(t *testing.T) {
 	}
 }
 
+func TestGetBranchDiffStat_AheadBehind(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[diff origin/main...HEAD --numstat]":                "10\t3\tmain.go\n",
+			"/repo:[rev-list --left-right --count origin/main...HEAD]": "1\t3\n",
+		},
+	}
+
+	got, err := GetBranchDiffStat(runner, "/repo", "origin/main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := model.StatusInfo{Insertions: 10, Deletions: 3, Ahead: 3, Behind: 1}
+	if got != want {
+		t.Errorf("GetBranchDiffStat = %+v, want %+v", got, want)
+	}
+}
+
 func TestGetBranchDiffStat_ErrorReturnsEmpty(t *testing.T) {
 	runner := FakeCommandRunner{
 		Errors: map[string]error{
@@ -77,3 +96,23 @@ func TestGetBranchDiffStat_ErrorReturnsEmpty(t *testing.T) {
 		t.Fatalf("expected error, got nil")
 	}
 }
+
+func TestGetUncommittedStat(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[diff HEAD --numstat]": "4\t1\tmain.go\n-\t-\tlogo.png\n2\t0\tREADME.md\n",
+		},
+	}
+
+	got, err := GetUncommittedStat(runner, "/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (model.StatusInfo{Insertions: 6, Deletions: 1}); got != want {
+		t.Errorf("GetUncommittedStat = %+v, want %+v", got, want)
+	}
+
+	if _, err := GetUncommittedStat(FakeCommandRunner{Errors: map[string]error{"/bare:[diff HEAD --numstat]": fmt.Errorf("not a work tree")}}, "/bare"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
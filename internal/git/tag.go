@@ -0,0 +1,41 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ListTags returns the tags reachable from HEAD in dir, highest version
+// first.
+func ListTags(runner CommandRunner, dir string) ([]string, error) {
+	out, err := runWithRetry(runner, dir, "tag", "--list", "--merged", "HEAD", "--sort=-v:refname")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// TagDate returns the commit date of the commit tag points at.
+func TagDate(runner CommandRunner, dir, tag string) (time.Time, error) {
+	out, err := runner.Run(dir, "log", "-1", "--format=%cI", tag)
+	if err != nil {
+		return time.Time{}, err
+	}
+	date, err := time.Parse(time.RFC3339, strings.TrimSpace(out))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing date of %s: %w", tag, err)
+	}
+	return date, nil
+}
+
+// CreateTag creates the annotated tag at HEAD and pushes it to origin.
+func CreateTag(runner CommandRunner, dir, tag, message string) error {
+	if _, err := runner.Run(dir, "tag", "-a", tag, "-m", message); err != nil {
+		return fmt.Errorf("creating tag %s: %w", tag, err)
+	}
+	if _, err := runner.Run(dir, "push", "origin", "refs/tags/"+tag); err != nil {
+		return fmt.Errorf("pushing tag %s: %w", tag, err)
+	}
+	return nil
+}
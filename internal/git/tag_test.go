@@ -0,0 +1,55 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+func TestListTags(t *testing.T) {
+	dir, runner := gitRepo(t)
+	mustGit(t, runner, dir, "tag", "v1.9.0", "main")
+	commitFile(t, runner, dir, "a.txt", "a\n", "add a")
+	mustGit(t, runner, dir, "tag", "v1.10.0")
+	mustGit(t, runner, dir, "checkout", "-q", "main")
+	commitFile(t, runner, dir, "b.txt", "b\n", "add b")
+	mustGit(t, runner, dir, "tag", "v2.0.0")
+	mustGit(t, runner, dir, "checkout", "-q", "feature")
+
+	tags, err := ListTags(runner, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || tags[0] != "v1.10.0" || tags[1] != "v1.9.0" {
+		t.Errorf("tags = %v, want the reachable ones by version", tags)
+	}
+}
+
+func TestTagDate(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{"/repo:[log -1 --format=%cI v1.0.0]": "2025-03-04T05:06:07+09:00\n"},
+	}
+
+	date, err := TagDate(runner, "/repo", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 3, 3, 20, 6, 7, 0, time.UTC); !date.Equal(want) {
+		t.Errorf("date = %v, want %v", date, want)
+	}
+}
+
+func TestCreateTag(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[tag -a v1.1.0 -m v1.1.0]":      "",
+			"/repo:[push origin refs/tags/v1.1.0]": "",
+		},
+	}
+
+	if err := CreateTag(runner, "/repo", "v1.1.0", "v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateTag(runner, "/repo", "v1.2.0", "v1.2.0"); err == nil {
+		t.Error("expected the failing tag command to surface")
+	}
+}
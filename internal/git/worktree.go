@@ -1,17 +1,30 @@
 package git
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/mikanfactory/yakumo/internal/model"
 )
 
 type worktreeEntry struct {
-	Path   string
-	Branch string
-	IsBare bool
+	Path     string
+	Head     string // the commit checked out
+	Branch   string
+	IsBare   bool
+	Locked   bool
+	Trashed  bool // locked with TrashLockReason
+	Prunable bool // its directory is gone; `git worktree prune` drops it
 }
 
+// TrashLockReason is the lock reason of worktrees moved to the trash. The
+// lock keeps `git worktree prune` from dropping them.
+const TrashLockReason = "archived by yakumo"
+
 // ListWorktrees runs `git worktree list --porcelain` and parses the output.
 func ListWorktrees(runner CommandRunner, repoPath string) ([]worktreeEntry, error) {
 	out, err := runner.Run(repoPath, "worktree", "list", "--porcelain")
@@ -74,6 +87,8 @@ func parseBlock(block string) worktreeEntry {
 		switch {
 		case strings.HasPrefix(line, "worktree "):
 			entry.Path = strings.TrimPrefix(line, "worktree ")
+		case strings.HasPrefix(line, "HEAD "):
+			entry.Head = strings.TrimPrefix(line, "HEAD ")
 		case strings.HasPrefix(line, "branch "):
 			ref := strings.TrimPrefix(line, "branch ")
 			entry.Branch = strings.TrimPrefix(ref, "refs/heads/")
@@ -81,6 +96,11 @@ func parseBlock(block string) worktreeEntry {
 			entry.Branch = "(detached)"
 		case line == "bare":
 			entry.IsBare = true
+		case line == "locked" || strings.HasPrefix(line, "locked "):
+			entry.Locked = true
+			entry.Trashed = line == "locked "+TrashLockReason
+		case line == "prunable" || strings.HasPrefix(line, "prunable "):
+			entry.Prunable = true
 		}
 	}
 
@@ -89,7 +109,10 @@ func parseBlock(block string) worktreeEntry {
 
 // IsBranchExistsError reports whether err indicates a branch name collision.
 func IsBranchExistsError(err error) bool {
-	return err != nil && strings.Contains(err.Error(), "already exists")
+	if errors.Is(err, ErrBranchExists) {
+		return true
+	}
+	return err != nil && isBranchExistsMessage(strings.ToLower(err.Error()))
 }
 
 // AddWorktree creates a new worktree with a new branch.
@@ -103,7 +126,13 @@ func AddWorktree(runner CommandRunner, repoPath, newPath, branch, baseRef string
 
 // FetchBranch fetches a specific branch from origin.
 func FetchBranch(runner CommandRunner, repoPath, branch string) error {
-	_, err := runner.Run(repoPath, "fetch", "origin", branch)
+	_, err := runWithRetry(runner, repoPath, "fetch", "origin", branch)
+	return err
+}
+
+// Fetch fetches every branch from origin, pruning deleted remote branches.
+func Fetch(runner CommandRunner, repoPath string) error {
+	_, err := runWithRetry(runner, repoPath, "fetch", "--prune", "origin")
 	return err
 }
 
@@ -113,27 +142,156 @@ func AddWorktreeFromBranch(runner CommandRunner, repoPath, newPath, branch strin
 	return err
 }
 
+// AddWorktreeTracking creates a new worktree with a local branch that tracks
+// remoteRef, e.g. "origin/fix-login".
+func AddWorktreeTracking(runner CommandRunner, repoPath, newPath, branch, remoteRef string) error {
+	_, err := runner.Run(repoPath, "worktree", "add", "--track", "-b", branch, newPath, remoteRef)
+	return err
+}
+
 // RenameBranch renames a branch in the given worktree directory.
 func RenameBranch(runner CommandRunner, worktreePath, oldBranch, newBranch string) error {
 	_, err := runner.Run(worktreePath, "branch", "-m", oldBranch, newBranch)
 	return err
 }
 
+// MoveWorktree moves a linked worktree to newPath, keeping git's
+// administrative files in sync. The main worktree cannot be moved.
+func MoveWorktree(runner CommandRunner, repoPath, oldPath, newPath string) error {
+	_, err := runner.Run(repoPath, "worktree", "move", oldPath, newPath)
+	return err
+}
+
+// TrashWorktree moves a linked worktree to trashPath and locks it with
+// TrashLockReason, keeping its uncommitted and untracked files so it can be
+// restored. Its HEAD is detached first so that the branch can be checked out
+// elsewhere while it is in the trash.
+func TrashWorktree(runner CommandRunner, repoPath, worktreePath, trashPath string) error {
+	if _, err := runner.Run(worktreePath, "checkout", "--detach"); err != nil {
+		return fmt.Errorf("detaching worktree HEAD: %w", err)
+	}
+	if err := MoveWorktree(runner, repoPath, worktreePath, trashPath); err != nil {
+		return fmt.Errorf("moving worktree to the trash: %w", err)
+	}
+	if _, err := runner.Run(repoPath, "worktree", "lock", "--reason", TrashLockReason, trashPath); err != nil {
+		return fmt.Errorf("locking trashed worktree: %w", err)
+	}
+	return nil
+}
+
+// RestoreWorktree moves a trashed worktree back to path and checks branch
+// out again. The worktree stays detached when branch has moved on or is
+// checked out elsewhere, as switching would mix its changes with others.
+func RestoreWorktree(runner CommandRunner, repoPath, trashPath, path, branch string) error {
+	if _, err := runner.Run(repoPath, "worktree", "unlock", trashPath); err != nil {
+		return fmt.Errorf("unlocking trashed worktree: %w", err)
+	}
+	if err := MoveWorktree(runner, repoPath, trashPath, path); err != nil {
+		return fmt.Errorf("moving worktree out of the trash: %w", err)
+	}
+	if branch == "" {
+		return nil
+	}
+	head, err := runner.Run(path, "rev-parse", "HEAD")
+	if err != nil {
+		return nil
+	}
+	tip, err := runner.Run(path, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	if err != nil || strings.TrimSpace(tip) != strings.TrimSpace(head) {
+		return nil
+	}
+	// Best effort: git refuses a branch checked out in another worktree.
+	_, _ = runner.Run(path, "switch", "--quiet", branch)
+	return nil
+}
+
+// PurgeWorktree deletes a trashed worktree and its changes for good.
+func PurgeWorktree(runner CommandRunner, repoPath, trashPath string) error {
+	// A second --force is needed to remove a locked worktree.
+	_, err := runner.Run(repoPath, "worktree", "remove", "--force", "--force", trashPath)
+	return err
+}
+
+// PruneWorktrees runs `git worktree prune` in repoPath and returns the paths
+// of the worktrees it dropped. Locked worktrees, such as trashed ones, are
+// kept even when their directory is missing.
+func PruneWorktrees(runner CommandRunner, repoPath string) ([]string, error) {
+	entries, err := ListWorktrees(runner, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, e := range entries {
+		if e.Prunable && !e.Locked {
+			stale = append(stale, e.Path)
+		}
+	}
+	if _, err := runner.Run(repoPath, "worktree", "prune"); err != nil {
+		return nil, err
+	}
+	return stale, nil
+}
+
+// CurrentBranch returns the branch checked out in dir via `git symbolic-ref`.
+func CurrentBranch(runner CommandRunner, dir string) (string, error) {
+	out, err := runner.Run(dir, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// MainRepoPath returns the path of the main working tree that dir belongs to,
+// so linked worktrees resolve to the repository they were created from.
+func MainRepoPath(runner CommandRunner, dir string) (string, error) {
+	out, err := runner.Run(dir, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(strings.TrimSpace(out)), nil
+}
+
+// RemoteURL returns the URL of the origin remote for dir.
+func RemoteURL(runner CommandRunner, dir string) (string, error) {
+	out, err := runner.Run(dir, "remote", "get-url", "origin")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
 // RemoveWorktree removes an existing worktree.
 func RemoveWorktree(runner CommandRunner, repoPath, worktreePath string) error {
 	_, err := runner.Run(repoPath, "worktree", "remove", worktreePath)
 	return err
 }
 
-// ToWorktreeInfo converts parsed entries to model.WorktreeInfo slices.
+// ToWorktreeInfo converts parsed entries to model.WorktreeInfo slices,
+// leaving out worktrees moved to the trash.
 func ToWorktreeInfo(entries []worktreeEntry) []model.WorktreeInfo {
-	infos := make([]model.WorktreeInfo, len(entries))
-	for i, e := range entries {
-		infos[i] = model.WorktreeInfo{
+	infos := make([]model.WorktreeInfo, 0, len(entries))
+	for _, e := range entries {
+		if e.Trashed {
+			continue
+		}
+		infos = append(infos, model.WorktreeInfo{
 			Path:   e.Path,
+			Head:   e.Head,
 			Branch: e.Branch,
 			IsBare: e.IsBare,
-		}
+		})
 	}
 	return infos
 }
+
+// WorktreeCreatedAt approximates when a linked worktree was added from the
+// modification time of its .git file, which git writes once on creation. The
+// main worktree (whose .git is a directory) and unreadable paths yield the
+// zero time.
+func WorktreeCreatedAt(worktreePath string) time.Time {
+	info, err := os.Lstat(filepath.Join(worktreePath, ".git"))
+	if err != nil || info.IsDir() {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -2,7 +2,10 @@ package git
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestListWorktrees(t *testing.T) {
@@ -204,6 +207,90 @@ func TestRenameBranch_Error(t *testing.T) {
 	}
 }
 
+func TestAddWorktreeTracking(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[worktree add --track -b fix-login /wt/fix-login origin/fix-login]": "",
+		},
+	}
+
+	if err := AddWorktreeTracking(runner, "/repo", "/wt/fix-login", "fix-login", "origin/fix-login"); err != nil {
+		t.Fatalf("AddWorktreeTracking failed: %v", err)
+	}
+}
+
+func TestMoveWorktree(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[worktree move /wt/south-korea /wt/fix-login]": "",
+		},
+	}
+
+	if err := MoveWorktree(runner, "/repo", "/wt/south-korea", "/wt/fix-login"); err != nil {
+		t.Fatalf("MoveWorktree failed: %v", err)
+	}
+}
+
+func TestCurrentBranch(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/tmp/worktree:[symbolic-ref --short HEAD]": "user/fix-login\n",
+		},
+	}
+
+	branch, err := CurrentBranch(runner, "/tmp/worktree")
+	if err != nil {
+		t.Fatalf("CurrentBranch failed: %v", err)
+	}
+	if branch != "user/fix-login" {
+		t.Errorf("branch = %q, want %q", branch, "user/fix-login")
+	}
+}
+
+func TestCurrentBranch_Detached(t *testing.T) {
+	runner := FakeCommandRunner{
+		Errors: map[string]error{
+			"/tmp/worktree:[symbolic-ref --short HEAD]": fmt.Errorf("not a symbolic ref"),
+		},
+	}
+
+	if _, err := CurrentBranch(runner, "/tmp/worktree"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestMainRepoPath(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/tmp/worktree:[rev-parse --path-format=absolute --git-common-dir]": "/repo/.git\n",
+		},
+	}
+
+	path, err := MainRepoPath(runner, "/tmp/worktree")
+	if err != nil {
+		t.Fatalf("MainRepoPath failed: %v", err)
+	}
+	if path != "/repo" {
+		t.Errorf("path = %q, want %q", path, "/repo")
+	}
+}
+
+func TestRemoteURL(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[remote get-url origin]": "git@bitbucket.org:team/repo.git\n",
+		},
+	}
+
+	remote, err := RemoteURL(runner, "/repo")
+	if err != nil {
+		t.Fatalf("RemoteURL failed: %v", err)
+	}
+	if remote != "git@bitbucket.org:team/repo.git" {
+		t.Errorf("remote = %q, want %q", remote, "git@bitbucket.org:team/repo.git")
+	}
+}
+
 func TestRemoveWorktree(t *testing.T) {
 	runner := FakeCommandRunner{
 		Outputs: map[string]string{
@@ -241,6 +328,18 @@ func TestFetchBranch(t *testing.T) {
 	}
 }
 
+func TestFetch(t *testing.T) {
+	runner := FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[fetch --prune origin]": "",
+		},
+	}
+
+	if err := Fetch(runner, "/repo"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+}
+
 func TestFetchBranch_Error(t *testing.T) {
 	runner := FakeCommandRunner{
 		Outputs: map[string]string{},
@@ -285,6 +384,7 @@ func TestIsBranchExistsError(t *testing.T) {
 		{"nil", nil, false},
 		{"branch exists", fmt.Errorf("git [...] failed: fatal: A branch named 'user/japan' already exists."), true},
 		{"other error", fmt.Errorf("network error"), false},
+		{"worktree path exists", fmt.Errorf("git [...] failed: fatal: '/wt/repo/japan' already exists"), false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -307,3 +407,109 @@ func assertWorktree(t *testing.T, got worktreeEntry, want WorktreeResult, label
 		t.Errorf("%s.IsBare = %v, want %v", label, got.IsBare, want.IsBare)
 	}
 }
+
+func TestWorktreeCreatedAt(t *testing.T) {
+	linked := t.TempDir()
+	gitFile := filepath.Join(linked, ".git")
+	if err := os.WriteFile(gitFile, []byte("gitdir: /repo/.git/worktrees/x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(gitFile, created, created); err != nil {
+		t.Fatal(err)
+	}
+	if got := WorktreeCreatedAt(linked); !got.Equal(created) {
+		t.Errorf("linked worktree: got %v, want %v", got, created)
+	}
+
+	main := t.TempDir()
+	if err := os.Mkdir(filepath.Join(main, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := WorktreeCreatedAt(main); !got.IsZero() {
+		t.Errorf("main worktree: got %v, want zero", got)
+	}
+
+	if got := WorktreeCreatedAt(filepath.Join(main, "missing")); !got.IsZero() {
+		t.Errorf("missing path: got %v, want zero", got)
+	}
+}
+
+func TestTrashWorktree_RealRepo(t *testing.T) {
+	dir, runner := gitRepo(t)
+	mustGit(t, runner, dir, "checkout", "-q", "main")
+	wt := filepath.Join(t.TempDir(), "wt")
+	trashed := filepath.Join(t.TempDir(), "trashed")
+	mustGit(t, runner, dir, "worktree", "add", "-q", wt, "feature")
+	if err := os.WriteFile(filepath.Join(wt, "wip.txt"), []byte("wip\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := TrashWorktree(runner, dir, wt, trashed); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ListWorktrees(runner, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || !entries[1].Trashed || len(ToWorktreeInfo(entries)) != 1 {
+		t.Errorf("entries = %+v, want the trashed worktree hidden", entries)
+	}
+	if _, err := os.Stat(filepath.Join(trashed, "wip.txt")); err != nil {
+		t.Errorf("untracked files should move to the trash: %v", err)
+	}
+
+	other := filepath.Join(t.TempDir(), "other")
+	mustGit(t, runner, dir, "worktree", "add", "-q", other, "feature")
+	mustGit(t, runner, dir, "worktree", "remove", other)
+
+	if err := RestoreWorktree(runner, dir, trashed, wt, "feature"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(wt, "wip.txt")); err != nil {
+		t.Errorf("restore should bring the files back: %v", err)
+	}
+	if branch, err := CurrentBranch(runner, wt); err != nil || branch != "feature" {
+		t.Errorf("restored branch = %q, %v; want feature", branch, err)
+	}
+
+	if err := TrashWorktree(runner, dir, wt, trashed); err != nil {
+		t.Fatal(err)
+	}
+	if err := PurgeWorktree(runner, dir, trashed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(trashed); !os.IsNotExist(err) {
+		t.Errorf("purge should delete the directory, stat err = %v", err)
+	}
+	if out := mustGit(t, runner, dir, "branch", "--list", "feature"); out == "" {
+		t.Error("purge should keep the branch")
+	}
+}
+
+func TestPruneWorktrees_RealRepo(t *testing.T) {
+	dir, runner := gitRepo(t)
+	mustGit(t, runner, dir, "checkout", "-q", "main")
+	gone := filepath.Join(t.TempDir(), "gone")
+	locked := filepath.Join(t.TempDir(), "locked")
+	mustGit(t, runner, dir, "worktree", "add", "-q", gone, "feature")
+	mustGit(t, runner, dir, "worktree", "add", "-q", "-b", "other", locked)
+	mustGit(t, runner, dir, "worktree", "lock", locked)
+	for _, path := range []string{gone, locked} {
+		if err := os.RemoveAll(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pruned, err := PruneWorktrees(runner, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 1 || filepath.Base(pruned[0]) != "gone" {
+		t.Errorf("pruned = %v, want only the unlocked worktree", pruned)
+	}
+	entries, _ := ListWorktrees(runner, dir)
+	if len(entries) != 2 || !entries[1].Locked {
+		t.Errorf("entries = %+v, want main and the locked worktree", entries)
+	}
+}
@@ -0,0 +1,770 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+)
+
+// DefaultAPIBaseURL is the GitHub REST API endpoint used by APIRunner.
+const DefaultAPIBaseURL = "https://api.github.com"
+
+// ResolveToken returns the GitHub token to use when the gh CLI is missing.
+// $GH_TOKEN and $GITHUB_TOKEN take precedence over the configured token.
+func ResolveToken(configToken string) string {
+	for _, key := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+		if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+			return v
+		}
+	}
+	return strings.TrimSpace(configToken)
+}
+
+// APIRunner implements Runner on top of the GitHub REST and GraphQL APIs so that yakumo
+// works on machines without the gh CLI. It understands the subset of gh
+// invocations made by this package (pr view, pr list, pr edit) and returns
+// output in the same JSON shape gh would print.
+type APIRunner struct {
+	Token   string
+	BaseURL string
+	Client  *http.Client
+	Git     git.CommandRunner
+}
+
+// NewAPIRunner creates an APIRunner for api.github.com authenticated with token.
+func NewAPIRunner(token string, gitRunner git.CommandRunner) *APIRunner {
+	return &APIRunner{
+		Token:   token,
+		BaseURL: DefaultAPIBaseURL,
+		Client:  &http.Client{Timeout: 15 * time.Second},
+		Git:     gitRunner,
+	}
+}
+
+func (r *APIRunner) Run(dir string, args ...string) (string, error) {
+	if len(args) < 2 {
+		return "", fmt.Errorf("github api: unsupported command %v", args)
+	}
+
+	if args[0] == "api" && args[1] == "graphql" {
+		return r.graphql(dir, args[2:])
+	}
+
+	target, flags := parseGhArgs(args[2:])
+
+	if args[0] == "issue" && args[1] == "view" {
+		return r.issueView(target)
+	}
+	if args[0] == "run" && args[1] == "list" {
+		return r.runList(dir, flags)
+	}
+	if args[0] != "pr" {
+		return "", fmt.Errorf("github api: unsupported command %v", args)
+	}
+
+	switch args[1] {
+	case "view":
+		return r.prView(dir, target)
+	case "list":
+		return r.prList(dir, flags)
+	case "edit":
+		return "", r.prEdit(dir, target, flags)
+	default:
+		return "", fmt.Errorf("github api: unsupported command %v", args)
+	}
+}
+
+// parseGhArgs splits gh arguments into an optional positional target and
+// "--flag value" pairs.
+func parseGhArgs(args []string) (string, map[string]string) {
+	var target string
+	flags := make(map[string]string)
+	for i := 0; i < len(args); i++ {
+		if name, ok := strings.CutPrefix(args[i], "--"); ok {
+			if i+1 < len(args) {
+				flags[name] = args[i+1]
+				i++
+			}
+			continue
+		}
+		if target == "" {
+			target = args[i]
+		}
+	}
+	return target, flags
+}
+
+// graphql runs a `gh api graphql` query given as -f/-F key=value fields.
+// As with gh, the query field is the query and the others are variables:
+// -F values are converted to numbers and booleans, and {owner} and {repo}
+// are filled in from the origin remote of dir.
+func (r *APIRunner) graphql(dir string, args []string) (string, error) {
+	var query string
+	variables := make(map[string]any)
+	for i := 0; i+1 < len(args); i += 2 {
+		flag, field := args[i], args[i+1]
+		if flag != "-f" && flag != "-F" {
+			return "", fmt.Errorf("github api: unsupported graphql flag %q", flag)
+		}
+		name, value, _ := strings.Cut(field, "=")
+		if name == "query" {
+			query = value
+			continue
+		}
+		if value == "{owner}" || value == "{repo}" {
+			owner, repo, err := r.repoSlug(dir)
+			if err != nil {
+				return "", err
+			}
+			value = map[string]string{"{owner}": owner, "{repo}": repo}[value]
+		}
+		variables[name] = value
+		if flag == "-F" {
+			variables[name] = typedField(value)
+		}
+	}
+
+	var resp json.RawMessage
+	if err := r.do(http.MethodPost, "/graphql", map[string]any{"query": query, "variables": variables}, &resp); err != nil {
+		return "", err
+	}
+	return string(resp), nil
+}
+
+// typedField converts a -F value as gh does: integers and booleans become
+// JSON numbers and booleans, anything else stays a string.
+func typedField(value string) any {
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return value
+}
+
+// apiPR is the gh-compatible JSON document printed by APIRunner.
+type apiPR struct {
+	PRView
+	HeadRefName string     `json:"headRefName"`
+	Author      UserNode   `json:"author"`
+	MergedAt    *time.Time `json:"mergedAt"`
+}
+
+type restUser struct {
+	Login string `json:"login"`
+}
+
+type restPull struct {
+	Number         int         `json:"number"`
+	Title          string      `json:"title"`
+	Body           string      `json:"body"`
+	State          string      `json:"state"`
+	User           restUser    `json:"user"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+	MergedAt       *time.Time  `json:"merged_at"`
+	HTMLURL        string      `json:"html_url"`
+	MergeableState string      `json:"mergeable_state"`
+	Labels         []LabelNode `json:"labels"`
+	Assignees      []restUser  `json:"assignees"`
+	Reviewers      []restUser  `json:"requested_reviewers"`
+	Teams          []struct {
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	} `json:"requested_teams"`
+	Head struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+type restReview struct {
+	User  restUser `json:"user"`
+	State string   `json:"state"`
+}
+
+type restCheckRuns struct {
+	CheckRuns []struct {
+		Name        string    `json:"name"`
+		Status      string    `json:"status"`
+		Conclusion  string    `json:"conclusion"`
+		StartedAt   time.Time `json:"started_at"`
+		CompletedAt time.Time `json:"completed_at"`
+	} `json:"check_runs"`
+}
+
+func (r *APIRunner) prView(dir, target string) (string, error) {
+	owner, repo, err := r.repoSlug(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var number int
+	if target != "" {
+		number, err = prNumberFromTarget(target)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		branch, err := git.CurrentBranch(r.Git, dir)
+		if err != nil {
+			return "", fmt.Errorf("resolving current branch: %w", err)
+		}
+		pulls, err := r.listPulls(owner, repo, pullQuery{head: branch, state: "all"}, 100)
+		if err != nil {
+			return "", err
+		}
+		if len(pulls) == 0 {
+			return "", fmt.Errorf("%w for branch %q", ErrNoPullRequest, branch)
+		}
+		number = pulls[0].Number
+	}
+
+	var pull restPull
+	if err := r.get(fmt.Sprintf("/repos/%s/%s/pulls/%d", owner, repo, number), &pull); err != nil {
+		return "", err
+	}
+
+	pr, err := r.buildPR(owner, repo, pull)
+	if err != nil {
+		return "", err
+	}
+	return marshalString(pr)
+}
+
+// issueView fetches an issue by URL. Unlike PRs the owner and repo come from
+// the URL, since issues are only looked up when creating a worktree.
+func (r *APIRunner) issueView(target string) (string, error) {
+	info, err := ParseGitHubURL(target)
+	if err != nil {
+		return "", err
+	}
+	if info.Type != URLTypeIssue {
+		return "", fmt.Errorf("not an issue URL: %s", target)
+	}
+
+	var issue struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := r.get(fmt.Sprintf("/repos/%s/%s/issues/%s", info.Owner, info.Repo, info.IssueNumber), &issue); err != nil {
+		return "", err
+	}
+	return marshalString(Issue{Number: issue.Number, Title: issue.Title, URL: issue.HTMLURL})
+}
+
+// prList lists PRs like `gh pr list`, honoring --head, --base, --state,
+// --limit, --json and a `merged:>=DATE` --search. Fields REST lacks are fetched for every PR at once with one
+// GraphQL query, and only when --json asks for them, so polling a
+// repository costs two requests however many PRs it has.
+func (r *APIRunner) prList(dir string, flags map[string]string) (string, error) {
+	owner, repo, err := r.repoSlug(dir)
+	if err != nil {
+		return "", err
+	}
+	query := pullQuery{head: flags["head"], base: flags["base"], state: flags["state"]}
+	if query.state == "" {
+		query.state = "open"
+	}
+	if s := flags["search"]; s != "" {
+		date, ok := strings.CutPrefix(s, "merged:>=")
+		if !ok {
+			return "", fmt.Errorf("github api: unsupported pr list --search %q", s)
+		}
+		if query.mergedSince, err = time.Parse("2006-01-02", date); err != nil {
+			return "", fmt.Errorf("github api: invalid merged date in --search %q", s)
+		}
+	}
+	limit := 30 // gh's default
+	if s := flags["limit"]; s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit <= 0 {
+			return "", fmt.Errorf("github api: invalid --limit %q", s)
+		}
+	}
+
+	pulls, err := r.listPulls(owner, repo, query, limit)
+	if err != nil {
+		return "", err
+	}
+
+	prs := make([]apiPR, 0, len(pulls))
+	for _, pull := range pulls {
+		prs = append(prs, restPR(pull))
+	}
+	if fields := strings.Split(flags["json"], ","); len(prs) > 0 && slices.ContainsFunc(fields, isGraphQLField) {
+		if err := r.fillFromGraphQL(owner, repo, prs, fields); err != nil {
+			return "", err
+		}
+	}
+	return marshalString(prs)
+}
+
+// graphQLFields are the `--json` fields of a PR that REST lacks or has
+// only per PR, with the GraphQL selection that fetches each.
+var graphQLFields = map[string]string{
+	"reviewDecision":    "reviewDecision",
+	"mergeStateStatus":  "mergeStateStatus",
+	"latestReviews":     "latestReviews(first: 100) { nodes { author { login } state } }",
+	"statusCheckRollup": "commits(last: 1) { nodes { commit { statusCheckRollup { contexts(first: 100) { nodes { ... on CheckRun { name status conclusion startedAt completedAt } ... on StatusContext { context state } } } } } } }",
+}
+
+func isGraphQLField(field string) bool {
+	_, ok := graphQLFields[field]
+	return ok
+}
+
+// graphQLPR is a pull request as graphQLFields select it.
+type graphQLPR struct {
+	ReviewDecision   string `json:"reviewDecision"`
+	MergeStateStatus string `json:"mergeStateStatus"`
+	LatestReviews    struct {
+		Nodes []ReviewNode `json:"nodes"`
+	} `json:"latestReviews"`
+	Commits struct {
+		Nodes []struct {
+			Commit struct {
+				StatusCheckRollup *struct {
+					Contexts struct {
+						Nodes []StatusCheckNode `json:"nodes"`
+					} `json:"contexts"`
+				} `json:"statusCheckRollup"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"commits"`
+}
+
+// fillFromGraphQL sets the requested fields of prs that REST lacks, with
+// one query aliasing each PR as pr<number>.
+func (r *APIRunner) fillFromGraphQL(owner, repo string, prs []apiPR, fields []string) error {
+	var selection []string
+	for _, field := range fields {
+		if sel, ok := graphQLFields[field]; ok {
+			selection = append(selection, sel)
+		}
+	}
+	var query strings.Builder
+	query.WriteString("query($owner: String!, $repo: String!) {\n  repository(owner: $owner, name: $repo) {\n")
+	for _, pr := range prs {
+		fmt.Fprintf(&query, "    pr%d: pullRequest(number: %d) { %s }\n", pr.Number, pr.Number, strings.Join(selection, " "))
+	}
+	query.WriteString("  }\n}")
+
+	var resp struct {
+		Data struct {
+			Repository map[string]graphQLPR `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	body := map[string]any{
+		"query":     query.String(),
+		"variables": map[string]any{"owner": owner, "repo": repo},
+	}
+	if err := r.do(http.MethodPost, "/graphql", body, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("github graphql: %s", resp.Errors[0].Message)
+	}
+	for i := range prs {
+		node, ok := resp.Data.Repository[fmt.Sprintf("pr%d", prs[i].Number)]
+		if !ok {
+			continue
+		}
+		prs[i].ReviewDecision = node.ReviewDecision
+		if node.MergeStateStatus != "" {
+			prs[i].MergeStateStatus = node.MergeStateStatus
+		}
+		prs[i].LatestReviews = node.LatestReviews.Nodes
+		for _, c := range node.Commits.Nodes {
+			if rollup := c.Commit.StatusCheckRollup; rollup != nil {
+				prs[i].StatusCheckRollup = rollup.Contexts.Nodes
+			}
+		}
+	}
+	return nil
+}
+
+func (r *APIRunner) prEdit(dir, target string, flags map[string]string) error {
+	owner, repo, err := r.repoSlug(dir)
+	if err != nil {
+		return err
+	}
+	number, err := r.editTarget(dir, owner, repo, target)
+	if err != nil {
+		return err
+	}
+
+	if label := flags["add-label"]; label != "" {
+		body := map[string][]string{"labels": {label}}
+		return r.do(http.MethodPost, fmt.Sprintf("/repos/%s/%s/issues/%d/labels", owner, repo, number), body, nil)
+	}
+	if label := flags["remove-label"]; label != "" {
+		return r.do(http.MethodDelete, fmt.Sprintf("/repos/%s/%s/issues/%d/labels/%s", owner, repo, number, url.PathEscape(label)), nil, nil)
+	}
+	return fmt.Errorf("github api: unsupported pr edit flags %v", flags)
+}
+
+// editTarget resolves the PR `gh pr edit` acts on: the one given by number
+// or URL, else the open PR for the current branch.
+func (r *APIRunner) editTarget(dir, owner, repo, target string) (int, error) {
+	if target != "" {
+		return prNumberFromTarget(target)
+	}
+	branch, err := git.CurrentBranch(r.Git, dir)
+	if err != nil {
+		return 0, fmt.Errorf("resolving current branch: %w", err)
+	}
+	pulls, err := r.listPulls(owner, repo, pullQuery{head: branch, state: "open"}, 100)
+	if err != nil {
+		return 0, err
+	}
+	if len(pulls) == 0 {
+		return 0, fmt.Errorf("%w for branch %q", ErrNoPullRequest, branch)
+	}
+	return pulls[0].Number, nil
+}
+
+// restRuns is the REST listing of a repository's Actions workflow runs.
+type restRuns struct {
+	WorkflowRuns []struct {
+		HeadSHA      string    `json:"head_sha"`
+		Name         string    `json:"name"`
+		Status       string    `json:"status"`
+		Conclusion   string    `json:"conclusion"`
+		RunStartedAt time.Time `json:"run_started_at"`
+		UpdatedAt    time.Time `json:"updated_at"`
+	} `json:"workflow_runs"`
+}
+
+// runList lists Actions runs like `gh run list --json`, honoring --branch
+// and --limit (at most 100).
+func (r *APIRunner) runList(dir string, flags map[string]string) (string, error) {
+	owner, repo, err := r.repoSlug(dir)
+	if err != nil {
+		return "", err
+	}
+	limit := 20 // gh's default
+	if s := flags["limit"]; s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit <= 0 {
+			return "", fmt.Errorf("github api: invalid --limit %q", s)
+		}
+	}
+	q := url.Values{}
+	q.Set("per_page", strconv.Itoa(min(limit, 100)))
+	if branch := flags["branch"]; branch != "" {
+		q.Set("branch", branch)
+	}
+	var resp restRuns
+	if err := r.get(fmt.Sprintf("/repos/%s/%s/actions/runs?%s", owner, repo, q.Encode()), &resp); err != nil {
+		return "", err
+	}
+
+	runs := make([]workflowRun, 0, len(resp.WorkflowRuns))
+	for _, run := range resp.WorkflowRuns {
+		runs = append(runs, workflowRun{
+			HeadSha:      run.HeadSHA,
+			WorkflowName: run.Name,
+			Status:       run.Status,
+			Conclusion:   run.Conclusion,
+			StartedAt:    run.RunStartedAt,
+			UpdatedAt:    run.UpdatedAt,
+		})
+	}
+	return marshalString(runs)
+}
+
+// pullQuery selects the pulls listPulls returns. REST has no merged state,
+// so "merged" lists closed pulls and keeps those with a merge time.
+type pullQuery struct {
+	head, base, state string
+	mergedSince       time.Time // drops pulls merged before, when set
+}
+
+// listPulls lists up to limit pulls, newest first, paging past REST's
+// 100 per request.
+func (r *APIRunner) listPulls(owner, repo string, query pullQuery, limit int) ([]restPull, error) {
+	perPage := min(limit, 100)
+	merged := query.state == "merged" || !query.mergedSince.IsZero()
+	q := url.Values{}
+	q.Set("state", query.state)
+	if query.state == "merged" {
+		q.Set("state", "closed")
+	}
+	q.Set("per_page", strconv.Itoa(perPage))
+	if query.head != "" {
+		q.Set("head", owner+":"+query.head)
+	}
+	if query.base != "" {
+		q.Set("base", query.base)
+	}
+	if !query.mergedSince.IsZero() {
+		// A pull is updated when it is merged, so once pulls sorted by
+		// update time fall before mergedSince, none later can match.
+		q.Set("sort", "updated")
+		q.Set("direction", "desc")
+	}
+	var pulls []restPull
+	for page := 1; len(pulls) < limit; page++ {
+		if page > 1 {
+			q.Set("page", strconv.Itoa(page))
+		}
+		var batch []restPull
+		if err := r.get(fmt.Sprintf("/repos/%s/%s/pulls?%s", owner, repo, q.Encode()), &batch); err != nil {
+			return nil, err
+		}
+		for _, pull := range batch {
+			if merged && (pull.MergedAt == nil || pull.MergedAt.Before(query.mergedSince)) {
+				continue
+			}
+			pulls = append(pulls, pull)
+		}
+		if len(batch) < perPage {
+			break
+		}
+		if last := batch[len(batch)-1]; !query.mergedSince.IsZero() && last.UpdatedAt.Before(query.mergedSince) {
+			break
+		}
+	}
+	if len(pulls) > limit {
+		pulls = pulls[:limit]
+	}
+	return pulls, nil
+}
+
+// restPR converts the fields of pull that REST lists to a gh-compatible PR
+// document.
+func restPR(pull restPull) apiPR {
+	pr := apiPR{
+		PRView: PRView{
+			Number:           pull.Number,
+			BaseRefName:      pull.Base.Ref,
+			Title:            pull.Title,
+			Body:             pull.Body,
+			State:            restPRState(pull),
+			MergeStateStatus: strings.ToUpper(pull.MergeableState),
+			URL:              pull.HTMLURL,
+			Labels:           pull.Labels,
+		},
+		HeadRefName: pull.Head.Ref,
+		Author:      UserNode{Login: pull.User.Login},
+		MergedAt:    pull.MergedAt,
+	}
+	for _, a := range pull.Assignees {
+		pr.Assignees = append(pr.Assignees, UserNode{Login: a.Login})
+	}
+	for _, u := range pull.Reviewers {
+		pr.ReviewRequests = append(pr.ReviewRequests, ReviewRequest{Login: u.Login})
+	}
+	for _, t := range pull.Teams {
+		pr.ReviewRequests = append(pr.ReviewRequests, ReviewRequest{Name: t.Name, Slug: t.Slug})
+	}
+	return pr
+}
+
+// buildPR fills a gh-compatible PR document, fetching reviews and check runs
+// with separate REST calls. Comments are left out, as for gh they are paged
+// with FetchPRComments.
+func (r *APIRunner) buildPR(owner, repo string, pull restPull) (apiPR, error) {
+	pr := restPR(pull)
+
+	// REST has no review decision and a lossy mergeable_state; ask GraphQL
+	// for both, keeping the REST values if the query fails.
+	if status, err := r.prStatus(owner, repo, pull.Number); err == nil {
+		pr.ReviewDecision = status.ReviewDecision
+		if status.MergeStateStatus != "" {
+			pr.MergeStateStatus = status.MergeStateStatus
+		}
+	}
+
+	var reviews []restReview
+	if err := r.get(fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews?per_page=100", owner, repo, pull.Number), &reviews); err != nil {
+		return apiPR{}, err
+	}
+	pr.LatestReviews = latestReviews(reviews)
+
+	if pull.Head.SHA != "" {
+		var runs restCheckRuns
+		if err := r.get(fmt.Sprintf("/repos/%s/%s/commits/%s/check-runs?per_page=100", owner, repo, pull.Head.SHA), &runs); err != nil {
+			return apiPR{}, err
+		}
+		for _, run := range runs.CheckRuns {
+			pr.StatusCheckRollup = append(pr.StatusCheckRollup, StatusCheckNode{
+				Name:        run.Name,
+				Status:      strings.ToUpper(run.Status),
+				Conclusion:  strings.ToUpper(run.Conclusion),
+				StartedAt:   run.StartedAt,
+				CompletedAt: run.CompletedAt,
+			})
+		}
+	}
+
+	return pr, nil
+}
+
+const prStatusQuery = `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) { reviewDecision mergeStateStatus }
+  }
+}`
+
+type prStatus struct {
+	ReviewDecision   string `json:"reviewDecision"`
+	MergeStateStatus string `json:"mergeStateStatus"`
+}
+
+// prStatus fetches the review decision and merge state via GraphQL.
+func (r *APIRunner) prStatus(owner, repo string, number int) (prStatus, error) {
+	var resp struct {
+		Data struct {
+			Repository struct {
+				PullRequest prStatus `json:"pullRequest"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	body := map[string]any{
+		"query":     prStatusQuery,
+		"variables": map[string]any{"owner": owner, "repo": repo, "number": number},
+	}
+	if err := r.do(http.MethodPost, "/graphql", body, &resp); err != nil {
+		return prStatus{}, err
+	}
+	if len(resp.Errors) > 0 {
+		return prStatus{}, fmt.Errorf("github graphql: %s", resp.Errors[0].Message)
+	}
+	return resp.Data.Repository.PullRequest, nil
+}
+
+// latestReviews keeps the most recent review per author, preserving the order
+// in which authors first appear.
+func latestReviews(reviews []restReview) []ReviewNode {
+	index := make(map[string]int)
+	var latest []ReviewNode
+	for _, rv := range reviews {
+		node := ReviewNode{Author: CommentAuthor{Login: rv.User.Login}, State: rv.State}
+		if i, ok := index[rv.User.Login]; ok {
+			latest[i] = node
+			continue
+		}
+		index[rv.User.Login] = len(latest)
+		latest = append(latest, node)
+	}
+	return latest
+}
+
+func restPRState(pull restPull) string {
+	if pull.MergedAt != nil {
+		return "MERGED"
+	}
+	return strings.ToUpper(pull.State)
+}
+
+// prNumberFromTarget accepts a PR number or a PR URL, as gh does.
+func prNumberFromTarget(target string) (int, error) {
+	if n, err := strconv.Atoi(target); err == nil {
+		return n, nil
+	}
+	info, err := ParseGitHubURL(target)
+	if err != nil {
+		return 0, err
+	}
+	if info.Type != URLTypePR {
+		return 0, fmt.Errorf("not a pull request URL: %s", target)
+	}
+	return strconv.Atoi(info.PRNumber)
+}
+
+// repoSlug resolves owner and repo name from the origin remote of dir.
+func (r *APIRunner) repoSlug(dir string) (string, string, error) {
+	out, err := r.Git.Run(dir, "remote", "get-url", "origin")
+	if err != nil {
+		return "", "", fmt.Errorf("resolving origin remote: %w", err)
+	}
+	return ParseRemoteURL(strings.TrimSpace(out))
+}
+
+func (r *APIRunner) get(path string, v any) error {
+	return r.do(http.MethodGet, path, nil, v)
+}
+
+func (r *APIRunner) do(method, path string, body, v any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		reqBody = strings.NewReader(string(data))
+	}
+
+	baseURL := r.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultAPIBaseURL
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(baseURL, "/")+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if r.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("github api %s %s failed: %w: %w", method, path, ErrNetwork, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading github api response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Status: resp.Status, Body: strings.TrimSpace(string(data))}
+	}
+
+	if v == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing github api response: %w", err)
+	}
+	return nil
+}
+
+func marshalString(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("encoding output: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,384 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+)
+
+func newAPITestRunner(t *testing.T, routes map[string]string) (*APIRunner, *[]string) {
+	t.Helper()
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.RequestURI()
+		requests = append(requests, key)
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization = %q, want bearer token", got)
+		}
+		body, ok := routes[key]
+		if !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	gitRunner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[remote get-url origin]":     "git@github.com:owner/repo.git\n",
+			"/repo:[symbolic-ref --short HEAD]": "feat\n",
+		},
+	}
+
+	runner := NewAPIRunner("test-token", gitRunner)
+	runner.BaseURL = server.URL
+	return runner, &requests
+}
+
+var apiPullJSON = `{
+	"number": 7, "title": "feat: api", "body": "desc", "state": "open",
+	"html_url": "https://github.com/owner/repo/pull/7", "mergeable_state": "clean",
+	"labels": [{"name": "bug"}], "assignees": [{"login": "alice"}],
+	"requested_reviewers": [{"login": "bob"}], "requested_teams": [{"name": "Core", "slug": "core"}],
+	"head": {"ref": "feat", "sha": "abc123"}, "base": {"ref": "main"}
+}`
+
+func apiDetailRoutes() map[string]string {
+	return map[string]string{
+		"GET /repos/owner/repo/pulls/7/reviews?per_page=100":           `[{"user": {"login": "carol"}, "state": "COMMENTED"}, {"user": {"login": "carol"}, "state": "APPROVED"}]`,
+		"GET /repos/owner/repo/commits/abc123/check-runs?per_page=100": `{"check_runs": [{"name": "CI", "status": "completed", "conclusion": "success"}]}`,
+	}
+}
+
+func TestAPIRunner_FetchPR(t *testing.T) {
+	routes := apiDetailRoutes()
+	routes["GET /repos/owner/repo/pulls?head=owner%3Afeat&per_page=100&state=all"] = "[" + apiPullJSON + "]"
+	routes["GET /repos/owner/repo/pulls/7"] = apiPullJSON
+	runner, _ := newAPITestRunner(t, routes)
+
+	pr, err := FetchPR(runner, "/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if pr.Title != "feat: api" || pr.State != "OPEN" || pr.MergeStateStatus != "CLEAN" {
+		t.Errorf("unexpected PR header: %+v", pr)
+	}
+	if got := strings.Join(pr.LabelNames(), ","); got != "bug" {
+		t.Errorf("labels = %q, want bug", got)
+	}
+	if got := strings.Join(pr.ReviewerNames(), ","); got != "carol,bob,core" {
+		t.Errorf("reviewers = %q, want carol,bob,core", got)
+	}
+	if len(pr.LatestReviews) != 1 || pr.LatestReviews[0].State != "APPROVED" {
+		t.Errorf("latest reviews = %+v, want single APPROVED", pr.LatestReviews)
+	}
+	if len(pr.StatusCheckRollup) != 1 || !pr.StatusCheckRollup[0].Passed() {
+		t.Errorf("checks = %+v, want one passing check", pr.StatusCheckRollup)
+	}
+	if len(pr.Comments) != 0 {
+		t.Errorf("comments = %+v, want none: they are paged with FetchPRComments", pr.Comments)
+	}
+}
+
+func TestAPIRunner_FetchPR_GraphQLStatus(t *testing.T) {
+	routes := apiDetailRoutes()
+	routes["GET /repos/owner/repo/pulls?head=owner%3Afeat&per_page=100&state=all"] = "[" + apiPullJSON + "]"
+	routes["GET /repos/owner/repo/pulls/7"] = apiPullJSON
+	routes["POST /graphql"] = `{"data": {"repository": {"pullRequest": {"reviewDecision": "CHANGES_REQUESTED", "mergeStateStatus": "BLOCKED"}}}}`
+	runner, _ := newAPITestRunner(t, routes)
+
+	pr, err := FetchPR(runner, "/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := MapMergeStateStatus(pr.MergeStateStatus, pr.ReviewDecision); got != "Changes requested" {
+		t.Errorf("merge status = %q, want Changes requested", got)
+	}
+}
+
+func TestAPIRunner_FetchPRComments(t *testing.T) {
+	var body struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		io.WriteString(w, `{"data": {"repository": {"pullRequest": {"comments": {
+			"totalCount": 3,
+			"pageInfo": {"hasPreviousPage": true, "startCursor": "Y3Vyc29y"},
+			"nodes": [{"author": {"login": "erin"}, "body": "ship it", "createdAt": "2025-01-02T00:00:00Z"}]
+		}}}}}`)
+	}))
+	t.Cleanup(server.Close)
+	runner := NewAPIRunner("test-token", git.FakeCommandRunner{Outputs: map[string]string{
+		"/repo:[remote get-url origin]": "git@github.com:owner/repo.git\n",
+	}})
+	runner.BaseURL = server.URL
+
+	page, err := FetchPRComments(runner, "/repo", 7, 1, "b2xkZXI=")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.TotalCount != 3 || page.Before != "Y3Vyc29y" || len(page.Comments) != 1 || page.Comments[0].Author.Login != "erin" {
+		t.Errorf("page = %+v, want erin's comment with a cursor to 2 older ones", page)
+	}
+	want := map[string]any{"owner": "owner", "repo": "repo", "number": float64(7), "limit": float64(1), "before": "b2xkZXI="}
+	for name, value := range want {
+		if body.Variables[name] != value {
+			t.Errorf("variable %s = %#v, want %#v", name, body.Variables[name], value)
+		}
+	}
+	if !strings.Contains(body.Query, "comments(last: $limit, before: $before)") {
+		t.Errorf("query = %q", body.Query)
+	}
+}
+
+func TestAPIRunner_FetchPR_NoPR(t *testing.T) {
+	routes := map[string]string{
+		"GET /repos/owner/repo/pulls?head=owner%3Afeat&per_page=100&state=all": "[]",
+	}
+	runner, _ := newAPITestRunner(t, routes)
+
+	_, err := FetchPR(runner, "/repo")
+	if !errors.Is(err, ErrNoPullRequest) {
+		t.Fatalf("expected ErrNoPullRequest, got %v", err)
+	}
+}
+
+func TestAPIRunner_FetchPRBranch(t *testing.T) {
+	routes := apiDetailRoutes()
+	routes["GET /repos/owner/repo/pulls/7"] = apiPullJSON
+	runner, _ := newAPITestRunner(t, routes)
+
+	branch, err := FetchPRBranch(runner, "/repo", "https://github.com/owner/repo/pull/7")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if branch != "feat" {
+		t.Errorf("branch = %q, want feat", branch)
+	}
+}
+
+func TestAPIRunner_FetchIssue(t *testing.T) {
+	runner, _ := newAPITestRunner(t, map[string]string{
+		"GET /repos/owner/repo/issues/12": `{"number": 12, "title": "Login redirect loops", "html_url": "https://github.com/owner/repo/issues/12"}`,
+	})
+
+	issue, err := FetchIssue(runner, "/repo", "https://github.com/owner/repo/issues/12")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.Number != 12 || issue.Title != "Login redirect loops" || issue.URL != "https://github.com/owner/repo/issues/12" {
+		t.Errorf("issue = %+v", issue)
+	}
+}
+
+var apiGraphQLPullsJSON = `{"data": {"repository": {"pr7": {
+	"reviewDecision": "APPROVED", "mergeStateStatus": "CLEAN",
+	"latestReviews": {"nodes": [{"author": {"login": "carol"}, "state": "APPROVED"}]},
+	"commits": {"nodes": [{"commit": {"statusCheckRollup": {"contexts": {"nodes": [
+		{"name": "CI", "status": "COMPLETED", "conclusion": "SUCCESS"}
+	]}}}}]}
+}}}}`
+
+func TestAPIRunner_FetchPRs(t *testing.T) {
+	runner, requests := newAPITestRunner(t, map[string]string{
+		"GET /repos/owner/repo/pulls?head=owner%3Afeat&per_page=30&state=open": "[" + apiPullJSON + "]",
+		"POST /graphql": apiGraphQLPullsJSON,
+	})
+
+	prs, err := FetchPRs(runner, "/repo", "feat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs) != 1 || prs[0].Number != 7 || prs[0].BaseRefName != "main" {
+		t.Fatalf("prs = %+v, want #7 -> main", prs)
+	}
+	if prs[0].ReviewDecision != "APPROVED" || len(prs[0].LatestReviews) != 1 || len(prs[0].StatusCheckRollup) != 1 {
+		t.Errorf("pr = %+v, want the GraphQL fields filled", prs[0])
+	}
+	if len(*requests) != 2 {
+		t.Errorf("requests = %v, want one list and one GraphQL query", *requests)
+	}
+}
+
+func TestAPIRunner_FetchOpenPRs(t *testing.T) {
+	pulls := make([]string, 0, 60)
+	for i := range 60 {
+		pulls = append(pulls, fmt.Sprintf(`{"number": %d, "state": "open", "head": {"ref": "b%d"}, "base": {"ref": "main"}}`, i+1, i+1))
+	}
+	runner, requests := newAPITestRunner(t, map[string]string{
+		"GET /repos/owner/repo/pulls?per_page=100&state=open": "[" + strings.Join(pulls, ",") + "]",
+		"POST /graphql": `{"data": {"repository": {}}}`,
+	})
+
+	prs, err := FetchOpenPRs(runner, "/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs) != 60 || prs[59].HeadRefName != "b60" {
+		t.Errorf("got %d PRs, want all 60", len(prs))
+	}
+	if len(*requests) != 2 {
+		t.Errorf("requests = %v, want one list and one GraphQL query", *requests)
+	}
+}
+
+func TestAPIRunner_FetchPRStates(t *testing.T) {
+	page := func(from, n int) string {
+		pulls := make([]string, 0, n)
+		for i := from; i < from+n; i++ {
+			pulls = append(pulls, fmt.Sprintf(`{"number": %d, "state": "closed", "merged_at": "2026-01-02T03:04:05Z", "head": {"ref": "b%d"}}`, i, i))
+		}
+		return "[" + strings.Join(pulls, ",") + "]"
+	}
+	runner, requests := newAPITestRunner(t, map[string]string{
+		"GET /repos/owner/repo/pulls?per_page=100&state=all":        page(1, 100),
+		"GET /repos/owner/repo/pulls?page=2&per_page=100&state=all": page(101, 20),
+	})
+
+	prs, err := FetchPRStates(runner, "/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs) != 120 || prs[119].HeadRefName != "b120" || prs[0].State != PRStateMerged {
+		t.Errorf("got %d PRs, want 120 merged ones", len(prs))
+	}
+	if len(*requests) != 2 {
+		t.Errorf("requests = %v, want two pages and no per-PR calls", *requests)
+	}
+}
+
+func TestAPIRunner_FetchMergedPRs(t *testing.T) {
+	since := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	runner, _ := newAPITestRunner(t, map[string]string{
+		"GET /repos/owner/repo/pulls?base=main&direction=desc&per_page=100&sort=updated&state=closed": `[
+			{"number": 14, "title": "Add search", "user": {"login": "bob"}, "merged_at": "2025-03-06T00:00:00Z", "updated_at": "2025-03-06T00:00:00Z"},
+			{"number": 13, "title": "Closed unmerged", "merged_at": null, "updated_at": "2025-03-05T12:00:00Z"},
+			{"number": 12, "title": "Fix login", "user": {"login": "alice"}, "merged_at": "2025-03-05T00:00:00Z", "updated_at": "2025-03-05T00:00:00Z"},
+			{"number": 11, "title": "Shipped in the last release", "merged_at": "2025-03-04T08:00:00Z", "updated_at": "2025-03-04T08:00:00Z"},
+			{"number": 9, "title": "Older", "merged_at": "2025-02-01T00:00:00Z", "updated_at": "2025-03-04T09:00:00Z"}
+		]`,
+	})
+
+	prs, err := FetchMergedPRs(runner, "/repo", "main", since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs) != 2 || prs[0].Number != 12 || prs[1].Number != 14 {
+		t.Fatalf("prs = %+v, want #12 then #14", prs)
+	}
+	if prs[0].Author.Login != "alice" {
+		t.Errorf("author = %q, want alice", prs[0].Author.Login)
+	}
+}
+
+func TestAPIRunner_PRListUnsupportedSearch(t *testing.T) {
+	runner, _ := newAPITestRunner(t, map[string]string{})
+
+	if _, err := runner.Run("/repo", "pr", "list", "--search", "author:bob"); err == nil {
+		t.Fatal("expected an error for an unsupported search")
+	}
+}
+
+func TestAPIRunner_FetchBranchChecks(t *testing.T) {
+	runner, _ := newAPITestRunner(t, map[string]string{
+		"GET /repos/owner/repo/actions/runs?branch=main&per_page=50": `{"workflow_runs": [
+			{"head_sha": "new", "name": "CI", "status": "completed", "conclusion": "failure", "run_started_at": "2025-03-05T00:00:00Z", "updated_at": "2025-03-05T00:05:00Z"},
+			{"head_sha": "old", "name": "CI", "status": "completed", "conclusion": "success"}
+		]}`,
+	})
+
+	checks, err := FetchBranchChecks(runner, "/repo", "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(checks) != 1 || checks[0].Name != "CI" || !checks[0].Failed() || checks[0].DurationString() != "5m" {
+		t.Errorf("checks = %+v, want the failed CI run of the newest commit", checks)
+	}
+}
+
+func TestAPIRunner_AddAndRemoveLabel(t *testing.T) {
+	routes := map[string]string{
+		"GET /repos/owner/repo/pulls?head=owner%3Afeat&per_page=100&state=open": "[" + apiPullJSON + "]",
+		"POST /repos/owner/repo/issues/7/labels":                                "[]",
+		"DELETE /repos/owner/repo/issues/7/labels/needs%20review":               "",
+	}
+	runner, requests := newAPITestRunner(t, routes)
+
+	if err := AddLabel(runner, "/repo", 0, "bug"); err != nil {
+		t.Fatalf("AddLabel error: %v", err)
+	}
+	if err := RemoveLabel(runner, "/repo", 0, "needs review"); err != nil {
+		t.Fatalf("RemoveLabel error: %v", err)
+	}
+
+	joined := strings.Join(*requests, "\n")
+	if !strings.Contains(joined, "POST /repos/owner/repo/issues/7/labels") {
+		t.Errorf("expected POST labels request, got:\n%s", joined)
+	}
+	if !strings.Contains(joined, "DELETE /repos/owner/repo/issues/7/labels/needs%20review") {
+		t.Errorf("expected DELETE label request, got:\n%s", joined)
+	}
+}
+
+func TestAPIRunner_AddLabelToPRNumber(t *testing.T) {
+	runner, requests := newAPITestRunner(t, map[string]string{
+		"POST /repos/owner/repo/issues/9/labels": "[]",
+	})
+
+	if err := AddLabel(runner, "/repo", 9, "bug"); err != nil {
+		t.Fatalf("AddLabel error: %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("requests = %v, want only the label edit of #9", *requests)
+	}
+}
+
+func TestAPIRunner_HTTPError(t *testing.T) {
+	runner, _ := newAPITestRunner(t, map[string]string{})
+
+	_, err := runner.Run("/repo", "pr", "view", "7", "--json", "headRefName")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 404 {
+		t.Fatalf("expected 404 APIError, got %v", err)
+	}
+}
+
+func TestAPIRunner_UnsupportedCommand(t *testing.T) {
+	runner := NewAPIRunner("t", git.FakeCommandRunner{})
+
+	if _, err := runner.Run("/repo", "issue", "list"); err == nil {
+		t.Fatal("expected error for unsupported command")
+	}
+}
+
+func TestResolveToken(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	if got := ResolveToken(" from-config "); got != "from-config" {
+		t.Errorf("ResolveToken() = %q, want from-config", got)
+	}
+
+	t.Setenv("GITHUB_TOKEN", "from-github-token")
+	if got := ResolveToken("from-config"); got != "from-github-token" {
+		t.Errorf("ResolveToken() = %q, want from-github-token", got)
+	}
+
+	t.Setenv("GH_TOKEN", "from-gh-token")
+	if got := ResolveToken("from-config"); got != "from-gh-token" {
+		t.Errorf("ResolveToken() = %q, want from-gh-token", got)
+	}
+}
@@ -0,0 +1,81 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// CommentPage is one page of a PR's comments, fetched newest page first so
+// the latest discussion shows without loading hundreds of older comments.
+type CommentPage struct {
+	Comments   []CommentNode // oldest first within the page
+	TotalCount int           // comments on the PR, loaded or not
+	// Before is the cursor of the next, older page, or "" when this page
+	// reaches the first comment.
+	Before string
+}
+
+const prCommentsQuery = `query($owner: String!, $repo: String!, $number: Int!, $limit: Int!, $before: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      comments(last: $limit, before: $before) {
+        totalCount
+        pageInfo { hasPreviousPage startCursor }
+        nodes { author { login } body createdAt }
+      }
+    }
+  }
+}`
+
+// FetchPRComments returns up to limit comments of PR number that come
+// before the cursor before, or the newest ones when before is "".
+func FetchPRComments(runner Runner, dir string, number, limit int, before string) (CommentPage, error) {
+	args := []string{"api", "graphql",
+		"-f", "query=" + prCommentsQuery,
+		"-F", "owner={owner}",
+		"-F", "repo={repo}",
+		"-F", "number=" + strconv.Itoa(number),
+		"-F", "limit=" + strconv.Itoa(limit),
+	}
+	if before != "" {
+		args = append(args, "-f", "before="+before)
+	}
+	out, err := runWithRetry(runner, dir, args...)
+	if err != nil {
+		return CommentPage{}, err
+	}
+
+	var resp struct {
+		Data struct {
+			Repository struct {
+				PullRequest struct {
+					Comments struct {
+						TotalCount int `json:"totalCount"`
+						PageInfo   struct {
+							HasPreviousPage bool   `json:"hasPreviousPage"`
+							StartCursor     string `json:"startCursor"`
+						} `json:"pageInfo"`
+						Nodes []CommentNode `json:"nodes"`
+					} `json:"comments"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		return CommentPage{}, fmt.Errorf("failed to parse PR comments: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return CommentPage{}, fmt.Errorf("github graphql: %s", resp.Errors[0].Message)
+	}
+
+	comments := resp.Data.Repository.PullRequest.Comments
+	page := CommentPage{Comments: comments.Nodes, TotalCount: comments.TotalCount}
+	if comments.PageInfo.HasPreviousPage {
+		page.Before = comments.PageInfo.StartCursor
+	}
+	return page, nil
+}
@@ -0,0 +1,110 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// Sentinel errors classifying GitHub failures from both the gh CLI and the
+// REST API runner. Use errors.Is to test for them.
+var (
+	ErrGhNotFound       = errors.New("gh executable not found")
+	ErrNotAuthenticated = errors.New("not authenticated with GitHub")
+	ErrNoPullRequest    = errors.New("no pull request found")
+	ErrRateLimited      = errors.New("GitHub API rate limit exceeded")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrNetwork          = errors.New("network error talking to GitHub")
+)
+
+// IsTransient reports whether err is likely to succeed if the request is
+// simply made again.
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrNetwork)
+}
+
+// CommandError is returned by OSRunner when gh exits with an error.
+type CommandError struct {
+	Args   []string
+	Stderr string
+	Kind   error // one of the sentinel errors above, or nil
+	Err    error // underlying exec error
+}
+
+func (e *CommandError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("gh %v failed: %s", e.Args, e.Stderr)
+	}
+	return fmt.Sprintf("gh %v failed: %v", e.Args, e.Err)
+}
+
+func (e *CommandError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+func newCommandError(args []string, stderr string, err error) *CommandError {
+	kind := classify(stderr)
+	if errors.Is(err, exec.ErrNotFound) {
+		kind = ErrGhNotFound
+	}
+	return &CommandError{Args: args, Stderr: stderr, Kind: kind, Err: err}
+}
+
+// APIError is returned by APIRunner for non-2xx responses.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("github api %s %s failed: %s: %s", e.Method, e.Path, e.Status, e.Body)
+}
+
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrNotAuthenticated
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusForbidden:
+		if strings.Contains(strings.ToLower(e.Body), "rate limit") {
+			return ErrRateLimited
+		}
+		return ErrPermissionDenied
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrNetwork
+	default:
+		return nil
+	}
+}
+
+func classify(stderr string) error {
+	lower := strings.ToLower(stderr)
+	switch {
+	case strings.Contains(lower, "gh auth login"), strings.Contains(lower, "authentication"), strings.Contains(lower, "http 401"):
+		return ErrNotAuthenticated
+	case strings.Contains(lower, "no pull requests found"), strings.Contains(lower, "no open pull request"):
+		return ErrNoPullRequest
+	case strings.Contains(lower, "rate limit"):
+		return ErrRateLimited
+	case strings.Contains(lower, "http 403"), strings.Contains(lower, "resource not accessible"), strings.Contains(lower, "permission denied"):
+		return ErrPermissionDenied
+	case strings.Contains(lower, "connection reset"),
+		strings.Contains(lower, "timeout"),
+		strings.Contains(lower, "error connecting to"),
+		strings.Contains(lower, "http 502"),
+		strings.Contains(lower, "http 503"),
+		strings.Contains(lower, "http 504"):
+		return ErrNetwork
+	default:
+		return nil
+	}
+}
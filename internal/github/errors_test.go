@@ -0,0 +1,81 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/retry"
+)
+
+func TestCommandError_Classification(t *testing.T) {
+	exitErr := fmt.Errorf("exit status 1")
+	tests := []struct {
+		name   string
+		stderr string
+		err    error
+		want   error
+	}{
+		{name: "not logged in", stderr: "To get started with GitHub CLI, please run:  gh auth login", err: exitErr, want: ErrNotAuthenticated},
+		{name: "no PR", stderr: `no pull requests found for branch "feat"`, err: exitErr, want: ErrNoPullRequest},
+		{name: "rate limited", stderr: "API rate limit exceeded for user ID 1", err: exitErr, want: ErrRateLimited},
+		{name: "forbidden", stderr: "GraphQL: Resource not accessible by integration", err: exitErr, want: ErrPermissionDenied},
+		{name: "gh missing", err: exec.ErrNotFound, want: ErrGhNotFound},
+		{name: "unclassified", stderr: "something else", err: exitErr, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newCommandError([]string{"pr", "view"}, tt.stderr, tt.err)
+			if err.Kind != tt.want {
+				t.Errorf("Kind = %v, want %v", err.Kind, tt.want)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIError_Unwrap(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, want: ErrNotAuthenticated},
+		{name: "too many requests", status: http.StatusTooManyRequests, want: ErrRateLimited},
+		{name: "forbidden rate limit", status: http.StatusForbidden, body: `{"message":"API rate limit exceeded"}`, want: ErrRateLimited},
+		{name: "forbidden", status: http.StatusForbidden, body: `{"message":"Resource not accessible"}`, want: ErrPermissionDenied},
+		{name: "not found", status: http.StatusNotFound, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := &APIError{Method: "GET", Path: "/x", StatusCode: tt.status, Body: tt.body}
+			if got := err.Unwrap(); got != tt.want {
+				t.Errorf("Unwrap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchPR_RetriesNetworkErrors(t *testing.T) {
+	defer func(p retry.Policy) { retry.Default = p }(retry.Default)
+	retry.Default.Sleep = func(time.Duration) {}
+
+	key := fmt.Sprintf("/repo:[pr view --json %s]", prViewFields)
+	runner := &FakeRunner{
+		Errors: map[string]error{key: newCommandError(nil, "Post https://api.github.com/graphql: connection reset by peer", fmt.Errorf("exit status 1"))},
+	}
+
+	_, err := FetchPR(runner, "/repo")
+	if !errors.Is(err, ErrNetwork) {
+		t.Errorf("err = %v, want ErrNetwork", err)
+	}
+	if len(runner.Calls) != 3 {
+		t.Errorf("calls = %d, want 3", len(runner.Calls))
+	}
+}
@@ -0,0 +1,31 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Issue is the subset of `gh issue view --json` used to seed a worktree.
+type Issue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+}
+
+// FetchIssue uses the gh CLI to look up the issue at issueURL.
+func FetchIssue(runner Runner, dir, issueURL string) (Issue, error) {
+	out, err := runWithRetry(runner, dir, "issue", "view", issueURL, "--json", "number,title,url")
+	if err != nil {
+		return Issue{}, fmt.Errorf("fetching issue: %w", err)
+	}
+
+	var issue Issue
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &issue); err != nil {
+		return Issue{}, fmt.Errorf("parsing issue response: %w", err)
+	}
+	if issue.Title == "" {
+		return Issue{}, fmt.Errorf("issue has no title")
+	}
+	return issue, nil
+}
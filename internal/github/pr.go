@@ -3,12 +3,17 @@ package github
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // PRView represents the JSON output from `gh pr view --json ...`.
 type PRView struct {
+	Number            int               `json:"number"`
+	BaseRefName       string            `json:"baseRefName"`
+	HeadRefName       string            `json:"headRefName"`
 	Title             string            `json:"title"`
 	Body              string            `json:"body"`
 	State             string            `json:"state"`
@@ -17,6 +22,34 @@ type PRView struct {
 	StatusCheckRollup []StatusCheckNode `json:"statusCheckRollup"`
 	Comments          []CommentNode     `json:"comments"`
 	URL               string            `json:"url"`
+	Labels            []LabelNode       `json:"labels"`
+	Assignees         []UserNode        `json:"assignees"`
+	ReviewRequests    []ReviewRequest   `json:"reviewRequests"`
+	LatestReviews     []ReviewNode      `json:"latestReviews"`
+}
+
+// LabelNode represents a label attached to a PR.
+type LabelNode struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// UserNode represents a GitHub user such as an assignee.
+type UserNode struct {
+	Login string `json:"login"`
+}
+
+// ReviewRequest represents a pending review request for a user or a team.
+type ReviewRequest struct {
+	Login string `json:"login"` // set for users
+	Name  string `json:"name"`  // set for teams
+	Slug  string `json:"slug"`  // set for teams
+}
+
+// ReviewNode represents the latest review submitted by a reviewer.
+type ReviewNode struct {
+	Author CommentAuthor `json:"author"`
+	State  string        `json:"state"`
 }
 
 // StatusCheckNode represents a CI check or status check.
@@ -55,6 +88,24 @@ func (s StatusCheckNode) Passed() bool {
 	return s.Conclusion == "SUCCESS" || s.State == "SUCCESS"
 }
 
+// Failed returns whether the check finished unsuccessfully.
+func (s StatusCheckNode) Failed() bool {
+	switch s.Conclusion {
+	case "FAILURE", "TIMED_OUT", "CANCELLED", "ACTION_REQUIRED", "STARTUP_FAILURE":
+		return true
+	}
+	return s.State == "FAILURE" || s.State == "ERROR"
+}
+
+// Pending returns whether the check has not finished yet. Check runs report
+// this through Status, commit statuses through State.
+func (s StatusCheckNode) Pending() bool {
+	if s.Status != "" {
+		return s.Status != "COMPLETED"
+	}
+	return s.State == "PENDING" || s.State == "EXPECTED"
+}
+
 // DurationString returns a human-readable duration string.
 func (s StatusCheckNode) DurationString() string {
 	if s.CompletedAt.IsZero() || s.StartedAt.IsZero() {
@@ -80,11 +131,65 @@ func (c CommentNode) Preview(maxLen int) string {
 	return body
 }
 
-var prViewFields = "title,body,state,mergeStateStatus,reviewDecision,statusCheckRollup,comments,url"
+// DisplayName returns the user login or team slug for a review request.
+func (r ReviewRequest) DisplayName() string {
+	if r.Login != "" {
+		return r.Login
+	}
+	if r.Slug != "" {
+		return r.Slug
+	}
+	return r.Name
+}
+
+// LabelNames returns the names of all labels on the PR.
+func (p PRView) LabelNames() []string {
+	names := make([]string, 0, len(p.Labels))
+	for _, l := range p.Labels {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// AssigneeLogins returns the logins of all assignees on the PR.
+func (p PRView) AssigneeLogins() []string {
+	logins := make([]string, 0, len(p.Assignees))
+	for _, a := range p.Assignees {
+		logins = append(logins, a.Login)
+	}
+	return logins
+}
+
+// ReviewerNames returns everyone who reviewed or was requested to review,
+// in order: submitted reviews first, then pending requests. Duplicates are dropped.
+func (p PRView) ReviewerNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, r := range p.LatestReviews {
+		if r.Author.Login == "" || seen[r.Author.Login] {
+			continue
+		}
+		seen[r.Author.Login] = true
+		names = append(names, r.Author.Login)
+	}
+	for _, r := range p.ReviewRequests {
+		name := r.DisplayName()
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// prViewFields leaves out comments: a PR with hundreds of them would make
+// every poll slow, so they are paged separately with FetchPRComments.
+var prViewFields = "number,title,body,state,mergeStateStatus,reviewDecision,statusCheckRollup,url,labels,assignees,reviewRequests,latestReviews"
 
 // FetchPR runs `gh pr view` and returns the parsed PR data.
 func FetchPR(runner Runner, dir string) (PRView, error) {
-	out, err := runner.Run(dir, "pr", "view", "--json", prViewFields)
+	out, err := runWithRetry(runner, dir, "pr", "view", "--json", prViewFields)
 	if err != nil {
 		return PRView{}, err
 	}
@@ -97,6 +202,155 @@ func FetchPR(runner Runner, dir string) (PRView, error) {
 	return pr, nil
 }
 
+var prListFields = "baseRefName," + prViewFields
+
+// FetchPRs lists all open PRs whose head is branch. Several PRs can share a
+// head branch, e.g. stacked PRs or the same branch proposed to different bases.
+func FetchPRs(runner Runner, dir, branch string) ([]PRView, error) {
+	out, err := runWithRetry(runner, dir, "pr", "list", "--head", branch, "--state", "open", "--json", prListFields)
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []PRView
+	if err := json.Unmarshal([]byte(out), &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse gh pr list output: %w", err)
+	}
+
+	return prs, nil
+}
+
+// FetchOpenPRs lists the open PRs of the repository with just enough data for
+// a status badge: number, head branch and checks. One call covers every
+// worktree, so the sidebar polls once per repository instead of per branch.
+func FetchOpenPRs(runner Runner, dir string) ([]PRView, error) {
+	out, err := runWithRetry(runner, dir, "pr", "list", "--state", "open", "--limit", "100", "--json", "number,headRefName,statusCheckRollup")
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []PRView
+	if err := json.Unmarshal([]byte(out), &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse gh pr list output: %w", err)
+	}
+
+	return prs, nil
+}
+
+// MergedPR is a pull request listed for release notes.
+type MergedPR struct {
+	Number   int           `json:"number"`
+	Title    string        `json:"title"`
+	Author   CommentAuthor `json:"author"`
+	MergedAt time.Time     `json:"mergedAt"`
+}
+
+// FetchMergedPRs lists the PRs merged into base after since, oldest first.
+// The search qualifier only has day precision, so PRs merged earlier that
+// day are dropped here.
+func FetchMergedPRs(runner Runner, dir, base string, since time.Time) ([]MergedPR, error) {
+	args := []string{"pr", "list", "--state", "merged", "--base", base, "--limit", "200", "--json", "number,title,author,mergedAt"}
+	if !since.IsZero() {
+		args = append(args, "--search", "merged:>="+since.UTC().Format("2006-01-02"))
+	}
+	out, err := runWithRetry(runner, dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []MergedPR
+	if err := json.Unmarshal([]byte(out), &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse gh pr list output: %w", err)
+	}
+	merged := prs[:0]
+	for _, pr := range prs {
+		if pr.MergedAt.After(since) {
+			merged = append(merged, pr)
+		}
+	}
+	slices.SortFunc(merged, func(a, b MergedPR) int { return a.MergedAt.Compare(b.MergedAt) })
+	return merged, nil
+}
+
+// PR states as reported by `gh pr list --json state`.
+const (
+	PRStateOpen   = "OPEN"
+	PRStateClosed = "CLOSED"
+	PRStateMerged = "MERGED"
+)
+
+// FetchPRStates lists the repository's most recent PRs in every state with
+// their head branch, for spotting worktrees whose PR was merged or closed.
+func FetchPRStates(runner Runner, dir string) ([]PRView, error) {
+	out, err := runWithRetry(runner, dir, "pr", "list", "--state", "all", "--limit", "200", "--json", "number,headRefName,state")
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []PRView
+	if err := json.Unmarshal([]byte(out), &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse gh pr list output: %w", err)
+	}
+
+	return prs, nil
+}
+
+// Combined check states returned by CheckRollup, named after GitHub's
+// StatusState enum.
+const (
+	RollupSuccess = "SUCCESS"
+	RollupFailure = "FAILURE"
+	RollupPending = "PENDING"
+)
+
+// CheckRollup combines the PR's checks into one state: FAILURE if any check
+// failed, else PENDING if any is still running, else SUCCESS. A PR without
+// checks returns "".
+func (p PRView) CheckRollup() string {
+	if len(p.StatusCheckRollup) == 0 {
+		return ""
+	}
+	pending := false
+	for _, s := range p.StatusCheckRollup {
+		switch {
+		case s.Failed():
+			return RollupFailure
+		case s.Pending():
+			pending = true
+		}
+	}
+	if pending {
+		return RollupPending
+	}
+	return RollupSuccess
+}
+
+// AddLabel adds a label to PR number, or to the PR for the current branch
+// when number is 0, via `gh pr edit`.
+func AddLabel(runner Runner, dir string, number int, label string) error {
+	if _, err := runner.Run(dir, prEditArgs(number, "--add-label", label)...); err != nil {
+		return fmt.Errorf("adding label %q: %w", label, err)
+	}
+	return nil
+}
+
+// RemoveLabel removes a label from PR number, or from the PR for the
+// current branch when number is 0, via `gh pr edit`.
+func RemoveLabel(runner Runner, dir string, number int, label string) error {
+	if _, err := runner.Run(dir, prEditArgs(number, "--remove-label", label)...); err != nil {
+		return fmt.Errorf("removing label %q: %w", label, err)
+	}
+	return nil
+}
+
+func prEditArgs(number int, flags ...string) []string {
+	args := []string{"pr", "edit"}
+	if number != 0 {
+		args = append(args, strconv.Itoa(number))
+	}
+	return append(args, flags...)
+}
+
 // MapMergeStateStatus converts GitHub's mergeStateStatus to a display string.
 func MapMergeStateStatus(mergeState string, reviewDecision string) string {
 	switch mergeState {
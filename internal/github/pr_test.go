@@ -2,6 +2,7 @@ package github
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -94,6 +95,42 @@ func TestFetchPR_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestFetchPRComments_FirstPage(t *testing.T) {
+	key := fmt.Sprintf("/repo:%v", []string{"api", "graphql",
+		"-f", "query=" + prCommentsQuery,
+		"-F", "owner={owner}", "-F", "repo={repo}", "-F", "number=12", "-F", "limit=20",
+	})
+	runner := &FakeRunner{Outputs: map[string]string{key: `{"data": {"repository": {"pullRequest": {"comments": {
+		"totalCount": 2,
+		"pageInfo": {"hasPreviousPage": false, "startCursor": "Zmlyc3Q="},
+		"nodes": [{"author": {"login": "a"}, "body": "one"}, {"author": {"login": "b"}, "body": "two"}]
+	}}}}}`}}
+
+	page, err := FetchPRComments(runner, "/repo", 12, 20, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Comments) != 2 || page.Comments[1].Body != "two" || page.TotalCount != 2 {
+		t.Errorf("page = %+v, want both comments oldest first", page)
+	}
+	if page.Before != "" {
+		t.Errorf("Before = %q, want none when the page reaches the first comment", page.Before)
+	}
+}
+
+func TestFetchPRComments_GraphQLError(t *testing.T) {
+	key := fmt.Sprintf("/repo:%v", []string{"api", "graphql",
+		"-f", "query=" + prCommentsQuery,
+		"-F", "owner={owner}", "-F", "repo={repo}", "-F", "number=12", "-F", "limit=20",
+		"-f", "before=Y3Vy",
+	})
+	runner := &FakeRunner{Outputs: map[string]string{key: `{"errors": [{"message": "Could not resolve to a PullRequest"}]}`}}
+
+	if _, err := FetchPRComments(runner, "/repo", 12, 20, "Y3Vy"); err == nil || !strings.Contains(err.Error(), "Could not resolve") {
+		t.Errorf("err = %v, want the GraphQL error", err)
+	}
+}
+
 func TestStatusCheckNode_CheckName(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -210,3 +247,212 @@ func TestMapMergeStateStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchPR_Metadata(t *testing.T) {
+	jsonOutput := `{
+		"title": "feat: add auth flow",
+		"labels": [{"name": "bug", "color": "d73a4a"}, {"name": "ui", "color": "000000"}],
+		"assignees": [{"login": "alice"}],
+		"reviewRequests": [{"login": "bob"}, {"name": "Core Team", "slug": "core"}],
+		"latestReviews": [{"author": {"login": "carol"}, "state": "APPROVED"}, {"author": {"login": "bob"}, "state": "COMMENTED"}]
+	}`
+
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("/repo:[pr view --json %s]", prViewFields): jsonOutput,
+		},
+	}
+
+	pr, err := FetchPR(runner, "/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := strings.Join(pr.LabelNames(), ","); got != "bug,ui" {
+		t.Errorf("LabelNames() = %q, want %q", got, "bug,ui")
+	}
+	if got := strings.Join(pr.AssigneeLogins(), ","); got != "alice" {
+		t.Errorf("AssigneeLogins() = %q, want %q", got, "alice")
+	}
+	if got := strings.Join(pr.ReviewerNames(), ","); got != "carol,bob,core" {
+		t.Errorf("ReviewerNames() = %q, want %q", got, "carol,bob,core")
+	}
+}
+
+func TestPRView_MetadataEmpty(t *testing.T) {
+	var pr PRView
+	if len(pr.LabelNames()) != 0 || len(pr.AssigneeLogins()) != 0 || len(pr.ReviewerNames()) != 0 {
+		t.Error("expected empty metadata for zero PRView")
+	}
+}
+
+func TestReviewRequest_DisplayName(t *testing.T) {
+	tests := []struct {
+		name string
+		req  ReviewRequest
+		want string
+	}{
+		{name: "user", req: ReviewRequest{Login: "bob"}, want: "bob"},
+		{name: "team slug", req: ReviewRequest{Name: "Core Team", Slug: "core"}, want: "core"},
+		{name: "team name only", req: ReviewRequest{Name: "Core Team"}, want: "Core Team"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.req.DisplayName(); got != tt.want {
+				t.Errorf("DisplayName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddLabel(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[pr edit 12 --add-label bug]": "",
+		},
+	}
+
+	if err := AddLabel(runner, "/repo", 12, "bug"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runner.Calls) != 1 {
+		t.Fatalf("expected 1 call, got %d", len(runner.Calls))
+	}
+}
+
+func TestRemoveLabel_Error(t *testing.T) {
+	runner := &FakeRunner{
+		Errors: map[string]error{
+			"/repo:[pr edit --remove-label bug]": fmt.Errorf("label not found"),
+		},
+	}
+
+	err := RemoveLabel(runner, "/repo", 0, "bug")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "bug") {
+		t.Errorf("error should mention label, got %v", err)
+	}
+}
+
+func TestFetchPRs(t *testing.T) {
+	jsonOutput := `[
+		{"number": 12, "baseRefName": "main", "title": "feat: base"},
+		{"number": 13, "baseRefName": "release", "title": "feat: backport"}
+	]`
+
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("/repo:[pr list --head feat --state open --json %s]", prListFields): jsonOutput,
+		},
+	}
+
+	prs, err := FetchPRs(runner, "/repo", "feat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("expected 2 PRs, got %d", len(prs))
+	}
+	if prs[1].Number != 13 || prs[1].BaseRefName != "release" {
+		t.Errorf("prs[1] = #%d -> %q, want #13 -> release", prs[1].Number, prs[1].BaseRefName)
+	}
+}
+
+func TestFetchPRs_InvalidJSON(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("/repo:[pr list --head feat --state open --json %s]", prListFields): "nope",
+		},
+	}
+
+	if _, err := FetchPRs(runner, "/repo", "feat"); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+}
+
+func TestFetchOpenPRs(t *testing.T) {
+	jsonOutput := `[
+		{"number": 12, "headRefName": "feat", "statusCheckRollup": [{"status": "COMPLETED", "conclusion": "SUCCESS"}]},
+		{"number": 13, "headRefName": "fix", "statusCheckRollup": []}
+	]`
+
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[pr list --state open --limit 100 --json number,headRefName,statusCheckRollup]": jsonOutput,
+		},
+	}
+
+	prs, err := FetchOpenPRs(runner, "/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("expected 2 PRs, got %d", len(prs))
+	}
+	if prs[0].HeadRefName != "feat" || prs[0].CheckRollup() != RollupSuccess {
+		t.Errorf("prs[0] = %q %q, want feat SUCCESS", prs[0].HeadRefName, prs[0].CheckRollup())
+	}
+}
+
+func TestFetchMergedPRs(t *testing.T) {
+	since := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[pr list --state merged --base main --limit 200 --json number,title,author,mergedAt --search merged:>=2025-03-04]": `[
+				{"number": 14, "title": "Add search", "author": {"login": "bob"}, "mergedAt": "2025-03-06T00:00:00Z"},
+				{"number": 12, "title": "Fix login", "author": {"login": "alice"}, "mergedAt": "2025-03-05T00:00:00Z"},
+				{"number": 11, "title": "Shipped in the last release", "mergedAt": "2025-03-04T08:00:00Z"}
+			]`,
+		},
+	}
+
+	prs, err := FetchMergedPRs(runner, "/repo", "main", since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs) != 2 || prs[0].Number != 12 || prs[1].Number != 14 {
+		t.Errorf("prs = %+v, want #12 then #14", prs)
+	}
+}
+
+func TestFetchPRStates(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[pr list --state all --limit 200 --json number,headRefName,state]": `[{"number": 12, "headRefName": "feat", "state": "MERGED"}]`,
+		},
+	}
+
+	prs, err := FetchPRStates(runner, "/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs) != 1 || prs[0].HeadRefName != "feat" || prs[0].State != PRStateMerged {
+		t.Errorf("prs = %+v", prs)
+	}
+}
+
+func TestPRView_CheckRollup(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []StatusCheckNode
+		want   string
+	}{
+		{name: "no checks", checks: nil, want: ""},
+		{name: "all passed", checks: []StatusCheckNode{{Status: "COMPLETED", Conclusion: "SUCCESS"}, {State: "SUCCESS"}}, want: RollupSuccess},
+		{name: "skipped counts as passed", checks: []StatusCheckNode{{Status: "COMPLETED", Conclusion: "SKIPPED"}}, want: RollupSuccess},
+		{name: "running check", checks: []StatusCheckNode{{Status: "COMPLETED", Conclusion: "SUCCESS"}, {Status: "IN_PROGRESS"}}, want: RollupPending},
+		{name: "pending status", checks: []StatusCheckNode{{State: "PENDING"}}, want: RollupPending},
+		{name: "failure wins over pending", checks: []StatusCheckNode{{Status: "QUEUED"}, {Status: "COMPLETED", Conclusion: "FAILURE"}}, want: RollupFailure},
+		{name: "status error", checks: []StatusCheckNode{{State: "ERROR"}}, want: RollupFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := PRView{StatusCheckRollup: tt.checks}
+			if got := pr.CheckRollup(); got != tt.want {
+				t.Errorf("CheckRollup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package github
 import (
 	"fmt"
 	"os/exec"
+
+	"github.com/mikanfactory/yakumo/internal/retry"
 )
 
 // Runner abstracts gh CLI command execution for testability.
@@ -19,13 +21,21 @@ func (r OSRunner) Run(dir string, args ...string) (string, error) {
 	out, err := cmd.Output()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("gh %v failed: %s", args, string(exitErr.Stderr))
+			return "", newCommandError(args, string(exitErr.Stderr), err)
 		}
-		return "", fmt.Errorf("gh %v failed: %w", args, err)
+		return "", newCommandError(args, "", err)
 	}
 	return string(out), nil
 }
 
+// runWithRetry runs a read-only gh command, retrying transient failures with
+// backoff.
+func runWithRetry(runner Runner, dir string, args ...string) (string, error) {
+	return retry.Do(retry.Default, IsTransient, func() (string, error) {
+		return runner.Run(dir, args...)
+	})
+}
+
 // FakeRunner is a test double that returns preset output and records calls.
 type FakeRunner struct {
 	Outputs map[string]string
@@ -0,0 +1,57 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// workflowRun is one entry of `gh run list --json`.
+type workflowRun struct {
+	HeadSha      string    `json:"headSha"`
+	WorkflowName string    `json:"workflowName"`
+	Status       string    `json:"status"`
+	Conclusion   string    `json:"conclusion"`
+	StartedAt    time.Time `json:"startedAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// FetchBranchChecks returns the Actions runs of the newest commit on branch
+// that has any, one per workflow, as status checks. gh lists runs newest
+// first and in lower case, so they are upper-cased to match check runs.
+func FetchBranchChecks(runner Runner, dir, branch string) ([]StatusCheckNode, error) {
+	out, err := runWithRetry(runner, dir, "run", "list", "--branch", branch, "--limit", "50", "--json", "headSha,workflowName,status,conclusion,startedAt,updatedAt")
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []workflowRun
+	if err := json.Unmarshal([]byte(out), &runs); err != nil {
+		return nil, fmt.Errorf("failed to parse gh run list output: %w", err)
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+
+	head := runs[0].HeadSha
+	seen := make(map[string]bool)
+	var checks []StatusCheckNode
+	for _, r := range runs {
+		if r.HeadSha != head || seen[r.WorkflowName] {
+			continue
+		}
+		seen[r.WorkflowName] = true
+		check := StatusCheckNode{
+			Name:       r.WorkflowName,
+			Status:     strings.ToUpper(r.Status),
+			Conclusion: strings.ToUpper(r.Conclusion),
+			StartedAt:  r.StartedAt,
+		}
+		if check.Status == "COMPLETED" {
+			check.CompletedAt = r.UpdatedAt
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
@@ -0,0 +1,47 @@
+package github
+
+import "testing"
+
+func TestFetchBranchChecks(t *testing.T) {
+	jsonOutput := `[
+		{"headSha": "new", "workflowName": "CI", "status": "completed", "conclusion": "failure"},
+		{"headSha": "new", "workflowName": "Lint", "status": "in_progress", "conclusion": ""},
+		{"headSha": "new", "workflowName": "CI", "status": "completed", "conclusion": "success"},
+		{"headSha": "old", "workflowName": "Deploy", "status": "completed", "conclusion": "success"}
+	]`
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[run list --branch main --limit 50 --json headSha,workflowName,status,conclusion,startedAt,updatedAt]": jsonOutput,
+		},
+	}
+
+	checks, err := FetchBranchChecks(runner, "/repo", "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(checks) != 2 {
+		t.Fatalf("expected the newest run of each workflow on the head commit, got %+v", checks)
+	}
+	if !checks[0].Failed() || checks[0].Name != "CI" {
+		t.Errorf("checks[0] = %+v, want failed CI", checks[0])
+	}
+	if !checks[1].Pending() {
+		t.Errorf("checks[1] = %+v, want pending", checks[1])
+	}
+	if got := (PRView{StatusCheckRollup: checks}).CheckRollup(); got != RollupFailure {
+		t.Errorf("rollup = %q, want FAILURE", got)
+	}
+}
+
+func TestFetchBranchChecks_NoRuns(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[run list --branch main --limit 50 --json headSha,workflowName,status,conclusion,startedAt,updatedAt]": "[]",
+		},
+	}
+
+	checks, err := FetchBranchChecks(runner, "/repo", "main")
+	if err != nil || len(checks) != 0 {
+		t.Errorf("got %v, %v; want no checks", checks, err)
+	}
+}
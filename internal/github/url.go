@@ -14,18 +14,20 @@ type URLType int
 const (
 	URLTypeBranch URLType = iota
 	URLTypePR
+	URLTypeIssue
 )
 
 // URLInfo holds the parsed result of a GitHub URL.
 type URLInfo struct {
-	Type     URLType
-	Owner    string
-	Repo     string
-	Branch   string // populated for branch URLs
-	PRNumber string // populated for PR URLs
+	Type        URLType
+	Owner       string
+	Repo        string
+	Branch      string // populated for branch URLs
+	PRNumber    string // populated for PR URLs
+	IssueNumber string // populated for issue URLs
 }
 
-// ParseGitHubURL parses a GitHub branch or PR URL and extracts its components.
+// ParseGitHubURL parses a GitHub branch, PR or issue URL and extracts its components.
 func ParseGitHubURL(rawURL string) (URLInfo, error) {
 	if rawURL == "" {
 		return URLInfo{}, fmt.Errorf("empty URL")
@@ -40,13 +42,13 @@ func ParseGitHubURL(rawURL string) (URLInfo, error) {
 		return URLInfo{}, fmt.Errorf("not a GitHub URL: %s", parsed.Host)
 	}
 
-	// path: /owner/repo/tree/branch-name or /owner/repo/pull/123
+	// path: /owner/repo/tree/branch-name, /owner/repo/pull/123 or /owner/repo/issues/123
 	path := strings.TrimPrefix(parsed.Path, "/")
 	path = strings.TrimSuffix(path, "/")
 	segments := strings.SplitN(path, "/", 4)
 
 	if len(segments) < 4 {
-		return URLInfo{}, fmt.Errorf("unsupported GitHub URL format: need /owner/repo/tree|pull/...")
+		return URLInfo{}, fmt.Errorf("unsupported GitHub URL format: need /owner/repo/tree|pull|issues/...")
 	}
 
 	owner := segments[0]
@@ -82,9 +84,79 @@ func ParseGitHubURL(rawURL string) (URLInfo, error) {
 			PRNumber: numberStr,
 		}, nil
 
+	case "issues":
+		numberStr := strings.SplitN(rest, "/", 2)[0]
+		if numberStr == "" {
+			return URLInfo{}, fmt.Errorf("issue number is empty")
+		}
+		if _, err := strconv.Atoi(numberStr); err != nil {
+			return URLInfo{}, fmt.Errorf("invalid issue number: %q", numberStr)
+		}
+		return URLInfo{
+			Type:        URLTypeIssue,
+			Owner:       owner,
+			Repo:        repo,
+			IssueNumber: numberStr,
+		}, nil
+
+	default:
+		return URLInfo{}, fmt.Errorf("unsupported GitHub URL type: %q (expected tree, pull or issues)", kind)
+	}
+}
+
+// ParseRemoteURL extracts owner and repo from a GitHub remote URL in either
+// SSH ("git@github.com:owner/repo.git") or HTTPS form.
+func ParseRemoteURL(remote string) (string, string, error) {
+	path := ""
+	switch {
+	case strings.HasPrefix(remote, "git@github.com:"):
+		path = strings.TrimPrefix(remote, "git@github.com:")
 	default:
-		return URLInfo{}, fmt.Errorf("unsupported GitHub URL type: %q (expected tree or pull)", kind)
+		parsed, err := url.Parse(remote)
+		if err != nil || parsed.Host != "github.com" {
+			return "", "", fmt.Errorf("not a GitHub remote: %s", remote)
+		}
+		path = strings.TrimPrefix(parsed.Path, "/")
+	}
+
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+	segments := strings.Split(path, "/")
+	if len(segments) != 2 || segments[0] == "" || segments[1] == "" {
+		return "", "", fmt.Errorf("unsupported GitHub remote format: %s", remote)
+	}
+	return segments[0], segments[1], nil
+}
+
+// CompareURL returns the github.com page that drafts a pull request from head
+// into base, pre-filling the description with body when it is non-empty.
+func CompareURL(owner, repo, base, head, body string) string {
+	u := url.URL{
+		Scheme: "https",
+		Host:   "github.com",
+		Path:   fmt.Sprintf("/%s/%s/compare/%s...%s", owner, repo, base, head),
+	}
+	q := url.Values{"expand": {"1"}}
+	if body != "" {
+		q.Set("body", body)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// ReleaseURL returns the github.com page that drafts a release for tag,
+// pre-filling the title with the tag and the notes with body.
+func ReleaseURL(owner, repo, tag, body string) string {
+	u := url.URL{
+		Scheme: "https",
+		Host:   "github.com",
+		Path:   fmt.Sprintf("/%s/%s/releases/new", owner, repo),
+	}
+	q := url.Values{"tag": {tag}, "title": {tag}}
+	if body != "" {
+		q.Set("body", body)
 	}
+	u.RawQuery = q.Encode()
+	return u.String()
 }
 
 // prBranchResponse represents the JSON from `gh pr view --json headRefName`.
@@ -94,7 +166,7 @@ type prBranchResponse struct {
 
 // FetchPRBranch uses the gh CLI to get the branch name for a PR URL.
 func FetchPRBranch(runner Runner, dir string, prURL string) (string, error) {
-	out, err := runner.Run(dir, "pr", "view", prURL, "--json", "headRefName")
+	out, err := runWithRetry(runner, dir, "pr", "view", prURL, "--json", "headRefName")
 	if err != nil {
 		return "", fmt.Errorf("fetching PR branch: %w", err)
 	}
@@ -111,11 +183,14 @@ func FetchPRBranch(runner Runner, dir string, prURL string) (string, error) {
 	return resp.HeadRefName, nil
 }
 
-// BranchSlug returns the last segment of a branch name for use as a directory name.
-// e.g. "feature/my-branch" -> "my-branch", "main" -> "main"
+// BranchSlug flattens a branch name into a single directory name: the first
+// segment (the user or type prefix) is dropped and any remaining "/" become
+// "-", so nested branches never produce nested directories.
+// e.g. "feature/my-branch" -> "my-branch", "release/2024/fix-x" -> "2024-fix-x", "main" -> "main"
 func BranchSlug(branch string) string {
-	if idx := strings.LastIndex(branch, "/"); idx >= 0 {
-		return branch[idx+1:]
+	slug := branch
+	if _, rest, ok := strings.Cut(branch, "/"); ok && rest != "" {
+		slug = rest
 	}
-	return branch
+	return strings.ReplaceAll(strings.Trim(slug, "/"), "/", "-")
 }
@@ -118,6 +118,24 @@ func TestParseGitHubURL_PRURL(t *testing.T) {
 	}
 }
 
+func TestParseGitHubURL_IssueURL(t *testing.T) {
+	for _, raw := range []string{
+		"https://github.com/owner/repo/issues/12",
+		"https://github.com/owner/repo/issues/12/",
+	} {
+		t.Run(raw, func(t *testing.T) {
+			info, err := ParseGitHubURL(raw)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := URLInfo{Type: URLTypeIssue, Owner: "owner", Repo: "repo", IssueNumber: "12"}
+			if info != want {
+				t.Errorf("info = %+v, want %+v", info, want)
+			}
+		})
+	}
+}
+
 func TestParseGitHubURL_Invalid(t *testing.T) {
 	tests := []struct {
 		name string
@@ -130,6 +148,8 @@ func TestParseGitHubURL_Invalid(t *testing.T) {
 		{name: "tree without branch", url: "https://github.com/owner/repo/tree/"},
 		{name: "pull without number", url: "https://github.com/owner/repo/pull/"},
 		{name: "pull with non-numeric", url: "https://github.com/owner/repo/pull/abc"},
+		{name: "issues without number", url: "https://github.com/owner/repo/issues/"},
+		{name: "issues with non-numeric", url: "https://github.com/owner/repo/issues/new"},
 	}
 
 	for _, tt := range tests {
@@ -161,6 +181,25 @@ func TestFetchPRBranch(t *testing.T) {
 	}
 }
 
+func TestFetchIssue(t *testing.T) {
+	issueURL := "https://github.com/owner/repo/issues/12"
+	key := fmt.Sprintf(".:%v", []string{"issue", "view", issueURL, "--json", "number,title,url"})
+
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			key: `{"number":12,"title":"Login redirect loops","url":"` + issueURL + `"}` + "\n",
+		},
+	}
+
+	issue, err := FetchIssue(runner, ".", issueURL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.Number != 12 || issue.Title != "Login redirect loops" {
+		t.Errorf("issue = %+v", issue)
+	}
+}
+
 func TestBranchSlug(t *testing.T) {
 	tests := []struct {
 		branch string
@@ -169,7 +208,9 @@ func TestBranchSlug(t *testing.T) {
 		{branch: "main", want: "main"},
 		{branch: "feature/my-branch", want: "my-branch"},
 		{branch: "mikanfactory/fix-login", want: "fix-login"},
-		{branch: "feat/scope/description", want: "description"},
+		{branch: "feat/scope/description", want: "scope-description"},
+		{branch: "release/2024/fix-x", want: "2024-fix-x"},
+		{branch: "a/b/c/d", want: "b-c-d"},
 	}
 
 	for _, tt := range tests {
@@ -181,3 +222,56 @@ func TestBranchSlug(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		remote    string
+		wantOwner string
+		wantRepo  string
+		wantErr   bool
+	}{
+		{remote: "git@github.com:owner/repo.git", wantOwner: "owner", wantRepo: "repo"},
+		{remote: "https://github.com/owner/repo.git", wantOwner: "owner", wantRepo: "repo"},
+		{remote: "https://github.com/owner/repo", wantOwner: "owner", wantRepo: "repo"},
+		{remote: "ssh://git@github.com/owner/repo.git", wantOwner: "owner", wantRepo: "repo"},
+		{remote: "https://gitlab.com/owner/repo.git", wantErr: true},
+		{remote: "git@github.com:owner", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			owner, repo, err := ParseRemoteURL(tt.remote)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if owner != tt.wantOwner || repo != tt.wantRepo {
+				t.Errorf("got %s/%s, want %s/%s", owner, repo, tt.wantOwner, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func TestCompareURL(t *testing.T) {
+	got := CompareURL("owner", "repo", "main", "feat/login", "Fix the login redirect")
+	want := "https://github.com/owner/repo/compare/main...feat/login?body=Fix+the+login+redirect&expand=1"
+	if got != want {
+		t.Errorf("CompareURL = %q, want %q", got, want)
+	}
+
+	if got := CompareURL("owner", "repo", "main", "feat", ""); got != "https://github.com/owner/repo/compare/main...feat?expand=1" {
+		t.Errorf("CompareURL without body = %q", got)
+	}
+}
+
+func TestReleaseURL(t *testing.T) {
+	got := ReleaseURL("owner", "repo", "v1.2.0", "- Fix login (#12)")
+	want := "https://github.com/owner/repo/releases/new?body=-+Fix+login+%28%2312%29&tag=v1.2.0&title=v1.2.0"
+	if got != want {
+		t.Errorf("ReleaseURL = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,188 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MRView represents the JSON output from `glab mr view --output json`.
+type MRView struct {
+	IID                 int        `json:"iid"`
+	Title               string     `json:"title"`
+	Description         string     `json:"description"`
+	State               string     `json:"state"`
+	WebURL              string     `json:"web_url"`
+	SourceBranch        string     `json:"source_branch"`
+	TargetBranch        string     `json:"target_branch"`
+	Labels              []string   `json:"labels"`
+	Assignees           []User     `json:"assignees"`
+	Reviewers           []User     `json:"reviewers"`
+	DetailedMergeStatus string     `json:"detailed_merge_status"`
+	HasConflicts        bool       `json:"has_conflicts"`
+	HeadPipeline        *Pipeline  `json:"head_pipeline"`
+	CreatedAt           time.Time  `json:"created_at"`
+	MergedAt            *time.Time `json:"merged_at"`
+}
+
+// User represents a GitLab user.
+type User struct {
+	Username string `json:"username"`
+}
+
+// Pipeline represents a CI pipeline, e.g. the head pipeline of an MR.
+type Pipeline struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+}
+
+// Job represents a CI job in a pipeline.
+type Job struct {
+	Name       string    `json:"name"`
+	Stage      string    `json:"stage"`
+	Status     string    `json:"status"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Note represents a comment on an MR.
+type Note struct {
+	Author    User      `json:"author"`
+	Body      string    `json:"body"`
+	System    bool      `json:"system"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Approvals represents the approval state of an MR.
+type Approvals struct {
+	ApprovedBy []struct {
+		User User `json:"user"`
+	} `json:"approved_by"`
+}
+
+// FetchMR uses glab to get the MR for the current branch in dir.
+func FetchMR(runner Runner, dir string) (MRView, error) {
+	out, err := runner.Run(dir, "mr", "view", "--output", "json")
+	if err != nil {
+		return MRView{}, fmt.Errorf("fetching MR: %w", err)
+	}
+
+	var mr MRView
+	if err := json.Unmarshal([]byte(out), &mr); err != nil {
+		return MRView{}, fmt.Errorf("parsing MR JSON: %w", err)
+	}
+	return mr, nil
+}
+
+// FetchMRByIID uses glab to get MR iid. project (e.g. "group/repo") selects
+// another project; empty means the project of dir.
+func FetchMRByIID(runner Runner, dir, project string, iid int) (MRView, error) {
+	args := []string{"mr", "view", strconv.Itoa(iid)}
+	if project != "" {
+		args = append(args, "--repo", project)
+	}
+	out, err := runner.Run(dir, append(args, "--output", "json")...)
+	if err != nil {
+		return MRView{}, fmt.Errorf("fetching MR !%d: %w", iid, err)
+	}
+
+	var mr MRView
+	if err := json.Unmarshal([]byte(out), &mr); err != nil {
+		return MRView{}, fmt.Errorf("parsing MR JSON: %w", err)
+	}
+	return mr, nil
+}
+
+// FetchMRs uses glab to list open MRs whose source branch is branch.
+func FetchMRs(runner Runner, dir, branch string) ([]MRView, error) {
+	out, err := runner.Run(dir, "mr", "list", "--source-branch", branch, "--output", "json")
+	if err != nil {
+		return nil, fmt.Errorf("listing MRs: %w", err)
+	}
+
+	var mrs []MRView
+	if err := json.Unmarshal([]byte(out), &mrs); err != nil {
+		return nil, fmt.Errorf("parsing MR list JSON: %w", err)
+	}
+	return mrs, nil
+}
+
+// FetchNotes returns the non-system comments on MR iid.
+func FetchNotes(runner Runner, dir string, iid int) ([]Note, error) {
+	var notes []Note
+	if err := api(runner, dir, fmt.Sprintf("projects/:id/merge_requests/%d/notes?sort=asc", iid), &notes); err != nil {
+		return nil, fmt.Errorf("fetching MR notes: %w", err)
+	}
+
+	comments := make([]Note, 0, len(notes))
+	for _, n := range notes {
+		if !n.System {
+			comments = append(comments, n)
+		}
+	}
+	return comments, nil
+}
+
+// FetchJobs returns the jobs of pipeline id.
+func FetchJobs(runner Runner, dir string, pipelineID int) ([]Job, error) {
+	var jobs []Job
+	if err := api(runner, dir, fmt.Sprintf("projects/:id/pipelines/%d/jobs", pipelineID), &jobs); err != nil {
+		return nil, fmt.Errorf("fetching pipeline jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// FetchLatestPipeline returns the newest pipeline run for ref, or nil when
+// the ref has none.
+func FetchLatestPipeline(runner Runner, dir, ref string) (*Pipeline, error) {
+	var pipelines []Pipeline
+	path := "projects/:id/pipelines?per_page=1&ref=" + url.QueryEscape(ref)
+	if err := api(runner, dir, path, &pipelines); err != nil {
+		return nil, fmt.Errorf("fetching pipelines for %s: %w", ref, err)
+	}
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+	return &pipelines[0], nil
+}
+
+// FetchApprovals returns the approval state of MR iid.
+func FetchApprovals(runner Runner, dir string, iid int) (Approvals, error) {
+	var approvals Approvals
+	if err := api(runner, dir, fmt.Sprintf("projects/:id/merge_requests/%d/approvals", iid), &approvals); err != nil {
+		return Approvals{}, fmt.Errorf("fetching MR approvals: %w", err)
+	}
+	return approvals, nil
+}
+
+// AddLabel adds label to MR iid via `glab mr update --label`.
+func AddLabel(runner Runner, dir string, iid int, label string) error {
+	if _, err := runner.Run(dir, "mr", "update", strconv.Itoa(iid), "--label", label); err != nil {
+		return fmt.Errorf("adding label %q: %w", label, err)
+	}
+	return nil
+}
+
+// RemoveLabel removes label from MR iid via `glab mr update --unlabel`.
+func RemoveLabel(runner Runner, dir string, iid int, label string) error {
+	if _, err := runner.Run(dir, "mr", "update", strconv.Itoa(iid), "--unlabel", label); err != nil {
+		return fmt.Errorf("removing label %q: %w", label, err)
+	}
+	return nil
+}
+
+// api runs `glab api` for path, which may use glab's :id placeholder for the
+// current project, and decodes the JSON response into v.
+func api(runner Runner, dir, path string, v any) error {
+	out, err := runner.Run(dir, "api", path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), v); err != nil {
+		return fmt.Errorf("parsing glab api response: %w", err)
+	}
+	return nil
+}
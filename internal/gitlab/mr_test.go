@@ -0,0 +1,126 @@
+package gitlab
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFetchMR(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[mr view --output json]": `{"iid": 3, "title": "feat: mr", "state": "opened", "source_branch": "feat", "target_branch": "main", "labels": ["bug"], "head_pipeline": {"id": 99, "status": "success"}}`,
+		},
+	}
+
+	mr, err := FetchMR(runner, "/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mr.IID != 3 || mr.SourceBranch != "feat" || mr.TargetBranch != "main" {
+		t.Errorf("unexpected MR: %+v", mr)
+	}
+	if mr.HeadPipeline == nil || mr.HeadPipeline.ID != 99 {
+		t.Errorf("head pipeline = %+v, want id 99", mr.HeadPipeline)
+	}
+}
+
+func TestFetchMR_Error(t *testing.T) {
+	runner := &FakeRunner{
+		Errors: map[string]error{
+			"/repo:[mr view --output json]": fmt.Errorf("no open merge request"),
+		},
+	}
+
+	if _, err := FetchMR(runner, "/repo"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestFetchMRByIID(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[mr view 12 --repo group/repo --output json]": `{"iid": 12, "source_branch": "feature/x"}`,
+			"/repo:[mr view 12 --output json]":                   `{"iid": 12, "source_branch": "feature/y"}`,
+		},
+	}
+
+	mr, err := FetchMRByIID(runner, "/repo", "group/repo", 12)
+	if err != nil || mr.SourceBranch != "feature/x" {
+		t.Errorf("with project = %+v, %v; want feature/x", mr, err)
+	}
+	mr, err = FetchMRByIID(runner, "/repo", "", 12)
+	if err != nil || mr.SourceBranch != "feature/y" {
+		t.Errorf("without project = %+v, %v; want feature/y", mr, err)
+	}
+}
+
+func TestFetchMRs(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[mr list --source-branch feat --output json]": `[{"iid": 1}, {"iid": 2}]`,
+		},
+	}
+
+	mrs, err := FetchMRs(runner, "/repo", "feat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mrs) != 2 || mrs[1].IID != 2 {
+		t.Errorf("mrs = %+v, want !1 and !2", mrs)
+	}
+}
+
+func TestFetchNotes_SkipsSystemNotes(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[api projects/:id/merge_requests/3/notes?sort=asc]": `[
+				{"author": {"username": "bot"}, "body": "added 1 commit", "system": true},
+				{"author": {"username": "alice"}, "body": "LGTM", "system": false}
+			]`,
+		},
+	}
+
+	notes, err := FetchNotes(runner, "/repo", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notes) != 1 || notes[0].Author.Username != "alice" {
+		t.Errorf("notes = %+v, want only alice's", notes)
+	}
+}
+
+func TestFetchLatestPipeline(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[api projects/:id/pipelines?per_page=1&ref=release%2F1.0]": `[{"id": 7, "status": "failed"}]`,
+			"/repo:[api projects/:id/pipelines?per_page=1&ref=main]":          `[]`,
+		},
+	}
+
+	pipeline, err := FetchLatestPipeline(runner, "/repo", "release/1.0")
+	if err != nil || pipeline == nil || pipeline.ID != 7 {
+		t.Errorf("got %+v, %v; want pipeline 7", pipeline, err)
+	}
+	pipeline, err = FetchLatestPipeline(runner, "/repo", "main")
+	if err != nil || pipeline != nil {
+		t.Errorf("got %+v, %v; want no pipeline", pipeline, err)
+	}
+}
+
+func TestAddAndRemoveLabel(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[mr update 3 --label bug]": "",
+		},
+		Errors: map[string]error{
+			"/repo:[mr update 3 --unlabel bug]": fmt.Errorf("forbidden"),
+		},
+	}
+
+	if err := AddLabel(runner, "/repo", 3, "bug"); err != nil {
+		t.Fatalf("AddLabel error: %v", err)
+	}
+	if err := RemoveLabel(runner, "/repo", 3, "bug"); err == nil {
+		t.Fatal("expected RemoveLabel error, got nil")
+	}
+}
@@ -0,0 +1,61 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// URLInfo holds the parsed result of a GitLab branch or MR URL.
+type URLInfo struct {
+	Project string // full project path, e.g. "group/sub/repo"
+	Branch  string // populated for branch URLs
+	MRIID   int    // populated for MR URLs
+}
+
+// IsMR reports whether the URL points at a merge request.
+func (u URLInfo) IsMR() bool {
+	return u.MRIID != 0
+}
+
+// ParseGitLabURL parses a GitLab branch URL (/group/repo/-/tree/branch) or MR
+// URL (/group/repo/-/merge_requests/12). Self-hosted hosts are accepted.
+func ParseGitLabURL(rawURL string) (URLInfo, error) {
+	if rawURL == "" {
+		return URLInfo{}, fmt.Errorf("empty URL")
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return URLInfo{}, fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Host == "" {
+		return URLInfo{}, fmt.Errorf("invalid URL: missing host")
+	}
+
+	project, rest, ok := strings.Cut(strings.Trim(parsed.Path, "/"), "/-/")
+	if !ok || project == "" {
+		return URLInfo{}, fmt.Errorf("unsupported GitLab URL format: need /group/repo/-/tree|merge_requests/...")
+	}
+
+	kind, value, _ := strings.Cut(rest, "/")
+	switch kind {
+	case "tree":
+		if value == "" {
+			return URLInfo{}, fmt.Errorf("branch name is empty")
+		}
+		return URLInfo{Project: project, Branch: value}, nil
+
+	case "merge_requests":
+		numberStr := strings.SplitN(value, "/", 2)[0]
+		iid, err := strconv.Atoi(numberStr)
+		if err != nil || iid <= 0 {
+			return URLInfo{}, fmt.Errorf("invalid MR number: %q", numberStr)
+		}
+		return URLInfo{Project: project, MRIID: iid}, nil
+
+	default:
+		return URLInfo{}, fmt.Errorf("unsupported GitLab URL type: %q (expected tree or merge_requests)", kind)
+	}
+}
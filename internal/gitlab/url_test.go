@@ -0,0 +1,48 @@
+package gitlab
+
+import "testing"
+
+func TestParseGitLabURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    URLInfo
+		wantErr bool
+	}{
+		{
+			name: "branch",
+			url:  "https://gitlab.com/group/repo/-/tree/feature/x",
+			want: URLInfo{Project: "group/repo", Branch: "feature/x"},
+		},
+		{
+			name: "MR in subgroup",
+			url:  "https://gitlab.example.com/group/sub/repo/-/merge_requests/12/diffs",
+			want: URLInfo{Project: "group/sub/repo", MRIID: 12},
+		},
+		{name: "no dash separator", url: "https://gitlab.com/group/repo/tree/main", wantErr: true},
+		{name: "bad MR number", url: "https://gitlab.com/group/repo/-/merge_requests/abc", wantErr: true},
+		{name: "unsupported kind", url: "https://gitlab.com/group/repo/-/issues/3", wantErr: true},
+		{name: "empty branch", url: "https://gitlab.com/group/repo/-/tree/", wantErr: true},
+		{name: "empty", url: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGitLabURL(tt.url)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseGitLabURL() = %+v, want %+v", got, tt.want)
+			}
+			if got.IsMR() != (tt.want.MRIID != 0) {
+				t.Errorf("IsMR() = %v", got.IsMR())
+			}
+		})
+	}
+}
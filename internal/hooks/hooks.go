@@ -0,0 +1,83 @@
+// Package hooks runs the shell commands users configure for points in a
+// worktree's life, such as `direnv allow` after it is created or a database
+// teardown before it is archived.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// For returns the hooks of the repository at repoPath: its own, each
+// falling back to the top-level one when unset.
+func For(cfg model.Config, repoPath string) model.Hooks {
+	hooks := cfg.Hooks
+	for _, repo := range cfg.Repositories {
+		if repo.Path != repoPath {
+			continue
+		}
+		if repo.Hooks.PostCreate != "" {
+			hooks.PostCreate = repo.Hooks.PostCreate
+		}
+		if repo.Hooks.PreArchive != "" {
+			hooks.PreArchive = repo.Hooks.PreArchive
+		}
+	}
+	return hooks
+}
+
+// Env describes the worktree a hook runs for. It reaches the hook as
+// YAKUMO_WORKTREE, YAKUMO_BRANCH, YAKUMO_REPO and YAKUMO_REPO_NAME.
+type Env struct {
+	Worktree string
+	Branch   string // "" when detached
+	Repo     string
+}
+
+func (e Env) environ() []string {
+	return append(os.Environ(),
+		"YAKUMO_WORKTREE="+e.Worktree,
+		"YAKUMO_BRANCH="+e.Branch,
+		"YAKUMO_REPO="+e.Repo,
+		"YAKUMO_REPO_NAME="+filepath.Base(e.Repo),
+	)
+}
+
+// Timeout is how long a hook may run before it is killed, so a hook that
+// hangs cannot hang adding or archiving a worktree with it.
+var Timeout = 5 * time.Minute
+
+// Run runs command with sh in the worktree of env. An empty command does
+// nothing. The error of a failed command carries its output.
+func Run(command string, env Env) error {
+	if command == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = env.Worktree
+	cmd.Env = env.environ()
+	// Processes the hook left running may hold its output open; stop
+	// waiting for them once sh is gone.
+	cmd.WaitDelay = time.Second
+	out, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s: timed out after %s", command, Timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %w: %s", command, err, msg)
+		}
+		return fmt.Errorf("%s: %w", command, err)
+	}
+	return nil
+}
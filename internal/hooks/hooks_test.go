@@ -0,0 +1,66 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestFor(t *testing.T) {
+	cfg := model.Config{
+		Hooks: model.Hooks{PostCreate: "direnv allow", PreArchive: "make down"},
+		Repositories: []model.RepositoryDef{
+			{Path: "/repo/a", Hooks: model.Hooks{PreArchive: "./teardown.sh"}},
+			{Path: "/repo/b"},
+		},
+	}
+	if got, want := For(cfg, "/repo/a"), (model.Hooks{PostCreate: "direnv allow", PreArchive: "./teardown.sh"}); got != want {
+		t.Errorf("For(a) = %+v, want %+v", got, want)
+	}
+	if got := For(cfg, "/repo/b"); got != cfg.Hooks {
+		t.Errorf("For(b) = %+v, want the top-level hooks", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	env := Env{Worktree: dir, Branch: "me/chile", Repo: "/src/app"}
+	if err := Run(`echo "$YAKUMO_BRANCH $YAKUMO_REPO_NAME $YAKUMO_REPO $YAKUMO_WORKTREE" > out`, env); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(string(out)), "me/chile app /src/app "+dir; got != want {
+		t.Errorf("hook saw %q, want %q", got, want)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	err := Run("echo db is busy >&2; exit 3", Env{Worktree: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "db is busy") {
+		t.Errorf("Run = %v, want an error with the hook's output", err)
+	}
+	if err := Run("", Env{Worktree: "/nonexistent"}); err != nil {
+		t.Errorf("Run of an empty hook = %v, want nil", err)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	defer func(d time.Duration) { Timeout = d }(Timeout)
+	Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	err := Run("sleep 10", Env{Worktree: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Run = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run returned after %s, want it killed at the timeout", elapsed)
+	}
+}
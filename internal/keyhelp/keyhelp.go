@@ -0,0 +1,172 @@
+// Package keyhelp renders the ? overlay of the TUIs from their keymaps, so
+// the listed keys are the ones the Update functions actually match.
+package keyhelp
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// Section is the key bindings of one mode.
+type Section struct {
+	Title string
+	Keys  []key.Binding
+}
+
+// Bindings returns the key.Binding fields of keyMap, a struct, in the order
+// they are declared. Listing a keymap this way means a binding added to it
+// can't be left out of the overlay.
+func Bindings(keyMap any) []key.Binding {
+	v := reflect.ValueOf(keyMap)
+	var bindings []key.Binding
+	for i := range v.NumField() {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		if b, ok := v.Field(i).Interface().(key.Binding); ok && b.Enabled() {
+			bindings = append(bindings, b)
+		}
+	}
+	return bindings
+}
+
+// ShortHelp joins bindings into a one-line help such as "q: quit  /: filter".
+func ShortHelp(bindings ...key.Binding) string {
+	parts := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		h := b.Help()
+		parts = append(parts, h.Key+": "+h.Desc)
+	}
+	return strings.Join(parts, "  ")
+}
+
+// Lines lays sections out as a title line per mode followed by one line per
+// binding, with the keys aligned in a column.
+func Lines(sections []Section, titleStyle, keyStyle lipgloss.Style) []string {
+	width := 0
+	for _, s := range sections {
+		for _, b := range s.Keys {
+			width = max(width, ansi.StringWidth(b.Help().Key))
+		}
+	}
+
+	var lines []string
+	for i, s := range sections {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, titleStyle.Render(s.Title))
+		for _, b := range s.Keys {
+			h := b.Help()
+			pad := strings.Repeat(" ", width-ansi.StringWidth(h.Key))
+			lines = append(lines, "  "+keyStyle.Render(h.Key)+pad+"  "+h.Desc)
+		}
+	}
+	return lines
+}
+
+// Rebind applies the `keybindings` config: overrides maps a mode to the new
+// keys of its actions. keymaps maps each mode to a pointer to its keymap
+// struct, whose fields are the actions in snake_case (QuickDiff is
+// quick_diff); an empty key list unbinds the action. Unknown modes and
+// actions are errors, and so is a key bound to two actions of one mode.
+func Rebind(keymaps map[string]any, overrides map[string]map[string]model.KeyList) error {
+	var errs []error
+	for _, mode := range slices.Sorted(maps.Keys(overrides)) {
+		km, ok := keymaps[mode]
+		if !ok {
+			errs = append(errs, fmt.Errorf("keybindings: unknown mode %q (want one of %s)", mode, strings.Join(slices.Sorted(maps.Keys(keymaps)), ", ")))
+			continue
+		}
+		v := reflect.ValueOf(km).Elem()
+		for _, action := range slices.Sorted(maps.Keys(overrides[mode])) {
+			keys := overrides[mode][action]
+			b := binding(v, action)
+			if b == nil {
+				errs = append(errs, fmt.Errorf("keybindings.%s: unknown action %q", mode, action))
+				continue
+			}
+			if len(keys) == 0 {
+				b.Unbind()
+				continue
+			}
+			b.SetKeys(keys...)
+			b.SetHelp(helpKey(keys), b.Help().Desc)
+		}
+		errs = append(errs, checkDuplicates(mode, v))
+	}
+	return errors.Join(errs...)
+}
+
+// binding returns the field of the keymap struct v named action in
+// snake_case, or nil.
+func binding(v reflect.Value, action string) *key.Binding {
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if f.IsExported() && snakeCase(f.Name) == action {
+			b, _ := v.Field(i).Addr().Interface().(*key.Binding)
+			return b
+		}
+	}
+	return nil
+}
+
+func checkDuplicates(mode string, v reflect.Value) error {
+	boundTo := make(map[string]string)
+	var errs []error
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		b, ok := v.Field(i).Interface().(key.Binding)
+		if !ok {
+			continue
+		}
+		for _, k := range b.Keys() {
+			if other, ok := boundTo[k]; ok {
+				errs = append(errs, fmt.Errorf("keybindings.%s: %q is bound to both %s and %s", mode, k, other, snakeCase(f.Name)))
+				continue
+			}
+			boundTo[k] = snakeCase(f.Name)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// snakeCase turns a field name such as QuickDiff or OpenPR into quick_diff or
+// open_pr.
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+var keyNames = map[string]string{" ": "space", "up": "↑", "down": "↓", "left": "←", "right": "→"}
+
+// helpKey is how the overlay shows keys, e.g. "↑/i".
+func helpKey(keys []string) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = cmp.Or(keyNames[k], k)
+	}
+	return strings.Join(names, "/")
+}
@@ -0,0 +1,93 @@
+package keyhelp
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+type testKeyMap struct {
+	Up       key.Binding
+	Quit     key.Binding
+	Disabled key.Binding
+	name     string
+}
+
+var testKeys = testKeyMap{
+	Up:       key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "move up")),
+	Quit:     key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit")),
+	Disabled: key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "gone"), key.WithDisabled()),
+}
+
+func TestBindings(t *testing.T) {
+	got := Bindings(testKeys)
+	if len(got) != 2 || !slices.Equal(got[0].Keys(), []string{"up", "k"}) || got[1].Help().Desc != "quit" {
+		t.Errorf("Bindings = %+v, want Up then Quit without the disabled binding", got)
+	}
+}
+
+func TestShortHelp(t *testing.T) {
+	if got := ShortHelp(testKeys.Up, testKeys.Quit); got != "↑/k: move up  q: quit" {
+		t.Errorf("ShortHelp = %q", got)
+	}
+}
+
+func TestLines(t *testing.T) {
+	plain := lipgloss.NewStyle()
+	got := Lines([]Section{
+		{Title: "List", Keys: []key.Binding{testKeys.Up}},
+		{Title: "Global", Keys: []key.Binding{testKeys.Quit}},
+	}, plain, plain)
+	want := []string{"List", "  ↑/k  move up", "", "Global", "  q    quit"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Lines =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	keys := testKeys
+	keymaps := map[string]any{"list": &keys}
+
+	err := Rebind(keymaps, map[string]map[string]model.KeyList{
+		"list": {"up": {"up", "i"}, "quit": {}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(keys.Up.Keys(), []string{"up", "i"}) || keys.Up.Help().Key != "↑/i" || keys.Up.Help().Desc != "move up" {
+		t.Errorf("Up = %v %+v, want rebound to up/i", keys.Up.Keys(), keys.Up.Help())
+	}
+	if keys.Quit.Enabled() || len(Bindings(keys)) != 1 {
+		t.Error("an empty key list should unbind the action")
+	}
+	if testKeys.Up.Help().Key != "↑/k" {
+		t.Error("Rebind should only change the keymap it was given")
+	}
+}
+
+func TestRebind_Errors(t *testing.T) {
+	keys := testKeys
+	keymaps := map[string]any{"list": &keys}
+
+	for name, overrides := range map[string]map[string]map[string]model.KeyList{
+		"unknown mode":   {"sidebar": {"up": {"i"}}},
+		"unknown action": {"list": {"archive": {"x"}}},
+		"clashing keys":  {"list": {"quit": {"k"}}},
+	} {
+		if err := Rebind(keymaps, overrides); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{"Up": "up", "QuickDiff": "quick_diff", "OpenPR": "open_pr", "ForceQuit": "force_quit"} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
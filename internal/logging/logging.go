@@ -0,0 +1,80 @@
+// Package logging is the leveled, structured log shared by yakumo's
+// packages: records go through slog's default logger, tagged with the
+// component that wrote them, at a level set by log_level or
+// $YAKUMO_LOG_LEVEL.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvLevel overrides the configured log_level, e.g. YAKUMO_LOG_LEVEL=debug
+// to trace a single run.
+const EnvLevel = "YAKUMO_LOG_LEVEL"
+
+// MaxBytes is the size at which debug.log is rotated to debug.log.1.
+const MaxBytes = 10 << 20
+
+// Levels lists the accepted log levels, most verbose first.
+var Levels = []string{"debug", "info", "warn", "error"}
+
+var level = new(slog.LevelVar)
+
+// Path returns where the UIs write their log.
+func Path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "yakumo", "debug.log"), nil
+}
+
+// Setup makes w the destination of slog's default logger, and so of the
+// standard log package, at the level in $YAKUMO_LOG_LEVEL or info.
+func Setup(w io.Writer) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})))
+	if err := SetLevel(""); err != nil {
+		For("logging").Warn("ignoring "+EnvLevel, "err", err)
+	}
+}
+
+// SetLevel sets the level to configured, a log_level value, unless
+// $YAKUMO_LOG_LEVEL is set. An invalid level leaves it unchanged.
+func SetLevel(configured string) error {
+	if env := os.Getenv(EnvLevel); env != "" {
+		configured = env
+	}
+	l, err := ParseLevel(configured)
+	if err != nil {
+		return err
+	}
+	level.Set(l)
+	return nil
+}
+
+// ParseLevel parses one of Levels. An empty level is info.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (want %s)", s, strings.Join(Levels, ", "))
+}
+
+// For returns the default logger tagged with component, such as "git" or
+// "branch-rename". It is looked up on every call so that records written
+// before Setup and after it both reach the current destination.
+func For(component string) *slog.Logger {
+	return slog.Default().With("component", component)
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want slog.Level
+	}{
+		{"", slog.LevelInfo},
+		{"debug", slog.LevelDebug},
+		{"WARN", slog.LevelWarn},
+		{"error", slog.LevelError},
+	}
+	for _, tt := range tests {
+		if got, err := ParseLevel(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel(verbose) should fail")
+	}
+}
+
+func setupBuffer(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	prev := slog.Default()
+	t.Cleanup(func() {
+		slog.SetDefault(prev)
+		level.Set(slog.LevelInfo)
+	})
+	var buf bytes.Buffer
+	Setup(&buf)
+	return &buf
+}
+
+func TestSetup_LevelAndComponent(t *testing.T) {
+	t.Setenv(EnvLevel, "")
+	buf := setupBuffer(t)
+
+	For("git").Debug("ran git")
+	For("tui").Warn("saving failed", "err", "disk full")
+	log.Printf("from the log package")
+	out := buf.String()
+	if strings.Contains(out, "ran git") {
+		t.Errorf("debug should be off at the default level:\n%s", out)
+	}
+	for _, want := range []string{"level=WARN", "component=tui", `err="disk full"`, "from the log package"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log lacks %q:\n%s", want, out)
+		}
+	}
+
+	if err := SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	For("git").Debug("ran git")
+	if !strings.Contains(buf.String(), "component=git") {
+		t.Errorf("debug should be on after SetLevel:\n%s", buf.String())
+	}
+	if err := SetLevel("verbose"); err == nil {
+		t.Error("an invalid level should be an error")
+	}
+}
+
+func TestSetLevel_EnvOverridesConfig(t *testing.T) {
+	t.Setenv(EnvLevel, "error")
+	buf := setupBuffer(t)
+
+	if err := SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	For("tui").Warn("dropped")
+	For("tui").Error("kept")
+	if out := buf.String(); strings.Contains(out, "dropped") || !strings.Contains(out, "kept") {
+		t.Errorf("%s should win over log_level:\n%s", EnvLevel, out)
+	}
+}
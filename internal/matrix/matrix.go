@@ -0,0 +1,83 @@
+// Package matrix runs one command in every worktree of a repository at once,
+// e.g. to check that a shared refactor still builds on each in-flight branch.
+package matrix
+
+import (
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// Runner runs name with args in dir and returns its combined output.
+type Runner func(dir, name string, args ...string) (string, error)
+
+// OSRunner runs the command via os/exec.
+func OSRunner(dir, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// Target is a worktree to run the command in.
+type Target struct {
+	Branch string
+	Path   string
+}
+
+// Result is the outcome of the command in one worktree.
+type Result struct {
+	Target
+	Output  string
+	Err     error
+	Elapsed time.Duration
+}
+
+// Passed reports whether the command exited successfully.
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// Targets lists the non-bare worktrees of group.
+func Targets(group model.RepoGroup) []Target {
+	var targets []Target
+	for _, wt := range group.Worktrees {
+		if wt.IsBare {
+			continue
+		}
+		targets = append(targets, Target{Branch: wt.Branch, Path: wt.Path})
+	}
+	return targets
+}
+
+// Run runs argv in every target, at most jobs at a time (at least one).
+// onDone, when non-nil, is called with each result as soon as it finishes;
+// calls are never concurrent. Results follow the order of targets.
+func Run(run Runner, targets []Target, argv []string, jobs int, onDone func(Result)) []Result {
+	results := make([]Result, len(targets))
+	sem := make(chan struct{}, max(jobs, 1))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			start := time.Now()
+			out, err := run(t.Path, argv[0], argv[1:]...)
+			r := Result{Target: t, Output: out, Err: err, Elapsed: time.Since(start)}
+			results[i] = r
+			if onDone != nil {
+				mu.Lock()
+				onDone(r)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
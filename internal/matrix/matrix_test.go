@@ -0,0 +1,66 @@
+package matrix
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestTargets(t *testing.T) {
+	group := model.RepoGroup{Worktrees: []model.WorktreeInfo{
+		{Path: "/repo", IsBare: true},
+		{Path: "/wt/a", Branch: "a"},
+	}}
+	if got := Targets(group); len(got) != 1 || got[0] != (Target{Branch: "a", Path: "/wt/a"}) {
+		t.Errorf("Targets = %+v, want only /wt/a", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	var running, peak atomic.Int32
+	run := func(dir, name string, args ...string) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if name != "go" || !slices.Equal(args, []string{"build", "./..."}) {
+			t.Errorf("ran %s %v", name, args)
+		}
+		if dir == "/wt/b" {
+			return "b.go:1: undefined: Foo\n", errors.New("exit status 1")
+		}
+		return "", nil
+	}
+	targets := []Target{{Branch: "a", Path: "/wt/a"}, {Branch: "b", Path: "/wt/b"}, {Branch: "c", Path: "/wt/c"}}
+
+	var mu sync.Mutex
+	var streamed []string
+	results := Run(run, targets, []string{"go", "build", "./..."}, 2, func(r Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		streamed = append(streamed, r.Path)
+	})
+
+	if peak.Load() > 2 {
+		t.Errorf("%d commands ran at once, want at most 2", peak.Load())
+	}
+	if len(streamed) != 3 {
+		t.Errorf("streamed %v, want every worktree", streamed)
+	}
+	if len(results) != 3 || results[0].Path != "/wt/a" || results[2].Path != "/wt/c" {
+		t.Fatalf("results = %+v, want the order of targets", results)
+	}
+	if !results[0].Passed() || results[1].Passed() || results[1].Output == "" {
+		t.Errorf("results = %+v, want only b to fail with its output", results)
+	}
+}
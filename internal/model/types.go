@@ -1,11 +1,127 @@
 package model
 
+import (
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
 // Config represents the application configuration loaded from YAML.
 type Config struct {
 	SidebarWidth     int             `yaml:"sidebar_width"`
 	DefaultBaseRef   string          `yaml:"default_base_ref"`
 	Repositories     []RepositoryDef `yaml:"repositories"`
 	WorktreeBasePath string          `yaml:"worktree_base_path"`
+	GitHubToken      string          `yaml:"github_token,omitempty"`
+	SessionPrefix    string          `yaml:"session_prefix,omitempty"`
+	TrashDir         string          `yaml:"trash_dir,omitempty"`
+	DiffBase         string          `yaml:"diff_base,omitempty"`
+	AutoWIP          string          `yaml:"auto_wip,omitempty"`
+	Paranoid         bool            `yaml:"paranoid,omitempty"`
+	// ScanPaths are directories searched for git repositories on load. Those
+	// found are listed after Repositories, named after their directory.
+	ScanPaths []string `yaml:"scan_paths,omitempty"`
+	// Include lists config files merged under this one, relative to it.
+	// Profiles names sets of such files, of which --profile picks one.
+	Include  []string            `yaml:"include,omitempty"`
+	Profiles map[string][]string `yaml:"profiles,omitempty"`
+	// TmuxMode is "session" for a tmux session per worktree or "window" for
+	// a window per worktree in the main session.
+	TmuxMode string `yaml:"tmux_mode,omitempty"`
+	// SessionNameTemplate names worktree sessions, e.g. "{{.Repo}}-{{.Slug}}".
+	SessionNameTemplate string `yaml:"session_name_template,omitempty"`
+	// PortBase turns on port allocation: the session of each worktree gets
+	// its own block of PortBlockSize ports from here as PORT, PORT_2 and so on.
+	PortBase      int `yaml:"port_base,omitempty"`
+	PortBlockSize int `yaml:"port_block_size,omitempty"`
+	// AgentPollInterval is how often the sidebar polls tmux for agent
+	// status, as a Go duration such as "2s".
+	AgentPollInterval string `yaml:"agent_poll_interval,omitempty"`
+	// Agents adds coding agents to detect in tmux panes, or replaces the
+	// built-in profile of the same name.
+	Agents []AgentProfileDef `yaml:"agents,omitempty"`
+	// Notifications picks how the sidebar tells that an agent needs input.
+	Notifications NotificationConfig `yaml:"notifications,omitempty"`
+	// Keybindings rebinds keys per mode, e.g. sidebar: {archive: x}.
+	Keybindings map[string]map[string]KeyList `yaml:"keybindings,omitempty"`
+	Theme       ThemeConfig                   `yaml:"theme,omitempty"`
+	// Hooks run for worktrees of every repository that sets none of its own.
+	Hooks Hooks `yaml:"hooks,omitempty"`
+	// Templates are offered when adding a worktree, to provision kinds of
+	// worktrees such as "bugfix" and "experiment" differently.
+	Templates []WorktreeTemplate `yaml:"templates,omitempty"`
+	// CheckForUpdates looks up the latest yakumo release on GitHub once a
+	// day and tells in the sidebar when it is newer than this build.
+	CheckForUpdates bool `yaml:"check_for_updates,omitempty"`
+	// LogLevel is the least severe level written to debug.log: debug, info,
+	// warn or error. $YAKUMO_LOG_LEVEL overrides it.
+	LogLevel string `yaml:"log_level,omitempty"`
+	// BranchNamer picks the model that names branches after the first
+	// prompt.
+	BranchNamer BranchNamerConfig `yaml:"branch_namer,omitempty"`
+}
+
+// BranchNamerConfig selects the LLM behind branch naming: the claude CLI, an
+// OpenAI-compatible API or a local Ollama. Empty fields take the provider's
+// defaults.
+type BranchNamerConfig struct {
+	Provider  string `yaml:"provider,omitempty"` // claude, openai or ollama
+	Model     string `yaml:"model,omitempty"`
+	Endpoint  string `yaml:"endpoint,omitempty"`    // base URL of the API
+	APIKeyEnv string `yaml:"api_key_env,omitempty"` // variable holding the API key
+	// Prompt replaces the instructions sent before the task description.
+	Prompt string `yaml:"prompt,omitempty"`
+	// Template makes the branch name from .Slug, .Prefix and .Ticket, e.g.
+	// "{{.Ticket}}-{{.Slug}}".
+	Template      string `yaml:"template,omitempty"`
+	Prefix        string `yaml:"prefix,omitempty"`         // keep or none
+	MaxLength     int    `yaml:"max_length,omitempty"`     // of the slug, 30 when unset
+	Charset       string `yaml:"charset,omitempty"`        // a regexp character class, e.g. "a-z0-9_-"
+	TicketPattern string `yaml:"ticket_pattern,omitempty"` // finds .Ticket in the prompt
+}
+
+// WorktreeTemplate changes how a worktree is created and set up. Unset
+// fields keep the repository's settings; copy and symlink patterns are added
+// to the repository's.
+type WorktreeTemplate struct {
+	Name            string            `yaml:"name"`
+	BaseRef         string            `yaml:"base_ref,omitempty"`
+	BranchPrefix    string            `yaml:"branch_prefix,omitempty"` // instead of the git user name
+	CopyOnCreate    []string          `yaml:"copy_on_create,omitempty"`
+	SymlinkOnCreate []string          `yaml:"symlink_on_create,omitempty"`
+	StartupCommand  string            `yaml:"startup_command,omitempty"`
+	Panes           map[string]string `yaml:"panes,omitempty"`
+}
+
+// Hooks are shell commands run in a worktree at points of its life, with
+// YAKUMO_WORKTREE, YAKUMO_BRANCH, YAKUMO_REPO and YAKUMO_REPO_NAME set.
+type Hooks struct {
+	PostCreate string `yaml:"post_create,omitempty"` // after the worktree is added
+	PreArchive string `yaml:"pre_archive,omitempty"` // before it is archived; a failure keeps it
+}
+
+// ThemeConfig picks the colors of both UIs: a built-in preset, with
+// individual colors (e.g. accent: "#ff8800") overridden on top of it.
+type ThemeConfig struct {
+	Preset string            `yaml:"preset,omitempty"`
+	Colors map[string]string `yaml:"colors,omitempty"`
+}
+
+// KeyList is the keys bound to one action. In YAML it is either a single key
+// or a list of keys; an empty list unbinds the action.
+type KeyList []string
+
+func (k *KeyList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*k = KeyList{node.Value}
+		return nil
+	}
+	var keys []string
+	if err := node.Decode(&keys); err != nil {
+		return err
+	}
+	*k = keys
+	return nil
 }
 
 // RepositoryDef represents a repository entry from config.
@@ -14,6 +130,53 @@ type RepositoryDef struct {
 	Path           string   `yaml:"path"`
 	StartupCommand string   `yaml:"startup_command,omitempty"`
 	RbCommands     []string `yaml:"rb_commands,omitempty"`
+	Forge          string   `yaml:"forge,omitempty"`
+	Sort           string   `yaml:"sort,omitempty"`
+	DevLog         bool     `yaml:"dev_log,omitempty"`
+	// WorktreeBasePath replaces the top-level worktree_base_path for this
+	// repository, e.g. to keep its worktrees on another disk.
+	WorktreeBasePath string `yaml:"worktree_base_path,omitempty"`
+	// BaseRef replaces default_base_ref for this repository, e.g.
+	// origin/develop for one that branches off develop.
+	BaseRef string `yaml:"base_ref,omitempty"`
+	// Tasks are named commands, such as test: go test ./..., run from the
+	// sidebar's task picker in a pane of the worktree's session.
+	Tasks map[string]string `yaml:"tasks,omitempty"`
+	// Panes maps a pane of a new session (center, top_right, bottom_right,
+	// center_2, center_3, bottom_right_2, bottom_right_3) to the command to
+	// start in it, e.g. bottom_right: npm run dev.
+	Panes map[string]string `yaml:"panes,omitempty"`
+	// Env is exported into every pane of a new session. Values are
+	// templates with {{.Repo}} and {{.Slug}}, the worktree directory name,
+	// e.g. DATABASE_URL: postgres://localhost/app_{{.Slug}}.
+	Env map[string]string `yaml:"env,omitempty"`
+	// CopyOnCreate and SymlinkOnCreate are glob patterns of untracked paths
+	// in the repository, such as .env or node_modules, that a new worktree
+	// gets a copy of or a symlink to.
+	CopyOnCreate    []string `yaml:"copy_on_create,omitempty"`
+	SymlinkOnCreate []string `yaml:"symlink_on_create,omitempty"`
+	// Hooks overrides the top-level hooks for this repository, per hook.
+	Hooks Hooks `yaml:"hooks,omitempty"`
+}
+
+// NotificationConfig lists the channels ("desktop", "tmux") to notify on
+// for each agent transition. Unset means desktop only; an empty list turns
+// the notification off.
+type NotificationConfig struct {
+	Waiting []string `yaml:"waiting,omitempty"` // an agent asks for permission
+	Idle    []string `yaml:"idle,omitempty"`    // an agent finished running
+}
+
+// AgentProfileDef describes how to spot a coding agent in a tmux pane and
+// read its state. Every pattern is a regular expression; running patterns
+// may capture the elapsed time in a group named "elapsed".
+type AgentProfileDef struct {
+	Name      string   `yaml:"name"`
+	Processes []string `yaml:"processes,omitempty"` // whole pane_current_command, case-insensitive
+	Title     string   `yaml:"title,omitempty"`     // pane title
+	Running   []string `yaml:"running,omitempty"`
+	Waiting   []string `yaml:"waiting,omitempty"`
+	Prompt    string   `yaml:"prompt,omitempty"` // the agent is idle, waiting for input
 }
 
 // RepoGroup represents a repository and all its discovered worktrees.
@@ -25,16 +188,21 @@ type RepoGroup struct {
 
 // WorktreeInfo represents a single git worktree with its status.
 type WorktreeInfo struct {
-	Path   string
-	Branch string
-	Status StatusInfo
-	IsBare bool
+	Path        string
+	Head        string // the commit checked out, from `git worktree list`
+	Branch      string
+	Status      StatusInfo
+	IsBare      bool
+	Description string    // branch.<name>.description, first line only
+	CreatedAt   time.Time // when the worktree was added; zero for the main worktree
 }
 
-// StatusInfo holds the aggregated line change counts for a worktree.
+// StatusInfo holds the aggregated line change and commit counts for a worktree.
 type StatusInfo struct {
 	Insertions int
 	Deletions  int
+	Ahead      int // commits on the branch not in the base ref
+	Behind     int // commits on the base ref not in the branch
 }
 
 // AgentState represents the current state of a Claude Code agent in a tmux pane.
@@ -47,13 +215,39 @@ const (
 	AgentStateWaiting                   // Waiting for user permission/confirmation
 )
 
-// AgentInfo holds the detected status of a Claude Code instance in a single pane.
+// AgentInfo holds the detected status of a coding agent in a single pane.
 type AgentInfo struct {
 	PaneID  string
+	Agent   string // name of the matching profile, e.g. "claude"
 	State   AgentState
 	Elapsed string // e.g. "2m 30s", populated only when Running
 }
 
+// AgentEvent is a change in the state of the agent in one pane, recorded for
+// the agent activity timeline.
+type AgentEvent struct {
+	PaneID string     `json:"pane"`
+	Agent  string     `json:"agent,omitempty"`
+	State  AgentState `json:"state"` // AgentStateNone once the agent has gone
+	At     time.Time  `json:"at"`
+}
+
+// CheckState is the combined CI result of a pull request.
+type CheckState int
+
+const (
+	ChecksNone    CheckState = iota // No checks reported
+	ChecksPending                   // At least one check still running
+	ChecksPassing                   // Every check succeeded
+	ChecksFailing                   // At least one check failed
+)
+
+// PRStatus summarizes the open pull request of a worktree's branch.
+type PRStatus struct {
+	Number int // 0 when the branch has no open PR
+	Checks CheckState
+}
+
 // ItemKind identifies what type of navigation item this is.
 type ItemKind int
 
@@ -97,4 +291,14 @@ type NavigableItem struct {
 	Status       StatusInfo
 	AgentStatus  []AgentInfo
 	IsBare       bool
+	Description  string
+	Highlight    []int  // rune indexes of Label matched by the sidebar filter
+	SortLabel    string // group headers: the sort mode, when not the default order
+	Collapsed    bool   // group headers: the group's worktrees are hidden
+	BaseRed      string // group headers: the base branch, when its latest CI run failed
+	PR           PRStatus
+	Marked       bool   // worktrees: marked in the sidebar's select mode
+	Pinned       bool   // worktrees: pinned to the top of the group
+	RecentRepo   string // worktrees in the Recent section: the repository's name
+	Ports        string // worktrees: the assigned port block, e.g. "4010-4019"
 }
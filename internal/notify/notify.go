@@ -0,0 +1,90 @@
+// Package notify shows desktop notifications through whichever platform
+// tool is installed.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnavailable is returned when no notification tool is installed.
+var ErrUnavailable = errors.New("no notification tool available")
+
+// Channel is a way of telling the user about an agent: a desktop
+// notification, or a message in the tmux status line.
+type Channel string
+
+const (
+	Desktop Channel = "desktop"
+	Tmux    Channel = "tmux"
+)
+
+// DefaultChannels are used for a state with no notifications setting.
+var DefaultChannels = []Channel{Desktop}
+
+// ParseChannels checks the channel names of a notifications setting.
+func ParseChannels(names []string) ([]Channel, error) {
+	channels := make([]Channel, 0, len(names))
+	for _, name := range names {
+		switch c := Channel(name); c {
+		case Desktop, Tmux:
+			channels = append(channels, c)
+		default:
+			return nil, fmt.Errorf("unknown channel %q: must be %q or %q", name, Desktop, Tmux)
+		}
+	}
+	return channels, nil
+}
+
+// Notifier abstracts desktop notifications for testability.
+type Notifier interface {
+	Notify(title, body string) error
+}
+
+// OSNotifier notifies with osascript on macOS and notify-send elsewhere.
+type OSNotifier struct{}
+
+// command returns the command that shows a notification on goos.
+func command(goos, title, body string) []string {
+	if goos == "darwin" {
+		return []string{"osascript", "-e", fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))}
+	}
+	return []string{"notify-send", "--app-name=yakumo", title, body}
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (n OSNotifier) Notify(title, body string) error {
+	args := command(runtime.GOOS, title, body)
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return ErrUnavailable
+	}
+	if out, err := exec.Command(path, args[1:]...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Notification is a notification sent to a FakeNotifier.
+type Notification struct {
+	Title string
+	Body  string
+}
+
+// FakeNotifier is a test double that records what it was sent.
+type FakeNotifier struct {
+	Sent []Notification
+	Err  error
+}
+
+func (n *FakeNotifier) Notify(title, body string) error {
+	n.Sent = append(n.Sent, Notification{Title: title, Body: body})
+	return n.Err
+}
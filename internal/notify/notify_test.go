@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	got := command("darwin", "yakumo", `fix "login" needs input`)
+	want := []string{"osascript", "-e", `display notification "fix \"login\" needs input" with title "yakumo"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("darwin command = %q, want %q", got, want)
+	}
+
+	got = command("linux", "yakumo", "done")
+	want = []string{"notify-send", "--app-name=yakumo", "yakumo", "done"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("linux command = %q, want %q", got, want)
+	}
+}
+
+func TestParseChannels(t *testing.T) {
+	got, err := ParseChannels([]string{"desktop", "tmux"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []Channel{Desktop, Tmux}) {
+		t.Errorf("ParseChannels = %v", got)
+	}
+
+	if _, err := ParseChannels([]string{"email"}); err == nil {
+		t.Error("expected an error for an unknown channel")
+	}
+}
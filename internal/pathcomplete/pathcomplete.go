@@ -2,6 +2,7 @@ package pathcomplete
 
 import (
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 )
@@ -19,6 +20,24 @@ func DefaultDirLister(path string) ([]os.DirEntry, error) {
 // Suggestions include a trailing "/" so the user can continue typing the next segment.
 // If the input starts with ~/, suggestions also use the ~/ prefix.
 func ListDirSuggestions(input, homeDir string, lister DirLister, maxResults int) []string {
+	return ListFilteredDirSuggestions(input, homeDir, lister, nil, maxResults)
+}
+
+// DirFilter reports whether the directory at path should be suggested.
+type DirFilter func(path string) bool
+
+// HasGitEntry reports whether the directory at path contains a .git entry,
+// a directory in a repository or a file in a linked worktree.
+func HasGitEntry(path string) bool {
+	_, err := os.Lstat(filepath.Join(path, ".git"))
+	return err == nil
+}
+
+// ListFilteredDirSuggestions is ListDirSuggestions keeping only the
+// directories keep accepts, or all of them when keep is nil. keep is called
+// lazily, in name order on the directories matching the input, until
+// maxResults are found, so a large directory costs no more than needed.
+func ListFilteredDirSuggestions(input, homeDir string, lister DirLister, keep DirFilter, maxResults int) []string {
 	if input == "" {
 		return nil
 	}
@@ -33,6 +52,7 @@ func ListDirSuggestions(input, homeDir string, lister DirLister, maxResults int)
 	if err != nil {
 		return nil
 	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
 
 	// Ensure dir has trailing slash for path construction.
 	dirSlash := dir
@@ -42,6 +62,9 @@ func ListDirSuggestions(input, homeDir string, lister DirLister, maxResults int)
 
 	var suggestions []string
 	for _, entry := range entries {
+		if len(suggestions) == maxResults {
+			break
+		}
 		if !entry.IsDir() {
 			continue
 		}
@@ -49,6 +72,9 @@ func ListDirSuggestions(input, homeDir string, lister DirLister, maxResults int)
 		if prefix != "" && !strings.HasPrefix(name, prefix) {
 			continue
 		}
+		if keep != nil && !keep(dirSlash+name) {
+			continue
+		}
 
 		fullPath := dirSlash + name + "/"
 		if useTilde {
@@ -63,10 +89,6 @@ func ListDirSuggestions(input, homeDir string, lister DirLister, maxResults int)
 	}
 
 	sort.Strings(suggestions)
-	if len(suggestions) > maxResults {
-		suggestions = suggestions[:maxResults]
-	}
-
 	return suggestions
 }
 
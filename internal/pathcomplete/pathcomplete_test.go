@@ -3,6 +3,8 @@ package pathcomplete
 import (
 	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -222,3 +224,48 @@ func TestExtractDir_TildeOnly(t *testing.T) {
 		t.Errorf("expected %q, got %q", "/home/user/", result)
 	}
 }
+
+func TestListFilteredDirSuggestions(t *testing.T) {
+	lister := fakeLister(map[string][]os.DirEntry{
+		"/code": {
+			fakeDirEntry{name: "web", isDir: true},
+			fakeDirEntry{name: "notes", isDir: true},
+			fakeDirEntry{name: "api", isDir: true},
+			fakeDirEntry{name: "app", isDir: true},
+			fakeDirEntry{name: "archive", isDir: true},
+		},
+	})
+	repos := map[string]bool{"/code/api": true, "/code/app": true, "/code/web": true}
+	var checked []string
+	keep := func(path string) bool {
+		checked = append(checked, path)
+		return repos[path]
+	}
+
+	result := ListFilteredDirSuggestions("/code/a", "/home/user", lister, keep, 10)
+	if strings.Join(result, ",") != "/code/api/,/code/app/" {
+		t.Errorf("result = %v, want the repositories matching the input", result)
+	}
+	if strings.Join(checked, ",") != "/code/api,/code/app,/code/archive" {
+		t.Errorf("checked %v, want only the directories matching the input", checked)
+	}
+
+	checked = nil
+	result = ListFilteredDirSuggestions("/code/", "/home/user", lister, keep, 1)
+	if strings.Join(result, ",") != "/code/api/" || len(checked) != 1 {
+		t.Errorf("result = %v after checking %v, want to stop at maxResults", result, checked)
+	}
+}
+
+func TestHasGitEntry(t *testing.T) {
+	dir := t.TempDir()
+	if HasGitEntry(dir) {
+		t.Error("a directory without .git is not a repository")
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git"), []byte("gitdir: /elsewhere\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !HasGitEntry(dir) {
+		t.Error("a .git file, as in a linked worktree, counts")
+	}
+}
@@ -0,0 +1,102 @@
+// Package ports hands each worktree its own block of ports, so the dev
+// servers of worktrees running side by side do not clash.
+package ports
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// DefaultBlockSize is how many ports a worktree gets without a
+// port_block_size setting.
+const DefaultBlockSize = 10
+
+// maxPort is the highest TCP port.
+const maxPort = 65535
+
+// Block is a range of Size ports starting at Start.
+type Block struct {
+	Start int
+	Size  int
+}
+
+// String returns the range, e.g. "4010-4019".
+func (b Block) String() string {
+	if b.Size <= 1 {
+		return strconv.Itoa(b.Start)
+	}
+	return fmt.Sprintf("%d-%d", b.Start, b.Start+b.Size-1)
+}
+
+// Env returns the ports as environment variables: PORT for the first, then
+// PORT_2, PORT_3 and so on.
+func (b Block) Env() map[string]string {
+	env := make(map[string]string, b.Size)
+	for i := range b.Size {
+		name := "PORT"
+		if i > 0 {
+			name = "PORT_" + strconv.Itoa(i+1)
+		}
+		env[name] = strconv.Itoa(b.Start + i)
+	}
+	return env
+}
+
+// Store persists the first port of each worktree's block.
+type Store interface {
+	Get() map[string]int
+	Save(blocks map[string]int) error
+}
+
+// Blocks returns the blocks of size ports assigned in store, keyed by
+// worktree path.
+func Blocks(store Store, size int) map[string]Block {
+	blocks := make(map[string]Block)
+	for path, start := range store.Get() {
+		blocks[path] = Block{Start: start, Size: size}
+	}
+	return blocks
+}
+
+// Assign returns the block of the worktree at worktreePath, assigning it the
+// lowest free block of size ports from base on first use. Blocks of
+// worktrees that no longer exist are freed first.
+func Assign(store Store, worktreePath string, base, size int) (Block, error) {
+	blocks := store.Get()
+	if start, ok := blocks[worktreePath]; ok {
+		return Block{Start: start, Size: size}, nil
+	}
+	for path := range blocks {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			delete(blocks, path)
+		}
+	}
+
+	start := base
+	for ; start+size-1 <= maxPort; start += size {
+		if free(blocks, start, size) {
+			break
+		}
+	}
+	if start+size-1 > maxPort {
+		return Block{}, fmt.Errorf("no free block of %d ports from %d", size, base)
+	}
+	blocks[worktreePath] = start
+	if err := store.Save(blocks); err != nil {
+		return Block{}, err
+	}
+	return Block{Start: start, Size: size}, nil
+}
+
+// free reports whether the block of size ports at start overlaps none of
+// blocks.
+func free(blocks map[string]int, start, size int) bool {
+	for _, other := range blocks {
+		if other < start+size && start < other+size {
+			return false
+		}
+	}
+	return true
+}
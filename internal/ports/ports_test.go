@@ -0,0 +1,67 @@
+package ports
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type memStore struct{ blocks map[string]int }
+
+func (s *memStore) Get() map[string]int { return maps.Clone(s.blocks) }
+
+func (s *memStore) Save(blocks map[string]int) error {
+	s.blocks = blocks
+	return nil
+}
+
+func TestAssign(t *testing.T) {
+	dir := t.TempDir()
+	a, b, c := filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")
+	for _, path := range []string{a, b, c} {
+		if err := os.Mkdir(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := &memStore{blocks: map[string]int{
+		a:                          4000,
+		filepath.Join(dir, "gone"): 4010,
+	}}
+
+	got, err := Assign(store, a, 4000, 10)
+	if err != nil || got != (Block{Start: 4000, Size: 10}) {
+		t.Fatalf("Assign(a) = %+v, %v, want the block it already has", got, err)
+	}
+
+	// The block of the removed worktree is free again.
+	got, err = Assign(store, b, 4000, 10)
+	if err != nil || got.Start != 4010 {
+		t.Fatalf("Assign(b) = %+v, %v, want 4010", got, err)
+	}
+	got, err = Assign(store, c, 4000, 10)
+	if err != nil || got.Start != 4020 {
+		t.Fatalf("Assign(c) = %+v, %v, want 4020", got, err)
+	}
+	if len(store.blocks) != 3 {
+		t.Errorf("store = %v, want a, b and c", store.blocks)
+	}
+
+	if _, err := Assign(&memStore{blocks: map[string]int{a: 65530}}, b, 65530, 10); err == nil {
+		t.Error("expected an error when no block fits below 65536")
+	}
+}
+
+func TestBlock(t *testing.T) {
+	b := Block{Start: 4010, Size: 3}
+	if got := b.String(); got != "4010-4012" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (Block{Start: 4010, Size: 1}).String(); got != "4010" {
+		t.Errorf("String() of one port = %q", got)
+	}
+	want := map[string]string{"PORT": "4010", "PORT_2": "4011", "PORT_3": "4012"}
+	if got := b.Env(); !maps.Equal(got, want) {
+		t.Errorf("Env() = %v, want %v", got, want)
+	}
+}
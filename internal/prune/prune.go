@@ -0,0 +1,132 @@
+// Package prune cleans up what deleted worktree directories leave behind:
+// git's worktree metadata and the tmux sessions that were started in them.
+package prune
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mikanfactory/yakumo/internal/audit"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+// Report lists what a run removed.
+type Report struct {
+	Worktrees []string // worktree paths whose metadata was pruned
+	Sessions  []string // killed tmux sessions
+}
+
+// Empty reports whether nothing was stale.
+func (r Report) Empty() bool {
+	return len(r.Worktrees) == 0 && len(r.Sessions) == 0
+}
+
+func (r Report) String() string {
+	if r.Empty() {
+		return "Nothing to prune."
+	}
+	var b strings.Builder
+	for _, path := range r.Worktrees {
+		fmt.Fprintf(&b, "pruned worktree %s\n", path)
+	}
+	for _, name := range r.Sessions {
+		fmt.Fprintf(&b, "killed session %s\n", name)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Run prunes the worktree metadata of each repository, then kills the tmux
+// sessions whose start directory no longer exists and was either a pruned
+// worktree or inside one of worktreeBases. Other sessions, including the main one and
+// those of other users sharing the server, are never touched. tmuxRunner may
+// be nil, and a missing tmux server is not an error. Failures are collected and the rest carries on.
+func Run(runner git.CommandRunner, tmuxRunner tmux.Runner, repoPaths []string, worktreeBases []string, auditLog audit.Log) (Report, error) {
+	var report Report
+	var errs []error
+	for _, repo := range repoPaths {
+		pruned, err := git.PruneWorktrees(runner, repo)
+		for _, path := range pruned {
+			auditLog.Record(audit.Event{Op: audit.OpPrune, Repo: repo, Path: path}, nil)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", repo, err))
+		}
+		report.Worktrees = append(report.Worktrees, pruned...)
+	}
+
+	if tmuxRunner != nil {
+		sessions, err := orphanedSessions(tmuxRunner, report.Worktrees, worktreeBases)
+		if err != nil && !tmux.IsUnavailable(err) {
+			errs = append(errs, fmt.Errorf("listing tmux sessions: %w", err))
+		}
+		for _, s := range sessions {
+			err := tmux.KillWorktree(tmuxRunner, s.name)
+			auditLog.Record(audit.Event{Op: audit.OpKillSession, Path: s.path, Detail: s.name}, err)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("killing session %s: %w", s.name, err))
+				continue
+			}
+			report.Sessions = append(report.Sessions, s.name)
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// StaleSessions returns the sessions Run would kill for worktrees deleted
+// from worktreeBases, without killing them.
+func StaleSessions(tmuxRunner tmux.Runner, worktreeBases []string) ([]string, error) {
+	sessions, err := orphanedSessions(tmuxRunner, nil, worktreeBases)
+	names := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		names = append(names, s.name)
+	}
+	return names, err
+}
+
+type session struct {
+	name string
+	path string
+}
+
+func orphanedSessions(tmuxRunner tmux.Runner, pruned []string, worktreeBases []string) ([]session, error) {
+	paths, err := tmux.WorktreePaths(tmuxRunner)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(pruned))
+	for _, p := range pruned {
+		known[filepath.Clean(p)] = true
+	}
+	prefix := tmux.SessionName("")
+
+	var orphaned []session
+	for name, path := range paths {
+		if name == tmux.MainSession() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		path = filepath.Clean(path)
+		if !known[path] && !slices.ContainsFunc(worktreeBases, func(base string) bool { return within(base, path) }) {
+			continue
+		}
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		orphaned = append(orphaned, session{name: name, path: path})
+	}
+	slices.SortFunc(orphaned, func(a, b session) int { return strings.Compare(a.name, b.name) })
+	return orphaned, nil
+}
+
+// within reports whether path is strictly inside dir.
+func within(dir, path string) bool {
+	if dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, "../")
+}
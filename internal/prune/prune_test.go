@@ -0,0 +1,86 @@
+package prune
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/audit"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+func TestRun(t *testing.T) {
+	base := t.TempDir()
+	elsewhere := t.TempDir()
+	gone := filepath.Join(elsewhere, "gone")
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[worktree list --porcelain]": "worktree /repo\nbranch refs/heads/main\n\n" +
+				"worktree " + gone + "\nbranch refs/heads/gone\nprunable gitdir file points to non-existent location\n\n" +
+				"worktree /trash/x\nbranch refs/heads/x\nlocked archived by yakumo\nprunable gitdir file points to non-existent location\n",
+			"/repo:[worktree prune]": "",
+		},
+		Errors: map[string]error{"/broken:[worktree list --porcelain]": errors.New("not a git repository")},
+	}
+	tmuxRunner := &tmux.FakeRunner{
+		Outputs: map[string]string{
+			"[list-sessions -F #{session_name}\t#{session_path}]": strings.Join([]string{
+				"yakumo-main\t" + filepath.Join(base, "missing-main"),
+				"gone\t" + gone, // a pruned worktree
+				"old-feature\t" + filepath.Join(base, "old-feature"), // inside the worktree base
+				"alive\t" + base,
+				"personal\t" + filepath.Join(elsewhere, "deleted-by-hand"), // not yakumo's
+			}, "\n"),
+			"[kill-session -t =gone]":        "",
+			"[kill-session -t =old-feature]": "",
+		},
+	}
+	auditLog := audit.Log{Path: filepath.Join(t.TempDir(), "audit.jsonl")}.From(audit.SourceCLI)
+
+	report, err := Run(runner, tmuxRunner, []string{"/repo", "/broken"}, []string{base}, auditLog)
+
+	if err == nil || !strings.Contains(err.Error(), "/broken: not a git repository") {
+		t.Errorf("err = %v, want the broken repository reported", err)
+	}
+	if fmt.Sprint(report.Worktrees) != fmt.Sprint([]string{gone}) {
+		t.Errorf("Worktrees = %v, want only the unlocked stale one", report.Worktrees)
+	}
+	if fmt.Sprint(report.Sessions) != "[gone old-feature]" {
+		t.Errorf("Sessions = %v", report.Sessions)
+	}
+	if events, _ := auditLog.Read(audit.Filter{}); len(events) != 3 {
+		t.Errorf("audit events = %+v, want one prune and two kills", events)
+	}
+}
+
+func TestStaleSessions(t *testing.T) {
+	base := t.TempDir()
+	repoBase := t.TempDir() // a repository's own worktree_base_path
+	tmuxRunner := &tmux.FakeRunner{
+		Outputs: map[string]string{
+			"[list-sessions -F #{session_name}\t#{session_path}]": "old-feature\t" + filepath.Join(base, "old-feature") + "\nalive\t" + base +
+				"\nother-disk\t" + filepath.Join(repoBase, "other-disk"),
+		},
+	}
+
+	names, err := StaleSessions(tmuxRunner, []string{base, repoBase})
+	if err != nil || fmt.Sprint(names) != "[old-feature other-disk]" {
+		t.Errorf("StaleSessions = %v, %v; want [old-feature other-disk]", names, err)
+	}
+	if len(tmuxRunner.Calls) != 1 {
+		t.Errorf("calls = %v, want only the listing", tmuxRunner.Calls)
+	}
+}
+
+func TestReportString(t *testing.T) {
+	if got := (Report{}).String(); got != "Nothing to prune." {
+		t.Errorf("empty = %q", got)
+	}
+	got := Report{Worktrees: []string{"/wt/a"}, Sessions: []string{"a"}}.String()
+	if got != "pruned worktree /wt/a\nkilled session a" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,188 @@
+// Package release prepares a version tag for the primary worktree of a
+// GitHub repository: the next semver, release notes drafted from the PRs
+// merged since the last tag, and the page that publishes the release.
+package release
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+)
+
+// ErrNotPrimaryWorktree is returned when releasing from a linked worktree.
+var ErrNotPrimaryWorktree = errors.New("releases are tagged from the primary worktree")
+
+// Bump selects which part of the version is incremented.
+type Bump string
+
+const (
+	BumpPatch Bump = "patch"
+	BumpMinor Bump = "minor"
+	BumpMajor Bump = "major"
+)
+
+// Bumps lists the bumps in the order they are offered.
+var Bumps = []Bump{BumpPatch, BumpMinor, BumpMajor}
+
+// Version is a semver release version such as v1.2.3.
+type Version struct {
+	Prefix              string // "v" or ""
+	Major, Minor, Patch int
+}
+
+// ParseVersion parses a tag like "v1.2.3" or "1.2.3". Pre-release and build
+// suffixes are not release versions and are rejected.
+func ParseVersion(tag string) (Version, bool) {
+	v := Version{}
+	rest := tag
+	if after, ok := strings.CutPrefix(tag, "v"); ok {
+		v.Prefix, rest = "v", after
+	}
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return Version{}, false
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || p != strconv.Itoa(n) {
+			return Version{}, false
+		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+	return v, true
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+}
+
+// Bump returns the version after applying b.
+func (v Version) Bump(b Bump) Version {
+	switch b {
+	case BumpMajor:
+		return Version{Prefix: v.Prefix, Major: v.Major + 1}
+	case BumpMinor:
+		return Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor + 1}
+	default:
+		return Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+}
+
+// Less reports whether v comes before o. The prefix is ignored.
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+// Latest returns the first tag that is a release version, from tags sorted
+// highest first.
+func Latest(tags []string) (Version, string, bool) {
+	for _, tag := range tags {
+		if v, ok := ParseVersion(tag); ok {
+			return v, tag, true
+		}
+	}
+	return Version{}, "", false
+}
+
+// Plan is what a release starts from.
+type Plan struct {
+	Dir     string
+	Owner   string
+	Repo    string
+	Base    string
+	LastTag string  // "" for the first release
+	Current Version // v0.0.0 for the first release
+	PRs     []github.MergedPR
+}
+
+// Prepare checks that dir is the primary worktree with base checked out and
+// collects the last release tag and the PRs merged into base since then.
+func Prepare(runner git.CommandRunner, ghRunner github.Runner, dir, base string) (Plan, error) {
+	root, err := runner.Run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return Plan{}, err
+	}
+	mainPath, err := git.MainRepoPath(runner, dir)
+	if err != nil {
+		return Plan{}, err
+	}
+	if filepath.Clean(strings.TrimSpace(root)) != filepath.Clean(mainPath) {
+		return Plan{}, fmt.Errorf("%w (%s)", ErrNotPrimaryWorktree, mainPath)
+	}
+	if branch, err := git.CurrentBranch(runner, dir); err != nil || branch != base {
+		return Plan{}, fmt.Errorf("check out %s before releasing", base)
+	}
+
+	remote, err := git.RemoteURL(runner, dir)
+	if err != nil {
+		return Plan{}, fmt.Errorf("resolving origin remote: %w", err)
+	}
+	owner, repo, err := github.ParseRemoteURL(remote)
+	if err != nil {
+		return Plan{}, err
+	}
+	plan := Plan{Dir: dir, Owner: owner, Repo: repo, Base: base, Current: Version{Prefix: "v"}}
+
+	tags, err := git.ListTags(runner, dir)
+	if err != nil {
+		return Plan{}, fmt.Errorf("listing tags: %w", err)
+	}
+	var since time.Time
+	if v, tag, ok := Latest(tags); ok {
+		plan.Current, plan.LastTag = v, tag
+		if since, err = git.TagDate(runner, dir, tag); err != nil {
+			return Plan{}, err
+		}
+	}
+
+	if plan.PRs, err = github.FetchMergedPRs(ghRunner, dir, base, since); err != nil {
+		return Plan{}, fmt.Errorf("listing merged PRs: %w", err)
+	}
+	return plan, nil
+}
+
+// Notes drafts the release notes for tag: one line per merged PR and a link
+// to the full diff since the last release.
+func (p Plan) Notes(tag string) string {
+	var b strings.Builder
+	if len(p.PRs) == 0 {
+		fmt.Fprintf(&b, "No pull requests merged since %s.\n", cmp.Or(p.LastTag, "the first commit"))
+	} else {
+		b.WriteString("## What's Changed\n\n")
+		for _, pr := range p.PRs {
+			fmt.Fprintf(&b, "- %s (#%d)", pr.Title, pr.Number)
+			if pr.Author.Login != "" {
+				fmt.Fprintf(&b, " by @%s", pr.Author.Login)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if p.LastTag != "" {
+		fmt.Fprintf(&b, "\n**Full Changelog**: https://github.com/%s/%s/compare/%s...%s\n", p.Owner, p.Repo, p.LastTag, tag)
+	}
+	return b.String()
+}
+
+// Publish creates and pushes tag at HEAD and returns the page that drafts
+// its GitHub release with the notes filled in.
+func Publish(runner git.CommandRunner, p Plan, tag string) (string, error) {
+	if err := git.CreateTag(runner, p.Dir, tag, tag); err != nil {
+		return "", err
+	}
+	return github.ReleaseURL(p.Owner, p.Repo, tag, p.Notes(tag)), nil
+}
@@ -0,0 +1,134 @@
+package release
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+		ok   bool
+	}{
+		{tag: "v1.2.3", want: "v1.2.3", ok: true},
+		{tag: "0.10.0", want: "0.10.0", ok: true},
+		{tag: "v1.2.3-rc.1"},
+		{tag: "v1.2"},
+		{tag: "v01.2.3"},
+		{tag: "nightly"},
+	}
+	for _, tt := range tests {
+		v, ok := ParseVersion(tt.tag)
+		if ok != tt.ok || (ok && v.String() != tt.want) {
+			t.Errorf("ParseVersion(%q) = %v, %v", tt.tag, v, ok)
+		}
+	}
+}
+
+func TestVersionBump(t *testing.T) {
+	v := Version{Prefix: "v", Major: 1, Minor: 2, Patch: 3}
+	for bump, want := range map[Bump]string{BumpPatch: "v1.2.4", BumpMinor: "v1.3.0", BumpMajor: "v2.0.0"} {
+		if got := v.Bump(bump).String(); got != want {
+			t.Errorf("%s bump = %s, want %s", bump, got, want)
+		}
+	}
+}
+
+func TestVersionLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"v1.2.3", "v1.2.4", true},
+		{"v1.2.3", "1.10.0", true},
+		{"v2.0.0", "v1.9.9", false},
+		{"v1.2.3", "1.2.3", false},
+	}
+	for _, tt := range tests {
+		a, _ := ParseVersion(tt.a)
+		b, _ := ParseVersion(tt.b)
+		if got := a.Less(b); got != tt.want {
+			t.Errorf("%s < %s = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLatest_SkipsNonReleaseTags(t *testing.T) {
+	v, tag, ok := Latest([]string{"v2.0.0-beta", "nightly", "v1.4.0", "v1.3.9"})
+	if !ok || tag != "v1.4.0" || v.Minor != 4 {
+		t.Errorf("Latest = %v %q %v, want v1.4.0", v, tag, ok)
+	}
+	if _, _, ok := Latest(nil); ok {
+		t.Error("no tags should report no release")
+	}
+}
+
+func primaryRunner() git.FakeCommandRunner {
+	return git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[rev-parse --show-toplevel]":                         "/repo\n",
+			"/repo:[rev-parse --path-format=absolute --git-common-dir]": "/repo/.git\n",
+			"/repo:[symbolic-ref --short HEAD]":                         "main\n",
+			"/repo:[remote get-url origin]":                             "git@github.com:owner/app.git\n",
+			"/repo:[tag --list --merged HEAD --sort=-v:refname]":        "v1.4.0\nv1.3.0\n",
+			"/repo:[log -1 --format=%cI v1.4.0]":                        "2025-03-04T00:00:00Z\n",
+			"/repo:[tag -a v1.5.0 -m v1.5.0]":                           "",
+			"/repo:[push origin refs/tags/v1.5.0]":                      "",
+			"/wt:[rev-parse --show-toplevel]":                           "/wt\n",
+			"/wt:[rev-parse --path-format=absolute --git-common-dir]":   "/repo/.git\n",
+		},
+	}
+}
+
+func TestPrepareAndPublish(t *testing.T) {
+	gh := &github.FakeRunner{
+		Outputs: map[string]string{
+			"/repo:[pr list --state merged --base main --limit 200 --json number,title,author,mergedAt --search merged:>=2025-03-04]": `[
+				{"number": 12, "title": "Fix login", "author": {"login": "alice"}, "mergedAt": "2025-03-05T00:00:00Z"}
+			]`,
+		},
+	}
+
+	plan, err := Prepare(primaryRunner(), gh, "/repo", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.LastTag != "v1.4.0" || len(plan.PRs) != 1 {
+		t.Fatalf("plan = %+v", plan)
+	}
+
+	tag := plan.Current.Bump(BumpMinor).String()
+	notes := plan.Notes(tag)
+	for _, want := range []string{"- Fix login (#12) by @alice", "compare/v1.4.0...v1.5.0"} {
+		if !strings.Contains(notes, want) {
+			t.Errorf("notes missing %q:\n%s", want, notes)
+		}
+	}
+
+	url, err := Publish(primaryRunner(), plan, tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(url, "https://github.com/owner/app/releases/new?") || !strings.Contains(url, "tag=v1.5.0") {
+		t.Errorf("url = %q", url)
+	}
+}
+
+func TestPrepare_LinkedWorktree(t *testing.T) {
+	_, err := Prepare(primaryRunner(), &github.FakeRunner{}, "/wt", "main")
+	if !errors.Is(err, ErrNotPrimaryWorktree) {
+		t.Errorf("err = %v, want ErrNotPrimaryWorktree", err)
+	}
+}
+
+func TestNotes_FirstRelease(t *testing.T) {
+	notes := Plan{Owner: "owner", Repo: "app"}.Notes("v0.1.0")
+	if !strings.Contains(notes, "No pull requests merged") || strings.Contains(notes, "Full Changelog") {
+		t.Errorf("notes = %q", notes)
+	}
+}
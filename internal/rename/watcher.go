@@ -2,10 +2,11 @@ package rename
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"time"
 
+	"github.com/mikanfactory/yakumo/internal/audit"
 	"github.com/mikanfactory/yakumo/internal/branchname"
 	"github.com/mikanfactory/yakumo/internal/claude"
 	"github.com/mikanfactory/yakumo/internal/git"
@@ -26,6 +27,8 @@ type WatcherConfig struct {
 	PollInterval       time.Duration
 	Timeout            time.Duration
 	RenameRetryBackoff time.Duration
+	// Namer makes the new branch name; the zero Namer gives the defaults.
+	Namer branchname.Namer
 }
 
 // Watcher polls Claude history for a first prompt and renames the branch accordingly.
@@ -35,7 +38,8 @@ type Watcher struct {
 	generator branchname.Generator
 	runner    git.CommandRunner
 	tmuxRunner tmux.Runner
-	logger    *log.Logger
+	logger    *slog.Logger
+	audit     audit.Log
 }
 
 // NewWatcher creates a new rename watcher.
@@ -50,32 +54,38 @@ func NewWatcher(cfg WatcherConfig, reader claude.Reader, gen branchname.Generato
 }
 
 // SetLogger sets a logger for the watcher. If nil, logging is disabled.
-func (w *Watcher) SetLogger(l *log.Logger) {
+func (w *Watcher) SetLogger(l *slog.Logger) {
 	w.logger = l
 }
 
-func (w *Watcher) logf(format string, args ...interface{}) {
-	if w.logger != nil {
-		w.logger.Printf("[branch-rename] "+format, args...)
+// SetAudit records the rename in auditLog.
+func (w *Watcher) SetAudit(auditLog audit.Log) {
+	w.audit = auditLog.From(audit.SourceWatcher)
+}
+
+func (w *Watcher) log() *slog.Logger {
+	if w.logger == nil {
+		return slog.New(slog.DiscardHandler)
 	}
+	return w.logger
 }
 
 // Run polls for a first prompt and renames the branch when found.
 // Returns nil on success, or an error on timeout / rename failure.
 func (w *Watcher) Run() error {
-	w.logf("started: path=%q branch=%q createdAt=%d timeout=%v", w.config.WorktreePath, w.config.Branch, w.config.CreatedAt, w.config.Timeout)
+	w.log().Info("started", "path", w.config.WorktreePath, "branch", w.config.Branch, "created_at", w.config.CreatedAt, "timeout", w.config.Timeout)
 	deadline := time.Now().Add(w.config.Timeout)
 
 	for {
 		if time.Now().After(deadline) {
-			w.logf("timeout: no prompt detected within %v for path=%q", w.config.Timeout, w.config.WorktreePath)
+			w.log().Warn("timeout: no prompt detected", "path", w.config.WorktreePath, "timeout", w.config.Timeout)
 			return fmt.Errorf("timeout: no prompt detected within %v", w.config.Timeout)
 		}
 
-		w.logf("polling: path=%q elapsed=%dms", w.config.WorktreePath, time.Now().UnixMilli()-w.config.CreatedAt)
+		w.log().Debug("polling", "path", w.config.WorktreePath, "elapsed_ms", time.Now().UnixMilli()-w.config.CreatedAt)
 		prompt, found := w.findPrompt()
 		if found {
-			w.logf("prompt detected: %q for path=%q", prompt, w.config.WorktreePath)
+			w.log().Info("prompt detected", "prompt", prompt, "path", w.config.WorktreePath)
 			return w.renameBranchWithRetry(prompt)
 		}
 
@@ -91,10 +101,10 @@ func (w *Watcher) renameBranchWithRetry(prompt string) error {
 
 	var lastErr error
 	for attempt := 1; attempt <= maxRenameAttempts; attempt++ {
-		w.logf("renameBranch attempt %d/%d", attempt, maxRenameAttempts)
+		w.log().Debug("renameBranch", "attempt", attempt, "of", maxRenameAttempts)
 		if err := w.renameBranch(prompt); err != nil {
 			lastErr = err
-			w.logf("renameBranch attempt %d/%d failed: %v", attempt, maxRenameAttempts, err)
+			w.log().Warn("renameBranch failed", "attempt", attempt, "of", maxRenameAttempts, "err", err)
 			if attempt < maxRenameAttempts {
 				time.Sleep(backoff)
 			}
@@ -108,39 +118,33 @@ func (w *Watcher) renameBranchWithRetry(prompt string) error {
 func (w *Watcher) findPrompt() (string, bool) {
 	data, err := w.reader.ReadHistoryFile()
 	if err != nil {
-		w.logf("findPrompt: ReadHistoryFile error: %v", err)
+		w.log().Error("findPrompt: ReadHistoryFile", "err", err)
 		return "", false
 	}
 	entries, err := claude.ParseHistory(data)
 	if err != nil {
-		w.logf("findPrompt: ParseHistory error: %v", err)
+		w.log().Error("findPrompt: ParseHistory", "err", err)
 		return "", false
 	}
 	prompt, _, found := claude.FindFirstPrompt(entries, w.config.WorktreePath, w.config.CreatedAt)
 	if !found {
-		w.logf("findPrompt: no prompt found for path=%q afterTimestamp=%d (entries=%d)", w.config.WorktreePath, w.config.CreatedAt, len(entries))
+		w.log().Debug("findPrompt: no prompt found", "path", w.config.WorktreePath, "after_timestamp", w.config.CreatedAt, "entries", len(entries))
 	}
 	return prompt, found
 }
 
 func (w *Watcher) renameBranch(prompt string) error {
-	w.logf("renameBranch: generating name for prompt=%q", prompt)
+	w.log().Debug("renameBranch: generating name", "prompt", prompt)
 	name, err := w.generator.GenerateBranchName(prompt)
 	if err != nil {
-		w.logf("renameBranch: GenerateBranchName error: %v", err)
+		w.log().Error("renameBranch: GenerateBranchName", "err", err)
 		return fmt.Errorf("generating branch name: %w", err)
 	}
 
-	sanitized := branchname.SanitizeBranchName(name)
-	if sanitized == "" {
-		w.logf("renameBranch: SanitizeBranchName returned empty for raw=%q", name)
-		return fmt.Errorf("generated branch name is empty")
-	}
-
-	// Preserve username prefix: "shoji/south-korea" -> "shoji/fix-login"
-	newBranch := sanitized
-	if parts := strings.SplitN(w.config.Branch, "/", 2); len(parts) == 2 {
-		newBranch = parts[0] + "/" + sanitized
+	newBranch, err := w.config.Namer.BranchName(w.config.Branch, prompt, name)
+	if err != nil {
+		w.log().Error("renameBranch: BranchName", "raw", name, "err", err)
+		return err
 	}
 
 	// Resolve the actual tmux session name before git rename (session may have been renamed)
@@ -159,22 +163,24 @@ func (w *Watcher) renameBranch(prompt string) error {
 		oldSessionName = tmux.ResolveSessionName(w.tmuxRunner, w.config.WorktreePath, getBranch)
 	}
 
-	w.logf("renameBranch: renaming %q -> %q in %q", w.config.Branch, newBranch, w.config.WorktreePath)
-	if err := git.RenameBranch(w.runner, w.config.WorktreePath, w.config.Branch, newBranch); err != nil {
-		w.logf("renameBranch: RenameBranch error: %v", err)
+	w.log().Debug("renameBranch: renaming", "from", w.config.Branch, "to", newBranch, "path", w.config.WorktreePath)
+	err = git.RenameBranch(w.runner, w.config.WorktreePath, w.config.Branch, newBranch)
+	w.audit.Record(audit.Event{Op: audit.OpRename, Path: w.config.WorktreePath, Branch: w.config.Branch, Detail: newBranch}, err)
+	if err != nil {
+		w.log().Error("renameBranch: RenameBranch", "err", err)
 		return fmt.Errorf("renaming branch: %w", err)
 	}
 
-	w.logf("renameBranch: success %q -> %q", w.config.Branch, newBranch)
+	w.log().Info("renameBranch: success", "from", w.config.Branch, "to", newBranch)
 
 	// Rename tmux session to match the new branch slug (non-fatal)
 	if w.tmuxRunner != nil && oldSessionName != "" {
-		newSessionName := branchname.SlugFromBranch(newBranch)
+		newSessionName := tmux.WorktreeSessionName(w.config.WorktreePath, branchname.SlugFromBranch(newBranch))
 		if newSessionName != oldSessionName {
-			if err := tmux.RenameSession(w.tmuxRunner, oldSessionName, newSessionName); err != nil {
-				w.logf("renameBranch: tmux rename-session failed (non-fatal): %v", err)
+			if err := tmux.RenameWorktree(w.tmuxRunner, oldSessionName, newSessionName); err != nil {
+				w.log().Warn("renameBranch: tmux rename-session failed (non-fatal)", "err", err)
 			} else {
-				w.logf("renameBranch: tmux session renamed %q -> %q", oldSessionName, newSessionName)
+				w.log().Info("renameBranch: tmux session renamed", "from", oldSessionName, "to", newSessionName)
 			}
 		}
 	}
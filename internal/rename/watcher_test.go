@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"testing"
 	"time"
@@ -13,6 +13,7 @@ import (
 	"github.com/mikanfactory/yakumo/internal/branchname"
 	"github.com/mikanfactory/yakumo/internal/claude"
 	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
 	"github.com/mikanfactory/yakumo/internal/tmux"
 )
 
@@ -118,6 +119,39 @@ func TestWatcher_Run_Success(t *testing.T) {
 	}
 }
 
+func TestWatcher_Run_Namer(t *testing.T) {
+	wtPath := "/Users/shoji/yakumo/south-korea"
+	createdAt := time.Now().UnixMilli()
+
+	historyData := makeHistory(wtPath, "PROJ-123 add user authentication", createdAt+1000)
+
+	reader := claude.FakeReader{Data: historyData}
+	gen := branchname.FakeGenerator{Result: "add-auth"}
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("%s:[branch -m shoji/south-korea PROJ-123-add-auth]", wtPath): "",
+		},
+	}
+	namer, err := branchname.NewNamer(model.BranchNamerConfig{Template: "{{with .Ticket}}{{.}}-{{end}}{{.Slug}}", Prefix: "none"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := WatcherConfig{
+		WorktreePath: wtPath,
+		Branch:       "shoji/south-korea",
+		CreatedAt:    createdAt,
+		PollInterval: 10 * time.Millisecond,
+		Timeout:      1 * time.Second,
+		Namer:        namer,
+	}
+
+	w := NewWatcher(cfg, reader, gen, runner, nil)
+	if err := w.Run(); err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+}
+
 func TestWatcher_Run_Timeout(t *testing.T) {
 	reader := claude.FakeReader{Data: []byte{}} // empty history
 	gen := branchname.FakeGenerator{Result: "unused"}
@@ -161,7 +195,7 @@ func TestWatcher_Run_LLMError(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	logger := log.New(&buf, "", 0)
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
 
 	w := NewWatcher(cfg, reader, gen, runner, nil)
 	w.SetLogger(logger)
@@ -176,7 +210,7 @@ func TestWatcher_Run_LLMError(t *testing.T) {
 		t.Errorf("error should wrap generating branch name error, got: %v", err)
 	}
 	output := buf.String()
-	for _, phrase := range []string{"attempt 1/3", "attempt 2/3", "attempt 3/3"} {
+	for _, phrase := range []string{"attempt=1 of=3", "attempt=2 of=3", "attempt=3 of=3"} {
 		if !strings.Contains(output, phrase) {
 			t.Errorf("log output should contain %q, got:\n%s", phrase, output)
 		}
@@ -334,7 +368,7 @@ func TestWatcher_Run_LogsProgress(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	logger := log.New(&buf, "", 0)
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
 
 	w := NewWatcher(cfg, reader, gen, runner, nil)
 	w.SetLogger(logger)
@@ -345,9 +379,9 @@ func TestWatcher_Run_LogsProgress(t *testing.T) {
 
 	output := buf.String()
 	expectedPhrases := []string{
-		"started:",
-		"polling:",
-		"prompt detected:",
+		"msg=started",
+		"msg=polling",
+		"msg=\"prompt detected\"",
 		"renameBranch: generating name",
 		"renameBranch: renaming",
 		"renameBranch: success",
@@ -373,15 +407,15 @@ func TestWatcher_FindPrompt_LogsErrors(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	logger := log.New(&buf, "", 0)
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
 
 	w := NewWatcher(cfg, reader, gen, runner, nil)
 	w.SetLogger(logger)
 	_ = w.Run() // will timeout
 
 	output := buf.String()
-	if !strings.Contains(output, "ReadHistoryFile error") {
-		t.Errorf("log output should contain ReadHistoryFile error, got:\n%s", output)
+	if !strings.Contains(output, "ReadHistoryFile") || !strings.Contains(output, "level=ERROR") {
+		t.Errorf("log output should contain the ReadHistoryFile error, got:\n%s", output)
 	}
 }
 
@@ -0,0 +1,85 @@
+// Package restore recreates the worktree sessions yakumo set up after the
+// tmux server that held them went away, as after a reboot or tmux kill-server.
+package restore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mikanfactory/yakumo/internal/state"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+// Report lists what a run did.
+type Report struct {
+	Sessions []string // recreated sessions
+	Gone     []string // worktree paths that no longer exist, forgotten
+}
+
+// Empty reports whether nothing had to be recreated or forgotten.
+func (r Report) Empty() bool {
+	return len(r.Sessions) == 0 && len(r.Gone) == 0
+}
+
+func (r Report) String() string {
+	if r.Empty() {
+		return "Nothing to restore."
+	}
+	var b strings.Builder
+	for _, name := range r.Sessions {
+		fmt.Fprintf(&b, "restored session %s\n", name)
+	}
+	for _, path := range r.Gone {
+		fmt.Fprintf(&b, "forgot %s (worktree is gone)\n", path)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Launcher starts the tools of a worktree in a session Run just created.
+type Launcher func(layout tmux.SessionLayout, saved state.SavedSession)
+
+// Run recreates the saved sessions that are not running, under their saved
+// names, and passes each to launch, which may be nil. Sessions of worktrees
+// that no longer exist are removed from the store. Failures are collected and
+// the rest carries on.
+func Run(tmuxRunner tmux.Runner, store state.SavedSessions, launch Launcher) (Report, error) {
+	var report Report
+	var errs []error
+	for _, saved := range store.Get() {
+		if _, err := os.Stat(saved.WorktreePath); errors.Is(err, os.ErrNotExist) {
+			report.Gone = append(report.Gone, saved.WorktreePath)
+			continue
+		}
+		if exists, _ := tmux.HasWorktree(tmuxRunner, saved.Name); exists {
+			continue
+		}
+		layout, err := tmux.EnsureWorktreeSession(tmuxRunner, saved.WorktreePath, saved.StartupCommand, nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", saved.WorktreePath, err))
+			continue
+		}
+		if layout.Center1.PaneID == "" {
+			// Running under the default name already.
+			continue
+		}
+		if saved.Name != "" && saved.Name != layout.SessionName {
+			if err := tmux.RenameWorktree(tmuxRunner, layout.SessionName, saved.Name); err != nil {
+				errs = append(errs, fmt.Errorf("renaming session %s to %s: %w", layout.SessionName, saved.Name, err))
+			} else {
+				layout.SessionName = saved.Name
+			}
+		}
+		report.Sessions = append(report.Sessions, layout.SessionName)
+		if launch != nil {
+			launch(layout, saved)
+		}
+	}
+	if len(report.Gone) > 0 {
+		if err := store.Remove(report.Gone...); err != nil {
+			errs = append(errs, fmt.Errorf("forgetting removed worktrees: %w", err))
+		}
+	}
+	return report, errors.Join(errs...)
+}
@@ -0,0 +1,79 @@
+package restore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/state"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+func TestRun(t *testing.T) {
+	base := t.TempDir()
+	feat := filepath.Join(base, "feat")
+	running := filepath.Join(base, "running")
+	for _, dir := range []string{feat, running} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gone := filepath.Join(base, "gone")
+
+	store := state.SavedSessions{File: state.File{Path: filepath.Join(t.TempDir(), "sessions.json")}}
+	for _, saved := range []state.SavedSession{
+		{Name: "feat-login", WorktreePath: feat, RepoPath: "/repo"},
+		{Name: "running", WorktreePath: running, RepoPath: "/repo"},
+		{Name: "gone", WorktreePath: gone, RepoPath: "/repo"},
+	} {
+		if err := store.Add(saved); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tmuxRunner := &tmux.FakeRunner{
+		Outputs: map[string]string{
+			"[has-session -t =running]":                                      "",
+			"[new-session -d -s feat -c " + feat + "]":                       "",
+			"[rename-window -t =feat:0 main-window]":                         "",
+			"[split-window -h -t =feat:main-window -c " + feat + " -p 25]":   "",
+			"[split-window -v -t =feat:main-window.1 -c " + feat + " -p 70]": "",
+			"[list-panes -t =feat:main-window -F #{pane_id}]":                "%0\n%1\n%2\n",
+			"[new-window -t =feat -n background-window -c " + feat + "]":     "",
+			"[split-window -v -t =feat:background-window -c " + feat + "]":   "",
+			"[list-panes -t =feat:background-window -F #{pane_id}]":          "%3\n%4\n%5\n%6\n",
+			"[rename-session -t =feat feat-login]":                           "",
+		},
+	}
+
+	var launched []string
+	report, err := Run(tmuxRunner, store, func(layout tmux.SessionLayout, saved state.SavedSession) {
+		launched = append(launched, layout.SessionName+" "+layout.Center1.PaneID)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(report.Sessions) != "[feat-login]" {
+		t.Errorf("Sessions = %v, want [feat-login]", report.Sessions)
+	}
+	if fmt.Sprint(report.Gone) != fmt.Sprint([]string{gone}) {
+		t.Errorf("Gone = %v, want [%s]", report.Gone, gone)
+	}
+	if fmt.Sprint(launched) != "[feat-login %0]" {
+		t.Errorf("launched = %v, want the recreated session under its saved name", launched)
+	}
+	if got := store.Get(); len(got) != 2 {
+		t.Errorf("store = %+v, want the removed worktree forgotten", got)
+	}
+}
+
+func TestReportString(t *testing.T) {
+	if got := (Report{}).String(); got != "Nothing to restore." {
+		t.Errorf("empty = %q", got)
+	}
+	got := Report{Sessions: []string{"a"}, Gone: []string{"/wt/b"}}.String()
+	if got != "restored session a\nforgot /wt/b (worktree is gone)" {
+		t.Errorf("String() = %q", got)
+	}
+}
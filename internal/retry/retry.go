@@ -0,0 +1,85 @@
+// Package retry re-runs operations that fail transiently (lock files,
+// network hiccups) with jittered exponential backoff.
+package retry
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy controls how many times an operation is attempted and how long to
+// wait between attempts.
+type Policy struct {
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Sleep waits between attempts; nil means time.Sleep.
+	Sleep func(time.Duration)
+}
+
+// Default is used by the git, gh and tmux helpers. Three attempts keep the
+// worst case under a second so the UIs never appear stuck.
+var Default = Policy{
+	Attempts:  3,
+	BaseDelay: 100 * time.Millisecond,
+	MaxDelay:  500 * time.Millisecond,
+}
+
+// Error aggregates the failures of every attempt once retries are exhausted.
+// It unwraps to each attempt's error, so errors.Is still matches sentinels.
+type Error struct {
+	Errs []error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("gave up after %d attempts: %v", len(e.Errs), e.Errs[len(e.Errs)-1])
+}
+
+func (e *Error) Unwrap() []error {
+	return e.Errs
+}
+
+// Do calls fn until it succeeds, returns an error for which transient reports
+// false, or the policy runs out of attempts. A single failed attempt returns
+// its error unchanged; repeated failures are aggregated into *Error.
+func Do[T any](p Policy, transient func(error) bool, fn func() (T, error)) (T, error) {
+	attempts := max(p.Attempts, 1)
+	sleep := p.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	var errs []error
+	for i := range attempts {
+		v, err := fn()
+		if err == nil {
+			return v, nil
+		}
+		errs = append(errs, err)
+		if !transient(err) || i == attempts-1 {
+			break
+		}
+		sleep(p.delay(i))
+	}
+
+	var zero T
+	if len(errs) == 1 {
+		return zero, errs[0]
+	}
+	return zero, &Error{Errs: errs}
+}
+
+// delay returns the jittered backoff before attempt n+1: a random duration in
+// [d/2, d] where d doubles from BaseDelay up to MaxDelay.
+func (p Policy) delay(n int) time.Duration {
+	d := p.BaseDelay << n
+	if p.MaxDelay > 0 && (d > p.MaxDelay || d <= 0) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
@@ -0,0 +1,95 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("transient")
+
+func isTransient(err error) bool { return errors.Is(err, errTransient) }
+
+func noSleepPolicy(attempts int) (Policy, *[]time.Duration) {
+	var slept []time.Duration
+	return Policy{
+		Attempts:  attempts,
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  150 * time.Millisecond,
+		Sleep:     func(d time.Duration) { slept = append(slept, d) },
+	}, &slept
+}
+
+func TestDo_SucceedsAfterTransientFailures(t *testing.T) {
+	p, slept := noSleepPolicy(3)
+	calls := 0
+
+	got, err := Do(p, isTransient, func() (string, error) {
+		calls++
+		if calls < 3 {
+			return "", errTransient
+		}
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "ok" || calls != 3 {
+		t.Errorf("got %q after %d calls, want ok after 3", got, calls)
+	}
+	if len(*slept) != 2 {
+		t.Fatalf("slept %d times, want 2", len(*slept))
+	}
+	for i, d := range *slept {
+		if d < 50*time.Millisecond || d > 150*time.Millisecond {
+			t.Errorf("delay %d = %v, want within [50ms, 150ms]", i, d)
+		}
+	}
+}
+
+func TestDo_NonTransientStopsImmediately(t *testing.T) {
+	p, slept := noSleepPolicy(3)
+	permanent := errors.New("permanent")
+	calls := 0
+
+	_, err := Do(p, isTransient, func() (int, error) {
+		calls++
+		return 0, permanent
+	})
+	if err != permanent {
+		t.Errorf("err = %v, want the original error", err)
+	}
+	if calls != 1 || len(*slept) != 0 {
+		t.Errorf("calls = %d, sleeps = %d; want 1, 0", calls, len(*slept))
+	}
+}
+
+func TestDo_AggregatesAfterExhaustingAttempts(t *testing.T) {
+	p, _ := noSleepPolicy(3)
+
+	_, err := Do(p, isTransient, func() (int, error) {
+		return 0, errTransient
+	})
+
+	var retryErr *Error
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected *Error, got %T: %v", err, err)
+	}
+	if len(retryErr.Errs) != 3 {
+		t.Errorf("aggregated %d errors, want 3", len(retryErr.Errs))
+	}
+	if !errors.Is(err, errTransient) {
+		t.Error("aggregated error should still match the attempt errors")
+	}
+}
+
+func TestDo_ZeroAttemptsRunsOnce(t *testing.T) {
+	calls := 0
+	_, _ = Do(Policy{}, isTransient, func() (int, error) {
+		calls++
+		return 0, errTransient
+	})
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
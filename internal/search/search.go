@@ -0,0 +1,70 @@
+// Package search greps every worktree of the configured repositories in
+// parallel and groups the matches per worktree.
+package search
+
+import (
+	"sync"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// Target is a worktree to search.
+type Target struct {
+	Repo     string
+	RepoPath string
+	Branch   string
+	Path     string
+}
+
+// Result holds the matches found in one worktree.
+type Result struct {
+	Target
+	Matches   []git.GrepMatch
+	Truncated bool
+	Err       error
+}
+
+// TargetsFromGroups lists the non-bare worktrees in groups.
+func TargetsFromGroups(groups []model.RepoGroup) []Target {
+	var targets []Target
+	for _, g := range groups {
+		for _, wt := range g.Worktrees {
+			if wt.IsBare {
+				continue
+			}
+			targets = append(targets, Target{Repo: g.Name, RepoPath: g.RootPath, Branch: wt.Branch, Path: wt.Path})
+		}
+	}
+	return targets
+}
+
+// Run greps pattern in every target concurrently, keeping at most limit
+// matches per worktree (0 means no limit). Results follow the order of
+// targets; worktrees without matches are omitted.
+func Run(runner git.CommandRunner, targets []Target, pattern string, limit int) []Result {
+	results := make([]Result, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			matches, err := git.Grep(runner, t.Path, pattern)
+			r := Result{Target: t, Matches: matches, Err: err}
+			if limit > 0 && len(r.Matches) > limit {
+				r.Matches = r.Matches[:limit]
+				r.Truncated = true
+			}
+			results[i] = r
+		}()
+	}
+	wg.Wait()
+
+	var found []Result
+	for _, r := range results {
+		if r.Err != nil || len(r.Matches) > 0 {
+			found = append(found, r)
+		}
+	}
+	return found
+}
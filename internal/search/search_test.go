@@ -0,0 +1,58 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func grepKey(dir, pattern string) string {
+	return fmt.Sprintf("%s:%v", dir, []string{"grep", "-z", "-n", "-I", "--no-color", "--full-name", "-e", pattern})
+}
+
+func TestTargetsFromGroups(t *testing.T) {
+	groups := []model.RepoGroup{{
+		Name:     "repo",
+		RootPath: "/repo",
+		Worktrees: []model.WorktreeInfo{
+			{Path: "/repo", IsBare: true},
+			{Path: "/wt/a", Branch: "a"},
+		},
+	}}
+
+	got := TargetsFromGroups(groups)
+	if len(got) != 1 || got[0] != (Target{Repo: "repo", RepoPath: "/repo", Branch: "a", Path: "/wt/a"}) {
+		t.Errorf("targets = %+v, want only /wt/a", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			grepKey("/wt/a", "TODO"): "a.go\x001\x00// TODO one\na.go\x002\x00// TODO two\na.go\x003\x00// TODO three\n",
+			grepKey("/wt/b", "TODO"): "",
+		},
+		Errors: map[string]error{
+			grepKey("/wt/c", "TODO"): fmt.Errorf("boom"),
+		},
+	}
+	targets := []Target{
+		{Repo: "r", Branch: "a", Path: "/wt/a"},
+		{Repo: "r", Branch: "b", Path: "/wt/b"},
+		{Repo: "r", Branch: "c", Path: "/wt/c"},
+	}
+
+	results := Run(runner, targets, "TODO", 2)
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2 (b has no matches)", len(results))
+	}
+	if results[0].Path != "/wt/a" || len(results[0].Matches) != 2 || !results[0].Truncated {
+		t.Errorf("results[0] = %+v, want 2 truncated matches in /wt/a", results[0])
+	}
+	if results[1].Path != "/wt/c" || results[1].Err == nil {
+		t.Errorf("results[1] = %+v, want error for /wt/c", results[1])
+	}
+}
@@ -0,0 +1,138 @@
+// Package seed fills a new worktree with the untracked files it needs from
+// the main working tree, such as .env files or node_modules, which
+// `git worktree add` leaves behind.
+package seed
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Validate checks copy_on_create and symlink_on_create entries: glob
+// patterns relative to the repository that stay inside it.
+func Validate(patterns []string) error {
+	for _, p := range patterns {
+		if p == "" || filepath.IsAbs(p) {
+			return fmt.Errorf("%q: must be a path relative to the repository", p)
+		}
+		if clean := filepath.Clean(p); clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("%q: must stay inside the repository", p)
+		}
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("%q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// Apply copies the files and directories of repoPath matching copyPatterns
+// into worktreePath, then links those matching linkPatterns to their
+// originals. Patterns that match nothing and paths the worktree already has,
+// such as tracked files, are skipped. Failures are collected and the rest
+// carries on.
+func Apply(repoPath, worktreePath string, copyPatterns, linkPatterns []string) error {
+	var errs []error
+	for _, rel := range matches(repoPath, copyPatterns, &errs) {
+		if err := copyPath(filepath.Join(repoPath, rel), filepath.Join(worktreePath, rel)); err != nil {
+			errs = append(errs, fmt.Errorf("copying %s: %w", rel, err))
+		}
+	}
+	for _, rel := range matches(repoPath, linkPatterns, &errs) {
+		dst := filepath.Join(worktreePath, rel)
+		if exists(dst) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			errs = append(errs, fmt.Errorf("linking %s: %w", rel, err))
+			continue
+		}
+		if err := os.Symlink(filepath.Join(repoPath, rel), dst); err != nil {
+			errs = append(errs, fmt.Errorf("linking %s: %w", rel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// matches returns the paths under repoPath matching patterns, relative to it.
+func matches(repoPath string, patterns []string, errs *[]error) []string {
+	var rels []string
+	for _, p := range patterns {
+		found, err := filepath.Glob(filepath.Join(repoPath, p))
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%q: %w", p, err))
+			continue
+		}
+		for _, path := range found {
+			rel, err := filepath.Rel(repoPath, path)
+			if err != nil {
+				continue
+			}
+			rels = append(rels, rel)
+		}
+	}
+	return rels
+}
+
+// copyPath copies the file or directory tree at src to dst, keeping files
+// dst already has.
+func copyPath(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case exists(target):
+			return nil
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			return copyFile(path, target, info.Mode().Perm())
+		}
+		return nil // sockets, devices and the like
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
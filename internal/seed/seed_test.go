@@ -0,0 +1,78 @@
+package seed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestApply(t *testing.T) {
+	repo, wt := t.TempDir(), t.TempDir()
+	write(t, filepath.Join(repo, ".env"), "DATABASE_URL=postgres://localhost/app")
+	write(t, filepath.Join(repo, ".env.local"), "DEBUG=1")
+	write(t, filepath.Join(repo, "config", "master.key"), "secret")
+	write(t, filepath.Join(repo, "node_modules", "left-pad", "index.js"), "module.exports = 1")
+	write(t, filepath.Join(repo, ".envrc"), "repo's copy")
+	write(t, filepath.Join(wt, ".envrc"), "tracked")
+
+	err := Apply(repo, wt, []string{".env*", "config", "missing"}, []string{"node_modules"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := read(t, filepath.Join(wt, ".env")); got != "DATABASE_URL=postgres://localhost/app" {
+		t.Errorf(".env = %q", got)
+	}
+	if got := read(t, filepath.Join(wt, ".env.local")); got != "DEBUG=1" {
+		t.Errorf(".env.local = %q", got)
+	}
+	if got := read(t, filepath.Join(wt, "config", "master.key")); got != "secret" {
+		t.Errorf("config/master.key = %q", got)
+	}
+	if info, err := os.Stat(filepath.Join(wt, ".env")); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf(".env mode = %v, %v, want 0600 kept", info.Mode().Perm(), err)
+	}
+	if got := read(t, filepath.Join(wt, ".envrc")); got != "tracked" {
+		t.Errorf(".envrc = %q, want the worktree's own file kept", got)
+	}
+
+	link, err := os.Readlink(filepath.Join(wt, "node_modules"))
+	if err != nil || link != filepath.Join(repo, "node_modules") {
+		t.Errorf("node_modules link = %q, %v, want the repository's", link, err)
+	}
+
+	// Applying again keeps what is there.
+	if err := Apply(repo, wt, []string{".env*"}, []string{"node_modules"}); err != nil {
+		t.Errorf("second Apply: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate([]string{".env", "config/*.key", "node_modules"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, bad := range []string{"", "/etc/passwd", "../shared/.env", "[", "a/../../b"} {
+		if err := Validate([]string{bad}); err == nil {
+			t.Errorf("Validate(%q) should fail", bad)
+		}
+	}
+}
@@ -4,9 +4,24 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/theme"
+)
+
+var (
+	accentColor  lipgloss.Color
+	commandStyle lipgloss.Style
 )
 
-var accentColor = lipgloss.Color("#89b4fa")
+func init() {
+	ApplyTheme(theme.Default())
+}
+
+// ApplyTheme sets the colors of the spinner from the `theme` config.
+func ApplyTheme(t theme.Theme) {
+	accentColor = t.Accent
+	commandStyle = lipgloss.NewStyle().Foreground(accentColor).Bold(true)
+}
 
 // StatusMsg updates the displayed status text.
 type StatusMsg string
@@ -16,12 +31,22 @@ type DoneMsg struct {
 	Err error
 }
 
+// ConfirmMsg asks the user whether to run Command, a shell command about to
+// be typed into a pane, or when Question is set, asks Question about
+// Command. The answer is sent on Reply.
+type ConfirmMsg struct {
+	Question string
+	Command  string
+	Reply    chan<- bool
+}
+
 // Model is a mini Bubble Tea model that shows a spinner with a status message.
 type Model struct {
 	spinner spinner.Model
 	status  string
 	done    bool
 	err     error
+	confirm *ConfirmMsg
 }
 
 // New creates a new spinner model with the given initial status message.
@@ -44,14 +69,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case StatusMsg:
 		m.status = string(msg)
 		return m, nil
+	case ConfirmMsg:
+		m.confirm = &msg
+		return m, nil
 	case DoneMsg:
 		m.done = true
 		m.err = msg.Err
 		return m, tea.Quit
 	case tea.KeyMsg:
 		if msg.Type == tea.KeyCtrlC {
+			m.answer(false)
 			return m, tea.Quit
 		}
+		if m.confirm != nil {
+			switch msg.String() {
+			case "y", "enter":
+				m.answer(true)
+			case "n", "esc":
+				m.answer(false)
+			}
+		}
 		return m, nil
 	default:
 		var cmd tea.Cmd
@@ -60,10 +97,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// answer replies to the pending confirmation, if any.
+func (m *Model) answer(ok bool) {
+	if m.confirm == nil {
+		return
+	}
+	m.confirm.Reply <- ok
+	m.confirm = nil
+}
+
 func (m Model) View() string {
 	if m.done {
 		return ""
 	}
+	if m.confirm != nil && m.confirm.Question != "" {
+		return "  " + m.confirm.Question + "\n    " + commandStyle.Render(m.confirm.Command) + "\n  y/enter: yes  n/esc: no\n"
+	}
+	if m.confirm != nil {
+		return "  Run in pane?\n    " + commandStyle.Render(m.confirm.Command) + "\n  y/enter: run  n/esc: skip\n"
+	}
 	return "  " + m.spinner.View() + " " + m.status + "\n"
 }
 
@@ -71,3 +123,19 @@ func (m Model) View() string {
 func (m Model) Result() error {
 	return m.err
 }
+
+// Confirm asks the user of prog whether to run command and waits for the
+// answer, for a tmux.ConfirmRunner in paranoid mode.
+func Confirm(prog *tea.Program, command string) bool {
+	reply := make(chan bool, 1)
+	prog.Send(ConfirmMsg{Command: command, Reply: reply})
+	return <-reply
+}
+
+// Ask asks the user of prog a yes/no question about detail and waits for the
+// answer.
+func Ask(prog *tea.Program, question, detail string) bool {
+	reply := make(chan bool, 1)
+	prog.Send(ConfirmMsg{Question: question, Command: detail, Reply: reply})
+	return <-reply
+}
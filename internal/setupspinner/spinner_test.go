@@ -118,3 +118,51 @@ func TestNewSetsInitialStatus(t *testing.T) {
 		t.Errorf("expected initial status 'my initial status', got %q", m.status)
 	}
 }
+
+func TestConfirmMsgWaitsForAnswer(t *testing.T) {
+	for key, want := range map[string]bool{"y": true, "n": false} {
+		reply := make(chan bool, 1)
+		m := New("Launching Claude...")
+		updated, _ := m.Update(ConfirmMsg{Command: "claude", Reply: reply})
+		model := updated.(Model)
+
+		if view := model.View(); !strings.Contains(view, "claude") || !strings.Contains(view, "y/enter: run") {
+			t.Errorf("expected the command and its keys in the view, got %q", view)
+		}
+
+		updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		model = updated.(Model)
+		if got := <-reply; got != want {
+			t.Errorf("%s: reply = %v, want %v", key, got, want)
+		}
+		if strings.Contains(model.View(), "Run in pane?") {
+			t.Errorf("%s: expected the prompt to close", key)
+		}
+	}
+}
+
+func TestConfirmMsgWithQuestion(t *testing.T) {
+	reply := make(chan bool, 1)
+	m := New("Creating session...")
+	updated, _ := m.Update(ConfirmMsg{Question: "Add the missing windows?", Command: "background-window", Reply: reply})
+	model := updated.(Model)
+
+	view := model.View()
+	if !strings.Contains(view, "Add the missing windows?") || strings.Contains(view, "Run in pane?") {
+		t.Errorf("expected the question instead of the pane prompt, got %q", view)
+	}
+	if !strings.Contains(view, "background-window") || !strings.Contains(view, "y/enter: yes") {
+		t.Errorf("expected the detail and its keys in the view, got %q", view)
+	}
+}
+
+func TestCtrlCDeclinesPendingConfirm(t *testing.T) {
+	reply := make(chan bool, 1)
+	m := New("working...")
+	updated, _ := m.Update(ConfirmMsg{Command: "claude", Reply: reply})
+	updated.(Model).Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	if <-reply {
+		t.Error("expected ctrl+c to decline the command")
+	}
+}
@@ -0,0 +1,78 @@
+package sidebar
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// FuzzyMatch reports whether every rune of query appears in s in order,
+// ignoring case, and returns the rune indexes in s that matched. Matching is
+// greedy from the left, which is enough to highlight why an item matched.
+func FuzzyMatch(query, s string) ([]int, bool) {
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return nil, true
+	}
+	var positions []int
+	qi := 0
+	for i, r := range []rune(s) {
+		if unicode.ToLower(r) == q[qi] {
+			positions = append(positions, i)
+			qi++
+			if qi == len(q) {
+				return positions, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Filter keeps the worktrees whose branch, repository name, or path fuzzily
+// match query, along with the headers of their groups. Action rows are
+// dropped. Matched branch runes are recorded in Highlight.
+func Filter(items []model.NavigableItem, query string) []model.NavigableItem {
+	if query == "" {
+		return items
+	}
+
+	var filtered []model.NavigableItem
+	var header *model.NavigableItem
+	headerAdded := false
+	for _, item := range items {
+		switch item.Kind {
+		case model.ItemKindGroupHeader:
+			h := item
+			header = &h
+			headerAdded = false
+			continue
+		case model.ItemKindWorktree:
+		default:
+			continue
+		}
+
+		positions, ok := FuzzyMatch(query, item.Label)
+		if !ok {
+			repoMatch := header != nil && matches(query, header.Label) ||
+				item.RecentRepo != "" && matches(query, item.RecentRepo)
+			if !repoMatch && !matches(query, item.WorktreePath) {
+				continue
+			}
+			positions = nil
+		}
+
+		if header != nil && !headerAdded {
+			filtered = append(filtered, *header)
+			headerAdded = true
+		}
+		item.Highlight = positions
+		filtered = append(filtered, item)
+	}
+	return filtered
+}
+
+func matches(query, s string) bool {
+	_, ok := FuzzyMatch(query, s)
+	return ok
+}
@@ -0,0 +1,86 @@
+package sidebar
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		query     string
+		s         string
+		want      []int
+		wantMatch bool
+	}{
+		{query: "", s: "anything", want: nil, wantMatch: true},
+		{query: "flr", s: "fix-login-redirect", want: []int{0, 4, 10}, wantMatch: true},
+		{query: "FLR", s: "fix-login-redirect", want: []int{0, 4, 10}, wantMatch: true},
+		{query: "xyz", s: "fix-login-redirect", want: nil, wantMatch: false},
+		{query: "rf", s: "fr", want: nil, wantMatch: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query+"/"+tt.s, func(t *testing.T) {
+			got, ok := FuzzyMatch(tt.query, tt.s)
+			if ok != tt.wantMatch || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FuzzyMatch(%q, %q) = %v, %v; want %v, %v", tt.query, tt.s, got, ok, tt.want, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	items := BuildItems([]model.RepoGroup{
+		{
+			Name:     "yakumo",
+			RootPath: "/code/yakumo",
+			Worktrees: []model.WorktreeInfo{
+				{Path: "/code/yakumo", Branch: "main"},
+				{Path: "/wt/yakumo/login", Branch: "shoji/fix-login"},
+			},
+		},
+		{
+			Name:     "website",
+			RootPath: "/code/website",
+			Worktrees: []model.WorktreeInfo{
+				{Path: "/code/website", Branch: "main"},
+			},
+		},
+	})
+
+	t.Run("by branch", func(t *testing.T) {
+		got := Filter(items, "login")
+		if len(got) != 2 {
+			t.Fatalf("got %d items, want header + 1 worktree: %+v", len(got), got)
+		}
+		assertItem(t, got[0], model.ItemKindGroupHeader, "yakumo", true)
+		assertItem(t, got[1], model.ItemKindWorktree, "shoji/fix-login", true)
+		if len(got[1].Highlight) != 5 {
+			t.Errorf("Highlight = %v, want 5 positions", got[1].Highlight)
+		}
+	})
+
+	t.Run("by repo name", func(t *testing.T) {
+		got := Filter(items, "website")
+		if len(got) != 2 || got[1].WorktreePath != "/code/website" {
+			t.Fatalf("got %+v, want website's worktree", got)
+		}
+		if got[1].Highlight != nil {
+			t.Errorf("repo-name matches should not highlight the branch, got %v", got[1].Highlight)
+		}
+	})
+
+	t.Run("by path", func(t *testing.T) {
+		got := Filter(items, "wt/yak")
+		if len(got) != 2 || got[1].WorktreePath != "/wt/yakumo/login" {
+			t.Fatalf("got %+v, want the /wt worktree", got)
+		}
+	})
+
+	t.Run("empty query returns everything", func(t *testing.T) {
+		if got := Filter(items, ""); len(got) != len(items) {
+			t.Errorf("got %d items, want %d", len(got), len(items))
+		}
+	})
+}
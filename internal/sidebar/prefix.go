@@ -0,0 +1,85 @@
+package sidebar
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// NoPrefixLabel heads the worktrees whose branch has no prefix in the
+// branch-prefix view.
+const NoPrefixLabel = "(no prefix)"
+
+// BranchPrefix returns branch up to and including its first "/", such as
+// "feature/" for "feature/login", or "" when the branch has no prefix.
+func BranchPrefix(branch string) string {
+	if i := strings.Index(branch, "/"); i > 0 {
+		return branch[:i+1]
+	}
+	return ""
+}
+
+// ByBranchPrefix is the alternative to BuildItems that groups worktrees by
+// branch prefix across repositories instead of by repository. Prefixes are
+// sorted, with NoPrefixLabel last; within a prefix, worktrees keep the order
+// of groups. Like the Recent section, headers are not selectable and each
+// row carries the name of its repository in RecentRepo. There are no
+// "+ Add worktree" rows, as those belong to a repository.
+func ByBranchPrefix(groups []model.RepoGroup) []model.NavigableItem {
+	rows := make(map[string][]model.NavigableItem)
+	for _, group := range groups {
+		for _, wt := range group.Worktrees {
+			prefix := BranchPrefix(wt.Branch)
+			rows[prefix] = append(rows[prefix], model.NavigableItem{
+				Kind:         model.ItemKindWorktree,
+				Label:        wt.Branch,
+				Selectable:   true,
+				WorktreePath: wt.Path,
+				RepoRootPath: group.RootPath,
+				Status:       wt.Status,
+				IsBare:       wt.IsBare,
+				Description:  wt.Description,
+				RecentRepo:   group.Name,
+			})
+		}
+	}
+
+	prefixes := make([]string, 0, len(rows))
+	for prefix := range rows {
+		prefixes = append(prefixes, prefix)
+	}
+	slices.SortFunc(prefixes, func(a, b string) int {
+		switch {
+		case a == b:
+			return 0
+		case a == "":
+			return 1
+		case b == "":
+			return -1
+		}
+		return strings.Compare(a, b)
+	})
+
+	var items []model.NavigableItem
+	for _, prefix := range prefixes {
+		label := prefix
+		if label == "" {
+			label = NoPrefixLabel
+		}
+		items = append(items, model.NavigableItem{Kind: model.ItemKindGroupHeader, Label: label})
+		items = append(items, rows[prefix]...)
+	}
+	return append(items,
+		model.NavigableItem{
+			Kind:       model.ItemKindAddRepo,
+			Label:      "+ Add repository",
+			Selectable: true,
+		},
+		model.NavigableItem{
+			Kind:       model.ItemKindSettings,
+			Label:      "Settings",
+			Selectable: true,
+		},
+	)
+}
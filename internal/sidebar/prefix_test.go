@@ -0,0 +1,67 @@
+package sidebar
+
+import (
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestBranchPrefix(t *testing.T) {
+	for branch, want := range map[string]string{
+		"feature/login": "feature/",
+		"fix/a/b":       "fix/",
+		"main":          "",
+		"/odd":          "",
+		"release/":      "release/",
+	} {
+		if got := BranchPrefix(branch); got != want {
+			t.Errorf("BranchPrefix(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
+
+func TestByBranchPrefix(t *testing.T) {
+	groups := []model.RepoGroup{
+		{Name: "api", RootPath: "/code/api", Worktrees: []model.WorktreeInfo{
+			{Path: "/code/api", Branch: "main", IsBare: true},
+			{Path: "/wt/api-login", Branch: "feature/login"},
+			{Path: "/wt/api-crash", Branch: "fix/crash"},
+		}},
+		{Name: "web", RootPath: "/code/web", Worktrees: []model.WorktreeInfo{
+			{Path: "/wt/web-login", Branch: "feature/login-page"},
+		}},
+	}
+
+	items := ByBranchPrefix(groups)
+
+	var got []string
+	for _, item := range items {
+		got = append(got, item.Label+"|"+item.RecentRepo)
+	}
+	want := []string{
+		"feature/|",
+		"feature/login|api",
+		"feature/login-page|web",
+		"fix/|",
+		"fix/crash|api",
+		NoPrefixLabel + "|",
+		"main|api",
+		"+ Add repository|",
+		"Settings|",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("items = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("items[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if items[0].Kind != model.ItemKindGroupHeader || items[0].Selectable {
+		t.Errorf("header = %+v, want an unselectable group header", items[0])
+	}
+	if items[2].RepoRootPath != "/code/web" || items[2].WorktreePath != "/wt/web-login" {
+		t.Errorf("rows should act like the worktree they stand for: %+v", items[2])
+	}
+}
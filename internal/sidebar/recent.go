@@ -0,0 +1,45 @@
+package sidebar
+
+import "github.com/mikanfactory/yakumo/internal/model"
+
+// RecentLabel is the header of the recently used worktrees section.
+const RecentLabel = "Recent"
+
+// Recent returns a "Recent" section listing up to limit worktrees from paths,
+// newest first, as rows to put above the repository groups. Paths that no
+// longer match a worktree in groups are skipped, and nil is returned when
+// none is left. The header is not selectable, and each row carries the name
+// of its repository in RecentRepo.
+func Recent(groups []model.RepoGroup, paths []string, limit int) []model.NavigableItem {
+	byPath := make(map[string]model.NavigableItem)
+	for _, group := range groups {
+		for _, wt := range group.Worktrees {
+			byPath[wt.Path] = model.NavigableItem{
+				Kind:         model.ItemKindWorktree,
+				Label:        wt.Branch,
+				Selectable:   true,
+				WorktreePath: wt.Path,
+				RepoRootPath: group.RootPath,
+				Status:       wt.Status,
+				IsBare:       wt.IsBare,
+				RecentRepo:   group.Name,
+			}
+		}
+	}
+
+	var rows []model.NavigableItem
+	for _, path := range paths {
+		if len(rows) == limit {
+			break
+		}
+		if item, ok := byPath[path]; ok {
+			rows = append(rows, item)
+			delete(byPath, path)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	header := model.NavigableItem{Kind: model.ItemKindGroupHeader, Label: RecentLabel}
+	return append([]model.NavigableItem{header}, rows...)
+}
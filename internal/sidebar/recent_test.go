@@ -0,0 +1,30 @@
+package sidebar
+
+import (
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestRecent(t *testing.T) {
+	groups := sortGroups()
+	paths := []string{"/wt/gone", "/wt/a", "/code/repo", "/wt/a", "/wt/b"}
+
+	items := Recent(groups, paths, 2)
+	if len(items) != 3 {
+		t.Fatalf("got %d items, want a header and two rows: %+v", len(items), items)
+	}
+	if items[0].Label != RecentLabel || items[0].Selectable {
+		t.Errorf("header = %+v, want an unselectable Recent header", items[0])
+	}
+	if items[1].Label != "alpha" || items[2].Label != "main" || items[1].RecentRepo != "repo" {
+		t.Errorf("rows = %+v, want alpha then main from repo", items[1:])
+	}
+	if items[1].Kind != model.ItemKindWorktree || items[1].RepoRootPath != "/code/repo" {
+		t.Errorf("rows should act like the worktree they stand for: %+v", items[1])
+	}
+
+	if got := Recent(groups, []string{"/wt/gone"}, 5); got != nil {
+		t.Errorf("Recent with no known paths = %+v, want nil", got)
+	}
+}
@@ -11,9 +11,10 @@ func BuildItems(groups []model.RepoGroup) []model.NavigableItem {
 
 	for _, group := range groups {
 		items = append(items, model.NavigableItem{
-			Kind:       model.ItemKindGroupHeader,
-			Label:      group.Name,
-			Selectable: false,
+			Kind:         model.ItemKindGroupHeader,
+			Label:        group.Name,
+			Selectable:   true,
+			RepoRootPath: group.RootPath,
 		})
 
 		for _, wt := range group.Worktrees {
@@ -25,6 +26,7 @@ func BuildItems(groups []model.RepoGroup) []model.NavigableItem {
 				RepoRootPath: group.RootPath,
 				Status:       wt.Status,
 				IsBare:       wt.IsBare,
+				Description:  wt.Description,
 			})
 		}
 
@@ -51,3 +53,24 @@ func BuildItems(groups []model.RepoGroup) []model.NavigableItem {
 
 	return items
 }
+
+// Collapse hides the worktree and "+ Add worktree" rows of every repository
+// in collapsed, marking its header as Collapsed.
+func Collapse(items []model.NavigableItem, collapsed map[string]bool) []model.NavigableItem {
+	if len(collapsed) == 0 {
+		return items
+	}
+	var out []model.NavigableItem
+	for _, item := range items {
+		switch item.Kind {
+		case model.ItemKindGroupHeader:
+			item.Collapsed = collapsed[item.RepoRootPath]
+		case model.ItemKindWorktree, model.ItemKindAddWorktree:
+			if collapsed[item.RepoRootPath] {
+				continue
+			}
+		}
+		out = append(out, item)
+	}
+	return out
+}
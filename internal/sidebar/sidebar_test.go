@@ -26,7 +26,7 @@ func TestBuildItems_SingleRepo(t *testing.T) {
 	}
 
 	// Group header
-	assertItem(t, items[0], model.ItemKindGroupHeader, "myrepo", false)
+	assertItem(t, items[0], model.ItemKindGroupHeader, "myrepo", true)
 	// Worktrees
 	assertItem(t, items[1], model.ItemKindWorktree, "main", true)
 	if items[1].WorktreePath != "/code/myrepo" {
@@ -78,10 +78,10 @@ func TestBuildItems_MultipleRepos(t *testing.T) {
 		t.Fatalf("len(items) = %d, want 9", len(items))
 	}
 
-	assertItem(t, items[0], model.ItemKindGroupHeader, "repo1", false)
+	assertItem(t, items[0], model.ItemKindGroupHeader, "repo1", true)
 	assertItem(t, items[1], model.ItemKindWorktree, "main", true)
 	assertItem(t, items[2], model.ItemKindAddWorktree, "+ Add worktree", true)
-	assertItem(t, items[3], model.ItemKindGroupHeader, "repo2", false)
+	assertItem(t, items[3], model.ItemKindGroupHeader, "repo2", true)
 	assertItem(t, items[4], model.ItemKindWorktree, "develop", true)
 	assertItem(t, items[5], model.ItemKindWorktree, "hotfix", true)
 	assertItem(t, items[6], model.ItemKindAddWorktree, "+ Add worktree", true)
@@ -117,7 +117,7 @@ func TestBuildItems_RepoWithNoWorktrees(t *testing.T) {
 		t.Fatalf("len(items) = %d, want 4", len(items))
 	}
 
-	assertItem(t, items[0], model.ItemKindGroupHeader, "empty-repo", false)
+	assertItem(t, items[0], model.ItemKindGroupHeader, "empty-repo", true)
 	assertItem(t, items[1], model.ItemKindAddWorktree, "+ Add worktree", true)
 	if items[1].RepoRootPath != "/code/empty-repo" {
 		t.Errorf("items[1].RepoRootPath = %q, want %q", items[1].RepoRootPath, "/code/empty-repo")
@@ -183,3 +183,26 @@ func assertItem(t *testing.T, item model.NavigableItem, kind model.ItemKind, lab
 		t.Errorf("Selectable = %v, want %v", item.Selectable, selectable)
 	}
 }
+
+func TestCollapse(t *testing.T) {
+	groups := []model.RepoGroup{
+		{Name: "repo1", RootPath: "/code/repo1", Worktrees: []model.WorktreeInfo{{Path: "/code/repo1", Branch: "main"}}},
+		{Name: "repo2", RootPath: "/code/repo2", Worktrees: []model.WorktreeInfo{{Path: "/code/repo2", Branch: "main"}}},
+	}
+
+	items := Collapse(BuildItems(groups), map[string]bool{"/code/repo1": true})
+
+	// Expected: collapsed repo1 header + repo2 header, worktree, add worktree + add repo + settings = 6
+	if len(items) != 6 {
+		t.Fatalf("len(items) = %d, want 6", len(items))
+	}
+	assertItem(t, items[0], model.ItemKindGroupHeader, "repo1", true)
+	if !items[0].Collapsed {
+		t.Error("repo1 header should be marked collapsed")
+	}
+	assertItem(t, items[1], model.ItemKindGroupHeader, "repo2", true)
+	if items[1].Collapsed {
+		t.Error("repo2 header should not be marked collapsed")
+	}
+	assertItem(t, items[2], model.ItemKindWorktree, "main", true)
+}
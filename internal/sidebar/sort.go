@@ -0,0 +1,93 @@
+package sidebar
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// SortMode orders the worktrees within each repository group.
+type SortMode string
+
+const (
+	SortCreated  SortMode = "created"  // oldest first, matching `git worktree list`
+	SortActivity SortMode = "activity" // most recent tmux activity first
+	SortDiffSize SortMode = "diff"     // most Insertions+Deletions first
+	SortName     SortMode = "name"     // branch name, alphabetical
+)
+
+// sortModes is the cycle order of the sort key.
+var sortModes = []SortMode{SortCreated, SortActivity, SortDiffSize, SortName}
+
+// ParseSortMode validates a `sort` value from the config. The empty string
+// selects SortCreated.
+func ParseSortMode(s string) (SortMode, bool) {
+	if s == "" {
+		return SortCreated, true
+	}
+	for _, mode := range sortModes {
+		if SortMode(s) == mode {
+			return mode, true
+		}
+	}
+	return SortCreated, false
+}
+
+// Next returns the mode after m in the cycle.
+func (m SortMode) Next() SortMode {
+	for i, mode := range sortModes {
+		if mode == m {
+			return sortModes[(i+1)%len(sortModes)]
+		}
+	}
+	return SortCreated
+}
+
+// Sort returns a copy of groups with each group's worktrees ordered by the
+// mode modeFor returns for its RootPath. activity maps worktree paths to the
+// last activity of their tmux session. Worktrees whose path is in pinned come
+// first, ordered among themselves by the same mode. Ties keep their original
+// order.
+func Sort(groups []model.RepoGroup, modeFor func(repoPath string) SortMode, activity map[string]time.Time, pinned map[string]bool) []model.RepoGroup {
+	sorted := make([]model.RepoGroup, len(groups))
+	for i, group := range groups {
+		worktrees := append([]model.WorktreeInfo(nil), group.Worktrees...)
+		less := sortLess(modeFor(group.RootPath), worktrees, activity)
+		sort.SliceStable(worktrees, func(i, j int) bool {
+			if pi, pj := pinned[worktrees[i].Path], pinned[worktrees[j].Path]; pi != pj {
+				return pi
+			}
+			return less(i, j)
+		})
+		group.Worktrees = worktrees
+		sorted[i] = group
+	}
+	return sorted
+}
+
+func sortLess(mode SortMode, wts []model.WorktreeInfo, activity map[string]time.Time) func(i, j int) bool {
+	switch mode {
+	case SortActivity:
+		return func(i, j int) bool {
+			return activity[wts[i].Path].After(activity[wts[j].Path])
+		}
+	case SortDiffSize:
+		return func(i, j int) bool {
+			return diffSize(wts[i]) > diffSize(wts[j])
+		}
+	case SortName:
+		return func(i, j int) bool {
+			return strings.ToLower(wts[i].Branch) < strings.ToLower(wts[j].Branch)
+		}
+	default:
+		return func(i, j int) bool {
+			return wts[i].CreatedAt.Before(wts[j].CreatedAt)
+		}
+	}
+}
+
+func diffSize(wt model.WorktreeInfo) int {
+	return wt.Status.Insertions + wt.Status.Deletions
+}
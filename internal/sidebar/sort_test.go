@@ -0,0 +1,106 @@
+package sidebar
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func sortGroups() []model.RepoGroup {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []model.RepoGroup{{
+		Name:     "repo",
+		RootPath: "/code/repo",
+		Worktrees: []model.WorktreeInfo{
+			{Path: "/code/repo", Branch: "main"},
+			{Path: "/wt/b", Branch: "Beta", CreatedAt: t0.Add(2 * time.Hour), Status: model.StatusInfo{Insertions: 1}},
+			{Path: "/wt/a", Branch: "alpha", CreatedAt: t0.Add(time.Hour), Status: model.StatusInfo{Insertions: 10, Deletions: 5}},
+		},
+	}}
+}
+
+func branches(groups []model.RepoGroup) []string {
+	var out []string
+	for _, wt := range groups[0].Worktrees {
+		out = append(out, wt.Branch)
+	}
+	return out
+}
+
+func TestSort(t *testing.T) {
+	activity := map[string]time.Time{
+		"/wt/b":      time.Unix(300, 0),
+		"/code/repo": time.Unix(200, 0),
+	}
+
+	tests := []struct {
+		mode SortMode
+		want []string
+	}{
+		{SortCreated, []string{"main", "alpha", "Beta"}},
+		{SortActivity, []string{"Beta", "main", "alpha"}},
+		{SortDiffSize, []string{"alpha", "Beta", "main"}},
+		{SortName, []string{"alpha", "Beta", "main"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			groups := sortGroups()
+			got := branches(Sort(groups, func(string) SortMode { return tt.mode }, activity, nil))
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("order = %v, want %v", got, tt.want)
+				}
+			}
+			if groups[0].Worktrees[0].Branch != "main" {
+				t.Error("Sort should not reorder the input groups")
+			}
+		})
+	}
+}
+
+func TestSort_PinnedFirst(t *testing.T) {
+	got := branches(Sort(sortGroups(), func(string) SortMode { return SortName }, nil, map[string]bool{"/wt/b": true}))
+	if want := []string{"Beta", "alpha", "main"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+
+	pinned := map[string]bool{"/wt/b": true, "/wt/a": true}
+	got = branches(Sort(sortGroups(), func(string) SortMode { return SortCreated }, nil, pinned))
+	if want := []string{"alpha", "Beta", "main"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("pinned worktrees should stay above main, order = %v, want %v", got, want)
+	}
+}
+
+func TestParseSortMode(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   SortMode
+		wantOK bool
+	}{
+		{"", SortCreated, true},
+		{"activity", SortActivity, true},
+		{"diff", SortDiffSize, true},
+		{"name", SortName, true},
+		{"size", SortCreated, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseSortMode(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseSortMode(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSortMode_NextCycles(t *testing.T) {
+	mode := SortCreated
+	seen := map[SortMode]bool{}
+	for range len(sortModes) {
+		seen[mode] = true
+		mode = mode.Next()
+	}
+	if mode != SortCreated || len(seen) != len(sortModes) {
+		t.Errorf("Next should visit every mode and wrap, ended at %q after %v", mode, seen)
+	}
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+const (
+	// agentHistoryAge is how long AgentHistory keeps events.
+	agentHistoryAge = 7 * 24 * time.Hour
+	// maxAgentEvents caps the events kept per worktree.
+	maxAgentEvents = 500
+)
+
+// AgentHistory records when the agents in each worktree changed state,
+// keyed by worktree path, oldest first.
+type AgentHistory struct {
+	File File
+}
+
+// Get returns the recorded events, or an empty map if none.
+func (s AgentHistory) Get() map[string][]model.AgentEvent {
+	history := map[string][]model.AgentEvent{}
+	if err := s.File.Load(&history); err != nil {
+		return map[string][]model.AgentEvent{}
+	}
+	return history
+}
+
+// Add appends events to the worktree at worktreePath, dropping those older
+// than a week.
+func (s AgentHistory) Add(worktreePath string, events []model.AgentEvent) error {
+	history := map[string][]model.AgentEvent{}
+	if err := s.File.Load(&history); err != nil {
+		return err
+	}
+	history[worktreePath] = append(history[worktreePath], events...)
+
+	cutoff := time.Now().Add(-agentHistoryAge)
+	for path, events := range history {
+		i := 0
+		for i < len(events) && events[i].At.Before(cutoff) {
+			i++
+		}
+		events = events[max(i, len(events)-maxAgentEvents):]
+		if len(events) == 0 {
+			delete(history, path)
+			continue
+		}
+		history[path] = events
+	}
+	return s.File.Save(history)
+}
@@ -0,0 +1,30 @@
+package state
+
+// CollapsedGroups remembers which repository groups are folded in the
+// sidebar, keyed by repository root path.
+type CollapsedGroups struct {
+	File File
+}
+
+// Get returns the collapsed repositories, or an empty map if none.
+func (s CollapsedGroups) Get() map[string]bool {
+	collapsed := map[string]bool{}
+	if err := s.File.Load(&collapsed); err != nil {
+		return map[string]bool{}
+	}
+	return collapsed
+}
+
+// Set records whether the group for repoPath is collapsed.
+func (s CollapsedGroups) Set(repoPath string, collapsed bool) error {
+	groups := map[string]bool{}
+	if err := s.File.Load(&groups); err != nil {
+		return err
+	}
+	if collapsed {
+		groups[repoPath] = true
+	} else {
+		delete(groups, repoPath)
+	}
+	return s.File.Save(groups)
+}
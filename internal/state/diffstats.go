@@ -0,0 +1,23 @@
+package state
+
+import "github.com/mikanfactory/yakumo/internal/git"
+
+// DiffStats keeps the sidebar's cached diff stats between runs, so opening
+// yakumo again does not re-run `git diff` in every unchanged worktree.
+type DiffStats struct {
+	File File
+}
+
+// Get returns the saved entries keyed by worktree path, or nil if none.
+func (s DiffStats) Get() map[string]git.StatCacheEntry {
+	var entries map[string]git.StatCacheEntry
+	if err := s.File.Load(&entries); err != nil {
+		return nil
+	}
+	return entries
+}
+
+// Save replaces the saved entries.
+func (s DiffStats) Save(entries map[string]git.StatCacheEntry) error {
+	return s.File.Save(entries)
+}
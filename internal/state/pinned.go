@@ -0,0 +1,30 @@
+package state
+
+// PinnedWorktrees remembers which worktrees are pinned to the top of their
+// repository group in the sidebar, keyed by worktree path.
+type PinnedWorktrees struct {
+	File File
+}
+
+// Get returns the pinned worktrees, or an empty map if none.
+func (s PinnedWorktrees) Get() map[string]bool {
+	pinned := map[string]bool{}
+	if err := s.File.Load(&pinned); err != nil {
+		return map[string]bool{}
+	}
+	return pinned
+}
+
+// Set records whether the worktree at worktreePath is pinned.
+func (s PinnedWorktrees) Set(worktreePath string, pinned bool) error {
+	worktrees := map[string]bool{}
+	if err := s.File.Load(&worktrees); err != nil {
+		return err
+	}
+	if pinned {
+		worktrees[worktreePath] = true
+	} else {
+		delete(worktrees, worktreePath)
+	}
+	return s.File.Save(worktrees)
+}
@@ -0,0 +1,21 @@
+package state
+
+// PortBlocks keeps the first port of the block assigned to each worktree,
+// keyed by worktree path.
+type PortBlocks struct {
+	File File
+}
+
+// Get returns the assigned blocks, or an empty map if none.
+func (s PortBlocks) Get() map[string]int {
+	blocks := map[string]int{}
+	if err := s.File.Load(&blocks); err != nil || blocks == nil {
+		return map[string]int{}
+	}
+	return blocks
+}
+
+// Save replaces the assigned blocks.
+func (s PortBlocks) Save(blocks map[string]int) error {
+	return s.File.Save(blocks)
+}
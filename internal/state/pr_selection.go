@@ -0,0 +1,26 @@
+package state
+
+// PRSelections remembers which pull request was picked for each worktree
+// when a branch has several open PRs.
+type PRSelections struct {
+	File File
+}
+
+// Get returns the remembered PR number for worktreePath, or 0 if none.
+func (s PRSelections) Get(worktreePath string) int {
+	selections := map[string]int{}
+	if err := s.File.Load(&selections); err != nil {
+		return 0
+	}
+	return selections[worktreePath]
+}
+
+// Set records number as the picked PR for worktreePath.
+func (s PRSelections) Set(worktreePath string, number int) error {
+	selections := map[string]int{}
+	if err := s.File.Load(&selections); err != nil {
+		return err
+	}
+	selections[worktreePath] = number
+	return s.File.Save(selections)
+}
@@ -0,0 +1,35 @@
+package state
+
+import "slices"
+
+// maxRecent is how many worktrees RecentWorktrees remembers.
+const maxRecent = 20
+
+// RecentWorktrees remembers the worktrees most recently jumped to from the
+// sidebar, newest first.
+type RecentWorktrees struct {
+	File File
+}
+
+// Get returns the remembered worktree paths, newest first.
+func (s RecentWorktrees) Get() []string {
+	var paths []string
+	if err := s.File.Load(&paths); err != nil {
+		return nil
+	}
+	return paths
+}
+
+// Add moves worktreePath to the front of the list.
+func (s RecentWorktrees) Add(worktreePath string) error {
+	var paths []string
+	if err := s.File.Load(&paths); err != nil {
+		return err
+	}
+	paths = slices.DeleteFunc(paths, func(p string) bool { return p == worktreePath })
+	paths = append([]string{worktreePath}, paths...)
+	if len(paths) > maxRecent {
+		paths = paths[:maxRecent]
+	}
+	return s.File.Save(paths)
+}
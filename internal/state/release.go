@@ -0,0 +1,29 @@
+package state
+
+import "time"
+
+// checkedRelease is the newest yakumo release seen and when it was asked for.
+type checkedRelease struct {
+	Tag string    `json:"tag"`
+	At  time.Time `json:"at"`
+}
+
+// LatestRelease remembers the newest yakumo release on GitHub, so the update
+// check asks for it once in a while rather than on every launch.
+type LatestRelease struct {
+	File File
+}
+
+// Get returns the release tag if it was asked for less than maxAge before now.
+func (s LatestRelease) Get(maxAge time.Duration, now time.Time) (string, bool) {
+	var checked checkedRelease
+	if err := s.File.Load(&checked); err != nil || checked.At.IsZero() || now.Sub(checked.At) >= maxAge {
+		return "", false
+	}
+	return checked.Tag, true
+}
+
+// Set remembers tag as the newest release at time at.
+func (s LatestRelease) Set(tag string, at time.Time) error {
+	return s.File.Save(checkedRelease{Tag: tag, At: at})
+}
@@ -0,0 +1,51 @@
+package state
+
+import "slices"
+
+// SavedSession is a worktree session yakumo set up, kept so it can be
+// recreated once the tmux server that held it is gone.
+type SavedSession struct {
+	Name           string `json:"name"`
+	WorktreePath   string `json:"worktree_path"`
+	RepoPath       string `json:"repo_path"`
+	StartupCommand string `json:"startup_command,omitempty"`
+}
+
+// SavedSessions remembers the worktree sessions yakumo set up, one per
+// worktree, oldest first.
+type SavedSessions struct {
+	File File
+}
+
+// Get returns the saved sessions.
+func (s SavedSessions) Get() []SavedSession {
+	var sessions []SavedSession
+	if err := s.File.Load(&sessions); err != nil {
+		return nil
+	}
+	return sessions
+}
+
+// Add saves session, replacing the one saved for the same worktree.
+func (s SavedSessions) Add(session SavedSession) error {
+	var sessions []SavedSession
+	if err := s.File.Load(&sessions); err != nil {
+		return err
+	}
+	if i := slices.IndexFunc(sessions, func(saved SavedSession) bool { return saved.WorktreePath == session.WorktreePath }); i >= 0 {
+		sessions[i] = session
+	} else {
+		sessions = append(sessions, session)
+	}
+	return s.File.Save(sessions)
+}
+
+// Remove forgets the sessions of the worktrees at worktreePaths.
+func (s SavedSessions) Remove(worktreePaths ...string) error {
+	var sessions []SavedSession
+	if err := s.File.Load(&sessions); err != nil {
+		return err
+	}
+	sessions = slices.DeleteFunc(sessions, func(saved SavedSession) bool { return slices.Contains(worktreePaths, saved.WorktreePath) })
+	return s.File.Save(sessions)
+}
@@ -0,0 +1,127 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"syscall"
+)
+
+// Dir returns the directory where yakumo keeps persisted UI state:
+// yakumo/users/<user> below $XDG_STATE_HOME, or ~/.local/state. Each user
+// gets a directory of their own so that users sharing a state directory on
+// one machine don't overwrite each other's files.
+func Dir() (string, error) {
+	base, err := baseDir()
+	if err != nil {
+		return "", err
+	}
+	if name := userName(); name != "" {
+		return filepath.Join(base, "users", name), nil
+	}
+	return base, nil
+}
+
+// baseDir returns the yakumo directory shared by every user.
+func baseDir() (string, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("getting home directory: %w", err)
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, "yakumo"), nil
+}
+
+// userName returns $USER, or the name of the current user when it is not
+// set. It is "" when neither is known, leaving the state shared.
+func userName() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// DefaultPath returns the path of a named state file inside Dir. A file of
+// that name left directly in the shared directory by an older version is
+// moved there first, when the current user owns it.
+func DefaultPath(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if base, err := baseDir(); err == nil && base != dir {
+		adoptLegacy(filepath.Join(base, name), path)
+	}
+	return path, nil
+}
+
+// adoptLegacy moves the state file at legacy to path when path does not
+// exist yet and legacy belongs to the current user. Failures leave both as
+// they were: the state then starts afresh.
+func adoptLegacy(legacy, path string) {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return
+	}
+	info, err := os.Stat(legacy)
+	if err != nil || !info.Mode().IsRegular() || !ownedByCurrentUser(info) {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	os.Rename(legacy, path)
+}
+
+// ownedByCurrentUser reports whether info is of a file the current user owns.
+func ownedByCurrentUser(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(st.Uid) == os.Getuid()
+}
+
+// File is a JSON document persisted at Path.
+type File struct {
+	Path string
+}
+
+// Load decodes the file into v. A missing file is not an error and leaves v untouched.
+func (f File) Load(v any) error {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading state file %s: %w", f.Path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing state file %s: %w", f.Path, err)
+	}
+	return nil
+}
+
+// Save encodes v as JSON and writes it atomically, creating parent directories.
+func (f File) Save(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+	tmp := f.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("writing state file %s: %w", f.Path, err)
+	}
+	if err := os.Rename(tmp, f.Path); err != nil {
+		return fmt.Errorf("replacing state file %s: %w", f.Path, err)
+	}
+	return nil
+}
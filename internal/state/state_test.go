@@ -0,0 +1,361 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestDir_XDGStateHome(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/xdg-state")
+	t.Setenv("USER", "alice")
+
+	dir, err := Dir()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dir != "/tmp/xdg-state/yakumo/users/alice" {
+		t.Errorf("Dir() = %q, want %q", dir, "/tmp/xdg-state/yakumo/users/alice")
+	}
+}
+
+func TestDir_DefaultsToHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("USER", "alice")
+
+	dir, err := Dir()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := filepath.Join(home, ".local", "state", "yakumo", "users", "alice")
+	if dir != want {
+		t.Errorf("Dir() = %q, want %q", dir, want)
+	}
+}
+
+func TestDir_SeparatesUsers(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/srv/shared-state")
+
+	t.Setenv("USER", "alice")
+	alice, err := DefaultPath("sessions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("USER", "bob")
+	bob, err := DefaultPath("sessions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alice == bob {
+		t.Errorf("alice and bob share %s", alice)
+	}
+}
+
+func TestDefaultPath_AdoptsLegacyFile(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", base)
+	t.Setenv("USER", "alice")
+	legacy := filepath.Join(base, "yakumo", "pinned_worktrees.json")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte(`["/wt/a"]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := DefaultPath("pinned_worktrees.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != `["/wt/a"]` {
+		t.Errorf("%s = %q, %v; want the legacy file moved there", path, data, err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("the legacy file should be gone, stat err = %v", err)
+	}
+}
+
+func TestFile_LoadMissing(t *testing.T) {
+	f := File{Path: filepath.Join(t.TempDir(), "missing.json")}
+
+	v := map[string]int{"keep": 1}
+	if err := f.Load(&v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v["keep"] != 1 {
+		t.Error("Load of a missing file should leave v untouched")
+	}
+}
+
+func TestFile_SaveAndLoad(t *testing.T) {
+	f := File{Path: filepath.Join(t.TempDir(), "nested", "state.json")}
+
+	if err := f.Save(map[string]int{"a": 1}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	got := map[string]int{}
+	if err := f.Load(&got); err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if got["a"] != 1 {
+		t.Errorf("got %v, want a=1", got)
+	}
+	if _, err := os.Stat(f.Path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary file should not remain after Save")
+	}
+}
+
+func TestFile_LoadInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.json")
+	os.WriteFile(path, []byte("not json"), 0o644)
+
+	var v map[string]int
+	if err := (File{Path: path}).Load(&v); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+}
+
+func TestPRSelections_GetSet(t *testing.T) {
+	s := PRSelections{File: File{Path: filepath.Join(t.TempDir(), "prs.json")}}
+
+	if got := s.Get("/wt/a"); got != 0 {
+		t.Errorf("Get() on empty store = %d, want 0", got)
+	}
+	if err := s.Set("/wt/a", 42); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if err := s.Set("/wt/b", 7); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if got := s.Get("/wt/a"); got != 42 {
+		t.Errorf("Get(/wt/a) = %d, want 42", got)
+	}
+	if got := s.Get("/wt/b"); got != 7 {
+		t.Errorf("Get(/wt/b) = %d, want 7", got)
+	}
+}
+
+func TestTodos_GetAdd(t *testing.T) {
+	s := Todos{File: File{Path: filepath.Join(t.TempDir(), "todos.json")}}
+
+	if got := s.Get("/wt/a"); got != nil {
+		t.Errorf("Get on empty store = %v, want nil", got)
+	}
+	if err := s.Add("/wt/a", "first"); err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+	if err := s.Add("/wt/a", "second"); err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+	got := s.Get("/wt/a")
+	if len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Errorf("Get = %v, want [first second]", got)
+	}
+	if got := s.Get("/wt/b"); got != nil {
+		t.Errorf("Get for other worktree = %v, want nil", got)
+	}
+}
+
+func TestCollapsedGroups_GetSet(t *testing.T) {
+	s := CollapsedGroups{File: File{Path: filepath.Join(t.TempDir(), "collapsed.json")}}
+
+	if got := s.Get(); len(got) != 0 {
+		t.Errorf("Get on empty store = %v, want empty", got)
+	}
+	if err := s.Set("/code/a", true); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if err := s.Set("/code/b", true); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if err := s.Set("/code/a", false); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	got := s.Get()
+	if len(got) != 1 || !got["/code/b"] {
+		t.Errorf("Get = %v, want only /code/b", got)
+	}
+}
+
+func TestPinnedWorktrees_GetSet(t *testing.T) {
+	s := PinnedWorktrees{File: File{Path: filepath.Join(t.TempDir(), "pinned.json")}}
+
+	if got := s.Get(); len(got) != 0 {
+		t.Errorf("Get on empty store = %v, want empty", got)
+	}
+	if err := s.Set("/wt/a", true); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if err := s.Set("/wt/b", true); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if err := s.Set("/wt/a", false); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	got := s.Get()
+	if len(got) != 1 || !got["/wt/b"] {
+		t.Errorf("Get = %v, want only /wt/b", got)
+	}
+}
+
+func TestRecentWorktrees_Add(t *testing.T) {
+	s := RecentWorktrees{File: File{Path: filepath.Join(t.TempDir(), "recent.json")}}
+
+	if got := s.Get(); len(got) != 0 {
+		t.Errorf("Get on empty store = %v, want empty", got)
+	}
+	for _, path := range []string{"/wt/a", "/wt/b", "/wt/a"} {
+		if err := s.Add(path); err != nil {
+			t.Fatalf("Add error: %v", err)
+		}
+	}
+	if got := s.Get(); len(got) != 2 || got[0] != "/wt/a" || got[1] != "/wt/b" {
+		t.Errorf("Get = %v, want [/wt/a /wt/b]", got)
+	}
+
+	for i := range maxRecent + 5 {
+		if err := s.Add(fmt.Sprintf("/wt/%d", i)); err != nil {
+			t.Fatalf("Add error: %v", err)
+		}
+	}
+	if got := s.Get(); len(got) != maxRecent {
+		t.Errorf("len(Get) = %d, want %d", len(got), maxRecent)
+	}
+}
+
+func TestDiffStats_GetSave(t *testing.T) {
+	s := DiffStats{File: File{Path: filepath.Join(t.TempDir(), "diff_stats.json")}}
+
+	if got := s.Get(); len(got) != 0 {
+		t.Errorf("Get on empty store = %v, want empty", got)
+	}
+	index := time.Date(2026, 1, 2, 3, 4, 5, 6, time.Local)
+	entry := git.StatCacheEntry{Head: "abc", Index: index, BaseRef: "origin/main", Status: model.StatusInfo{Insertions: 3, Ahead: 1}, At: index}
+	if err := s.Save(map[string]git.StatCacheEntry{"/wt/a": entry}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	got := s.Get()["/wt/a"]
+	if got.Head != "abc" || !got.Index.Equal(index) || got.Status != entry.Status {
+		t.Errorf("Get = %+v, want %+v", got, entry)
+	}
+}
+
+func TestAgentHistory_AddDropsOldEvents(t *testing.T) {
+	s := AgentHistory{File: File{Path: filepath.Join(t.TempDir(), "agent_history.json")}}
+
+	if got := s.Get(); len(got) != 0 {
+		t.Errorf("Get on empty store = %v, want empty", got)
+	}
+	old := model.AgentEvent{PaneID: "%1", State: model.AgentStateRunning, At: time.Now().Add(-8 * 24 * time.Hour)}
+	if err := s.Add("/wt/old", []model.AgentEvent{old}); err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+	recent := model.AgentEvent{PaneID: "%2", Agent: "claude", State: model.AgentStateIdle, At: time.Now()}
+	if err := s.Add("/wt/a", []model.AgentEvent{recent}); err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+
+	got := s.Get()
+	if _, ok := got["/wt/old"]; ok {
+		t.Error("events older than a week should be dropped")
+	}
+	if len(got["/wt/a"]) != 1 || got["/wt/a"][0].Agent != "claude" || got["/wt/a"][0].State != model.AgentStateIdle {
+		t.Errorf("Get[/wt/a] = %+v, want the recent event", got["/wt/a"])
+	}
+}
+
+func TestSavedSessions_AddRemove(t *testing.T) {
+	s := SavedSessions{File: File{Path: filepath.Join(t.TempDir(), "sessions.json")}}
+
+	if got := s.Get(); len(got) != 0 {
+		t.Errorf("Get on empty store = %v, want empty", got)
+	}
+	for _, session := range []SavedSession{
+		{Name: "a", WorktreePath: "/wt/a", RepoPath: "/repo"},
+		{Name: "b", WorktreePath: "/wt/b", RepoPath: "/repo", StartupCommand: "direnv allow"},
+		{Name: "feat-a", WorktreePath: "/wt/a", RepoPath: "/repo"},
+	} {
+		if err := s.Add(session); err != nil {
+			t.Fatalf("Add error: %v", err)
+		}
+	}
+	got := s.Get()
+	if len(got) != 2 || got[0].Name != "feat-a" || got[1].StartupCommand != "direnv allow" {
+		t.Errorf("Get = %+v, want feat-a then b", got)
+	}
+
+	if err := s.Remove("/wt/a"); err != nil {
+		t.Fatalf("Remove error: %v", err)
+	}
+	if got := s.Get(); len(got) != 1 || got[0].WorktreePath != "/wt/b" {
+		t.Errorf("Get after Remove = %+v, want only /wt/b", got)
+	}
+}
+
+func TestStatusLines_GetSet(t *testing.T) {
+	s := StatusLines{File: File{Path: filepath.Join(t.TempDir(), "status_lines.json")}}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if _, ok := s.Get("tmux\x00/wt/a", 5*time.Second, now); ok {
+		t.Error("Get on empty store should miss")
+	}
+	if err := s.Set("tmux\x00/wt/old", "old", now.Add(-2*time.Hour)); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if err := s.Set("tmux\x00/wt/a", "✳ 1 main", now); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+
+	if got, ok := s.Get("tmux\x00/wt/a", 5*time.Second, now.Add(4*time.Second)); !ok || got != "✳ 1 main" {
+		t.Errorf("Get = %q, %v, want the cached line", got, ok)
+	}
+	if _, ok := s.Get("tmux\x00/wt/a", 5*time.Second, now.Add(5*time.Second)); ok {
+		t.Error("a line as old as maxAge should miss")
+	}
+	if _, ok := s.Get("tmux\x00/wt/old", 24*time.Hour, now); ok {
+		t.Error("lines older than an hour should be dropped on Set")
+	}
+}
+
+func TestWorktreeTemplates_GetSet(t *testing.T) {
+	s := WorktreeTemplates{File: File{Path: filepath.Join(t.TempDir(), "templates.json")}}
+
+	if got := s.Get(); len(got) != 0 {
+		t.Errorf("Get on empty store = %v, want empty", got)
+	}
+	if err := s.Set("/wt/a", "bugfix"); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if err := s.Set("/wt/b", "experiment"); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if got := s.Get(); len(got) != 2 || got["/wt/a"] != "bugfix" || got["/wt/b"] != "experiment" {
+		t.Errorf("Get = %v", got)
+	}
+}
+
+func TestLatestRelease_GetSet(t *testing.T) {
+	s := LatestRelease{File: File{Path: filepath.Join(t.TempDir(), "latest_release.json")}}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if _, ok := s.Get(24*time.Hour, now); ok {
+		t.Error("Get on empty store should miss")
+	}
+	if err := s.Set("v0.5.0", now); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if got, ok := s.Get(24*time.Hour, now.Add(time.Hour)); !ok || got != "v0.5.0" {
+		t.Errorf("Get = %q, %v, want v0.5.0", got, ok)
+	}
+	if _, ok := s.Get(24*time.Hour, now.Add(24*time.Hour)); ok {
+		t.Error("a check as old as maxAge should miss")
+	}
+}
@@ -0,0 +1,47 @@
+package state
+
+import "time"
+
+// statusLineAge is how long StatusLines keeps a line nobody asked for again.
+const statusLineAge = time.Hour
+
+// StatusLine is a rendered `yakumo status` line and when it was built.
+type StatusLine struct {
+	Line string    `json:"line"`
+	At   time.Time `json:"at"`
+}
+
+// StatusLines caches the lines printed by `yakumo status`, keyed by format
+// and directory, so the tmux status bar can call it every few seconds.
+type StatusLines struct {
+	File File
+}
+
+// Get returns the line cached under key if it was built less than maxAge
+// before now.
+func (s StatusLines) Get(key string, maxAge time.Duration, now time.Time) (string, bool) {
+	lines := map[string]StatusLine{}
+	if err := s.File.Load(&lines); err != nil {
+		return "", false
+	}
+	cached, ok := lines[key]
+	if !ok || now.Sub(cached.At) >= maxAge {
+		return "", false
+	}
+	return cached.Line, true
+}
+
+// Set caches line under key, dropping lines older than an hour.
+func (s StatusLines) Set(key, line string, at time.Time) error {
+	lines := map[string]StatusLine{}
+	if err := s.File.Load(&lines); err != nil {
+		return err
+	}
+	for k, cached := range lines {
+		if at.Sub(cached.At) > statusLineAge {
+			delete(lines, k)
+		}
+	}
+	lines[key] = StatusLine{Line: line, At: at}
+	return s.File.Save(lines)
+}
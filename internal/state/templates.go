@@ -0,0 +1,27 @@
+package state
+
+// WorktreeTemplates remembers which template each worktree was created
+// with, keyed by worktree path, so its session is set up from it.
+type WorktreeTemplates struct {
+	File File
+}
+
+// Get returns the template name of each worktree, or an empty map if none.
+func (s WorktreeTemplates) Get() map[string]string {
+	templates := map[string]string{}
+	if err := s.File.Load(&templates); err != nil || templates == nil {
+		return map[string]string{}
+	}
+	return templates
+}
+
+// Set records that the worktree at worktreePath was created with the
+// template called name.
+func (s WorktreeTemplates) Set(worktreePath, name string) error {
+	templates := map[string]string{}
+	if err := s.File.Load(&templates); err != nil {
+		return err
+	}
+	templates[worktreePath] = name
+	return s.File.Save(templates)
+}
@@ -0,0 +1,25 @@
+package state
+
+// Todos holds per-worktree todo items shown in the diff UI's Checks tab.
+type Todos struct {
+	File File
+}
+
+// Get returns the todos recorded for worktreePath, or nil if none.
+func (s Todos) Get(worktreePath string) []string {
+	todos := map[string][]string{}
+	if err := s.File.Load(&todos); err != nil {
+		return nil
+	}
+	return todos[worktreePath]
+}
+
+// Add appends todo to the list for worktreePath.
+func (s Todos) Add(worktreePath, todo string) error {
+	todos := map[string][]string{}
+	if err := s.File.Load(&todos); err != nil {
+		return err
+	}
+	todos[worktreePath] = append(todos[worktreePath], todo)
+	return s.File.Save(todos)
+}
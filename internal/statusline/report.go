@@ -0,0 +1,177 @@
+package statusline
+
+import (
+	"cmp"
+
+	"github.com/mikanfactory/yakumo/internal/agent"
+	"github.com/mikanfactory/yakumo/internal/forge"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+// Report is what `yakumo status --json` prints for dashboards: the agent
+// counts of the status line and every configured repository with its
+// worktrees.
+type Report struct {
+	Running      int          `json:"running"`
+	Waiting      int          `json:"waiting"`
+	Repositories []Repository `json:"repositories"`
+}
+
+// Repository is a configured repository in a Report.
+type Repository struct {
+	Name      string     `json:"name"`
+	Path      string     `json:"path"`
+	Worktrees []Worktree `json:"worktrees"`
+	Error     string     `json:"error,omitempty"` // why its worktrees could not be listed
+}
+
+// Worktree is a worktree in a Report.
+type Worktree struct {
+	Path        string  `json:"path"`
+	Branch      string  `json:"branch"` // "(detached)" for a detached HEAD
+	Primary     bool    `json:"primary"`
+	Session     string  `json:"session,omitempty"` // the tmux session, while it runs
+	Diff        Diff    `json:"diff"`              // the branch against the base ref
+	Uncommitted Diff    `json:"uncommitted"`
+	PR          *PR     `json:"pr,omitempty"` // the open PR of the branch
+	Agents      []Agent `json:"agents"`
+}
+
+// Diff is a count of changed lines and, for a branch, of the commits it is
+// ahead of and behind the base ref.
+type Diff struct {
+	Insertions int `json:"insertions"`
+	Deletions  int `json:"deletions"`
+	Ahead      int `json:"ahead,omitempty"`
+	Behind     int `json:"behind,omitempty"`
+}
+
+// PR is the open pull request of a worktree's branch.
+type PR struct {
+	Number int    `json:"number"`
+	Checks string `json:"checks"` // none, pending, passing or failing
+}
+
+// Agent is a coding agent running in a pane of a worktree's session.
+type Agent struct {
+	Pane    string `json:"pane"`
+	Agent   string `json:"agent"`
+	State   string `json:"state"`             // idle, running or waiting
+	Elapsed string `json:"elapsed,omitempty"` // while running
+}
+
+// CollectReport builds the Report of repos. Branches are compared with each
+// repository's base_ref, or baseRef. tmuxRunner may be nil, leaving out the
+// agents. PRs are asked for on each repository's forge with forgeOpts, and
+// left out where its runner is missing. Like Collect, a failure only leaves
+// the matching fields empty.
+func CollectReport(gitRunner git.CommandRunner, tmuxRunner tmux.Runner, forgeOpts forge.Options, profiles []agent.Profile, repos []model.RepositoryDef, baseRef string) Report {
+	var bySession map[string][]model.AgentInfo
+	if tmuxRunner != nil {
+		bySession, _ = agent.DetectAllAgents(tmuxRunner, profiles)
+	}
+	sessions := make(map[string]bool, len(bySession))
+	for name := range bySession {
+		sessions[name] = true
+	}
+
+	report := Report{Repositories: []Repository{}}
+	for _, repo := range repos {
+		r := Repository{Name: repo.Name, Path: repo.Path, Worktrees: []Worktree{}}
+		entries, err := git.ListWorktrees(gitRunner, repo.Path)
+		if err != nil {
+			r.Error = err.Error()
+			report.Repositories = append(report.Repositories, r)
+			continue
+		}
+		infos := git.ToWorktreeInfo(entries)
+		prs := openPRs(forgeOpts, repo, infos)
+		base := cmp.Or(repo.BaseRef, baseRef)
+		for _, info := range infos {
+			if info.IsBare {
+				continue
+			}
+			wt := Worktree{Path: info.Path, Branch: info.Branch, Primary: info.Path == repo.Path, PR: prs[info.Branch], Agents: []Agent{}}
+			if stat, err := git.GetBranchDiffStat(gitRunner, info.Path, base); err == nil {
+				wt.Diff = Diff{Insertions: stat.Insertions, Deletions: stat.Deletions, Ahead: stat.Ahead, Behind: stat.Behind}
+			}
+			if stat, err := git.GetUncommittedStat(gitRunner, info.Path); err == nil {
+				wt.Uncommitted = Diff{Insertions: stat.Insertions, Deletions: stat.Deletions}
+			}
+			if name := tmux.MatchSessionName(sessions, info.Path, info.Branch); sessions[name] {
+				wt.Session = name
+			}
+			for _, a := range bySession[wt.Session] {
+				switch a.State {
+				case model.AgentStateRunning:
+					report.Running++
+				case model.AgentStateWaiting:
+					report.Waiting++
+				}
+				wt.Agents = append(wt.Agents, Agent{Pane: a.PaneID, Agent: a.Agent, State: agentStateName(a.State), Elapsed: a.Elapsed})
+			}
+			r.Worktrees = append(r.Worktrees, wt)
+		}
+		report.Repositories = append(report.Repositories, r)
+	}
+	return report
+}
+
+// openPRs returns the open PRs of repo keyed by head branch. Providers that
+// cannot list them all at once are asked for the branch of each worktree.
+func openPRs(opts forge.Options, repo model.RepositoryDef, worktrees []model.WorktreeInfo) map[string]*PR {
+	provider, err := forge.New(forge.KindFor(repo.Forge, opts.GitRunner, repo.Path), opts)
+	if err != nil {
+		return nil
+	}
+	prs := make(map[string]*PR)
+	if lister, ok := provider.(forge.OpenPRLister); ok {
+		views, err := lister.FetchOpenPRs(repo.Path)
+		if err != nil {
+			return nil
+		}
+		for _, pr := range views {
+			prs[pr.HeadRefName] = &PR{Number: pr.Number, Checks: checksName(pr.CheckRollup())}
+		}
+		return prs
+	}
+	for _, wt := range worktrees {
+		if wt.IsBare || wt.Branch == "" || wt.Branch == "(detached)" {
+			continue
+		}
+		views, err := provider.FetchPRs(repo.Path, wt.Branch)
+		if err != nil {
+			return nil
+		}
+		if len(views) > 0 {
+			prs[wt.Branch] = &PR{Number: views[0].Number, Checks: checksName(views[0].CheckRollup())}
+		}
+	}
+	return prs
+}
+
+func checksName(rollup string) string {
+	switch rollup {
+	case github.RollupSuccess:
+		return "passing"
+	case github.RollupFailure:
+		return "failing"
+	case github.RollupPending:
+		return "pending"
+	}
+	return "none"
+}
+
+func agentStateName(state model.AgentState) string {
+	switch state {
+	case model.AgentStateRunning:
+		return "running"
+	case model.AgentStateWaiting:
+		return "waiting"
+	default:
+		return "idle"
+	}
+}
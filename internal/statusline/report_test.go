@@ -0,0 +1,106 @@
+package statusline
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/forge"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/gitlab"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+func TestCollectReport(t *testing.T) {
+	gitRunner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/a:[worktree list --porcelain]": "worktree /code/a\nbranch refs/heads/main\n\n" +
+				"worktree /wt/feat\nbranch refs/heads/feat\n",
+			"/code/a:[remote get-url origin]":                             "git@github.com:owner/a.git\n",
+			"/wt/feat:[diff origin/main...HEAD --numstat]":                "10\t2\tlogin.go\n",
+			"/wt/feat:[rev-list --left-right --count origin/main...HEAD]": "1\t3\n",
+			"/wt/feat:[diff HEAD --numstat]":                              "4\t0\tlogin.go\n",
+		},
+	}
+	ghRunner := &github.FakeRunner{
+		Outputs: map[string]string{
+			"/code/a:[pr list --state open --limit 100 --json number,headRefName,statusCheckRollup]": `[{"number": 12, "headRefName": "feat", "statusCheckRollup": [{"status": "COMPLETED", "conclusion": "FAILURE"}]}]`,
+		},
+	}
+	tmuxRunner := &tmux.FakeRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("%v", []string{"list-panes", "-a", "-F", "#{session_name}\t#{pane_id}\t#{pane_title}\t#{pane_current_command}"}): "feat\t%0\t✳ claude\tnode\n",
+			fmt.Sprintf("%v", []string{"capture-pane", "-p", "-t", "%0"}):                                                                "  ❯ ",
+		},
+	}
+	repos := []model.RepositoryDef{{Name: "a", Path: "/code/a"}, {Name: "b", Path: "/code/b"}}
+
+	report := CollectReport(gitRunner, tmuxRunner, forge.Options{GitHubRunner: ghRunner, GitRunner: gitRunner}, nil, repos, "origin/main")
+	if len(report.Repositories) != 2 || report.Repositories[1].Error == "" {
+		t.Fatalf("repositories = %+v, want b with an error", report.Repositories)
+	}
+	worktrees := report.Repositories[0].Worktrees
+	if len(worktrees) != 2 || !worktrees[0].Primary || worktrees[0].PR != nil {
+		t.Fatalf("worktrees = %+v, want main first without a PR", worktrees)
+	}
+	feat := worktrees[1]
+	if feat.Diff != (Diff{Insertions: 10, Deletions: 2, Ahead: 3, Behind: 1}) || feat.Uncommitted != (Diff{Insertions: 4}) {
+		t.Errorf("diff = %+v, uncommitted = %+v", feat.Diff, feat.Uncommitted)
+	}
+	if feat.PR == nil || *feat.PR != (PR{Number: 12, Checks: "failing"}) {
+		t.Errorf("pr = %+v, want #12 failing", feat.PR)
+	}
+	if feat.Session != "feat" || len(feat.Agents) != 1 || feat.Agents[0].Agent != "claude" || feat.Agents[0].Pane != "%0" {
+		t.Errorf("session = %q, agents = %+v, want claude in %%0 of feat", feat.Session, feat.Agents)
+	}
+}
+
+func TestCollectReport_NoTmuxOrGitHub(t *testing.T) {
+	gitRunner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/a:[worktree list --porcelain]": "worktree /code/a\nbranch refs/heads/main\n",
+		},
+	}
+
+	report := CollectReport(gitRunner, nil, forge.Options{GitRunner: gitRunner}, nil, []model.RepositoryDef{{Name: "a", Path: "/code/a"}}, "origin/main")
+	wt := report.Repositories[0].Worktrees[0]
+	if wt.Session != "" || wt.PR != nil || wt.Agents == nil || len(wt.Agents) != 0 {
+		t.Errorf("worktree = %+v, want no session, PR or agents", wt)
+	}
+}
+
+func TestCollectReport_GitLabAndRepoBaseRef(t *testing.T) {
+	gitRunner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/a:[worktree list --porcelain]": "worktree /code/a\nbranch refs/heads/develop\n\n" +
+				"worktree /wt/feat\nbranch refs/heads/feat\n",
+			"/wt/feat:[diff origin/develop...HEAD --numstat]":                "10\t2\tlogin.go\n",
+			"/wt/feat:[rev-list --left-right --count origin/develop...HEAD]": "0\t1\n",
+		},
+	}
+	glabRunner := &gitlab.FakeRunner{
+		Outputs: map[string]string{
+			"/code/a:[mr list --source-branch feat --output json]":       `[{"iid": 7, "source_branch": "feat"}]`,
+			"/code/a:[mr list --source-branch develop --output json]":    `[]`,
+			"/code/a:[mr view 7 --output json]":                          `{"iid": 7, "source_branch": "feat", "state": "opened", "head_pipeline": {"id": 99}}`,
+			"/code/a:[api projects/:id/merge_requests/7/approvals]":      `{"approved_by": []}`,
+			"/code/a:[api projects/:id/merge_requests/7/notes?sort=asc]": `[]`,
+			"/code/a:[api projects/:id/pipelines/99/jobs]":               `[{"name": "test", "stage": "test", "status": "success"}]`,
+		},
+	}
+	repos := []model.RepositoryDef{{Name: "a", Path: "/code/a", Forge: forge.KindGitLab, BaseRef: "origin/develop"}}
+
+	report := CollectReport(gitRunner, nil, forge.Options{GitLabRunner: glabRunner, GitRunner: gitRunner}, nil, repos, "origin/main")
+	worktrees := report.Repositories[0].Worktrees
+	if len(worktrees) != 2 {
+		t.Fatalf("worktrees = %+v", worktrees)
+	}
+	feat := worktrees[1]
+	if feat.Diff != (Diff{Insertions: 10, Deletions: 2, Ahead: 1}) {
+		t.Errorf("diff = %+v, want it against origin/develop", feat.Diff)
+	}
+	if feat.PR == nil || *feat.PR != (PR{Number: 7, Checks: "passing"}) {
+		t.Errorf("pr = %+v, want !7 passing", feat.PR)
+	}
+}
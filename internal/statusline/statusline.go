@@ -0,0 +1,98 @@
+// Package statusline builds the compact summary `yakumo status` prints for
+// the tmux status bar: agent counts across every worktree session and the
+// branch and uncommitted changes of one directory. With --json it prints a
+// Report of every repository and worktree instead.
+package statusline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mikanfactory/yakumo/internal/agent"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+// Format is how a Summary is printed.
+type Format string
+
+const (
+	// FormatPlain prints the summary as plain text (the default).
+	FormatPlain Format = "plain"
+	// FormatTmux colors the summary with tmux #[fg=...] style directives.
+	FormatTmux Format = "tmux"
+)
+
+// ParseFormat parses the --format flag. An empty string means FormatPlain.
+func ParseFormat(s string) (Format, bool) {
+	switch Format(s) {
+	case "", FormatPlain:
+		return FormatPlain, true
+	case FormatTmux:
+		return FormatTmux, true
+	}
+	return "", false
+}
+
+// Summary is what the status line shows.
+type Summary struct {
+	Running int
+	Waiting int
+	Branch  string           // "" outside a git work tree or on a detached HEAD
+	Dirty   model.StatusInfo // uncommitted changes of the directory
+}
+
+// Collect counts the agents of every worktree session and reads the branch
+// and uncommitted changes of dir. tmuxRunner may be nil, and a missing tmux
+// server or a dir outside git only leaves the matching fields empty.
+func Collect(gitRunner git.CommandRunner, tmuxRunner tmux.Runner, profiles []agent.Profile, dir string) Summary {
+	var s Summary
+	if tmuxRunner != nil {
+		statuses, _ := agent.DetectAllAgents(tmuxRunner, profiles)
+		for _, agents := range statuses {
+			for _, a := range agents {
+				switch a.State {
+				case model.AgentStateRunning:
+					s.Running++
+				case model.AgentStateWaiting:
+					s.Waiting++
+				}
+			}
+		}
+	}
+	if dir != "" {
+		s.Branch, _ = git.CurrentBranch(gitRunner, dir)
+		s.Dirty, _ = git.GetUncommittedStat(gitRunner, dir)
+	}
+	return s
+}
+
+// Render prints s in format, leaving out the parts that are zero or empty,
+// e.g. "⚠ 1 ✳ 2 feat/login +12 -3".
+func (s Summary) Render(format Format) string {
+	var parts []string
+	add := func(color, text string) {
+		if format == FormatTmux {
+			// A # in a branch name would start a tmux directive.
+			text = fmt.Sprintf("#[fg=%s]%s#[default]", color, strings.ReplaceAll(text, "#", "##"))
+		}
+		parts = append(parts, text)
+	}
+	if s.Waiting > 0 {
+		add("cyan", fmt.Sprintf("⚠ %d", s.Waiting))
+	}
+	if s.Running > 0 {
+		add("yellow", fmt.Sprintf("✳ %d", s.Running))
+	}
+	if s.Branch != "" {
+		add("default", s.Branch)
+	}
+	if s.Dirty.Insertions > 0 {
+		add("green", fmt.Sprintf("+%d", s.Dirty.Insertions))
+	}
+	if s.Dirty.Deletions > 0 {
+		add("red", fmt.Sprintf("-%d", s.Dirty.Deletions))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,62 @@
+package statusline
+
+import (
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+func TestParseFormat(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want Format
+		ok   bool
+	}{
+		{"", FormatPlain, true},
+		{"plain", FormatPlain, true},
+		{"tmux", FormatTmux, true},
+		{"json", "", false},
+	} {
+		if got, ok := ParseFormat(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	gitRunner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/wt/feat:[symbolic-ref --short HEAD]": "feat/login\n",
+			"/wt/feat:[diff HEAD --numstat]":       "12\t3\tlogin.go\n",
+		},
+	}
+	// No tmux server: only the git fields are filled.
+	tmuxRunner := &tmux.FakeRunner{}
+
+	got := Collect(gitRunner, tmuxRunner, nil, "/wt/feat")
+	want := Summary{Branch: "feat/login", Dirty: model.StatusInfo{Insertions: 12, Deletions: 3}}
+	if got != want {
+		t.Errorf("Collect = %+v, want %+v", got, want)
+	}
+
+	if got := Collect(gitRunner, nil, nil, "/not-a-repo"); got != (Summary{}) {
+		t.Errorf("Collect outside git = %+v, want empty", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	s := Summary{Running: 2, Waiting: 1, Branch: "fix/#42", Dirty: model.StatusInfo{Insertions: 12, Deletions: 3}}
+
+	if got, want := s.Render(FormatPlain), "⚠ 1 ✳ 2 fix/#42 +12 -3"; got != want {
+		t.Errorf("plain = %q, want %q", got, want)
+	}
+	want := "#[fg=cyan]⚠ 1#[default] #[fg=yellow]✳ 2#[default] #[fg=default]fix/##42#[default] #[fg=green]+12#[default] #[fg=red]-3#[default]"
+	if got := s.Render(FormatTmux); got != want {
+		t.Errorf("tmux = %q, want %q", got, want)
+	}
+	if got := (Summary{Branch: "main"}).Render(FormatPlain); got != "main" {
+		t.Errorf("clean = %q, want only the branch", got)
+	}
+}
@@ -0,0 +1,203 @@
+// Package tasks runs a repository's tasks, such as test, lint and build, in
+// tmux panes and reads back how each went from a status file the pane
+// appends to. A task is a single command; rb_commands run as one task that
+// stops at the first failing command. Status files outlive the run, so the
+// outcome of the last run of each task in a worktree is remembered.
+package tasks
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/state"
+)
+
+// RBCommands is the name the rb_commands of a repository run under.
+const RBCommands = "rb_commands"
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ValidateName checks a task name from the config: it names a file.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) || name == "." || name == ".." {
+		return fmt.Errorf("%q: must be letters, digits, '_', '.' and '-'", name)
+	}
+	if name == RBCommands {
+		return fmt.Errorf("%q: is reserved for rb_commands", name)
+	}
+	return nil
+}
+
+// State is where a command is in a run.
+type State int
+
+const (
+	Pending State = iota
+	Running
+	Passed
+	Failed
+	Skipped // an earlier command failed
+)
+
+func (s State) String() string {
+	switch s {
+	case Running:
+		return "running"
+	case Passed:
+		return "passed"
+	case Failed:
+		return "failed"
+	case Skipped:
+		return "skipped"
+	default:
+		return "pending"
+	}
+}
+
+// Check is one command of a run.
+type Check struct {
+	Command  string
+	State    State
+	ExitCode int
+}
+
+// StatusPath returns the status file of the task called name in
+// worktreePath inside the yakumo state directory.
+func StatusPath(worktreePath, name string) (string, error) {
+	dir := strings.Trim(filepath.ToSlash(filepath.Clean(worktreePath)), "/")
+	return state.DefaultPath(filepath.Join("tasks", strings.ReplaceAll(dir, "/", "-")+"."+name+".status"))
+}
+
+// Reset empties the status file at statusPath for a new run.
+func Reset(statusPath string) error {
+	if err := os.MkdirAll(filepath.Dir(statusPath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(statusPath, nil, 0o644)
+}
+
+// Script returns the command line that runs commands in order with sh,
+// stopping at the first failure, and records each start and exit status in
+// statusPath. It is typed into a pane, whatever shell runs there. A run
+// interrupted with Ctrl-C, or whose pane is closed, records the command it
+// was in as failed on its way out rather than leaving it running for good.
+func Script(commands []string, statusPath string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "s=%s; trap 'exit 129' HUP; trap 'exit 130' INT; trap 'exit 143' TERM; "+
+		`trap 'c=$?; [ -z "$i" ] || echo done $i $c >> "$s"' EXIT; `, shellQuote(statusPath))
+	for i, command := range commands {
+		fmt.Fprintf(&b, `printf '\n==> %%s\n' %s; i=%d; echo start %d >> "$s"; (%s); c=$?; echo done %d $c >> "$s"; i=; [ $c -eq 0 ] || exit $c; `,
+			shellQuote(command), i, i, command, i)
+	}
+	return "sh -c " + shellQuote(strings.TrimSuffix(b.String(), " "))
+}
+
+// Read returns the checks of commands as recorded in the status file at
+// statusPath so far. A missing file reads as nothing started.
+func Read(commands []string, statusPath string) ([]Check, error) {
+	checks := make([]Check, len(commands))
+	for i, command := range commands {
+		checks[i] = Check{Command: command}
+	}
+	f, err := os.Open(statusPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return checks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	failed := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		i, err := strconv.Atoi(fields[1])
+		if err != nil || i < 0 || i >= len(checks) {
+			continue
+		}
+		switch {
+		case fields[0] == "start":
+			checks[i].State = Running
+		case fields[0] == "done" && len(fields) == 3:
+			code, _ := strconv.Atoi(fields[2])
+			checks[i].ExitCode = code
+			checks[i].State = Passed
+			if code != 0 {
+				checks[i].State = Failed
+				failed = true
+			}
+		}
+	}
+	if failed {
+		for i := range checks {
+			if checks[i].State == Pending {
+				checks[i].State = Skipped
+			}
+		}
+	}
+	return checks, scanner.Err()
+}
+
+// UpdatedAt returns when the status file at statusPath last changed, about
+// when its run finished, or the zero time when it never ran.
+func UpdatedAt(statusPath string) time.Time {
+	info, err := os.Stat(statusPath)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// Finished reports whether no command of checks is still to run.
+func Finished(checks []Check) bool {
+	for _, c := range checks {
+		if c.State == Pending || c.State == Running {
+			return false
+		}
+	}
+	return true
+}
+
+// Summarize folds the checks of a run into the state of the whole task:
+// running while any command runs, failed once one failed, passed when all
+// passed and pending when it has not run.
+func Summarize(checks []Check) Check {
+	var summary Check
+	passed := 0
+	for _, c := range checks {
+		switch c.State {
+		case Running:
+			return c
+		case Failed:
+			summary = c
+		case Passed:
+			passed++
+		}
+	}
+	if summary.State == Failed {
+		return summary
+	}
+	if len(checks) > 0 && passed == len(checks) {
+		return Check{State: Passed}
+	}
+	if passed > 0 {
+		// Between two commands of a sequence.
+		return Check{State: Running}
+	}
+	return Check{}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
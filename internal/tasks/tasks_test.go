@@ -0,0 +1,124 @@
+package tasks
+
+import (
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func states(checks []Check) []State {
+	var out []State
+	for _, c := range checks {
+		out = append(out, c.State)
+	}
+	return out
+}
+
+func TestScriptStopsAtFirstFailure(t *testing.T) {
+	status := filepath.Join(t.TempDir(), "rb", "wt.status")
+	if err := Reset(status); err != nil {
+		t.Fatal(err)
+	}
+	commands := []string{"echo 'it''s fine'", "exit 3", "echo never"}
+
+	checks, err := Read(commands, status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := states(checks); !slices.Equal(got, []State{Pending, Pending, Pending}) || Finished(checks) {
+		t.Errorf("before the run: %v", got)
+	}
+
+	// The failing command makes the line exit non-zero.
+	_ = exec.Command("sh", "-c", Script(commands, status)).Run()
+
+	checks, err = Read(commands, status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := states(checks); !slices.Equal(got, []State{Passed, Failed, Skipped}) {
+		t.Errorf("states = %v, want passed, failed, skipped", got)
+	}
+	if checks[1].ExitCode != 3 || !Finished(checks) {
+		t.Errorf("checks = %+v", checks)
+	}
+}
+
+func TestScriptRecordsInterruptedRun(t *testing.T) {
+	status := filepath.Join(t.TempDir(), "wt.status")
+	if err := Reset(status); err != nil {
+		t.Fatal(err)
+	}
+	// $$ is the script's shell, signalled as Ctrl-C or a closed pane would.
+	commands := []string{"true", "kill -TERM $$", "echo never"}
+
+	_ = exec.Command("sh", "-c", Script(commands, status)).Run()
+
+	checks, err := Read(commands, status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := states(checks); !slices.Equal(got, []State{Passed, Failed, Skipped}) {
+		t.Errorf("states = %v, want passed, failed, skipped", got)
+	}
+	if checks[1].ExitCode != 143 || !Finished(checks) {
+		t.Errorf("checks = %+v, want the interrupted command failed with 143", checks)
+	}
+}
+
+func TestReadRunning(t *testing.T) {
+	status := filepath.Join(t.TempDir(), "wt.status")
+	if err := exec.Command("sh", "-c", "printf 'start 0\\ndone 0 0\\nstart 1\\n' > "+status).Run(); err != nil {
+		t.Fatal(err)
+	}
+	checks, err := Read([]string{"make test", "make lint", "make build"}, status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := states(checks); !slices.Equal(got, []State{Passed, Running, Pending}) || Finished(checks) {
+		t.Errorf("states = %v, want passed, running, pending", got)
+	}
+}
+
+func TestReadMissingFile(t *testing.T) {
+	checks, err := Read([]string{"make test"}, filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(checks) != 1 || checks[0].State != Pending {
+		t.Errorf("Read = %+v, %v", checks, err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		states []State
+		want   State
+	}{
+		{[]State{Pending, Pending}, Pending},
+		{[]State{Passed, Running}, Running},
+		{[]State{Passed, Pending}, Running},
+		{[]State{Passed, Failed, Skipped}, Failed},
+		{[]State{Passed, Passed}, Passed},
+	}
+	for _, tt := range tests {
+		var checks []Check
+		for _, s := range tt.states {
+			checks = append(checks, Check{State: s})
+		}
+		if got := Summarize(checks).State; got != tt.want {
+			t.Errorf("Summarize(%v) = %v, want %v", tt.states, got, tt.want)
+		}
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"test", "lint-js", "e2e_ci", "build.prod"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "a/b", "..", "run tests", RBCommands} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q) = nil, want an error", name)
+		}
+	}
+}
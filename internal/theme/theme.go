@@ -0,0 +1,135 @@
+// Package theme holds the color palettes the TUIs derive their styles from,
+// chosen with the `theme` config.
+package theme
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// Theme is a color palette. Every style of the sidebar, diff-ui and the
+// setup spinner is built from these colors.
+type Theme struct {
+	Fg        lipgloss.Color // regular text
+	FgDim     lipgloss.Color // help lines, headers, secondary text
+	Accent    lipgloss.Color // the cursor and highlights in the sidebar
+	Secondary lipgloss.Color // the cursor and links in diff-ui
+	Green     lipgloss.Color // additions, passing checks, idle agents
+	Red       lipgloss.Color // deletions, failures, errors
+	Yellow    lipgloss.Color // pending checks, search matches, running agents
+	Cyan      lipgloss.Color // sidebar actions, agents waiting for input
+	Selection lipgloss.Color // background of the selected row in diff-ui
+}
+
+// DefaultPreset is the preset used when the config names none.
+const DefaultPreset = "dark"
+
+// Presets are the built-in themes.
+var Presets = map[string]Theme{
+	// Catppuccin Mocha, for dark terminals.
+	"dark": {
+		Fg:        "#cdd6f4",
+		FgDim:     "#6c7086",
+		Accent:    "#89b4fa",
+		Secondary: "#f5c2e7",
+		Green:     "#a6e3a1",
+		Red:       "#f38ba8",
+		Yellow:    "#f9e2af",
+		Cyan:      "#89dceb",
+		Selection: "#313244",
+	},
+	// Catppuccin Latte, for light terminals.
+	"light": {
+		Fg:        "#4c4f69",
+		FgDim:     "#8c8fa1",
+		Accent:    "#1e66f5",
+		Secondary: "#ea76cb",
+		Green:     "#40a02b",
+		Red:       "#d20f39",
+		Yellow:    "#df8e1d",
+		Cyan:      "#04a5e5",
+		Selection: "#ccd0da",
+	},
+	// The 256-color palette diff-ui used before themes, for terminals
+	// without true color.
+	"classic": {
+		Fg:        "255",
+		FgDim:     "240",
+		Accent:    "111",
+		Secondary: "212",
+		Green:     "82",
+		Red:       "196",
+		Yellow:    "220",
+		Cyan:      "117",
+		Selection: "236",
+	},
+}
+
+// colorPattern matches the colors lipgloss understands: #rgb, #rrggbb or an
+// ANSI 256-color number.
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|[0-9]{1,3})$`)
+
+// Resolve builds the theme of cfg: its preset (DefaultPreset when unset)
+// with cfg.Colors, keyed by snake_case name such as fg_dim, laid over it.
+func Resolve(cfg model.ThemeConfig) (Theme, error) {
+	name := cfg.Preset
+	if name == "" {
+		name = DefaultPreset
+	}
+	t, ok := Presets[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("theme.preset %q: must be one of %s", cfg.Preset, strings.Join(slices.Sorted(maps.Keys(Presets)), ", "))
+	}
+
+	fields := t.fields()
+	for _, key := range slices.Sorted(maps.Keys(cfg.Colors)) {
+		value := cfg.Colors[key]
+		field, ok := fields[key]
+		if !ok {
+			return Theme{}, fmt.Errorf("theme.colors.%s: unknown color (want one of %s)", key, strings.Join(slices.Sorted(maps.Keys(fields)), ", "))
+		}
+		if !validColor(value) {
+			return Theme{}, fmt.Errorf("theme.colors.%s %q: must be #rgb, #rrggbb or a 256-color number", key, value)
+		}
+		*field = lipgloss.Color(value)
+	}
+	return t, nil
+}
+
+func (t *Theme) fields() map[string]*lipgloss.Color {
+	return map[string]*lipgloss.Color{
+		"fg":        &t.Fg,
+		"fg_dim":    &t.FgDim,
+		"accent":    &t.Accent,
+		"secondary": &t.Secondary,
+		"green":     &t.Green,
+		"red":       &t.Red,
+		"yellow":    &t.Yellow,
+		"cyan":      &t.Cyan,
+		"selection": &t.Selection,
+	}
+}
+
+func validColor(s string) bool {
+	if !colorPattern.MatchString(s) {
+		return false
+	}
+	if s[0] == '#' {
+		return true
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n <= 255
+}
+
+// Default returns the default preset.
+func Default() Theme {
+	return Presets[DefaultPreset]
+}
@@ -0,0 +1,62 @@
+package theme
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestResolve_DefaultPreset(t *testing.T) {
+	got, err := Resolve(model.ThemeConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != Presets[DefaultPreset] {
+		t.Errorf("Resolve of an empty config = %+v, want the %s preset", got, DefaultPreset)
+	}
+}
+
+func TestResolve_OverridesColors(t *testing.T) {
+	got, err := Resolve(model.ThemeConfig{
+		Preset: "light",
+		Colors: map[string]string{"accent": "#ff8800", "fg_dim": "244"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Accent != lipgloss.Color("#ff8800") || got.FgDim != lipgloss.Color("244") {
+		t.Errorf("Accent = %q, FgDim = %q; want the overrides", got.Accent, got.FgDim)
+	}
+	if got.Fg != Presets["light"].Fg {
+		t.Errorf("Fg = %q, want the light preset's", got.Fg)
+	}
+	if Presets["light"].Accent == "#ff8800" {
+		t.Error("Resolve should not change the preset itself")
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	for name, cfg := range map[string]model.ThemeConfig{
+		"unknown preset": {Preset: "solarized"},
+		"unknown color":  {Colors: map[string]string{"purple": "#800080"}},
+		"not a color":    {Colors: map[string]string{"red": "crimson"}},
+		"out of range":   {Colors: map[string]string{"red": "300"}},
+		"bad hex":        {Colors: map[string]string{"red": "#ff00"}},
+	} {
+		if _, err := Resolve(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestPresetsSetEveryColor(t *testing.T) {
+	for name, p := range Presets {
+		for key, c := range p.fields() {
+			if *c == "" {
+				t.Errorf("preset %s leaves %s unset", name, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package tmux
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SessionActivity returns the last activity time of every tmux session,
+// keyed by session name.
+func SessionActivity(runner Runner) (map[string]time.Time, error) {
+	out, err := Query(runner, "list-sessions", "-F", "#{session_name}\t#{session_activity}")
+	if err != nil {
+		return nil, err
+	}
+	return parseSessionActivity(out), nil
+}
+
+// parseSessionActivity parses "name\tunix-seconds" lines, skipping any that
+// do not carry a timestamp.
+func parseSessionActivity(output string) map[string]time.Time {
+	activity := make(map[string]time.Time)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		name, ts, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		secs, err := strconv.ParseInt(strings.TrimSpace(ts), 10, 64)
+		if err != nil {
+			continue
+		}
+		activity[name] = time.Unix(secs, 0)
+	}
+	return activity
+}
+
+// SessionPaths returns the start directory of every tmux session, keyed by
+// session name. yakumo starts worktree sessions in the worktree directory.
+func SessionPaths(runner Runner) (map[string]string, error) {
+	out, err := Query(runner, "list-sessions", "-F", "#{session_name}\t#{session_path}")
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if name, path, ok := strings.Cut(line, "\t"); ok && path != "" {
+			paths[name] = path
+		}
+	}
+	return paths, nil
+}
+
+// LeavingSessionPath returns the start directory of the session the user is
+// switching away from: the current session, or the client's previous one
+// when yakumo runs in the main session. In window mode it is the current
+// window's worktree. It returns "" when there is none.
+func LeavingSessionPath(runner Runner) (string, error) {
+	if mode == ModeWindow {
+		name, err := CurrentWorktreeName(runner)
+		if err != nil {
+			return "", err
+		}
+		paths, err := WorktreePaths(runner)
+		if err != nil {
+			return "", err
+		}
+		return paths[name], nil
+	}
+	name, err := CurrentSessionName(runner)
+	if err != nil {
+		return "", err
+	}
+	if name == MainSession() {
+		out, err := Query(runner, "display-message", "-p", "#{client_last_session}")
+		if err != nil {
+			return "", err
+		}
+		name = strings.TrimSpace(out)
+	}
+	if name == "" || name == MainSession() {
+		return "", nil
+	}
+	paths, err := SessionPaths(runner)
+	if err != nil {
+		return "", err
+	}
+	return paths[name], nil
+}
@@ -0,0 +1,77 @@
+package tmux
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionActivity(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[list-sessions -F #{session_name}\t#{session_activity}]": "main\t1700000000\nfeature-x\t1700000100\nbroken\tnope\n",
+		},
+	}
+
+	got, err := SessionActivity(runner)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d sessions, want 2: %v", len(got), got)
+	}
+	if !got["feature-x"].Equal(time.Unix(1700000100, 0)) {
+		t.Errorf("feature-x = %v, want %v", got["feature-x"], time.Unix(1700000100, 0))
+	}
+}
+
+func TestSessionPaths(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[list-sessions -F #{session_name}\t#{session_path}]": "main\t/home/me\nfeature-x\t/wt/feature-x\nodd\t\n",
+		},
+	}
+
+	got, err := SessionPaths(runner)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got["feature-x"] != "/wt/feature-x" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestLeavingSessionPath(t *testing.T) {
+	t.Setenv("TMUX_PANE", "")
+	sessions := "yakumo-main\t/home/me\nfeature-x\t/wt/feature-x\n"
+
+	fromWorktree := &FakeRunner{
+		Outputs: map[string]string{
+			"[display-message -p #{session_name}]":                "feature-x\n",
+			"[list-sessions -F #{session_name}\t#{session_path}]": sessions,
+		},
+	}
+	if got, err := LeavingSessionPath(fromWorktree); err != nil || got != "/wt/feature-x" {
+		t.Errorf("from a worktree session = %q, %v", got, err)
+	}
+
+	fromMain := &FakeRunner{
+		Outputs: map[string]string{
+			"[display-message -p #{session_name}]":                "yakumo-main\n",
+			"[display-message -p #{client_last_session}]":         "feature-x\n",
+			"[list-sessions -F #{session_name}\t#{session_path}]": sessions,
+		},
+	}
+	if got, err := LeavingSessionPath(fromMain); err != nil || got != "/wt/feature-x" {
+		t.Errorf("from the main session = %q, %v; want the previous session's path", got, err)
+	}
+
+	firstVisit := &FakeRunner{
+		Outputs: map[string]string{
+			"[display-message -p #{session_name}]":        "yakumo-main\n",
+			"[display-message -p #{client_last_session}]": "\n",
+		},
+	}
+	if got, err := LeavingSessionPath(firstVisit); err != nil || got != "" {
+		t.Errorf("without a previous session = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,73 @@
+package tmux
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// envName matches the names a repository's env config may set.
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ExpandEnv checks the variable names of a repository's env config and
+// executes each value as a template with data, so that every worktree can
+// get its own value, e.g. DATABASE_URL: postgres://localhost/app_{{.Slug}}.
+func ExpandEnv(env map[string]string, data SessionNameData) (map[string]string, error) {
+	expanded := make(map[string]string, len(env))
+	for name, value := range env {
+		if !envName.MatchString(name) {
+			return nil, fmt.Errorf("invalid variable name %q", name)
+		}
+		t, err := template.New(name).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		if err := t.Execute(&b, data); err != nil {
+			return nil, err
+		}
+		expanded[name] = b.String()
+	}
+	return expanded, nil
+}
+
+// InjectEnv exports env into a session just created for a worktree. The
+// variables are set in the session environment, which panes opened later
+// inherit, and exported in every pane of layout, whose shells are already
+// running. In window mode the main session is shared by every worktree, so
+// only the panes get them.
+func InjectEnv(runner Runner, layout SessionLayout, env map[string]string) error {
+	if len(env) == 0 {
+		return nil
+	}
+	names := slices.Sorted(maps.Keys(env))
+	if mode != ModeWindow {
+		for _, name := range names {
+			if _, err := runner.Run("set-environment", "-t", "="+layout.SessionName, name, env[name]); err != nil {
+				return fmt.Errorf("setting %s in session %s: %w", name, layout.SessionName, err)
+			}
+		}
+	}
+
+	assignments := make([]string, len(names))
+	for i, name := range names {
+		assignments[i] = name + "=" + shellQuote(env[name])
+	}
+	export := "export " + strings.Join(assignments, " ")
+	for _, name := range PaneNames {
+		if id := layout.PaneID(name); id != "" {
+			if err := SendKeys(runner, id, export); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
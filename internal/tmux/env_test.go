@@ -0,0 +1,70 @@
+package tmux
+
+import "testing"
+
+func TestExpandEnv(t *testing.T) {
+	got, err := ExpandEnv(map[string]string{
+		"DATABASE_URL": "postgres://localhost/{{.Repo}}_{{.Slug}}",
+		"RAILS_ENV":    "development",
+	}, SessionNameData{Repo: "app", Slug: "feat"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["DATABASE_URL"] != "postgres://localhost/app_feat" || got["RAILS_ENV"] != "development" {
+		t.Errorf("ExpandEnv = %v", got)
+	}
+
+	for _, env := range []map[string]string{
+		{"1PORT": "3000"},
+		{"BAD-NAME": "x"},
+		{"URL": "{{.Branch}}"},
+		{"URL": "{{.Slug"},
+	} {
+		if _, err := ExpandEnv(env, SessionNameData{}); err == nil {
+			t.Errorf("ExpandEnv(%v) should fail", env)
+		}
+	}
+}
+
+func TestInjectEnv(t *testing.T) {
+	env := map[string]string{"RAILS_ENV": "development", "DATABASE_URL": "postgres://it's/app"}
+	export := `export DATABASE_URL='postgres://it'\''s/app' RAILS_ENV='development'`
+	layout := SessionLayout{
+		SessionName:  "feat",
+		Center1:      Pane{PaneID: "%0"},
+		TopRight1:    Pane{PaneID: "%1"},
+		BottomRight1: Pane{PaneID: "%2"},
+	}
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[set-environment -t =feat DATABASE_URL postgres://it's/app]": "",
+			"[set-environment -t =feat RAILS_ENV development]":            "",
+			"[send-keys -t %0 " + export + " Enter]":                      "",
+			"[send-keys -t %1 " + export + " Enter]":                      "",
+			"[send-keys -t %2 " + export + " Enter]":                      "",
+		},
+	}
+
+	if err := InjectEnv(runner, layout, env); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runner.Calls) != 5 {
+		t.Errorf("calls = %v, want two set-environment and three exports", runner.Calls)
+	}
+
+	SetMode(ModeWindow)
+	defer SetMode(ModeSession)
+	runner.Calls = nil
+	if err := InjectEnv(runner, layout, env); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, call := range runner.Calls {
+		if call[0] == "set-environment" {
+			t.Errorf("window mode should not set the shared main session's environment, got %v", call)
+		}
+	}
+
+	if err := InjectEnv(&FakeRunner{}, layout, nil); err != nil {
+		t.Errorf("no env should run nothing, got %v", err)
+	}
+}
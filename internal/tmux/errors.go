@@ -0,0 +1,79 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Sentinel errors classifying tmux failures. Use errors.Is to test for them.
+var (
+	ErrTmuxNotFound     = errors.New("tmux executable not found")
+	ErrNoServer         = errors.New("no tmux server running")
+	ErrSessionNotFound  = errors.New("tmux session not found")
+	ErrPaneNotFound     = errors.New("tmux pane not found")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrServerBusy       = errors.New("tmux server temporarily unavailable")
+)
+
+// CommandError is returned by OSRunner when tmux exits with an error.
+type CommandError struct {
+	Args   []string
+	Stderr string
+	Kind   error // one of the sentinel errors above, or nil
+	Err    error // underlying exec error
+}
+
+func (e *CommandError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("tmux %v failed: %s", e.Args, e.Stderr)
+	}
+	return fmt.Sprintf("tmux %v failed: %v", e.Args, e.Err)
+}
+
+func (e *CommandError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+func newCommandError(args []string, stderr string, err error) *CommandError {
+	return &CommandError{Args: args, Stderr: stderr, Kind: classify(stderr, err), Err: err}
+}
+
+func classify(stderr string, err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrTmuxNotFound
+	}
+	lower := strings.ToLower(stderr)
+	switch {
+	case strings.Contains(lower, "no server running"), strings.Contains(lower, "error connecting to"):
+		return ErrNoServer
+	case strings.Contains(lower, "can't find session"), strings.Contains(lower, "session not found"):
+		return ErrSessionNotFound
+	case strings.Contains(lower, "can't find pane"):
+		return ErrPaneNotFound
+	case strings.Contains(lower, "permission denied"):
+		return ErrPermissionDenied
+	case strings.Contains(lower, "server exited unexpectedly"),
+		strings.Contains(lower, "lost server"),
+		strings.Contains(lower, "resource temporarily unavailable"):
+		return ErrServerBusy
+	default:
+		return nil
+	}
+}
+
+// IsUnavailable reports whether err means tmux itself cannot be used (not
+// installed or no server), as opposed to a missing session or pane.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrTmuxNotFound) || errors.Is(err, ErrNoServer)
+}
+
+// IsTransient reports whether err is likely to succeed if the query is simply
+// run again.
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrServerBusy)
+}
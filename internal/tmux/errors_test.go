@@ -0,0 +1,93 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/retry"
+)
+
+func TestCommandError_Classification(t *testing.T) {
+	exitErr := fmt.Errorf("exit status 1")
+	tests := []struct {
+		name   string
+		stderr string
+		err    error
+		want   error
+	}{
+		{name: "no server", stderr: "no server running on /tmp/tmux-501/default", err: exitErr, want: ErrNoServer},
+		{name: "connect failure", stderr: "error connecting to /tmp/tmux-501/default (No such file or directory)", err: exitErr, want: ErrNoServer},
+		{name: "missing session", stderr: "can't find session: foo", err: exitErr, want: ErrSessionNotFound},
+		{name: "missing pane", stderr: "can't find pane: %9", err: exitErr, want: ErrPaneNotFound},
+		{name: "tmux missing", err: exec.ErrNotFound, want: ErrTmuxNotFound},
+		{name: "unclassified", stderr: "unknown command: foo", err: exitErr, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newCommandError([]string{"has-session"}, tt.stderr, tt.err)
+			if err.Kind != tt.want {
+				t.Errorf("Kind = %v, want %v", err.Kind, tt.want)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "no server", err: fmt.Errorf("wrapped: %w", ErrNoServer), want: true},
+		{name: "not installed", err: ErrTmuxNotFound, want: true},
+		{name: "missing session", err: ErrSessionNotFound, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUnavailable(tt.err); got != tt.want {
+				t.Errorf("IsUnavailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasSession_Unavailable(t *testing.T) {
+	runner := &FakeRunner{
+		Errors: map[string]error{
+			"[has-session -t =my-session]": newCommandError(nil, "no server running on /tmp/tmux", fmt.Errorf("exit status 1")),
+		},
+	}
+
+	exists, err := HasSession(runner, "my-session")
+	if exists {
+		t.Error("expected session to not exist")
+	}
+	if !errors.Is(err, ErrNoServer) {
+		t.Errorf("err = %v, want ErrNoServer", err)
+	}
+}
+
+func TestQuery_RetriesServerBusy(t *testing.T) {
+	defer func(p retry.Policy) { retry.Default = p }(retry.Default)
+	retry.Default.Sleep = func(time.Duration) {}
+
+	busy := newCommandError(nil, "server exited unexpectedly", fmt.Errorf("exit status 1"))
+	runner := &FakeRunner{
+		Errors: map[string]error{"[list-windows]": busy},
+	}
+
+	_, err := Query(runner, "list-windows")
+	if !errors.Is(err, ErrServerBusy) {
+		t.Errorf("err = %v, want ErrServerBusy", err)
+	}
+	if len(runner.Calls) != retry.Default.Attempts {
+		t.Errorf("calls = %d, want %d", len(runner.Calls), retry.Default.Attempts)
+	}
+}
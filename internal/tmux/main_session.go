@@ -10,10 +10,15 @@ const MainSessionName = "yakumo-main"
 
 const yakumoLogoCommand = `npx oh-my-logo "yakumo" ocean --filled --block-font block`
 
+// MainSession returns the main session name with the configured prefix.
+func MainSession() string {
+	return SessionName(MainSessionName)
+}
+
 // EnsureMainSession checks if the yakumo main session exists, and creates it if not.
 // When creating a new session, it displays the yakumo logo via npx oh-my-logo.
 func EnsureMainSession(runner Runner) error {
-	exists, err := HasSession(runner, MainSessionName)
+	exists, err := HasSession(runner, MainSession())
 	if err != nil {
 		return fmt.Errorf("checking main session: %w", err)
 	}
@@ -26,12 +31,12 @@ func EnsureMainSession(runner Runner) error {
 		homeDir = "/"
 	}
 
-	if _, err := runner.Run("new-session", "-d", "-s", MainSessionName, "-c", homeDir); err != nil {
+	if _, err := runner.Run("new-session", "-d", "-s", MainSession(), "-c", homeDir); err != nil {
 		return fmt.Errorf("creating main session: %w", err)
 	}
 
 	// Display yakumo logo (non-fatal)
-	SendKeys(runner, MainSessionName, yakumoLogoCommand)
+	SendKeys(runner, MainSession(), yakumoLogoCommand)
 
 	return nil
 }
@@ -50,7 +55,7 @@ func SwitchToMainSession(runner Runner) error {
 	if err := EnsureMainSession(runner); err != nil {
 		return err
 	}
-	if _, err := runner.Run("switch-client", "-t", MainSessionName); err != nil {
+	if _, err := runner.Run("switch-client", "-t", MainSession()); err != nil {
 		return fmt.Errorf("switching to main session: %w", err)
 	}
 	return nil
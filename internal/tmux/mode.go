@@ -0,0 +1,288 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Mode is how worktrees are laid out in tmux, from the tmux_mode config.
+type Mode string
+
+const (
+	// ModeSession gives each worktree its own session (the default).
+	ModeSession Mode = "session"
+	// ModeWindow gives each worktree a window of the main session instead.
+	// Its window has the main-window panes but no background window.
+	ModeWindow Mode = "window"
+)
+
+// ParseMode parses the tmux_mode config. An empty string means ModeSession.
+func ParseMode(s string) (Mode, bool) {
+	switch Mode(s) {
+	case "", ModeSession:
+		return ModeSession, true
+	case ModeWindow:
+		return ModeWindow, true
+	}
+	return "", false
+}
+
+var mode = ModeSession
+
+// SetMode sets how worktrees are laid out for every function of this package
+// that finds, creates, switches to, renames or kills a worktree's session.
+func SetMode(m Mode) {
+	if m == "" {
+		m = ModeSession
+	}
+	mode = m
+}
+
+// WindowMode reports whether worktrees get a window of the main session.
+func WindowMode() bool {
+	return mode == ModeWindow
+}
+
+// pathOption is the window option holding the worktree path of a worktree
+// window, which a window, unlike a session, has no start directory for.
+const pathOption = "@yakumo-worktree"
+
+// worktreeTarget is the tmux target of the worktree session or, in window
+// mode, the worktree window of the main session with the given name.
+func worktreeTarget(name string) string {
+	if mode == ModeWindow {
+		return "=" + MainSession() + ":=" + name
+	}
+	return "=" + name
+}
+
+// worktreeWindows lists the windows of the main session with the given
+// format after their name, or none when there is no main session.
+func worktreeWindows(runner Runner, format string) (map[string]string, error) {
+	exists, err := HasSession(runner, MainSession())
+	if err != nil || !exists {
+		return nil, err
+	}
+	out, err := Query(runner, "list-windows", "-t", "="+MainSession(), "-F", "#{window_name}\t"+format)
+	if err != nil {
+		return nil, err
+	}
+	windows := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		// Trimming the output drops the tab of a last line with no value.
+		if name, value, _ := strings.Cut(line, "\t"); name != "" {
+			windows[name] = value
+		}
+	}
+	return windows, nil
+}
+
+// HasWorktree checks if the session, or window in window mode, of a worktree
+// exists. Like HasSession, it returns an error only when tmux itself is
+// unavailable.
+func HasWorktree(runner Runner, name string) (bool, error) {
+	if mode != ModeWindow {
+		return HasSession(runner, name)
+	}
+	windows, err := worktreeWindows(runner, "")
+	if err != nil {
+		if IsUnavailable(err) {
+			return false, err
+		}
+		return false, nil
+	}
+	_, ok := windows[name]
+	return ok, nil
+}
+
+// KillWorktree terminates the session, or window in window mode, of a
+// worktree.
+func KillWorktree(runner Runner, name string) error {
+	if mode == ModeWindow {
+		_, err := runner.Run("kill-window", "-t", worktreeTarget(name))
+		return err
+	}
+	return KillSession(runner, name)
+}
+
+// RenameWorktree renames the session, or window in window mode, of a
+// worktree.
+func RenameWorktree(runner Runner, oldName, newName string) error {
+	if mode == ModeWindow {
+		_, err := runner.Run("rename-window", "-t", worktreeTarget(oldName), newName)
+		return err
+	}
+	return RenameSession(runner, oldName, newName)
+}
+
+// ListWorktreePanes lists every pane that can belong to a worktree with the
+// given format, each line starting with the name of the worktree's session,
+// or window in window mode, and a tab.
+func ListWorktreePanes(runner Runner, format string) (string, error) {
+	if mode == ModeWindow {
+		return Query(runner, "list-panes", "-s", "-t", "="+MainSession(), "-F", "#{window_name}\t"+format)
+	}
+	return Query(runner, "list-panes", "-a", "-F", "#{session_name}\t"+format)
+}
+
+// ListPanesOfWorktree lists the panes of the session, or window in window
+// mode, of one worktree with the given format.
+func ListPanesOfWorktree(runner Runner, name string, format string) (string, error) {
+	if mode == ModeWindow {
+		return Query(runner, "list-panes", "-t", worktreeTarget(name), "-F", format)
+	}
+	return Query(runner, "list-panes", "-s", "-t", name, "-F", format)
+}
+
+// CreateWindowLayout creates the window of a worktree in the main session,
+// with the 3 panes of main-window, and returns a SessionLayout named after
+// the window with only those panes.
+// If startupCommand is non-empty, it is run before splitting.
+func CreateWindowLayout(runner Runner, windowName string, startDir string, startupCommand string) (SessionLayout, error) {
+	if err := EnsureMainSession(runner); err != nil {
+		return SessionLayout{}, err
+	}
+	out, err := runner.Run("new-window", "-d", "-P", "-F", "#{window_id}", "-t", "="+MainSession()+":", "-n", windowName, "-c", startDir)
+	if err != nil {
+		return SessionLayout{}, fmt.Errorf("creating window %s: %w", windowName, err)
+	}
+	window := strings.TrimSpace(out)
+
+	if _, err := runner.Run("set-option", "-w", "-t", window, pathOption, startDir); err != nil {
+		return SessionLayout{}, fmt.Errorf("recording the worktree of window %s: %w", windowName, err)
+	}
+
+	if startupCommand != "" {
+		if _, err := runner.Run("run-shell", "-c", startDir, startupCommand); err != nil {
+			// Non-fatal: startup command failure should not block window creation
+		}
+	}
+
+	if err := splitMainLayout(runner, window, startDir); err != nil {
+		return SessionLayout{}, err
+	}
+
+	out, err = Query(runner, "list-panes", "-t", window, "-F", "#{pane_id}")
+	if err != nil {
+		return SessionLayout{}, fmt.Errorf("listing panes for %s: %w", windowName, err)
+	}
+	ids := parsePaneIDs(out)
+	if len(ids) != 3 {
+		return SessionLayout{}, fmt.Errorf("expected 3 panes in window %s, got %d", windowName, len(ids))
+	}
+	return SessionLayout{
+		SessionName:  windowName,
+		Center1:      Pane{Area: PaneAreaCenter, Index: 1, PaneID: ids[0]},
+		TopRight1:    Pane{Area: PaneAreaTopRight, Index: 1, PaneID: ids[1]},
+		BottomRight1: Pane{Area: PaneAreaBottomRight, Index: 1, PaneID: ids[2]},
+	}, nil
+}
+
+// SwitchToWorktree switches the client to the session of a worktree, or to
+// the main session and the worktree's window in window mode.
+func SwitchToWorktree(runner Runner, name string) error {
+	if mode != ModeWindow {
+		return SwitchToSession(runner, name)
+	}
+	if _, err := runner.Run("switch-client", "-t", "="+MainSession()); err != nil {
+		return fmt.Errorf("switching to main session: %w", err)
+	}
+	if _, err := runner.Run("select-window", "-t", worktreeTarget(name)); err != nil {
+		return fmt.Errorf("selecting window %s: %w", name, err)
+	}
+	return nil
+}
+
+// SetWorktreePath records path as the worktree of the window with the given
+// name after its worktree was moved. Sessions keep their start directory, so
+// it does nothing outside window mode.
+func SetWorktreePath(runner Runner, name, path string) error {
+	if mode != ModeWindow {
+		return nil
+	}
+	_, err := runner.Run("set-option", "-w", "-t", worktreeTarget(name), pathOption, path)
+	return err
+}
+
+// WorktreePaths is SessionPaths for the sessions, or windows in window mode,
+// of worktrees. Windows yakumo did not create have no path and are left out.
+func WorktreePaths(runner Runner) (map[string]string, error) {
+	if mode != ModeWindow {
+		return SessionPaths(runner)
+	}
+	windows, err := worktreeWindows(runner, "#{"+pathOption+"}")
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]string)
+	for name, path := range windows {
+		if path != "" {
+			paths[name] = path
+		}
+	}
+	return paths, nil
+}
+
+// WorktreeActivity is SessionActivity for the sessions, or windows in window
+// mode, of worktrees.
+func WorktreeActivity(runner Runner) (map[string]time.Time, error) {
+	if mode != ModeWindow {
+		return SessionActivity(runner)
+	}
+	windows, err := worktreeWindows(runner, "#{window_activity}")
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for name, ts := range windows {
+		lines = append(lines, name+"\t"+ts)
+	}
+	return parseSessionActivity(strings.Join(lines, "\n")), nil
+}
+
+// CurrentWorktreeName is CurrentSessionName, or the name of the current
+// window in window mode.
+func CurrentWorktreeName(runner Runner) (string, error) {
+	if mode != ModeWindow {
+		return CurrentSessionName(runner)
+	}
+	args := []string{"display-message", "-p"}
+	if pane := os.Getenv("TMUX_PANE"); pane != "" {
+		args = append(args, "-t", pane)
+	}
+	out, err := runner.Run(append(args, "#{window_name}")...)
+	if err != nil {
+		return "", fmt.Errorf("getting window name: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// WorktreeBackgroundTarget is the window of the worktree's session that holds
+// its background panes, or in window mode the worktree's own window, which
+// has no background window.
+func WorktreeBackgroundTarget(name string) string {
+	if mode == ModeWindow {
+		return worktreeTarget(name)
+	}
+	return name + ":" + backgroundWindowName
+}
+
+// AttachWorktree runs a tmux client in the terminal attached to the
+// main-window of the worktree's session, or to the main session at the
+// worktree's window in window mode, until it detaches. It is for running
+// outside tmux.
+func AttachWorktree(name string) error {
+	target := "=" + name + ":" + mainWindowName
+	if mode == ModeWindow {
+		target = worktreeTarget(name)
+	}
+	cmd := exec.Command(tmuxBinary(), "attach-session", "-t", target)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("attaching to %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package tmux
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Mode
+		ok   bool
+	}{
+		{"", ModeSession, true},
+		{"session", ModeSession, true},
+		{"window", ModeWindow, true},
+		{"pane", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseMode(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseMode(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHasWorktree_WindowMode(t *testing.T) {
+	SetMode(ModeWindow)
+	t.Cleanup(func() { SetMode(ModeSession) })
+
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[has-session -t =yakumo-main]":                      "",
+			"[list-windows -t =yakumo-main -F #{window_name}\t]": "yakumo\t\nfix-login\t\n",
+		},
+	}
+
+	if ok, err := HasWorktree(runner, "fix-login"); err != nil || !ok {
+		t.Errorf("HasWorktree(fix-login) = %v, %v, want true", ok, err)
+	}
+	if ok, err := HasWorktree(runner, "south-korea"); err != nil || ok {
+		t.Errorf("HasWorktree(south-korea) = %v, %v, want false", ok, err)
+	}
+}
+
+func TestHasWorktree_WindowModeNoMainSession(t *testing.T) {
+	SetMode(ModeWindow)
+	t.Cleanup(func() { SetMode(ModeSession) })
+
+	runner := &FakeRunner{
+		Errors: map[string]error{
+			"[has-session -t =yakumo-main]": fmt.Errorf("no session"),
+		},
+	}
+
+	if ok, err := HasWorktree(runner, "fix-login"); err != nil || ok {
+		t.Errorf("HasWorktree() = %v, %v, want false", ok, err)
+	}
+}
+
+func TestWorktreePaths_WindowMode(t *testing.T) {
+	SetMode(ModeWindow)
+	t.Cleanup(func() { SetMode(ModeSession) })
+
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[has-session -t =yakumo-main]":                                         "",
+			"[list-windows -t =yakumo-main -F #{window_name}\t#{@yakumo-worktree}]": "yakumo\t\nfix-login\t/repos/fix-login\n",
+		},
+	}
+
+	paths, err := WorktreePaths(runner)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != 1 || paths["fix-login"] != "/repos/fix-login" {
+		t.Errorf("WorktreePaths() = %v, want only fix-login", paths)
+	}
+}
+
+func TestCreateWindowLayout(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[has-session -t =yakumo-main]": "",
+			"[new-window -d -P -F #{window_id} -t =yakumo-main: -n fix-login -c /repos/fix-login]": "@3\n",
+			"[set-option -w -t @3 @yakumo-worktree /repos/fix-login]":                              "",
+			"[split-window -h -t @3 -c /repos/fix-login -p 25]":                                    "",
+			"[split-window -v -t @3.1 -c /repos/fix-login -p 70]":                                  "",
+			"[list-panes -t @3 -F #{pane_id}]":                                                     "%4\n%5\n%6\n",
+		},
+	}
+
+	layout, err := CreateWindowLayout(runner, "fix-login", "/repos/fix-login", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if layout.SessionName != "fix-login" {
+		t.Errorf("SessionName = %q, want fix-login", layout.SessionName)
+	}
+	if layout.Center1.PaneID != "%4" || layout.TopRight1.PaneID != "%5" || layout.BottomRight1.PaneID != "%6" {
+		t.Errorf("layout panes = %+v", layout)
+	}
+	if layout.Center2.PaneID != "" {
+		t.Errorf("window layout should have no background panes, got %q", layout.Center2.PaneID)
+	}
+}
+
+func TestSwitchToWorktree_WindowMode(t *testing.T) {
+	SetMode(ModeWindow)
+	t.Cleanup(func() { SetMode(ModeSession) })
+
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[switch-client -t =yakumo-main]":            "",
+			"[select-window -t =yakumo-main:=fix-login]": "",
+		},
+	}
+
+	if err := SwitchToWorktree(runner, "fix-login"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runner.Calls) != 2 {
+		t.Errorf("calls = %v, want switch-client and select-window", runner.Calls)
+	}
+}
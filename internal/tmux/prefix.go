@@ -0,0 +1,21 @@
+package tmux
+
+import "strings"
+
+// sessionPrefix namespaces every session yakumo creates, so users sharing a
+// tmux server don't collide on worktree names.
+var sessionPrefix string
+
+// SetSessionPrefix sets the prefix joined to session names with "/".
+// An empty prefix leaves names unchanged.
+func SetSessionPrefix(prefix string) {
+	sessionPrefix = unsafeNameChars.Replace(strings.TrimSuffix(prefix, "/"))
+}
+
+// SessionName returns name with the configured prefix, e.g. "alice/fix-login".
+func SessionName(name string) string {
+	if sessionPrefix == "" {
+		return name
+	}
+	return sessionPrefix + "/" + name
+}
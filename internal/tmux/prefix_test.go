@@ -0,0 +1,41 @@
+package tmux
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSessionName(t *testing.T) {
+	t.Cleanup(func() { SetSessionPrefix("") })
+
+	if got := SessionName("fix-login"); got != "fix-login" {
+		t.Errorf("SessionName() without prefix = %q, want %q", got, "fix-login")
+	}
+
+	SetSessionPrefix("alice/")
+	if got := SessionName("fix-login"); got != "alice/fix-login" {
+		t.Errorf("SessionName() = %q, want %q", got, "alice/fix-login")
+	}
+	if got := MainSession(); got != "alice/yakumo-main" {
+		t.Errorf("MainSession() = %q, want %q", got, "alice/yakumo-main")
+	}
+}
+
+func TestResolveSessionName_Prefixed(t *testing.T) {
+	SetSessionPrefix("alice")
+	t.Cleanup(func() { SetSessionPrefix("") })
+
+	runner := &FakeRunner{
+		Errors: map[string]error{
+			"[has-session -t =alice/south-korea]": fmt.Errorf("not found"),
+		},
+		Outputs: map[string]string{
+			"[has-session -t =alice/fix-login]": "",
+		},
+	}
+	getBranch := func(string) (string, error) { return "shoji/fix-login", nil }
+
+	if got := ResolveSessionName(runner, "/repos/south-korea", getBranch); got != "alice/fix-login" {
+		t.Errorf("got %q, want %q", got, "alice/fix-login")
+	}
+}
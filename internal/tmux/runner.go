@@ -1,12 +1,17 @@
 package tmux
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/retry"
 )
 
 // Runner abstracts tmux command execution for testability.
@@ -78,16 +83,26 @@ func resolveTmuxFromServer() string {
 
 func (r OSRunner) Run(args ...string) (string, error) {
 	cmd := exec.Command(tmuxBinary(), args...)
+	start := time.Now()
 	out, err := cmd.Output()
+	logging.For("tmux").Debug("ran tmux", "args", strings.Join(args, " "), "took", time.Since(start), "err", err)
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("tmux %v failed: %s", args, string(exitErr.Stderr))
+			return "", newCommandError(args, string(exitErr.Stderr), err)
 		}
-		return "", fmt.Errorf("tmux %v failed: %w", args, err)
+		return "", newCommandError(args, "", err)
 	}
 	return string(out), nil
 }
 
+// Query runs a read-only tmux command (list-*, display-message, capture-pane),
+// retrying transient failures with backoff.
+func Query(runner Runner, args ...string) (string, error) {
+	return retry.Do(retry.Default, IsTransient, func() (string, error) {
+		return runner.Run(args...)
+	})
+}
+
 // FakeRunner is a test double that returns preset output and records calls.
 type FakeRunner struct {
 	Outputs map[string]string
@@ -114,3 +129,48 @@ func (r *FakeRunner) Run(args ...string) (string, error) {
 	}
 	return "", fmt.Errorf("FakeRunner: no output for key %q", key)
 }
+
+// ErrDeclined is returned by ConfirmRunner for a command the user declined.
+var ErrDeclined = errors.New("declined by the user")
+
+// ConfirmRunner asks Confirm before each tmux command that injects a shell
+// command (send-keys into a pane, run-shell) and only runs the ones
+// confirmed; other commands pass straight through to Runner.
+type ConfirmRunner struct {
+	Runner  Runner
+	Confirm func(command string) bool
+}
+
+func (r ConfirmRunner) Run(args ...string) (string, error) {
+	if command, ok := injectedCommand(args); ok && !r.Confirm(command) {
+		return "", ErrDeclined
+	}
+	return r.Runner.Run(args...)
+}
+
+// injectedCommand returns the shell command that tmux args would run:
+// the keys of send-keys without the trailing Enter, or the command of
+// run-shell.
+func injectedCommand(args []string) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	switch args[0] {
+	case "send-keys", "run-shell":
+	default:
+		return "", false
+	}
+	var words []string
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "-t", "-c":
+			i++
+			continue
+		}
+		words = append(words, args[i])
+	}
+	if args[0] == "send-keys" && len(words) > 0 && words[len(words)-1] == "Enter" {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " "), true
+}
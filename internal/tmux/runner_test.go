@@ -1,7 +1,11 @@
 package tmux
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 )
 
@@ -69,3 +73,63 @@ func TestFakeRunner_RecordsCalls(t *testing.T) {
 		t.Errorf("unexpected call args: %v", runner.Calls[0])
 	}
 }
+
+func TestConfirmRunner_AsksBeforeInjectingCommands(t *testing.T) {
+	fake := &FakeRunner{Outputs: map[string]string{
+		"[send-keys -t %2 claude Enter]":              "",
+		"[run-shell -c /wt npm install]":              "",
+		"[list-panes -F #{pane_id}]":                  "%2\n",
+		"[send-keys -t %3 rm -rf node_modules Enter]": "",
+	}}
+	var asked []string
+	runner := ConfirmRunner{Runner: fake, Confirm: func(command string) bool {
+		asked = append(asked, command)
+		return command != "rm -rf node_modules"
+	}}
+
+	if err := SendKeys(runner, "%2", "claude"); err != nil {
+		t.Fatalf("confirmed send-keys failed: %v", err)
+	}
+	if _, err := runner.Run("run-shell", "-c", "/wt", "npm install"); err != nil {
+		t.Fatalf("confirmed run-shell failed: %v", err)
+	}
+	if _, err := runner.Run("list-panes", "-F", "#{pane_id}"); err != nil {
+		t.Fatalf("list-panes failed: %v", err)
+	}
+	if err := SendKeys(runner, "%3", "rm -rf node_modules"); !errors.Is(err, ErrDeclined) {
+		t.Errorf("declined send-keys: err = %v, want ErrDeclined", err)
+	}
+
+	wantAsked := []string{"claude", "npm install", "rm -rf node_modules"}
+	if fmt.Sprint(asked) != fmt.Sprint(wantAsked) {
+		t.Errorf("asked %q, want %q", asked, wantAsked)
+	}
+	if len(fake.Calls) != 3 {
+		t.Errorf("calls = %v, want the declined command left out", fake.Calls)
+	}
+}
+
+// OSRunner never picks a socket itself, so tmux finds the server of the
+// user running yakumo under $TMUX_TMPDIR, as for a plain tmux command.
+func TestOSRunner_UsesTMUXTMPDIR(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux is not installed")
+	}
+	t.Setenv("TMUX", "")
+	tmpdir := t.TempDir()
+	t.Setenv("TMUX_TMPDIR", tmpdir)
+
+	runner := OSRunner{}
+	if _, err := runner.Run("new-session", "-d", "-s", "yakumo-test"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { runner.Run("kill-server") })
+
+	socket := filepath.Join(tmpdir, fmt.Sprintf("tmux-%d", os.Getuid()), "default")
+	if _, err := os.Stat(socket); err != nil {
+		t.Errorf("no server socket under $TMUX_TMPDIR: %v", err)
+	}
+	if out, err := runner.Run("list-sessions", "-F", "#{session_name}"); err != nil || out != "yakumo-test\n" {
+		t.Errorf("list-sessions = %q, %v; want only the session started under $TMUX_TMPDIR", out, err)
+	}
+}
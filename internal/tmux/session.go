@@ -3,6 +3,7 @@ package tmux
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -39,6 +40,32 @@ type SessionLayout struct {
 	BottomRight3 Pane
 }
 
+// PaneNames are the names the panes config gives the panes of a
+// SessionLayout: the main window's, then the background window's.
+var PaneNames = []string{"center", "top_right", "bottom_right", "center_2", "center_3", "bottom_right_2", "bottom_right_3"}
+
+// PaneID returns the ID of the pane with the given name from PaneNames, or
+// "" for an unknown name.
+func (l SessionLayout) PaneID(name string) string {
+	switch name {
+	case "center":
+		return l.Center1.PaneID
+	case "top_right":
+		return l.TopRight1.PaneID
+	case "bottom_right":
+		return l.BottomRight1.PaneID
+	case "center_2":
+		return l.Center2.PaneID
+	case "center_3":
+		return l.Center3.PaneID
+	case "bottom_right_2":
+		return l.BottomRight2.PaneID
+	case "bottom_right_3":
+		return l.BottomRight3.PaneID
+	}
+	return ""
+}
+
 // parsePaneIDs parses the output of `tmux list-panes -F '#{pane_id}'` into a slice of pane ID strings.
 func parsePaneIDs(output string) []string {
 	var ids []string
@@ -74,10 +101,14 @@ func buildSessionLayout(sessionName string, mainPaneIDs []string, bgPaneIDs []st
 	}, nil
 }
 
-// HasSession checks if a tmux session with the given name exists.
+// HasSession checks if a tmux session with the given name exists. It returns
+// an error only when tmux itself is unavailable (see IsUnavailable).
 func HasSession(runner Runner, sessionName string) (bool, error) {
 	_, err := runner.Run("has-session", "-t", "="+sessionName)
 	if err != nil {
+		if IsUnavailable(err) {
+			return false, err
+		}
 		return false, nil
 	}
 	return true, nil
@@ -101,9 +132,10 @@ type BranchGetter func(worktreePath string) (string, error)
 // ResolveSessionName determines the tmux session name for a worktree.
 // It first checks for a session matching filepath.Base(worktreePath),
 // then checks for a session matching the branch slug (e.g. "fix-login" from "shoji/fix-login").
+// Both candidates carry the configured session prefix and template.
 func ResolveSessionName(runner Runner, worktreePath string, getBranch BranchGetter) string {
-	defaultName := filepath.Base(worktreePath)
-	if exists, _ := HasSession(runner, defaultName); exists {
+	defaultName := WorktreeSessionName(worktreePath, filepath.Base(worktreePath))
+	if exists, _ := HasWorktree(runner, defaultName); exists {
 		return defaultName
 	}
 	if getBranch == nil {
@@ -113,16 +145,37 @@ func ResolveSessionName(runner Runner, worktreePath string, getBranch BranchGett
 	if err != nil || branch == "" {
 		return defaultName
 	}
-	slug := branch
-	if parts := strings.SplitN(branch, "/", 2); len(parts) == 2 {
-		slug = parts[1]
+	slug := branchSessionName(worktreePath, branch)
+	if exists, _ := HasWorktree(runner, slug); exists {
+		return slug
 	}
-	if exists, _ := HasSession(runner, slug); exists {
+	return defaultName
+}
+
+// MatchSessionName is ResolveSessionName for callers that already know the
+// running sessions and the worktree's branch, such as the agent status poll,
+// so resolving many worktrees runs no tmux or git commands.
+func MatchSessionName(sessions map[string]bool, worktreePath, branch string) string {
+	defaultName := WorktreeSessionName(worktreePath, filepath.Base(worktreePath))
+	if sessions[defaultName] || branch == "" {
+		return defaultName
+	}
+	if slug := branchSessionName(worktreePath, branch); sessions[slug] {
 		return slug
 	}
 	return defaultName
 }
 
+// branchSessionName is the session name of a branch: its slug without the
+// user prefix, e.g. "fix-login" for "shoji/fix-login".
+func branchSessionName(worktreePath, branch string) string {
+	slug := branch
+	if parts := strings.SplitN(branch, "/", 2); len(parts) == 2 {
+		slug = parts[1]
+	}
+	return WorktreeSessionName(worktreePath, slug)
+}
+
 // SwitchToSession switches the client to an existing session and selects the main-window.
 func SwitchToSession(runner Runner, sessionName string) error {
 	if _, err := runner.Run("switch-client", "-t", "="+sessionName); err != nil {
@@ -137,7 +190,7 @@ func SwitchToSession(runner Runner, sessionName string) error {
 // listPaneIDs fetches pane IDs for a specific window in a session.
 func listPaneIDs(runner Runner, sessionName string, windowName string) ([]string, error) {
 	target := sessionName + ":" + windowName
-	out, err := runner.Run("list-panes", "-t", "="+target, "-F", "#{pane_id}")
+	out, err := Query(runner, "list-panes", "-t", "="+target, "-F", "#{pane_id}")
 	if err != nil {
 		return nil, fmt.Errorf("listing panes for %s: %w", target, err)
 	}
@@ -159,13 +212,16 @@ func createMainWindow(runner Runner, sessionName string, startDir string) error
 		return fmt.Errorf("renaming window to %s: %w", mainWindowName, err)
 	}
 
-	mainTarget := sessionName + ":" + mainWindowName
+	return splitMainLayout(runner, "="+sessionName+":"+mainWindowName, startDir)
+}
 
-	if _, err := runner.Run("split-window", "-h", "-t", "="+mainTarget, "-c", startDir, "-p", "25"); err != nil {
+// splitMainLayout splits the window target into the panes of main-window.
+func splitMainLayout(runner Runner, target string, startDir string) error {
+	if _, err := runner.Run("split-window", "-h", "-t", target, "-c", startDir, "-p", "25"); err != nil {
 		return fmt.Errorf("creating right column split: %w", err)
 	}
 
-	if _, err := runner.Run("split-window", "-v", "-t", "="+mainTarget+".1", "-c", startDir, "-p", "70"); err != nil {
+	if _, err := runner.Run("split-window", "-v", "-t", target+".1", "-c", startDir, "-p", "70"); err != nil {
 		return fmt.Errorf("creating bottom-right split: %w", err)
 	}
 
@@ -189,6 +245,94 @@ func createBackgroundWindow(runner Runner, sessionName string, startDir string)
 	return nil
 }
 
+// windowPanes is how many panes each window of a worktree session has, in
+// the order the windows are created.
+var windowPanes = []struct {
+	name  string
+	panes int
+}{{mainWindowName, 3}, {backgroundWindowName, 4}}
+
+// listWindowPanes returns the pane count of every window of a session, keyed
+// by window name.
+func listWindowPanes(runner Runner, sessionName string) (map[string]int, error) {
+	out, err := Query(runner, "list-windows", "-t", "="+sessionName, "-F", "#{window_name}\t#{window_panes}")
+	if err != nil {
+		return nil, fmt.Errorf("listing windows of %s: %w", sessionName, err)
+	}
+	counts := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		name, panes, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(panes))
+		counts[name] = n
+	}
+	return counts, nil
+}
+
+// MissingWindows returns the windows of yakumo's layout that an existing
+// worktree session lacks or that have fewer panes than the layout gives them,
+// as in a session created outside yakumo. Worktree windows of window mode
+// have no such layout to check, so it returns none in window mode.
+func MissingWindows(runner Runner, sessionName string) ([]string, error) {
+	if mode == ModeWindow {
+		return nil, nil
+	}
+	counts, err := listWindowPanes(runner, sessionName)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, w := range windowPanes {
+		if counts[w.name] < w.panes {
+			missing = append(missing, w.name)
+		}
+	}
+	return missing, nil
+}
+
+// ReconcileSessionLayout adds what MissingWindows reports to an existing
+// session: missing windows are created with the panes of the layout and
+// windows with too few panes are split. Panes already there are left as they
+// are, so nothing running in the session is disturbed.
+func ReconcileSessionLayout(runner Runner, sessionName string, startDir string) error {
+	counts, err := listWindowPanes(runner, sessionName)
+	if err != nil {
+		return err
+	}
+
+	mainTarget := "=" + sessionName + ":" + mainWindowName
+	switch counts[mainWindowName] {
+	case 0:
+		if _, err := runner.Run("new-window", "-d", "-t", "="+sessionName+":", "-n", mainWindowName, "-c", startDir); err != nil {
+			return fmt.Errorf("creating %s: %w", mainWindowName, err)
+		}
+		if err := splitMainLayout(runner, mainTarget, startDir); err != nil {
+			return err
+		}
+	case 1:
+		if err := splitMainLayout(runner, mainTarget, startDir); err != nil {
+			return err
+		}
+	case 2:
+		if _, err := runner.Run("split-window", "-v", "-t", mainTarget+".1", "-c", startDir, "-p", "70"); err != nil {
+			return fmt.Errorf("creating bottom-right split: %w", err)
+		}
+	}
+
+	panes := counts[backgroundWindowName]
+	if panes == 0 {
+		return createBackgroundWindow(runner, sessionName, startDir)
+	}
+	for i := panes; i < 4; i++ {
+		if _, err := runner.Run("split-window", "-v", "-t", "="+sessionName+":"+backgroundWindowName, "-c", startDir); err != nil {
+			return fmt.Errorf("creating background pane %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
 // CreateSessionLayout creates a full session with main-window (3 panes) and
 // background-window (5 panes), returning a SessionLayout with all pane IDs.
 // If startupCommand is non-empty, it is sent to the initial pane before splitting.
@@ -224,33 +368,27 @@ func CreateSessionLayout(runner Runner, sessionName string, startDir string, sta
 	return buildSessionLayout(sessionName, mainPaneIDs, bgPaneIDs)
 }
 
-// SelectWorktreeSession finds or creates a tmux session for the given worktree path.
-// If the session already exists, it switches to it.
-// If not, it creates the full layout and switches to the new session.
+// EnsureWorktreeSession finds or creates a tmux session, or a window of the
+// main session in window mode, for the given worktree path without switching
+// to it. The returned layout only has pane IDs when
+// the session was created by this call.
 // startupCommand is sent to the initial pane before splitting (only for new sessions).
 // getBranch is optional; when provided, it is used to resolve renamed sessions.
-func SelectWorktreeSession(runner Runner, worktreePath string, startupCommand string, getBranch BranchGetter) (SessionLayout, error) {
+func EnsureWorktreeSession(runner Runner, worktreePath string, startupCommand string, getBranch BranchGetter) (SessionLayout, error) {
 	sessionName := ResolveSessionName(runner, worktreePath, getBranch)
 
-	exists, _ := HasSession(runner, sessionName)
-
-	if exists {
-		if err := SwitchToSession(runner, sessionName); err != nil {
-			return SessionLayout{}, err
-		}
+	if exists, _ := HasWorktree(runner, sessionName); exists {
 		return SessionLayout{SessionName: sessionName}, nil
 	}
 
 	// For new sessions, use the default name (filepath.Base)
-	newSessionName := filepath.Base(worktreePath)
-	layout, err := CreateSessionLayout(runner, newSessionName, worktreePath, startupCommand)
+	create := CreateSessionLayout
+	if mode == ModeWindow {
+		create = CreateWindowLayout
+	}
+	layout, err := create(runner, WorktreeSessionName(worktreePath, filepath.Base(worktreePath)), worktreePath, startupCommand)
 	if err != nil {
 		return SessionLayout{}, fmt.Errorf("creating session layout: %w", err)
 	}
-
-	if err := SwitchToSession(runner, newSessionName); err != nil {
-		return layout, fmt.Errorf("switching to new session: %w", err)
-	}
-
 	return layout, nil
 }
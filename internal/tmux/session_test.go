@@ -93,6 +93,22 @@ func TestBuildSessionLayout_ValidInput(t *testing.T) {
 	}
 }
 
+func TestSessionLayout_PaneID(t *testing.T) {
+	layout, err := buildSessionLayout("s", []string{"%0", "%1", "%2"}, []string{"%3", "%4", "%5", "%6"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, name := range PaneNames {
+		if got, want := layout.PaneID(name), fmt.Sprintf("%%%d", i); got != want {
+			t.Errorf("PaneID(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if got := layout.PaneID("left"); got != "" {
+		t.Errorf("PaneID(left) = %q, want empty", got)
+	}
+}
+
 func TestBuildSessionLayout_WrongMainCount(t *testing.T) {
 	_, err := buildSessionLayout("s", []string{"%0", "%1"}, []string{"%3", "%4", "%5", "%6", "%7"})
 	if err == nil {
@@ -205,6 +221,27 @@ func TestRenameSession_Error(t *testing.T) {
 
 // --- ResolveSessionName tests ---
 
+func TestMatchSessionName(t *testing.T) {
+	tests := []struct {
+		name     string
+		sessions map[string]bool
+		branch   string
+		want     string
+	}{
+		{name: "default exists", sessions: map[string]bool{"south-korea": true, "fix-login": true}, branch: "shoji/fix-login", want: "south-korea"},
+		{name: "slug exists", sessions: map[string]bool{"fix-login": true}, branch: "shoji/fix-login", want: "fix-login"},
+		{name: "neither exists", sessions: map[string]bool{"other": true}, branch: "shoji/fix-login", want: "south-korea"},
+		{name: "no branch", sessions: nil, branch: "", want: "south-korea"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchSessionName(tt.sessions, "/repos/south-korea", tt.branch); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestResolveSessionName_DefaultExists(t *testing.T) {
 	runner := &FakeRunner{
 		Outputs: map[string]string{
@@ -641,97 +678,76 @@ func TestCreateSessionLayout_EmptyStartupCommand(t *testing.T) {
 	}
 }
 
-// --- SelectWorktreeSession tests ---
-
-func TestSelectWorktreeSession_ExistingSession(t *testing.T) {
+func TestEnsureWorktreeSession_DoesNotSwitch(t *testing.T) {
 	runner := &FakeRunner{
+		Errors: map[string]error{
+			"[has-session -t =feat]": fmt.Errorf("not found"),
+		},
 		Outputs: map[string]string{
-			"[has-session -t =my-worktree]":                    "",
-			"[switch-client -t =my-worktree]":                  "",
-			"[select-window -t =my-worktree:main-window]":      "",
+			"[new-session -d -s feat -c /repos/feat]":                       "",
+			"[rename-window -t =feat:0 main-window]":                        "",
+			"[split-window -h -t =feat:main-window -c /repos/feat -p 25]":   "",
+			"[split-window -v -t =feat:main-window.1 -c /repos/feat -p 70]": "",
+			"[list-panes -t =feat:main-window -F #{pane_id}]":               "%0\n%1\n%2\n",
+			"[new-window -t =feat -n background-window -c /repos/feat]":     "",
+			"[split-window -v -t =feat:background-window -c /repos/feat]":   "",
+			"[list-panes -t =feat:background-window -F #{pane_id}]":         "%3\n%4\n%5\n%6\n",
 		},
 	}
 
-	layout, err := SelectWorktreeSession(runner, "/repos/my-worktree", "", nil)
+	layout, err := EnsureWorktreeSession(runner, "/repos/feat", "", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if layout.SessionName != "my-worktree" {
-		t.Errorf("SessionName = %q, want %q", layout.SessionName, "my-worktree")
+	if layout.Center1.PaneID != "%0" {
+		t.Errorf("Center1.PaneID = %q, want %%0", layout.Center1.PaneID)
 	}
-	// Should NOT call new-session
 	for _, call := range runner.Calls {
-		if call[0] == "new-session" {
-			t.Error("should not create new session when existing one found")
+		if call[0] == "switch-client" {
+			t.Error("should not switch to the session")
 		}
 	}
 }
 
-func TestSelectWorktreeSession_NewSession(t *testing.T) {
+func TestMissingWindows(t *testing.T) {
 	runner := &FakeRunner{
-		Errors: map[string]error{
-			"[has-session -t =feat]": fmt.Errorf("not found"),
-		},
 		Outputs: map[string]string{
-			"[new-session -d -s feat -c /repos/feat]":                              "",
-			"[rename-window -t =feat:0 main-window]":                                "",
-			"[split-window -h -t =feat:main-window -c /repos/feat -p 25]":                 "",
-			"[split-window -v -t =feat:main-window.1 -c /repos/feat -p 70]":               "",
-			"[list-panes -t =feat:main-window -F #{pane_id}]":                       "%0\n%1\n%2\n",
-			"[new-window -t =feat -n background-window -c /repos/feat]":             "",
-			"[split-window -v -t =feat:background-window -c /repos/feat]":           "",
-			"[list-panes -t =feat:background-window -F #{pane_id}]":                 "%3\n%4\n%5\n%6\n",
-			"[switch-client -t =feat]":                                               "",
-			"[select-window -t =feat:main-window]":                                  "",
+			"[list-windows -t =adopted -F #{window_name}\t#{window_panes}]": "zsh\t1\nmain-window\t3\nbackground-window\t2\n",
 		},
 	}
 
-	layout, err := SelectWorktreeSession(runner, "/repos/feat", "", nil)
+	missing, err := MissingWindows(runner, "adopted")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if layout.SessionName != "feat" {
-		t.Errorf("SessionName = %q, want %q", layout.SessionName, "feat")
-	}
-	if layout.Center1.PaneID != "%0" {
-		t.Errorf("Center1.PaneID = %q, want %%0", layout.Center1.PaneID)
+	if len(missing) != 1 || missing[0] != "background-window" {
+		t.Errorf("MissingWindows() = %v, want [background-window]", missing)
 	}
 }
 
-func TestSelectWorktreeSession_CreateError(t *testing.T) {
+func TestReconcileSessionLayout(t *testing.T) {
 	runner := &FakeRunner{
-		Errors: map[string]error{
-			"[has-session -t =bad]":              fmt.Errorf("not found"),
-			"[new-session -d -s bad -c /bad]": fmt.Errorf("create failed"),
+		Outputs: map[string]string{
+			"[list-windows -t =adopted -F #{window_name}\t#{window_panes}]": "zsh\t1\nbackground-window\t2\n",
+			"[new-window -d -t =adopted: -n main-window -c /wt]":             "",
+			"[split-window -h -t =adopted:main-window -c /wt -p 25]":         "",
+			"[split-window -v -t =adopted:main-window.1 -c /wt -p 70]":       "",
+			"[split-window -v -t =adopted:background-window -c /wt]":         "",
 		},
 	}
 
-	_, err := SelectWorktreeSession(runner, "/bad", "", nil)
-	if err == nil {
-		t.Fatal("expected error")
+	if err := ReconcileSessionLayout(runner, "adopted", "/wt"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-}
 
-func TestSelectWorktreeSession_SwitchAfterCreateError(t *testing.T) {
-	runner := &FakeRunner{
-		Errors: map[string]error{
-			"[has-session -t =feat]":  fmt.Errorf("not found"),
-			"[switch-client -t =feat]": fmt.Errorf("switch failed"),
-		},
-		Outputs: map[string]string{
-			"[new-session -d -s feat -c /repos/feat]":                      "",
-			"[rename-window -t =feat:0 main-window]":                        "",
-			"[split-window -h -t =feat:main-window -c /repos/feat -p 25]":         "",
-			"[split-window -v -t =feat:main-window.1 -c /repos/feat -p 70]":       "",
-			"[list-panes -t =feat:main-window -F #{pane_id}]":               "%0\n%1\n%2\n",
-			"[new-window -t =feat -n background-window -c /repos/feat]":     "",
-			"[split-window -v -t =feat:background-window -c /repos/feat]":   "",
-			"[list-panes -t =feat:background-window -F #{pane_id}]":         "%3\n%4\n%5\n%6\n",
-		},
+	var splits int
+	for _, call := range runner.Calls {
+		if call[0] == "split-window" {
+			splits++
+		}
 	}
-
-	_, err := SelectWorktreeSession(runner, "/repos/feat", "", nil)
-	if err == nil {
-		t.Fatal("expected error")
+	// Two for main-window, two for the background panes it lacks.
+	if splits != 4 {
+		t.Errorf("split-window calls = %d, want 4: %v", splits, runner.Calls)
 	}
 }
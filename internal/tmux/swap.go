@@ -1,6 +1,7 @@
 package tmux
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -27,9 +28,16 @@ func CurrentSessionName(runner Runner) (string, error) {
 	return strings.TrimSpace(out), nil
 }
 
+// errNoBackgroundWindow is returned by the swaps in window mode, where a
+// worktree has no background window to swap with.
+var errNoBackgroundWindow = errors.New("tmux_mode window has no background-window to swap panes with")
+
 // SwapCenter swaps center panes between main-window and background-window.
 // Replicates the logic from scripts/swap-center.sh.
 func SwapCenter(runner Runner) error {
+	if mode == ModeWindow {
+		return errNoBackgroundWindow
+	}
 	session, err := CurrentSessionName(runner)
 	if err != nil {
 		return err
@@ -37,13 +45,13 @@ func SwapCenter(runner Runner) error {
 
 	src1 := "=" + session + ":main-window.0"
 	dst1 := "=" + session + ":background-window.0"
-	if _, err := runner.Run("swap-pane", "-d", "-s", src1, "-t", dst1); err != nil {
+	if err := swapPane(runner, session, src1, dst1); err != nil {
 		return fmt.Errorf("swap center step 1: %w", err)
 	}
 
 	src2 := "=" + session + ":background-window.0"
 	dst2 := "=" + session + ":background-window.1"
-	if _, err := runner.Run("swap-pane", "-d", "-s", src2, "-t", dst2); err != nil {
+	if err := swapPane(runner, session, src2, dst2); err != nil {
 		return fmt.Errorf("swap center step 2: %w", err)
 	}
 
@@ -53,6 +61,9 @@ func SwapCenter(runner Runner) error {
 // SwapRightBelow swaps right-below panes between main-window and background-window.
 // Replicates the logic from scripts/swap-rb.sh.
 func SwapRightBelow(runner Runner) error {
+	if mode == ModeWindow {
+		return errNoBackgroundWindow
+	}
 	session, err := CurrentSessionName(runner)
 	if err != nil {
 		return err
@@ -60,15 +71,45 @@ func SwapRightBelow(runner Runner) error {
 
 	src1 := "=" + session + ":main-window.2"
 	dst1 := "=" + session + ":background-window.2"
-	if _, err := runner.Run("swap-pane", "-d", "-s", src1, "-t", dst1); err != nil {
+	if err := swapPane(runner, session, src1, dst1); err != nil {
 		return fmt.Errorf("swap right-below step 1: %w", err)
 	}
 
 	src2 := "=" + session + ":background-window.2"
 	dst2 := "=" + session + ":background-window.3"
-	if _, err := runner.Run("swap-pane", "-d", "-s", src2, "-t", dst2); err != nil {
+	if err := swapPane(runner, session, src2, dst2); err != nil {
 		return fmt.Errorf("swap right-below step 2: %w", err)
 	}
 
 	return nil
 }
+
+// swapPane swaps the panes src and dst of session. When the swap fails
+// because panes of the layout were closed, the missing panes are recreated
+// and the swap is retried once; otherwise the error tells which of the two
+// targets is absent.
+func swapPane(runner Runner, session, src, dst string) error {
+	_, err := runner.Run("swap-pane", "-d", "-s", src, "-t", dst)
+	if err == nil {
+		return nil
+	}
+
+	if missing, lerr := MissingWindows(runner, session); lerr == nil && len(missing) > 0 {
+		out, derr := runner.Run("display-message", "-p", "-t", "="+session, "#{session_path}")
+		if derr != nil {
+			return err
+		}
+		if rerr := ReconcileSessionLayout(runner, session, strings.TrimSpace(out)); rerr != nil {
+			return fmt.Errorf("recreating %s: %w", strings.Join(missing, ", "), rerr)
+		}
+		_, err = runner.Run("swap-pane", "-d", "-s", src, "-t", dst)
+		return err
+	}
+
+	for _, target := range []string{src, dst} {
+		if _, perr := runner.Run("display-message", "-p", "-t", target, "#{pane_id}"); perr != nil {
+			return fmt.Errorf("pane %s not found: %w", target, err)
+		}
+	}
+	return err
+}
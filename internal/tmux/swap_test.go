@@ -2,6 +2,7 @@ package tmux
 
 import (
 	"errors"
+	"strings"
 	"testing"
 )
 
@@ -78,7 +79,7 @@ func TestCurrentSessionName(t *testing.T) {
 		runner := &FakeRunner{
 			Errors: map[string]error{
 				"[display-message -p -t %9 #{session_name}]": errors.New("server exited unexpectedly"),
-				"[display-message -p #{session_name}]":        errors.New("no current client"),
+				"[display-message -p #{session_name}]":       errors.New("no current client"),
 			},
 		}
 		_, err := CurrentSessionName(runner)
@@ -95,9 +96,9 @@ func TestSwapCenter(t *testing.T) {
 		t.Setenv("TMUX_PANE", "")
 		runner := &FakeRunner{
 			Outputs: map[string]string{
-				"[display-message -p #{session_name}]":                                              "dev",
-				"[swap-pane -d -s =dev:main-window.0 -t =dev:background-window.0]":                   "",
-				"[swap-pane -d -s =dev:background-window.0 -t =dev:background-window.1]":             "",
+				"[display-message -p #{session_name}]":                                   "dev",
+				"[swap-pane -d -s =dev:main-window.0 -t =dev:background-window.0]":       "",
+				"[swap-pane -d -s =dev:background-window.0 -t =dev:background-window.1]": "",
 			},
 		}
 
@@ -145,7 +146,7 @@ func TestSwapCenter(t *testing.T) {
 		t.Setenv("TMUX_PANE", "")
 		runner := &FakeRunner{
 			Outputs: map[string]string{
-				"[display-message -p #{session_name}]":                            "dev",
+				"[display-message -p #{session_name}]":                             "dev",
 				"[swap-pane -d -s =dev:main-window.0 -t =dev:background-window.0]": "",
 			},
 			Errors: map[string]error{
@@ -167,9 +168,9 @@ func TestSwapRightBelow(t *testing.T) {
 		t.Setenv("TMUX_PANE", "")
 		runner := &FakeRunner{
 			Outputs: map[string]string{
-				"[display-message -p #{session_name}]":                                  "dev",
-				"[swap-pane -d -s =dev:main-window.2 -t =dev:background-window.2]":        "",
-				"[swap-pane -d -s =dev:background-window.2 -t =dev:background-window.3]":  "",
+				"[display-message -p #{session_name}]":                                   "dev",
+				"[swap-pane -d -s =dev:main-window.2 -t =dev:background-window.2]":       "",
+				"[swap-pane -d -s =dev:background-window.2 -t =dev:background-window.3]": "",
 			},
 		}
 
@@ -217,7 +218,7 @@ func TestSwapRightBelow(t *testing.T) {
 		t.Setenv("TMUX_PANE", "")
 		runner := &FakeRunner{
 			Outputs: map[string]string{
-				"[display-message -p #{session_name}]":                            "dev",
+				"[display-message -p #{session_name}]":                             "dev",
 				"[swap-pane -d -s =dev:main-window.2 -t =dev:background-window.2]": "",
 			},
 			Errors: map[string]error{
@@ -231,3 +232,70 @@ func TestSwapRightBelow(t *testing.T) {
 		}
 	})
 }
+
+// failOnceRunner fails the first call with key, as a swap onto a pane that
+// was closed does before the layout is recreated.
+type failOnceRunner struct {
+	*FakeRunner
+	key    string
+	failed bool
+}
+
+func (r *failOnceRunner) Run(args ...string) (string, error) {
+	if !r.failed && r.FakeRunner.key(args...) == r.key {
+		r.failed = true
+		r.Calls = append(r.Calls, args)
+		return "", errors.New("can't find pane")
+	}
+	return r.FakeRunner.Run(args...)
+}
+
+func TestSwapCenter_RecreatesClosedPanes(t *testing.T) {
+	t.Setenv("TMUX_PANE", "")
+	swap := "[swap-pane -d -s =dev:main-window.0 -t =dev:background-window.0]"
+	runner := &failOnceRunner{key: swap, FakeRunner: &FakeRunner{
+		Outputs: map[string]string{
+			"[display-message -p #{session_name}]":                      "dev",
+			"[list-windows -t =dev -F #{window_name}\t#{window_panes}]": "main-window\t3\nbackground-window\t1\n",
+			"[display-message -p -t =dev #{session_path}]":              "/wt\n",
+			"[split-window -v -t =dev:background-window -c /wt]":        "",
+			swap: "",
+			"[swap-pane -d -s =dev:background-window.0 -t =dev:background-window.1]": "",
+		},
+	}}
+
+	if err := SwapCenter(runner); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var splits, swaps int
+	for _, call := range runner.Calls {
+		switch call[0] {
+		case "split-window":
+			splits++
+		case "swap-pane":
+			swaps++
+		}
+	}
+	if splits != 3 || swaps != 3 {
+		t.Errorf("splits = %d, swaps = %d, want 3 each: %v", splits, swaps, runner.Calls)
+	}
+}
+
+func TestSwapRightBelow_ReportsAbsentPane(t *testing.T) {
+	t.Setenv("TMUX_PANE", "")
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[display-message -p #{session_name}]":                      "dev",
+			"[list-windows -t =dev -F #{window_name}\t#{window_panes}]": "main-window\t3\nbackground-window\t4\n",
+			"[display-message -p -t =dev:main-window.2 #{pane_id}]":     "%2\n",
+		},
+		Errors: map[string]error{
+			"[swap-pane -d -s =dev:main-window.2 -t =dev:background-window.2]": errors.New("can't find pane"),
+		},
+	}
+
+	err := SwapRightBelow(runner)
+	if err == nil || !strings.Contains(err.Error(), "pane =dev:background-window.2 not found") {
+		t.Errorf("err = %v, want it to name the absent background pane", err)
+	}
+}
@@ -0,0 +1,69 @@
+package tmux
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// SessionNameData is what a session_name_template is executed with.
+type SessionNameData struct {
+	Repo string // name of the worktree's repository in the config
+	Slug string // the worktree directory name, or the branch slug
+}
+
+// sessionTemplate names worktree sessions when set; nil keeps the bare slug.
+var sessionTemplate *template.Template
+
+// repoOf tells the repository name of a worktree path for the template.
+var repoOf = func(worktreePath string) string {
+	return filepath.Base(filepath.Dir(worktreePath))
+}
+
+// ParseSessionTemplate parses a session_name_template such as
+// "{{.Repo}}-{{.Slug}}". It is executed once so that unknown fields fail here
+// rather than while naming a session.
+func ParseSessionTemplate(s string) (*template.Template, error) {
+	t, err := template.New("session_name_template").Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, SessionNameData{Repo: "repo", Slug: "slug"}); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(b.String()) == "" {
+		return nil, fmt.Errorf("template gives an empty session name")
+	}
+	return t, nil
+}
+
+// SetSessionTemplate names worktree sessions with t, looking up the Repo of a
+// worktree with repo. A nil t restores the bare worktree directory name.
+func SetSessionTemplate(t *template.Template, repo func(worktreePath string) string) {
+	sessionTemplate = t
+	if repo != nil {
+		repoOf = repo
+	}
+}
+
+// WorktreeSessionName returns the session name for the worktree at
+// worktreePath with the given slug: the slug, or the session_name_template
+// applied to it, with the configured prefix. Characters tmux does not allow
+// in session names are replaced with '-'.
+func WorktreeSessionName(worktreePath, slug string) string {
+	if sessionTemplate == nil {
+		return SessionName(unsafeNameChars.Replace(slug))
+	}
+	var b strings.Builder
+	if err := sessionTemplate.Execute(&b, SessionNameData{Repo: repoOf(worktreePath), Slug: slug}); err != nil {
+		return SessionName(unsafeNameChars.Replace(slug))
+	}
+	return SessionName(unsafeNameChars.Replace(strings.TrimSpace(b.String())))
+}
+
+// unsafeNameChars replaces the characters that separate the parts of a tmux
+// target, which would break "=session:=window" targets, and which tmux
+// itself changes in session names.
+var unsafeNameChars = strings.NewReplacer(":", "-", ".", "-")
@@ -0,0 +1,66 @@
+package tmux
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseSessionTemplate(t *testing.T) {
+	if _, err := ParseSessionTemplate("{{.Repo}}-{{.Slug}}"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, s := range []string{"{{.Repo", "{{.Branch}}", "{{if false}}x{{end}}"} {
+		if _, err := ParseSessionTemplate(s); err == nil {
+			t.Errorf("ParseSessionTemplate(%q) should fail", s)
+		}
+	}
+}
+
+func TestWorktreeSessionName(t *testing.T) {
+	tmpl, err := ParseSessionTemplate("{{.Repo}}-{{.Slug}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetSessionTemplate(tmpl, func(string) string { return "api.v2" })
+	SetSessionPrefix("alice")
+	t.Cleanup(func() {
+		SetSessionTemplate(nil, nil)
+		SetSessionPrefix("")
+	})
+
+	if got := WorktreeSessionName("/repos/api/fix-login", "fix-login"); got != "alice/api-v2-fix-login" {
+		t.Errorf("got %q, want %q", got, "alice/api-v2-fix-login")
+	}
+}
+
+func TestResolveSessionName_Template(t *testing.T) {
+	tmpl, err := ParseSessionTemplate("{{.Repo}}-{{.Slug}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetSessionTemplate(tmpl, func(string) string { return "web" })
+	t.Cleanup(func() { SetSessionTemplate(nil, nil) })
+
+	runner := &FakeRunner{
+		Errors: map[string]error{
+			"[has-session -t =web-south-korea]": fmt.Errorf("not found"),
+		},
+		Outputs: map[string]string{
+			"[has-session -t =web-fix-login]": "",
+		},
+	}
+	getBranch := func(string) (string, error) { return "shoji/fix-login", nil }
+
+	if got := ResolveSessionName(runner, "/repos/web/south-korea", getBranch); got != "web-fix-login" {
+		t.Errorf("got %q, want %q", got, "web-fix-login")
+	}
+}
+
+func TestWorktreeSessionName_UnsafeCharacters(t *testing.T) {
+	SetSessionPrefix("john.doe")
+	t.Cleanup(func() { SetSessionPrefix("") })
+
+	if got := WorktreeSessionName("/repos/api/v1.2-fix", "v1.2:fix"); got != "john-doe/v1-2-fix" {
+		t.Errorf("got %q, want %q", got, "john-doe/v1-2-fix")
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // IsInsideTmux checks whether the current process is running inside a tmux session.
@@ -15,7 +18,7 @@ var IsInsideTmux = func() bool {
 // FindWindow looks for a tmux window whose name matches the given name.
 // Returns the window index if found, or empty string if not.
 func FindWindow(runner Runner, windowName string) (string, error) {
-	out, err := runner.Run("list-windows", "-F", "#{window_name}\t#{window_index}")
+	out, err := Query(runner, "list-windows", "-F", "#{window_name}\t#{window_index}")
 	if err != nil {
 		return "", err
 	}
@@ -61,6 +64,18 @@ func SendKeys(runner Runner, target string, command string) error {
 	return nil
 }
 
+// SendPrompt types text into the given pane and submits it with Enter. The
+// text is sent literally, so words like "Enter" or "C-c" are not read as keys.
+func SendPrompt(runner Runner, target string, text string) error {
+	if _, err := runner.Run("send-keys", "-t", target, "-l", text); err != nil {
+		return fmt.Errorf("sending prompt to %s: %w", target, err)
+	}
+	if _, err := runner.Run("send-keys", "-t", target, "Enter"); err != nil {
+		return fmt.Errorf("submitting prompt to %s: %w", target, err)
+	}
+	return nil
+}
+
 // SelectPane focuses the given pane target via tmux select-pane.
 // The target should be a pane ID (e.g., "%0") or a session:window.pane reference.
 func SelectPane(runner Runner, target string) error {
@@ -71,15 +86,127 @@ func SelectPane(runner Runner, target string) error {
 	return nil
 }
 
+// FocusPane selects the window holding the pane with the given ID, then the
+// pane itself, so a pane in a background window is brought forward too.
+func FocusPane(runner Runner, paneID string) error {
+	if _, err := runner.Run("select-window", "-t", paneID); err != nil {
+		return fmt.Errorf("selecting the window of pane %s: %w", paneID, err)
+	}
+	return SelectPane(runner, paneID)
+}
+
+// DisplayPopup runs command in a popup of the given size over the current
+// client. The popup closes when the command exits.
+func DisplayPopup(runner Runner, width, height, command string) error {
+	if _, err := runner.Run("display-popup", "-E", "-w", width, "-h", height, command); err != nil {
+		return fmt.Errorf("opening popup: %w", err)
+	}
+	return nil
+}
+
+// PipePane starts piping the output of the given pane into command, which
+// tmux runs through the shell. An existing pipe on the pane is left running.
+func PipePane(runner Runner, target string, command string) error {
+	_, err := runner.Run("pipe-pane", "-o", "-t", target, command)
+	if err != nil {
+		return fmt.Errorf("piping pane %s: %w", target, err)
+	}
+	return nil
+}
+
+// PaneStatus describes one pane of a worktree's session for the sidebar's
+// pane list.
+type PaneStatus struct {
+	PaneID   string
+	Window   string
+	Command  string
+	Title    string
+	Activity time.Time // last activity of the pane's window
+}
+
+// ListPanes returns the panes of the session, or window in window mode, of a
+// worktree.
+func ListPanes(runner Runner, name string) ([]PaneStatus, error) {
+	out, err := ListPanesOfWorktree(runner, name, "#{pane_id}\t#{window_name}\t#{pane_current_command}\t#{window_activity}\t#{pane_title}")
+	if err != nil {
+		return nil, fmt.Errorf("listing panes of %s: %w", name, err)
+	}
+	return parsePaneStatuses(out), nil
+}
+
+// IdlePanes returns the panes that sit at a shell prompt, those of the
+// background window first, where a long run stays out of the way.
+func IdlePanes(panes []PaneStatus) []string {
+	idle := func(p PaneStatus) bool {
+		return slices.Contains([]string{"zsh", "bash", "fish", "sh"}, strings.ToLower(p.Command))
+	}
+	var background, others []string
+	for _, p := range panes {
+		switch {
+		case !idle(p):
+		case p.Window == backgroundWindowName:
+			background = append(background, p.PaneID)
+		default:
+			others = append(others, p.PaneID)
+		}
+	}
+	return append(background, others...)
+}
+
+// parsePaneStatuses parses the list-panes output of ListPanes. The title goes
+// last since it is the only field that may hold a tab.
+func parsePaneStatuses(output string) []PaneStatus {
+	var panes []PaneStatus
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.SplitN(line, "\t", 5)
+		if len(parts) != 5 {
+			continue
+		}
+		p := PaneStatus{PaneID: parts[0], Window: parts[1], Command: parts[2], Title: parts[4]}
+		if secs, err := strconv.ParseInt(parts[3], 10, 64); err == nil {
+			p.Activity = time.Unix(secs, 0)
+		}
+		panes = append(panes, p)
+	}
+	return panes
+}
+
+// KillPane closes the given pane and whatever runs in it.
+func KillPane(runner Runner, paneID string) error {
+	if _, err := runner.Run("kill-pane", "-t", paneID); err != nil {
+		return fmt.Errorf("killing pane %s: %w", paneID, err)
+	}
+	return nil
+}
+
+// RespawnPane kills whatever runs in the given pane and starts a new shell in
+// it, keeping the pane's place in the layout.
+func RespawnPane(runner Runner, paneID string) error {
+	if _, err := runner.Run("respawn-pane", "-k", "-t", paneID); err != nil {
+		return fmt.Errorf("respawning pane %s: %w", paneID, err)
+	}
+	return nil
+}
+
 // PaneCurrentCommand returns the current foreground command of the given pane.
 func PaneCurrentCommand(runner Runner, target string) (string, error) {
-	out, err := runner.Run("display-message", "-p", "-t", target, "#{pane_current_command}")
+	out, err := Query(runner, "display-message", "-p", "-t", target, "#{pane_current_command}")
 	if err != nil {
 		return "", fmt.Errorf("getting pane command for %s: %w", target, err)
 	}
 	return strings.TrimSpace(out), nil
 }
 
+// DisplayMessage shows text in the status line of the current client. Any
+// '#' in text is escaped so tmux does not read it as a format.
+func DisplayMessage(runner Runner, text string) error {
+	_, err := runner.Run("display-message", strings.ReplaceAll(text, "#", "##"))
+	if err != nil {
+		return fmt.Errorf("displaying message: %w", err)
+	}
+	return nil
+}
+
 
 // parseWindowList parses `tmux list-windows` output and returns the window index
 // for the window matching the given name, or empty string if not found.
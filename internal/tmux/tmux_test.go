@@ -2,7 +2,9 @@ package tmux
 
 import (
 	"fmt"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestParseWindowList(t *testing.T) {
@@ -248,6 +250,23 @@ func TestSendKeys_Error(t *testing.T) {
 	}
 }
 
+func TestDisplayPopup(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[display-popup -E -w 80% -h 60% '/bin/yakumo']": "",
+		},
+	}
+
+	if err := DisplayPopup(runner, "80%", "60%", "'/bin/yakumo'"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	runner = &FakeRunner{}
+	if err := DisplayPopup(runner, "80%", "60%", "yakumo"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
 func TestSelectPane_Success(t *testing.T) {
 	runner := &FakeRunner{
 		Outputs: map[string]string{
@@ -312,6 +331,65 @@ func TestPaneCurrentCommand_Error(t *testing.T) {
 	}
 }
 
+func TestSendPrompt(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[send-keys -t %3 -l fix the Enter key]": "",
+			"[send-keys -t %3 Enter]":                "",
+		},
+	}
+
+	if err := SendPrompt(runner, "%3", "fix the Enter key"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runner.Calls) != 2 {
+		t.Errorf("calls = %v, want the literal text then Enter", runner.Calls)
+	}
+}
+
+func TestSendPrompt_Error(t *testing.T) {
+	runner := &FakeRunner{
+		Errors: map[string]error{
+			"[send-keys -t %3 -l hello]": fmt.Errorf("can't find pane"),
+		},
+	}
+
+	if err := SendPrompt(runner, "%3", "hello"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(runner.Calls) != 1 {
+		t.Errorf("Enter should not be sent after a failure, calls = %v", runner.Calls)
+	}
+}
+
+func TestFocusPane(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[select-window -t %7]": "",
+			"[select-pane -t %7]":   "",
+		},
+	}
+
+	if err := FocusPane(runner, "%7"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runner.Calls) != 2 || runner.Calls[0][0] != "select-window" {
+		t.Errorf("calls = %v, want select-window then select-pane", runner.Calls)
+	}
+}
+
+func TestDisplayMessage_EscapesFormats(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[display-message fix ##12 is waiting]": "",
+		},
+	}
+
+	if err := DisplayMessage(runner, "fix #12 is waiting"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestIsInsideTmux(t *testing.T) {
 	original := IsInsideTmux
 	t.Cleanup(func() { IsInsideTmux = original })
@@ -326,3 +404,85 @@ func TestIsInsideTmux(t *testing.T) {
 		t.Error("expected false")
 	}
 }
+
+func TestPipePane(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[pipe-pane -o -t %3 yakumo devlog-write /tmp/wt.log]": "",
+		},
+	}
+
+	if err := PipePane(runner, "%3", "yakumo devlog-write /tmp/wt.log"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runner.Calls) != 1 {
+		t.Fatalf("expected 1 call, got %d", len(runner.Calls))
+	}
+}
+
+func TestPipePane_Error(t *testing.T) {
+	runner := &FakeRunner{
+		Errors: map[string]error{
+			"[pipe-pane -o -t %3 cat]": fmt.Errorf("can't find pane"),
+		},
+	}
+
+	if err := PipePane(runner, "%3", "cat"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestListPanes(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{
+			"[list-panes -s -t dev -F #{pane_id}\t#{window_name}\t#{pane_current_command}\t#{window_activity}\t#{pane_title}]": "%0\tmain-window\tnode\t1700000000\t✳ claude\n%1\tmain-window\tzsh\t1700000000\thost\n",
+		},
+	}
+
+	panes, err := ListPanes(runner, "dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(panes) != 2 {
+		t.Fatalf("expected 2 panes, got %d", len(panes))
+	}
+	want := PaneStatus{PaneID: "%0", Window: "main-window", Command: "node", Title: "✳ claude", Activity: time.Unix(1700000000, 0)}
+	if panes[0] != want {
+		t.Errorf("panes[0] = %+v, want %+v", panes[0], want)
+	}
+}
+
+func TestIdlePanes(t *testing.T) {
+	panes := []PaneStatus{
+		{PaneID: "%0", Window: "main-window", Command: "node"},
+		{PaneID: "%2", Window: "main-window", Command: "zsh"},
+		{PaneID: "%3", Window: "background-window", Command: "npm"},
+		{PaneID: "%4", Window: "background-window", Command: "bash"},
+	}
+	if got := IdlePanes(panes); !slices.Equal(got, []string{"%4", "%2"}) {
+		t.Errorf("IdlePanes = %v, want the idle background pane %%4 first", got)
+	}
+	if got := IdlePanes(panes[:1]); len(got) != 0 {
+		t.Errorf("IdlePanes = %v where none is idle", got)
+	}
+}
+
+func TestKillPane(t *testing.T) {
+	runner := &FakeRunner{
+		Outputs: map[string]string{"[kill-pane -t %4]": ""},
+	}
+
+	if err := KillPane(runner, "%4"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRespawnPane_Error(t *testing.T) {
+	runner := &FakeRunner{
+		Errors: map[string]error{"[respawn-pane -k -t %4]": fmt.Errorf("can't find pane")},
+	}
+
+	if err := RespawnPane(runner, "%4"); err == nil {
+		t.Fatal("expected error")
+	}
+}
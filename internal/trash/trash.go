@@ -0,0 +1,203 @@
+// Package trash keeps archived worktrees, with their uncommitted changes, in
+// a trash directory so they can be restored or purged later.
+package trash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+)
+
+// Entry describes a worktree in the trash. It is saved as <id>.json next to
+// the worktree directory <id>.
+type Entry struct {
+	ID           string    `json:"id"`
+	RepoPath     string    `json:"repo_path"`
+	OriginalPath string    `json:"original_path"`
+	Branch       string    `json:"branch"`
+	ArchivedAt   time.Time `json:"archived_at"`
+}
+
+// Trash is a trash directory. The zero value is disabled: archiving removes
+// worktrees for good, as before the trash existed.
+type Trash struct {
+	Dir string
+}
+
+// DefaultDir returns $XDG_DATA_HOME/yakumo/trash, falling back to
+// ~/.local/share/yakumo/trash.
+func DefaultDir() (string, error) {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("getting home directory: %w", err)
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(base, "yakumo", "trash"), nil
+}
+
+// New returns the trash at dir, or at DefaultDir when dir is empty.
+func New(dir string) (Trash, error) {
+	if dir != "" {
+		return Trash{Dir: dir}, nil
+	}
+	dir, err := DefaultDir()
+	if err != nil {
+		return Trash{}, err
+	}
+	return Trash{Dir: dir}, nil
+}
+
+// Enabled reports whether archived worktrees are kept.
+func (t Trash) Enabled() bool {
+	return t.Dir != ""
+}
+
+// Path returns where the worktree of e is kept.
+func (t Trash) Path(e Entry) string {
+	return filepath.Join(t.Dir, e.ID)
+}
+
+func (t Trash) metaPath(id string) string {
+	return filepath.Join(t.Dir, id+".json")
+}
+
+// Archive moves the worktree at worktreePath into the trash.
+func (t Trash) Archive(runner git.CommandRunner, repoPath, worktreePath, branch string, now time.Time) (Entry, error) {
+	e := Entry{
+		RepoPath:     repoPath,
+		OriginalPath: worktreePath,
+		Branch:       branch,
+		ArchivedAt:   now,
+	}
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return Entry{}, fmt.Errorf("creating trash directory: %w", err)
+	}
+	id := filepath.Base(worktreePath) + "-" + now.Format("20060102-150405")
+	if err := t.reserve(&e, id); err != nil {
+		return Entry{}, err
+	}
+	if err := git.TrashWorktree(runner, repoPath, worktreePath, t.Path(e)); err != nil {
+		os.Remove(t.metaPath(e.ID))
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// reserve saves the metadata of e under the first free ID of id, id-2,
+// id-3, ..., so that worktrees of the same name archived within a second,
+// even by another process, do not collide.
+func (t Trash) reserve(e *Entry, id string) error {
+	for n := 1; ; n++ {
+		e.ID = id
+		if n > 1 {
+			e.ID = fmt.Sprintf("%s-%d", id, n)
+		}
+		if _, err := os.Lstat(t.Path(*e)); err == nil {
+			continue
+		}
+		data, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return err
+		}
+		f, err := os.OpenFile(t.metaPath(e.ID), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("writing trash metadata: %w", err)
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(t.metaPath(e.ID))
+			return fmt.Errorf("writing trash metadata: %w", err)
+		}
+		return nil
+	}
+}
+
+// List returns the trashed worktrees, most recently archived first. A missing
+// trash directory is empty.
+func (t Trash) List() ([]Entry, error) {
+	files, err := os.ReadDir(t.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading trash directory: %w", err)
+	}
+	var entries []Entry
+	for _, f := range files {
+		id, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok || f.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(t.metaPath(id))
+		if err != nil {
+			return nil, fmt.Errorf("reading trash metadata: %w", err)
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", f.Name(), err)
+		}
+		entries = append(entries, e)
+	}
+	slices.SortFunc(entries, func(a, b Entry) int { return b.ArchivedAt.Compare(a.ArchivedAt) })
+	return entries, nil
+}
+
+// Restore moves e back to where it was archived from.
+func (t Trash) Restore(runner git.CommandRunner, e Entry) error {
+	if _, err := os.Stat(e.OriginalPath); err == nil {
+		return fmt.Errorf("cannot restore: %s already exists", e.OriginalPath)
+	}
+	if err := git.RestoreWorktree(runner, e.RepoPath, t.Path(e), e.OriginalPath, e.Branch); err != nil {
+		return err
+	}
+	return os.Remove(t.metaPath(e.ID))
+}
+
+// Purge deletes e for good. Entries whose directory is already gone are
+// just forgotten.
+func (t Trash) Purge(runner git.CommandRunner, e Entry) error {
+	if err := git.PurgeWorktree(runner, e.RepoPath, t.Path(e)); err != nil {
+		if _, statErr := os.Stat(t.Path(e)); statErr == nil {
+			return err
+		}
+	}
+	return os.Remove(t.metaPath(e.ID))
+}
+
+// PurgeOlderThan purges the entries archived before cutoff, carrying on past
+// failures, and returns the purged ones.
+func (t Trash) PurgeOlderThan(runner git.CommandRunner, cutoff time.Time) ([]Entry, error) {
+	entries, err := t.List()
+	if err != nil {
+		return nil, err
+	}
+	var purged []Entry
+	var errs []error
+	for _, e := range entries {
+		if !e.ArchivedAt.Before(cutoff) {
+			continue
+		}
+		if err := t.Purge(runner, e); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.ID, err))
+			continue
+		}
+		purged = append(purged, e)
+	}
+	return purged, errors.Join(errs...)
+}
@@ -0,0 +1,151 @@
+package trash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+)
+
+func TestNew_DefaultDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/data")
+
+	tr, err := New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.Dir != "/data/yakumo/trash" || !tr.Enabled() {
+		t.Errorf("Dir = %q", tr.Dir)
+	}
+	if tr, _ := New("/custom"); tr.Dir != "/custom" {
+		t.Errorf("configured Dir = %q", tr.Dir)
+	}
+	if (Trash{}).Enabled() {
+		t.Error("the zero Trash should be disabled")
+	}
+}
+
+func TestArchiveListRestore(t *testing.T) {
+	tr := Trash{Dir: t.TempDir()}
+	wt := filepath.Join(t.TempDir(), "repo-feat")
+	older := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			wt + ":[checkout --detach]": "",
+			fmt.Sprintf("/repo:[worktree move %s %s/repo-feat-20250101-090000]", wt, tr.Dir):                      "",
+			fmt.Sprintf("/repo:[worktree lock --reason archived by yakumo %s/repo-feat-20250101-090000]", tr.Dir): "",
+			fmt.Sprintf("/repo:[worktree move %s %s/repo-feat-20250101-100000]", wt, tr.Dir):                      "",
+			fmt.Sprintf("/repo:[worktree lock --reason archived by yakumo %s/repo-feat-20250101-100000]", tr.Dir): "",
+			fmt.Sprintf("/repo:[worktree unlock %s/repo-feat-20250101-090000]", tr.Dir):                           "",
+			fmt.Sprintf("/repo:[worktree move %s/repo-feat-20250101-090000 %s]", tr.Dir, wt):                      "",
+		},
+	}
+
+	if _, err := tr.Archive(runner, "/repo", wt, "feat", older); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Archive(runner, "/repo", wt, "feat-2", newer); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := tr.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Branch != "feat-2" || entries[1].OriginalPath != wt {
+		t.Fatalf("entries = %+v, want newest first", entries)
+	}
+
+	if err := tr.Restore(runner, entries[1]); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := tr.List(); len(entries) != 1 {
+		t.Errorf("restored entry should leave the trash, got %+v", entries)
+	}
+}
+
+func TestArchive_SameNameSameSecond(t *testing.T) {
+	tr := Trash{Dir: t.TempDir()}
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	a := filepath.Join(t.TempDir(), "foo")
+	b := filepath.Join(t.TempDir(), "foo")
+	runner := git.FakeCommandRunner{Outputs: map[string]string{}}
+	for _, m := range []struct{ wt, id string }{{a, "foo-20250101-090000"}, {b, "foo-20250101-090000-2"}} {
+		runner.Outputs[m.wt+":[checkout --detach]"] = ""
+		runner.Outputs[fmt.Sprintf("/repo:[worktree move %s %s/%s]", m.wt, tr.Dir, m.id)] = ""
+		runner.Outputs[fmt.Sprintf("/repo:[worktree lock --reason archived by yakumo %s/%s]", tr.Dir, m.id)] = ""
+	}
+
+	first, err := tr.Archive(runner, "/repo", a, "feat", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := tr.Archive(runner, "/repo", b, "feat", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != "foo-20250101-090000" || second.ID != "foo-20250101-090000-2" {
+		t.Errorf("IDs = %q, %q; want a numeric suffix on the second", first.ID, second.ID)
+	}
+}
+
+func TestArchive_FailureForgetsEntry(t *testing.T) {
+	tr := Trash{Dir: t.TempDir()}
+	wt := filepath.Join(t.TempDir(), "foo")
+
+	if _, err := tr.Archive(git.FakeCommandRunner{}, "/repo", wt, "feat", time.Now()); err == nil {
+		t.Fatal("expected an error from git")
+	}
+	if entries, err := tr.List(); err != nil || len(entries) != 0 {
+		t.Errorf("entries = %+v, %v; want none", entries, err)
+	}
+}
+
+func TestRestore_OriginalPathTaken(t *testing.T) {
+	tr := Trash{Dir: t.TempDir()}
+	taken := t.TempDir()
+
+	err := tr.Restore(git.FakeCommandRunner{}, Entry{ID: "x", RepoPath: "/repo", OriginalPath: taken})
+	if err == nil {
+		t.Error("restoring over an existing directory should fail")
+	}
+}
+
+func TestPurgeOlderThan(t *testing.T) {
+	tr := Trash{Dir: t.TempDir()}
+	cutoff := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	for _, e := range []Entry{
+		{ID: "old", RepoPath: "/repo", ArchivedAt: cutoff.Add(-time.Hour)},
+		{ID: "gone", RepoPath: "/repo", ArchivedAt: cutoff.Add(-2 * time.Hour)},
+		{ID: "new", RepoPath: "/repo", ArchivedAt: cutoff.Add(time.Hour)},
+	} {
+		if err := os.WriteFile(tr.metaPath(e.ID), []byte(fmt.Sprintf(`{"id": %q, "repo_path": %q, "archived_at": %q}`, e.ID, e.RepoPath, e.ArchivedAt.Format(time.RFC3339))), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(tr.Dir, "old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("/repo:[worktree remove --force --force %s/old]", tr.Dir): "",
+		},
+	}
+
+	// "gone" fails in git but its directory no longer exists, so it is
+	// forgotten too.
+	purged, err := tr.PurgeOlderThan(runner, cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(purged) != 2 {
+		t.Errorf("purged = %+v, want old and gone", purged)
+	}
+	if entries, _ := tr.List(); len(entries) != 1 || entries[0].ID != "new" {
+		t.Errorf("remaining = %+v, want only new", entries)
+	}
+}
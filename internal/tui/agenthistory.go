@@ -0,0 +1,238 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// AgentHistoryStore persists when the agents in each worktree changed state.
+type AgentHistoryStore interface {
+	Get() map[string][]model.AgentEvent
+	Add(worktreePath string, events []model.AgentEvent) error
+}
+
+// WithAgentHistory returns a copy of the model that restores the agent
+// history from store and records every state change polled into it.
+func (m Model) WithAgentHistory(store AgentHistoryStore) Model {
+	m.agentHistoryStore = store
+	if store != nil {
+		m.agentHistory = store.Get()
+	}
+	return m
+}
+
+// agentChanges returns, by worktree path, the agents in statuses whose state
+// differs from the last one recorded in history, and an AgentStateNone event
+// for each recorded agent that has gone.
+func agentChanges(history map[string][]model.AgentEvent, statuses map[string][]model.AgentInfo, now time.Time) map[string][]model.AgentEvent {
+	changes := make(map[string][]model.AgentEvent)
+	for path, agents := range statuses {
+		last := lastAgentEvents(history[path])
+		for _, a := range agents {
+			if e, ok := last[a.PaneID]; !ok || e.State != a.State {
+				changes[path] = append(changes[path], model.AgentEvent{PaneID: a.PaneID, Agent: a.Agent, State: a.State, At: now})
+			}
+		}
+	}
+	for path, events := range history {
+		for pane, e := range lastAgentEvents(events) {
+			gone := !slices.ContainsFunc(statuses[path], func(a model.AgentInfo) bool { return a.PaneID == pane })
+			if gone && e.State != model.AgentStateNone {
+				changes[path] = append(changes[path], model.AgentEvent{PaneID: pane, Agent: e.Agent, State: model.AgentStateNone, At: now})
+			}
+		}
+	}
+	for _, events := range changes {
+		slices.SortFunc(events, func(a, b model.AgentEvent) int { return strings.Compare(a.PaneID, b.PaneID) })
+	}
+	return changes
+}
+
+// lastAgentEvents returns the latest event of each pane in events.
+func lastAgentEvents(events []model.AgentEvent) map[string]model.AgentEvent {
+	last := make(map[string]model.AgentEvent)
+	for _, e := range events {
+		last[e.PaneID] = e
+	}
+	return last
+}
+
+// recordAgentEvents adds the state changes of the latest poll to the agent
+// history and saves them.
+func (m Model) recordAgentEvents(now time.Time) Model {
+	if m.agentHistoryStore == nil {
+		return m
+	}
+	changes := agentChanges(m.agentHistory, m.agentStatus, now)
+	if len(changes) == 0 {
+		return m
+	}
+	history := maps.Clone(m.agentHistory)
+	if history == nil {
+		history = make(map[string][]model.AgentEvent)
+	}
+	for path, events := range changes {
+		history[path] = append(slices.Clip(history[path]), events...)
+		if err := m.agentHistoryStore.Add(path, events); err != nil {
+			logging.For("agent").Warn("saving agent history failed", "err", err)
+		}
+	}
+	m.agentHistory = history
+	return m
+}
+
+// agentRun is one stretch of an agent running, ended at now if it still is.
+type agentRun struct {
+	agent   string
+	start   time.Time
+	end     time.Time
+	ongoing bool
+}
+
+// agentRuns pairs each pane's switches to running with what followed them.
+func agentRuns(events []model.AgentEvent, now time.Time) []agentRun {
+	var runs []agentRun
+	open := make(map[string]int) // pane → its run in runs
+	for _, e := range events {
+		i, running := open[e.PaneID]
+		switch {
+		case e.State == model.AgentStateRunning && !running:
+			open[e.PaneID] = len(runs)
+			runs = append(runs, agentRun{agent: e.Agent, start: e.At})
+		case e.State != model.AgentStateRunning && running:
+			runs[i].end = e.At
+			delete(open, e.PaneID)
+		}
+	}
+	for _, i := range open {
+		runs[i].end = now
+		runs[i].ongoing = true
+	}
+	return runs
+}
+
+// activitySummary counts the runs started today and the time spent running
+// since midnight, e.g. "Ran 3 times today, 42m total".
+func activitySummary(runs []agentRun, now time.Time) string {
+	y, mo, d := now.Date()
+	midnight := time.Date(y, mo, d, 0, 0, 0, 0, now.Location())
+	count := 0
+	var total time.Duration
+	for _, r := range runs {
+		if !r.start.Before(midnight) {
+			count++
+		}
+		if r.end.After(midnight) {
+			total += r.end.Sub(maxTime(r.start, midnight))
+		}
+	}
+	times := "times"
+	if count == 1 {
+		times = "time"
+	}
+	return fmt.Sprintf("Ran %d %s today, %s total", count, times, formatRunDuration(total))
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// formatRunDuration shows d to the minute, or in seconds when shorter.
+func formatRunDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// openAgentActivity shows the a view with the agent timeline of the
+// worktree under the cursor.
+func (m Model) openAgentActivity() Model {
+	if m.cursor >= len(m.items) || m.items[m.cursor].Kind != model.ItemKindWorktree {
+		return m
+	}
+	item := m.items[m.cursor]
+	m.showingAgentActivity = true
+	m.agentActivityPath = item.WorktreePath
+	m.agentActivityLabel = item.Label
+	m.agentActivityScroll = 0
+	return m
+}
+
+func (m Model) updateAgentActivityMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, globalKeys.ForceQuit):
+		m.quitting = true
+		return m, tea.Quit
+	case key.Matches(msg, agentActivityKeys.Close):
+		m.showingAgentActivity = false
+	case key.Matches(msg, agentActivityKeys.Down):
+		m.agentActivityScroll = min(m.agentActivityScroll+1, max(len(m.agentActivityLines(time.Now()))-1, 0))
+	case key.Matches(msg, agentActivityKeys.Up):
+		m.agentActivityScroll = max(m.agentActivityScroll-1, 0)
+	}
+	return m, nil
+}
+
+// agentActivityLines is today's summary followed by every recorded run,
+// newest first under a heading for its day. A run still going is marked ▸.
+func (m Model) agentActivityLines(now time.Time) []string {
+	runs := agentRuns(m.agentHistory[m.agentActivityPath], now)
+	if len(runs) == 0 {
+		return []string{helpStyle.PaddingTop(0).Render("  No agent runs recorded.")}
+	}
+	width := max(m.sidebarWidth, 20) - 3
+	lines := strings.Split(indentLines(wrapText(activitySummary(runs, now), width), "  "), "\n")
+	day := ""
+	for _, r := range slices.Backward(runs) {
+		if d := r.start.Format("Mon Jan 2"); d != day {
+			day = d
+			lines = append(lines, "", helpStyle.PaddingTop(0).Render("  "+day))
+		}
+		marker := "  "
+		if r.ongoing {
+			marker = "▸ "
+		}
+		lines = append(lines, fmt.Sprintf("%s%s  %-6s %s", marker, r.start.Format("15:04"), formatRunDuration(r.end.Sub(r.start)), r.agent))
+	}
+	return lines
+}
+
+func renderAgentActivityView(m Model) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Agent activity: " + m.agentActivityLabel))
+	b.WriteString("\n")
+
+	lines := m.agentActivityLines(time.Now())
+	start := min(m.agentActivityScroll, max(len(lines)-1, 0))
+	end := len(lines)
+	if vp := viewportHeight(m.height); vp > 0 && start+vp < end {
+		end = start + vp
+	}
+	clip := lipgloss.NewStyle().MaxWidth(max(m.sidebarWidth, 20))
+	for _, l := range lines[start:end] {
+		b.WriteString(clip.Render(l))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpStyle.Render(keyhelp.ShortHelp(keyhelp.Bindings(agentActivityKeys)...)))
+	return b.String()
+}
@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+type fakeAgentHistory struct {
+	history map[string][]model.AgentEvent
+	added   map[string][]model.AgentEvent
+}
+
+func (s *fakeAgentHistory) Get() map[string][]model.AgentEvent { return s.history }
+
+func (s *fakeAgentHistory) Add(worktreePath string, events []model.AgentEvent) error {
+	if s.added == nil {
+		s.added = make(map[string][]model.AgentEvent)
+	}
+	s.added[worktreePath] = append(s.added[worktreePath], events...)
+	return nil
+}
+
+func TestAgentChanges(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	history := map[string][]model.AgentEvent{
+		"/code/repo1":      {{PaneID: "%0", State: model.AgentStateIdle}, {PaneID: "%0", State: model.AgentStateRunning}},
+		"/code/repo1-feat": {{PaneID: "%5", Agent: "aider", State: model.AgentStateIdle}},
+	}
+	statuses := map[string][]model.AgentInfo{
+		"/code/repo1": {{PaneID: "%0", State: model.AgentStateRunning}, {PaneID: "%1", State: model.AgentStateIdle}},
+	}
+
+	changes := agentChanges(history, statuses, now)
+	if got := changes["/code/repo1"]; len(got) != 1 || got[0].PaneID != "%1" {
+		t.Errorf("repo1 changes = %+v, want only the new pane %%1", got)
+	}
+	if got := changes["/code/repo1-feat"]; len(got) != 1 || got[0].State != model.AgentStateNone || got[0].Agent != "aider" {
+		t.Errorf("repo1-feat changes = %+v, want aider gone", got)
+	}
+
+	history["/code/repo1-feat"] = append(history["/code/repo1-feat"], changes["/code/repo1-feat"]...)
+	if got := agentChanges(history, statuses, now)["/code/repo1-feat"]; len(got) != 0 {
+		t.Errorf("a gone agent should be recorded once, got %+v", got)
+	}
+}
+
+func TestActivitySummary(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return time.Date(2026, 3, 4, h, m, 0, 0, time.UTC) }
+	events := []model.AgentEvent{
+		{PaneID: "%0", State: model.AgentStateRunning, At: at(-1, 50)}, // yesterday 23:50, ran past midnight
+		{PaneID: "%0", State: model.AgentStateIdle, At: at(0, 10)},
+		{PaneID: "%0", State: model.AgentStateRunning, At: at(9, 0)},
+		{PaneID: "%0", State: model.AgentStateWaiting, At: at(9, 20)},
+		{PaneID: "%1", State: model.AgentStateRunning, At: at(11, 48)},
+	}
+
+	runs := agentRuns(events, now)
+	if len(runs) != 3 || !runs[2].ongoing {
+		t.Fatalf("runs = %+v, want 3 with the last still going", runs)
+	}
+	if got, want := activitySummary(runs, now), "Ran 2 times today, 42m total"; got != want {
+		t.Errorf("activitySummary = %q, want %q", got, want)
+	}
+}
+
+func TestAgentStatusMsg_RecordsHistory(t *testing.T) {
+	store := &fakeAgentHistory{}
+	m := testModel().WithAgentHistory(store)
+
+	result, _ := m.Update(AgentStatusMsg{Statuses: map[string][]model.AgentInfo{
+		"/code/repo1": {{PaneID: "%0", Agent: "claude", State: model.AgentStateRunning}},
+	}, manual: true})
+	m = result.(Model)
+	result, _ = m.Update(AgentStatusMsg{Statuses: map[string][]model.AgentInfo{
+		"/code/repo1": {{PaneID: "%0", Agent: "claude", State: model.AgentStateRunning}},
+	}, manual: true})
+	m = result.(Model)
+
+	if got := store.added["/code/repo1"]; len(got) != 1 || got[0].State != model.AgentStateRunning {
+		t.Errorf("saved events = %+v, want one running event", got)
+	}
+	if len(m.agentHistory["/code/repo1"]) != 1 {
+		t.Errorf("agentHistory = %+v", m.agentHistory)
+	}
+}
+
+func TestAgentHistory_RecordedWhileUnfocused(t *testing.T) {
+	store := &fakeAgentHistory{}
+	m := testModel().WithAgentHistory(store)
+	m.tmuxRunner = &tmux.FakeRunner{}
+	m.groups = []model.RepoGroup{{Name: "repo1", RootPath: "/code/repo1"}}
+	m.agentTickRunning = true
+
+	result, _ := m.Update(tea.BlurMsg{})
+	result, cmd := result.(Model).Update(AgentTickMsg(time.Now()))
+	if cmd == nil {
+		t.Fatal("an unfocused sidebar should keep polling agents")
+	}
+	result, _ = result.(Model).Update(AgentStatusMsg{Statuses: map[string][]model.AgentInfo{
+		"/code/repo1": {{PaneID: "%0", Agent: "claude", State: model.AgentStateRunning}},
+	}})
+
+	if got := store.added["/code/repo1"]; len(got) != 1 {
+		t.Errorf("saved events = %+v, want the run seen while unfocused", got)
+	}
+	if len(result.(Model).agentHistory["/code/repo1"]) != 1 {
+		t.Errorf("agentHistory = %+v", result.(Model).agentHistory)
+	}
+}
+
+func TestAgentActivityView(t *testing.T) {
+	m := testModel()
+	m.height = 40
+	m.agentHistory = map[string][]model.AgentEvent{
+		"/code/repo1": {{PaneID: "%0", Agent: "claude", State: model.AgentStateRunning, At: time.Now().Add(-5 * time.Minute)}},
+	}
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	m = result.(Model)
+	if !m.showingAgentActivity {
+		t.Fatal("a should open the agent activity view")
+	}
+	view := m.View()
+	for _, want := range []string{"Agent activity: main", "Ran ", "claude"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should contain %q:\n%s", want, view)
+		}
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if result.(Model).showingAgentActivity {
+		t.Error("esc should close the agent activity view")
+	}
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/agent"
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// WithAgentPollInterval returns a copy of the model that polls tmux for agent
+// status every d, from the agent_poll_interval config.
+func (m Model) WithAgentPollInterval(d time.Duration) Model {
+	m.agentPollInterval = d
+	return m
+}
+
+// WithAgentProfiles returns a copy of the model that detects the agents of
+// profiles, built from the agents config.
+func (m Model) WithAgentProfiles(profiles []agent.Profile) Model {
+	m.agentProfiles = profiles
+	return m
+}
+
+// unfocusedPollFactor is how many times slower agents are polled while the
+// terminal is unfocused: slow enough to stay cheap, yet still notifying and
+// recording history for sessions nobody is watching.
+const unfocusedPollFactor = 10
+
+func (m Model) agentTickCmd() tea.Cmd {
+	d := cmp.Or(m.agentPollInterval, config.DefaultAgentPollInterval)
+	if m.unfocused {
+		d *= unfocusedPollFactor
+	}
+	return tea.Tick(d, func(t time.Time) tea.Msg {
+		return AgentTickMsg(t)
+	})
+}
+
+// focus goes back to polling agents at full speed, with a poll straight away
+// so the statuses are not stale after the slower unfocused polls.
+func (m Model) focus() (Model, tea.Cmd) {
+	m.unfocused = false
+	if m.agentUnavailable || len(m.groups) == 0 || m.tmuxRunner == nil {
+		return m, nil
+	}
+	poll := fetchAgentStatusCmd(m.tmuxRunner, m.agentProfiles, m.groups)
+	if m.agentTickRunning {
+		poll = manualPoll(poll)
+	}
+	m.agentTickRunning = true
+	return m, poll
+}
+
+// refresh reloads the worktrees, with fresh diff stats, and polls agent
+// status now rather than waiting for the next tick.
+func (m Model) refresh() (Model, tea.Cmd) {
+	m.statCache.Clear()
+	cmds := []tea.Cmd{fetchGitDataCmd(m.config, m.runner, m.statCache)}
+	if m.agentUnavailable || len(m.groups) == 0 || m.tmuxRunner == nil {
+		return m, tea.Batch(cmds...)
+	}
+	poll := fetchAgentStatusCmd(m.tmuxRunner, m.agentProfiles, m.groups)
+	if m.agentTickRunning {
+		poll = manualPoll(poll)
+	}
+	m.agentTickRunning = true
+	return m, tea.Batch(append(cmds, poll)...)
+}
+
+// manualPoll marks the AgentStatusMsg of poll as made on request.
+func manualPoll(poll tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		msg := poll()
+		if status, ok := msg.(AgentStatusMsg); ok {
+			status.manual = true
+			return status
+		}
+		return msg
+	}
+}
+
+// agentPane picks the pane to jump to among a worktree's agents: the first
+// one waiting for input, else the first running, else the first found.
+func agentPane(agents []model.AgentInfo) string {
+	for _, state := range []model.AgentState{model.AgentStateWaiting, model.AgentStateRunning} {
+		if i := slices.IndexFunc(agents, func(a model.AgentInfo) bool { return a.State == state }); i >= 0 {
+			return agents[i].PaneID
+		}
+	}
+	if len(agents) > 0 {
+		return agents[0].PaneID
+	}
+	return ""
+}
+
+// jumpToAgent selects the worktree under the cursor like enter, and has its
+// agent's pane focused rather than Center1. Without an agent it is a plain
+// open.
+func (m Model) jumpToAgent() (tea.Model, tea.Cmd) {
+	if m.cursor >= len(m.items) || m.items[m.cursor].Kind != model.ItemKindWorktree {
+		return m, nil
+	}
+	item := m.items[m.cursor]
+	m, cmd := m.selectWorktree(item.WorktreePath, item.RepoRootPath)
+	m.selectedPane = agentPane(m.agentStatus[item.WorktreePath])
+	return m, cmd
+}
@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+func TestAgentTickCmd_UsesConfiguredInterval(t *testing.T) {
+	m := testModel().WithAgentPollInterval(time.Millisecond)
+	done := make(chan tea.Msg, 1)
+	go func() { done <- m.agentTickCmd()() }()
+	select {
+	case msg := <-done:
+		if _, ok := msg.(AgentTickMsg); !ok {
+			t.Errorf("got %T, want AgentTickMsg", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the tick should fire after the configured interval")
+	}
+}
+
+func TestBlur_SlowsAgentPollingUntilFocus(t *testing.T) {
+	m := testModel().WithAgentPollInterval(time.Millisecond)
+	m.tmuxRunner = &tmux.FakeRunner{}
+	m.groups = []model.RepoGroup{{Name: "repo1", RootPath: "/code/repo1"}}
+	m.agentTickRunning = true
+
+	result, _ := m.Update(tea.BlurMsg{})
+	result, cmd := result.(Model).Update(AgentTickMsg(time.Now()))
+	m = result.(Model)
+	if cmd == nil || !m.agentTickRunning {
+		t.Fatal("an unfocused sidebar should keep polling tmux, for notifications and history")
+	}
+	if msg := cmd(); !isAgentStatus(msg) {
+		t.Fatalf("got %T, want AgentStatusMsg", msg)
+	}
+	start := time.Now()
+	m.agentTickCmd()()
+	if elapsed := time.Since(start); elapsed < unfocusedPollFactor*time.Millisecond {
+		t.Errorf("unfocused tick after %v, want it slowed down", elapsed)
+	}
+
+	result, cmd = m.Update(tea.FocusMsg{})
+	m = result.(Model)
+	if cmd == nil || !m.agentTickRunning || m.unfocused {
+		t.Fatal("focus should poll straight away")
+	}
+	if poll, ok := cmd().(AgentStatusMsg); !ok || !poll.manual {
+		t.Error("focus should poll agent status without starting a second tick")
+	}
+}
+
+func isAgentStatus(msg tea.Msg) bool {
+	_, ok := msg.(AgentStatusMsg)
+	return ok
+}
+
+func TestRefreshKey_PollsWithoutDoublingTheTick(t *testing.T) {
+	m := testModel()
+	m.config = model.Config{Repositories: []model.RepositoryDef{{Name: "repo1", Path: "/code/repo1"}}}
+	m.runner = &fakeRunner{}
+	m.tmuxRunner = &tmux.FakeRunner{}
+	m.agentTickRunning = true
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if cmd == nil || result.(Model).loading {
+		t.Fatal("ctrl+r should reload in the background")
+	}
+	var poll AgentStatusMsg
+	for _, c := range cmd().(tea.BatchMsg) {
+		if msg, ok := c().(AgentStatusMsg); ok {
+			poll = msg
+		}
+	}
+	if !poll.manual {
+		t.Fatal("ctrl+r should poll agent status, marked as made on request")
+	}
+
+	if _, cmd := result.(Model).Update(poll); cmd != nil {
+		t.Error("a poll made on request should not schedule another tick")
+	}
+}
+
+func TestJumpToAgent_FocusesWaitingPane(t *testing.T) {
+	m := testModel()
+	m.agentStatus = map[string][]model.AgentInfo{
+		"/code/repo1": {
+			{PaneID: "%1", State: model.AgentStateRunning},
+			{PaneID: "%2", State: model.AgentStateWaiting},
+		},
+	}
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+	updated := result.(Model)
+
+	if updated.Selected() != "/code/repo1" {
+		t.Errorf("Selected() = %q, want %q", updated.Selected(), "/code/repo1")
+	}
+	if updated.SelectedPane() != "%2" {
+		t.Errorf("SelectedPane() = %q, want the waiting pane %%2", updated.SelectedPane())
+	}
+	if cmd == nil {
+		t.Error("expected tea.Quit cmd")
+	}
+}
+
+func TestJumpToAgent_NoAgentOpensWorktree(t *testing.T) {
+	m := testModel()
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+	updated := result.(Model)
+
+	if updated.Selected() != "/code/repo1" || updated.SelectedPane() != "" {
+		t.Errorf("Selected() = %q, SelectedPane() = %q, want a plain open", updated.Selected(), updated.SelectedPane())
+	}
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// agentSummary counts the agents of every worktree session by state, e.g.
+// "2 running · 1 waiting for input · 4 idle", colored like the agent icons.
+// States no agent is in are left out, and it is "" when there are no agents.
+func agentSummary(statuses map[string][]model.AgentInfo) string {
+	counts := make(map[model.AgentState]int)
+	for _, agents := range statuses {
+		for _, a := range agents {
+			counts[a.State]++
+		}
+	}
+	parts := []struct {
+		state model.AgentState
+		text  string
+		color lipgloss.Color
+	}{
+		{model.AgentStateRunning, "running", colorAgentRunning},
+		{model.AgentStateWaiting, "waiting for input", colorAgentWaiting},
+		{model.AgentStateIdle, "idle", colorAgentIdle},
+	}
+	var out []string
+	for _, p := range parts {
+		if n := counts[p.state]; n > 0 {
+			out = append(out, lipgloss.NewStyle().Foreground(p.color).Render(fmt.Sprintf("%d %s", n, p.text)))
+		}
+	}
+	return strings.Join(out, sortLabelStyle.Render(" · "))
+}
+
+// renderAgentSummary returns the agent summary wrapped to the sidebar, or ""
+// when no agent is found.
+func (m Model) renderAgentSummary() string {
+	summary := agentSummary(m.agentStatus)
+	if summary == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().PaddingLeft(1).Width(max(m.sidebarWidth, 20)).Render(summary)
+}
+
+// nextWaitingAgent moves the cursor to the next worktree, after it and
+// wrapping around, with an agent waiting for input.
+func (m Model) nextWaitingAgent() (Model, tea.Cmd) {
+	waiting := func(item model.NavigableItem) bool {
+		if item.Kind != model.ItemKindWorktree {
+			return false
+		}
+		for _, a := range item.AgentStatus {
+			if a.State == model.AgentStateWaiting {
+				return true
+			}
+		}
+		return false
+	}
+	for n := 1; n <= len(m.items); n++ {
+		i := (m.cursor + n) % len(m.items)
+		if waiting(m.items[i]) {
+			m.cursor = i
+			return recomputeScroll(m), nil
+		}
+	}
+	return m.notify(SeverityInfo, "No agent is waiting for input", "")
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestAgentSummary(t *testing.T) {
+	statuses := map[string][]model.AgentInfo{
+		"/code/repo1":      {{PaneID: "%0", State: model.AgentStateRunning}, {PaneID: "%1", State: model.AgentStateIdle}},
+		"/code/repo1-feat": {{PaneID: "%2", State: model.AgentStateRunning}, {PaneID: "%3", State: model.AgentStateWaiting}},
+	}
+
+	if got, want := agentSummary(statuses), "2 running · 1 waiting for input · 1 idle"; got != want {
+		t.Errorf("agentSummary = %q, want %q", got, want)
+	}
+	if got := agentSummary(nil); got != "" {
+		t.Errorf("agentSummary(nil) = %q, want empty", got)
+	}
+}
+
+func TestView_ShowsAgentSummary(t *testing.T) {
+	m := testModel()
+	m = pollAgents(m, model.AgentStateWaiting)
+
+	if view := m.View(); !strings.Contains(view, "1 waiting for input") {
+		t.Errorf("view should show the agent summary:\n%s", view)
+	}
+}
+
+func TestNextWaitingAgent(t *testing.T) {
+	m := testModel()
+	result, _ := m.Update(AgentStatusMsg{Statuses: map[string][]model.AgentInfo{
+		"/code/repo1":      {{PaneID: "%0", State: model.AgentStateWaiting}},
+		"/code/repo1-feat": {{PaneID: "%1", State: model.AgentStateWaiting}},
+	}, manual: true})
+	m = result.(Model)
+	w := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}}
+
+	result, _ = m.Update(w)
+	m = result.(Model)
+	if got := m.items[m.cursor].WorktreePath; got != "/code/repo1-feat" {
+		t.Fatalf("cursor on %q, want the next waiting worktree", got)
+	}
+	result, _ = m.Update(w)
+	m = result.(Model)
+	if got := m.items[m.cursor].WorktreePath; got != "/code/repo1" {
+		t.Errorf("cursor on %q, want it to wrap around", got)
+	}
+}
+
+func TestNextWaitingAgent_None(t *testing.T) {
+	m := testModel()
+	cursor := m.cursor
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	m = result.(Model)
+	if m.cursor != cursor || m.toast.Text != "No agent is waiting for input" {
+		t.Errorf("cursor = %d, toast = %q", m.cursor, m.toast.Text)
+	}
+}
@@ -0,0 +1,204 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/audit"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/trash"
+)
+
+// TrashListMsg carries the worktrees in the trash.
+type TrashListMsg struct {
+	Entries []trash.Entry
+	Err     error
+}
+
+// TrashRestoredMsg is sent when an archived worktree has been moved back.
+type TrashRestoredMsg struct {
+	Entry trash.Entry
+	Err   error
+}
+
+// TrashPurgedMsg is sent when an archived worktree has been deleted for good.
+type TrashPurgedMsg struct {
+	Entry trash.Entry
+	Err   error
+}
+
+func listTrashCmd(bin trash.Trash) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := bin.List()
+		return TrashListMsg{Entries: entries, Err: err}
+	}
+}
+
+func restoreTrashCmd(runner git.CommandRunner, bin trash.Trash, auditLog audit.Log, e trash.Entry) tea.Cmd {
+	return func() tea.Msg {
+		err := bin.Restore(runner, e)
+		auditLog.Record(trashEvent(audit.OpRestore, bin, e), err)
+		return TrashRestoredMsg{Entry: e, Err: err}
+	}
+}
+
+func purgeTrashCmd(runner git.CommandRunner, bin trash.Trash, auditLog audit.Log, e trash.Entry) tea.Cmd {
+	return func() tea.Msg {
+		err := bin.Purge(runner, e)
+		auditLog.Record(trashEvent(audit.OpPurge, bin, e), err)
+		return TrashPurgedMsg{Entry: e, Err: err}
+	}
+}
+
+// trashEvent describes an operation on the trashed worktree e.
+func trashEvent(op audit.Op, bin trash.Trash, e trash.Entry) audit.Event {
+	return audit.Event{Op: op, Repo: e.RepoPath, Path: e.OriginalPath, Branch: e.Branch, Detail: bin.Path(e)}
+}
+
+// startArchived opens the list of archived worktrees.
+func (m Model) startArchived() (Model, tea.Cmd) {
+	m.showingArchived = true
+	m.archivedLoading = true
+	m.archivedBusy = false
+	m.archivedEntries = nil
+	m.archivedCursor = 0
+	m.archivedConfirmPurge = false
+	m.archivedErr = nil
+	return m, listTrashCmd(m.trash)
+}
+
+// dropArchived removes e from the list, keeping the cursor in range.
+func (m Model) dropArchived(e trash.Entry) Model {
+	var remaining []trash.Entry
+	for _, other := range m.archivedEntries {
+		if other.ID != e.ID {
+			remaining = append(remaining, other)
+		}
+	}
+	m.archivedEntries = remaining
+	m.archivedCursor = max(min(m.archivedCursor, len(remaining)-1), 0)
+	return m
+}
+
+func (m Model) updateArchivedMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case TrashListMsg:
+		m.archivedLoading = false
+		m.archivedEntries = msg.Entries
+		m.archivedErr = msg.Err
+		return m, nil
+
+	case TrashRestoredMsg:
+		m.archivedBusy = false
+		m.archivedErr = msg.Err
+		if msg.Err != nil {
+			return m, nil
+		}
+		m = m.dropArchived(msg.Entry)
+		m.loading = true
+		return m, fetchGitDataCmd(m.config, m.runner, m.statCache)
+
+	case TrashPurgedMsg:
+		m.archivedBusy = false
+		m.archivedErr = msg.Err
+		if msg.Err == nil {
+			m = m.dropArchived(msg.Entry)
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if key.Matches(msg, globalKeys.ForceQuit) {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		if m.archivedLoading || m.archivedBusy {
+			return m, nil
+		}
+		if m.archivedConfirmPurge {
+			m.archivedConfirmPurge = false
+			if key.Matches(msg, archivedKeys.Confirm) && m.archivedCursor < len(m.archivedEntries) {
+				m.archivedBusy = true
+				m.archivedErr = nil
+				return m, purgeTrashCmd(m.runner, m.trash, m.audit, m.archivedEntries[m.archivedCursor])
+			}
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, archivedKeys.Close):
+			m.showingArchived = false
+			m.archivedEntries = nil
+			m.archivedErr = nil
+		case key.Matches(msg, archivedKeys.Down):
+			if m.archivedCursor < len(m.archivedEntries)-1 {
+				m.archivedCursor++
+			}
+		case key.Matches(msg, archivedKeys.Up):
+			if m.archivedCursor > 0 {
+				m.archivedCursor--
+			}
+		case key.Matches(msg, archivedKeys.Restore):
+			if m.archivedCursor < len(m.archivedEntries) {
+				m.archivedBusy = true
+				m.archivedErr = nil
+				return m, restoreTrashCmd(m.runner, m.trash, m.audit, m.archivedEntries[m.archivedCursor])
+			}
+		case key.Matches(msg, archivedKeys.Purge):
+			if m.archivedCursor < len(m.archivedEntries) {
+				m.archivedConfirmPurge = true
+			}
+		}
+	}
+	return m, nil
+}
+
+func renderArchivedView(m Model) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Archived Worktrees"))
+	b.WriteString("\n")
+
+	help := keyhelp.ShortHelp(archivedKeys.Up, archivedKeys.Down, archivedKeys.Restore, archivedKeys.Purge, archivedKeys.Close)
+	switch {
+	case m.archivedLoading:
+		b.WriteString("  Reading the trash...\n")
+	case m.archivedBusy:
+		b.WriteString("  Working...\n")
+	case len(m.archivedEntries) == 0:
+		b.WriteString("  The trash is empty\n")
+		help = keyhelp.ShortHelp(archivedKeys.Close)
+	default:
+		clip := lipgloss.NewStyle().MaxWidth(max(m.sidebarWidth, 20))
+		selectedStyle := lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
+		for i, e := range m.archivedEntries {
+			line := e.Branch
+			if line == "" {
+				line = filepath.Base(e.OriginalPath)
+			}
+			if i == m.archivedCursor {
+				line = selectedStyle.Render("> " + line)
+			} else {
+				line = "  " + line
+			}
+			meta := fmt.Sprintf("%s, %s", filepath.Base(e.RepoPath), e.ArchivedAt.Local().Format("2006-01-02 15:04"))
+			b.WriteString(clip.Render(line + " " + sortLabelStyle.Render(meta)))
+			b.WriteString("\n")
+		}
+		if m.archivedConfirmPurge {
+			b.WriteString(helpStyle.PaddingTop(0).Render("  Delete this worktree and its uncommitted changes? (y/N)"))
+			b.WriteString("\n")
+			help = keyhelp.ShortHelp(archivedKeys.Confirm) + "  any other key: cancel"
+		}
+	}
+
+	if m.archivedErr != nil {
+		b.WriteString(renderErrorBlock(m.archivedErr, m.width))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render(help))
+	return b.String()
+}
@@ -0,0 +1,170 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/audit"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/trash"
+)
+
+func archivedModel() Model {
+	m := testModel().WithTrash(trash.Trash{Dir: "/trash"})
+	m.showingArchived = true
+	m.archivedEntries = []trash.Entry{
+		{ID: "a-1", RepoPath: "/code/repo1", OriginalPath: "/wt/a", Branch: "a"},
+		{ID: "b-1", RepoPath: "/code/repo1", OriginalPath: "/wt/b", Branch: "b"},
+	}
+	return m
+}
+
+func TestUpdate_U_OpensArchivedOnlyWithTrash(t *testing.T) {
+	key := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")}
+
+	result, _ := testModel().Update(key)
+	if result.(Model).showingArchived {
+		t.Error("u should do nothing without a trash")
+	}
+
+	result, cmd := testModel().WithTrash(trash.Trash{Dir: t.TempDir()}).Update(key)
+	if !result.(Model).showingArchived || cmd == nil {
+		t.Fatal("u should open the archived list and read the trash")
+	}
+	if msg := cmd().(TrashListMsg); msg.Err != nil || len(msg.Entries) != 0 {
+		t.Errorf("empty trash = %+v", msg)
+	}
+}
+
+func TestUpdate_Archived_PurgeNeedsConfirmation(t *testing.T) {
+	m := archivedModel()
+	x := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}
+
+	result, _ := m.Update(x)
+	m = result.(Model)
+	if !m.archivedConfirmPurge || !strings.Contains(renderArchivedView(m), "(y/N)") {
+		t.Fatal("x should ask before deleting")
+	}
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if cmd != nil || result.(Model).archivedConfirmPurge {
+		t.Error("any key other than y should cancel")
+	}
+
+	result, _ = result.(Model).Update(x)
+	result, cmd = result.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if cmd == nil || !result.(Model).archivedBusy {
+		t.Fatal("y should purge the selected worktree")
+	}
+
+	result, _ = result.(Model).Update(TrashPurgedMsg{Entry: m.archivedEntries[0]})
+	m = result.(Model)
+	if len(m.archivedEntries) != 1 || m.archivedEntries[0].ID != "b-1" {
+		t.Errorf("entries = %+v, want only b-1", m.archivedEntries)
+	}
+}
+
+func TestUpdate_TrashRestoredMsg(t *testing.T) {
+	m := archivedModel()
+	m.archivedCursor = 1
+
+	result, cmd := m.Update(TrashRestoredMsg{Entry: m.archivedEntries[1], Err: errors.New("already exists")})
+	updated := result.(Model)
+	if cmd != nil || len(updated.archivedEntries) != 2 || !strings.Contains(renderArchivedView(updated), "already exists") {
+		t.Error("a failed restore should keep the entry and show the error")
+	}
+
+	result, cmd = m.Update(TrashRestoredMsg{Entry: m.archivedEntries[1]})
+	updated = result.(Model)
+	if cmd == nil || len(updated.archivedEntries) != 1 || updated.archivedCursor != 0 {
+		t.Errorf("a restore should drop the entry and reload the sidebar, got %+v", updated.archivedEntries)
+	}
+}
+
+func TestArchiveWorktreeCmd_Trash(t *testing.T) {
+	bin := trash.Trash{Dir: t.TempDir()}
+	wt := filepath.Join(t.TempDir(), "old-worktree")
+	if err := os.Mkdir(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			wt + ":[symbolic-ref --short HEAD]": "feat\n",
+			wt + ":[checkout --detach]":         "",
+		},
+	}
+	// The trashed path embeds the archive time; allow for a second ticking
+	// over while the command runs.
+	now := time.Now()
+	for _, at := range []time.Time{now, now.Add(time.Second)} {
+		dest := filepath.Join(bin.Dir, "old-worktree-"+at.Format("20060102-150405"))
+		runner.Outputs[fmt.Sprintf("/repo:[worktree move %s %s]", wt, dest)] = ""
+		runner.Outputs[fmt.Sprintf("/repo:[worktree lock --reason %s %s]", git.TrashLockReason, dest)] = ""
+	}
+
+	if msg := archiveWorktreeCmd(runner, nil, bin, audit.Log{}, model.Config{}, "/repo", wt, false)(); msg != (WorktreeArchivedMsg{RepoPath: "/repo"}) {
+		t.Fatalf("msg = %#v, want WorktreeArchivedMsg", msg)
+	}
+	entries, err := bin.List()
+	if err != nil || len(entries) != 1 || entries[0].Branch != "feat" || entries[0].OriginalPath != wt {
+		t.Errorf("trash = %+v, %v", entries, err)
+	}
+}
+
+func TestArchiveWorktree_RecordsAudit(t *testing.T) {
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/wt/a:[symbolic-ref --short HEAD]": "feat\n",
+			"/repo:[worktree remove /wt/a]":     "",
+		},
+		Errors: map[string]error{"/repo:[worktree remove /wt/b]": errors.New("contains modified files")},
+	}
+	auditLog := audit.Log{Path: filepath.Join(t.TempDir(), "audit.jsonl")}.From(audit.SourceCleanup)
+
+	if err := ArchiveWorktree(runner, nil, trash.Trash{}, auditLog, model.Config{}, "/repo", "/wt/a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ArchiveWorktree(runner, nil, trash.Trash{}, auditLog, model.Config{}, "/repo", "/wt/b"); err == nil {
+		t.Fatal("expected the failing remove to surface")
+	}
+
+	events, err := auditLog.Read(audit.Filter{Op: audit.OpArchive, Source: audit.SourceCleanup})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Branch != "feat" || events[0].Err != "" || events[1].Err != "contains modified files" {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestArchiveWorktree_PreArchiveHook(t *testing.T) {
+	wt := t.TempDir()
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			wt + ":[symbolic-ref --short HEAD]":  "feat\n",
+			"/repo:[worktree remove " + wt + "]": "",
+		},
+	}
+	cfg := model.Config{Repositories: []model.RepositoryDef{{Path: "/repo", Hooks: model.Hooks{PreArchive: `echo "$YAKUMO_BRANCH" > ../teardown`}}}}
+	if err := ArchiveWorktree(runner, nil, trash.Trash{}, audit.Log{}, cfg, "/repo", wt); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(wt, "..", "teardown")); err != nil || string(data) != "feat\n" {
+		t.Errorf("pre_archive hook wrote %q, %v", data, err)
+	}
+
+	// No git calls are faked for this worktree: a failed hook must stop
+	// before the worktree is touched.
+	cfg.Hooks.PreArchive = "exit 1"
+	cfg.Repositories[0].Hooks = model.Hooks{}
+	if err := ArchiveWorktree(git.FakeCommandRunner{}, nil, trash.Trash{}, audit.Log{}, cfg, "/repo", t.TempDir()); err == nil || !strings.Contains(err.Error(), "pre_archive") {
+		t.Errorf("ArchiveWorktree = %v, want the pre_archive failure", err)
+	}
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/forge"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// BaseChecksMsg delivers the CI state of a repository's base branch.
+type BaseChecksMsg struct {
+	RepoPath string
+	State    model.CheckState
+	Err      error
+}
+
+// fetchBaseChecksCmd fetches the latest CI run of baseRef's branch on the
+// repository's forge, so a red base branch can be told apart from failures
+// caused by a worktree's own changes.
+func fetchBaseChecksCmd(opts forge.Options, repoPath, configuredForge, baseRef string) tea.Cmd {
+	return func() tea.Msg {
+		provider, err := forge.New(forge.KindFor(configuredForge, opts.GitRunner, repoPath), opts)
+		if err != nil {
+			return BaseChecksMsg{RepoPath: repoPath, Err: err}
+		}
+		checks, err := provider.FetchBranchChecks(repoPath, forge.BranchName(baseRef))
+		if err != nil {
+			return BaseChecksMsg{RepoPath: repoPath, Err: err}
+		}
+		return BaseChecksMsg{
+			RepoPath: repoPath,
+			State:    checkState(github.PRView{StatusCheckRollup: checks}.CheckRollup()),
+		}
+	}
+}
+
+// applyBaseChecks stores a fetch result and updates the group headers.
+// Failed fetches keep the previous state.
+func (m Model) applyBaseChecks(msg BaseChecksMsg) Model {
+	if msg.Err != nil {
+		logging.For("base-checks").Warn("fetching CI failed (non-fatal)", "repo", msg.RepoPath, "err", msg.Err)
+		return m
+	}
+	if m.baseChecks == nil {
+		m.baseChecks = make(map[string]model.CheckState)
+	}
+	m.baseChecks[msg.RepoPath] = msg.State
+	for i := range m.items {
+		if m.items[i].Kind == model.ItemKindGroupHeader {
+			m.items[i].BaseRed = m.baseRedFor(m.items[i].RepoRootPath)
+		}
+	}
+	return m
+}
+
+// baseRedFor returns the base branch name when its CI is failing in the
+// repository at repoPath, or "".
+func (m Model) baseRedFor(repoPath string) string {
+	if m.baseChecks[repoPath] != model.ChecksFailing {
+		return ""
+	}
+	return forge.BranchName(config.BaseRef(m.config, repoPath))
+}
+
+// BaseRedBadge renders the group header warning for a failing base branch.
+// Returns "" when base is "".
+func BaseRedBadge(base string) string {
+	if base == "" {
+		return ""
+	}
+	return " " + lipgloss.NewStyle().Foreground(colorRed).Render("✗ "+base+" red")
+}
@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/forge"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestFetchBaseChecksCmd(t *testing.T) {
+	gh := &github.FakeRunner{
+		Outputs: map[string]string{
+			"/code/repo1:[run list --branch main --limit 50 --json headSha,workflowName,status,conclusion,startedAt,updatedAt]": `[{"headSha": "a", "workflowName": "CI", "status": "completed", "conclusion": "failure"}]`,
+		},
+	}
+	opts := forge.Options{GitHubRunner: gh, GitRunner: git.FakeCommandRunner{}}
+
+	msg := fetchBaseChecksCmd(opts, "/code/repo1", forge.KindGitHub, "origin/main")().(BaseChecksMsg)
+	if msg.Err != nil || msg.State != model.ChecksFailing {
+		t.Errorf("got %+v, want failing", msg)
+	}
+}
+
+func TestUpdate_BaseChecksMsg_MarksGroupHeader(t *testing.T) {
+	m := testModel()
+
+	result, _ := m.Update(BaseChecksMsg{RepoPath: "/code/repo1", State: model.ChecksFailing})
+	updated := result.(Model)
+
+	for _, item := range updated.items {
+		if item.Kind != model.ItemKindGroupHeader {
+			continue
+		}
+		want := ""
+		if item.RepoRootPath == "/code/repo1" {
+			want = "main"
+		}
+		if item.BaseRed != want {
+			t.Errorf("%s: BaseRed = %q, want %q", item.Label, item.BaseRed, want)
+		}
+	}
+	if !strings.Contains(updated.View(), "✗ main red") {
+		t.Error("the sidebar should flag the red base branch")
+	}
+
+	result, _ = updated.Update(BaseChecksMsg{RepoPath: "/code/repo1", Err: fmt.Errorf("rate limited")})
+	if result.(Model).baseChecks["/code/repo1"] != model.ChecksFailing {
+		t.Error("a failed fetch should keep the previous state")
+	}
+
+	result, _ = result.(Model).Update(BaseChecksMsg{RepoPath: "/code/repo1", State: model.ChecksPassing})
+	if strings.Contains(result.(Model).View(), "main red") {
+		t.Error("a green base branch should clear the warning")
+	}
+}
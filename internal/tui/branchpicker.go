@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/sidebar"
+)
+
+// branchPickerMax caps the branches listed under the add-worktree input.
+const branchPickerMax = 8
+
+// BranchListMsg carries the branches of the repository a worktree is being
+// added to.
+type BranchListMsg struct {
+	RepoPath string
+	Branches []git.Branch
+	Err      error
+}
+
+func listBranchesCmd(runner git.CommandRunner, repoPath string) tea.Cmd {
+	return func() tea.Msg {
+		branches, err := git.ListBranches(runner, repoPath)
+		return BranchListMsg{RepoPath: repoPath, Branches: branches, Err: err}
+	}
+}
+
+// addWorktreeFromExistingBranchCmd checks out a picked branch into a fresh
+// worktree. Remote branches get a local branch of the same name tracking them.
+func addWorktreeFromExistingBranchCmd(runner git.CommandRunner, repoPath, basePath, repoName string, branch git.Branch) tea.Cmd {
+	return func() tea.Msg {
+		remoteRef := ""
+		if branch.Remote != "" {
+			remoteRef = branch.String()
+		}
+		msg := checkoutWorktree(runner, repoPath, basePath, repoName, branch.Name, remoteRef)
+		if added, ok := msg.(WorktreeAddedMsg); ok {
+			added.Existing = true
+			return added
+		}
+		return msg
+	}
+}
+
+// applyBranchList keeps the branches that can still be checked out: git
+// refuses a branch that already has a worktree.
+func (m Model) applyBranchList(msg BranchListMsg) Model {
+	if msg.RepoPath != m.addingWorktreeRepoPath {
+		return m
+	}
+	if msg.Err != nil {
+		logging.For("worktree").Warn("listing branches failed (non-fatal)", "err", msg.Err)
+		return m
+	}
+
+	checkedOut := make(map[string]bool)
+	for _, group := range m.groups {
+		if group.RootPath != msg.RepoPath {
+			continue
+		}
+		for _, wt := range group.Worktrees {
+			checkedOut[wt.Branch] = true
+		}
+	}
+	m.branches = nil
+	for _, b := range msg.Branches {
+		if !checkedOut[b.Name] {
+			m.branches = append(m.branches, b)
+		}
+	}
+	m.branchCursor = min(m.branchCursor, len(m.branchMatches())-1)
+	return m
+}
+
+// branchMatches returns the picker rows for the current input: branches
+// fuzzily matching it, or none while a URL is being entered.
+func (m Model) branchMatches() []git.Branch {
+	input := strings.TrimSpace(m.textInput.Value())
+	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+		return nil
+	}
+	var matches []git.Branch
+	for _, b := range m.branches {
+		if _, ok := sidebar.FuzzyMatch(input, b.String()); ok {
+			matches = append(matches, b)
+			if len(matches) == branchPickerMax {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// moveBranchCursor moves the picker selection by delta. -1 means nothing is
+// picked and enter uses the typed input as before.
+func (m Model) moveBranchCursor(delta int) Model {
+	n := len(m.branchMatches())
+	m.branchCursor = max(min(m.branchCursor+delta, n-1), -1)
+	return m
+}
+
+// renderBranchPicker returns one line per matching branch, marking the
+// picked one.
+func renderBranchPicker(m Model) []string {
+	selectedStyle := lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
+	var lines []string
+	for i, b := range m.branchMatches() {
+		if i == m.branchCursor {
+			lines = append(lines, selectedStyle.Render("> "+b.String()))
+		} else {
+			lines = append(lines, sortLabelStyle.Render("  "+b.String()))
+		}
+	}
+	return lines
+}
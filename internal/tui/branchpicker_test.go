@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func pickerModel() Model {
+	m := testModel()
+	m.addingWorktree = true
+	m.addingWorktreeRepoPath = "/code/repo1"
+	m.branchCursor = -1
+	m = m.applyBranchList(BranchListMsg{
+		RepoPath: "/code/repo1",
+		Branches: []git.Branch{
+			{Name: "feature-x"}, // already has a worktree
+			{Name: "fix-login"},
+			{Name: "feature/search", Remote: "origin"},
+		},
+	})
+	return m
+}
+
+func TestApplyBranchList_SkipsCheckedOutBranches(t *testing.T) {
+	m := pickerModel()
+
+	got := m.branchMatches()
+	if len(got) != 2 || got[0].Name != "fix-login" || got[1].String() != "origin/feature/search" {
+		t.Errorf("branchMatches = %+v, want fix-login and origin/feature/search", got)
+	}
+}
+
+func TestApplyBranchList_IgnoresOtherRepo(t *testing.T) {
+	m := testModel()
+	m.addingWorktreeRepoPath = "/code/repo1"
+
+	m = m.applyBranchList(BranchListMsg{RepoPath: "/code/other", Branches: []git.Branch{{Name: "x"}}})
+	if len(m.branches) != 0 {
+		t.Errorf("branches of another repository should be ignored, got %+v", m.branches)
+	}
+}
+
+func TestBranchMatches_NarrowsAndHidesForURLs(t *testing.T) {
+	m := pickerModel()
+
+	m.textInput.SetValue("srch")
+	if got := m.branchMatches(); len(got) != 1 || got[0].Name != "feature/search" {
+		t.Errorf("fuzzy match = %+v, want only feature/search", got)
+	}
+
+	m.textInput.SetValue("https://github.com/o/r/pull/1")
+	if got := m.branchMatches(); len(got) != 0 {
+		t.Errorf("URLs should hide the picker, got %+v", got)
+	}
+}
+
+func TestUpdate_AddWorktreeMode_PickBranch(t *testing.T) {
+	m := pickerModel()
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	result, _ = result.(Model).Update(tea.KeyMsg{Type: tea.KeyDown})
+	result, _ = result.(Model).Update(tea.KeyMsg{Type: tea.KeyDown}) // clamped at the last row
+	updated := result.(Model)
+	if updated.branchCursor != 1 {
+		t.Fatalf("branchCursor = %d, want 1", updated.branchCursor)
+	}
+
+	result, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updated = result.(Model)
+	if updated.addingWorktree || !updated.loading || cmd == nil {
+		t.Error("enter on a picked branch should start creating the worktree")
+	}
+}
+
+func TestUpdate_AddWorktreeMode_UpReturnsToTypedInput(t *testing.T) {
+	m := pickerModel()
+	m.branchCursor = 0
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	result, _ = result.(Model).Update(tea.KeyMsg{Type: tea.KeyUp})
+	if got := result.(Model).branchCursor; got != -1 {
+		t.Errorf("branchCursor = %d, want -1", got)
+	}
+}
+
+func TestAddWorktreeFromExistingBranchCmd_Remote(t *testing.T) {
+	base := t.TempDir()
+	newPath := filepath.Join(base, "repo1", "search")
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("/code/repo1:[worktree add --track -b feature/search %s origin/feature/search]", newPath): "",
+		},
+	}
+
+	msg := addWorktreeFromExistingBranchCmd(runner, "/code/repo1", base, "repo1", git.Branch{Name: "feature/search", Remote: "origin"})()
+
+	added, ok := msg.(WorktreeAddedMsg)
+	if !ok {
+		t.Fatalf("expected WorktreeAddedMsg, got %#v", msg)
+	}
+	if added.WorktreePath != newPath || added.Branch != "feature/search" || !added.Existing {
+		t.Errorf("got %+v", added)
+	}
+}
+
+func TestAddWorktreeFromExistingBranchCmd_Local(t *testing.T) {
+	base := t.TempDir()
+	newPath := filepath.Join(base, "repo1", "fix-login")
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("/code/repo1:[worktree add %s fix-login]", newPath): "",
+		},
+	}
+
+	msg := addWorktreeFromExistingBranchCmd(runner, "/code/repo1", base, "repo1", git.Branch{Name: "fix-login"})()
+
+	if _, ok := msg.(WorktreeAddedMsg); !ok {
+		t.Fatalf("expected WorktreeAddedMsg, got %#v", msg)
+	}
+}
+
+func TestUpdate_WorktreeAddedMsg_ExistingSkipsAutoRename(t *testing.T) {
+	m := testModel()
+	m.branchRenames = map[string]model.BranchRenameInfo{}
+
+	result, _ := m.Update(WorktreeAddedMsg{WorktreePath: "/code/fix-login", Branch: "fix-login", Existing: true})
+	updated := result.(Model)
+
+	if _, ok := updated.branchRenames["/code/fix-login"]; ok {
+		t.Error("an existing branch should not be queued for auto-rename")
+	}
+}
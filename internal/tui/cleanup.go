@@ -0,0 +1,298 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/audit"
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/forge"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+	"github.com/mikanfactory/yakumo/internal/trash"
+)
+
+// CleanupCandidate is a worktree whose branch looks finished.
+type CleanupCandidate struct {
+	RepoPath     string
+	WorktreePath string
+	Branch       string
+	Reason       string // e.g. "PR #12 merged"
+	Selected     bool
+}
+
+// CleanupCandidatesMsg carries the worktrees offered by the clean-up view.
+type CleanupCandidatesMsg struct {
+	Candidates []CleanupCandidate
+	Err        error
+}
+
+// WorktreesArchivedMsg is sent when a bulk archive finishes. Err joins the
+// errors of the worktrees that could not be removed.
+type WorktreesArchivedMsg struct {
+	Archived  []string
+	RepoPaths []string // the repositories worktrees were archived from
+	Err       error
+}
+
+// repoSource is what findCleanupCandidates needs to know about a repository.
+type repoSource struct {
+	group   model.RepoGroup
+	forge   string
+	baseRef string
+}
+
+// cleanupCandidatesCmd finds worktrees whose branch is merged into the base
+// ref of its repository, or whose GitHub PR was merged or closed. Main and
+// bare worktrees are never offered. PR lookups are skipped without a GitHub runner and failures there
+// only drop that signal.
+func cleanupCandidatesCmd(runner git.CommandRunner, ghRunner github.Runner, repos []repoSource) tea.Cmd {
+	return func() tea.Msg {
+		var candidates []CleanupCandidate
+		var errs []error
+		for _, repo := range repos {
+			found, err := findCleanupCandidates(runner, ghRunner, repo)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", repo.group.Name, err))
+			}
+			candidates = append(candidates, found...)
+		}
+		return CleanupCandidatesMsg{Candidates: candidates, Err: errors.Join(errs...)}
+	}
+}
+
+func findCleanupCandidates(runner git.CommandRunner, ghRunner github.Runner, repo repoSource) ([]CleanupCandidate, error) {
+	repoPath := repo.group.RootPath
+	merged, err := git.MergedBranches(runner, repoPath, repo.baseRef)
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest PR per branch; gh lists them newest first.
+	prs := make(map[string]github.PRView)
+	if ghRunner != nil && forge.KindFor(repo.forge, runner, repoPath) == forge.KindGitHub {
+		list, err := github.FetchPRStates(ghRunner, repoPath)
+		if err != nil {
+			logging.For("cleanup").Warn("listing PRs failed (non-fatal)", "repo", repoPath, "err", err)
+		}
+		for _, pr := range list {
+			if _, seen := prs[pr.HeadRefName]; !seen {
+				prs[pr.HeadRefName] = pr
+			}
+		}
+	}
+
+	var candidates []CleanupCandidate
+	for _, wt := range repo.group.Worktrees {
+		if wt.IsBare || wt.Branch == "" || wt.Path == repoPath {
+			continue
+		}
+		c := CleanupCandidate{RepoPath: repoPath, WorktreePath: wt.Path, Branch: wt.Branch}
+		pr, hasPR := prs[wt.Branch]
+		switch {
+		case hasPR && pr.State == github.PRStateOpen:
+			continue
+		case hasPR && pr.State == github.PRStateMerged:
+			c.Reason, c.Selected = fmt.Sprintf("PR #%d merged", pr.Number), true
+		case merged[wt.Branch]:
+			c.Reason, c.Selected = "merged into "+repo.baseRef, true
+		case hasPR && pr.State == github.PRStateClosed:
+			// Closed without merging: offered, but kept unless picked.
+			c.Reason = fmt.Sprintf("PR #%d closed", pr.Number)
+		default:
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// archiveWorktreesCmd archives each candidate in turn, carrying on past
+// failures such as worktrees with uncommitted changes.
+func archiveWorktreesCmd(runner git.CommandRunner, tmuxRunner tmux.Runner, bin trash.Trash, auditLog audit.Log, cfg model.Config, targets []CleanupCandidate) tea.Cmd {
+	return func() tea.Msg {
+		var msg WorktreesArchivedMsg
+		var errs []error
+		for _, c := range targets {
+			if err := ArchiveWorktree(runner, tmuxRunner, bin, auditLog, cfg, c.RepoPath, c.WorktreePath); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", c.Branch, err))
+				continue
+			}
+			msg.Archived = append(msg.Archived, c.WorktreePath)
+			if !slices.Contains(msg.RepoPaths, c.RepoPath) {
+				msg.RepoPaths = append(msg.RepoPaths, c.RepoPath)
+			}
+		}
+		msg.Err = errors.Join(errs...)
+		return msg
+	}
+}
+
+// startCleanup opens the clean-up view and starts looking for candidates.
+func (m Model) startCleanup() (Model, tea.Cmd) {
+	repos := make([]repoSource, 0, len(m.groups))
+	for _, g := range m.groups {
+		repos = append(repos, repoSource{group: g, forge: m.repoForge(g.RootPath), baseRef: config.BaseRef(m.config, g.RootPath)})
+	}
+	m.showingCleanup = true
+	m.cleanupLoading = true
+	m.cleanupArchiving = false
+	m.cleanupCandidates = nil
+	m.cleanupCursor = 0
+	m.cleanupErr = nil
+	return m, cleanupCandidatesCmd(m.runner, m.forgeOpts.GitHubRunner, repos)
+}
+
+func (m Model) updateCleanupMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case CleanupCandidatesMsg:
+		m.cleanupLoading = false
+		m.cleanupCandidates = msg.Candidates
+		m.cleanupErr = msg.Err
+		return m, nil
+
+	case WorktreesArchivedMsg:
+		m.cleanupArchiving = false
+		m.cleanupErr = msg.Err
+		archived := make(map[string]bool, len(msg.Archived))
+		for _, path := range msg.Archived {
+			archived[path] = true
+			m.skipPendingRename(path)
+		}
+		var remaining []CleanupCandidate
+		for _, c := range m.cleanupCandidates {
+			if !archived[c.WorktreePath] {
+				remaining = append(remaining, c)
+			}
+		}
+		m.cleanupCandidates = remaining
+		m.cleanupCursor = max(min(m.cleanupCursor, len(remaining)-1), 0)
+		if msg.Err == nil {
+			m.showingCleanup = false
+		}
+		m.loading = true
+		return m, m.refreshRepos(msg.RepoPaths...)
+
+	case tea.KeyMsg:
+		if key.Matches(msg, globalKeys.ForceQuit) {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		if m.cleanupLoading || m.cleanupArchiving {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, cleanupKeys.Close):
+			m.showingCleanup = false
+			m.cleanupCandidates = nil
+			m.cleanupErr = nil
+		case key.Matches(msg, cleanupKeys.Down):
+			if m.cleanupCursor < len(m.cleanupCandidates)-1 {
+				m.cleanupCursor++
+			}
+		case key.Matches(msg, cleanupKeys.Up):
+			if m.cleanupCursor > 0 {
+				m.cleanupCursor--
+			}
+		case key.Matches(msg, cleanupKeys.Toggle):
+			if m.cleanupCursor < len(m.cleanupCandidates) {
+				m.cleanupCandidates = selectCandidates(m.cleanupCandidates, func(i int, c CleanupCandidate) bool {
+					return c.Selected != (i == m.cleanupCursor)
+				})
+			}
+		case key.Matches(msg, cleanupKeys.All):
+			all := !allSelected(m.cleanupCandidates)
+			m.cleanupCandidates = selectCandidates(m.cleanupCandidates, func(int, CleanupCandidate) bool { return all })
+		case key.Matches(msg, cleanupKeys.Archive):
+			var targets []CleanupCandidate
+			for _, c := range m.cleanupCandidates {
+				if c.Selected {
+					targets = append(targets, c)
+				}
+			}
+			if len(targets) == 0 {
+				return m, nil
+			}
+			m.cleanupArchiving = true
+			m.cleanupErr = nil
+			return m.withProgress("Archiving worktrees...", func(runner git.CommandRunner) tea.Cmd {
+				return archiveWorktreesCmd(runner, m.tmuxRunner, m.trash, m.audit.From(audit.SourceCleanup), m.config, targets)
+			})
+		}
+	}
+	return m, nil
+}
+
+// selectCandidates returns a copy of candidates with Selected set by pick,
+// so models sharing the slice are not affected.
+func selectCandidates(candidates []CleanupCandidate, pick func(int, CleanupCandidate) bool) []CleanupCandidate {
+	out := make([]CleanupCandidate, len(candidates))
+	for i, c := range candidates {
+		c.Selected = pick(i, c)
+		out[i] = c
+	}
+	return out
+}
+
+func allSelected(candidates []CleanupCandidate) bool {
+	for _, c := range candidates {
+		if !c.Selected {
+			return false
+		}
+	}
+	return true
+}
+
+func renderCleanupView(m Model) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Clean Up Worktrees"))
+	b.WriteString("\n")
+
+	help := keyhelp.ShortHelp(keyhelp.Bindings(cleanupKeys)...)
+	switch {
+	case m.cleanupLoading:
+		b.WriteString("  Looking for merged worktrees...\n")
+	case m.cleanupArchiving:
+		b.WriteString("  " + m.progressLine("Archiving worktrees...") + "\n")
+	case len(m.cleanupCandidates) == 0:
+		b.WriteString("  Nothing to clean up\n")
+		help = keyhelp.ShortHelp(cleanupKeys.Close)
+	default:
+		clip := lipgloss.NewStyle().MaxWidth(max(m.sidebarWidth, 20))
+		reasonStyle := sortLabelStyle
+		selectedStyle := lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
+		for i, c := range m.cleanupCandidates {
+			box := "[ ]"
+			if c.Selected {
+				box = "[x]"
+			}
+			line := box + " " + c.Branch
+			if i == m.cleanupCursor {
+				line = selectedStyle.Render("> " + line)
+			} else {
+				line = "  " + line
+			}
+			b.WriteString(clip.Render(line + " " + reasonStyle.Render(c.Reason)))
+			b.WriteString("\n")
+		}
+		b.WriteString(helpStyle.PaddingTop(0).Render("  Branches are kept; only the worktrees are removed."))
+		b.WriteString("\n")
+	}
+
+	if m.cleanupErr != nil {
+		b.WriteString(renderErrorBlock(m.cleanupErr, m.width))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render(help))
+	return b.String()
+}
@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/audit"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/trash"
+)
+
+func cleanupRepo() repoSource {
+	return repoSource{
+		forge:   "github",
+		baseRef: "origin/main",
+		group: model.RepoGroup{
+			Name:     "repo1",
+			RootPath: "/code/repo1",
+			Worktrees: []model.WorktreeInfo{
+				{Path: "/code/repo1", Branch: "main"},
+				{Path: "/wt/shipped", Branch: "shipped"},
+				{Path: "/wt/squashed", Branch: "squashed"},
+				{Path: "/wt/abandoned", Branch: "abandoned"},
+				{Path: "/wt/reopened", Branch: "reopened"},
+				{Path: "/wt/wip", Branch: "wip"},
+			},
+		},
+	}
+}
+
+func cleanupRunners() (git.FakeCommandRunner, *github.FakeRunner) {
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/repo1:[for-each-ref --merged origin/main --format=%(refname:short)\t%(objectname) refs/heads]": "main\taaa\nshipped\tbbb\n",
+			"/code/repo1:[rev-list --first-parent origin/main]":                                                   "aaa\n",
+		},
+	}
+	ghRunner := &github.FakeRunner{
+		Outputs: map[string]string{
+			"/code/repo1:[pr list --state all --limit 200 --json number,headRefName,state]": `[
+				{"number": 5, "headRefName": "reopened", "state": "OPEN"},
+				{"number": 4, "headRefName": "squashed", "state": "MERGED"},
+				{"number": 3, "headRefName": "abandoned", "state": "CLOSED"},
+				{"number": 2, "headRefName": "reopened", "state": "CLOSED"}
+			]`,
+		},
+	}
+	return runner, ghRunner
+}
+
+func TestFindCleanupCandidates(t *testing.T) {
+	runner, ghRunner := cleanupRunners()
+
+	got, err := findCleanupCandidates(runner, ghRunner, cleanupRepo())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	for _, c := range got {
+		lines = append(lines, c.Branch+": "+c.Reason+map[bool]string{true: " [x]", false: " [ ]"}[c.Selected])
+	}
+	want := "shipped: merged into origin/main [x]," +
+		"squashed: PR #4 merged [x]," +
+		"abandoned: PR #3 closed [ ]"
+	if strings.Join(lines, ",") != want {
+		t.Errorf("candidates =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.ReplaceAll(want, ",", "\n"))
+	}
+}
+
+func TestFindCleanupCandidates_WithoutGitHub(t *testing.T) {
+	runner, _ := cleanupRunners()
+
+	got, err := findCleanupCandidates(runner, nil, cleanupRepo())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Branch != "shipped" {
+		t.Errorf("got %+v, want only the git-merged branch", got)
+	}
+}
+
+func cleanupModel() Model {
+	m := testModel()
+	m.showingCleanup = true
+	m.cleanupCandidates = []CleanupCandidate{
+		{RepoPath: "/code/repo1", WorktreePath: "/wt/a", Branch: "a", Selected: true},
+		{RepoPath: "/code/repo1", WorktreePath: "/wt/b", Branch: "b"},
+	}
+	return m
+}
+
+func TestUpdate_Cleanup_ToggleAndSelectAll(t *testing.T) {
+	m := cleanupModel()
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m = result.(Model)
+	if m.cleanupCandidates[0].Selected {
+		t.Error("space should unselect the first candidate")
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = result.(Model)
+	if !allSelected(m.cleanupCandidates) {
+		t.Error("a should select every candidate")
+	}
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = result.(Model)
+	for _, c := range m.cleanupCandidates {
+		if c.Selected {
+			t.Error("a again should clear the selection")
+		}
+	}
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("enter with nothing selected should do nothing")
+	}
+}
+
+func TestUpdate_Cleanup_EnterArchivesSelected(t *testing.T) {
+	m := cleanupModel()
+	m.runner = git.FakeCommandRunner{
+		Outputs: map[string]string{"/code/repo1:[worktree remove /wt/a]": ""},
+	}
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !result.(Model).cleanupArchiving || cmd == nil {
+		t.Fatal("enter should archive the selected worktrees")
+	}
+	msg := opMsg(t, cmd).(WorktreesArchivedMsg)
+	if len(msg.Archived) != 1 || msg.Archived[0] != "/wt/a" || msg.Err != nil {
+		t.Errorf("got %+v, want only /wt/a archived", msg)
+	}
+}
+
+func TestArchiveWorktreesCmd_ContinuesPastFailures(t *testing.T) {
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{"/code/repo1:[worktree remove /wt/b]": ""},
+		Errors:  map[string]error{"/code/repo1:[worktree remove /wt/a]": errors.New("contains modified files")},
+	}
+	targets := []CleanupCandidate{
+		{RepoPath: "/code/repo1", WorktreePath: "/wt/a", Branch: "a"},
+		{RepoPath: "/code/repo1", WorktreePath: "/wt/b", Branch: "b"},
+	}
+
+	msg := archiveWorktreesCmd(runner, nil, trash.Trash{}, audit.Log{}, model.Config{}, targets)().(WorktreesArchivedMsg)
+
+	if len(msg.Archived) != 1 || msg.Archived[0] != "/wt/b" {
+		t.Errorf("Archived = %v, want [/wt/b]", msg.Archived)
+	}
+	if msg.Err == nil || !strings.Contains(msg.Err.Error(), "a: contains modified files") {
+		t.Errorf("Err = %v", msg.Err)
+	}
+}
+
+func TestUpdate_WorktreesArchivedMsg_KeepsFailedOpen(t *testing.T) {
+	m := cleanupModel()
+	m.cleanupArchiving = true
+
+	result, cmd := m.Update(WorktreesArchivedMsg{Archived: []string{"/wt/a"}, Err: errors.New("b: dirty")})
+	updated := result.(Model)
+
+	if !updated.showingCleanup || len(updated.cleanupCandidates) != 1 || updated.cleanupCandidates[0].Branch != "b" {
+		t.Errorf("the failed worktree should stay listed, got %+v", updated.cleanupCandidates)
+	}
+	if cmd == nil {
+		t.Error("the sidebar should reload after archiving")
+	}
+	if !strings.Contains(renderCleanupView(updated), "b: dirty") {
+		t.Error("the view should show the failure")
+	}
+
+	result, _ = m.Update(WorktreesArchivedMsg{Archived: []string{"/wt/a"}})
+	if result.(Model).showingCleanup {
+		t.Error("a clean run should close the view")
+	}
+}
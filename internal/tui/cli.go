@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/forge"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// AddWorktree creates a worktree of the repository at repoPath as the add
+// prompt does, for `yakumo add`: off the base ref under a random name, or for
+// rawURL when set, checking out the branch of a PR or branch URL, or naming a
+// new branch after a GitHub issue. The worktree is seeded and its post_create
+// hook run; a failed hook is left in HookErr and keeps the worktree.
+func AddWorktree(cfg model.Config, runner git.CommandRunner, forgeOpts forge.Options, repoPath, rawURL string) (WorktreeAddedMsg, error) {
+	repoName := repoNameFromConfig(cfg, repoPath)
+	base := config.WorktreeBase(cfg, repoPath)
+	cfg.DefaultBaseRef = config.BaseRef(cfg, repoPath)
+	if forgeOpts.GitRunner == nil {
+		forgeOpts.GitRunner = runner
+	}
+
+	var create tea.Cmd
+	switch info, err := github.ParseGitHubURL(rawURL); {
+	case rawURL == "":
+		create = addWorktreeCmd(runner, repoPath, base, repoName, cfg.DefaultBaseRef, "")
+	case err == nil && info.Type == github.URLTypeIssue:
+		if forgeOpts.GitHubRunner == nil {
+			return WorktreeAddedMsg{}, fmt.Errorf("creating a worktree from an issue needs gh or a GitHub token")
+		}
+		create = addWorktreeFromIssueCmd(runner, forgeOpts.GitHubRunner, nil, nil, repoPath, base, repoName, cfg.DefaultBaseRef, "", rawURL)
+	default:
+		provider, err := providerForURL(cfg, forgeOpts, repoPath, rawURL)
+		if err != nil {
+			return WorktreeAddedMsg{}, err
+		}
+		create = addWorktreeFromURLCmd(runner, provider, repoPath, base, repoName, rawURL)
+	}
+
+	switch msg := seedWorktreeCmd(cfg, "", create)().(type) {
+	case WorktreeAddedMsg:
+		return msg, nil
+	case WorktreeAddErrMsg:
+		return WorktreeAddedMsg{}, msg.Err
+	default:
+		return WorktreeAddedMsg{}, fmt.Errorf("unexpected result %T", msg)
+	}
+}
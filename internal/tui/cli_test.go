@@ -0,0 +1,31 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/forge"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestAddWorktree(t *testing.T) {
+	cfg := model.Config{
+		DefaultBaseRef:   "origin/main",
+		WorktreeBasePath: t.TempDir(),
+		Repositories:     []model.RepositoryDef{{Name: "myrepo", Path: "/repo"}},
+	}
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{"/repo:[config user.name]": "testuser\n"},
+		Errors:  map[string]error{"/repo:[fetch origin main]": fmt.Errorf("network error")},
+	}
+
+	if _, err := AddWorktree(cfg, runner, forge.Options{}, "/repo", ""); err == nil || !strings.Contains(err.Error(), "fetching origin/main") {
+		t.Errorf("err = %v, want the fetch failure", err)
+	}
+	_, err := AddWorktree(cfg, runner, forge.Options{}, "/repo", "https://github.com/owner/repo/issues/7")
+	if err == nil || !strings.Contains(err.Error(), "needs gh") {
+		t.Errorf("err = %v, want an issue to need GitHub access", err)
+	}
+}
@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// GroupStore persists which repository groups are collapsed in the sidebar.
+type GroupStore interface {
+	Get() map[string]bool
+	Set(repoPath string, collapsed bool) error
+}
+
+// WithGroupStore returns a copy of the model that restores collapsed groups
+// from store and saves them whenever a group is toggled.
+func (m Model) WithGroupStore(store GroupStore) Model {
+	m.groupStore = store
+	if store != nil {
+		m.collapsed = store.Get()
+	}
+	return m
+}
+
+// toggleGroup collapses or expands the repository group whose header is
+// under the cursor, leaving the cursor on that header.
+func (m Model) toggleGroup() Model {
+	if m.cursor >= len(m.items) || m.items[m.cursor].Kind != model.ItemKindGroupHeader {
+		return m
+	}
+	repoPath := m.items[m.cursor].RepoRootPath
+	collapsed := make(map[string]bool, len(m.collapsed)+1)
+	for path, c := range m.collapsed {
+		collapsed[path] = c
+	}
+	collapsed[repoPath] = !collapsed[repoPath]
+	m.collapsed = collapsed
+
+	if m.groupStore != nil {
+		if err := m.groupStore.Set(repoPath, collapsed[repoPath]); err != nil {
+			logging.For("sidebar").Warn("saving collapsed groups failed", "err", err)
+		}
+	}
+
+	m.items = buildItems(m)
+	for i, item := range m.items {
+		if item.Kind == model.ItemKindGroupHeader && item.RepoRootPath == repoPath {
+			m.cursor = i
+			break
+		}
+	}
+	return recomputeScroll(m)
+}
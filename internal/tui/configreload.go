@@ -0,0 +1,65 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/agent"
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// ConfigReloadedMsg carries the config read again after its file changed.
+type ConfigReloadedMsg struct {
+	Config model.Config
+	Err    error
+}
+
+// WithConfigReload returns a copy of the model that reads its config file
+// again whenever changes receives, such as from a config.Watcher.
+func (m Model) WithConfigReload(changes <-chan struct{}) Model {
+	m.configChanges = changes
+	return m
+}
+
+// waitForConfigChangeCmd waits for the next change and reads the file at
+// path. It ends with changes.
+func waitForConfigChangeCmd(changes <-chan struct{}, path string) tea.Cmd {
+	return func() tea.Msg {
+		if _, ok := <-changes; !ok {
+			return nil
+		}
+		cfg, err := config.LoadFromFile(path)
+		return ConfigReloadedMsg{Config: cfg, Err: err}
+	}
+}
+
+// applyConfigReload switches to the reloaded repositories and settings and
+// refreshes the list. An invalid config is reported and the running one
+// kept. Key bindings, the theme, tmux naming, paranoid mode, port_base,
+// trash_dir and the commands the session restore launches are set up once
+// at start, so those take a restart.
+func (m Model) applyConfigReload(msg ConfigReloadedMsg) (Model, tea.Cmd) {
+	wait := waitForConfigChangeCmd(m.configChanges, m.configPath)
+	if msg.Err != nil {
+		m, toast := m.notify(SeverityWarning, "Config not reloaded", msg.Err.Error())
+		return m, tea.Batch(wait, toast)
+	}
+
+	cfg := msg.Config
+	m.config = cfg
+	m.sidebarWidth = cfg.SidebarWidth
+	if d, err := config.ParseAgentPollInterval(cfg.AgentPollInterval); err == nil {
+		m.agentPollInterval = d
+	}
+	if profiles, err := agent.ParseProfiles(cfg.Agents); err == nil {
+		m.agentProfiles = profiles
+	}
+	if err := logging.SetLevel(cfg.LogLevel); err != nil {
+		logging.For("tui").Warn("setting the reloaded log level failed (non-fatal)", "err", err)
+	}
+
+	m, refresh := m.refresh()
+	m, toast := m.notify(SeverityInfo, "Config reloaded", "")
+	return recomputeScroll(m), tea.Batch(wait, refresh, toast)
+}
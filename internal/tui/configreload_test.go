@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigReload(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "sidebar_width: 40\nrepositories:\n  - name: repo1\n    path: /code/repo1\n  - name: repo2\n    path: /code/repo2\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changes := make(chan struct{}, 1)
+	m := testModel().WithConfigReload(changes)
+	m.configPath = cfgPath
+
+	changes <- struct{}{}
+	result, cmd := m.Update(waitForConfigChangeCmd(changes, cfgPath)())
+	m = result.(Model)
+	if len(m.config.Repositories) != 2 || m.sidebarWidth != 40 {
+		t.Errorf("config = %+v, sidebarWidth = %d; want the reloaded one", m.config, m.sidebarWidth)
+	}
+	if m.toast.Text != "Config reloaded" || cmd == nil {
+		t.Errorf("toast = %q, cmd = %v; want a toast and a refresh", m.toast.Text, cmd)
+	}
+
+	if err := os.WriteFile(cfgPath, []byte("tmux_mode: pane\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changes <- struct{}{}
+	result, _ = m.Update(waitForConfigChangeCmd(changes, cfgPath)())
+	m = result.(Model)
+	if len(m.config.Repositories) != 2 || m.toast.Severity != SeverityWarning || m.toast.Text != "Config not reloaded" {
+		t.Errorf("an invalid config should be reported and the running one kept: toast = %+v", m.toast)
+	}
+
+	close(changes)
+	if msg := waitForConfigChangeCmd(changes, cfgPath)(); msg != nil {
+		t.Errorf("waiting should end with the watcher, got %#v", msg)
+	}
+}
@@ -0,0 +1,226 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/devlog"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+)
+
+// debugLogMaxLines caps how much of debug.log the viewer loads.
+const debugLogMaxLines = 2000
+
+// DebugLogMsg carries the tail of debug.log.
+type DebugLogMsg struct {
+	Lines []string
+	Err   error
+}
+
+// WithDebugLog returns a copy of the model whose ctrl+l viewer reads the
+// log at path.
+func (m Model) WithDebugLog(path string) Model {
+	m.debugLogPath = path
+	return m
+}
+
+func loadDebugLogCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		lines, err := devlog.Tail(path, debugLogMaxLines)
+		if errors.Is(err, os.ErrNotExist) {
+			return DebugLogMsg{}
+		}
+		return DebugLogMsg{Lines: lines, Err: err}
+	}
+}
+
+var debugLogComponent = regexp.MustCompile(`\bcomponent=(\S+)`)
+
+// debugLogComponents returns the subsystems that wrote lines, sorted.
+func debugLogComponents(lines []string) []string {
+	var components []string
+	for _, l := range lines {
+		if m := debugLogComponent.FindStringSubmatch(l); m != nil && !slices.Contains(components, m[1]) {
+			components = append(components, m[1])
+		}
+	}
+	slices.Sort(components)
+	return components
+}
+
+// debugLogLines returns the lines written by component, or every line when
+// it is "".
+func debugLogLines(lines []string, component string) []string {
+	if component == "" {
+		return lines
+	}
+	var filtered []string
+	for _, l := range lines {
+		if m := debugLogComponent.FindStringSubmatch(l); m != nil && m[1] == component {
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered
+}
+
+// debugLogPrefix is the time, level and msg keys slog starts a line with.
+var debugLogPrefix = regexp.MustCompile(`^time=\S*T(\d\d:\d\d:\d\d)\S* level=(\w+) msg=`)
+
+// compactDebugLogLine shortens the slog prefix of line to "15:04:05 WARN ",
+// leaving more of the narrow sidebar to the message.
+func compactDebugLogLine(line string) string {
+	return debugLogPrefix.ReplaceAllString(line, "$1 $2 ")
+}
+
+// renderDebugLogLine wraps a line to the sidebar, colored by its level.
+func renderDebugLogLine(m Model, line string) string {
+	return debugLogLineStyle(line).Width(max(m.sidebarWidth, 20)).Render(compactDebugLogLine(line))
+}
+
+// debugLogBottom is the scroll offset, in lines of the log, that shows the
+// end of the shown lines.
+func debugLogBottom(m Model) int {
+	vp := viewportHeight(m.height)
+	shown := debugLogLines(m.debugLogLines, m.debugLogFilter)
+	if vp <= 0 {
+		return 0
+	}
+	used := 0
+	for i := len(shown) - 1; i >= 0; i-- {
+		used += lipgloss.Height(renderDebugLogLine(m, shown[i]))
+		if used > vp {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// openDebugLog opens the viewer on every subsystem, scrolled to the end once
+// the log is read.
+func (m Model) openDebugLog() (Model, tea.Cmd) {
+	if m.debugLogPath == "" {
+		return m.notify(SeverityWarning, "No debug log", "")
+	}
+	m.showingDebugLog = true
+	m.debugLogLoading = true
+	m.debugLogLines = nil
+	m.debugLogErr = nil
+	m.debugLogFilter = ""
+	m.debugLogScroll = 0
+	return m, loadDebugLogCmd(m.debugLogPath)
+}
+
+// cycleDebugLogFilter moves the filter to the next subsystem, or the
+// previous one when back is set, passing through "all" between the last
+// and the first.
+func (m Model) cycleDebugLogFilter(back bool) Model {
+	choices := append([]string{""}, debugLogComponents(m.debugLogLines)...)
+	i := max(slices.Index(choices, m.debugLogFilter), 0)
+	if back {
+		i--
+	} else {
+		i++
+	}
+	m.debugLogFilter = choices[(i+len(choices))%len(choices)]
+	m.debugLogScroll = debugLogBottom(m)
+	return m
+}
+
+func (m Model) updateDebugLogMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case DebugLogMsg:
+		m.debugLogLoading = false
+		m.debugLogLines = msg.Lines
+		m.debugLogErr = msg.Err
+		m.debugLogScroll = debugLogBottom(m)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, debugLogKeys.Close):
+			m.showingDebugLog = false
+			m.debugLogLines = nil
+			return m, nil
+		case key.Matches(msg, globalKeys.ForceQuit):
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, debugLogKeys.Down):
+			shown := debugLogLines(m.debugLogLines, m.debugLogFilter)
+			m.debugLogScroll = min(m.debugLogScroll+1, max(len(shown)-1, 0))
+		case key.Matches(msg, debugLogKeys.Up):
+			if m.debugLogScroll > 0 {
+				m.debugLogScroll--
+			}
+		case key.Matches(msg, debugLogKeys.Top):
+			m.debugLogScroll = 0
+		case key.Matches(msg, debugLogKeys.Bottom):
+			m.debugLogScroll = debugLogBottom(m)
+		case key.Matches(msg, debugLogKeys.Next, debugLogKeys.Prev):
+			return m.cycleDebugLogFilter(key.Matches(msg, debugLogKeys.Prev)), nil
+		case key.Matches(msg, debugLogKeys.Reload):
+			m.debugLogLoading = true
+			return m, loadDebugLogCmd(m.debugLogPath)
+		}
+	}
+	return m, nil
+}
+
+// debugLogLineStyle colors a line by its level.
+func debugLogLineStyle(line string) lipgloss.Style {
+	switch {
+	case strings.Contains(line, "level=ERROR"):
+		return lipgloss.NewStyle().Foreground(colorRed)
+	case strings.Contains(line, "level=WARN"):
+		return lipgloss.NewStyle().Foreground(colorYellow)
+	case strings.Contains(line, "level=DEBUG"):
+		return lipgloss.NewStyle().Foreground(colorFgDim)
+	}
+	return lipgloss.NewStyle()
+}
+
+func renderDebugLogView(m Model) string {
+	var b strings.Builder
+
+	filter := m.debugLogFilter
+	if filter == "" {
+		filter = "all"
+	}
+	b.WriteString(titleStyle.Render("Debug Log: " + filter))
+	b.WriteString("\n")
+
+	shown := debugLogLines(m.debugLogLines, m.debugLogFilter)
+	switch {
+	case m.debugLogLoading && len(m.debugLogLines) == 0:
+		b.WriteString("  Loading log...\n")
+	case m.debugLogErr != nil:
+		b.WriteString(renderErrorBlock(m.debugLogErr, m.width))
+		b.WriteString("\n")
+	case len(m.debugLogLines) == 0:
+		b.WriteString("  Nothing logged yet\n")
+	case len(shown) == 0:
+		b.WriteString("  Nothing logged by " + filter + "\n")
+	default:
+		vp := viewportHeight(m.height)
+		used := 0
+		for _, l := range shown[min(m.debugLogScroll, len(shown)-1):] {
+			line := renderDebugLogLine(m, l)
+			h := lipgloss.Height(line)
+			if vp > 0 && used+h > vp {
+				break
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+			used += h
+		}
+	}
+
+	b.WriteString(helpStyle.Render(keyhelp.ShortHelp(debugLogKeys.Up, debugLogKeys.Down, debugLogKeys.Next, debugLogKeys.Reload, debugLogKeys.Close)))
+	return b.String()
+}
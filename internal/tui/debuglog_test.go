@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const testDebugLog = `time=2026-10-16T10:00:00Z level=DEBUG msg="ran git" component=git dir=/code/repo1 args="worktree list"
+time=2026-10-16T10:00:01Z level=WARN msg="tmux rename-session failed (non-fatal)" component=branch-rename err="no such session"
+time=2026-10-16T10:00:02Z level=DEBUG msg="ran tmux" component=tmux args="list-sessions"
+time=2026-10-16T10:00:03Z level=INFO msg="renameBranch: success" component=branch-rename from=me/tokyo to=me/fix-login
+`
+
+func TestDebugLog_FilterBySubsystem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "debug.log")
+	if err := os.WriteFile(path, []byte(testDebugLog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := testModel().WithDebugLog(path)
+	m.height = 20
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	m = result.(Model)
+	if !m.showingDebugLog || cmd == nil {
+		t.Fatal("ctrl+l should open the debug log and read it")
+	}
+	result, _ = m.Update(cmd())
+	m = result.(Model)
+	if view := renderDebugLogView(m); !strings.Contains(view, "Debug Log: all") || !strings.Contains(view, "ran tmux") {
+		t.Errorf("view should show every line:\n%s", view)
+	}
+
+	// Subsystems are cycled in name order: branch-rename, git, tmux.
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = result.(Model)
+	view := renderDebugLogView(m)
+	if !strings.Contains(view, "Debug Log: branch-rename") || !strings.Contains(view, "fix-login") || strings.Contains(view, "ran git") {
+		t.Errorf("tab should show branch-rename only:\n%s", view)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	result, _ = result.(Model).Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	m = result.(Model)
+	if m.debugLogFilter != "tmux" {
+		t.Errorf("shift+tab should wrap around to the last subsystem, filter = %q", m.debugLogFilter)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	if result.(Model).showingDebugLog {
+		t.Error("ctrl+l should close the debug log")
+	}
+}
+
+func TestDebugLog_NotWrittenYet(t *testing.T) {
+	m := testModel().WithDebugLog(filepath.Join(t.TempDir(), "debug.log"))
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	result, _ = result.(Model).Update(cmd())
+	m = result.(Model)
+	if m.debugLogErr != nil || !strings.Contains(renderDebugLogView(m), "Nothing logged yet") {
+		t.Errorf("a missing log should read as empty, err = %v", m.debugLogErr)
+	}
+}
+
+func TestDebugLog_HiddenFromHelp(t *testing.T) {
+	if strings.Contains(workspacesHelp(), "debug log") {
+		t.Errorf("the help line should not list ctrl+l: %s", workspacesHelp())
+	}
+}
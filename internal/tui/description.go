@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+)
+
+// BranchDescriptionSetMsg is sent when a branch description has been saved.
+type BranchDescriptionSetMsg struct{}
+
+// BranchDescriptionErrMsg is sent when saving a branch description fails.
+type BranchDescriptionErrMsg struct {
+	Err error
+}
+
+func setBranchDescriptionCmd(runner git.CommandRunner, worktreePath, branch, description string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.SetBranchDescription(runner, worktreePath, branch, description); err != nil {
+			return BranchDescriptionErrMsg{Err: err}
+		}
+		return BranchDescriptionSetMsg{}
+	}
+}
+
+func (m Model) updateEditDescriptionMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEscape:
+			m.editingDescription = false
+			m.textInput.SetValue("")
+			return m, nil
+		case tea.KeyEnter:
+			description := strings.TrimSpace(m.textInput.Value())
+			m.textInput.SetValue("")
+			m.editingDescription = false
+			if description == m.descriptionOld {
+				return m, nil
+			}
+			m.loading = true
+			m.err = nil
+			return m, setBranchDescriptionCmd(m.runner, m.descriptionPath, m.descriptionBranch, description)
+		case tea.KeyCtrlC:
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func renderEditDescriptionView(m Model) string {
+	return modalLayout{
+		title:  "Describe Branch",
+		prompt: "One-line purpose for " + m.descriptionBranch + ":",
+		input:  m.textInput.View(),
+		help:   "enter: save (empty clears)  esc: cancel",
+	}.render(m.width, m.height)
+}
@@ -0,0 +1,237 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/devlog"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+)
+
+// devLogMaxLines caps how much of the dev-server log the viewer loads.
+const devLogMaxLines = 2000
+
+// devLogPollInterval is how often follow mode re-reads the log.
+const devLogPollInterval = time.Second
+
+// DevLogMsg carries the tail of a worktree's dev-server log.
+type DevLogMsg struct {
+	WorktreePath string
+	Lines        []string
+	Err          error
+}
+
+// DevLogTickMsg re-reads the log while follow mode is on. Gen is the
+// follow it belongs to; ticks of an earlier one are dropped, so turning
+// follow on again does not add a second chain of reads.
+type DevLogTickMsg struct {
+	WorktreePath string
+	Gen          int
+}
+
+func loadDevLogCmd(worktreePath string) tea.Cmd {
+	return func() tea.Msg {
+		path, err := devlog.Path(worktreePath)
+		if err != nil {
+			return DevLogMsg{WorktreePath: worktreePath, Err: err}
+		}
+		lines, err := devlog.Tail(path, devLogMaxLines)
+		return DevLogMsg{WorktreePath: worktreePath, Lines: lines, Err: err}
+	}
+}
+
+func devLogTickCmd(worktreePath string, gen int) tea.Cmd {
+	return tea.Tick(devLogPollInterval, func(time.Time) tea.Msg {
+		return DevLogTickMsg{WorktreePath: worktreePath, Gen: gen}
+	})
+}
+
+// followDevLog turns follow mode on, reloading the log now and starting a
+// new chain of ticks that replaces any running one.
+func (m Model) followDevLog() (Model, tea.Cmd) {
+	m.devLogFollow = true
+	m.devLogGen++
+	return m, tea.Batch(loadDevLogCmd(m.devLogPath), devLogTickCmd(m.devLogPath, m.devLogGen))
+}
+
+// devLogBottom is the scroll offset that shows the end of the log.
+func devLogBottom(m Model) int {
+	vp := viewportHeight(m.height)
+	if vp <= 0 {
+		return 0
+	}
+	return max(len(m.devLogLines)-vp, 0)
+}
+
+// devLogFind returns the next line at or after from (searching backwards
+// when back is set) containing the query, wrapping around, or -1.
+func devLogFind(lines []string, query string, from int, back bool) int {
+	if query == "" || len(lines) == 0 {
+		return -1
+	}
+	q := strings.ToLower(query)
+	for n := range len(lines) {
+		i := from + n
+		if back {
+			i = from - n
+		}
+		i = ((i % len(lines)) + len(lines)) % len(lines)
+		if strings.Contains(strings.ToLower(lines[i]), q) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m Model) updateDevLogMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case DevLogMsg:
+		if msg.WorktreePath != m.devLogPath {
+			return m, nil
+		}
+		m.devLogLoading = false
+		m.devLogLines = msg.Lines
+		m.devLogErr = msg.Err
+		if m.devLogFollow {
+			m.devLogScroll = devLogBottom(m)
+		}
+		return m, nil
+
+	case DevLogTickMsg:
+		if msg.WorktreePath != m.devLogPath || msg.Gen != m.devLogGen || !m.devLogFollow {
+			return m, nil
+		}
+		return m, tea.Batch(loadDevLogCmd(m.devLogPath), devLogTickCmd(m.devLogPath, m.devLogGen))
+
+	case tea.KeyMsg:
+		if m.devLogSearching {
+			return m.updateDevLogSearch(msg)
+		}
+		switch {
+		case key.Matches(msg, devLogKeys.Close):
+			m.showingDevLog = false
+			m.devLogFollow = false
+			m.devLogLines = nil
+			return m, nil
+		case key.Matches(msg, globalKeys.ForceQuit):
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, devLogKeys.Down):
+			m.devLogFollow = false
+			m.devLogScroll = min(m.devLogScroll+1, max(len(m.devLogLines)-1, 0))
+		case key.Matches(msg, devLogKeys.Up):
+			m.devLogFollow = false
+			if m.devLogScroll > 0 {
+				m.devLogScroll--
+			}
+		case key.Matches(msg, devLogKeys.Top):
+			m.devLogFollow = false
+			m.devLogScroll = 0
+		case key.Matches(msg, devLogKeys.Bottom):
+			m.devLogScroll = devLogBottom(m)
+		case key.Matches(msg, devLogKeys.Follow):
+			if m.devLogFollow {
+				m.devLogFollow = false
+				return m, nil
+			}
+			m.devLogScroll = devLogBottom(m)
+			return m.followDevLog()
+		case key.Matches(msg, devLogKeys.Search):
+			m.devLogSearching = true
+			m.textInput.Placeholder = "search log"
+			m.textInput.SetValue(m.devLogQuery)
+			m.textInput.CursorEnd()
+			return m, m.textInput.Focus()
+		case key.Matches(msg, devLogKeys.Next, devLogKeys.Prev):
+			back := key.Matches(msg, devLogKeys.Prev)
+			from := m.devLogScroll + 1
+			if back {
+				from = m.devLogScroll - 1
+			}
+			if i := devLogFind(m.devLogLines, m.devLogQuery, from, back); i >= 0 {
+				m.devLogFollow = false
+				m.devLogScroll = i
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateDevLogSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		m.devLogSearching = false
+		m.textInput.SetValue("")
+		return m, nil
+	case tea.KeyEnter:
+		m.devLogSearching = false
+		m.devLogQuery = m.textInput.Value()
+		m.textInput.SetValue("")
+		// Search backwards from the bottom of the screen so the most recent
+		// match is found first.
+		from := min(m.devLogScroll+max(viewportHeight(m.height), 1)-1, len(m.devLogLines)-1)
+		if i := devLogFind(m.devLogLines, m.devLogQuery, from, true); i >= 0 {
+			m.devLogFollow = false
+			m.devLogScroll = i
+		}
+		return m, nil
+	case tea.KeyCtrlC:
+		m.quitting = true
+		return m, tea.Quit
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func renderDevLogView(m Model) string {
+	var b strings.Builder
+
+	title := "Dev Log: " + m.devLogLabel
+	if m.devLogFollow {
+		title += " (following)"
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n")
+
+	switch {
+	case m.devLogLoading:
+		b.WriteString("  Loading log...\n")
+	case m.devLogErr != nil:
+		b.WriteString(renderErrorBlock(m.devLogErr, m.width))
+		b.WriteString("\n")
+	case len(m.devLogLines) == 0:
+		b.WriteString("  Log is empty\n")
+	default:
+		start := min(m.devLogScroll, len(m.devLogLines)-1)
+		end := len(m.devLogLines)
+		if vp := viewportHeight(m.height); vp > 0 && start+vp < end {
+			end = start + vp
+		}
+		clip := lipgloss.NewStyle().MaxWidth(max(m.sidebarWidth, 20))
+		matchStyle := lipgloss.NewStyle().Foreground(colorYellow)
+		query := strings.ToLower(m.devLogQuery)
+		for _, l := range m.devLogLines[start:end] {
+			if query != "" && strings.Contains(strings.ToLower(l), query) {
+				l = matchStyle.Render(l)
+			}
+			b.WriteString(clip.Render(l))
+			b.WriteString("\n")
+		}
+	}
+
+	switch {
+	case m.devLogSearching:
+		b.WriteString(helpStyle.Render("/ " + m.textInput.View()))
+	case m.devLogQuery != "":
+		b.WriteString(helpStyle.Render("/" + m.devLogQuery + "  " + keyhelp.ShortHelp(devLogKeys.Next, devLogKeys.Prev, devLogKeys.Follow, devLogKeys.Close)))
+	default:
+		b.WriteString(helpStyle.Render(keyhelp.ShortHelp(devLogKeys.Up, devLogKeys.Down, devLogKeys.Bottom, devLogKeys.Search, devLogKeys.Follow, devLogKeys.Close)))
+	}
+	return b.String()
+}
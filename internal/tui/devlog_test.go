@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func devLogModel() Model {
+	m := testModel()
+	m.height = 10
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	m = result.(Model)
+
+	var lines []string
+	for i := range 30 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	lines[5] = "ERROR boom"
+	lines[20] = "error again"
+	result, _ = m.Update(DevLogMsg{WorktreePath: m.devLogPath, Lines: lines})
+	return result.(Model)
+}
+
+func TestUpdate_L_OpensDevLogFollowing(t *testing.T) {
+	m := testModel()
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	m = result.(Model)
+	if !m.showingDevLog || !m.devLogFollow || m.devLogPath != "/code/repo1" {
+		t.Fatalf("L should open the log of the selected worktree in follow mode, got %+v", m.devLogPath)
+	}
+	if cmd == nil {
+		t.Error("L should load the log")
+	}
+}
+
+func TestDevLog_FollowScrollsToEnd(t *testing.T) {
+	m := devLogModel()
+	if m.devLogScroll != devLogBottom(m) || m.devLogScroll == 0 {
+		t.Errorf("follow mode should show the end of the log, scroll = %d", m.devLogScroll)
+	}
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	m = result.(Model)
+	if m.devLogFollow {
+		t.Error("scrolling up should stop following")
+	}
+	_, cmd := m.Update(DevLogTickMsg{WorktreePath: m.devLogPath, Gen: m.devLogGen})
+	if cmd != nil {
+		t.Error("ticks should stop reloading once follow is off")
+	}
+}
+
+func TestDevLog_RefollowDropsOldTicks(t *testing.T) {
+	m := devLogModel()
+	old := m.devLogGen
+
+	for _, k := range []string{"f", "f"} {
+		result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		m = result.(Model)
+	}
+	if !m.devLogFollow || m.devLogGen == old {
+		t.Fatalf("following again should start a new generation, follow = %v gen = %d", m.devLogFollow, m.devLogGen)
+	}
+	if _, cmd := m.Update(DevLogTickMsg{WorktreePath: m.devLogPath, Gen: old}); cmd != nil {
+		t.Error("a tick of the earlier follow should be dropped")
+	}
+	if _, cmd := m.Update(DevLogTickMsg{WorktreePath: m.devLogPath, Gen: m.devLogGen}); cmd == nil {
+		t.Error("a tick of the current follow should reload")
+	}
+}
+
+func TestDevLog_Search(t *testing.T) {
+	m := devLogModel()
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = result.(Model)
+	for _, r := range "error" {
+		result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = result.(Model)
+	}
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if m.devLogQuery != "error" || m.devLogScroll != 20 {
+		t.Fatalf("search should jump to the latest match, query = %q scroll = %d", m.devLogQuery, m.devLogScroll)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	m = result.(Model)
+	if m.devLogScroll != 5 {
+		t.Errorf("N should move to the previous match (case-insensitive), got %d", m.devLogScroll)
+	}
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m = result.(Model)
+	if m.devLogScroll != 20 {
+		t.Errorf("n should move to the next match, got %d", m.devLogScroll)
+	}
+}
+
+func TestDevLog_EscCloses(t *testing.T) {
+	m := devLogModel()
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	m = result.(Model)
+	if m.showingDevLog || m.devLogFollow {
+		t.Error("esc should close the viewer and stop following")
+	}
+}
+
+func TestDevLogFind_Wraps(t *testing.T) {
+	lines := []string{"a", "match", "b"}
+	if got := devLogFind(lines, "match", 2, false); got != 1 {
+		t.Errorf("forward search should wrap, got %d", got)
+	}
+	if got := devLogFind(lines, "missing", 0, false); got != -1 {
+		t.Errorf("no match should return -1, got %d", got)
+	}
+}
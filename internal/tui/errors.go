@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"errors"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+const (
+	gitRetryDelay = time.Second
+	maxGitRetries = 3
+)
+
+// GitDataRetryMsg triggers a refetch after a transient git failure.
+type GitDataRetryMsg struct{}
+
+func gitRetryCmd() tea.Cmd {
+	return tea.Tick(gitRetryDelay, func(time.Time) tea.Msg {
+		return GitDataRetryMsg{}
+	})
+}
+
+// isTransientGitError reports whether a git failure is likely to succeed
+// if retried shortly, e.g. another git process holding index.lock.
+func isTransientGitError(err error) bool {
+	return git.IsTransient(err)
+}
+
+// errorHint returns a short suggestion for errors the user can fix, or "".
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, git.ErrGitNotFound):
+		return "Install git and make sure it is on your PATH."
+	case errors.Is(err, git.ErrNotARepository):
+		return "Check that the path points at a git repository."
+	case errors.Is(err, git.ErrPermissionDenied), errors.Is(err, tmux.ErrPermissionDenied):
+		return "Check file permissions for the repository and worktree directories."
+	case errors.Is(err, tmux.ErrTmuxNotFound), errors.Is(err, tmux.ErrNoServer):
+		return "Run yakumo inside a tmux session."
+	case errors.Is(err, github.ErrNotAuthenticated):
+		return "Run `gh auth login` or set GH_TOKEN."
+	case errors.Is(err, github.ErrGhNotFound):
+		return "Install gh or set GH_TOKEN to resolve PR URLs."
+	case errors.Is(err, github.ErrRateLimited):
+		return "GitHub rate limit reached; try again in a few minutes."
+	default:
+		return ""
+	}
+}
+
+// renderErrorBlock renders err for modal views, wrapped to width (0 leaves it
+// unwrapped), followed by a hint when one applies. A nil err renders nothing.
+func renderErrorBlock(err error, width int) string {
+	if err == nil {
+		return ""
+	}
+	// errorStyle adds one column of padding in front of the indent.
+	s := errorStyle.Render(indentLines(wrapText("Error: "+err.Error(), width-3), "  "))
+	if hint := errorHint(err); hint != "" {
+		s += "\n" + helpStyle.Render(indentLines(wrapText(hint, width-3), "  "))
+	}
+	return s
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// applyFilter rebuilds the sidebar items from m.groups, keeping only those
+// matching m.filterQuery, and moves the cursor to the first result.
+func applyFilter(m Model) Model {
+	m.items = buildItems(m)
+	m.cursor = FirstSelectable(m.items)
+	return recomputeScroll(m)
+}
+
+func (m Model) updateFilterMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, filterKeys.Cancel):
+		m.filtering = false
+		m.filterQuery = ""
+		m.textInput.SetValue("")
+		return applyFilter(m), nil
+	case key.Matches(msg, filterKeys.Open):
+		m.filtering = false
+		m.textInput.SetValue("")
+		if m.cursor < len(m.items) && m.items[m.cursor].Kind == model.ItemKindWorktree {
+			item := m.items[m.cursor]
+			next, cmd := m.selectWorktree(item.WorktreePath, item.RepoRootPath)
+			return next, cmd
+		}
+		m.filterQuery = ""
+		return applyFilter(m), nil
+	case key.Matches(msg, globalKeys.ForceQuit):
+		m.quitting = true
+		return m, tea.Quit
+	case key.Matches(msg, filterKeys.Up):
+		m.cursor = PrevSelectable(m.items, m.cursor)
+		return recomputeScroll(m), nil
+	case key.Matches(msg, filterKeys.Down):
+		m.cursor = NextSelectable(m.items, m.cursor)
+		return recomputeScroll(m), nil
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	if query := m.textInput.Value(); query != m.filterQuery {
+		m.filterQuery = query
+		m = applyFilter(m)
+	}
+	return m, cmd
+}
+
+// renderHighlighted renders s with style, drawing the runes at positions in
+// the filter highlight color.
+func renderHighlighted(s string, positions []int, style lipgloss.Style) string {
+	if len(positions) == 0 {
+		return style.Render(s)
+	}
+	hl := style.Foreground(colorYellow).Underline(true)
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+	var b strings.Builder
+	for i, r := range []rune(s) {
+		if marked[i] {
+			b.WriteString(hl.Render(string(r)))
+		} else {
+			b.WriteString(style.Render(string(r)))
+		}
+	}
+	return b.String()
+}
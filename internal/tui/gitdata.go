@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"maps"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// gitDataWorkers bounds the git processes a fetch runs at once.
+const gitDataWorkers = 8
+
+// gitDataStream carries the follow-up GitDataMsgs of one fetch. Only the
+// latest is kept: each holds every diff stat computed so far.
+type gitDataStream struct {
+	msgs chan tea.Msg
+}
+
+// send replaces an unread message with msg. Only the fetch's goroutine sends,
+// so there is always room after draining.
+func (s *gitDataStream) send(msg tea.Msg) {
+	select {
+	case s.msgs <- msg:
+		return
+	default:
+	}
+	select {
+	case <-s.msgs:
+	default:
+	}
+	s.msgs <- msg
+}
+
+func (s *gitDataStream) next() tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-s.msgs
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// fetchGitDataCmd lists the worktrees of every repository, a few at a time,
+// and returns them straight away without diff stats. The stats are then
+// computed in the background, or taken from cache, and streamed as follow-up
+// GitDataMsgs.
+func fetchGitDataCmd(cfg model.Config, runner git.CommandRunner, cache *git.StatCache) tea.Cmd {
+	return func() tea.Msg {
+		groups := make([]model.RepoGroup, len(cfg.Repositories))
+		errs := make([]error, len(cfg.Repositories))
+		parallel(len(cfg.Repositories), gitDataWorkers, func(i int) {
+			groups[i], errs[i] = listRepoGroup(runner, cfg.Repositories[i])
+		})
+		for _, err := range errs {
+			if err != nil {
+				return GitDataErrMsg{Err: err}
+			}
+		}
+
+		var worktrees []model.WorktreeInfo
+		var baseRefs []string
+		for i, g := range groups {
+			worktrees = append(worktrees, g.Worktrees...)
+			baseRef := config.BaseRef(cfg, cfg.Repositories[i].Path)
+			for range g.Worktrees {
+				baseRefs = append(baseRefs, baseRef)
+			}
+		}
+		if len(worktrees) == 0 {
+			return GitDataMsg{Groups: groups}
+		}
+
+		stream := &gitDataStream{msgs: make(chan tea.Msg, 1)}
+		go streamDiffStats(runner, cache, baseRefs, worktrees, stream)
+		return GitDataMsg{Groups: groups, Partial: true, stream: stream}
+	}
+}
+
+func listRepoGroup(runner git.CommandRunner, repoDef model.RepositoryDef) (model.RepoGroup, error) {
+	entries, err := git.ListWorktrees(runner, repoDef.Path)
+	if err != nil {
+		return model.RepoGroup{}, err
+	}
+
+	worktrees := git.ToWorktreeInfo(entries)
+	descriptions := git.GetBranchDescriptions(runner, repoDef.Path)
+	for i := range worktrees {
+		worktrees[i].Description = descriptions[worktrees[i].Branch]
+		worktrees[i].CreatedAt = git.WorktreeCreatedAt(worktrees[i].Path)
+	}
+	return model.RepoGroup{Name: repoDef.Name, RootPath: repoDef.Path, Worktrees: worktrees}, nil
+}
+
+type diffStatResult struct {
+	path   string
+	status model.StatusInfo
+	err    error
+}
+
+// streamDiffStats computes the diff stat of each worktree and sends the stats
+// so far, keyed by path, on stream as each one comes in. The last message is
+// not Partial; a failure ends the stream with a GitDataErrMsg.
+func streamDiffStats(runner git.CommandRunner, cache *git.StatCache, baseRefs []string, worktrees []model.WorktreeInfo, stream *gitDataStream) {
+	defer close(stream.msgs)
+
+	results := make(chan diffStatResult)
+	go func() {
+		parallel(len(worktrees), gitDataWorkers, func(i int) {
+			wt := worktrees[i]
+			status, err := cache.DiffStat(runner, wt.Path, wt.Head, baseRefs[i])
+			results <- diffStatResult{path: wt.Path, status: status, err: err}
+		})
+		close(results)
+	}()
+
+	stats := make(map[string]model.StatusInfo, len(worktrees))
+	var firstErr error
+	for r := range results {
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		stats[r.path] = r.status
+		if firstErr == nil {
+			stream.send(GitDataMsg{Partial: len(stats) < len(worktrees), stream: stream, update: true, stats: maps.Clone(stats)})
+		}
+	}
+	if firstErr != nil {
+		stream.send(GitDataErrMsg{Err: firstErr})
+	}
+}
+
+// parallel calls fn with 0..n-1, running at most limit calls at once, and
+// returns when all have finished.
+func parallel(n, limit int, fn func(i int)) {
+	sem := make(chan struct{}, max(limit, 1))
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
+
+// cloneGroups copies groups deeply enough that filling in stats on the copy
+// leaves the original alone, as earlier models may still hold it.
+func cloneGroups(groups []model.RepoGroup) []model.RepoGroup {
+	out := make([]model.RepoGroup, len(groups))
+	for i, g := range groups {
+		g.Worktrees = append([]model.WorktreeInfo(nil), g.Worktrees...)
+		out[i] = g
+	}
+	return out
+}
+
+// applyDiffStats fills in the stats of a follow-up of the fetch the sidebar
+// is showing. Worktrees are matched by path, so a repository refreshed in the
+// meantime keeps its rows, and the cursor stays on its row even when the diff
+// sort moves it.
+func (m Model) applyDiffStats(msg GitDataMsg) (Model, tea.Cmd) {
+	if msg.stream != m.gitData {
+		return m, nil
+	}
+	m.groups = cloneGroups(m.groups)
+	for _, g := range m.groups {
+		for i, wt := range g.Worktrees {
+			if status, ok := msg.stats[wt.Path]; ok {
+				g.Worktrees[i].Status = status
+			}
+		}
+	}
+	m = rebuildItems(m)
+	if !msg.Partial {
+		return m, nil
+	}
+	return m, msg.stream.next()
+}
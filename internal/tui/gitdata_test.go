@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/sidebar"
+)
+
+func twoRepoRunner() git.FakeCommandRunner {
+	outputs := map[string]string{
+		"/a:[worktree list --porcelain]": "worktree /a\nHEAD abc\nbranch refs/heads/main\n\nworktree /a-small\nHEAD abd\nbranch refs/heads/small\n\nworktree /a-big\nHEAD abe\nbranch refs/heads/big\n\n",
+		"/b:[worktree list --porcelain]": "worktree /b\nHEAD bcd\nbranch refs/heads/main\n\n",
+	}
+	for path, numstat := range map[string]string{"/a": "", "/a-small": "1\t0\tx.go\n", "/a-big": "90\t10\tx.go\n", "/b": ""} {
+		outputs[path+":[diff origin/main...HEAD --numstat]"] = numstat
+		outputs[path+":[rev-list --left-right --count origin/main...HEAD]"] = "0\t0\n"
+	}
+	return git.FakeCommandRunner{Outputs: outputs}
+}
+
+var twoRepoConfig = model.Config{
+	DefaultBaseRef: "origin/main",
+	Repositories:   []model.RepositoryDef{{Name: "a", Path: "/a", Sort: "diff"}, {Name: "b", Path: "/b"}},
+}
+
+func TestFetchGitDataCmd_StreamsDiffStats(t *testing.T) {
+	msg, ok := fetchGitDataCmd(twoRepoConfig, twoRepoRunner(), nil)().(GitDataMsg)
+	if !ok {
+		t.Fatalf("expected GitDataMsg, got %T", msg)
+	}
+	if !msg.Partial || len(msg.Groups) != 2 || msg.Groups[0].Name != "a" || msg.Groups[1].Name != "b" {
+		t.Fatalf("first message = %+v, want both repositories in config order, stats pending", msg)
+	}
+	if msg.Groups[0].Worktrees[2].Status.Insertions != 0 {
+		t.Error("the first message should not wait for diff stats")
+	}
+
+	var last GitDataMsg
+	for next := msg.stream.next(); ; {
+		update, ok := next().(GitDataMsg)
+		if !ok {
+			break
+		}
+		last = update
+		if !update.Partial {
+			break
+		}
+	}
+	if last.Partial || len(last.stats) != 4 || last.stats["/a-big"].Insertions != 90 || last.stats["/a-small"].Insertions != 1 {
+		t.Errorf("last update = %+v, want every diff stat", last)
+	}
+}
+
+func TestFetchGitDataCmd_RepoBaseRef(t *testing.T) {
+	runner := twoRepoRunner()
+	runner.Outputs["/b:[diff origin/develop...HEAD --numstat]"] = "5\t1\tx.go\n"
+	runner.Outputs["/b:[rev-list --left-right --count origin/develop...HEAD]"] = "0\t2\n"
+	cfg := twoRepoConfig
+	cfg.Repositories = []model.RepositoryDef{cfg.Repositories[0], {Name: "b", Path: "/b", BaseRef: "origin/develop"}}
+
+	msg := fetchGitDataCmd(cfg, runner, nil)().(GitDataMsg)
+	var last GitDataMsg
+	for next := msg.stream.next(); ; {
+		got := next()
+		update, ok := got.(GitDataMsg)
+		if !ok {
+			t.Fatalf("stream ended with %T", got)
+		}
+		last = update
+		if !update.Partial {
+			break
+		}
+	}
+	if got := last.stats["/b"]; got.Insertions != 5 || got.Ahead != 2 {
+		t.Errorf("stat of /b = %+v, want it against its own origin/develop", got)
+	}
+	if got := last.stats["/a-big"]; got.Insertions != 90 {
+		t.Errorf("stat of /a-big = %+v, want it against origin/main", got)
+	}
+}
+
+func TestFetchGitDataCmd_DiffStatErrorEndsStream(t *testing.T) {
+	runner := twoRepoRunner()
+	delete(runner.Outputs, "/a-big:[diff origin/main...HEAD --numstat]")
+
+	msg := fetchGitDataCmd(twoRepoConfig, runner, nil)().(GitDataMsg)
+	var got tea.Msg
+	for next := msg.stream.next(); ; {
+		got = next()
+		if update, ok := got.(GitDataMsg); !ok || !update.Partial {
+			break
+		}
+	}
+	if _, ok := got.(GitDataErrMsg); !ok {
+		t.Errorf("stream ended with %T, want GitDataErrMsg", got)
+	}
+}
+
+func TestApplyDiffStats_KeepsCursorAndIgnoresStaleFetches(t *testing.T) {
+	m := Model{config: twoRepoConfig, sidebarWidth: 30, sortModes: map[string]sidebar.SortMode{}}
+	first := fetchGitDataCmd(twoRepoConfig, twoRepoRunner(), nil)().(GitDataMsg)
+	result, _ := m.Update(first)
+	m = result.(Model)
+	for i, item := range m.items {
+		if item.WorktreePath == "/a-small" {
+			m.cursor = i
+		}
+	}
+
+	var final GitDataMsg
+	for next := first.stream.next(); ; {
+		update := next().(GitDataMsg)
+		final = update
+		if !update.Partial {
+			break
+		}
+	}
+	result, _ = m.Update(final)
+	m = result.(Model)
+	if m.items[m.cursor].WorktreePath != "/a-small" {
+		t.Errorf("cursor moved to %q, want it kept on /a-small as the diff sort reorders", m.items[m.cursor].WorktreePath)
+	}
+
+	stale := GitDataMsg{stream: &gitDataStream{}, update: true, stats: map[string]model.StatusInfo{"/a-big": {}}}
+	result, _ = m.Update(stale)
+	if result.(Model).groups[0].Worktrees[2].Status.Insertions != 90 {
+		t.Error("stats from an earlier fetch should be ignored")
+	}
+}
+
+func TestParallel_BoundsConcurrency(t *testing.T) {
+	var running, peak, calls atomic.Int32
+	parallel(20, 3, func(int) {
+		calls.Add(1)
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+	})
+	if calls.Load() != 20 || peak.Load() > 3 {
+		t.Errorf("calls = %d, peak = %d, want 20 calls at most 3 at once", calls.Load(), peak.Load())
+	}
+}
+
+func TestFetchGitDataCmd_NoRepositories(t *testing.T) {
+	msg, ok := fetchGitDataCmd(model.Config{}, git.FakeCommandRunner{}, nil)().(GitDataMsg)
+	if !ok || msg.Partial || msg.stream != nil {
+		t.Errorf("got %+v, want a complete empty GitDataMsg", msg)
+	}
+}
+
+func TestFetchGitDataCmd_UsesCachedDiffStats(t *testing.T) {
+	runner := twoRepoRunner()
+	delete(runner.Outputs, "/a-big:[diff origin/main...HEAD --numstat]")
+	cache := git.NewStatCache(time.Minute, map[string]git.StatCacheEntry{
+		"/a-big": {Head: "abe", BaseRef: "origin/main", Status: model.StatusInfo{Insertions: 7}, At: time.Now()},
+	})
+
+	msg := fetchGitDataCmd(twoRepoConfig, runner, cache)().(GitDataMsg)
+	var got tea.Msg
+	for next := msg.stream.next(); ; {
+		got = next()
+		if update, ok := got.(GitDataMsg); !ok || !update.Partial {
+			break
+		}
+	}
+	if update, ok := got.(GitDataMsg); !ok || update.stats["/a-big"].Insertions != 7 {
+		t.Errorf("stream ended with %+v, want the cached stat of /a-big", got)
+	}
+}
@@ -0,0 +1,193 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/search"
+)
+
+// grepMatchLimit caps the matches kept per worktree in the results view.
+const grepMatchLimit = 50
+
+// GrepResultMsg carries the matches of a cross-worktree search.
+type GrepResultMsg struct {
+	Pattern string
+	Results []search.Result
+}
+
+func grepCmd(runner git.CommandRunner, groups []model.RepoGroup, pattern string) tea.Cmd {
+	return func() tea.Msg {
+		return GrepResultMsg{
+			Pattern: pattern,
+			Results: search.Run(runner, search.TargetsFromGroups(groups), pattern, grepMatchLimit),
+		}
+	}
+}
+
+// grepHit locates one match inside grepResults.
+type grepHit struct {
+	result int
+	match  int
+}
+
+// grepHits flattens the results into the list the cursor moves over.
+func grepHits(results []search.Result) []grepHit {
+	var hits []grepHit
+	for i, r := range results {
+		for j := range r.Matches {
+			hits = append(hits, grepHit{result: i, match: j})
+		}
+	}
+	return hits
+}
+
+func (m Model) updateGrepInputMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEscape:
+			m.grepping = false
+			m.textInput.SetValue("")
+			return m, nil
+		case tea.KeyEnter:
+			pattern := m.textInput.Value()
+			m.textInput.SetValue("")
+			m.grepping = false
+			if strings.TrimSpace(pattern) == "" {
+				return m, nil
+			}
+			m.showingGrep = true
+			m.grepLoading = true
+			m.grepPattern = pattern
+			m.grepResults = nil
+			m.grepCursor = 0
+			return m, grepCmd(m.runner, m.groups, pattern)
+		case tea.KeyCtrlC:
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) updateGrepResultsMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case GrepResultMsg:
+		if msg.Pattern == m.grepPattern {
+			m.grepResults = msg.Results
+			m.grepLoading = false
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		hits := grepHits(m.grepResults)
+		switch {
+		case key.Matches(msg, grepKeys.Close):
+			m.showingGrep = false
+			m.grepResults = nil
+			return m, nil
+		case key.Matches(msg, globalKeys.ForceQuit):
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, grepKeys.Down):
+			if m.grepCursor < len(hits)-1 {
+				m.grepCursor++
+			}
+		case key.Matches(msg, grepKeys.Up):
+			if m.grepCursor > 0 {
+				m.grepCursor--
+			}
+		case key.Matches(msg, grepKeys.Open):
+			if m.grepCursor >= len(hits) {
+				return m, nil
+			}
+			hit := hits[m.grepCursor]
+			r := m.grepResults[hit.result]
+			match := r.Matches[hit.match]
+			m.selected = r.Path
+			m.selectedRepoPath = r.RepoPath
+			m.selectedFile = fmt.Sprintf("%s:%d", filepath.Join(r.Path, match.File), match.Line)
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func renderGrepInputView(m Model) string {
+	return modalLayout{
+		title:  "Search Worktrees",
+		prompt: "Pattern to search in every worktree:",
+		input:  m.textInput.View(),
+		help:   "enter: search  esc: cancel",
+	}.render(m.width, m.height)
+}
+
+func renderGrepResultsView(m Model) string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Search: " + m.grepPattern))
+	b.WriteString("\n")
+
+	if m.grepLoading {
+		b.WriteString("  Searching...\n")
+		b.WriteString(helpStyle.Render(keyhelp.ShortHelp(grepKeys.Close)))
+		return b.String()
+	}
+
+	matchStyle := lipgloss.NewStyle().Foreground(colorFg)
+	locStyle := lipgloss.NewStyle().Foreground(colorFgDim)
+	clip := lipgloss.NewStyle().MaxWidth(max(m.sidebarWidth, 20))
+
+	var lines []string
+	cursorLine := 0
+	hit := 0
+	for _, r := range m.grepResults {
+		lines = append(lines, groupHeaderStyle.Render(fmt.Sprintf("%s %s", r.Repo, r.Branch)))
+		if r.Err != nil {
+			lines = append(lines, errorStyle.Render("  "+r.Err.Error()))
+			continue
+		}
+		for _, match := range r.Matches {
+			line := locStyle.Render(fmt.Sprintf("%s:%d ", match.File, match.Line)) + matchStyle.Render(strings.TrimSpace(match.Text))
+			if hit == m.grepCursor {
+				cursorLine = len(lines)
+				line = worktreeSelectedStyle.Render("> ") + line
+			} else {
+				line = "   " + line
+			}
+			lines = append(lines, line)
+			hit++
+		}
+		if r.Truncated {
+			lines = append(lines, locStyle.Render(fmt.Sprintf("   … first %d matches shown", grepMatchLimit)))
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "  No matches")
+	}
+
+	start := 0
+	end := len(lines)
+	if vp := viewportHeight(m.height); vp > 0 && end > vp {
+		start = max(cursorLine-vp+1, 0)
+		end = start + vp
+	}
+	for _, l := range lines[start:end] {
+		b.WriteString(clip.Render(l))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpStyle.Render(keyhelp.ShortHelp(keyhelp.Bindings(grepKeys)...)))
+	return b.String()
+}
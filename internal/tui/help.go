@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+)
+
+// openHelp shows the ? overlay listing the keys of every mode.
+func (m Model) openHelp() Model {
+	m.showingHelp = true
+	m.helpScroll = 0
+	return m
+}
+
+func (m Model) updateHelpMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, globalKeys.ForceQuit):
+		m.quitting = true
+		return m, tea.Quit
+	case key.Matches(msg, helpKeys.Close):
+		m.showingHelp = false
+	case key.Matches(msg, helpKeys.Down):
+		m.helpScroll = min(m.helpScroll+1, max(len(helpLines())-1, 0))
+	case key.Matches(msg, helpKeys.Up):
+		m.helpScroll = max(m.helpScroll-1, 0)
+	}
+	return m, nil
+}
+
+func helpLines() []string {
+	sectionStyle := lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
+	keyStyle := lipgloss.NewStyle().Bold(true)
+	return keyhelp.Lines(keySections(), sectionStyle, keyStyle)
+}
+
+func renderHelpView(m Model) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Keybindings"))
+	b.WriteString("\n")
+
+	lines := helpLines()
+	start := min(m.helpScroll, max(len(lines)-1, 0))
+	end := len(lines)
+	if vp := viewportHeight(m.height); vp > 0 && start+vp < end {
+		end = start + vp
+	}
+	clip := lipgloss.NewStyle().MaxWidth(max(m.sidebarWidth, 20))
+	for _, l := range lines[start:end] {
+		b.WriteString(clip.Render(l))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpStyle.Render(keyhelp.ShortHelp(helpKeys.Up, helpKeys.Down, helpKeys.Close)))
+	return b.String()
+}
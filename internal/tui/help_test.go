@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestHelpOverlay(t *testing.T) {
+	m, _ := pressKeys(t, testModel(), runeKey("?"))
+	if !m.showingHelp {
+		t.Fatal("? should open the keybinding help")
+	}
+	if view := m.View(); !strings.Contains(view, "Keybindings") || !strings.Contains(view, "enter/click  select") {
+		t.Errorf("help view =\n%s", view)
+	}
+	all := strings.Join(helpLines(), "\n")
+	for _, want := range []string{"Select mode (V)", "Rebase (R)", "move commit down", "esc/q/?"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("help should list %q", want)
+		}
+	}
+
+	m, _ = pressKeys(t, m, runeKey("d"))
+	if !m.showingHelp || m.confirmingArchive {
+		t.Error("keys other than the help's own should be ignored while it is open")
+	}
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEscape})
+	if m.showingHelp {
+		t.Error("esc should close the help")
+	}
+}
+
+func TestHelpOverlay_Scrolls(t *testing.T) {
+	m := testModel()
+	m.height = 10
+	m, _ = pressKeys(t, m, runeKey("?"), runeKey("j"), runeKey("j"))
+	if m.helpScroll != 2 {
+		t.Fatalf("helpScroll = %d, want 2", m.helpScroll)
+	}
+	if strings.Contains(m.View(), "Sidebar") {
+		t.Error("the first lines should scroll out of view")
+	}
+}
+
+func TestKeySections_EveryModeHasKeys(t *testing.T) {
+	for _, s := range keySections() {
+		if len(s.Keys) == 0 {
+			t.Errorf("section %q lists no keys", s.Title)
+		}
+		for _, b := range s.Keys {
+			if b.Help().Key == "" || b.Help().Desc == "" {
+				t.Errorf("section %q: binding %v has no help", s.Title, b.Keys())
+			}
+		}
+	}
+}
+
+func TestKeymaps_Rebind(t *testing.T) {
+	saved := sidebarKeys
+	t.Cleanup(func() { sidebarKeys = saved })
+
+	err := keyhelp.Rebind(Keymaps(), map[string]map[string]model.KeyList{
+		"sidebar": {"down": {"down", "n"}, "archive": {"x"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := testModel()
+	m.cursor = 0
+	m, _ = pressKeys(t, m, runeKey("j"))
+	if m.cursor != 0 {
+		t.Error("j should no longer move down")
+	}
+	m, _ = pressKeys(t, m, runeKey("n"))
+	if m.cursor == 0 {
+		t.Error("n should move down")
+	}
+	m, _ = pressKeys(t, m, runeKey("x"))
+	if !m.confirmingArchive {
+		t.Error("x should archive")
+	}
+	if view := testModel().View(); !strings.Contains(view, "↓/n: move") || !strings.Contains(view, "x: archive") {
+		t.Errorf("the help line should show the new keys:\n%s", view)
+	}
+}
+
+func TestWorkspacesHelp_EssentialKeysOnly(t *testing.T) {
+	help := workspacesHelp()
+	if want := "q: quit  ↑/k ↓/j: move  enter/click: select  d: archive  ?: help"; help != want {
+		t.Errorf("help line = %q, want %q", help, want)
+	}
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// Hinter gives a line of guidance to show above the worktree list, such as
+// the next step of `yakumo tutorial`. An empty hint shows nothing.
+type Hinter interface {
+	Hint(groups []model.RepoGroup) string
+}
+
+// WithHinter returns a copy of the model that shows the hints of h, worked
+// out again whenever the worktrees are reloaded.
+func (m Model) WithHinter(h Hinter) Model {
+	m.hinter = h
+	return m
+}
+
+// renderHint returns the hint wrapped to the sidebar, or "" without one.
+func (m Model) renderHint() string {
+	if m.hinter == nil {
+		return ""
+	}
+	hint := m.hinter.Hint(m.groups)
+	if hint == "" {
+		return ""
+	}
+	return hintStyle.Width(max(m.sidebarWidth, 20)).Render(hint)
+}
+
+// listHeight is viewportHeight less the rows taken by the agent summary, the
+// hint, the update notice and, when the items do not fit, the scroll indicators.
+func (m Model) listHeight() int {
+	rows, _ := m.listLayout()
+	return rows
+}
+
+// listLayout returns the rows left for items and whether the list overflows
+// them, in which case a scroll indicator row goes above and below the items.
+func (m Model) listLayout() (rows int, overflows bool) {
+	if m.height <= 0 {
+		return 0, false
+	}
+	rows = viewportHeight(m.height)
+	if summary := m.renderAgentSummary(); summary != "" {
+		rows = max(rows-lipgloss.Height(summary), 1)
+	}
+	if hint := m.renderHint(); hint != "" {
+		rows = max(rows-lipgloss.Height(hint), 1)
+	}
+	if update := m.renderUpdate(); update != "" {
+		rows = max(rows-lipgloss.Height(update), 1)
+	}
+	total := 0
+	for _, h := range itemHeights(m.items, m.cursor, m.sidebarWidth) {
+		total += h
+	}
+	if total <= rows {
+		return rows, false
+	}
+	return max(rows-2, 1), true
+}
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+type worktreeCountHinter struct{}
+
+func (worktreeCountHinter) Hint(groups []model.RepoGroup) string {
+	if len(groups) > 0 && len(groups[0].Worktrees) > 1 {
+		return "Press enter to launch it"
+	}
+	return ""
+}
+
+func TestView_ShowsHintAndShrinksList(t *testing.T) {
+	m := testModel()
+	m.height = 20
+	without := m.listHeight()
+
+	m = m.WithHinter(worktreeCountHinter{})
+	if !strings.Contains(m.View(), "Press enter to launch it") {
+		t.Error("view should show the hint")
+	}
+	if got := m.listHeight(); got != without-1 {
+		t.Errorf("listHeight = %d, want %d with a one-row hint", got, without-1)
+	}
+
+	m.groups = m.groups[:0]
+	if got := m.listHeight(); got != without {
+		t.Errorf("listHeight = %d, want %d without a hint", got, without)
+	}
+}
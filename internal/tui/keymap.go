@@ -0,0 +1,417 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+)
+
+// The key bindings of each mode. Update matches keys against these and the
+// ? overlay lists them, so the overlay always shows the keys that work.
+// Prompts that edit text (rename, describe, send prompt, add worktree...) keep their own
+// enter/esc handling and help line.
+
+func newKey(help, desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(help, desc))
+}
+
+var (
+	keyUp   = newKey("↑/k", "up", "up", "k")
+	keyDown = newKey("↓/j", "down", "down", "j")
+)
+
+var globalKeys = struct {
+	ForceQuit key.Binding
+}{
+	ForceQuit: newKey("ctrl+c", "quit from anywhere", "ctrl+c"),
+}
+
+var sidebarKeys = struct {
+	Quit       key.Binding
+	Up         key.Binding
+	Down       key.Binding
+	Open       key.Binding
+	Archive    key.Binding
+	Cleanup    key.Binding
+	Archived   key.Binding
+	Prune      key.Binding
+	RemoveRepo key.Binding
+	QuickDiff  key.Binding
+	Rename     key.Binding
+	Rebase     key.Binding
+	Describe   key.Binding
+	Search     key.Binding
+	Filter     key.Binding
+	Sort       key.Binding
+	GroupBy    key.Binding
+	Fold       key.Binding
+	Select     key.Binding
+	Pin        key.Binding
+	DevLog     key.Binding
+	Errors     key.Binding
+	Activity   key.Binding
+	AgentPane  key.Binding
+	Prompt     key.Binding
+	Waiting    key.Binding
+	Panes      key.Binding
+	Tasks      key.Binding
+	Restore    key.Binding
+	Refresh    key.Binding
+	Help       key.Binding
+	// debugLog opens debug.log. Being for bug hunts, it is left out of the
+	// help line.
+	debugLog key.Binding
+}{
+	Quit:       newKey("q", "quit", "q"),
+	Up:         keyUp,
+	Down:       keyDown,
+	Open:       newKey("enter/click", "select", "enter"),
+	Archive:    newKey("d", "archive", "d"),
+	Cleanup:    newKey("C", "clean up", "C"),
+	Archived:   newKey("u", "archived", "u"),
+	Prune:      newKey("P", "prune", "P"),
+	RemoveRepo: newKey("D", "remove repo", "D"),
+	QuickDiff:  newKey("v", "diff", "v"),
+	Rename:     newKey("r", "rename", "r"),
+	Rebase:     newKey("R", "rebase", "R"),
+	Describe:   newKey("E", "describe", "E"),
+	Search:     newKey("F", "search", "F"),
+	Filter:     newKey("/", "filter", "/"),
+	Sort:       newKey("s", "sort", "s"),
+	GroupBy:    newKey("b", "group by repo / branch prefix", "b"),
+	Fold:       newKey("space", "fold", " "),
+	Select:     newKey("V", "select", "V"),
+	Pin:        newKey("p", "pin", "p"),
+	DevLog:     newKey("L", "dev log", "L"),
+	Errors:     newKey("e", "errors", "e"),
+	Activity:   newKey("a", "agent activity", "a"),
+	AgentPane:  newKey("A", "jump to agent", "A"),
+	Prompt:     newKey("i", "send prompt", "i"),
+	Waiting:    newKey("w", "next waiting agent", "w"),
+	Panes:      newKey("T", "panes", "T"),
+	Tasks:      newKey("t", "tasks", "t"),
+	Restore:    newKey("O", "restore sessions", "O"),
+	Refresh:    newKey("ctrl+r", "refresh", "ctrl+r"),
+	Help:       newKey("?", "help", "?"),
+	debugLog:   newKey("ctrl+l", "debug log", "ctrl+l"),
+}
+
+var removeRepoKeys = struct {
+	Archive key.Binding
+	Confirm key.Binding
+	Cancel  key.Binding
+}{
+	Archive: newKey("a", "archive worktrees too", "a"),
+	Confirm: newKey("enter", "remove", "enter"),
+	Cancel:  newKey("esc", "cancel", "esc"),
+}
+
+var selectKeys = struct {
+	Mark    key.Binding
+	Archive key.Binding
+	Fetch   key.Binding
+	OpenAll key.Binding
+	Done    key.Binding
+}{
+	Mark:    newKey("space", "mark", " "),
+	Archive: newKey("d", "archive", "d"),
+	Fetch:   newKey("f", "fetch", "f"),
+	OpenAll: newKey("enter", "open all", "enter"),
+	Done:    newKey("esc", "done", "esc", "V"),
+}
+
+var filterKeys = struct {
+	Up     key.Binding
+	Down   key.Binding
+	Open   key.Binding
+	Cancel key.Binding
+}{
+	Up:     newKey("↑/ctrl+p", "up", "up", "ctrl+p"),
+	Down:   newKey("↓/ctrl+n", "down", "down", "ctrl+n"),
+	Open:   newKey("enter", "select", "enter"),
+	Cancel: newKey("esc", "clear the filter", "esc"),
+}
+
+var quickDiffKeys = struct {
+	Up    key.Binding
+	Down  key.Binding
+	Close key.Binding
+}{
+	Up:    keyUp,
+	Down:  keyDown,
+	Close: newKey("esc/q/v", "close", "esc", "q", "v"),
+}
+
+var devLogKeys = struct {
+	Up     key.Binding
+	Down   key.Binding
+	Top    key.Binding
+	Bottom key.Binding
+	Follow key.Binding
+	Search key.Binding
+	Next   key.Binding
+	Prev   key.Binding
+	Close  key.Binding
+}{
+	Up:     keyUp,
+	Down:   keyDown,
+	Top:    newKey("g", "top", "g"),
+	Bottom: newKey("G", "bottom", "G"),
+	Follow: newKey("f", "follow", "f"),
+	Search: newKey("/", "search", "/"),
+	Next:   newKey("n", "next match", "n"),
+	Prev:   newKey("N", "previous match", "N"),
+	Close:  newKey("esc/q/L", "close", "esc", "q", "L"),
+}
+
+var debugLogKeys = struct {
+	Up     key.Binding
+	Down   key.Binding
+	Top    key.Binding
+	Bottom key.Binding
+	Next   key.Binding
+	Prev   key.Binding
+	Reload key.Binding
+	Close  key.Binding
+}{
+	Up:     keyUp,
+	Down:   keyDown,
+	Top:    newKey("g", "top", "g"),
+	Bottom: newKey("G", "bottom", "G"),
+	Next:   newKey("tab", "next subsystem", "tab"),
+	Prev:   newKey("shift+tab", "previous subsystem", "shift+tab"),
+	Reload: newKey("r", "reload", "r"),
+	Close:  newKey("esc/q/ctrl+l", "close", "esc", "q", "ctrl+l"),
+}
+
+var grepKeys = struct {
+	Up    key.Binding
+	Down  key.Binding
+	Open  key.Binding
+	Close key.Binding
+}{
+	Up:    keyUp,
+	Down:  keyDown,
+	Open:  newKey("enter", "open in zed", "enter"),
+	Close: newKey("esc/q", "close", "esc", "q"),
+}
+
+var cleanupKeys = struct {
+	Up      key.Binding
+	Down    key.Binding
+	Toggle  key.Binding
+	All     key.Binding
+	Archive key.Binding
+	Close   key.Binding
+}{
+	Up:      keyUp,
+	Down:    keyDown,
+	Toggle:  newKey("space/x", "toggle", " ", "x"),
+	All:     newKey("a", "toggle all", "a"),
+	Archive: newKey("enter", "archive selected", "enter"),
+	Close:   newKey("esc/q", "close", "esc", "q"),
+}
+
+var archivedKeys = struct {
+	Up      key.Binding
+	Down    key.Binding
+	Restore key.Binding
+	Purge   key.Binding
+	Confirm key.Binding
+	Close   key.Binding
+}{
+	Up:      keyUp,
+	Down:    keyDown,
+	Restore: newKey("enter", "restore", "enter"),
+	Purge:   newKey("x", "delete for good", "x"),
+	Confirm: newKey("y", "confirm deleting", "y"),
+	Close:   newKey("esc/q", "close", "esc", "q"),
+}
+
+var templateKeys = struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Close  key.Binding
+}{
+	Up:     keyUp,
+	Down:   keyDown,
+	Select: newKey("enter", "use template", "enter"),
+	Close:  newKey("esc/q", "cancel", "esc", "q"),
+}
+
+var pruneKeys = struct {
+	Close key.Binding
+}{
+	Close: newKey("esc/q/enter", "close", "esc", "q", "enter"),
+}
+
+var restoreKeys = struct {
+	Close key.Binding
+}{
+	Close: newKey("esc/q/enter", "close", "esc", "q", "enter"),
+}
+
+var rebaseKeys = struct {
+	Up       key.Binding
+	Down     key.Binding
+	MoveDown key.Binding
+	MoveUp   key.Binding
+	Pick     key.Binding
+	Squash   key.Binding
+	Fixup    key.Binding
+	Drop     key.Binding
+	Reword   key.Binding
+	Run      key.Binding
+	Close    key.Binding
+}{
+	Up:       keyUp,
+	Down:     keyDown,
+	MoveDown: newKey("J", "move commit down", "J"),
+	MoveUp:   newKey("K", "move commit up", "K"),
+	Pick:     newKey("p", "pick", "p"),
+	Squash:   newKey("s", "squash", "s"),
+	Fixup:    newKey("f", "fixup", "f"),
+	Drop:     newKey("d", "drop", "d"),
+	Reword:   newKey("r", "reword", "r"),
+	Run:      newKey("enter", "run", "enter"),
+	Close:    newKey("esc/q", "close", "esc", "q"),
+}
+
+var rebaseConflictKeys = struct {
+	Continue key.Binding
+	Abort    key.Binding
+	Close    key.Binding
+}{
+	Continue: newKey("c", "continue", "c"),
+	Abort:    newKey("a", "abort", "a"),
+	Close:    newKey("esc/q", "close, leaving the rebase in progress", "esc", "q"),
+}
+
+var wipKeys = struct {
+	Restore key.Binding
+	Skip    key.Binding
+	Cancel  key.Binding
+}{
+	Restore: newKey("y/enter", "restore and switch", "y", "enter"),
+	Skip:    newKey("n", "switch without restoring", "n"),
+	Cancel:  newKey("esc", "cancel", "esc"),
+}
+
+var errorLogKeys = struct {
+	Up    key.Binding
+	Down  key.Binding
+	Clear key.Binding
+	Close key.Binding
+}{
+	Up:    keyUp,
+	Down:  keyDown,
+	Clear: newKey("x", "clear", "x"),
+	Close: newKey("esc/q/e", "close", "esc", "q", "e"),
+}
+
+var agentActivityKeys = struct {
+	Up    key.Binding
+	Down  key.Binding
+	Close key.Binding
+}{
+	Up:    keyUp,
+	Down:  keyDown,
+	Close: newKey("esc/q/a", "close", "esc", "q", "a"),
+}
+
+var panesKeys = struct {
+	Up      key.Binding
+	Down    key.Binding
+	Kill    key.Binding
+	Respawn key.Binding
+	Send    key.Binding
+	Close   key.Binding
+}{
+	Up:      keyUp,
+	Down:    keyDown,
+	Kill:    newKey("x", "kill", "x"),
+	Respawn: newKey("r", "respawn", "r"),
+	Send:    newKey("s", "send command", "s"),
+	Close:   newKey("esc/q/T", "close", "esc", "q", "T"),
+}
+
+var taskKeys = struct {
+	Up     key.Binding
+	Down   key.Binding
+	Run    key.Binding
+	RunAll key.Binding
+	Close  key.Binding
+}{
+	Up:     keyUp,
+	Down:   keyDown,
+	Run:    newKey("enter", "run", "enter"),
+	RunAll: newKey("a", "run all at once", "a"),
+	Close:  newKey("esc/q/t", "close", "esc", "q", "t"),
+}
+
+var helpKeys = struct {
+	Up    key.Binding
+	Down  key.Binding
+	Close key.Binding
+}{
+	Up:    keyUp,
+	Down:  keyDown,
+	Close: newKey("esc/q/?", "close", "esc", "q", "?"),
+}
+
+// Keymaps returns the keymap of each mode under its name in the
+// `keybindings` config, for keyhelp.Rebind.
+func Keymaps() map[string]any {
+	return map[string]any{
+		"global":          &globalKeys,
+		"sidebar":         &sidebarKeys,
+		"select":          &selectKeys,
+		"filter":          &filterKeys,
+		"quick_diff":      &quickDiffKeys,
+		"dev_log":         &devLogKeys,
+		"debug_log":       &debugLogKeys,
+		"search_results":  &grepKeys,
+		"template":        &templateKeys,
+		"cleanup":         &cleanupKeys,
+		"archived":        &archivedKeys,
+		"prune":           &pruneKeys,
+		"remove_repo":     &removeRepoKeys,
+		"restore":         &restoreKeys,
+		"rebase":          &rebaseKeys,
+		"rebase_conflict": &rebaseConflictKeys,
+		"wip":             &wipKeys,
+		"errors":          &errorLogKeys,
+		"agent_activity":  &agentActivityKeys,
+		"panes":           &panesKeys,
+		"tasks":           &taskKeys,
+		"help":            &helpKeys,
+	}
+}
+
+// keySections lists the bindings of every mode for the ? overlay.
+func keySections() []keyhelp.Section {
+	return []keyhelp.Section{
+		{Title: "Sidebar", Keys: keyhelp.Bindings(sidebarKeys)},
+		{Title: "Select mode (V)", Keys: keyhelp.Bindings(selectKeys)},
+		{Title: "Filter (/)", Keys: keyhelp.Bindings(filterKeys)},
+		{Title: "Quick diff (v)", Keys: keyhelp.Bindings(quickDiffKeys)},
+		{Title: "Dev log (L)", Keys: keyhelp.Bindings(devLogKeys)},
+		{Title: "Search results (F)", Keys: keyhelp.Bindings(grepKeys)},
+		{Title: "Worktree templates", Keys: keyhelp.Bindings(templateKeys)},
+		{Title: "Clean up (C)", Keys: keyhelp.Bindings(cleanupKeys)},
+		{Title: "Archived (u)", Keys: keyhelp.Bindings(archivedKeys)},
+		{Title: "Prune (P)", Keys: keyhelp.Bindings(pruneKeys)},
+		{Title: "Remove repository (D)", Keys: keyhelp.Bindings(removeRepoKeys)},
+		{Title: "Restore sessions (O)", Keys: keyhelp.Bindings(restoreKeys)},
+		{Title: "Rebase (R)", Keys: keyhelp.Bindings(rebaseKeys)},
+		{Title: "Rebase conflicts", Keys: keyhelp.Bindings(rebaseConflictKeys)},
+		{Title: "Restore work in progress", Keys: keyhelp.Bindings(wipKeys)},
+		{Title: "Recent errors (e)", Keys: keyhelp.Bindings(errorLogKeys)},
+		{Title: "Agent activity (a)", Keys: keyhelp.Bindings(agentActivityKeys)},
+		{Title: "Panes (T)", Keys: keyhelp.Bindings(panesKeys)},
+		{Title: "Tasks (t)", Keys: keyhelp.Bindings(taskKeys)},
+		{Title: "Help (?)", Keys: keyhelp.Bindings(helpKeys)},
+		{Title: "Everywhere", Keys: keyhelp.Bindings(globalKeys)},
+	}
+}
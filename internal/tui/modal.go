@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// minInputWidth keeps the text input usable in very narrow panes.
+const minInputWidth = 10
+
+// inputWidth returns the text input width that fits a pane of the given
+// width next to the two-space indent, the "> " prompt and the cursor.
+func inputWidth(paneWidth int) int {
+	return max(paneWidth-5, minInputWidth)
+}
+
+// modalLayout describes a modal view so it can be fitted to the terminal:
+// text wraps to the pane width, and when rows run short the spacing, then the
+// prompt and notes, then the tail of the error are dropped so the input and
+// the help line stay on screen. A zero width or height means the size is not
+// known yet and that dimension is left unconstrained.
+type modalLayout struct {
+	title  string
+	prompt string   // explanation above the input
+	input  string   // the text input, or the question being confirmed
+	notes  []string // extra lines under the input
+	err    error
+	help   string
+}
+
+func (l modalLayout) render(width, height int) string {
+	full := l.join(titleStyle.Render(l.title), l.prompt, l.notes, renderErrorBlock(l.err, width), helpStyle.Render(l.help), width, true)
+	if height <= 0 || lipgloss.Height(full) <= height {
+		return full
+	}
+
+	title := titleStyle.PaddingBottom(0).Render(l.title)
+	help := helpStyle.PaddingTop(0).Render(l.help)
+	errBlock := renderErrorBlock(l.err, width)
+	compact := l.join(title, l.prompt, l.notes, errBlock, help, width, false)
+	if lipgloss.Height(compact) <= height {
+		return compact
+	}
+
+	bare := l.join(title, "", nil, "", help, width, false)
+	if errBlock != "" {
+		errBlock = clipLines(errBlock, height-lipgloss.Height(bare))
+	}
+	return l.join(title, "", nil, errBlock, help, width, false)
+}
+
+func (l modalLayout) join(title, prompt string, notes []string, errBlock, help string, width int, spaced bool) string {
+	sep := "\n"
+	if spaced {
+		sep = "\n\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(title)
+	b.WriteString("\n")
+	if prompt != "" {
+		b.WriteString(indentLines(wrapText(prompt, width-2), "  "))
+		b.WriteString(sep)
+	}
+	b.WriteString(indentLines(wrapText(l.input, width-2), "  "))
+	for _, note := range notes {
+		b.WriteString("\n")
+		b.WriteString(indentLines(wrapText(note, width-2), "  "))
+	}
+	if errBlock != "" {
+		b.WriteString(sep)
+		b.WriteString(errBlock)
+	}
+	b.WriteString("\n")
+	b.WriteString(help)
+	return b.String()
+}
+
+// wrapText wraps s to width columns, breaking inside words (long paths) only
+// when needed. A non-positive width leaves s unchanged.
+func wrapText(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	return ansi.Wrap(s, width, "")
+}
+
+func indentLines(s, indent string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// clipLines keeps the first n lines of s, or none when n < 1.
+func clipLines(s string, n int) string {
+	if n < 1 {
+		return ""
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,32 +1,55 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	zone "github.com/lrstanley/bubblezone"
 
 	"github.com/mikanfactory/yakumo/internal/agent"
+	"github.com/mikanfactory/yakumo/internal/audit"
 	"github.com/mikanfactory/yakumo/internal/branchname"
 	"github.com/mikanfactory/yakumo/internal/claude"
+	"github.com/mikanfactory/yakumo/internal/clipboard"
 	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/forge"
 	"github.com/mikanfactory/yakumo/internal/git"
 	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/hooks"
+	"github.com/mikanfactory/yakumo/internal/logging"
 	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/notify"
 	"github.com/mikanfactory/yakumo/internal/pathcomplete"
+	"github.com/mikanfactory/yakumo/internal/ports"
+	"github.com/mikanfactory/yakumo/internal/prune"
+	"github.com/mikanfactory/yakumo/internal/restore"
+	"github.com/mikanfactory/yakumo/internal/search"
+	"github.com/mikanfactory/yakumo/internal/seed"
 	"github.com/mikanfactory/yakumo/internal/sidebar"
+	"github.com/mikanfactory/yakumo/internal/tasks"
 	"github.com/mikanfactory/yakumo/internal/tmux"
+	"github.com/mikanfactory/yakumo/internal/trash"
+	"github.com/mikanfactory/yakumo/internal/wip"
 )
 
 // GitDataMsg is sent when git data has been fetched.
 type GitDataMsg struct {
 	Groups []model.RepoGroup
+	// Partial is set while the diff stats of some worktrees are still being
+	// computed; more GitDataMsgs follow as they come in.
+	Partial bool
+	stream  *gitDataStream
+	update  bool                        // a follow-up of the same fetch with more diff stats
+	stats   map[string]model.StatusInfo // diff stats by worktree path, on follow-ups
 }
 
 // GitDataErrMsg is sent when git data fetching fails.
@@ -36,9 +59,15 @@ type GitDataErrMsg struct {
 
 // WorktreeAddedMsg is sent when a new worktree has been created.
 type WorktreeAddedMsg struct {
+	RepoPath     string
 	WorktreePath string
 	Branch       string
 	CreatedAt    int64 // Unix milliseconds
+	Issue        int   // issue number the branch was named after, 0 otherwise
+	Existing     bool  // an existing branch was checked out; it keeps its name
+	Named        bool  // the user typed the branch name; it keeps it
+	HookErr      error // the post_create hook failed; the worktree is kept
+	Template     string
 }
 
 // BranchRenameStartMsg indicates a first prompt was detected for a worktree.
@@ -55,6 +84,12 @@ type BranchRenameResultMsg struct {
 	Err          error
 }
 
+// ClipboardMsg carries the clipboard contents read when the add-worktree
+// prompt opens.
+type ClipboardMsg struct {
+	Text string
+}
+
 // WorktreeAddErrMsg is sent when worktree creation fails.
 type WorktreeAddErrMsg struct {
 	Err error
@@ -77,11 +112,19 @@ type AgentTickMsg time.Time
 // AgentStatusMsg delivers fetched agent status for all worktrees.
 type AgentStatusMsg struct {
 	Statuses map[string][]model.AgentInfo
+	// Activity is the last tmux activity of each worktree's session.
+	Activity map[string]time.Time
+	// Err is set when tmux is unavailable and polling should stop.
+	Err error
+	// manual marks a poll made on request while the tick was already
+	// scheduled; it must not schedule another.
+	manual bool
 }
 
 // PathSuggestionsMsg delivers directory completion candidates for the add-repo text input.
 type PathSuggestionsMsg struct {
 	Suggestions []string
+	Repos       []string // those of Suggestions that are git repositories
 	ForDir      string
 }
 
@@ -94,16 +137,16 @@ type RepoAddErrMsg struct {
 }
 
 // WorktreeArchivedMsg is sent when a worktree has been successfully archived.
-type WorktreeArchivedMsg struct{}
+type WorktreeArchivedMsg struct {
+	RepoPath string
+}
 
 // WorktreeArchiveErrMsg is sent when worktree archiving fails.
 type WorktreeArchiveErrMsg struct {
-	Err error
+	Err        error
+	HookFailed bool // the pre_archive hook failed, so the worktree can be archived without it
 }
 
-// agentPollInterval is how often we poll tmux for Claude Code agent status.
-const agentPollInterval = 500 * time.Millisecond
-
 // renameTimeoutMs is how long to wait for a prompt before giving up (10 minutes).
 const renameTimeoutMs = 10 * 60 * 1000
 
@@ -113,10 +156,12 @@ type Model struct {
 	groups                 []model.RepoGroup
 	cursor                 int
 	sidebarWidth           int
+	width                  int
 	height                 int
 	scrollOff              int
 	selected               string
 	selectedRepoPath       string
+	selectedPane           string
 	quitting               bool
 	err                    error
 	config                 model.Config
@@ -125,25 +170,203 @@ type Model struct {
 	addingRepo             bool
 	addingWorktree         bool
 	addingWorktreeRepoPath string
+	pickingTemplate        bool
+	templateCursor         int
+	addTemplate            string // name of the template picked for the worktree being added
+	templateStore          TemplateStore
+	branches               []git.Branch
+	branchCursor           int
 	textInput              textinput.Model
 	configPath             string
+	configChanges          <-chan struct{} // the config file changed; nil without hot reload
 	tmuxRunner             tmux.Runner
-	ghRunner               github.Runner
+	forgeOpts              forge.Options
 	agentStatus            map[string][]model.AgentInfo
 	branchRenames          map[string]model.BranchRenameInfo
 	claudeReader           claude.Reader
 	branchNameGen          branchname.Generator
 	lastSuggestionDir      string
+	pathCycle              []string // directories tab cycles through in the add-repo input, nil when not cycling
+	pathRepos              []string // the suggested directories that are git repositories
+	pathCursor             int
 	confirmingArchive      bool
 	archiveTarget          int
+	archiveHookFailed      bool // offer to archive archiveTarget without its failing pre_archive hook
+	archiveMarked          []CleanupCandidate
+	removingRepo           bool
+	removeRepoPath         string
+	removeRepoName         string
+	removeRepoArchive      bool // archive its worktrees along with it
+	selecting              bool
+	marked                 map[string]bool
 	agentTickRunning       bool
+	agentUnavailable       bool
+	agentPollInterval      time.Duration
+	agentProfiles          []agent.Profile
+	notifier               notify.Notifier
+	unfocused              bool // the terminal lost focus; agents are polled slower
+	gitRetries             int
+	todoStore              TodoStore
+	showingHelp            bool
+	helpScroll             int
+	showingQuickDiff       bool
+	quickDiffLoading       bool
+	quickDiffPath          string
+	quickDiffLabel         string
+	quickDiffScroll        int
+	quickDiff              QuickDiffMsg
+	quickDiffErr           error
+	editingDescription     bool
+	descriptionPath        string
+	descriptionBranch      string
+	descriptionOld         string
+	sendingPrompt          bool
+	promptPane             string
+	promptAgent            string
+	promptLabel            string
+	renamingWorktree       bool
+	renamePath             string
+	renameRepoPath         string
+	renameBranch           string
+	clipboard              clipboard.Reader
+	clipboardPrefilled     bool
+	grepping               bool
+	showingGrep            bool
+	grepLoading            bool
+	grepPattern            string
+	grepResults            []search.Result
+	grepCursor             int
+	selectedFile           string
+	filtering              bool
+	filterQuery            string
+	sortModes              map[string]sidebar.SortMode
+	groupByPrefix          bool
+	activity               map[string]time.Time
+	collapsed              map[string]bool
+	groupStore             GroupStore
+	pinned                 map[string]bool
+	pinStore               PinStore
+	recent                 []string
+	ports                  map[string]ports.Block
+	autoWIP                wip.Mode
+	wipTarget              Selection
+	wipChecking            bool
+	showingWIP             bool
+	wipRestoring           bool
+	wipSnapshot            wip.Snapshot
+	wipErr                 error
+	showingDevLog          bool
+	devLogLoading          bool
+	devLogPath             string
+	devLogLabel            string
+	devLogLines            []string
+	devLogErr              error
+	devLogScroll           int
+	devLogFollow           bool
+	devLogGen              int // of the running follow; see DevLogTickMsg
+	devLogSearching        bool
+	devLogQuery            string
+	debugLogPath           string
+	showingDebugLog        bool
+	debugLogLoading        bool
+	debugLogLines          []string
+	debugLogErr            error
+	debugLogScroll         int
+	debugLogFilter         string
+	prStatus               map[string]map[string]model.PRStatus
+	prFetchedAt            map[string]time.Time
+	prTickRunning          bool
+	baseChecks             map[string]model.CheckState
+	showingRebase          bool
+	rebaseLoading          bool
+	rebaseRunning          bool
+	rebaseRewording        bool
+	rebaseInProgress       bool
+	rebasePath             string
+	rebaseLabel            string
+	rebaseOnto             string
+	rebaseSteps            []git.RebaseStep
+	rebaseOriginal         []git.RebaseStep
+	rebaseCursor           int
+	rebaseConflicts        []string
+	rebaseErr              error
+	showingCleanup         bool
+	cleanupLoading         bool
+	cleanupArchiving       bool
+	cleanupCandidates      []CleanupCandidate
+	cleanupCursor          int
+	cleanupErr             error
+	trash                  trash.Trash
+	audit                  audit.Log
+	showingArchived        bool
+	archivedLoading        bool
+	archivedBusy           bool
+	archivedEntries        []trash.Entry
+	archivedCursor         int
+	archivedConfirmPurge   bool
+	archivedErr            error
+	showingPrune           bool
+	pruneRunning           bool
+	pruneReport            prune.Report
+	pruneErr               error
+	restoreSessions        SessionRestorer
+	showingRestore         bool
+	restoreRunning         bool
+	restoreReport          restore.Report
+	restoreErr             error
+	hinter                 Hinter
+	updateCheck            UpdateCheck
+	currentVersion         string
+	latestRelease          string
+	toast                  Toast
+	toastID                int
+	errorLog               []Toast
+	showingErrorLog        bool
+	showingAgentActivity   bool
+	agentActivityPath      string
+	agentActivityLabel     string
+	agentActivityScroll    int
+	showingPanes           bool
+	panesLoading           bool
+	panesSending           bool
+	panesPath              string
+	panesLabel             string
+	panesSession           string
+	panes                  []tmux.PaneStatus
+	panesCursor            int
+	panesErr               error
+	showingTasks           bool
+	taskPath               string
+	taskLabel              string
+	taskRows               []taskRow
+	taskCursor             int
+	taskChecks             map[string][]tasks.Check
+	taskUpdatedAt          map[string]time.Time
+	taskPanes              map[string]string
+	taskStarting           map[string]bool
+	taskErr                error
+	taskGen                int // of the running chain of reads; see TasksTickMsg
+	agentHistoryStore      AgentHistoryStore
+	agentHistory           map[string][]model.AgentEvent
+	errorLogScroll         int
+	spinner                spinner.Model
+	progressID             int
+	progressing            bool
+	progressStep           string
+	gitData                *gitDataStream
+	statCache              *git.StatCache
+}
+
+// TodoStore records todos for a worktree so the diff UI can list them.
+type TodoStore interface {
+	Add(worktreePath, todo string) error
 }
 
 // NewModel creates a new TUI model.
 // tmuxRunner may be nil when running outside tmux (agent polling is skipped).
-// ghRunner may be nil when gh CLI is not available (PR URL cloning is skipped).
+// forgeOpts runners may be nil when gh/glab are not available (PR URL cloning is skipped).
 // claudeReader and branchNameGen may be nil to disable LLM branch naming.
-func NewModel(cfg model.Config, runner git.CommandRunner, configPath string, tmuxRunner tmux.Runner, ghRunner github.Runner, claudeReader claude.Reader, branchNameGen branchname.Generator) Model {
+func NewModel(cfg model.Config, runner git.CommandRunner, configPath string, tmuxRunner tmux.Runner, forgeOpts forge.Options, claudeReader claude.Reader, branchNameGen branchname.Generator) Model {
 	ti := textinput.New()
 	ti.Placeholder = "/path/to/repository"
 	ti.CharLimit = 256
@@ -164,18 +387,72 @@ func NewModel(cfg model.Config, runner git.CommandRunner, configPath string, tmu
 		configPath:    configPath,
 		textInput:     ti,
 		tmuxRunner:    tmuxRunner,
-		ghRunner:      ghRunner,
+		forgeOpts:     forgeOpts,
 		branchRenames: renames,
 		claudeReader:  claudeReader,
 		branchNameGen: branchNameGen,
 	}
 }
 
+// WithTodoStore returns a copy of the model that seeds a todo into store when
+// a worktree is created from an issue URL.
+func (m Model) WithTodoStore(store TodoStore) Model {
+	m.todoStore = store
+	return m
+}
+
+// WithStatCache returns a copy of the model that reuses the diff stats of
+// unchanged worktrees from cache instead of running `git diff` again.
+func (m Model) WithStatCache(cache *git.StatCache) Model {
+	m.statCache = cache
+	return m
+}
+
+// WithTrash returns a copy of the model that archives worktrees into bin
+// instead of deleting them.
+func (m Model) WithTrash(bin trash.Trash) Model {
+	m.trash = bin
+	return m
+}
+
+// WithPorts returns a copy of the model that shows the port block assigned
+// to the worktree under the cursor, from blocks keyed by worktree path.
+func (m Model) WithPorts(blocks map[string]ports.Block) Model {
+	m.ports = blocks
+	return m
+}
+
+// WithAudit returns a copy of the model that records destructive operations
+// in auditLog.
+func (m Model) WithAudit(auditLog audit.Log) Model {
+	m.audit = auditLog.From(audit.SourceTUI)
+	return m
+}
+
+// WithClipboard returns a copy of the model that prefills the add-worktree
+// prompt with a GitHub URL found on the clipboard.
+func (m Model) WithClipboard(reader clipboard.Reader) Model {
+	m.clipboard = reader
+	return m
+}
+
 // Selected returns the selected worktree path, if any.
 func (m Model) Selected() string {
 	return m.selected
 }
 
+// SelectedFile returns "path:line" of the search match the user picked, if
+// the worktree was selected from cross-worktree search results.
+func (m Model) SelectedFile() string {
+	return m.selectedFile
+}
+
+// SelectedPane returns the ID of the agent pane to focus once the selected
+// worktree's session is up, if the worktree was picked with A.
+func (m Model) SelectedPane() string {
+	return m.selectedPane
+}
+
 // SelectedRepoPath returns the repository root path for the selected worktree.
 func (m Model) SelectedRepoPath() string {
 	return m.selectedRepoPath
@@ -196,18 +473,58 @@ func (m Model) PendingRename(worktreePath string) *model.BranchRenameInfo {
 }
 
 func (m Model) Init() tea.Cmd {
-	return fetchGitDataCmd(m.config, m.runner)
+	cmds := []tea.Cmd{fetchGitDataCmd(m.config, m.runner, m.statCache)}
+	if m.updateCheck != nil {
+		cmds = append(cmds, updateCheckCmd(m.updateCheck))
+	}
+	if m.configChanges != nil {
+		cmds = append(cmds, waitForConfigChangeCmd(m.configChanges, m.configPath))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Capture terminal size for cursor-following scroll. Must run before
 	// modal-mode dispatch so resize events are honored even during modals.
 	if sizeMsg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = sizeMsg.Width
 		m.height = sizeMsg.Height
+		m.textInput.Width = inputWidth(m.width)
 		m = recomputeScroll(m)
 		return m, nil
 	}
 
+	// Toasts expire on a timer, whatever mode the user has moved on to.
+	if expired, ok := msg.(ToastExpiredMsg); ok {
+		return m.expireToast(expired), nil
+	}
+
+	// Progress of an add, archive or rebase reaches whichever view shows it.
+	// So do diff stats and refreshed repositories: they keep the cursor on
+	// its row.
+	switch msg := msg.(type) {
+	case ProgressMsg:
+		return m.updateProgress(msg)
+	case spinner.TickMsg:
+		return m.updateSpinner(msg)
+	case GitDataMsg:
+		if msg.update {
+			return m.applyDiffStats(msg)
+		}
+	case RepoRefreshedMsg:
+		return m.applyRepoRefresh(msg), nil
+	case UpdateCheckedMsg:
+		m.latestRelease = msg.Latest
+		return recomputeScroll(m), nil
+	case ConfigReloadedMsg:
+		return m.applyConfigReload(msg)
+	case tea.FocusMsg:
+		return m.focus()
+	case tea.BlurMsg:
+		m.unfocused = true
+		return m, nil
+	}
+
 	// Handle add-repo input mode
 	if m.addingRepo {
 		return m.updateAddRepoMode(msg)
@@ -218,42 +535,108 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateAddWorktreeMode(msg)
 	}
 
+	// Handle branch description input mode
+	if m.editingDescription {
+		return m.updateEditDescriptionMode(msg)
+	}
+
+	// Handle send-prompt input mode
+	if m.sendingPrompt {
+		return m.updateSendPromptMode(msg)
+	}
+
+	// Handle manual worktree rename input mode
+	if m.renamingWorktree {
+		return m.updateRenameWorktreeMode(msg)
+	}
+
 	// Handle archive confirmation mode
 	if m.confirmingArchive {
 		return m.updateConfirmArchiveMode(msg)
 	}
 
+	// Handle remove-repository confirmation mode
+	if m.removingRepo {
+		return m.updateRemoveRepoMode(msg)
+	}
+
+	// While filtering, keys edit the query; background messages flow through.
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.filtering {
+		return m.updateFilterMode(keyMsg)
+	}
+
+	// Handle search pattern input mode
+	if m.grepping {
+		return m.updateGrepInputMode(msg)
+	}
+
+	if update := m.activeOverlay(msg); update != nil {
+		return update(m, msg)
+	}
+
 	switch msg := msg.(type) {
 
 	case GitDataMsg:
 		m.groups = msg.Groups
-		m.items = sidebar.BuildItems(msg.Groups)
-		m.cursor = FirstSelectable(m.items)
-		m.scrollOff = 0
-		m = recomputeScroll(m)
+		m = rebuildItems(m)
 		m.loading = false
-		if !m.agentTickRunning {
+		m.gitRetries = 0
+		m.gitData = msg.stream
+		var statsCmd tea.Cmd
+		if msg.Partial {
+			statsCmd = msg.stream.next()
+		}
+		var prCmd tea.Cmd
+		m, prCmd = m.refreshPRStatus()
+		if !m.agentTickRunning && !m.agentUnavailable {
 			m.agentTickRunning = true
-			return m, agentTickCmd()
+			return m, tea.Batch(m.agentTickCmd(), prCmd, statsCmd)
 		}
-		return m, nil
+		return m, tea.Batch(prCmd, statsCmd)
+
+	case PRStatusTickMsg:
+		m.prTickRunning = false
+		return m.refreshPRStatus()
+
+	case PRStatusMsg:
+		return m.applyPRStatus(msg), nil
+
+	case BaseChecksMsg:
+		return m.applyBaseChecks(msg), nil
 
 	case AgentTickMsg:
 		if len(m.groups) > 0 && m.tmuxRunner != nil {
-			return m, fetchAgentStatusCmd(m.tmuxRunner, m.runner, m.groups)
+			return m, fetchAgentStatusCmd(m.tmuxRunner, m.agentProfiles, m.groups)
 		}
-		return m, agentTickCmd()
+		return m, m.agentTickCmd()
 
 	case AgentStatusMsg:
+		if tmux.IsUnavailable(msg.Err) {
+			logging.For("agent").Warn("tmux unavailable, stopping agent polling", "err", msg.Err)
+			m.agentTickRunning = false
+			m.agentUnavailable = true
+			return m, nil
+		}
+		prevStatus := m.agentStatus
 		m.agentStatus = msg.Statuses
-		for i := range m.items {
-			if m.items[i].Kind == model.ItemKindWorktree {
-				m.items[i].AgentStatus = m.agentStatus[m.items[i].WorktreePath]
+		m.activity = msg.Activity
+		m = m.recordAgentEvents(time.Now())
+		if m.usesActivitySort() {
+			m = rebuildItems(m)
+		} else {
+			for i := range m.items {
+				if m.items[i].Kind == model.ItemKindWorktree {
+					m.items[i].AgentStatus = m.agentStatus[m.items[i].WorktreePath]
+				}
 			}
+			// The agent summary above the list may have come or gone.
+			m = recomputeScroll(m)
 		}
 
-		var cmds []tea.Cmd
-		cmds = append(cmds, agentTickCmd())
+		cmds := []tea.Cmd{m.notifyAgentsCmd(prevStatus)}
+		if !msg.manual {
+			cmds = append(cmds, m.agentTickCmd())
+		}
 
 		now := time.Now().UnixMilli()
 		for path, info := range m.branchRenames {
@@ -261,26 +644,62 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				continue
 			}
 			if now-info.CreatedAt > renameTimeoutMs {
-				log.Printf("[branch-rename] timeout: path=%q elapsed=%dms", path, now-info.CreatedAt)
+				logging.For("branch-rename").Info("timeout", "path", path, "elapsed_ms", now-info.CreatedAt)
 				info.Status = model.RenameStatusSkipped
 				m.branchRenames[path] = info
 				continue
 			}
-			log.Printf("[branch-rename] polling: path=%q elapsed=%dms", path, now-info.CreatedAt)
+			logging.For("branch-rename").Debug("polling", "path", path, "elapsed_ms", now-info.CreatedAt)
 			cmds = append(cmds, checkPromptCmd(m.claudeReader, path, info.CreatedAt))
 		}
 
 		return m, tea.Batch(cmds...)
 
 	case GitDataErrMsg:
+		if isTransientGitError(msg.Err) && m.gitRetries < maxGitRetries {
+			m.gitRetries++
+			return m, gitRetryCmd()
+		}
 		m.err = msg.Err
 		m.loading = false
 		return m, nil
 
+	case GitDataRetryMsg:
+		return m, fetchGitDataCmd(m.config, m.runner, m.statCache)
+
+	case BranchDescriptionSetMsg:
+		m.loading = true
+		return m, fetchGitDataCmd(m.config, m.runner, m.statCache)
+
+	case BranchDescriptionErrMsg:
+		m.loading = false
+		return m.notifyErr(msg.Err)
+
+	case PromptSentMsg:
+		return m.notify(SeverityInfo, "Sent to "+msg.Agent+" in "+msg.Label, "")
+
+	case PromptSendErrMsg:
+		return m.notifyErr(msg.Err)
+
+	case WorktreeRenamedMsg:
+		m.skipPendingRename(msg.OldPath)
+		m.loading = true
+		return m, fetchGitDataCmd(m.config, m.runner, m.statCache)
+
+	case WorktreeRenameErrMsg:
+		m.loading = false
+		return m.notifyErr(msg.Err)
+
 	case WorktreeAddedMsg:
 		m.loading = true
-		if m.branchRenames != nil && msg.WorktreePath != "" {
-			log.Printf("[branch-rename] WorktreeAdded: path=%q branch=%q createdAt=%d", msg.WorktreePath, msg.Branch, msg.CreatedAt)
+		if msg.Issue != 0 {
+			logging.For("branch-rename").Debug("named after an issue, skipping rename", "branch", msg.Branch, "issue", msg.Issue)
+		} else if msg.Existing {
+			logging.For("branch-rename").Debug("existing branch, skipping rename", "branch", msg.Branch)
+		} else if msg.Named {
+			logging.For("branch-rename").Debug("named by the user, skipping rename", "branch", msg.Branch)
+		} else if m.branchRenames != nil && msg.WorktreePath != "" {
+			logging.For("branch-rename").Debug("worktree added", "path", msg.WorktreePath, "branch", msg.Branch, "created_at", msg.CreatedAt)
 			m.branchRenames[msg.WorktreePath] = model.BranchRenameInfo{
 				Status:         model.RenameStatusPending,
 				OriginalBranch: msg.Branch,
@@ -288,9 +707,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				CreatedAt:      msg.CreatedAt,
 			}
 		} else if m.branchRenames == nil {
-			log.Printf("[branch-rename] WorktreeAdded: feature disabled (branchRenames=nil)")
+			logging.For("branch-rename").Debug("feature disabled (branchRenames=nil)")
+		}
+		m.recordTemplate(msg)
+		if msg.HookErr != nil {
+			var cmd tea.Cmd
+			m, cmd = m.notifyErr(msg.HookErr)
+			return m, tea.Batch(cmd, m.refreshRepos(msg.RepoPath))
 		}
-		return m, fetchGitDataCmd(m.config, m.runner)
+		return m, m.refreshRepos(msg.RepoPath)
 
 	case BranchRenameStartMsg:
 		if info, ok := m.branchRenames[msg.WorktreePath]; ok && info.Status == model.RenameStatusPending {
@@ -298,7 +723,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			info.FirstPrompt = msg.Prompt
 			info.SessionID = msg.SessionID
 			m.branchRenames[msg.WorktreePath] = info
-			return m, renameBranchCmd(m.branchNameGen, m.runner, m.tmuxRunner, msg.WorktreePath, info.OriginalBranch, msg.Prompt)
+			namer, _ := branchname.NewNamer(m.config.BranchNamer)
+			return m, renameBranchCmd(m.branchNameGen, namer, m.runner, m.tmuxRunner, m.audit.From(audit.SourceWatcher), msg.WorktreePath, info.OriginalBranch, msg.Prompt)
 		}
 		return m, nil
 
@@ -314,25 +740,34 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		if msg.Err == nil {
 			m.loading = true
-			return m, fetchGitDataCmd(m.config, m.runner)
+			return m, fetchGitDataCmd(m.config, m.runner, m.statCache)
 		}
 		return m, nil
 
 	case WorktreeAddErrMsg:
-		m.err = msg.Err
 		m.loading = false
-		return m, nil
+		return m.notifyErr(msg.Err)
 
 	case WorktreeArchivedMsg:
 		m.loading = true
 		m.confirmingArchive = false
-		return m, fetchGitDataCmd(m.config, m.runner)
+		return m, m.refreshRepos(msg.RepoPath)
 
 	case WorktreeArchiveErrMsg:
-		m.err = msg.Err
 		m.loading = false
 		m.confirmingArchive = false
-		return m, nil
+		return m.notifyErr(msg.Err)
+
+	case WorktreesFetchedMsg:
+		// The base refs have moved, so every cached diff stat may be stale.
+		m.statCache.Clear()
+		m.loading = true
+		if msg.Err != nil {
+			var cmd tea.Cmd
+			m, cmd = m.notify(SeverityWarning, msg.Err.Error(), errorHint(msg.Err))
+			return m, tea.Batch(cmd, fetchGitDataCmd(m.config, m.runner, m.statCache))
+		}
+		return m, fetchGitDataCmd(m.config, m.runner, m.statCache)
 
 	case RepoValidatedMsg:
 		m.loading = true
@@ -355,7 +790,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.addingRepo = false
 		m.textInput.SetValue("")
 		m.loading = true
-		return m, fetchGitDataCmd(m.config, m.runner)
+		return m, fetchGitDataCmd(m.config, m.runner, m.statCache)
 
 	case RepoAddErrMsg:
 		m.err = msg.Err
@@ -372,18 +807,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if zone.Get(ZoneID(i)).InBounds(msg) {
 					m.cursor = i
 					m = recomputeScroll(m)
+					if item.Kind == model.ItemKindGroupHeader {
+						return m.toggleGroup(), nil
+					}
 					if item.Kind == model.ItemKindWorktree {
-						m.selected = item.WorktreePath
-						m.selectedRepoPath = item.RepoRootPath
-						return m, tea.Quit
+						return m.selectWorktree(item.WorktreePath, item.RepoRootPath)
 					}
 					if item.Kind == model.ItemKindAddWorktree {
-						m.addingWorktree = true
-						m.addingWorktreeRepoPath = item.RepoRootPath
-						m.err = nil
-						m.textInput.Placeholder = "URL, branch name, or Enter for new branch"
-						cmd := m.textInput.Focus()
-						return m, cmd
+						return m.startAddWorktree(item.RepoRootPath)
 					}
 					if item.Kind == model.ItemKindAddRepo {
 						m.addingRepo = true
@@ -398,46 +829,222 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tea.KeyMsg:
-		switch msg.String() {
+		if m.selecting {
+			if next, cmd, ok := m.updateSelectKey(msg); ok {
+				return next, cmd
+			}
+		}
+
+		switch {
 
-		case "ctrl+c", "q":
+		case key.Matches(msg, sidebarKeys.Quit, globalKeys.ForceQuit):
 			m.quitting = true
 			return m, tea.Quit
 
-		case "up", "k":
+		case key.Matches(msg, sidebarKeys.Up):
 			m.cursor = PrevSelectable(m.items, m.cursor)
 			m = recomputeScroll(m)
 
-		case "down", "j":
+		case key.Matches(msg, sidebarKeys.Down):
 			m.cursor = NextSelectable(m.items, m.cursor)
 			m = recomputeScroll(m)
 
-		case "d":
+		case key.Matches(msg, sidebarKeys.Archive):
 			if m.cursor < len(m.items) {
 				item := m.items[m.cursor]
 				if item.Kind == model.ItemKindWorktree && !item.IsBare {
 					m.confirmingArchive = true
 					m.archiveTarget = m.cursor
+					m.archiveHookFailed = false
 					m.err = nil
 					return m, nil
 				}
 			}
 
-		case "enter":
+		case key.Matches(msg, sidebarKeys.RemoveRepo):
+			return m.startRemoveRepo(), nil
+
+		case key.Matches(msg, sidebarKeys.QuickDiff):
 			if m.cursor < len(m.items) {
 				item := m.items[m.cursor]
 				if item.Kind == model.ItemKindWorktree {
-					m.selected = item.WorktreePath
-					m.selectedRepoPath = item.RepoRootPath
-					return m, tea.Quit
+					m.showingQuickDiff = true
+					m.quickDiffLoading = true
+					m.quickDiffPath = item.WorktreePath
+					m.quickDiffLabel = item.Label
+					m.quickDiffScroll = 0
+					m.quickDiff = QuickDiffMsg{}
+					m.quickDiffErr = nil
+					return m, quickDiffCmd(m.runner, item.WorktreePath, config.BaseRef(m.config, item.RepoRootPath), git.DiffBase(m.config.DiffBase))
 				}
-				if item.Kind == model.ItemKindAddWorktree {
-					m.addingWorktree = true
-					m.addingWorktreeRepoPath = item.RepoRootPath
+			}
+
+		case key.Matches(msg, sidebarKeys.DevLog):
+			if m.cursor < len(m.items) {
+				item := m.items[m.cursor]
+				if item.Kind == model.ItemKindWorktree {
+					m.showingDevLog = true
+					m.devLogLoading = true
+					m.devLogPath = item.WorktreePath
+					m.devLogLabel = item.Label
+					m.devLogLines = nil
+					m.devLogErr = nil
+					m.devLogScroll = 0
+					m.devLogQuery = ""
+					return m.followDevLog()
+				}
+			}
+
+		case key.Matches(msg, sidebarKeys.debugLog):
+			return m.openDebugLog()
+
+		case key.Matches(msg, sidebarKeys.Sort):
+			if m.cursor < len(m.items) && m.items[m.cursor].RepoRootPath != "" {
+				repoPath := m.items[m.cursor].RepoRootPath
+				sortModes := make(map[string]sidebar.SortMode, len(m.sortModes)+1)
+				for path, mode := range m.sortModes {
+					sortModes[path] = mode
+				}
+				sortModes[repoPath] = m.sortModeFor(repoPath).Next()
+				m.sortModes = sortModes
+				m = rebuildItems(m)
+			}
+			return m, nil
+
+		case key.Matches(msg, sidebarKeys.GroupBy):
+			return m.toggleGroupByPrefix(), nil
+
+		case key.Matches(msg, sidebarKeys.Filter):
+			m.filtering = true
+			m.err = nil
+			m.textInput.Placeholder = "branch, repo or path"
+			m.textInput.SetValue("")
+			return m, m.textInput.Focus()
+
+		case key.Matches(msg, sidebarKeys.Search):
+			if len(m.groups) > 0 {
+				m.grepping = true
+				m.err = nil
+				m.textInput.Placeholder = "pattern"
+				m.textInput.SetValue("")
+				return m, m.textInput.Focus()
+			}
+
+		case key.Matches(msg, sidebarKeys.Describe):
+			if m.cursor < len(m.items) {
+				item := m.items[m.cursor]
+				if item.Kind == model.ItemKindWorktree && !item.IsBare && item.Label != "" {
+					m.editingDescription = true
+					m.descriptionPath = item.WorktreePath
+					m.descriptionBranch = item.Label
+					m.descriptionOld = item.Description
 					m.err = nil
-					m.textInput.Placeholder = "URL, branch name, or Enter for new branch"
-					cmd := m.textInput.Focus()
-					return m, cmd
+					m.textInput.Placeholder = "what is this branch for?"
+					m.textInput.SetValue(item.Description)
+					m.textInput.CursorEnd()
+					return m, m.textInput.Focus()
+				}
+			}
+
+		case key.Matches(msg, sidebarKeys.Rename):
+			if m.cursor < len(m.items) {
+				item := m.items[m.cursor]
+				if item.Kind == model.ItemKindWorktree && !item.IsBare && item.Label != "" {
+					m.renamingWorktree = true
+					m.renamePath = item.WorktreePath
+					m.renameRepoPath = item.RepoRootPath
+					m.renameBranch = item.Label
+					m.err = nil
+					m.textInput.Placeholder = "new branch name"
+					m.textInput.SetValue(item.Label)
+					m.textInput.CursorEnd()
+					return m, m.textInput.Focus()
+				}
+			}
+
+		case key.Matches(msg, sidebarKeys.Cleanup):
+			if len(m.groups) > 0 {
+				return m.startCleanup()
+			}
+
+		case key.Matches(msg, sidebarKeys.Archived):
+			if m.trash.Enabled() {
+				return m.startArchived()
+			}
+
+		case key.Matches(msg, sidebarKeys.Prune):
+			if len(m.groups) > 0 {
+				return m.startPrune()
+			}
+
+		case key.Matches(msg, sidebarKeys.Restore):
+			if m.restoreSessions != nil {
+				return m.startRestore()
+			}
+
+		case key.Matches(msg, sidebarKeys.Rebase):
+			if m.cursor < len(m.items) {
+				item := m.items[m.cursor]
+				if item.Kind == model.ItemKindWorktree && !item.IsBare && item.Label != "" {
+					baseRef := config.BaseRef(m.config, item.RepoRootPath)
+					m = m.closeRebase()
+					m.showingRebase = true
+					m.rebaseLoading = true
+					m.rebasePath = item.WorktreePath
+					m.rebaseLabel = item.Label
+					m.rebaseCursor = 0
+					return m, rebasePlanCmd(m.runner, item.WorktreePath, baseRef)
+				}
+			}
+
+		case key.Matches(msg, sidebarKeys.Select):
+			m.selecting = true
+			return m, nil
+
+		case key.Matches(msg, sidebarKeys.Pin):
+			return m.togglePin(), nil
+
+		case key.Matches(msg, sidebarKeys.Help):
+			return m.openHelp(), nil
+
+		case key.Matches(msg, sidebarKeys.Errors):
+			return m.openErrorLog(), nil
+
+		case key.Matches(msg, sidebarKeys.Activity):
+			return m.openAgentActivity(), nil
+
+		case key.Matches(msg, sidebarKeys.AgentPane):
+			return m.jumpToAgent()
+
+		case key.Matches(msg, sidebarKeys.Prompt):
+			return m.startSendPrompt()
+
+		case key.Matches(msg, sidebarKeys.Waiting):
+			return m.nextWaitingAgent()
+
+		case key.Matches(msg, sidebarKeys.Panes):
+			return m.openPanes()
+
+		case key.Matches(msg, sidebarKeys.Tasks):
+			return m.openTasks()
+
+		case key.Matches(msg, sidebarKeys.Refresh):
+			return m.refresh()
+
+		case key.Matches(msg, sidebarKeys.Fold):
+			return m.toggleGroup(), nil
+
+		case key.Matches(msg, sidebarKeys.Open):
+			if m.cursor < len(m.items) {
+				item := m.items[m.cursor]
+				if item.Kind == model.ItemKindGroupHeader {
+					return m.toggleGroup(), nil
+				}
+				if item.Kind == model.ItemKindWorktree {
+					return m.selectWorktree(item.WorktreePath, item.RepoRootPath)
+				}
+				if item.Kind == model.ItemKindAddWorktree {
+					return m.startAddWorktree(item.RepoRootPath)
 				}
 				if item.Kind == model.ItemKindAddRepo {
 					m.addingRepo = true
@@ -462,6 +1069,7 @@ func (m Model) updateAddRepoMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.textInput.SetValue("")
 			m.textInput.SetSuggestions(nil)
 			m.lastSuggestionDir = ""
+			m.pathCycle = nil
 			m.err = nil
 			return m, nil
 		case tea.KeyEnter:
@@ -472,19 +1080,27 @@ func (m Model) updateAddRepoMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			m.textInput.SetSuggestions(nil)
 			m.lastSuggestionDir = ""
+			m.pathCycle = nil
 			m.loading = true
 			m.err = nil
 			return m, validateRepoCmd(m.runner, path)
+		case tea.KeyTab:
+			return m.completePath(1)
+		case tea.KeyShiftTab:
+			return m.completePath(-1)
 		case tea.KeyCtrlC:
 			m.quitting = true
 			return m, tea.Quit
 		}
+		// Any other key keeps the directory picked with tab.
+		m.pathCycle = nil
 
 	case PathSuggestionsMsg:
 		homeDir, _ := os.UserHomeDir()
 		currentDir := pathcomplete.ExtractDir(m.textInput.Value(), homeDir)
 		if msg.ForDir == currentDir {
 			m.textInput.SetSuggestions(msg.Suggestions)
+			m.pathRepos = msg.Repos
 		}
 		return m, nil
 
@@ -511,7 +1127,7 @@ func (m Model) updateAddRepoMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.textInput.SetSuggestions(nil)
 		m.lastSuggestionDir = ""
 		m.loading = true
-		return m, fetchGitDataCmd(m.config, m.runner)
+		return m, fetchGitDataCmd(m.config, m.runner, m.statCache)
 
 	case RepoAddErrMsg:
 		m.err = msg.Err
@@ -521,41 +1137,60 @@ func (m Model) updateAddRepoMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	// Delegate to textinput
-	prevValue := m.textInput.Value()
 	var cmd tea.Cmd
 	m.textInput, cmd = m.textInput.Update(msg)
-	newValue := m.textInput.Value()
-
-	// Check if the directory portion changed; if so, fetch new suggestions.
-	if newValue != prevValue {
-		homeDir, _ := os.UserHomeDir()
-		newDir := pathcomplete.ExtractDir(newValue, homeDir)
-		if newDir != m.lastSuggestionDir {
-			m.lastSuggestionDir = newDir
-			return m, tea.Batch(cmd, fetchPathSuggestionsCmd(newValue))
-		}
-	}
 
-	return m, cmd
+	// Check if the directory portion changed, by typing or by a directory
+	// picked with tab; if so, fetch new suggestions.
+	m, fetch := m.fetchPathSuggestions()
+	return m, tea.Batch(cmd, fetch)
 }
 
 const maxPathSuggestions = 50
 
+// fetchPathSuggestionsCmd suggests the git repositories in the directory of
+// input first, then its other directories, such as ~/code/github.com, to go
+// on through.
 func fetchPathSuggestionsCmd(input string) tea.Cmd {
 	return func() tea.Msg {
 		homeDir, _ := os.UserHomeDir()
 		dir := pathcomplete.ExtractDir(input, homeDir)
-		suggestions := pathcomplete.ListDirSuggestions(input, homeDir, pathcomplete.DefaultDirLister, maxPathSuggestions)
+		repos := pathcomplete.ListFilteredDirSuggestions(input, homeDir, pathcomplete.DefaultDirLister, pathcomplete.HasGitEntry, maxPathSuggestions)
+		suggestions := slices.Clone(repos)
+		for _, d := range pathcomplete.ListDirSuggestions(input, homeDir, pathcomplete.DefaultDirLister, maxPathSuggestions) {
+			if len(suggestions) < maxPathSuggestions && !slices.Contains(repos, d) {
+				suggestions = append(suggestions, d)
+			}
+		}
 		return PathSuggestionsMsg{
 			Suggestions: suggestions,
+			Repos:       repos,
 			ForDir:      dir,
 		}
 	}
 }
 
+// openAddWorktree opens the add-worktree prompt for repoPath and checks the
+// clipboard for a URL to prefill.
+func (m Model) openAddWorktree(repoPath string) (tea.Model, tea.Cmd) {
+	m.addingWorktree = true
+	m.addingWorktreeRepoPath = repoPath
+	m.err = nil
+	m.clipboardPrefilled = false
+	m.branches = nil
+	m.branchCursor = -1
+	m.textInput.Placeholder = "URL, branch name, or Enter for new branch"
+	cmd := tea.Batch(m.textInput.Focus(), listBranchesCmd(m.runner, repoPath))
+	if m.clipboard != nil {
+		cmd = tea.Batch(cmd, readClipboardCmd(m.clipboard))
+	}
+	return m, cmd
+}
+
 func (m Model) updateAddWorktreeMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.clipboardPrefilled = false
 		switch msg.Type {
 		case tea.KeyEscape:
 			m.addingWorktree = false
@@ -563,20 +1198,59 @@ func (m Model) updateAddWorktreeMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.textInput.SetValue("")
 			m.err = nil
 			return m, nil
+		case tea.KeyUp, tea.KeyCtrlP:
+			return m.moveBranchCursor(-1), nil
+		case tea.KeyDown, tea.KeyCtrlN:
+			return m.moveBranchCursor(1), nil
 		case tea.KeyEnter:
+			matches := m.branchMatches()
 			input := strings.TrimSpace(m.textInput.Value())
 			m.textInput.SetValue("")
 			m.addingWorktree = false
 			m.loading = true
 			m.err = nil
 			repoName := repoNameFromConfig(m.config, m.addingWorktreeRepoPath)
+			cfg, prefix := m.templateConfig(m.addingWorktreeRepoPath)
+			base := config.WorktreeBase(cfg, m.addingWorktreeRepoPath)
+			template := m.addTemplate
+			if m.branchCursor >= 0 && m.branchCursor < len(matches) {
+				branch := matches[m.branchCursor]
+				return m.withProgress("Checking out "+branch.Name+"...", func(runner git.CommandRunner) tea.Cmd {
+					return seedWorktreeCmd(cfg, template, addWorktreeFromExistingBranchCmd(runner, m.addingWorktreeRepoPath, base, repoName, branch))
+				})
+			}
 			if input == "" {
-				return m, addWorktreeCmd(m.runner, m.addingWorktreeRepoPath, m.config.WorktreeBasePath, repoName, m.config.DefaultBaseRef)
+				return m.withProgress("Creating worktree...", func(runner git.CommandRunner) tea.Cmd {
+					return seedWorktreeCmd(cfg, template, addWorktreeCmd(runner, m.addingWorktreeRepoPath, base, repoName, cfg.DefaultBaseRef, prefix))
+				})
 			}
 			if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
-				return m, addWorktreeFromURLCmd(m.runner, m.ghRunner, m.addingWorktreeRepoPath, m.config.WorktreeBasePath, repoName, input)
+				if info, err := github.ParseGitHubURL(input); err == nil && info.Type == github.URLTypeIssue {
+					if m.forgeOpts.GitHubRunner == nil {
+						m.loading = false
+						return m.notifyErr(fmt.Errorf("creating a worktree from an issue needs gh or a GitHub token"))
+					}
+					return m.withProgress("Fetching issue #"+info.IssueNumber+"...", func(runner git.CommandRunner) tea.Cmd {
+						return seedWorktreeCmd(cfg, template, addWorktreeFromIssueCmd(runner, m.forgeOpts.GitHubRunner, m.branchNameGen, m.todoStore, m.addingWorktreeRepoPath, base, repoName, cfg.DefaultBaseRef, prefix, input))
+					})
+				}
+				provider, err := m.providerForURL(m.addingWorktreeRepoPath, input)
+				if err != nil {
+					m.loading = false
+					return m.notifyErr(err)
+				}
+				return m.withProgress("Looking up the branch...", func(runner git.CommandRunner) tea.Cmd {
+					return seedWorktreeCmd(cfg, template, addWorktreeFromURLCmd(runner, provider, m.addingWorktreeRepoPath, base, repoName, input))
+				})
+			}
+			if !strings.Contains(input, "/") {
+				return m.withProgress("Creating worktree...", func(runner git.CommandRunner) tea.Cmd {
+					return seedWorktreeCmd(cfg, template, addWorktreeWithNameCmd(runner, m.addingWorktreeRepoPath, base, repoName, cfg.DefaultBaseRef, prefix, input))
+				})
 			}
-			return m, addWorktreeFromBranchNameCmd(m.runner, m.addingWorktreeRepoPath, m.config.WorktreeBasePath, repoName, input)
+			return m.withProgress("Creating worktree...", func(runner git.CommandRunner) tea.Cmd {
+				return seedWorktreeCmd(cfg, template, addWorktreeFromBranchNameCmd(runner, m.addingWorktreeRepoPath, base, repoName, input))
+			})
 		case tea.KeyCtrlC:
 			m.quitting = true
 			return m, tea.Quit
@@ -585,7 +1259,7 @@ func (m Model) updateAddWorktreeMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case WorktreeAddedMsg:
 		m.loading = true
 		m.addingWorktree = false
-		if m.branchRenames != nil && msg.WorktreePath != "" {
+		if m.branchRenames != nil && msg.WorktreePath != "" && msg.Issue == 0 && !msg.Existing && !msg.Named {
 			m.branchRenames[msg.WorktreePath] = model.BranchRenameInfo{
 				Status:         model.RenameStatusPending,
 				OriginalBranch: msg.Branch,
@@ -593,18 +1267,37 @@ func (m Model) updateAddWorktreeMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 				CreatedAt:      msg.CreatedAt,
 			}
 		}
-		return m, fetchGitDataCmd(m.config, m.runner)
+		m.recordTemplate(msg)
+		if msg.HookErr != nil {
+			var cmd tea.Cmd
+			m, cmd = m.notifyErr(msg.HookErr)
+			return m, tea.Batch(cmd, m.refreshRepos(msg.RepoPath))
+		}
+		return m, m.refreshRepos(msg.RepoPath)
 
 	case WorktreeAddErrMsg:
 		m.err = msg.Err
 		m.loading = false
 		m.addingWorktree = false
 		return m, nil
+
+	case BranchListMsg:
+		return m.applyBranchList(msg), nil
+
+	case ClipboardMsg:
+		// Only offer the clipboard while the user has not started typing.
+		if m.textInput.Value() == "" && isGitHubURL(msg.Text) {
+			m.textInput.SetValue(msg.Text)
+			m.textInput.CursorEnd()
+			m.clipboardPrefilled = true
+		}
+		return m, nil
 	}
 
-	// Delegate to textinput
+	// Delegate to textinput; the picker narrows as the input changes.
 	var cmd tea.Cmd
 	m.textInput, cmd = m.textInput.Update(msg)
+	m.branchCursor = min(m.branchCursor, len(m.branchMatches())-1)
 	return m, cmd
 }
 
@@ -619,13 +1312,35 @@ func (m Model) updateConfirmArchiveMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.Type {
 		case tea.KeyEscape:
 			m.confirmingArchive = false
+			m.archiveMarked = nil
+			m.archiveHookFailed = false
 			m.err = nil
 			return m, nil
+		case tea.KeyRunes:
+			if !m.archiveHookFailed || msg.String() != "f" {
+				return m, nil
+			}
+			item := m.items[m.archiveTarget]
+			m.loading = true
+			m.archiveHookFailed = false
+			m.err = nil
+			return m.withProgress("Stopping the tmux session...", func(runner git.CommandRunner) tea.Cmd {
+				return archiveWorktreeCmd(runner, m.tmuxRunner, m.trash, m.audit, m.config, item.RepoRootPath, item.WorktreePath, true)
+			})
 		case tea.KeyEnter:
+			if len(m.archiveMarked) > 0 {
+				m.loading = true
+				m.err = nil
+				return m.withProgress("Archiving worktrees...", func(runner git.CommandRunner) tea.Cmd {
+					return archiveWorktreesCmd(runner, m.tmuxRunner, m.trash, m.audit, m.config, m.archiveMarked)
+				})
+			}
 			item := m.items[m.archiveTarget]
 			m.loading = true
 			m.err = nil
-			return m, archiveWorktreeCmd(m.runner, m.tmuxRunner, item.RepoRootPath, item.WorktreePath)
+			return m.withProgress("Stopping the tmux session...", func(runner git.CommandRunner) tea.Cmd {
+				return archiveWorktreeCmd(runner, m.tmuxRunner, m.trash, m.audit, m.config, item.RepoRootPath, item.WorktreePath, false)
+			})
 		case tea.KeyCtrlC:
 			m.quitting = true
 			return m, tea.Quit
@@ -634,55 +1349,128 @@ func (m Model) updateConfirmArchiveMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case WorktreeArchivedMsg:
 		m.loading = true
 		m.confirmingArchive = false
-		return m, fetchGitDataCmd(m.config, m.runner)
+		return m, m.refreshRepos(msg.RepoPath)
 
 	case WorktreeArchiveErrMsg:
-		m.err = msg.Err
 		m.loading = false
+		if msg.HookFailed {
+			// Stay in the dialog so the worktree can be archived anyway.
+			m.archiveHookFailed = true
+			m.err = msg.Err
+			return m, nil
+		}
 		m.confirmingArchive = false
-		return m, nil
+		return m.notifyErr(msg.Err)
+
+	case WorktreesArchivedMsg:
+		m.loading = true
+		m.confirmingArchive = false
+		m.archiveMarked = nil
+		m.selecting = false
+		m.marked = nil
+		if msg.Err != nil {
+			var cmd tea.Cmd
+			m, cmd = m.notifyErr(msg.Err)
+			return m, tea.Batch(cmd, m.refreshRepos(msg.RepoPaths...))
+		}
+		return m, m.refreshRepos(msg.RepoPaths...)
 	}
 
 	return m, nil
 }
 
-func archiveWorktreeCmd(runner git.CommandRunner, tmuxRunner tmux.Runner, repoRootPath, worktreePath string) tea.Cmd {
+// archiveWorktreeCmd archives one worktree; skipHook leaves out its
+// pre_archive hook, as when archiving it anyway after the hook failed.
+func archiveWorktreeCmd(runner git.CommandRunner, tmuxRunner tmux.Runner, bin trash.Trash, auditLog audit.Log, cfg model.Config, repoRootPath, worktreePath string, skipHook bool) tea.Cmd {
 	return func() tea.Msg {
-		// Kill tmux session first (processes inside worktree would block git worktree remove)
-		if tmuxRunner != nil {
-			var getBranch tmux.BranchGetter
-			if runner != nil {
-				getBranch = func(wtPath string) (string, error) {
-					out, err := runner.Run(wtPath, "symbolic-ref", "--short", "HEAD")
-					if err != nil {
-						return "", err
-					}
-					return strings.TrimSpace(out), nil
-				}
-			}
-			sessionName := tmux.ResolveSessionName(tmuxRunner, worktreePath, getBranch)
+		if err := archiveWorktree(runner, tmuxRunner, bin, auditLog, cfg, repoRootPath, worktreePath, skipHook); err != nil {
+			var hookErr preArchiveError
+			return WorktreeArchiveErrMsg{Err: err, HookFailed: errors.As(err, &hookErr)}
+		}
+		return WorktreeArchivedMsg{RepoPath: repoRootPath}
+	}
+}
+
+// preArchiveError is the failure of a pre_archive hook, which kept the
+// worktree.
+type preArchiveError struct {
+	err error
+}
+
+func (e preArchiveError) Error() string { return "pre_archive hook: " + e.err.Error() }
+
+func (e preArchiveError) Unwrap() error { return e.err }
+
+// ArchiveWorktree runs the repository's pre_archive hook, kills the
+// worktree's tmux session and moves the worktree to the trash, or removes it
+// when the trash is disabled. The branch is kept. A failed hook keeps the
+// worktree. Both steps are recorded in auditLog.
+func ArchiveWorktree(runner git.CommandRunner, tmuxRunner tmux.Runner, bin trash.Trash, auditLog audit.Log, cfg model.Config, repoRootPath, worktreePath string) error {
+	return archiveWorktree(runner, tmuxRunner, bin, auditLog, cfg, repoRootPath, worktreePath, false)
+}
+
+func archiveWorktree(runner git.CommandRunner, tmuxRunner tmux.Runner, bin trash.Trash, auditLog audit.Log, cfg model.Config, repoRootPath, worktreePath string, skipHook bool) error {
+	var branch string
+	if runner != nil {
+		branch, _ = git.CurrentBranch(runner, worktreePath) // "" when detached
+	}
+
+	if !skipHook {
+		env := hooks.Env{Worktree: worktreePath, Branch: branch, Repo: repoRootPath}
+		if err := hooks.Run(hooks.For(cfg, repoRootPath).PreArchive, env); err != nil {
+			return preArchiveError{err}
+		}
+	}
+	event := audit.Event{Op: audit.OpArchive, Repo: repoRootPath, Path: worktreePath, Branch: branch}
 
-			// If we're inside the session being deleted, switch to main session first
-			if tmux.IsCurrentSession(tmuxRunner, sessionName) {
-				if err := tmux.SwitchToMainSession(tmuxRunner); err != nil {
-					log.Printf("[archive] switch to main session failed (non-fatal): %v", err)
+	// Kill tmux session first (processes inside worktree would block git worktree remove)
+	if tmuxRunner != nil {
+		var getBranch tmux.BranchGetter
+		if runner != nil {
+			getBranch = func(wtPath string) (string, error) {
+				out, err := runner.Run(wtPath, "symbolic-ref", "--short", "HEAD")
+				if err != nil {
+					return "", err
 				}
+				return strings.TrimSpace(out), nil
 			}
+		}
+		sessionName := tmux.ResolveSessionName(tmuxRunner, worktreePath, getBranch)
 
-			tmux.KillSession(tmuxRunner, sessionName) // ignore error (session may not exist)
+		// If we're inside the session being deleted, switch to main session first
+		if tmux.IsCurrentSession(tmuxRunner, sessionName) {
+			if err := tmux.SwitchToMainSession(tmuxRunner); err != nil {
+				logging.For("archive").Warn("switch to main session failed (non-fatal)", "err", err)
+			}
 		}
 
-		if err := git.RemoveWorktree(runner, repoRootPath, worktreePath); err != nil {
-			return WorktreeArchiveErrMsg{Err: err}
+		// An error means the session did not exist; nothing was killed.
+		if err := tmux.KillWorktree(tmuxRunner, sessionName); err == nil {
+			auditLog.Record(audit.Event{Op: audit.OpKillSession, Repo: repoRootPath, Path: worktreePath, Branch: branch, Detail: sessionName}, nil)
 		}
+	}
 
-		// Clean up directory if it still remains
-		if _, err := os.Stat(worktreePath); err == nil {
-			os.RemoveAll(worktreePath)
+	if bin.Enabled() {
+		entry, err := bin.Archive(runner, repoRootPath, worktreePath, branch, time.Now())
+		if err == nil {
+			event.Detail = "moved to " + bin.Path(entry)
 		}
+		auditLog.Record(event, err)
+		return err
+	}
 
-		return WorktreeArchivedMsg{}
+	err := git.RemoveWorktree(runner, repoRootPath, worktreePath)
+	auditLog.Record(event, err)
+	if err != nil {
+		return err
 	}
+
+	// Clean up directory if it still remains
+	if _, err := os.Stat(worktreePath); err == nil {
+		os.RemoveAll(worktreePath)
+	}
+
+	return nil
 }
 
 func repoNameFromConfig(cfg model.Config, repoPath string) string {
@@ -694,80 +1482,243 @@ func repoNameFromConfig(cfg model.Config, repoPath string) string {
 	return filepath.Base(repoPath)
 }
 
-func addWorktreeCmd(runner git.CommandRunner, repoPath, basePath, repoName, baseRef string) tea.Cmd {
+// providerForURL returns the forge provider for a URL entered against the
+// repository at repoPath. The repository's `forge` setting wins; otherwise the
+// forge is inferred from the URL host.
+func (m Model) providerForURL(repoPath, rawURL string) (forge.Provider, error) {
+	return providerForURL(m.config, m.providerOpts(), repoPath, rawURL)
+}
+
+func providerForURL(cfg model.Config, opts forge.Options, repoPath, rawURL string) (forge.Provider, error) {
+	kind := ""
+	for _, repo := range cfg.Repositories {
+		if repo.Path == repoPath {
+			kind = repo.Forge
+		}
+	}
+	if kind == "" {
+		kind = forge.DetectKind(rawURL)
+	}
+	return forge.New(kind, opts)
+}
+
+// providerOpts returns the forge options, defaulting the git runner to the
+// sidebar's.
+func (m Model) providerOpts() forge.Options {
+	opts := m.forgeOpts
+	if opts.GitRunner == nil {
+		opts.GitRunner = m.runner
+	}
+	return opts
+}
+
+func readClipboardCmd(reader clipboard.Reader) tea.Cmd {
 	return func() tea.Msg {
-		userName, err := git.GetUserName(runner, repoPath)
+		text, err := reader.Read()
 		if err != nil {
-			return WorktreeAddErrMsg{Err: err}
+			logging.For("worktree").Warn("reading clipboard failed (non-fatal)", "err", err)
+			return nil
 		}
+		return ClipboardMsg{Text: text}
+	}
+}
 
-		country := git.RandomCountry()
-		baseSlug := git.Slugify(country)
-		userSlug := branchname.SanitizeBranchName(userName)
-		if userSlug == "" {
-			userSlug = "user"
+// isGitHubURL reports whether text is a single GitHub branch, PR or issue URL.
+func isGitHubURL(text string) bool {
+	if strings.ContainsAny(text, " \n\t") {
+		return false
+	}
+	_, err := github.ParseGitHubURL(text)
+	return err == nil
+}
+
+func addWorktreeCmd(runner git.CommandRunner, repoPath, basePath, repoName, baseRef, prefix string) tea.Cmd {
+	return func() tea.Msg {
+		userSlug, err := branchPrefix(runner, repoPath, prefix)
+		if err != nil {
+			return WorktreeAddErrMsg{Err: err}
 		}
+		country := git.RandomCountry()
+		return createWorktreeFromBase(runner, repoPath, basePath, repoName, baseRef, userSlug, git.Slugify(country))
+	}
+}
 
-		if fetchBranch, ok := strings.CutPrefix(baseRef, "origin/"); ok {
-			if err := git.FetchBranch(runner, repoPath, fetchBranch); err != nil {
-				return WorktreeAddErrMsg{Err: fmt.Errorf("fetching %s: %w", baseRef, err)}
+// seedWorktreeCmd runs create and fills the worktree it adds with the
+// repository's copy_on_create and symlink_on_create paths, then runs its
+// post_create hook. The worktree is marked as created with template.
+func seedWorktreeCmd(cfg model.Config, template string, create tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		msg := create()
+		added, ok := msg.(WorktreeAddedMsg)
+		if !ok {
+			return msg
+		}
+		added.Template = template
+		for _, repo := range cfg.Repositories {
+			if repo.Path != added.RepoPath {
+				continue
 			}
+			if err := seed.Apply(repo.Path, added.WorktreePath, repo.CopyOnCreate, repo.SymlinkOnCreate); err != nil {
+				logging.For("worktree").Warn("seeding failed (non-fatal)", "path", added.WorktreePath, "err", err)
+			}
+		}
+		env := hooks.Env{Worktree: added.WorktreePath, Branch: added.Branch, Repo: added.RepoPath}
+		if err := hooks.Run(hooks.For(cfg, added.RepoPath).PostCreate, env); err != nil {
+			added.HookErr = fmt.Errorf("post_create hook: %w", err)
+		}
+		return added
+	}
+}
+
+// addWorktreeWithNameCmd creates "<user>/<name>" off baseRef for a name typed
+// into the add-worktree prompt, with prefix in place of the user when set.
+// A name that is already a local or origin branch, such as "develop", checks
+// that branch out instead.
+func addWorktreeWithNameCmd(runner git.CommandRunner, repoPath, basePath, repoName, baseRef, prefix, name string) tea.Cmd {
+	return func() tea.Msg {
+		if branch, ok := git.FindBranch(runner, repoPath, name); ok {
+			return addWorktreeFromExistingBranchCmd(runner, repoPath, basePath, repoName, branch)()
+		}
+		slug := branchname.SanitizeBranchName(name)
+		if slug == "" {
+			return WorktreeAddErrMsg{Err: fmt.Errorf("%q is not a usable branch name", name)}
+		}
+		userSlug, err := branchPrefix(runner, repoPath, prefix)
+		if err != nil {
+			return WorktreeAddErrMsg{Err: err}
+		}
+		msg := createWorktreeFromBase(runner, repoPath, basePath, repoName, baseRef, userSlug, slug)
+		if added, ok := msg.(WorktreeAddedMsg); ok {
+			added.Named = true
+			return added
+		}
+		return msg
+	}
+}
+
+// addWorktreeFromIssueCmd creates a branch named "<user>/<number>-<slug>" for
+// a GitHub issue and seeds a todo pointing back at the issue. prefix, when
+// set, takes the place of the user.
+func addWorktreeFromIssueCmd(runner git.CommandRunner, ghRunner github.Runner, gen branchname.Generator, todos TodoStore, repoPath, basePath, repoName, baseRef, prefix, rawURL string) tea.Cmd {
+	return func() tea.Msg {
+		issue, err := github.FetchIssue(ghRunner, repoPath, rawURL)
+		if err != nil {
+			return WorktreeAddErrMsg{Err: err}
+		}
+		userSlug, err := branchPrefix(runner, repoPath, prefix)
+		if err != nil {
+			return WorktreeAddErrMsg{Err: err}
 		}
 
-		if err := os.MkdirAll(filepath.Join(basePath, repoName), 0o755); err != nil {
-			return WorktreeAddErrMsg{Err: fmt.Errorf("creating parent directory: %w", err)}
+		slug := fmt.Sprintf("%d-%s", issue.Number, issueSlug(gen, issue.Title))
+		msg := createWorktreeFromBase(runner, repoPath, basePath, repoName, baseRef, userSlug, slug)
+		added, ok := msg.(WorktreeAddedMsg)
+		if !ok {
+			return msg
 		}
+		added.Issue = issue.Number
 
-		const maxRetries = 10
-		for attempt := 1; attempt <= maxRetries; attempt++ {
-			slug := baseSlug
-			if attempt > 1 {
-				slug = fmt.Sprintf("%s-%d", baseSlug, attempt)
+		if todos != nil {
+			todo := fmt.Sprintf("Resolve #%d: %s", issue.Number, issue.Title)
+			if err := todos.Add(added.WorktreePath, todo); err != nil {
+				logging.For("worktree").Warn("seeding todo failed (non-fatal)", "path", added.WorktreePath, "err", err)
 			}
-			branch := userSlug + "/" + slug
-			newPath := filepath.Join(basePath, repoName, slug)
-			createdAt := time.Now().UnixMilli()
+		}
+		return added
+	}
+}
 
-			if err := git.AddWorktree(runner, repoPath, newPath, branch, baseRef); err != nil {
-				if git.IsBranchExistsError(err) {
-					continue
-				}
-				return WorktreeAddErrMsg{Err: err}
+// issueSlug names a branch after an issue title, asking gen for a concise name
+// and falling back to the sanitized title.
+func issueSlug(gen branchname.Generator, title string) string {
+	if gen != nil {
+		if name, err := gen.GenerateBranchName(title); err == nil {
+			if slug := branchname.SanitizeBranchName(name); slug != "" {
+				return slug
 			}
+		} else {
+			logging.For("worktree").Warn("GenerateBranchName for issue failed, using title", "err", err)
+		}
+	}
+	if slug := branchname.SanitizeBranchName(title); slug != "" {
+		return slug
+	}
+	return "issue"
+}
+
+// branchPrefix returns prefix, a template's branch prefix, or the one derived
+// from the git user name when it is empty.
+func branchPrefix(runner git.CommandRunner, repoPath, prefix string) (string, error) {
+	if prefix != "" {
+		return prefix, nil
+	}
+	return branchUserSlug(runner, repoPath)
+}
+
+// branchUserSlug returns the branch prefix derived from the git user name.
+func branchUserSlug(runner git.CommandRunner, repoPath string) (string, error) {
+	userName, err := git.GetUserName(runner, repoPath)
+	if err != nil {
+		return "", err
+	}
+	userSlug := branchname.SanitizeBranchName(userName)
+	if userSlug == "" {
+		userSlug = "user"
+	}
+	return userSlug, nil
+}
+
+// createWorktreeFromBase creates "<userSlug>/<baseSlug>" off baseRef, adding a
+// numeric suffix while the branch already exists.
+func createWorktreeFromBase(runner git.CommandRunner, repoPath, basePath, repoName, baseRef, userSlug, baseSlug string) tea.Msg {
+	if fetchBranch, ok := strings.CutPrefix(baseRef, "origin/"); ok {
+		if err := git.FetchBranch(runner, repoPath, fetchBranch); err != nil {
+			return WorktreeAddErrMsg{Err: fmt.Errorf("fetching %s: %w", baseRef, err)}
+		}
+	}
 
-			return WorktreeAddedMsg{
-				WorktreePath: newPath,
-				Branch:       branch,
-				CreatedAt:    createdAt,
+	if err := os.MkdirAll(filepath.Join(basePath, repoName), 0o755); err != nil {
+		return WorktreeAddErrMsg{Err: fmt.Errorf("creating parent directory: %w", err)}
+	}
+
+	const maxRetries = 10
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		slug := baseSlug
+		if attempt > 1 {
+			slug = fmt.Sprintf("%s-%d", baseSlug, attempt)
+		}
+		branch := userSlug + "/" + slug
+		newPath := filepath.Join(basePath, repoName, slug)
+		if pathExists(newPath) {
+			continue
+		}
+		createdAt := time.Now().UnixMilli()
+
+		if err := git.AddWorktree(runner, repoPath, newPath, branch, baseRef); err != nil {
+			if git.IsBranchExistsError(err) {
+				continue
 			}
+			return WorktreeAddErrMsg{Err: err}
 		}
 
-		return WorktreeAddErrMsg{
-			Err: fmt.Errorf("could not create worktree for %q: branch already exists after %d attempts", baseSlug, maxRetries),
+		return WorktreeAddedMsg{
+			RepoPath:     repoPath,
+			WorktreePath: newPath,
+			Branch:       branch,
+			CreatedAt:    createdAt,
 		}
 	}
+
+	return WorktreeAddErrMsg{
+		Err: fmt.Errorf("could not create worktree for %q: branch or directory already exists after %d attempts", baseSlug, maxRetries),
+	}
 }
 
-func addWorktreeFromURLCmd(runner git.CommandRunner, ghRunner github.Runner, repoPath, basePath, repoName, rawURL string) tea.Cmd {
+func addWorktreeFromURLCmd(runner git.CommandRunner, provider forge.Provider, repoPath, basePath, repoName, rawURL string) tea.Cmd {
 	return func() tea.Msg {
-		urlInfo, err := github.ParseGitHubURL(rawURL)
+		branch, err := provider.ResolveBranch(repoPath, rawURL)
 		if err != nil {
-			return WorktreeAddErrMsg{Err: fmt.Errorf("invalid URL: %w", err)}
-		}
-
-		var branch string
-		switch urlInfo.Type {
-		case github.URLTypeBranch:
-			branch = urlInfo.Branch
-		case github.URLTypePR:
-			if ghRunner == nil {
-				return WorktreeAddErrMsg{Err: fmt.Errorf("gh CLI is not available; cannot resolve PR URL")}
-			}
-			prBranch, err := github.FetchPRBranch(ghRunner, repoPath, rawURL)
-			if err != nil {
-				return WorktreeAddErrMsg{Err: fmt.Errorf("resolving PR branch: %w", err)}
-			}
-			branch = prBranch
+			return WorktreeAddErrMsg{Err: err}
 		}
 
 		return createWorktreeFromBranch(runner, repoPath, basePath, repoName, branch)
@@ -785,42 +1736,72 @@ func createWorktreeFromBranch(runner git.CommandRunner, repoPath, basePath, repo
 		return WorktreeAddErrMsg{Err: fmt.Errorf("fetching branch %q: %w", branch, err)}
 	}
 
-	slug := github.BranchSlug(branch)
-	newPath := filepath.Join(basePath, repoName, slug)
+	return checkoutWorktree(runner, repoPath, basePath, repoName, branch, "")
+}
+
+// checkoutWorktree adds a worktree for branch under basePath/repoName, in a
+// directory named after the branch slug. A non-empty remoteRef creates branch
+// as a new local branch tracking it.
+func checkoutWorktree(runner git.CommandRunner, repoPath, basePath, repoName, branch, remoteRef string) tea.Msg {
+	newPath := uniqueWorktreePath(filepath.Join(basePath, repoName), github.BranchSlug(branch))
 
 	if err := os.MkdirAll(filepath.Dir(newPath), 0o755); err != nil {
 		return WorktreeAddErrMsg{Err: fmt.Errorf("creating parent directory: %w", err)}
 	}
 
-	if err := git.AddWorktreeFromBranch(runner, repoPath, newPath, branch); err != nil {
+	var err error
+	if remoteRef != "" {
+		err = git.AddWorktreeTracking(runner, repoPath, newPath, branch, remoteRef)
+	} else {
+		err = git.AddWorktreeFromBranch(runner, repoPath, newPath, branch)
+	}
+	if err != nil {
 		return WorktreeAddErrMsg{Err: fmt.Errorf("creating worktree: %w", err)}
 	}
 
 	return WorktreeAddedMsg{
+		RepoPath:     repoPath,
 		WorktreePath: newPath,
 		Branch:       branch,
 		CreatedAt:    time.Now().UnixMilli(),
 	}
 }
 
+// uniqueWorktreePath returns parent/slug, or parent/slug-N for the first N
+// from 2 that is free, so branches whose slugs collide (e.g. "alice/fix" and
+// "bob/fix") get separate directories. The suffix matches the one
+// createWorktreeFromBase uses for colliding branch names.
+func uniqueWorktreePath(parent, slug string) string {
+	path := filepath.Join(parent, slug)
+	for n := 2; pathExists(path); n++ {
+		path = filepath.Join(parent, fmt.Sprintf("%s-%d", slug, n))
+	}
+	return path
+}
+
+func pathExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
 func checkPromptCmd(reader claude.Reader, worktreePath string, createdAt int64) tea.Cmd {
 	return func() tea.Msg {
 		data, err := reader.ReadHistoryFile()
 		if err != nil {
-			log.Printf("[branch-rename] checkPrompt: ReadHistoryFile error: %v", err)
+			logging.For("branch-rename").Error("checkPrompt: ReadHistoryFile", "err", err)
 			return nil
 		}
 		entries, err := claude.ParseHistory(data)
 		if err != nil {
-			log.Printf("[branch-rename] checkPrompt: ParseHistory error: %v", err)
+			logging.For("branch-rename").Error("checkPrompt: ParseHistory", "err", err)
 			return nil
 		}
 		prompt, sessionID, found := claude.FindFirstPrompt(entries, worktreePath, createdAt)
 		if !found {
-			log.Printf("[branch-rename] checkPrompt: no prompt found for path=%q afterTimestamp=%d (entries=%d)", worktreePath, createdAt, len(entries))
+			logging.For("branch-rename").Debug("checkPrompt: no prompt found", "path", worktreePath, "after_timestamp", createdAt, "entries", len(entries))
 			return nil
 		}
-		log.Printf("[branch-rename] checkPrompt: found prompt=%q sessionID=%q for path=%q", prompt, sessionID, worktreePath)
+		logging.For("branch-rename").Info("checkPrompt: found prompt", "prompt", prompt, "session_id", sessionID, "path", worktreePath)
 		return BranchRenameStartMsg{
 			WorktreePath: worktreePath,
 			Prompt:       prompt,
@@ -829,25 +1810,19 @@ func checkPromptCmd(reader claude.Reader, worktreePath string, createdAt int64)
 	}
 }
 
-func renameBranchCmd(gen branchname.Generator, runner git.CommandRunner, tmuxRunner tmux.Runner, worktreePath, originalBranch, prompt string) tea.Cmd {
+func renameBranchCmd(gen branchname.Generator, namer branchname.Namer, runner git.CommandRunner, tmuxRunner tmux.Runner, auditLog audit.Log, worktreePath, originalBranch, prompt string) tea.Cmd {
 	return func() tea.Msg {
-		log.Printf("[branch-rename] renameBranch: generating name for prompt=%q", prompt)
+		logging.For("branch-rename").Debug("renameBranch: generating name", "prompt", prompt)
 		name, err := gen.GenerateBranchName(prompt)
 		if err != nil {
-			log.Printf("[branch-rename] renameBranch: GenerateBranchName error: %v", err)
+			logging.For("branch-rename").Error("renameBranch: GenerateBranchName", "err", err)
 			return BranchRenameResultMsg{WorktreePath: worktreePath, Err: err}
 		}
 
-		sanitized := branchname.SanitizeBranchName(name)
-		if sanitized == "" {
-			log.Printf("[branch-rename] renameBranch: SanitizeBranchName returned empty for raw=%q", name)
-			return BranchRenameResultMsg{WorktreePath: worktreePath, Err: fmt.Errorf("generated branch name is empty")}
-		}
-
-		// Preserve username prefix: "shoji/south-korea" -> "shoji/fix-login"
-		newBranch := sanitized
-		if parts := strings.SplitN(originalBranch, "/", 2); len(parts) == 2 {
-			newBranch = parts[0] + "/" + sanitized
+		newBranch, err := namer.BranchName(originalBranch, prompt, name)
+		if err != nil {
+			logging.For("branch-rename").Error("renameBranch: BranchName", "raw", name, "err", err)
+			return BranchRenameResultMsg{WorktreePath: worktreePath, Err: err}
 		}
 
 		// Resolve the actual tmux session name before git rename (session may have been renamed)
@@ -866,22 +1841,24 @@ func renameBranchCmd(gen branchname.Generator, runner git.CommandRunner, tmuxRun
 			oldSessionName = tmux.ResolveSessionName(tmuxRunner, worktreePath, getBranch)
 		}
 
-		log.Printf("[branch-rename] renameBranch: renaming %q -> %q in %q", originalBranch, newBranch, worktreePath)
-		if err := git.RenameBranch(runner, worktreePath, originalBranch, newBranch); err != nil {
-			log.Printf("[branch-rename] renameBranch: RenameBranch error: %v", err)
+		logging.For("branch-rename").Debug("renameBranch: renaming", "from", originalBranch, "to", newBranch, "path", worktreePath)
+		err = git.RenameBranch(runner, worktreePath, originalBranch, newBranch)
+		auditLog.Record(audit.Event{Op: audit.OpRename, Path: worktreePath, Branch: originalBranch, Detail: newBranch}, err)
+		if err != nil {
+			logging.For("branch-rename").Error("renameBranch: RenameBranch", "err", err)
 			return BranchRenameResultMsg{WorktreePath: worktreePath, Err: err}
 		}
 
-		log.Printf("[branch-rename] renameBranch: success %q -> %q", originalBranch, newBranch)
+		logging.For("branch-rename").Info("renameBranch: success", "from", originalBranch, "to", newBranch)
 
 		// Rename tmux session to match the new branch slug (non-fatal)
 		if tmuxRunner != nil && oldSessionName != "" {
-			newSessionName := branchname.SlugFromBranch(newBranch)
+			newSessionName := tmux.WorktreeSessionName(worktreePath, branchname.SlugFromBranch(newBranch))
 			if newSessionName != oldSessionName {
-				if err := tmux.RenameSession(tmuxRunner, oldSessionName, newSessionName); err != nil {
-					log.Printf("[branch-rename] renameBranch: tmux rename-session failed (non-fatal): %v", err)
+				if err := tmux.RenameWorktree(tmuxRunner, oldSessionName, newSessionName); err != nil {
+					logging.For("branch-rename").Warn("renameBranch: tmux rename-session failed (non-fatal)", "err", err)
 				} else {
-					log.Printf("[branch-rename] renameBranch: tmux session renamed %q -> %q", oldSessionName, newSessionName)
+					logging.For("branch-rename").Info("renameBranch: tmux session renamed", "from", oldSessionName, "to", newSessionName)
 				}
 			}
 		}
@@ -913,7 +1890,10 @@ func validateRepoCmd(runner git.CommandRunner, inputPath string) tea.Cmd {
 
 		root, err := runner.Run(expanded, "rev-parse", "--show-toplevel")
 		if err != nil {
-			return RepoValidationErrMsg{Err: fmt.Errorf("not a git repository: %s", expanded)}
+			if errors.Is(err, git.ErrPermissionDenied) || errors.Is(err, git.ErrGitNotFound) {
+				return RepoValidationErrMsg{Err: err}
+			}
+			return RepoValidationErrMsg{Err: fmt.Errorf("%w: %s", git.ErrNotARepository, expanded)}
 		}
 
 		root = strings.TrimSpace(root)
@@ -931,73 +1911,44 @@ func addRepoToConfigCmd(configPath, name, repoPath string) tea.Cmd {
 	}
 }
 
-func agentTickCmd() tea.Cmd {
-	return tea.Tick(agentPollInterval, func(t time.Time) tea.Msg {
-		return AgentTickMsg(t)
-	})
-}
-
-func fetchAgentStatusCmd(tmuxRunner tmux.Runner, gitRunner git.CommandRunner, groups []model.RepoGroup) tea.Cmd {
+// fetchAgentStatusCmd polls the agents of every worktree's session, with one
+// list-panes for the whole server plus a capture-pane per agent pane.
+func fetchAgentStatusCmd(tmuxRunner tmux.Runner, profiles []agent.Profile, groups []model.RepoGroup) tea.Cmd {
 	return func() tea.Msg {
-		var getBranch tmux.BranchGetter
-		if gitRunner != nil {
-			getBranch = func(worktreePath string) (string, error) {
-				out, err := gitRunner.Run(worktreePath, "symbolic-ref", "--short", "HEAD")
-				if err != nil {
-					return "", err
-				}
-				return strings.TrimSpace(out), nil
+		bySession, err := agent.DetectAllAgents(tmuxRunner, profiles)
+		if err != nil {
+			if tmux.IsUnavailable(err) {
+				return AgentStatusMsg{Err: err}
 			}
+			logging.For("agent").Warn("listing panes failed", "err", err)
+		}
+		exists := make(map[string]bool, len(bySession))
+		for name := range bySession {
+			exists[name] = true
 		}
 
 		statuses := make(map[string][]model.AgentInfo)
+		sessions := make(map[string]string)
 		for _, group := range groups {
 			for _, wt := range group.Worktrees {
-				sessionName := tmux.ResolveSessionName(tmuxRunner, wt.Path, getBranch)
-				agents, err := agent.DetectSessionAgents(tmuxRunner, sessionName)
-				if err != nil {
-					continue
-				}
-				if len(agents) > 0 {
+				sessionName := tmux.MatchSessionName(exists, wt.Path, wt.Branch)
+				sessions[wt.Path] = sessionName
+				if agents := bySession[sessionName]; len(agents) > 0 {
 					statuses[wt.Path] = agents
 				}
 			}
 		}
-		return AgentStatusMsg{Statuses: statuses}
-	}
-}
-
-func fetchGitDataCmd(cfg model.Config, runner git.CommandRunner) tea.Cmd {
-	return func() tea.Msg {
-		var groups []model.RepoGroup
-
-		baseRef := cfg.DefaultBaseRef
-		if baseRef == "" {
-			baseRef = config.DefaultBaseRef
-		}
 
-		for _, repoDef := range cfg.Repositories {
-			entries, err := git.ListWorktrees(runner, repoDef.Path)
-			if err != nil {
-				return GitDataErrMsg{Err: err}
-			}
-
-			worktrees := git.ToWorktreeInfo(entries)
-			for i := range worktrees {
-				status, err := git.GetBranchDiffStat(runner, worktrees[i].Path, baseRef)
-				if err != nil {
-					return GitDataErrMsg{Err: err}
+		// Activity only feeds the "activity" sort order, so a failed query
+		// just leaves it unsorted.
+		activity := make(map[string]time.Time)
+		if sessionActivity, err := tmux.WorktreeActivity(tmuxRunner); err == nil {
+			for path, name := range sessions {
+				if t, ok := sessionActivity[name]; ok {
+					activity[path] = t
 				}
-				worktrees[i].Status = status
 			}
-
-			groups = append(groups, model.RepoGroup{
-				Name:      repoDef.Name,
-				RootPath:  repoDef.Path,
-				Worktrees: worktrees,
-			})
 		}
-
-		return GitDataMsg{Groups: groups}
+		return AgentStatusMsg{Statuses: statuses, Activity: activity}
 	}
 }
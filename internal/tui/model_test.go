@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -11,13 +12,17 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/mikanfactory/yakumo/internal/audit"
 	"github.com/mikanfactory/yakumo/internal/branchname"
 	"github.com/mikanfactory/yakumo/internal/claude"
+	"github.com/mikanfactory/yakumo/internal/clipboard"
+	"github.com/mikanfactory/yakumo/internal/forge"
 	"github.com/mikanfactory/yakumo/internal/git"
 	"github.com/mikanfactory/yakumo/internal/github"
 	"github.com/mikanfactory/yakumo/internal/model"
 	"github.com/mikanfactory/yakumo/internal/sidebar"
 	"github.com/mikanfactory/yakumo/internal/tmux"
+	"github.com/mikanfactory/yakumo/internal/trash"
 )
 
 func testModel() Model {
@@ -227,6 +232,75 @@ func TestUpdate_GitDataErrMsg(t *testing.T) {
 	}
 }
 
+func TestUpdate_GitDataErrMsg_RetriesLockedIndex(t *testing.T) {
+	m := Model{loading: true}
+	lockErr := fmt.Errorf("listing worktrees: %w", git.ErrIndexLocked)
+
+	result, cmd := m.Update(GitDataErrMsg{Err: lockErr})
+	updated := result.(Model)
+
+	if updated.err != nil {
+		t.Errorf("err should not be set while retrying, got %v", updated.err)
+	}
+	if !updated.loading {
+		t.Error("loading should stay true while retrying")
+	}
+	if updated.gitRetries != 1 {
+		t.Errorf("gitRetries = %d, want 1", updated.gitRetries)
+	}
+	if cmd == nil {
+		t.Fatal("expected a retry command")
+	}
+
+	updated.gitRetries = maxGitRetries
+	result, _ = updated.Update(GitDataErrMsg{Err: lockErr})
+	if result.(Model).err == nil {
+		t.Error("err should be set once retries are exhausted")
+	}
+}
+
+func TestUpdate_AgentStatusMsg_TmuxUnavailable(t *testing.T) {
+	m := testModel()
+	m.agentTickRunning = true
+
+	result, cmd := m.Update(AgentStatusMsg{Err: fmt.Errorf("has-session: %w", tmux.ErrNoServer)})
+	updated := result.(Model)
+
+	if cmd != nil {
+		t.Error("agent polling should stop when tmux is unavailable")
+	}
+	if updated.agentTickRunning || !updated.agentUnavailable {
+		t.Errorf("agentTickRunning = %v, agentUnavailable = %v; want false, true", updated.agentTickRunning, updated.agentUnavailable)
+	}
+}
+
+func TestErrorHint(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "not a repository", err: fmt.Errorf("%w: /tmp", git.ErrNotARepository), want: "git repository"},
+		{name: "tmux server", err: tmux.ErrNoServer, want: "tmux"},
+		{name: "gh auth", err: github.ErrNotAuthenticated, want: "gh auth login"},
+		{name: "unclassified", err: fmt.Errorf("boom"), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errorHint(tt.err)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("errorHint() = %q, want empty", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("errorHint() = %q, want it to mention %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSelected(t *testing.T) {
 	m := Model{selected: "/some/path"}
 	if m.Selected() != "/some/path" {
@@ -243,7 +317,7 @@ func TestNewModel(t *testing.T) {
 	}
 	runner := &fakeRunner{}
 
-	m := NewModel(cfg, runner, "/tmp/config.yaml", nil, nil, nil, nil)
+	m := NewModel(cfg, runner, "/tmp/config.yaml", nil, forge.Options{}, nil, nil)
 
 	if m.sidebarWidth != 35 {
 		t.Errorf("sidebarWidth = %d, want 35", m.sidebarWidth)
@@ -264,7 +338,7 @@ func TestInit_ReturnsCmd(t *testing.T) {
 		},
 	}
 	runner := &fakeRunner{}
-	m := NewModel(cfg, runner, "", nil, nil, nil, nil)
+	m := NewModel(cfg, runner, "", nil, forge.Options{}, nil, nil)
 
 	cmd := m.Init()
 	if cmd == nil {
@@ -397,8 +471,8 @@ func TestUpdate_WorktreeAddErrMsg(t *testing.T) {
 	if updated.loading {
 		t.Error("loading should be false after WorktreeAddErrMsg")
 	}
-	if updated.err == nil {
-		t.Error("err should be set")
+	if updated.err != nil || updated.toast.Severity != SeverityError || len(updated.errorLog) != 1 {
+		t.Errorf("the error should be a toast and logged, got err=%v toast=%+v", updated.err, updated.toast)
 	}
 }
 
@@ -432,7 +506,7 @@ func TestAddWorktreeCmd_Success(t *testing.T) {
 		},
 	}
 
-	cmd := addWorktreeCmd(runner, "/repo", "/tmp/yakumo", "myrepo", "origin/main")
+	cmd := addWorktreeCmd(runner, "/repo", "/tmp/yakumo", "myrepo", "origin/main", "")
 	msg := cmd()
 
 	// The command will fail at AddWorktree because FakeCommandRunner won't have
@@ -456,7 +530,7 @@ func TestAddWorktreeCmd_FetchError(t *testing.T) {
 		},
 	}
 
-	cmd := addWorktreeCmd(runner, "/repo", "/tmp/yakumo", "myrepo", "origin/main")
+	cmd := addWorktreeCmd(runner, "/repo", "/tmp/yakumo", "myrepo", "origin/main", "")
 	msg := cmd()
 
 	errMsg, ok := msg.(WorktreeAddErrMsg)
@@ -480,7 +554,7 @@ func TestAddWorktreeCmd_NoFetchForNonOriginBaseRef(t *testing.T) {
 
 	// baseRef without "origin/" prefix should skip fetch.
 	// If fetch were attempted, FakeCommandRunner would fail with a "fetching" error.
-	cmd := addWorktreeCmd(runner, "/repo", "/tmp/yakumo", "myrepo", "main")
+	cmd := addWorktreeCmd(runner, "/repo", "/tmp/yakumo", "myrepo", "main", "")
 	msg := cmd()
 
 	// Should fail at AddWorktree (random country key not registered), not at fetch
@@ -500,7 +574,7 @@ func TestAddWorktreeCmd_UserNameError(t *testing.T) {
 		},
 	}
 
-	cmd := addWorktreeCmd(runner, "/repo", "/tmp/yakumo", "myrepo", "origin/main")
+	cmd := addWorktreeCmd(runner, "/repo", "/tmp/yakumo", "myrepo", "origin/main", "")
 	msg := cmd()
 
 	errMsg, ok := msg.(WorktreeAddErrMsg)
@@ -512,11 +586,59 @@ func TestAddWorktreeCmd_UserNameError(t *testing.T) {
 	}
 }
 
+func TestSeedWorktreeCmd(t *testing.T) {
+	repo, wt := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, ".env"), []byte("PORT=3000"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := model.Config{Repositories: []model.RepositoryDef{{Name: "myrepo", Path: repo, CopyOnCreate: []string{".env"}}}}
+
+	added := WorktreeAddedMsg{RepoPath: repo, WorktreePath: wt, Branch: "alice/feat"}
+	msg := seedWorktreeCmd(cfg, "", func() tea.Msg { return added })()
+	if msg != added {
+		t.Errorf("msg = %#v, want the WorktreeAddedMsg passed through", msg)
+	}
+	if data, err := os.ReadFile(filepath.Join(wt, ".env")); err != nil || string(data) != "PORT=3000" {
+		t.Errorf(".env in the new worktree = %q, %v", data, err)
+	}
+
+	failed := WorktreeAddErrMsg{Err: fmt.Errorf("branch exists")}
+	if msg := seedWorktreeCmd(cfg, "", func() tea.Msg { return failed })(); msg != tea.Msg(failed) {
+		t.Errorf("msg = %#v, want the error passed through", msg)
+	}
+}
+
+func TestSeedWorktreeCmd_PostCreateHook(t *testing.T) {
+	wt := t.TempDir()
+	cfg := model.Config{
+		Hooks:        model.Hooks{PostCreate: `echo "$YAKUMO_BRANCH" > hooked`},
+		Repositories: []model.RepositoryDef{{Name: "myrepo", Path: "/repo"}},
+	}
+	added := WorktreeAddedMsg{RepoPath: "/repo", WorktreePath: wt, Branch: "alice/feat"}
+	if msg := seedWorktreeCmd(cfg, "", func() tea.Msg { return added })().(WorktreeAddedMsg); msg.HookErr != nil {
+		t.Fatalf("HookErr = %v", msg.HookErr)
+	}
+	if data, err := os.ReadFile(filepath.Join(wt, "hooked")); err != nil || string(data) != "alice/feat\n" {
+		t.Errorf("post_create hook wrote %q, %v", data, err)
+	}
+
+	cfg.Hooks.PostCreate = "exit 1"
+	msg := seedWorktreeCmd(cfg, "", func() tea.Msg { return added })().(WorktreeAddedMsg)
+	if msg.HookErr == nil {
+		t.Fatal("expected the failed hook to be reported")
+	}
+	m, _ := testModel().Update(msg)
+	if toast := m.(Model).toast; toast.Severity != SeverityError || !strings.Contains(toast.Text, "post_create") {
+		t.Errorf("toast = %+v, want the hook failure", toast)
+	}
+}
+
 func TestFetchGitDataCmd_Success(t *testing.T) {
 	runner := git.FakeCommandRunner{
 		Outputs: map[string]string{
-			"/repo:[worktree list --porcelain]":         "worktree /repo\nHEAD abc123\nbranch refs/heads/main\n\n",
-			"/repo:[diff origin/main...HEAD --numstat]": "",
+			"/repo:[worktree list --porcelain]":                        "worktree /repo\nHEAD abc123\nbranch refs/heads/main\n\n",
+			"/repo:[diff origin/main...HEAD --numstat]":                "",
+			"/repo:[rev-list --left-right --count origin/main...HEAD]": "0\t0\n",
 		},
 	}
 
@@ -527,7 +649,7 @@ func TestFetchGitDataCmd_Success(t *testing.T) {
 		},
 	}
 
-	cmd := fetchGitDataCmd(cfg, runner)
+	cmd := fetchGitDataCmd(cfg, runner, nil)
 	msg := cmd()
 
 	dataMsg, ok := msg.(GitDataMsg)
@@ -552,7 +674,7 @@ func TestFetchGitDataCmd_Error(t *testing.T) {
 		},
 	}
 
-	cmd := fetchGitDataCmd(cfg, runner)
+	cmd := fetchGitDataCmd(cfg, runner, nil)
 	msg := cmd()
 
 	_, ok := msg.(GitDataErrMsg)
@@ -1001,12 +1123,10 @@ func TestUpdate_AgentStatusMsg_Empty(t *testing.T) {
 func TestFetchAgentStatusCmd(t *testing.T) {
 	runner := &tmux.FakeRunner{
 		Outputs: map[string]string{
-			fmt.Sprintf("%v", []string{"has-session", "-t", "=repo1"}):                                                                 "",
-			fmt.Sprintf("%v", []string{"list-panes", "-s", "-t", "repo1", "-F", "#{pane_id}\t#{pane_title}\t#{pane_current_command}"}): "%0\t✳ claude\tnode\n",
-			fmt.Sprintf("%v", []string{"capture-pane", "-p", "-t", "%0"}):                                                              "  ❯ ",
-		},
-		Errors: map[string]error{
-			fmt.Sprintf("%v", []string{"has-session", "-t", "=repo1-feat"}): fmt.Errorf("no session"),
+			fmt.Sprintf("%v", []string{"list-panes", "-a", "-F", "#{session_name}\t#{pane_id}\t#{pane_title}\t#{pane_current_command}"}): "repo1\t%0\t✳ claude\tnode\nlogin\t%1\t✳ claude\tnode\nother\t%2\t✳ claude\tnode\n",
+			fmt.Sprintf("%v", []string{"capture-pane", "-p", "-t", "%0"}):                                                                "  ❯ ",
+			fmt.Sprintf("%v", []string{"capture-pane", "-p", "-t", "%1"}):                                                                "  ❯ ",
+			fmt.Sprintf("%v", []string{"capture-pane", "-p", "-t", "%2"}):                                                                "  ❯ ",
 		},
 	}
 
@@ -1017,6 +1137,7 @@ func TestFetchAgentStatusCmd(t *testing.T) {
 			Worktrees: []model.WorktreeInfo{
 				{Path: "/code/repo1", Branch: "main"},
 				{Path: "/code/repo1-feat", Branch: "feature"},
+				{Path: "/code/repo1-login", Branch: "shoji/login"},
 			},
 		},
 	}
@@ -1034,6 +1155,36 @@ func TestFetchAgentStatusCmd(t *testing.T) {
 	if len(statusMsg.Statuses["/code/repo1-feat"]) != 0 {
 		t.Errorf("expected 0 agents for /code/repo1-feat, got %d", len(statusMsg.Statuses["/code/repo1-feat"]))
 	}
+	if len(statusMsg.Statuses["/code/repo1-login"]) != 1 {
+		t.Errorf("expected the branch session's agent for /code/repo1-login, got %d", len(statusMsg.Statuses["/code/repo1-login"]))
+	}
+
+	listCalls := 0
+	for _, call := range runner.Calls {
+		switch call[0] {
+		case "list-panes":
+			listCalls++
+		case "has-session":
+			t.Errorf("unexpected has-session call: %v", call)
+		}
+	}
+	if listCalls != 1 {
+		t.Errorf("list-panes called %d times, want 1", listCalls)
+	}
+}
+
+func TestFetchAgentStatusCmd_NoServer(t *testing.T) {
+	runner := &tmux.FakeRunner{
+		Errors: map[string]error{
+			fmt.Sprintf("%v", []string{"list-panes", "-a", "-F", "#{session_name}\t#{pane_id}\t#{pane_title}\t#{pane_current_command}"}): tmux.ErrNoServer,
+		},
+	}
+	groups := []model.RepoGroup{{Worktrees: []model.WorktreeInfo{{Path: "/code/repo1", Branch: "main"}}}}
+
+	msg := fetchAgentStatusCmd(runner, nil, groups)().(AgentStatusMsg)
+	if !tmux.IsUnavailable(msg.Err) {
+		t.Errorf("Err = %v, want tmux unavailable", msg.Err)
+	}
 }
 
 func TestUpdate_WorktreeAddedMsg_RegistersRename(t *testing.T) {
@@ -1263,7 +1414,7 @@ func TestRenameBranchCmd_Success(t *testing.T) {
 		},
 	}
 
-	cmd := renameBranchCmd(gen, runner, nil, "/tmp/worktree", "shoji/south-korea", "fix the login redirect bug")
+	cmd := renameBranchCmd(gen, branchname.Namer{}, runner, nil, audit.Log{}, "/tmp/worktree", "shoji/south-korea", "fix the login redirect bug")
 	msg := cmd()
 
 	resultMsg, ok := msg.(BranchRenameResultMsg)
@@ -1282,7 +1433,7 @@ func TestRenameBranchCmd_LLMError(t *testing.T) {
 	gen := branchname.FakeGenerator{Err: fmt.Errorf("api timeout")}
 	runner := git.FakeCommandRunner{}
 
-	cmd := renameBranchCmd(gen, runner, nil, "/tmp/worktree", "shoji/south-korea", "some prompt")
+	cmd := renameBranchCmd(gen, branchname.Namer{}, runner, nil, audit.Log{}, "/tmp/worktree", "shoji/south-korea", "some prompt")
 	msg := cmd()
 
 	resultMsg, ok := msg.(BranchRenameResultMsg)
@@ -1298,7 +1449,7 @@ func TestRenameBranchCmd_EmptyName(t *testing.T) {
 	gen := branchname.FakeGenerator{Result: ""}
 	runner := git.FakeCommandRunner{}
 
-	cmd := renameBranchCmd(gen, runner, nil, "/tmp/worktree", "shoji/south-korea", "some prompt")
+	cmd := renameBranchCmd(gen, branchname.Namer{}, runner, nil, audit.Log{}, "/tmp/worktree", "shoji/south-korea", "some prompt")
 	msg := cmd()
 
 	resultMsg, ok := msg.(BranchRenameResultMsg)
@@ -1324,7 +1475,7 @@ func TestRenameBranchCmd_WithTmuxRunner_RenamesSession(t *testing.T) {
 		},
 	}
 
-	cmd := renameBranchCmd(gen, runner, tmuxRunner, "/tmp/worktree", "shoji/south-korea", "fix the login redirect bug")
+	cmd := renameBranchCmd(gen, branchname.Namer{}, runner, tmuxRunner, audit.Log{}, "/tmp/worktree", "shoji/south-korea", "fix the login redirect bug")
 	msg := cmd()
 
 	resultMsg, ok := msg.(BranchRenameResultMsg)
@@ -1368,7 +1519,7 @@ func TestRenameBranchCmd_WithTmuxRunner_ResolvesSlugSession(t *testing.T) {
 		},
 	}
 
-	cmd := renameBranchCmd(gen, runner, tmuxRunner, "/tmp/saint-pierre-and-miquelon", "mikanfactory/saint-pierre-and-miquelon", "fix the diff UI error")
+	cmd := renameBranchCmd(gen, branchname.Namer{}, runner, tmuxRunner, audit.Log{}, "/tmp/saint-pierre-and-miquelon", "mikanfactory/saint-pierre-and-miquelon", "fix the diff UI error")
 	msg := cmd()
 
 	resultMsg, ok := msg.(BranchRenameResultMsg)
@@ -1412,7 +1563,7 @@ func TestRenameTimeout(t *testing.T) {
 func TestFeatureDisabled_NilDeps(t *testing.T) {
 	cfg := model.Config{SidebarWidth: 30}
 	runner := &fakeRunner{}
-	m := NewModel(cfg, runner, "", nil, nil, nil, nil)
+	m := NewModel(cfg, runner, "", nil, forge.Options{}, nil, nil)
 
 	if m.branchRenames != nil {
 		t.Error("branchRenames should be nil when feature is disabled")
@@ -1524,6 +1675,34 @@ func TestUpdate_ConfirmArchiveMode_Enter_Confirms(t *testing.T) {
 	}
 }
 
+func TestUpdate_ConfirmArchiveMode_HookFailed(t *testing.T) {
+	m := testModel()
+	m.confirmingArchive = true
+	m.archiveTarget = m.cursor
+	m.runner = &fakeRunner{}
+
+	// Without a failed hook, f does nothing.
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if updated := result.(Model); updated.loading || cmd != nil {
+		t.Error("f should not archive before the hook failed")
+	}
+
+	result, _ = m.Update(WorktreeArchiveErrMsg{Err: preArchiveError{fmt.Errorf("exit status 1")}, HookFailed: true})
+	updated := result.(Model)
+	if !updated.confirmingArchive || !updated.archiveHookFailed || updated.err == nil {
+		t.Fatalf("a failed hook should keep the dialog open with the error: %+v", updated.err)
+	}
+
+	result, cmd = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	updated = result.(Model)
+	if !updated.loading || cmd == nil {
+		t.Error("f should archive without the hook")
+	}
+	if updated.archiveHookFailed {
+		t.Error("archiveHookFailed should be reset")
+	}
+}
+
 func TestUpdate_ConfirmArchiveMode_CtrlC_Quits(t *testing.T) {
 	m := testModel()
 	m.confirmingArchive = true
@@ -1590,8 +1769,8 @@ func TestUpdate_WorktreeArchiveErrMsg(t *testing.T) {
 	if updated.loading {
 		t.Error("loading should be false after archive error")
 	}
-	if updated.err == nil {
-		t.Error("err should be set")
+	if updated.err != nil || updated.toast.Text != "remove failed" {
+		t.Errorf("the error should be a toast, got err=%v toast=%+v", updated.err, updated.toast)
 	}
 	if updated.confirmingArchive {
 		t.Error("confirmingArchive should be false after error")
@@ -1610,7 +1789,7 @@ func TestArchiveWorktreeCmd_Success(t *testing.T) {
 		},
 	}
 
-	cmd := archiveWorktreeCmd(runner, tmuxRunner, "/repo", "/tmp/old-worktree")
+	cmd := archiveWorktreeCmd(runner, tmuxRunner, trash.Trash{}, audit.Log{}, model.Config{}, "/repo", "/tmp/old-worktree", false)
 	msg := cmd()
 
 	if _, ok := msg.(WorktreeArchivedMsg); !ok {
@@ -1640,7 +1819,7 @@ func TestArchiveWorktreeCmd_Error(t *testing.T) {
 		},
 	}
 
-	cmd := archiveWorktreeCmd(runner, tmuxRunner, "/repo", "/tmp/old-worktree")
+	cmd := archiveWorktreeCmd(runner, tmuxRunner, trash.Trash{}, audit.Log{}, model.Config{}, "/repo", "/tmp/old-worktree", false)
 	msg := cmd()
 
 	errMsg, ok := msg.(WorktreeArchiveErrMsg)
@@ -1659,7 +1838,7 @@ func TestArchiveWorktreeCmd_NilTmuxRunner(t *testing.T) {
 		},
 	}
 
-	cmd := archiveWorktreeCmd(runner, nil, "/repo", "/tmp/old-worktree")
+	cmd := archiveWorktreeCmd(runner, nil, trash.Trash{}, audit.Log{}, model.Config{}, "/repo", "/tmp/old-worktree", false)
 	msg := cmd()
 
 	if _, ok := msg.(WorktreeArchivedMsg); !ok {
@@ -1681,7 +1860,7 @@ func TestArchiveWorktreeCmd_RemovesDirectory(t *testing.T) {
 		},
 	}
 
-	cmd := archiveWorktreeCmd(runner, nil, tmpDir, worktreePath)
+	cmd := archiveWorktreeCmd(runner, nil, trash.Trash{}, audit.Log{}, model.Config{}, tmpDir, worktreePath, false)
 	msg := cmd()
 
 	if _, ok := msg.(WorktreeArchivedMsg); !ok {
@@ -1716,7 +1895,7 @@ func TestArchiveWorktreeCmd_SwitchesToMainWhenInCurrentSession(t *testing.T) {
 		},
 	}
 
-	cmd := archiveWorktreeCmd(runner, tmuxRunner, "/repo", "/tmp/south-korea")
+	cmd := archiveWorktreeCmd(runner, tmuxRunner, trash.Trash{}, audit.Log{}, model.Config{}, "/repo", "/tmp/south-korea", false)
 	msg := cmd()
 
 	if _, ok := msg.(WorktreeArchivedMsg); !ok {
@@ -1841,7 +2020,7 @@ func TestAddWorktreeFromURLCmd_BranchURL(t *testing.T) {
 		},
 	}
 
-	cmd := addWorktreeFromURLCmd(runner, nil, "/repo", basePath, "myrepo", "https://github.com/owner/repo/tree/feature/my-branch")
+	cmd := addWorktreeFromURLCmd(runner, forge.GitHub{}, "/repo", basePath, "myrepo", "https://github.com/owner/repo/tree/feature/my-branch")
 	msg := cmd()
 
 	addedMsg, ok := msg.(WorktreeAddedMsg)
@@ -1859,7 +2038,7 @@ func TestAddWorktreeFromURLCmd_BranchURL(t *testing.T) {
 func TestAddWorktreeFromURLCmd_InvalidURL(t *testing.T) {
 	runner := git.FakeCommandRunner{}
 
-	cmd := addWorktreeFromURLCmd(runner, nil, "/repo", "/tmp/yakumo", "myrepo", "https://example.com/not-github")
+	cmd := addWorktreeFromURLCmd(runner, forge.GitHub{}, "/repo", "/tmp/yakumo", "myrepo", "https://example.com/not-github")
 	msg := cmd()
 
 	_, ok := msg.(WorktreeAddErrMsg)
@@ -1871,7 +2050,7 @@ func TestAddWorktreeFromURLCmd_InvalidURL(t *testing.T) {
 func TestAddWorktreeFromURLCmd_PR_NoGhRunner(t *testing.T) {
 	runner := git.FakeCommandRunner{}
 
-	cmd := addWorktreeFromURLCmd(runner, nil, "/repo", "/tmp/yakumo", "myrepo", "https://github.com/owner/repo/pull/42")
+	cmd := addWorktreeFromURLCmd(runner, forge.GitHub{}, "/repo", "/tmp/yakumo", "myrepo", "https://github.com/owner/repo/pull/42")
 	msg := cmd()
 
 	errMsg, ok := msg.(WorktreeAddErrMsg)
@@ -1904,7 +2083,7 @@ func TestAddWorktreeFromURLCmd_PR_WithGhRunner(t *testing.T) {
 		},
 	}
 
-	cmd := addWorktreeFromURLCmd(gitRunner, ghRunner, "/repo", basePath, "myrepo", prURL)
+	cmd := addWorktreeFromURLCmd(gitRunner, forge.GitHub{Runner: ghRunner}, "/repo", basePath, "myrepo", prURL)
 	msg := cmd()
 
 	addedMsg, ok := msg.(WorktreeAddedMsg)
@@ -1919,6 +2098,104 @@ func TestAddWorktreeFromURLCmd_PR_WithGhRunner(t *testing.T) {
 	}
 }
 
+type fakeTodoStore map[string][]string
+
+func (s fakeTodoStore) Add(worktreePath, todo string) error {
+	s[worktreePath] = append(s[worktreePath], todo)
+	return nil
+}
+
+func TestAddWorktreeFromIssueCmd(t *testing.T) {
+	issueURL := "https://github.com/owner/repo/issues/12"
+	ghKey := fmt.Sprintf("/repo:%v", []string{"issue", "view", issueURL, "--json", "number,title,url"})
+
+	tests := []struct {
+		name       string
+		gen        branchname.Generator
+		wantBranch string
+	}{
+		{name: "generated name", gen: branchname.FakeGenerator{Result: "fix-login-loop"}, wantBranch: "testuser/12-fix-login-loop"},
+		{name: "generator error falls back to title", gen: branchname.FakeGenerator{Err: fmt.Errorf("boom")}, wantBranch: "testuser/12-login-redirect-loops"},
+		{name: "no generator", gen: nil, wantBranch: "testuser/12-login-redirect-loops"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			basePath := t.TempDir()
+			slug := branchname.SlugFromBranch(tt.wantBranch)
+			wantPath := filepath.Join(basePath, "myrepo", slug)
+			gitRunner := git.FakeCommandRunner{
+				Outputs: map[string]string{
+					"/repo:[config user.name]":  "testuser\n",
+					"/repo:[fetch origin main]": "",
+					fmt.Sprintf("/repo:%v", []string{"worktree", "add", wantPath, "-b", tt.wantBranch, "origin/main"}): "",
+				},
+			}
+			ghRunner := &github.FakeRunner{
+				Outputs: map[string]string{
+					ghKey: `{"number":12,"title":"Login redirect loops","url":"` + issueURL + `"}`,
+				},
+			}
+			todos := fakeTodoStore{}
+
+			msg := addWorktreeFromIssueCmd(gitRunner, ghRunner, tt.gen, todos, "/repo", basePath, "myrepo", "origin/main", "", issueURL)()
+
+			added, ok := msg.(WorktreeAddedMsg)
+			if !ok {
+				t.Fatalf("expected WorktreeAddedMsg, got %T: %v", msg, msg)
+			}
+			if added.Branch != tt.wantBranch {
+				t.Errorf("Branch = %q, want %q", added.Branch, tt.wantBranch)
+			}
+			if added.Issue != 12 {
+				t.Errorf("Issue = %d, want 12", added.Issue)
+			}
+			if got := todos[wantPath]; len(got) != 1 || got[0] != "Resolve #12: Login redirect loops" {
+				t.Errorf("todos = %v, want seeded issue todo", got)
+			}
+		})
+	}
+}
+
+func TestAddWorktreeFromIssueCmd_FetchError(t *testing.T) {
+	issueURL := "https://github.com/owner/repo/issues/12"
+	ghRunner := &github.FakeRunner{}
+
+	msg := addWorktreeFromIssueCmd(git.FakeCommandRunner{}, ghRunner, nil, nil, "/repo", t.TempDir(), "myrepo", "origin/main", "", issueURL)()
+
+	if _, ok := msg.(WorktreeAddErrMsg); !ok {
+		t.Fatalf("expected WorktreeAddErrMsg, got %T", msg)
+	}
+}
+
+func TestUpdate_AddWorktreeMode_Enter_IssueURL(t *testing.T) {
+	t.Run("without GitHub runner", func(t *testing.T) {
+		m := testModel()
+		m.addingWorktree = true
+		m.addingWorktreeRepoPath = "/code/repo1"
+		m.textInput.SetValue("https://github.com/owner/repo/issues/12")
+
+		result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = result.(Model)
+		if cmd == nil {
+			t.Error("expected the toast timer")
+		}
+		if m.toast.Severity != SeverityError || m.loading {
+			t.Errorf("expected an error toast and loading=false, got toast=%+v loading=%v", m.toast, m.loading)
+		}
+	})
+
+	t.Run("skips rename for issue branches", func(t *testing.T) {
+		m := testModel()
+		m.branchRenames = map[string]model.BranchRenameInfo{}
+
+		result, _ := m.Update(WorktreeAddedMsg{WorktreePath: "/wt/12-fix", Branch: "u/12-fix", Issue: 12})
+		m = result.(Model)
+		if _, ok := m.branchRenames["/wt/12-fix"]; ok {
+			t.Error("issue branches should not be queued for rename")
+		}
+	})
+}
+
 func TestUpdate_AddWorktreeMode_Enter_BranchName_FetchesAndAdds(t *testing.T) {
 	m := testModel()
 	m.addingWorktree = true
@@ -2251,3 +2528,750 @@ func TestUpdate_WindowSizeMsg_LargeHeight_ResetsScrollOff(t *testing.T) {
 		t.Errorf("scrollOff should reset to 0 when viewport fits all items, got %d", updated.scrollOff)
 	}
 }
+
+func TestProviderForURL(t *testing.T) {
+	m := testModel()
+	m.config = model.Config{
+		Repositories: []model.RepositoryDef{
+			{Name: "repo1", Path: "/code/repo1"},
+			{Name: "repo2", Path: "/code/repo2", Forge: "bitbucket"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		repoPath string
+		url      string
+		want     string
+	}{
+		{name: "detected github", repoPath: "/code/repo1", url: "https://github.com/o/r/pull/1", want: forge.KindGitHub},
+		{name: "detected bitbucket", repoPath: "/code/repo1", url: "https://bitbucket.org/o/r/pull-requests/1", want: forge.KindBitbucket},
+		{name: "detected gitlab", repoPath: "/code/repo1", url: "https://gitlab.com/g/r/-/merge_requests/1", want: forge.KindGitLab},
+		{name: "configured forge wins", repoPath: "/code/repo2", url: "https://example.com/x", want: forge.KindBitbucket},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := m.providerForURL(tt.repoPath, tt.url)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Kind() != tt.want {
+				t.Errorf("Kind() = %q, want %q", p.Kind(), tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdate_QuickDiff(t *testing.T) {
+	patch := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n"
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/repo1:[merge-base origin/main HEAD]": "abc\n",
+			"/code/repo1:[diff --stat abc]":             " main.go | 2 +-\n",
+			"/code/repo1:[diff abc]":                    patch,
+		},
+	}
+	m := testModel()
+	m.runner = runner
+	m.config.DefaultBaseRef = "origin/main"
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	m = result.(Model)
+	if !m.showingQuickDiff || !m.quickDiffLoading {
+		t.Fatal("v on a worktree should open the quick-diff overlay in loading state")
+	}
+	if cmd == nil {
+		t.Fatal("expected quick-diff cmd")
+	}
+
+	msg, ok := cmd().(QuickDiffMsg)
+	if !ok {
+		t.Fatalf("expected QuickDiffMsg, got %T", cmd())
+	}
+	if len(msg.Patch) != 6 || msg.Truncated {
+		t.Errorf("patch = %v (truncated=%v), want 6 lines", msg.Patch, msg.Truncated)
+	}
+
+	result, _ = m.Update(msg)
+	m = result.(Model)
+	if m.quickDiffLoading {
+		t.Error("loading should clear once the diff arrives")
+	}
+	view := m.View()
+	for _, want := range []string{"main.go | 2 +-", "+new", "esc/q/v: close"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should contain %q, got:\n%s", want, view)
+		}
+	}
+
+	// Agent ticks keep flowing while the overlay is open.
+	_, cmd = m.Update(AgentTickMsg{})
+	if cmd == nil {
+		t.Error("AgentTickMsg should still reschedule polling under the overlay")
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	m = result.(Model)
+	if m.showingQuickDiff {
+		t.Error("esc should close the overlay")
+	}
+}
+
+func TestQuickDiffCmd_Truncates(t *testing.T) {
+	var patch strings.Builder
+	for i := 0; i < quickDiffMaxLines+20; i++ {
+		patch.WriteString("+line\n")
+	}
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/wt:[merge-base origin/main HEAD]": "abc\n",
+			"/wt:[diff --stat abc]":             "",
+			"/wt:[diff abc]":                    patch.String(),
+		},
+	}
+
+	msg, ok := quickDiffCmd(runner, "/wt", "origin/main", git.DiffBaseMergeBase)().(QuickDiffMsg)
+	if !ok {
+		t.Fatal("expected QuickDiffMsg")
+	}
+	if len(msg.Patch) != quickDiffMaxLines || !msg.Truncated {
+		t.Errorf("got %d lines (truncated=%v), want %d truncated", len(msg.Patch), msg.Truncated, quickDiffMaxLines)
+	}
+}
+
+func TestUpdate_QuickDiff_IgnoresStaleResult(t *testing.T) {
+	m := testModel()
+	m.showingQuickDiff = true
+	m.quickDiffLoading = true
+	m.quickDiffPath = "/code/repo1-feat"
+
+	result, _ := m.Update(QuickDiffErrMsg{WorktreePath: "/code/repo1", Err: fmt.Errorf("boom")})
+	m = result.(Model)
+	if !m.quickDiffLoading || m.quickDiffErr != nil {
+		t.Error("results for another worktree should be ignored")
+	}
+}
+
+func TestUpdate_EditDescription(t *testing.T) {
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/repo1:[config branch.main.description Ship the thing]": "",
+		},
+	}
+	m := testModel()
+	m.runner = runner
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	m = result.(Model)
+	if !m.editingDescription || m.descriptionBranch != "main" {
+		t.Fatalf("E should start editing the description of main, got editing=%v branch=%q", m.editingDescription, m.descriptionBranch)
+	}
+	if !strings.Contains(m.View(), "Describe Branch") {
+		t.Error("view should show the description prompt")
+	}
+
+	m.textInput.SetValue("Ship the thing")
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if m.editingDescription || !m.loading || cmd == nil {
+		t.Fatalf("enter should save and show loading, got editing=%v loading=%v", m.editingDescription, m.loading)
+	}
+	if _, ok := cmd().(BranchDescriptionSetMsg); !ok {
+		t.Error("expected BranchDescriptionSetMsg")
+	}
+}
+
+func TestUpdate_EditDescription_Unchanged(t *testing.T) {
+	m := testModel()
+	m.items[m.cursor].Description = "Same"
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	m = result.(Model)
+	if m.textInput.Value() != "Same" {
+		t.Errorf("input should be prefilled, got %q", m.textInput.Value())
+	}
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if cmd != nil || m.loading {
+		t.Error("unchanged description should not write git config")
+	}
+}
+
+func TestUpdate_BranchDescriptionErrMsg(t *testing.T) {
+	m := testModel()
+	m.loading = true
+
+	result, _ := m.Update(BranchDescriptionErrMsg{Err: fmt.Errorf("locked")})
+	m = result.(Model)
+	if m.loading || m.toast.Text != "locked" {
+		t.Error("error should be surfaced and loading cleared")
+	}
+}
+
+func TestUpdate_AddWorktree_ClipboardPrefill(t *testing.T) {
+	tests := []struct {
+		name      string
+		clipboard string
+		typed     string
+		want      string
+	}{
+		{name: "GitHub PR URL", clipboard: "https://github.com/owner/repo/pull/42", want: "https://github.com/owner/repo/pull/42"},
+		{name: "issue URL", clipboard: "https://github.com/owner/repo/issues/7", want: "https://github.com/owner/repo/issues/7"},
+		{name: "plain text", clipboard: "some notes", want: ""},
+		{name: "user already typing", clipboard: "https://github.com/owner/repo/pull/42", typed: "feat", want: "feat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel().WithClipboard(clipboard.FakeReader{Text: tt.clipboard})
+			for i, item := range m.items {
+				if item.Kind == model.ItemKindAddWorktree {
+					m.cursor = i
+					break
+				}
+			}
+
+			result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			m = result.(Model)
+			if !m.addingWorktree || cmd == nil {
+				t.Fatal("enter on Add worktree should open the prompt and read the clipboard")
+			}
+			m.textInput.SetValue(tt.typed)
+
+			result, _ = m.Update(ClipboardMsg{Text: tt.clipboard})
+			m = result.(Model)
+			if got := m.textInput.Value(); got != tt.want {
+				t.Errorf("input = %q, want %q", got, tt.want)
+			}
+			if m.clipboardPrefilled != (tt.want != "" && tt.typed == "") {
+				t.Errorf("clipboardPrefilled = %v", m.clipboardPrefilled)
+			}
+		})
+	}
+}
+
+func TestReadClipboardCmd_Error(t *testing.T) {
+	if msg := readClipboardCmd(clipboard.FakeReader{Err: clipboard.ErrUnavailable})(); msg != nil {
+		t.Errorf("clipboard errors should be swallowed, got %T", msg)
+	}
+}
+
+func TestUpdate_Grep(t *testing.T) {
+	grepArgs := func(dir string) string {
+		return fmt.Sprintf("%s:%v", dir, []string{"grep", "-z", "-n", "-I", "--no-color", "--full-name", "-e", "TODO"})
+	}
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			grepArgs("/code/repo1"):      "a.go\x001\x00// TODO main\n",
+			grepArgs("/code/repo1-feat"): "b.go\x007\x00// TODO feat\n",
+		},
+	}
+	m := testModel()
+	m.runner = runner
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+	m = result.(Model)
+	if !m.grepping {
+		t.Fatal("F should open the search prompt")
+	}
+	m.textInput.SetValue("TODO")
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if !m.showingGrep || !m.grepLoading || cmd == nil {
+		t.Fatal("enter should start the search")
+	}
+
+	result, _ = m.Update(cmd())
+	m = result.(Model)
+	if m.grepLoading || len(m.grepResults) != 2 {
+		t.Fatalf("expected 2 worktrees with matches, got %+v", m.grepResults)
+	}
+	view := m.View()
+	if !strings.Contains(view, "b.go:7") {
+		t.Errorf("results view should list matches, got:\n%s", view)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = result.(Model)
+	result, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if cmd == nil {
+		t.Fatal("enter on a match should quit to switch sessions")
+	}
+	if m.Selected() != "/code/repo1-feat" || m.SelectedRepoPath() != "/code/repo1" {
+		t.Errorf("selected = %q (repo %q), want the feat worktree", m.Selected(), m.SelectedRepoPath())
+	}
+	if m.SelectedFile() != "/code/repo1-feat/b.go:7" {
+		t.Errorf("SelectedFile = %q", m.SelectedFile())
+	}
+}
+
+func TestUpdate_Grep_EmptyPatternCancels(t *testing.T) {
+	m := testModel()
+	m.grepping = true
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if m.grepping || m.showingGrep || cmd != nil {
+		t.Error("an empty pattern should just close the prompt")
+	}
+}
+
+func TestUpdate_Filter(t *testing.T) {
+	m := testModel()
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = result.(Model)
+	if !m.filtering {
+		t.Fatal("/ should start filtering")
+	}
+
+	for _, r := range "feat" {
+		result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = result.(Model)
+	}
+	if m.filterQuery != "feat" {
+		t.Fatalf("filterQuery = %q, want feat", m.filterQuery)
+	}
+	if len(m.items) != 2 || m.items[1].Label != "feature-x" {
+		t.Fatalf("items = %+v, want header + feature-x", m.items)
+	}
+	if m.items[m.cursor].Label != "feature-x" {
+		t.Errorf("cursor should land on the first match")
+	}
+
+	// j is typed into the query rather than moving the cursor.
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = result.(Model)
+	if m.filterQuery != "featj" || len(m.items) != 0 {
+		t.Errorf("query = %q with %d items, want featj with none", m.filterQuery, len(m.items))
+	}
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = result.(Model)
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if cmd == nil || m.Selected() != "/code/repo1-feat" {
+		t.Errorf("enter should select the filtered worktree, got %q", m.Selected())
+	}
+}
+
+func TestUpdate_Filter_EscRestores(t *testing.T) {
+	m := testModel()
+	total := len(m.items)
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = result.(Model)
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("zzz")})
+	m = result.(Model)
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	m = result.(Model)
+
+	if m.filtering || m.filterQuery != "" || len(m.items) != total {
+		t.Errorf("esc should clear the filter, got filtering=%v query=%q items=%d", m.filtering, m.filterQuery, len(m.items))
+	}
+}
+
+func TestUpdate_Filter_KeptAcrossRefresh(t *testing.T) {
+	m := testModel()
+	m.filtering = true
+	m.filterQuery = "feat"
+
+	result, _ := m.Update(GitDataMsg{Groups: m.groups})
+	m = result.(Model)
+	if len(m.items) != 2 {
+		t.Errorf("refresh should keep the filter applied, got %d items", len(m.items))
+	}
+}
+
+func TestUpdate_S_CyclesSortModeKeepingCursor(t *testing.T) {
+	m := testModel()
+	m.config.Repositories = []model.RepositoryDef{{Name: "repo1", Path: "/code/repo1", Sort: "diff"}}
+	m.groups[0].Worktrees[1].Status = model.StatusInfo{Insertions: 3}
+	m.items = buildItems(m)
+	if m.items[1].Label != "feature-x" {
+		t.Fatalf("configured diff sort should put feature-x first, got %q", m.items[1].Label)
+	}
+	if m.items[0].SortLabel != "diff" {
+		t.Errorf("header SortLabel = %q, want diff", m.items[0].SortLabel)
+	}
+	m.cursor = 1
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = result.(Model)
+
+	if got := m.sortModeFor("/code/repo1"); got != sidebar.SortName {
+		t.Errorf("sort mode = %q, want %q", got, sidebar.SortName)
+	}
+	if m.items[m.cursor].WorktreePath != "/code/repo1-feat" {
+		t.Errorf("cursor should stay on feature-x, got %q", m.items[m.cursor].WorktreePath)
+	}
+	if m.items[1].Label != "feature-x" || m.items[2].Label != "main" {
+		t.Errorf("name sort order = %q, %q", m.items[1].Label, m.items[2].Label)
+	}
+}
+
+func TestAgentStatusMsg_ResortsByActivity(t *testing.T) {
+	m := testModel()
+	m.sortModes = map[string]sidebar.SortMode{"/code/repo1": sidebar.SortActivity}
+
+	result, _ := m.Update(AgentStatusMsg{Activity: map[string]time.Time{
+		"/code/repo1-feat": time.Unix(100, 0),
+	}})
+	m = result.(Model)
+
+	if m.items[1].WorktreePath != "/code/repo1-feat" {
+		t.Errorf("most recently active worktree should be first, got %q", m.items[1].WorktreePath)
+	}
+	if m.items[m.cursor].WorktreePath != "/code/repo1" {
+		t.Errorf("cursor should stay on main, got %q", m.items[m.cursor].WorktreePath)
+	}
+}
+
+func TestUpdate_B_GroupsByBranchPrefixKeepingCursor(t *testing.T) {
+	m := testModel()
+	m.groups = []model.RepoGroup{
+		{Name: "api", RootPath: "/code/api", Worktrees: []model.WorktreeInfo{
+			{Path: "/code/api", Branch: "main"},
+			{Path: "/wt/api-login", Branch: "feature/login"},
+		}},
+		{Name: "web", RootPath: "/code/web", Worktrees: []model.WorktreeInfo{
+			{Path: "/wt/web-crash", Branch: "fix/crash"},
+			{Path: "/wt/web-page", Branch: "feature/page"},
+		}},
+	}
+	m.items = buildItems(m)
+	m.cursor = 5 // fix/crash in the web group
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	m = result.(Model)
+
+	var headers []string
+	for _, item := range m.items {
+		if item.Kind == model.ItemKindGroupHeader {
+			headers = append(headers, item.Label)
+		}
+	}
+	if want := []string{"feature/", "fix/", sidebar.NoPrefixLabel}; !slices.Equal(headers, want) {
+		t.Errorf("headers = %q, want %q", headers, want)
+	}
+	if item := m.items[m.cursor]; item.WorktreePath != "/wt/web-crash" || item.RecentRepo != "web" {
+		t.Errorf("cursor should stay on fix/crash, labelled with its repository, got %+v", item)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	m = result.(Model)
+	if m.items[0].Label != "api" || m.items[m.cursor].WorktreePath != "/wt/web-crash" {
+		t.Errorf("b again should group by repository on fix/crash, got %q and cursor on %q", m.items[0].Label, m.items[m.cursor].WorktreePath)
+	}
+}
+
+type fakeGroupStore struct {
+	collapsed map[string]bool
+}
+
+func (s *fakeGroupStore) Get() map[string]bool { return s.collapsed }
+
+func (s *fakeGroupStore) Set(repoPath string, collapsed bool) error {
+	s.collapsed[repoPath] = collapsed
+	return nil
+}
+
+func TestUpdate_EnterOnHeader_TogglesGroup(t *testing.T) {
+	store := &fakeGroupStore{collapsed: map[string]bool{}}
+	m := testModel().WithGroupStore(store)
+	m.cursor = 0 // repo1 header
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if cmd != nil {
+		t.Error("toggling a group should not quit")
+	}
+	if !store.collapsed["/code/repo1"] {
+		t.Error("collapsed state should be saved to the store")
+	}
+	for _, item := range m.items {
+		if item.Kind == model.ItemKindWorktree || item.Kind == model.ItemKindAddWorktree {
+			t.Errorf("collapsed group should hide %q", item.Label)
+		}
+	}
+	if m.cursor != 0 || !m.items[0].Collapsed {
+		t.Errorf("cursor should stay on the collapsed header, got %d", m.cursor)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m = result.(Model)
+	if store.collapsed["/code/repo1"] || len(m.items) != len(testModel().items) {
+		t.Errorf("space should expand the group again, got %d items", len(m.items))
+	}
+}
+
+func TestWithGroupStore_RestoresCollapsedGroups(t *testing.T) {
+	store := &fakeGroupStore{collapsed: map[string]bool{"/code/repo1": true}}
+	m := testModel().WithGroupStore(store)
+
+	result, _ := m.Update(GitDataMsg{Groups: m.groups})
+	m = result.(Model)
+	if len(m.items) != 3 || !m.items[0].Collapsed {
+		t.Errorf("saved collapsed group should load folded, got %+v", m.items)
+	}
+}
+
+func TestFilter_IgnoresCollapsedGroups(t *testing.T) {
+	m := testModel()
+	m.collapsed = map[string]bool{"/code/repo1": true}
+	m.filterQuery = "feat"
+
+	m = applyFilter(m)
+	if len(m.items) != 2 || m.items[1].Label != "feature-x" {
+		t.Errorf("filter should search inside collapsed groups, got %+v", m.items)
+	}
+}
+
+func TestAddWorktreeFromURLCmd_NestedBranchIsFlattened(t *testing.T) {
+	basePath := t.TempDir()
+	branch := "release/2024/fix-x"
+	wantPath := filepath.Join(basePath, "myrepo", "2024-fix-x")
+
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("/repo:%v", []string{"fetch", "origin", branch}):           "",
+			fmt.Sprintf("/repo:%v", []string{"worktree", "add", wantPath, branch}): "",
+		},
+	}
+
+	cmd := addWorktreeFromURLCmd(runner, forge.GitHub{}, "/repo", basePath, "myrepo", "https://github.com/owner/repo/tree/release/2024/fix-x")
+	msg := cmd()
+
+	addedMsg, ok := msg.(WorktreeAddedMsg)
+	if !ok {
+		t.Fatalf("expected WorktreeAddedMsg, got %T: %v", msg, msg)
+	}
+	if addedMsg.WorktreePath != wantPath {
+		t.Errorf("WorktreePath = %q, want %q", addedMsg.WorktreePath, wantPath)
+	}
+}
+
+func TestCreateWorktreeFromBranch_CollidingSlugGetsSuffix(t *testing.T) {
+	// "alice/fix" already lives in myrepo/fix; "bob/fix" from a PR URL and
+	// from a typed branch name must both land in myrepo/fix-2.
+	for _, viaURL := range []bool{true, false} {
+		basePath := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(basePath, "myrepo", "fix"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		branch := "bob/fix"
+		wantPath := filepath.Join(basePath, "myrepo", "fix-2")
+		runner := git.FakeCommandRunner{
+			Outputs: map[string]string{
+				fmt.Sprintf("/repo:%v", []string{"fetch", "origin", branch}):           "",
+				fmt.Sprintf("/repo:%v", []string{"worktree", "add", wantPath, branch}): "",
+			},
+		}
+
+		var cmd tea.Cmd
+		if viaURL {
+			cmd = addWorktreeFromURLCmd(runner, forge.GitHub{}, "/repo", basePath, "myrepo", "https://github.com/owner/repo/tree/bob/fix")
+		} else {
+			cmd = addWorktreeFromBranchNameCmd(runner, "/repo", basePath, "myrepo", branch)
+		}
+		msg := cmd()
+
+		addedMsg, ok := msg.(WorktreeAddedMsg)
+		if !ok {
+			t.Fatalf("viaURL=%v: expected WorktreeAddedMsg, got %T: %v", viaURL, msg, msg)
+		}
+		if addedMsg.WorktreePath != wantPath {
+			t.Errorf("viaURL=%v: WorktreePath = %q, want %q", viaURL, addedMsg.WorktreePath, wantPath)
+		}
+	}
+}
+
+func TestCreateWorktreeFromBase_SkipsExistingDirectory(t *testing.T) {
+	basePath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(basePath, "myrepo", "japan"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	wantPath := filepath.Join(basePath, "myrepo", "japan-2")
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("/repo:%v", []string{"worktree", "add", wantPath, "-b", "testuser/japan-2", "main"}): "",
+		},
+	}
+
+	msg := createWorktreeFromBase(runner, "/repo", basePath, "myrepo", "main", "testuser", "japan")
+
+	addedMsg, ok := msg.(WorktreeAddedMsg)
+	if !ok {
+		t.Fatalf("expected WorktreeAddedMsg, got %T: %v", msg, msg)
+	}
+	if addedMsg.WorktreePath != wantPath || addedMsg.Branch != "testuser/japan-2" {
+		t.Errorf("got %q on %q, want %q on testuser/japan-2", addedMsg.WorktreePath, addedMsg.Branch, wantPath)
+	}
+}
+
+func TestUpdate_AddWorktreeMode_Enter_PlainName_CreatesUserBranch(t *testing.T) {
+	m := testModel()
+	m.addingWorktree = true
+	m.addingWorktreeRepoPath = "/code/repo1"
+	m.branchCursor = -1
+	basePath := t.TempDir()
+	wantPath := filepath.Join(basePath, "repo1", "fix-login")
+	m.config = model.Config{
+		WorktreeBasePath: basePath,
+		DefaultBaseRef:   "main",
+		Repositories:     []model.RepositoryDef{{Name: "repo1", Path: "/code/repo1"}},
+	}
+	m.runner = git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/repo1:[config user.name]": "Alice\n",
+			fmt.Sprintf("/code/repo1:%v", []string{"worktree", "add", wantPath, "-b", "alice/fix-login", "main"}): "",
+		},
+	}
+	m.textInput.SetValue("Fix Login")
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !result.(Model).loading || cmd == nil {
+		t.Fatal("enter should start creating the worktree")
+	}
+
+	added, ok := opMsg(t, cmd).(WorktreeAddedMsg)
+	if !ok {
+		t.Fatal("expected WorktreeAddedMsg")
+	}
+	if added.Branch != "alice/fix-login" || added.WorktreePath != wantPath || !added.Named {
+		t.Errorf("got %+v", added)
+	}
+}
+
+func TestAddWorktreeWithNameCmd_UnusableName(t *testing.T) {
+	msg := addWorktreeWithNameCmd(git.FakeCommandRunner{}, "/repo", t.TempDir(), "myrepo", "main", "", "!!!")()
+
+	if _, ok := msg.(WorktreeAddErrMsg); !ok {
+		t.Errorf("expected WorktreeAddErrMsg, got %#v", msg)
+	}
+}
+
+func TestAddWorktreeWithNameCmd_ExistingBranch(t *testing.T) {
+	basePath := t.TempDir()
+	localPath := filepath.Join(basePath, "myrepo", "develop")
+	remotePath := filepath.Join(basePath, "myrepo", "release")
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[show-ref --verify --quiet refs/heads/develop]":                                                         "",
+			"/repo:[worktree add " + localPath + " develop]":                                                               "",
+			"/repo:[show-ref --verify --quiet refs/remotes/origin/release]":                                                "",
+			fmt.Sprintf("/repo:%v", []string{"worktree", "add", "--track", "-b", "release", remotePath, "origin/release"}): "",
+		},
+	}
+
+	for _, name := range []string{"develop", "release"} {
+		msg := addWorktreeWithNameCmd(runner, "/repo", basePath, "myrepo", "main", "", name)()
+		added, ok := msg.(WorktreeAddedMsg)
+		if !ok {
+			t.Fatalf("%s: expected WorktreeAddedMsg, got %#v", name, msg)
+		}
+		if added.Branch != name || !added.Existing {
+			t.Errorf("%s: got %+v, want the existing branch checked out", name, added)
+		}
+	}
+}
+
+func TestUpdate_WorktreeAddedMsg_NamedSkipsAutoRename(t *testing.T) {
+	m := testModel()
+	m.branchRenames = map[string]model.BranchRenameInfo{}
+
+	result, _ := m.Update(WorktreeAddedMsg{WorktreePath: "/wt/fix-login", Branch: "alice/fix-login", Named: true})
+
+	if _, ok := result.(Model).branchRenames["/wt/fix-login"]; ok {
+		t.Error("a branch named by the user should not be queued for auto-rename")
+	}
+}
+
+type fakePinStore struct {
+	pinned map[string]bool
+}
+
+func (s *fakePinStore) Get() map[string]bool { return s.pinned }
+
+func (s *fakePinStore) Set(worktreePath string, pinned bool) error {
+	s.pinned[worktreePath] = pinned
+	return nil
+}
+
+func TestUpdate_PinMovesWorktreeToTop(t *testing.T) {
+	store := &fakePinStore{pinned: map[string]bool{}}
+	m := testModel().WithPinStore(store)
+	for i, item := range m.items {
+		if item.WorktreePath == "/code/repo1-feat" {
+			m.cursor = i
+		}
+	}
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = result.(Model)
+	if !store.pinned["/code/repo1-feat"] {
+		t.Error("pin should be saved to the store")
+	}
+	if m.items[1].WorktreePath != "/code/repo1-feat" || !m.items[1].Pinned {
+		t.Errorf("pinned worktree should lead its group, got %+v", m.items[1])
+	}
+	if m.items[m.cursor].WorktreePath != "/code/repo1-feat" {
+		t.Error("cursor should follow the pinned worktree")
+	}
+	if !strings.Contains(m.View(), iconPin) {
+		t.Error("pinned worktree should show the pin icon")
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = result.(Model)
+	if store.pinned["/code/repo1-feat"] || m.items[1].WorktreePath != "/code/repo1" {
+		t.Error("p again should unpin the worktree")
+	}
+}
+
+func TestWithPinStore_RestoresPins(t *testing.T) {
+	store := &fakePinStore{pinned: map[string]bool{"/code/repo1-feat": true}}
+	m := testModel().WithPinStore(store)
+
+	result, _ := m.Update(GitDataMsg{Groups: m.groups})
+	m = result.(Model)
+	if m.items[1].WorktreePath != "/code/repo1-feat" {
+		t.Errorf("saved pin should load at the top, got %+v", m.items)
+	}
+}
+
+func TestRecentSection(t *testing.T) {
+	m := testModel().WithRecent([]string{"/code/repo1-feat", "/gone"})
+	m.items = nil // nothing loaded yet, as on startup
+	result, _ := m.Update(GitDataMsg{Groups: m.groups})
+	m = result.(Model)
+
+	if m.items[0].Label != "Recent" || m.items[0].Selectable {
+		t.Fatalf("first row = %+v, want the Recent header", m.items[0])
+	}
+	if m.items[1].WorktreePath != "/code/repo1-feat" || m.items[1].RecentRepo != "repo1" {
+		t.Fatalf("second row = %+v, want the recent worktree", m.items[1])
+	}
+	if m.cursor != 1 {
+		t.Errorf("cursor = %d, want the most recent worktree", m.cursor)
+	}
+	if view := m.View(); !strings.Contains(view, "Recent") || !strings.Contains(view, "repo1") {
+		t.Errorf("view should show the Recent section:\n%s", view)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = result.(Model)
+	if m.cursor != 1 {
+		t.Errorf("cursor = %d, should stay on the Recent row after re-sorting", m.cursor)
+	}
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || result.(Model).Selected() != "/code/repo1-feat" {
+		t.Error("enter on a Recent row should select its worktree")
+	}
+
+	m.filterQuery = "feat"
+	if items := buildItems(m); items[0].Label == "Recent" {
+		t.Error("the Recent section should be hidden while filtering")
+	}
+}
@@ -0,0 +1,199 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// WorktreesFetchedMsg is sent when the repositories of the marked worktrees
+// have been fetched. Err joins the repositories that could not be fetched.
+type WorktreesFetchedMsg struct {
+	Err error
+}
+
+// Selection is a worktree picked in the sidebar.
+type Selection struct {
+	WorktreePath string
+	RepoPath     string
+}
+
+// BackgroundSelected returns the worktrees marked in select mode other than
+// Selected, whose sessions should be opened without switching to them.
+func (m Model) BackgroundSelected() []Selection {
+	if m.selected == "" {
+		return nil
+	}
+	var extra []Selection
+	for _, s := range m.markedSelections() {
+		if s.WorktreePath != m.selected {
+			extra = append(extra, s)
+		}
+	}
+	return extra
+}
+
+// markedSelections returns the marked worktrees in sidebar order, skipping
+// marks whose worktree has since gone away.
+func (m Model) markedSelections() []Selection {
+	var marked []Selection
+	for _, g := range m.groups {
+		for _, wt := range g.Worktrees {
+			if m.marked[wt.Path] {
+				marked = append(marked, Selection{WorktreePath: wt.Path, RepoPath: g.RootPath})
+			}
+		}
+	}
+	return marked
+}
+
+// markedArchiveTargets returns the marked worktrees that can be archived:
+// main and bare worktrees are left out.
+func (m Model) markedArchiveTargets() []CleanupCandidate {
+	var targets []CleanupCandidate
+	for _, g := range m.groups {
+		for _, wt := range g.Worktrees {
+			if m.marked[wt.Path] && !wt.IsBare && wt.Path != g.RootPath {
+				targets = append(targets, CleanupCandidate{RepoPath: g.RootPath, WorktreePath: wt.Path, Branch: wt.Branch})
+			}
+		}
+	}
+	return targets
+}
+
+func fetchWorktreesCmd(runner git.CommandRunner, repoPaths []string) tea.Cmd {
+	return func() tea.Msg {
+		var errs []error
+		for _, repo := range repoPaths {
+			if err := git.Fetch(runner, repo); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", repo, err))
+			}
+		}
+		return WorktreesFetchedMsg{Err: errors.Join(errs...)}
+	}
+}
+
+// setMarks replaces the marked worktrees and refreshes the sidebar.
+func (m Model) setMarks(marked map[string]bool) Model {
+	m.marked = marked
+	m.items = buildItems(m)
+	return recomputeScroll(m)
+}
+
+// toggleMark marks or unmarks the worktree under the cursor. On a group
+// header it marks every worktree of the group, or unmarks them all when they
+// were already marked.
+func (m Model) toggleMark() Model {
+	if m.cursor >= len(m.items) {
+		return m
+	}
+	item := m.items[m.cursor]
+	marked := make(map[string]bool, len(m.marked)+1)
+	for path := range m.marked {
+		marked[path] = true
+	}
+
+	switch item.Kind {
+	case model.ItemKindWorktree:
+		if marked[item.WorktreePath] {
+			delete(marked, item.WorktreePath)
+		} else {
+			marked[item.WorktreePath] = true
+		}
+	case model.ItemKindGroupHeader:
+		var paths []string
+		all := true
+		for _, g := range m.groups {
+			if g.RootPath != item.RepoRootPath {
+				continue
+			}
+			for _, wt := range g.Worktrees {
+				paths = append(paths, wt.Path)
+				all = all && marked[wt.Path]
+			}
+		}
+		for _, path := range paths {
+			if all {
+				delete(marked, path)
+			} else {
+				marked[path] = true
+			}
+		}
+	default:
+		return m
+	}
+	return m.setMarks(marked)
+}
+
+// updateSelectKey handles the keys that act on marked worktrees in select
+// mode. It reports false for keys that keep their usual meaning.
+func (m Model) updateSelectKey(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, selectKeys.Done):
+		m.selecting = false
+		return m.setMarks(nil), nil, true
+
+	case key.Matches(msg, selectKeys.Mark):
+		return m.toggleMark(), nil, true
+
+	case key.Matches(msg, selectKeys.Archive):
+		targets := m.markedArchiveTargets()
+		if len(targets) == 0 {
+			return m, nil, true
+		}
+		m.confirmingArchive = true
+		m.archiveMarked = targets
+		m.err = nil
+		return m, nil, true
+
+	case key.Matches(msg, selectKeys.Fetch):
+		var repoPaths []string
+		for _, s := range m.markedSelections() {
+			if len(repoPaths) == 0 || repoPaths[len(repoPaths)-1] != s.RepoPath {
+				repoPaths = append(repoPaths, s.RepoPath)
+			}
+		}
+		if len(repoPaths) == 0 {
+			return m, nil, true
+		}
+		m.loading = true
+		m.err = nil
+		return m, fetchWorktreesCmd(m.runner, repoPaths), true
+
+	case key.Matches(msg, selectKeys.OpenAll):
+		if m.cursor < len(m.items) && m.items[m.cursor].Kind == model.ItemKindWorktree {
+			return m, nil, false
+		}
+		if marked := m.markedSelections(); len(marked) > 0 {
+			next, cmd := m.selectWorktree(marked[0].WorktreePath, marked[0].RepoPath)
+			return next, cmd, true
+		}
+	}
+	return m, nil, false
+}
+
+// selectHelp is the sidebar help line in select mode.
+func (m Model) selectHelp() string {
+	return fmt.Sprintf("%d marked  %s", len(m.markedSelections()), keyhelp.ShortHelp(keyhelp.Bindings(selectKeys)...))
+}
+
+// archiveMarkedPrompt is the archive confirmation question for the marked
+// worktrees.
+func (m Model) archiveMarkedPrompt() string {
+	branches := make([]string, 0, len(m.archiveMarked))
+	for _, c := range m.archiveMarked {
+		branches = append(branches, c.Branch)
+	}
+	noun := "worktrees"
+	if len(branches) == 1 {
+		noun = "worktree"
+	}
+	return fmt.Sprintf("Remove %d %s (%s)?", len(branches), noun, strings.Join(branches, ", "))
+}
@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func pressKeys(t *testing.T, m Model, keys ...tea.KeyMsg) (Model, tea.Cmd) {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, k := range keys {
+		var result tea.Model
+		result, cmd = m.Update(k)
+		m = result.(Model)
+	}
+	return m, cmd
+}
+
+func runeKey(s string) tea.KeyMsg {
+	if s == " " {
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// cursorOn moves the cursor to the worktree at path.
+func cursorOn(t *testing.T, m Model, path string) Model {
+	t.Helper()
+	for i, item := range m.items {
+		if item.Kind == model.ItemKindWorktree && item.WorktreePath == path {
+			m.cursor = i
+			return m
+		}
+	}
+	t.Fatalf("no worktree %s in the sidebar", path)
+	return m
+}
+
+func TestSelectMode_MarksWorktrees(t *testing.T) {
+	m := cursorOn(t, testModel(), "/code/repo1-feat")
+
+	m, _ = pressKeys(t, m, runeKey("V"), runeKey(" "))
+	if !m.selecting || !m.marked["/code/repo1-feat"] {
+		t.Fatalf("space should mark the worktree, marked = %v", m.marked)
+	}
+	if view := m.View(); !strings.Contains(view, "✓") || !strings.Contains(view, "1 marked") {
+		t.Errorf("view should show the mark and count:\n%s", view)
+	}
+
+	m, _ = pressKeys(t, m, runeKey(" "))
+	if len(m.marked) != 0 {
+		t.Errorf("space again should unmark, marked = %v", m.marked)
+	}
+
+	m.cursor = 0 // repo1 header
+	m, _ = pressKeys(t, m, runeKey(" "))
+	if len(m.marked) != 2 {
+		t.Errorf("space on a header should mark the whole group, marked = %v", m.marked)
+	}
+	m, _ = pressKeys(t, m, runeKey(" "))
+	if len(m.marked) != 0 {
+		t.Errorf("space on a fully marked header should unmark it, marked = %v", m.marked)
+	}
+	if m.items[0].Collapsed {
+		t.Error("space should not fold the group in select mode")
+	}
+
+	m, _ = pressKeys(t, m, runeKey(" "), tea.KeyMsg{Type: tea.KeyEscape})
+	if m.selecting || len(m.marked) != 0 {
+		t.Error("esc should leave select mode and drop the marks")
+	}
+}
+
+func TestSelectMode_ArchivesMarkedWorktrees(t *testing.T) {
+	m := testModel()
+	m.cursor = 0
+	m, _ = pressKeys(t, m, runeKey("V"), runeKey(" "), runeKey("d"))
+
+	if !m.confirmingArchive || len(m.archiveMarked) != 1 || m.archiveMarked[0].Branch != "feature-x" {
+		t.Fatalf("d should confirm archiving the marked worktrees except main, got %+v", m.archiveMarked)
+	}
+	if view := m.View(); !strings.Contains(view, "Remove 1 worktree (feature-x)?") {
+		t.Errorf("confirm view =\n%s", view)
+	}
+
+	m, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || !m.loading {
+		t.Fatal("enter should start archiving")
+	}
+
+	result, _ := m.Update(WorktreesArchivedMsg{Err: errors.New("feature-x: contains modified files")})
+	m = result.(Model)
+	if m.confirmingArchive || m.selecting || m.marked != nil || m.archiveMarked != nil {
+		t.Error("select mode should end once the worktrees are archived")
+	}
+	if len(m.errorLog) != 1 {
+		t.Error("archive failures should be reported")
+	}
+}
+
+func TestSelectMode_FetchesMarkedRepositories(t *testing.T) {
+	m := testModel()
+	m.cursor = 0
+	m.runner = git.FakeCommandRunner{Outputs: map[string]string{"/code/repo1:[fetch --prune origin]": ""}}
+
+	m, cmd := pressKeys(t, m, runeKey("V"), runeKey(" "), runeKey("f"))
+	if cmd == nil || !m.loading {
+		t.Fatal("f should fetch the marked worktrees")
+	}
+	msg, ok := cmd().(WorktreesFetchedMsg)
+	if !ok || msg.Err != nil {
+		t.Fatalf("msg = %+v, want a fetch once per repository", msg)
+	}
+
+	result, _ := m.Update(msg)
+	if !result.(Model).marked["/code/repo1-feat"] {
+		t.Error("marks should survive a fetch")
+	}
+}
+
+func TestSelectMode_EnterOpensMarkedWorktrees(t *testing.T) {
+	m := cursorOn(t, testModel(), "/code/repo1")
+	m, _ = pressKeys(t, m, runeKey("V"), runeKey(" "))
+	m = cursorOn(t, m, "/code/repo1-feat")
+	m, _ = pressKeys(t, m, runeKey(" "))
+
+	m, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || m.Selected() != "/code/repo1-feat" {
+		t.Fatalf("enter should select the worktree under the cursor, got %q", m.Selected())
+	}
+	extra := m.BackgroundSelected()
+	if len(extra) != 1 || extra[0] != (Selection{WorktreePath: "/code/repo1", RepoPath: "/code/repo1"}) {
+		t.Errorf("BackgroundSelected() = %+v", extra)
+	}
+}
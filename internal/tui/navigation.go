@@ -24,8 +24,14 @@ func PrevSelectable(items []model.NavigableItem, current int) int {
 	return current
 }
 
-// FirstSelectable returns the index of the first selectable item, or 0.
+// FirstSelectable returns the index of the first worktree, falling back to
+// the first selectable item (such as a collapsed group header), or 0.
 func FirstSelectable(items []model.NavigableItem) int {
+	for i, item := range items {
+		if item.Kind == model.ItemKindWorktree {
+			return i
+		}
+	}
 	for i, item := range items {
 		if item.Selectable {
 			return i
@@ -34,6 +40,22 @@ func FirstSelectable(items []model.NavigableItem) int {
 	return 0
 }
 
+// nearestSelectable returns the selectable item at i, or the closest one
+// after it, falling back to the closest one before it.
+func nearestSelectable(items []model.NavigableItem, i int) int {
+	if len(items) == 0 {
+		return 0
+	}
+	i = min(i, len(items)-1)
+	if items[i].Selectable {
+		return i
+	}
+	if next := NextSelectable(items, i); next != i {
+		return next
+	}
+	return PrevSelectable(items, i)
+}
+
 // recomputeScroll updates m.scrollOff based on current cursor, items, and
 // height. Call after any change that moves the cursor or changes the viewport.
 func recomputeScroll(m Model) Model {
@@ -42,7 +64,7 @@ func recomputeScroll(m Model) Model {
 		return m
 	}
 	heights := itemHeights(m.items, m.cursor, m.sidebarWidth)
-	vp := viewportHeight(m.height)
+	vp := m.listHeight()
 	m.scrollOff = adjustScroll(m.cursor, vp, heights)
 	return m
 }
@@ -63,6 +63,29 @@ func TestPrevSelectable(t *testing.T) {
 	}
 }
 
+func TestNearestSelectable(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []model.NavigableItem
+		i     int
+		want  int
+	}{
+		{"selectable", makeItems(true, true, true), 1, 1},
+		{"next after a header", makeItems(true, false, true), 1, 2},
+		{"past the end", makeItems(true, true), 5, 1},
+		{"back when nothing follows", makeItems(true, false, false), 2, 0},
+		{"empty", nil, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nearestSelectable(tt.items, tt.i); got != tt.want {
+				t.Errorf("nearestSelectable(%d) = %d, want %d", tt.i, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestAdjustScroll(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/notify"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+// WithNotifier returns a copy of the model that sends desktop notifications
+// through notifier when an agent needs input.
+func (m Model) WithNotifier(notifier notify.Notifier) Model {
+	m.notifier = notifier
+	return m
+}
+
+// agentEvent is an agent that started waiting for permission, or went from
+// running to idle, between two polls.
+type agentEvent struct {
+	path  string
+	agent model.AgentInfo
+}
+
+// agentEvents compares the agents of two polls, matching them by pane.
+func agentEvents(prev, next map[string][]model.AgentInfo) []agentEvent {
+	var events []agentEvent
+	for path, agents := range next {
+		for _, a := range agents {
+			before := model.AgentStateNone
+			if i := slices.IndexFunc(prev[path], func(p model.AgentInfo) bool { return p.PaneID == a.PaneID }); i >= 0 {
+				before = prev[path][i].State
+			}
+			if a.State == model.AgentStateWaiting && before != model.AgentStateWaiting ||
+				a.State == model.AgentStateIdle && before == model.AgentStateRunning {
+				events = append(events, agentEvent{path: path, agent: a})
+			}
+		}
+	}
+	slices.SortFunc(events, func(a, b agentEvent) int {
+		return cmp.Or(cmp.Compare(a.path, b.path), cmp.Compare(a.agent.PaneID, b.agent.PaneID))
+	})
+	return events
+}
+
+// notifyAgentsCmd notifies about the agents that changed state since prev,
+// the statuses of the previous poll. Nothing is sent after the first poll,
+// which has nothing to compare with.
+func (m Model) notifyAgentsCmd(prev map[string][]model.AgentInfo) tea.Cmd {
+	if prev == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, e := range agentEvents(prev, m.agentStatus) {
+		channels := m.notificationChannels(e.agent.State)
+		if len(channels) == 0 {
+			continue
+		}
+		cmds = append(cmds, notifyCmd(m.notifier, m.tmuxRunner, channels, m.notificationText(e)))
+	}
+	return tea.Batch(cmds...)
+}
+
+// notificationChannels returns the channels the notifications config picks
+// for state, which was checked when the config was loaded.
+func (m Model) notificationChannels(state model.AgentState) []notify.Channel {
+	names := m.config.Notifications.Idle
+	if state == model.AgentStateWaiting {
+		names = m.config.Notifications.Waiting
+	}
+	if names == nil {
+		return notify.DefaultChannels
+	}
+	channels, _ := notify.ParseChannels(names)
+	return channels
+}
+
+func (m Model) notificationText(e agentEvent) string {
+	where := filepath.Base(e.path)
+	for _, g := range m.groups {
+		for _, wt := range g.Worktrees {
+			if wt.Path == e.path && wt.Branch != "" {
+				where = wt.Branch + " (" + g.Name + ")"
+			}
+		}
+	}
+	agent := cmp.Or(e.agent.Agent, "agent")
+	if e.agent.State == model.AgentStateWaiting {
+		return fmt.Sprintf("%s is waiting for input in %s", agent, where)
+	}
+	return fmt.Sprintf("%s finished in %s", agent, where)
+}
+
+// notifyCmd sends text on each of channels. Failures are only logged: a
+// missing notify-send should not get in the way of the sidebar.
+func notifyCmd(notifier notify.Notifier, tmuxRunner tmux.Runner, channels []notify.Channel, text string) tea.Cmd {
+	return func() tea.Msg {
+		for _, c := range channels {
+			var err error
+			switch {
+			case c == notify.Desktop && notifier != nil:
+				err = notifier.Notify("yakumo", text)
+			case c == notify.Tmux && tmuxRunner != nil:
+				err = tmux.DisplayMessage(tmuxRunner, text)
+			}
+			if err != nil {
+				logging.For("notify").Warn("notifying failed", "channel", c, "err", err)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/notify"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+func agents(states ...model.AgentState) []model.AgentInfo {
+	var out []model.AgentInfo
+	for i, s := range states {
+		out = append(out, model.AgentInfo{PaneID: "%" + string(rune('0'+i)), Agent: "claude", State: s})
+	}
+	return out
+}
+
+func TestAgentEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		prev []model.AgentInfo
+		next []model.AgentInfo
+		want int
+	}{
+		{"starts waiting", agents(model.AgentStateRunning), agents(model.AgentStateWaiting), 1},
+		{"still waiting", agents(model.AgentStateWaiting), agents(model.AgentStateWaiting), 0},
+		{"finishes running", agents(model.AgentStateRunning), agents(model.AgentStateIdle), 1},
+		{"idle after waiting", agents(model.AgentStateWaiting), agents(model.AgentStateIdle), 0},
+		{"new pane waiting", nil, agents(model.AgentStateWaiting), 1},
+		{"new pane idle", nil, agents(model.AgentStateIdle), 0},
+		{"starts running", agents(model.AgentStateIdle), agents(model.AgentStateRunning), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := map[string][]model.AgentInfo{"/code/repo1": tt.prev}
+			next := map[string][]model.AgentInfo{"/code/repo1": tt.next}
+			if got := agentEvents(prev, next); len(got) != tt.want {
+				t.Errorf("agentEvents() = %+v, want %d events", got, tt.want)
+			}
+		})
+	}
+}
+
+// pollAgents feeds a manual AgentStatusMsg, which schedules no tick, with
+// statuses for /code/repo1.
+func pollAgents(m Model, states ...model.AgentState) Model {
+	result, _ := m.Update(AgentStatusMsg{Statuses: map[string][]model.AgentInfo{"/code/repo1": agents(states...)}, manual: true})
+	return result.(Model)
+}
+
+// runNotifications runs the notifications m sends after a poll that followed
+// one with prev, whether they come as one command or a batch.
+func runNotifications(m Model, prev map[string][]model.AgentInfo) {
+	if cmd := m.notifyAgentsCmd(prev); cmd != nil {
+		if batch, ok := cmd().(tea.BatchMsg); ok {
+			for _, c := range batch {
+				if c != nil {
+					c()
+				}
+			}
+		}
+	}
+}
+
+func TestNotifyAgents_Desktop(t *testing.T) {
+	notifier := &notify.FakeNotifier{}
+	m := testModel().WithNotifier(notifier)
+
+	first := pollAgents(m, model.AgentStateWaiting)
+	runNotifications(first, nil)
+	if len(notifier.Sent) != 0 {
+		t.Fatalf("the first poll should not notify, sent %+v", notifier.Sent)
+	}
+
+	running := pollAgents(first, model.AgentStateRunning)
+	idle := pollAgents(running, model.AgentStateIdle)
+	runNotifications(idle, running.agentStatus)
+	if len(notifier.Sent) != 1 {
+		t.Fatalf("sent %+v, want one notification", notifier.Sent)
+	}
+	if want := "claude finished in main (repo1)"; notifier.Sent[0].Body != want {
+		t.Errorf("body = %q, want %q", notifier.Sent[0].Body, want)
+	}
+}
+
+func TestNotifyAgents_Channels(t *testing.T) {
+	notifier := &notify.FakeNotifier{}
+	runner := &tmux.FakeRunner{Outputs: map[string]string{
+		"[display-message claude is waiting for input in main (repo1)]": "",
+	}}
+	m := testModel().WithNotifier(notifier)
+	m.tmuxRunner = runner
+	m.config.Notifications = model.NotificationConfig{Waiting: []string{"tmux"}, Idle: []string{}}
+
+	running := pollAgents(m, model.AgentStateRunning)
+	waiting := pollAgents(running, model.AgentStateWaiting)
+	runNotifications(waiting, running.agentStatus)
+	if len(notifier.Sent) != 0 {
+		t.Errorf("waiting is set to tmux only, but sent %+v", notifier.Sent)
+	}
+	if len(runner.Calls) != 1 {
+		t.Errorf("tmux calls = %v, want one display-message", runner.Calls)
+	}
+
+	idle := pollAgents(running, model.AgentStateIdle)
+	if cmd := idle.notifyAgentsCmd(running.agentStatus); cmd != nil {
+		t.Error("an empty idle list should turn the notification off")
+	}
+}
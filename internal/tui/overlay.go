@@ -0,0 +1,89 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// overlay is a view over the sidebar that captures input while it is open:
+// key presses, mouse events unless keysOnly, and the messages of its own
+// commands. Everything else, such as agent ticks and git data, keeps flowing
+// to the sidebar underneath.
+type overlay struct {
+	open     func(Model) bool
+	update   func(Model, tea.Msg) (tea.Model, tea.Cmd)
+	keysOnly bool
+	msgs     []func(tea.Msg) bool // the messages it takes besides input; see isMsg
+}
+
+// isMsg reports whether msg is a T, for overlay.msgs.
+func isMsg[T tea.Msg](msg tea.Msg) bool {
+	_, ok := msg.(T)
+	return ok
+}
+
+// keyUpdate adapts the update of an overlay that only takes key presses.
+func keyUpdate(update func(Model, tea.KeyMsg) (tea.Model, tea.Cmd)) func(Model, tea.Msg) (tea.Model, tea.Cmd) {
+	return func(m Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+		return update(m, msg.(tea.KeyMsg))
+	}
+}
+
+// overlays lists the overlays in the order they take input when more than
+// one is open, the quick-diff overlay last.
+var overlays = []overlay{
+	{open: func(m Model) bool { return m.pickingTemplate }, update: Model.updateTemplatePickerMode},
+	{open: func(m Model) bool { return m.wipChecking || m.showingWIP }, update: Model.updateWIPMode,
+		msgs: []func(tea.Msg) bool{isMsg[WIPFoundMsg], isMsg[WIPRestoredMsg]}},
+	{open: func(m Model) bool { return m.showingGrep }, update: Model.updateGrepResultsMode,
+		msgs: []func(tea.Msg) bool{isMsg[GrepResultMsg]}},
+	{open: func(m Model) bool { return m.showingDevLog }, update: Model.updateDevLogMode,
+		msgs: []func(tea.Msg) bool{isMsg[DevLogMsg], isMsg[DevLogTickMsg]}},
+	{open: func(m Model) bool { return m.showingDebugLog }, update: Model.updateDebugLogMode,
+		msgs: []func(tea.Msg) bool{isMsg[DebugLogMsg]}},
+	{open: func(m Model) bool { return m.showingCleanup }, update: Model.updateCleanupMode,
+		msgs: []func(tea.Msg) bool{isMsg[CleanupCandidatesMsg], isMsg[WorktreesArchivedMsg]}},
+	{open: func(m Model) bool { return m.showingPrune }, update: Model.updatePruneMode,
+		msgs: []func(tea.Msg) bool{isMsg[PruneDoneMsg]}},
+	{open: func(m Model) bool { return m.showingRestore }, update: Model.updateRestoreMode,
+		msgs: []func(tea.Msg) bool{isMsg[RestoreDoneMsg]}},
+	{open: func(m Model) bool { return m.showingArchived }, update: Model.updateArchivedMode,
+		msgs: []func(tea.Msg) bool{isMsg[TrashListMsg], isMsg[TrashRestoredMsg], isMsg[TrashPurgedMsg]}},
+	{open: func(m Model) bool { return m.showingRebase }, update: Model.updateRebaseMode,
+		msgs: []func(tea.Msg) bool{isMsg[RebasePlanMsg], isMsg[RebaseResultMsg]}},
+	{open: func(m Model) bool { return m.showingHelp }, update: keyUpdate(Model.updateHelpMode), keysOnly: true},
+	{open: func(m Model) bool { return m.showingErrorLog }, update: keyUpdate(Model.updateErrorLogMode), keysOnly: true},
+	{open: func(m Model) bool { return m.showingAgentActivity }, update: keyUpdate(Model.updateAgentActivityMode), keysOnly: true},
+	{open: func(m Model) bool { return m.showingPanes }, update: Model.updatePanesMode,
+		msgs: []func(tea.Msg) bool{isMsg[PanesMsg], isMsg[PaneActionMsg]}},
+	{open: func(m Model) bool { return m.showingTasks }, update: Model.updateTasksMode,
+		msgs: []func(tea.Msg) bool{isMsg[TasksStartedMsg], isMsg[TasksStatusMsg], isMsg[TasksTickMsg]}},
+	{open: func(m Model) bool { return m.showingQuickDiff }, update: Model.updateQuickDiffMode,
+		msgs: []func(tea.Msg) bool{isMsg[QuickDiffMsg], isMsg[QuickDiffErrMsg]}},
+}
+
+// takes reports whether o, while open, captures msg.
+func (o overlay) takes(msg tea.Msg) bool {
+	switch msg.(type) {
+	case tea.KeyMsg:
+		return true
+	case tea.MouseMsg:
+		return !o.keysOnly
+	}
+	for _, is := range o.msgs {
+		if is(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// activeOverlay returns the update of the first open overlay that captures
+// msg, or nil when msg goes to the sidebar.
+func (m Model) activeOverlay(msg tea.Msg) func(Model, tea.Msg) (tea.Model, tea.Cmd) {
+	for _, o := range overlays {
+		if o.open(m) && o.takes(msg) {
+			return o.update
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestActiveOverlay(t *testing.T) {
+	m := testModel()
+	if m.activeOverlay(tea.KeyMsg{Type: tea.KeyEnter}) != nil {
+		t.Error("keys should go to the sidebar with nothing open")
+	}
+
+	m.showingQuickDiff = true
+	if m.activeOverlay(tea.KeyMsg{Type: tea.KeyEnter}) == nil || m.activeOverlay(QuickDiffMsg{}) == nil {
+		t.Error("the quick diff should take keys and its own messages")
+	}
+	if m.activeOverlay(GitDataMsg{}) != nil {
+		t.Error("git data should flow to the sidebar under the quick diff")
+	}
+
+	m.showingHelp = true
+	if m.activeOverlay(tea.MouseMsg{}) == nil {
+		t.Error("the quick diff under the help should still take the mouse")
+	}
+	m.showingQuickDiff = false
+	if m.activeOverlay(tea.MouseMsg{}) != nil {
+		t.Error("the help takes only keys")
+	}
+}
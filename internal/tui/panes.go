@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+// errNoSession is shown in the pane list of a worktree without a session.
+var errNoSession = errors.New("this worktree has no tmux session yet")
+
+// PanesMsg carries the panes of a worktree's session.
+type PanesMsg struct {
+	WorktreePath string
+	Session      string
+	Panes        []tmux.PaneStatus
+	Err          error
+}
+
+// PaneActionMsg is sent when killing, respawning or typing into a pane
+// finishes.
+type PaneActionMsg struct {
+	Err error
+}
+
+// panesCmd resolves the session of the worktree and lists its panes.
+func panesCmd(runner git.CommandRunner, tmuxRunner tmux.Runner, worktreePath string) tea.Cmd {
+	return func() tea.Msg {
+		getBranch := func(dir string) (string, error) { return git.CurrentBranch(runner, dir) }
+		name := tmux.ResolveSessionName(tmuxRunner, worktreePath, getBranch)
+		exists, err := tmux.HasWorktree(tmuxRunner, name)
+		if err == nil && !exists {
+			err = errNoSession
+		}
+		if err != nil {
+			return PanesMsg{WorktreePath: worktreePath, Err: err}
+		}
+		panes, err := tmux.ListPanes(tmuxRunner, name)
+		return PanesMsg{WorktreePath: worktreePath, Session: name, Panes: panes, Err: err}
+	}
+}
+
+func paneActionCmd(action func() error) tea.Cmd {
+	return func() tea.Msg {
+		return PaneActionMsg{Err: action()}
+	}
+}
+
+// openPanes shows the T view with the panes of the session of the worktree
+// under the cursor.
+func (m Model) openPanes() (Model, tea.Cmd) {
+	if m.tmuxRunner == nil || m.cursor >= len(m.items) || m.items[m.cursor].Kind != model.ItemKindWorktree {
+		return m, nil
+	}
+	item := m.items[m.cursor]
+	m.showingPanes = true
+	m.panesLoading = true
+	m.panesPath = item.WorktreePath
+	m.panesLabel = item.Label
+	m.panesSession = ""
+	m.panes = nil
+	m.panesCursor = 0
+	m.panesErr = nil
+	return m, panesCmd(m.runner, m.tmuxRunner, item.WorktreePath)
+}
+
+func (m Model) updatePanesMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case PanesMsg:
+		if msg.WorktreePath != m.panesPath {
+			return m, nil
+		}
+		m.panesLoading = false
+		m.panesSession = msg.Session
+		m.panes = msg.Panes
+		m.panesCursor = max(min(m.panesCursor, len(m.panes)-1), 0)
+		m.panesErr = msg.Err
+		return m, nil
+
+	case PaneActionMsg:
+		m.panesErr = msg.Err
+		return m, panesCmd(m.runner, m.tmuxRunner, m.panesPath)
+
+	case tea.KeyMsg:
+		if m.panesSending {
+			return m.updatePaneSend(msg)
+		}
+		if key.Matches(msg, globalKeys.ForceQuit) {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		switch {
+		case key.Matches(msg, panesKeys.Close):
+			m.showingPanes = false
+			m.panes = nil
+			m.panesErr = nil
+			return m, nil
+		case key.Matches(msg, panesKeys.Down):
+			if m.panesCursor < len(m.panes)-1 {
+				m.panesCursor++
+			}
+			return m, nil
+		case key.Matches(msg, panesKeys.Up):
+			if m.panesCursor > 0 {
+				m.panesCursor--
+			}
+			return m, nil
+		}
+		if m.panesLoading || m.panesCursor >= len(m.panes) {
+			return m, nil
+		}
+		pane := m.panes[m.panesCursor].PaneID
+		switch {
+		case key.Matches(msg, panesKeys.Kill):
+			return m, paneActionCmd(func() error { return tmux.KillPane(m.tmuxRunner, pane) })
+		case key.Matches(msg, panesKeys.Respawn):
+			return m, paneActionCmd(func() error { return tmux.RespawnPane(m.tmuxRunner, pane) })
+		case key.Matches(msg, panesKeys.Send):
+			m.panesSending = true
+			m.textInput.Placeholder = "command to run in " + pane
+			m.textInput.SetValue("")
+			return m, m.textInput.Focus()
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updatePaneSend(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		m.panesSending = false
+		m.textInput.SetValue("")
+		return m, nil
+	case tea.KeyEnter:
+		command := strings.TrimSpace(m.textInput.Value())
+		m.panesSending = false
+		m.textInput.SetValue("")
+		if command == "" || m.panesCursor >= len(m.panes) {
+			return m, nil
+		}
+		pane := m.panes[m.panesCursor].PaneID
+		return m, paneActionCmd(func() error { return tmux.SendKeys(m.tmuxRunner, pane, command) })
+	case tea.KeyCtrlC:
+		m.quitting = true
+		return m, tea.Quit
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// paneLine shows a pane as its window, command and title, with how long ago
+// its window was last active.
+func paneLine(p tmux.PaneStatus, now time.Time) string {
+	line := fmt.Sprintf("%s %s %s", p.PaneID, p.Window, p.Command)
+	if p.Title != "" && p.Title != p.Command {
+		line += " " + sortLabelStyle.Render(p.Title)
+	}
+	if !p.Activity.IsZero() {
+		line += " " + sortLabelStyle.Render(formatRunDuration(now.Sub(p.Activity))+" ago")
+	}
+	return line
+}
+
+func renderPanesView(m Model) string {
+	var b strings.Builder
+	title := "Panes: " + m.panesLabel
+	if m.panesSession != "" && m.panesSession != m.panesLabel {
+		title += " (" + m.panesSession + ")"
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n")
+
+	help := keyhelp.ShortHelp(keyhelp.Bindings(panesKeys)...)
+	switch {
+	case m.panesLoading:
+		b.WriteString("  Listing panes...\n")
+	case len(m.panes) == 0:
+		help = keyhelp.ShortHelp(panesKeys.Close)
+	default:
+		clip := lipgloss.NewStyle().MaxWidth(max(m.sidebarWidth, 20))
+		selectedStyle := lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
+		now := time.Now()
+		for i, p := range m.panes {
+			line := "  " + paneLine(p, now)
+			if i == m.panesCursor {
+				line = selectedStyle.Render("> ") + paneLine(p, now)
+			}
+			b.WriteString(clip.Render(line))
+			b.WriteString("\n")
+		}
+	}
+
+	if m.panesErr != nil {
+		b.WriteString(renderErrorBlock(m.panesErr, m.width))
+		b.WriteString("\n")
+	}
+	if m.panesSending {
+		b.WriteString(helpStyle.Render("$ " + m.textInput.View()))
+		return b.String()
+	}
+	b.WriteString(helpStyle.Render(help))
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+func TestPanes_KillAndSend(t *testing.T) {
+	runner := &tmux.FakeRunner{Outputs: map[string]string{
+		"[kill-pane -t %1]":                "",
+		"[send-keys -t %0 make dev Enter]": "",
+	}}
+	m := testModel()
+	m.tmuxRunner = runner
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	m = result.(Model)
+	if !m.showingPanes || cmd == nil {
+		t.Fatal("T should open the pane list and load it")
+	}
+
+	result, _ = m.Update(PanesMsg{WorktreePath: m.panesPath, Session: "repo1", Panes: []tmux.PaneStatus{
+		{PaneID: "%0", Window: "main-window", Command: "zsh"},
+		{PaneID: "%1", Window: "main-window", Command: "node", Title: "✳ claude"},
+	}})
+	m = result.(Model)
+	if view := renderPanesView(m); !strings.Contains(view, "%1 main-window node") {
+		t.Errorf("view should list the panes, got %q", view)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m = result.(Model)
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if msg := cmd(); msg.(PaneActionMsg).Err != nil {
+		t.Fatalf("kill failed: %v", msg.(PaneActionMsg).Err)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	m = result.(Model)
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m = result.(Model)
+	if !m.panesSending {
+		t.Fatal("s should ask for a command")
+	}
+	m.textInput.SetValue("make dev")
+	result, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if result.(Model).panesSending || cmd == nil {
+		t.Fatal("enter should send the command")
+	}
+	if msg := cmd(); msg.(PaneActionMsg).Err != nil {
+		t.Fatalf("send failed: %v", msg.(PaneActionMsg).Err)
+	}
+	if len(runner.Calls) != 2 {
+		t.Errorf("tmux calls = %v", runner.Calls)
+	}
+}
+
+func TestPanes_NoTmux(t *testing.T) {
+	m := testModel()
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	if result.(Model).showingPanes || cmd != nil {
+		t.Error("the pane list needs tmux")
+	}
+}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/pathcomplete"
+)
+
+// pathPickerMax caps the directories listed under the add-repo input.
+const pathPickerMax = 8
+
+// pathMatches returns the suggested directories completing the input: those
+// being cycled through with tab, or else the ones it is a prefix of.
+func (m Model) pathMatches() []string {
+	if m.pathCycle != nil {
+		return m.pathCycle
+	}
+	input := m.textInput.Value()
+	if input == "" {
+		return nil
+	}
+	var matches []string
+	for _, s := range m.textInput.AvailableSuggestions() {
+		if strings.HasPrefix(s, input) && s != input {
+			matches = append(matches, s)
+		}
+	}
+	return matches
+}
+
+// completePath completes the input with the suggestion delta away from the
+// current one. A single match is taken at once, and the directories in it
+// are fetched so the next tab goes on from there; with more, tab and
+// shift+tab cycle through them until another key is pressed.
+func (m Model) completePath(delta int) (Model, tea.Cmd) {
+	matches := m.pathMatches()
+	switch {
+	case len(matches) == 0:
+		return m, nil
+	case len(matches) == 1:
+		m.pathCycle = nil
+		m.textInput.SetValue(matches[0])
+		m.textInput.CursorEnd()
+		return m.fetchPathSuggestions()
+	case m.pathCycle == nil:
+		m.pathCycle = matches
+		m.pathCursor = 0
+		if delta < 0 {
+			m.pathCursor = len(matches) - 1
+		}
+	default:
+		m.pathCursor = (m.pathCursor + delta + len(matches)) % len(matches)
+	}
+	m.textInput.SetValue(m.pathCycle[m.pathCursor])
+	m.textInput.CursorEnd()
+	return m, nil
+}
+
+// fetchPathSuggestions fetches the directories under the input when it is
+// in a directory they were not fetched for.
+func (m Model) fetchPathSuggestions() (Model, tea.Cmd) {
+	homeDir, _ := os.UserHomeDir()
+	value := m.textInput.Value()
+	dir := pathcomplete.ExtractDir(value, homeDir)
+	if dir == m.lastSuggestionDir {
+		return m, nil
+	}
+	m.lastSuggestionDir = dir
+	return m, fetchPathSuggestionsCmd(value)
+}
+
+// renderPathPicker returns one line per matching directory, at most
+// pathPickerMax around the one picked with tab. Git repositories, listed
+// first, are tagged to tell them from directories to go on through.
+func renderPathPicker(m Model) []string {
+	matches := m.pathMatches()
+	if len(matches) == 0 {
+		return nil
+	}
+	cursor := -1
+	if m.pathCycle != nil {
+		cursor = m.pathCursor
+	}
+	start := 0
+	if cursor >= pathPickerMax {
+		start = cursor - pathPickerMax + 1
+	}
+	end := min(start+pathPickerMax, len(matches))
+
+	selectedStyle := lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
+	var lines []string
+	for i := start; i < end; i++ {
+		var line string
+		if i == cursor {
+			line = selectedStyle.Render("> " + matches[i])
+		} else {
+			line = sortLabelStyle.Render("  " + matches[i])
+		}
+		if slices.Contains(m.pathRepos, matches[i]) {
+			line += markStyle.Render(" git")
+		}
+		lines = append(lines, line)
+	}
+	if more := len(matches) - end; more > 0 {
+		lines = append(lines, sortLabelStyle.Render(fmt.Sprintf("  … %d more", more)))
+	}
+	return lines
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func pathPickerModel(value string, suggestions ...string) Model {
+	m := addRepoModel()
+	m.textInput.SetValue(value)
+	m.textInput.CursorEnd()
+	m.textInput.SetSuggestions(suggestions)
+	m.lastSuggestionDir = "/code/"
+	return m
+}
+
+func TestAddRepo_TabCyclesSuggestions(t *testing.T) {
+	m := pathPickerModel("/code/a", "/code/api/", "/code/app/", "/code/web/")
+
+	var result tea.Model = m
+	press := func(k tea.KeyType) Model {
+		result, _ = result.(Model).Update(tea.KeyMsg{Type: k})
+		return result.(Model)
+	}
+
+	if got := press(tea.KeyTab).textInput.Value(); got != "/code/api/" {
+		t.Errorf("first tab: value = %q, want /code/api/", got)
+	}
+	if got := press(tea.KeyTab).textInput.Value(); got != "/code/app/" {
+		t.Errorf("second tab: value = %q, want /code/app/", got)
+	}
+	if got := press(tea.KeyTab).textInput.Value(); got != "/code/api/" {
+		t.Errorf("tab should wrap around: value = %q", got)
+	}
+	if got := press(tea.KeyShiftTab).textInput.Value(); got != "/code/app/" {
+		t.Errorf("shift+tab: value = %q, want /code/app/", got)
+	}
+
+	// Typing keeps the picked directory and fetches its subdirectories.
+	var cmd tea.Cmd
+	result, cmd = result.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	updated := result.(Model)
+	if updated.pathCycle != nil || updated.textInput.Value() != "/code/app/s" {
+		t.Errorf("after typing: cycle %q, value %q", updated.pathCycle, updated.textInput.Value())
+	}
+	if updated.lastSuggestionDir != "/code/app/" || cmd == nil {
+		t.Errorf("subdirectories of the picked one should be fetched, lastSuggestionDir = %q", updated.lastSuggestionDir)
+	}
+}
+
+func TestAddRepo_TabTakesSingleMatch(t *testing.T) {
+	m := pathPickerModel("/code/w", "/code/api/", "/code/web/")
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	updated := result.(Model)
+	if updated.textInput.Value() != "/code/web/" || updated.pathCycle != nil {
+		t.Errorf("value = %q, cycle %q; want /code/web/ taken outright", updated.textInput.Value(), updated.pathCycle)
+	}
+	if cmd == nil || updated.lastSuggestionDir != "/code/web/" {
+		t.Errorf("the next tab should complete within it, lastSuggestionDir = %q", updated.lastSuggestionDir)
+	}
+
+	// Nothing to complete.
+	m = pathPickerModel("/code/x", "/code/api/")
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := result.(Model).textInput.Value(); got != "/code/x" {
+		t.Errorf("value = %q, want it unchanged", got)
+	}
+}
+
+func TestRenderAddRepoView_PathPicker(t *testing.T) {
+	m := pathPickerModel("/code/a", "/code/api/", "/code/app/", "/code/web/")
+	m.width, m.height = 80, 30
+
+	view := renderAddRepoView(m)
+	if !strings.Contains(view, "/code/api/") || !strings.Contains(view, "/code/app/") || strings.Contains(view, "/code/web/") {
+		t.Errorf("the dropdown should list the matching directories:\n%s", view)
+	}
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if view := renderAddRepoView(result.(Model)); !strings.Contains(view, "> /code/app/") {
+		t.Errorf("the picked directory should be marked:\n%s", view)
+	}
+}
+
+func TestFetchPathSuggestionsCmd_ReposFirst(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"zeta/.git", "notes", "api/.git"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msg := fetchPathSuggestionsCmd(root + "/")().(PathSuggestionsMsg)
+	want := []string{root + "/api/", root + "/zeta/", root + "/notes/"}
+	if !slices.Equal(msg.Suggestions, want) {
+		t.Errorf("suggestions = %q, want the repositories first, then plain directories", msg.Suggestions)
+	}
+	if !slices.Equal(msg.Repos, want[:2]) {
+		t.Errorf("repos = %q, want api and zeta", msg.Repos)
+	}
+}
+
+func TestRenderPathPicker_TagsRepos(t *testing.T) {
+	m := pathPickerModel("/code/", "/code/api/", "/code/notes/")
+	m.pathRepos = []string{"/code/api/"}
+
+	lines := renderPathPicker(m)
+	if len(lines) != 2 || !strings.Contains(lines[0], "git") || strings.Contains(lines[1], "git") {
+		t.Errorf("lines = %q, want only the repository tagged", lines)
+	}
+}
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// PinStore persists which worktrees are pinned to the top of their group.
+type PinStore interface {
+	Get() map[string]bool
+	Set(worktreePath string, pinned bool) error
+}
+
+// WithPinStore returns a copy of the model that restores pinned worktrees
+// from store and saves them whenever a worktree is pinned or unpinned.
+func (m Model) WithPinStore(store PinStore) Model {
+	m.pinStore = store
+	if store != nil {
+		m.pinned = store.Get()
+	}
+	return m
+}
+
+// togglePin pins or unpins the worktree under the cursor, leaving the cursor
+// on it as it moves within its group.
+func (m Model) togglePin() Model {
+	if m.cursor >= len(m.items) || m.items[m.cursor].Kind != model.ItemKindWorktree {
+		return m
+	}
+	path := m.items[m.cursor].WorktreePath
+	pinned := make(map[string]bool, len(m.pinned)+1)
+	for p := range m.pinned {
+		pinned[p] = true
+	}
+	if pinned[path] {
+		delete(pinned, path)
+	} else {
+		pinned[path] = true
+	}
+	m.pinned = pinned
+
+	if m.pinStore != nil {
+		if err := m.pinStore.Set(path, pinned[path]); err != nil {
+			logging.For("sidebar").Warn("saving pinned worktrees failed", "err", err)
+		}
+	}
+	return rebuildItems(m)
+}
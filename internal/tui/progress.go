@@ -0,0 +1,161 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+)
+
+// ProgressMsg names the step a long operation (adding, archiving, rebasing)
+// has got to. Done is sent once the operation has finished.
+type ProgressMsg struct {
+	ID    int
+	Step  string
+	Done  bool
+	steps <-chan string
+}
+
+// progressRunner reports the git commands worth naming, such as a fetch or a
+// worktree add, on steps before running them.
+type progressRunner struct {
+	git.CommandRunner
+	steps chan<- string
+}
+
+func (r progressRunner) Run(dir string, args ...string) (string, error) {
+	r.report(args)
+	return r.CommandRunner.Run(dir, args...)
+}
+
+// RunEnv keeps git.RunRebase working through the wrapper.
+func (r progressRunner) RunEnv(dir string, env []string, args ...string) (string, error) {
+	envRunner, ok := r.CommandRunner.(git.EnvRunner)
+	if !ok {
+		return "", fmt.Errorf("git runner cannot set environment variables")
+	}
+	r.report(args)
+	return envRunner.RunEnv(dir, env, args...)
+}
+
+func (r progressRunner) report(args []string) {
+	step := stepName(args)
+	if step == "" {
+		return
+	}
+	// The spinner only shows the latest step; never hold git up for it.
+	select {
+	case r.steps <- step:
+	default:
+	}
+}
+
+// stepName describes the git command args as a step of an operation, or
+// returns "" for quick queries not worth showing.
+func stepName(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	last := args[len(args)-1]
+	switch args[0] {
+	case "fetch":
+		if len(args) == 3 && args[1] == "origin" {
+			return "Fetching " + last + " from origin..."
+		}
+		return "Fetching origin..."
+	case "rebase":
+		switch last {
+		case "--continue":
+			return "Continuing the rebase..."
+		case "--abort":
+			return "Aborting the rebase..."
+		}
+		return "Rebasing onto " + shortRef(last) + "..."
+	case "worktree":
+		if len(args) < 2 {
+			return ""
+		}
+		switch args[1] {
+		case "add":
+			return "Checking out the worktree..."
+		case "move":
+			return "Moving " + filepath.Base(args[2]) + " to the trash..."
+		case "remove":
+			return "Removing " + filepath.Base(last) + "..."
+		}
+	}
+	return ""
+}
+
+// shortRef abbreviates a commit hash the way git does, leaving names alone.
+func shortRef(ref string) string {
+	if len(ref) == 40 && strings.Trim(ref, "0123456789abcdef") == "" {
+		return ref[:7]
+	}
+	return ref
+}
+
+// withProgress starts op with a git runner that reports its steps, showing
+// label with a spinner until the first step comes in.
+func (m Model) withProgress(label string, op func(runner git.CommandRunner) tea.Cmd) (Model, tea.Cmd) {
+	steps := make(chan string, 8)
+	run := op(progressRunner{CommandRunner: m.runner, steps: steps})
+
+	m.progressID++
+	m.progressing = true
+	m.progressStep = label
+	m.spinner = spinner.New(spinner.WithSpinner(spinner.MiniDot), spinner.WithStyle(lipgloss.NewStyle().Foreground(colorAccent)))
+
+	done := func() tea.Msg {
+		defer close(steps)
+		return run()
+	}
+	return m, tea.Batch(done, waitForStep(m.progressID, steps), m.spinner.Tick)
+}
+
+// waitForStep delivers the next step reported on steps, then Done once the
+// operation has closed it.
+func waitForStep(id int, steps <-chan string) tea.Cmd {
+	return func() tea.Msg {
+		step, ok := <-steps
+		if !ok {
+			return ProgressMsg{ID: id, Done: true}
+		}
+		return ProgressMsg{ID: id, Step: step, steps: steps}
+	}
+}
+
+func (m Model) updateProgress(msg ProgressMsg) (Model, tea.Cmd) {
+	if msg.ID != m.progressID {
+		return m, nil
+	}
+	if msg.Done {
+		m.progressing = false
+		return m, nil
+	}
+	m.progressStep = msg.Step
+	return m, waitForStep(msg.ID, msg.steps)
+}
+
+func (m Model) updateSpinner(msg spinner.TickMsg) (Model, tea.Cmd) {
+	if !m.progressing {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+	return m, cmd
+}
+
+// progressLine is the spinner and current step while an operation runs, and
+// fallback otherwise.
+func (m Model) progressLine(fallback string) string {
+	if !m.progressing {
+		return fallback
+	}
+	return m.spinner.View() + " " + m.progressStep
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// opMsg runs the operation started by withProgress and returns its result,
+// skipping the progress and spinner cmds batched with it.
+func opMsg(t *testing.T, cmd tea.Cmd) tea.Msg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected an operation to start")
+	}
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) == 0 {
+		t.Fatalf("expected the batch started by withProgress, got %T", cmd())
+	}
+	return batch[0]()
+}
+
+func TestStepName(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"fetch", "origin", "feature/login"}, "Fetching feature/login from origin..."},
+		{[]string{"fetch", "--prune", "origin"}, "Fetching origin..."},
+		{[]string{"worktree", "add", "/wt/login", "-b", "u/login", "main"}, "Checking out the worktree..."},
+		{[]string{"worktree", "remove", "/wt/login"}, "Removing login..."},
+		{[]string{"worktree", "move", "/wt/login", "/trash/login"}, "Moving login to the trash..."},
+		{[]string{"rebase", "-i", "--autostash", "0123456789abcdef0123456789abcdef01234567"}, "Rebasing onto 0123456..."},
+		{[]string{"rebase", "--continue"}, "Continuing the rebase..."},
+		{[]string{"config", "user.name"}, ""},
+	} {
+		if got := stepName(tt.args); got != tt.want {
+			t.Errorf("stepName(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestWithProgress_ShowsEachStepUntilDone(t *testing.T) {
+	m := testModel()
+	m.addingWorktree = true
+	m.addingWorktreeRepoPath = "/code/repo1"
+	m.branchCursor = -1
+	basePath := t.TempDir()
+	m.config = model.Config{
+		WorktreeBasePath: basePath,
+		DefaultBaseRef:   "origin/main",
+		Repositories:     []model.RepositoryDef{{Name: "repo1", Path: "/code/repo1"}},
+	}
+	wantPath := filepath.Join(basePath, "repo1", "fix-login")
+	m.runner = git.FakeCommandRunner{Outputs: map[string]string{
+		"/code/repo1:[config user.name]":  "Alice\n",
+		"/code/repo1:[fetch origin main]": "",
+		fmt.Sprintf("/code/repo1:%v", []string{"worktree", "add", wantPath, "-b", "alice/fix-login", "origin/main"}): "",
+	}}
+	m.textInput.SetValue("fix-login")
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if !strings.Contains(m.View(), "Creating worktree...") {
+		t.Errorf("the label should show before the first step:\n%s", m.View())
+	}
+	batch := cmd().(tea.BatchMsg)
+	if _, ok := batch[0]().(WorktreeAddedMsg); !ok {
+		t.Fatal("the operation should still add the worktree")
+	}
+
+	// The steps were buffered while the operation ran; drain them in order.
+	var views []string
+	next := batch[1]
+	for next != nil {
+		result, next = m.Update(next())
+		m = result.(Model)
+		if m.progressing {
+			views = append(views, m.View())
+		}
+	}
+	if len(views) != 2 || !strings.Contains(views[0], "Fetching main from origin...") || !strings.Contains(views[1], "Checking out the worktree...") {
+		t.Errorf("views = %q, want the fetch then the checkout", views)
+	}
+	if m.progressing {
+		t.Error("progress should stop once the operation is done")
+	}
+}
+
+func TestUpdateProgress_IgnoresStaleOperations(t *testing.T) {
+	m := testModel()
+	m.progressID = 2
+	m.progressing = true
+	m.progressStep = "Rebasing..."
+
+	m, cmd := m.updateProgress(ProgressMsg{ID: 1, Done: true})
+	if !m.progressing || cmd != nil {
+		t.Error("a finished earlier operation should not stop the current one")
+	}
+	m, _ = m.updateProgress(ProgressMsg{ID: 1, Step: "Fetching origin..."})
+	if m.progressStep != "Rebasing..." {
+		t.Errorf("progressStep = %q, want the current operation's", m.progressStep)
+	}
+}
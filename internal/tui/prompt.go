@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+// PromptSentMsg is sent when a prompt has been typed into an agent's pane.
+type PromptSentMsg struct {
+	Agent string
+	Label string
+}
+
+// PromptSendErrMsg is sent when typing a prompt into an agent's pane fails.
+type PromptSendErrMsg struct {
+	Err error
+}
+
+func sendPromptCmd(runner tmux.Runner, paneID, text, agent, label string) tea.Cmd {
+	return func() tea.Msg {
+		if err := tmux.SendPrompt(runner, paneID, text); err != nil {
+			return PromptSendErrMsg{Err: err}
+		}
+		return PromptSentMsg{Agent: agent, Label: label}
+	}
+}
+
+// promptTarget picks the agent of a worktree to send a prompt to: the first
+// idle one, else the first running, which queues it. An agent waiting for
+// permission is skipped, since the text would answer its question.
+func promptTarget(agents []model.AgentInfo) (model.AgentInfo, bool) {
+	for _, state := range []model.AgentState{model.AgentStateIdle, model.AgentStateRunning} {
+		if i := slices.IndexFunc(agents, func(a model.AgentInfo) bool { return a.State == state }); i >= 0 {
+			return agents[i], true
+		}
+	}
+	return model.AgentInfo{}, false
+}
+
+// startSendPrompt opens the i prompt for the agent of the worktree under the
+// cursor, or says why there is none to send to.
+func (m Model) startSendPrompt() (Model, tea.Cmd) {
+	if m.tmuxRunner == nil || m.cursor >= len(m.items) || m.items[m.cursor].Kind != model.ItemKindWorktree {
+		return m, nil
+	}
+	item := m.items[m.cursor]
+	agents := m.agentStatus[item.WorktreePath]
+	a, ok := promptTarget(agents)
+	if !ok {
+		if len(agents) > 0 {
+			return m.notify(SeverityInfo, "The agent in "+item.Label+" is waiting for input; answer it in its pane", "")
+		}
+		return m.notify(SeverityInfo, "No agent is running in "+item.Label, "")
+	}
+	m.sendingPrompt = true
+	m.promptPane = a.PaneID
+	m.promptAgent = cmp.Or(a.Agent, "agent")
+	m.promptLabel = item.Label
+	m.err = nil
+	m.textInput.Placeholder = "what should it do next?"
+	m.textInput.SetValue("")
+	return m, m.textInput.Focus()
+}
+
+func (m Model) updateSendPromptMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEscape:
+			m.sendingPrompt = false
+			m.textInput.SetValue("")
+			return m, nil
+		case tea.KeyEnter:
+			text := strings.TrimSpace(m.textInput.Value())
+			m.textInput.SetValue("")
+			m.sendingPrompt = false
+			if text == "" {
+				return m, nil
+			}
+			return m, sendPromptCmd(m.tmuxRunner, m.promptPane, text, m.promptAgent, m.promptLabel)
+		case tea.KeyCtrlC:
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func renderSendPromptView(m Model) string {
+	return modalLayout{
+		title:  "Send Prompt",
+		prompt: fmt.Sprintf("Prompt for %s in %s:", m.promptAgent, m.promptLabel),
+		input:  m.textInput.View(),
+		help:   "enter: send  esc: cancel",
+	}.render(m.width, m.height)
+}
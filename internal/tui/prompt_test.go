@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+func TestPromptTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		agents []model.AgentInfo
+		want   string
+	}{
+		{"idle first", []model.AgentInfo{{PaneID: "%1", State: model.AgentStateRunning}, {PaneID: "%2", State: model.AgentStateIdle}}, "%2"},
+		{"queues on a running agent", []model.AgentInfo{{PaneID: "%1", State: model.AgentStateRunning}}, "%1"},
+		{"skips waiting", []model.AgentInfo{{PaneID: "%1", State: model.AgentStateWaiting}}, ""},
+		{"no agent", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := promptTarget(tt.agents)
+			if a.PaneID != tt.want {
+				t.Errorf("promptTarget() = %q, want %q", a.PaneID, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendPrompt(t *testing.T) {
+	runner := &tmux.FakeRunner{Outputs: map[string]string{
+		"[send-keys -t %0 -l add tests]": "",
+		"[send-keys -t %0 Enter]":        "",
+	}}
+	m := testModel()
+	m.tmuxRunner = runner
+	m.agentStatus = map[string][]model.AgentInfo{
+		"/code/repo1": {{PaneID: "%0", Agent: "claude", State: model.AgentStateIdle}},
+	}
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m = result.(Model)
+	if !m.sendingPrompt {
+		t.Fatal("i should open the prompt")
+	}
+	m.textInput.SetValue("add tests")
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if m.sendingPrompt || cmd == nil {
+		t.Fatal("enter should close the prompt and send it")
+	}
+	msg := cmd()
+	if sent, ok := msg.(PromptSentMsg); !ok || sent.Agent != "claude" || sent.Label != "main" {
+		t.Fatalf("got %#v, want PromptSentMsg for claude in main", msg)
+	}
+	if len(runner.Calls) != 2 {
+		t.Errorf("tmux calls = %v", runner.Calls)
+	}
+
+	result, _ = m.Update(msg)
+	if got := result.(Model).toast.Text; got != "Sent to claude in main" {
+		t.Errorf("toast = %q", got)
+	}
+}
+
+func TestSendPrompt_NoAgent(t *testing.T) {
+	m := testModel()
+	m.tmuxRunner = &tmux.FakeRunner{}
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m = result.(Model)
+	if m.sendingPrompt {
+		t.Error("the prompt should not open without an agent")
+	}
+	if m.toast.Text != "No agent is running in main" {
+		t.Errorf("toast = %q", m.toast.Text)
+	}
+}
@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/forge"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// prStatusTTL is how long fetched PR badges are trusted before gh is asked
+// again. Sidebar reloads within the window reuse the cache.
+const prStatusTTL = time.Minute
+
+// PRStatusTickMsg triggers a refresh of stale PR badges.
+type PRStatusTickMsg time.Time
+
+// PRStatusMsg delivers the open PRs of one repository, keyed by head branch.
+type PRStatusMsg struct {
+	RepoPath string
+	Statuses map[string]model.PRStatus
+	Err      error
+}
+
+func prStatusTickCmd() tea.Cmd {
+	return tea.Tick(prStatusTTL, func(t time.Time) tea.Msg {
+		return PRStatusTickMsg(t)
+	})
+}
+
+// fetchPRStatusCmd lists the open PRs of a repository. Repositories on other
+// forges get an empty result so they are not asked again until the cache
+// expires.
+func fetchPRStatusCmd(ghRunner github.Runner, gitRunner git.CommandRunner, repoPath, configuredForge string) tea.Cmd {
+	return func() tea.Msg {
+		if forge.KindFor(configuredForge, gitRunner, repoPath) != forge.KindGitHub {
+			return PRStatusMsg{RepoPath: repoPath}
+		}
+		prs, err := github.FetchOpenPRs(ghRunner, repoPath)
+		if err != nil {
+			return PRStatusMsg{RepoPath: repoPath, Err: err}
+		}
+		statuses := make(map[string]model.PRStatus, len(prs))
+		for _, pr := range prs {
+			statuses[pr.HeadRefName] = model.PRStatus{
+				Number: pr.Number,
+				Checks: checkState(pr.CheckRollup()),
+			}
+		}
+		return PRStatusMsg{RepoPath: repoPath, Statuses: statuses}
+	}
+}
+
+func checkState(rollup string) model.CheckState {
+	switch rollup {
+	case github.RollupSuccess:
+		return model.ChecksPassing
+	case github.RollupFailure:
+		return model.ChecksFailing
+	case github.RollupPending:
+		return model.ChecksPending
+	}
+	return model.ChecksNone
+}
+
+// refreshPRStatus returns commands fetching PR badges and base branch CI for
+// every repository whose cache is older than prStatusTTL, and starts the
+// refresh tick. It is a no-op without a GitHub runner.
+func (m Model) refreshPRStatus() (Model, tea.Cmd) {
+	ghRunner := m.forgeOpts.GitHubRunner
+	if ghRunner == nil {
+		return m, nil
+	}
+	if m.prFetchedAt == nil {
+		m.prFetchedAt = make(map[string]time.Time)
+	}
+
+	var cmds []tea.Cmd
+	now := time.Now()
+	for _, group := range m.groups {
+		if now.Sub(m.prFetchedAt[group.RootPath]) < prStatusTTL {
+			continue
+		}
+		m.prFetchedAt[group.RootPath] = now
+		cmds = append(cmds,
+			fetchPRStatusCmd(ghRunner, m.runner, group.RootPath, m.repoForge(group.RootPath)),
+			fetchBaseChecksCmd(m.providerOpts(), group.RootPath, m.repoForge(group.RootPath), config.BaseRef(m.config, group.RootPath)),
+		)
+	}
+	if !m.prTickRunning {
+		m.prTickRunning = true
+		cmds = append(cmds, prStatusTickCmd())
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// repoForge returns the `forge` setting of the repository at repoPath.
+func (m Model) repoForge(repoPath string) string {
+	for _, repo := range m.config.Repositories {
+		if repo.Path == repoPath {
+			return repo.Forge
+		}
+	}
+	return ""
+}
+
+// prStatusFor returns the badge data of a worktree row.
+func (m Model) prStatusFor(item model.NavigableItem) model.PRStatus {
+	return m.prStatus[item.RepoRootPath][item.Label]
+}
+
+// applyPRStatus stores a fetch result and updates the visible rows. Failed
+// fetches keep the previous badges.
+func (m Model) applyPRStatus(msg PRStatusMsg) Model {
+	if msg.Err != nil {
+		logging.For("pr-status").Warn("fetching PRs failed (non-fatal)", "repo", msg.RepoPath, "err", msg.Err)
+		return m
+	}
+	if m.prStatus == nil {
+		m.prStatus = make(map[string]map[string]model.PRStatus)
+	}
+	m.prStatus[msg.RepoPath] = msg.Statuses
+	for i := range m.items {
+		if m.items[i].Kind == model.ItemKindWorktree {
+			m.items[i].PR = m.prStatusFor(m.items[i])
+		}
+	}
+	return m
+}
+
+// PRBadge renders a compact "#123✓" badge for a worktree's open PR, colored
+// by its check status. Returns "" when the branch has no open PR.
+func PRBadge(pr model.PRStatus) string {
+	if pr.Number == 0 {
+		return ""
+	}
+	color, icon := colorFgDim, ""
+	switch pr.Checks {
+	case model.ChecksPassing:
+		color, icon = colorGreen, "✓"
+	case model.ChecksFailing:
+		color, icon = colorRed, "✗"
+	case model.ChecksPending:
+		color, icon = colorYellow, "●"
+	}
+	return " " + lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("#%d%s", pr.Number, icon))
+}
@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/forge"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+const openPRsKey = "/code/repo1:[pr list --state open --limit 100 --json number,headRefName,statusCheckRollup]"
+
+func TestFetchPRStatusCmd(t *testing.T) {
+	gh := &github.FakeRunner{
+		Outputs: map[string]string{
+			openPRsKey: `[{"number": 12, "headRefName": "feature-x", "statusCheckRollup": [{"status": "COMPLETED", "conclusion": "FAILURE"}]}]`,
+		},
+	}
+
+	msg := fetchPRStatusCmd(gh, nil, "/code/repo1", forge.KindGitHub)().(PRStatusMsg)
+	if msg.Err != nil {
+		t.Fatalf("unexpected error: %v", msg.Err)
+	}
+	want := model.PRStatus{Number: 12, Checks: model.ChecksFailing}
+	if got := msg.Statuses["feature-x"]; got != want {
+		t.Errorf("Statuses[feature-x] = %+v, want %+v", got, want)
+	}
+}
+
+func TestFetchPRStatusCmd_OtherForge(t *testing.T) {
+	gh := &github.FakeRunner{}
+
+	msg := fetchPRStatusCmd(gh, nil, "/code/repo1", forge.KindGitLab)().(PRStatusMsg)
+	if msg.Err != nil || len(msg.Statuses) != 0 {
+		t.Errorf("got %+v, want an empty result", msg)
+	}
+	if len(gh.Calls) != 0 {
+		t.Errorf("gh should not be called for GitLab repositories, got %v", gh.Calls)
+	}
+}
+
+func TestRefreshPRStatus_UsesCache(t *testing.T) {
+	m := testModel()
+	m.forgeOpts = forge.Options{GitHubRunner: &github.FakeRunner{}}
+
+	m, cmd := m.refreshPRStatus()
+	if cmd == nil {
+		t.Fatal("first refresh should fetch PRs")
+	}
+	if !m.prTickRunning {
+		t.Error("first refresh should start the tick")
+	}
+	fetchedAt := m.prFetchedAt["/code/repo1"]
+
+	m, cmd = m.refreshPRStatus()
+	if cmd != nil {
+		t.Error("refresh within the TTL should not fetch again")
+	}
+	if !m.prFetchedAt["/code/repo1"].Equal(fetchedAt) {
+		t.Error("cache timestamp should not move within the TTL")
+	}
+}
+
+func TestRefreshPRStatus_NoRunner(t *testing.T) {
+	m := testModel()
+
+	m, cmd := m.refreshPRStatus()
+	if cmd != nil || m.prTickRunning {
+		t.Error("without a GitHub runner nothing should be fetched")
+	}
+}
+
+func TestUpdate_PRStatusMsg_SetsBadges(t *testing.T) {
+	m := testModel()
+
+	result, _ := m.Update(PRStatusMsg{
+		RepoPath: "/code/repo1",
+		Statuses: map[string]model.PRStatus{"feature-x": {Number: 12, Checks: model.ChecksPassing}},
+	})
+	updated := result.(Model)
+
+	for _, item := range updated.items {
+		if item.Kind != model.ItemKindWorktree {
+			continue
+		}
+		want := 0
+		if item.Label == "feature-x" {
+			want = 12
+		}
+		if item.PR.Number != want {
+			t.Errorf("%s: PR.Number = %d, want %d", item.Label, item.PR.Number, want)
+		}
+	}
+}
+
+func TestUpdate_PRStatusMsg_ErrorKeepsBadges(t *testing.T) {
+	m := testModel()
+	result, _ := m.Update(PRStatusMsg{
+		RepoPath: "/code/repo1",
+		Statuses: map[string]model.PRStatus{"feature-x": {Number: 12}},
+	})
+
+	result, _ = result.(Model).Update(PRStatusMsg{RepoPath: "/code/repo1", Err: fmt.Errorf("rate limited")})
+	updated := result.(Model)
+
+	if got := updated.prStatus["/code/repo1"]["feature-x"].Number; got != 12 {
+		t.Errorf("cached PR number = %d, want 12", got)
+	}
+}
+
+func TestPRBadge(t *testing.T) {
+	if got := PRBadge(model.PRStatus{}); got != "" {
+		t.Errorf("no PR should render nothing, got %q", got)
+	}
+	tests := []struct {
+		checks model.CheckState
+		want   string
+	}{
+		{model.ChecksNone, "#7"},
+		{model.ChecksPassing, "#7✓"},
+		{model.ChecksFailing, "#7✗"},
+		{model.ChecksPending, "#7●"},
+	}
+	for _, tt := range tests {
+		if got := PRBadge(model.PRStatus{Number: 7, Checks: tt.checks}); !strings.Contains(got, tt.want) {
+			t.Errorf("PRBadge(%v) = %q, want it to contain %q", tt.checks, got, tt.want)
+		}
+	}
+}
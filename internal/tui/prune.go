@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/audit"
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/prune"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+// PruneDoneMsg is sent when stale worktree metadata and orphaned tmux
+// sessions have been cleaned up.
+type PruneDoneMsg struct {
+	Report prune.Report
+	Err    error
+}
+
+func pruneCmd(runner git.CommandRunner, tmuxRunner tmux.Runner, repoPaths []string, worktreeBases []string, auditLog audit.Log) tea.Cmd {
+	return func() tea.Msg {
+		report, err := prune.Run(runner, tmuxRunner, repoPaths, worktreeBases, auditLog)
+		return PruneDoneMsg{Report: report, Err: err}
+	}
+}
+
+// startPrune opens the prune view and prunes every repository in the sidebar.
+func (m Model) startPrune() (Model, tea.Cmd) {
+	repoPaths := make([]string, 0, len(m.groups))
+	for _, g := range m.groups {
+		repoPaths = append(repoPaths, g.RootPath)
+	}
+	m.showingPrune = true
+	m.pruneRunning = true
+	m.pruneReport = prune.Report{}
+	m.pruneErr = nil
+	return m, pruneCmd(m.runner, m.tmuxRunner, repoPaths, config.WorktreeBases(m.config), m.audit)
+}
+
+func (m Model) updatePruneMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case PruneDoneMsg:
+		m.pruneRunning = false
+		m.pruneReport = msg.Report
+		m.pruneErr = msg.Err
+		if len(msg.Report.Worktrees) == 0 {
+			return m, nil
+		}
+		m.loading = true
+		return m, fetchGitDataCmd(m.config, m.runner, m.statCache)
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, globalKeys.ForceQuit):
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, pruneKeys.Close):
+			if !m.pruneRunning {
+				m.showingPrune = false
+				m.pruneReport = prune.Report{}
+				m.pruneErr = nil
+			}
+		}
+	}
+	return m, nil
+}
+
+func renderPruneView(m Model) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Prune Stale Worktrees"))
+	b.WriteString("\n")
+
+	if m.pruneRunning {
+		b.WriteString("  Pruning worktree metadata and orphaned sessions...\n")
+	} else {
+		for _, line := range strings.Split(m.pruneReport.String(), "\n") {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	if m.pruneErr != nil {
+		b.WriteString(renderErrorBlock(m.pruneErr, m.width))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render(keyhelp.ShortHelp(pruneKeys.Close)))
+	return b.String()
+}
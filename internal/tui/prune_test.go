@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/prune"
+)
+
+func TestUpdate_P_StartsPrune(t *testing.T) {
+	result, cmd := testModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")})
+	m := result.(Model)
+	if !m.showingPrune || !m.pruneRunning || cmd == nil {
+		t.Fatal("P should open the prune view and start pruning")
+	}
+	if !strings.Contains(renderPruneView(m), "Pruning") {
+		t.Error("the view should show progress")
+	}
+
+	// Running, esc is ignored so the report is not lost.
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if !result.(Model).showingPrune {
+		t.Error("esc should not close the view while pruning")
+	}
+}
+
+func TestUpdate_PruneDoneMsg(t *testing.T) {
+	m := testModel()
+	m.showingPrune, m.pruneRunning = true, true
+
+	result, cmd := m.Update(PruneDoneMsg{})
+	updated := result.(Model)
+	if cmd != nil || !strings.Contains(renderPruneView(updated), "Nothing to prune.") {
+		t.Error("an empty report should not reload the sidebar")
+	}
+
+	result, cmd = m.Update(PruneDoneMsg{Report: prune.Report{Worktrees: []string{"/wt/gone"}}, Err: errors.New("/broken: not a git repository")})
+	updated = result.(Model)
+	view := renderPruneView(updated)
+	if cmd == nil || !strings.Contains(view, "pruned worktree /wt/gone") || !strings.Contains(view, "not a git repository") {
+		t.Errorf("pruned worktrees should reload the sidebar and show with the error, got:\n%s", view)
+	}
+
+	result, _ = updated.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if result.(Model).showingPrune {
+		t.Error("esc should close the report")
+	}
+}
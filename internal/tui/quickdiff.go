@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+)
+
+// quickDiffMaxLines caps how much of the patch the quick-diff overlay shows;
+// anything longer belongs in diff-ui.
+const quickDiffMaxLines = 100
+
+// QuickDiffMsg carries the branch diff for the quick-diff overlay.
+type QuickDiffMsg struct {
+	WorktreePath string
+	Stat         string
+	Patch        []string
+	Truncated    bool
+}
+
+// QuickDiffErrMsg is sent when the branch diff cannot be computed.
+type QuickDiffErrMsg struct {
+	WorktreePath string
+	Err          error
+}
+
+func quickDiffCmd(runner git.CommandRunner, worktreePath, baseRef string, strategy git.DiffBase) tea.Cmd {
+	return func() tea.Msg {
+		stat, patch, err := git.GetBranchDiff(runner, worktreePath, baseRef, strategy)
+		if err != nil {
+			return QuickDiffErrMsg{WorktreePath: worktreePath, Err: err}
+		}
+
+		lines := strings.Split(strings.TrimRight(patch, "\n"), "\n")
+		if patch == "" {
+			lines = nil
+		}
+		truncated := len(lines) > quickDiffMaxLines
+		if truncated {
+			lines = lines[:quickDiffMaxLines]
+		}
+		return QuickDiffMsg{
+			WorktreePath: worktreePath,
+			Stat:         strings.TrimRight(stat, "\n"),
+			Patch:        lines,
+			Truncated:    truncated,
+		}
+	}
+}
+
+func (m Model) updateQuickDiffMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case QuickDiffMsg:
+		if msg.WorktreePath == m.quickDiffPath {
+			m.quickDiff = msg
+			m.quickDiffLoading = false
+		}
+		return m, nil
+
+	case QuickDiffErrMsg:
+		if msg.WorktreePath == m.quickDiffPath {
+			m.quickDiffErr = msg.Err
+			m.quickDiffLoading = false
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, quickDiffKeys.Close):
+			m.showingQuickDiff = false
+			m.quickDiff = QuickDiffMsg{}
+			m.quickDiffErr = nil
+			return m, nil
+		case key.Matches(msg, globalKeys.ForceQuit):
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, quickDiffKeys.Down):
+			if m.quickDiffScroll < len(quickDiffLines(m))-1 {
+				m.quickDiffScroll++
+			}
+		case key.Matches(msg, quickDiffKeys.Up):
+			if m.quickDiffScroll > 0 {
+				m.quickDiffScroll--
+			}
+		}
+	}
+	return m, nil
+}
+
+// quickDiffLines returns the overlay body: the stat summary, a blank line,
+// then the (possibly truncated) patch with +/- lines colored.
+func quickDiffLines(m Model) []string {
+	addStyle := lipgloss.NewStyle().Foreground(colorGreen)
+	delStyle := lipgloss.NewStyle().Foreground(colorRed)
+	hunkStyle := lipgloss.NewStyle().Foreground(colorAccent)
+
+	if m.quickDiff.Stat == "" && len(m.quickDiff.Patch) == 0 {
+		return []string{"  No changes"}
+	}
+
+	var lines []string
+	lines = append(lines, strings.Split(m.quickDiff.Stat, "\n")...)
+	lines = append(lines, "")
+	for _, l := range m.quickDiff.Patch {
+		switch {
+		case strings.HasPrefix(l, "+++"), strings.HasPrefix(l, "---"):
+			lines = append(lines, l)
+		case strings.HasPrefix(l, "+"):
+			lines = append(lines, addStyle.Render(l))
+		case strings.HasPrefix(l, "-"):
+			lines = append(lines, delStyle.Render(l))
+		case strings.HasPrefix(l, "@@"):
+			lines = append(lines, hunkStyle.Render(l))
+		default:
+			lines = append(lines, l)
+		}
+	}
+	if m.quickDiff.Truncated {
+		lines = append(lines, helpStyle.Render(fmt.Sprintf("… first %d lines shown, open diff-ui for the rest", quickDiffMaxLines)))
+	}
+	return lines
+}
+
+func renderQuickDiffView(m Model) string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Diff: " + m.quickDiffLabel))
+	b.WriteString("\n")
+
+	switch {
+	case m.quickDiffLoading:
+		b.WriteString("  Loading diff...\n")
+	case m.quickDiffErr != nil:
+		b.WriteString(renderErrorBlock(m.quickDiffErr, m.width))
+		b.WriteString("\n")
+	default:
+		lines := quickDiffLines(m)
+		start := min(m.quickDiffScroll, max(len(lines)-1, 0))
+		end := len(lines)
+		if vp := viewportHeight(m.height); vp > 0 && start+vp < end {
+			end = start + vp
+		}
+		clip := lipgloss.NewStyle().MaxWidth(max(m.sidebarWidth, 20))
+		for _, l := range lines[start:end] {
+			b.WriteString(clip.Render(l))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(helpStyle.Render(keyhelp.ShortHelp(keyhelp.Bindings(quickDiffKeys)...)))
+	return b.String()
+}
@@ -0,0 +1,365 @@
+package tui
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+)
+
+// RebasePlanMsg carries the commits of a worktree's branch for the rebase
+// planner, or the conflicted files when a rebase is already in progress.
+type RebasePlanMsg struct {
+	WorktreePath string
+	Steps        []git.RebaseStep
+	Onto         string
+	InProgress   bool
+	Conflicts    []string
+	Err          error
+}
+
+// RebaseResultMsg is sent when a rebase command finishes. Non-empty Conflicts
+// means the rebase stopped and is still in progress.
+type RebaseResultMsg struct {
+	WorktreePath string
+	Conflicts    []string
+	Err          error
+}
+
+func rebasePlanCmd(runner git.CommandRunner, worktreePath, baseRef string) tea.Cmd {
+	return func() tea.Msg {
+		inProgress, err := git.RebaseInProgress(runner, worktreePath)
+		if err != nil {
+			return RebasePlanMsg{WorktreePath: worktreePath, Err: err}
+		}
+		if inProgress {
+			files, err := git.ConflictedFiles(runner, worktreePath)
+			return RebasePlanMsg{WorktreePath: worktreePath, InProgress: true, Conflicts: files, Err: err}
+		}
+		steps, onto, err := git.RebasePlan(runner, worktreePath, baseRef)
+		return RebasePlanMsg{WorktreePath: worktreePath, Steps: steps, Onto: onto, Err: err}
+	}
+}
+
+func runRebaseCmd(runner git.CommandRunner, worktreePath, onto string, steps []git.RebaseStep) tea.Cmd {
+	return func() tea.Msg {
+		return rebaseResult(runner, worktreePath, git.RunRebase(runner, worktreePath, onto, steps))
+	}
+}
+
+func continueRebaseCmd(runner git.CommandRunner, worktreePath string) tea.Cmd {
+	return func() tea.Msg {
+		return rebaseResult(runner, worktreePath, git.ContinueRebase(runner, worktreePath))
+	}
+}
+
+func abortRebaseCmd(runner git.CommandRunner, worktreePath string) tea.Cmd {
+	return func() tea.Msg {
+		return RebaseResultMsg{WorktreePath: worktreePath, Err: git.AbortRebase(runner, worktreePath)}
+	}
+}
+
+// rebaseResult turns a stop on conflicts into the list of files to resolve.
+func rebaseResult(runner git.CommandRunner, worktreePath string, err error) RebaseResultMsg {
+	if errors.Is(err, git.ErrRebaseConflict) {
+		files, ferr := git.ConflictedFiles(runner, worktreePath)
+		if ferr == nil {
+			return RebaseResultMsg{WorktreePath: worktreePath, Conflicts: files}
+		}
+	}
+	return RebaseResultMsg{WorktreePath: worktreePath, Err: err}
+}
+
+func (m Model) updateRebaseMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case RebasePlanMsg:
+		if msg.WorktreePath == m.rebasePath {
+			m.rebaseLoading = false
+			m.rebaseErr = msg.Err
+			m.rebaseSteps = msg.Steps
+			m.rebaseOriginal = slices.Clone(msg.Steps)
+			m.rebaseOnto = msg.Onto
+			m.rebaseInProgress = msg.InProgress
+			m.rebaseConflicts = msg.Conflicts
+		}
+		return m, nil
+
+	case RebaseResultMsg:
+		if msg.WorktreePath != m.rebasePath {
+			return m, nil
+		}
+		m.rebaseRunning = false
+		m.rebaseErr = msg.Err
+		m.rebaseConflicts = msg.Conflicts
+		if len(msg.Conflicts) > 0 {
+			m.rebaseInProgress = true
+			return m, nil
+		}
+		if msg.Err != nil {
+			return m, nil
+		}
+		// Finished or aborted: the branch changed, so refresh the sidebar stats.
+		m = m.closeRebase()
+		m.loading = true
+		return m, fetchGitDataCmd(m.config, m.runner, m.statCache)
+
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		if m.rebaseRewording {
+			return m.updateRebaseRewordMode(msg)
+		}
+		if m.rebaseRunning || m.rebaseLoading {
+			return m, nil
+		}
+		if m.rebaseInProgress {
+			return m.updateRebaseConflictKeys(msg)
+		}
+		return m.updateRebasePlanKeys(msg)
+	}
+	return m, nil
+}
+
+func (m Model) updateRebasePlanKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, rebaseKeys.Close):
+		return m.closeRebase(), nil
+	case key.Matches(msg, rebaseKeys.Down):
+		if m.rebaseCursor < len(m.rebaseSteps)-1 {
+			m.rebaseCursor++
+		}
+	case key.Matches(msg, rebaseKeys.Up):
+		if m.rebaseCursor > 0 {
+			m.rebaseCursor--
+		}
+	case key.Matches(msg, rebaseKeys.MoveDown):
+		m = m.moveRebaseStep(1)
+	case key.Matches(msg, rebaseKeys.MoveUp):
+		m = m.moveRebaseStep(-1)
+	case key.Matches(msg, rebaseKeys.Pick):
+		m = m.setRebaseAction(git.RebasePick)
+	case key.Matches(msg, rebaseKeys.Squash):
+		m = m.setRebaseAction(git.RebaseSquash)
+	case key.Matches(msg, rebaseKeys.Fixup):
+		m = m.setRebaseAction(git.RebaseFixup)
+	case key.Matches(msg, rebaseKeys.Drop):
+		m = m.setRebaseAction(git.RebaseDrop)
+	case key.Matches(msg, rebaseKeys.Reword):
+		if m.rebaseCursor < len(m.rebaseSteps) {
+			step := m.rebaseSteps[m.rebaseCursor]
+			m.rebaseRewording = true
+			m.textInput.Placeholder = "new commit subject"
+			m.textInput.SetValue(cmp.Or(step.Message, step.Subject))
+			m.textInput.CursorEnd()
+			return m, m.textInput.Focus()
+		}
+	case key.Matches(msg, rebaseKeys.Run):
+		if !m.rebasePlanChanged() {
+			return m.closeRebase(), nil
+		}
+		if _, err := git.RebaseTodo(m.rebaseSteps); err != nil {
+			m.rebaseErr = err
+			return m, nil
+		}
+		m.rebaseRunning = true
+		m.rebaseErr = nil
+		steps := slices.Clone(m.rebaseSteps)
+		return m.withProgress("Rebasing...", func(runner git.CommandRunner) tea.Cmd {
+			return runRebaseCmd(runner, m.rebasePath, m.rebaseOnto, steps)
+		})
+	}
+	return m, nil
+}
+
+func (m Model) updateRebaseConflictKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, rebaseConflictKeys.Close):
+		// The rebase stays in progress; reopening the planner resumes here.
+		return m.closeRebase(), nil
+	case key.Matches(msg, rebaseConflictKeys.Continue):
+		m.rebaseRunning = true
+		m.rebaseErr = nil
+		return m.withProgress("Continuing the rebase...", func(runner git.CommandRunner) tea.Cmd {
+			return continueRebaseCmd(runner, m.rebasePath)
+		})
+	case key.Matches(msg, rebaseConflictKeys.Abort):
+		m.rebaseRunning = true
+		m.rebaseErr = nil
+		return m.withProgress("Aborting the rebase...", func(runner git.CommandRunner) tea.Cmd {
+			return abortRebaseCmd(runner, m.rebasePath)
+		})
+	}
+	return m, nil
+}
+
+func (m Model) updateRebaseRewordMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		m.rebaseRewording = false
+		m.textInput.SetValue("")
+		return m, nil
+	case tea.KeyEnter:
+		message := strings.TrimSpace(m.textInput.Value())
+		m.rebaseRewording = false
+		m.textInput.SetValue("")
+		steps := slices.Clone(m.rebaseSteps)
+		step := &steps[m.rebaseCursor]
+		if message == "" || message == step.Subject {
+			step.Action, step.Message = git.RebasePick, ""
+		} else {
+			step.Action, step.Message = git.RebaseReword, message
+		}
+		m.rebaseSteps = steps
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// closeRebase hides the planner and forgets its plan.
+func (m Model) closeRebase() Model {
+	m.showingRebase = false
+	m.rebaseRunning = false
+	m.rebaseRewording = false
+	m.rebaseSteps = nil
+	m.rebaseOriginal = nil
+	m.rebaseConflicts = nil
+	m.rebaseErr = nil
+	m.rebaseInProgress = false
+	return m
+}
+
+// setRebaseAction sets the action of the commit under the cursor. Steps are
+// copied so models sharing the slice are not affected.
+func (m Model) setRebaseAction(action git.RebaseAction) Model {
+	if m.rebaseCursor >= len(m.rebaseSteps) {
+		return m
+	}
+	steps := slices.Clone(m.rebaseSteps)
+	steps[m.rebaseCursor].Action = action
+	steps[m.rebaseCursor].Message = ""
+	m.rebaseSteps = steps
+	return m
+}
+
+// moveRebaseStep swaps the commit under the cursor with its neighbour and
+// keeps the cursor on it.
+func (m Model) moveRebaseStep(delta int) Model {
+	to := m.rebaseCursor + delta
+	if to < 0 || to >= len(m.rebaseSteps) {
+		return m
+	}
+	steps := slices.Clone(m.rebaseSteps)
+	steps[m.rebaseCursor], steps[to] = steps[to], steps[m.rebaseCursor]
+	m.rebaseSteps = steps
+	m.rebaseCursor = to
+	return m
+}
+
+// rebasePlanChanged reports whether running the plan would rewrite anything.
+func (m Model) rebasePlanChanged() bool {
+	return !slices.Equal(m.rebaseSteps, m.rebaseOriginal)
+}
+
+func rebaseActionColor(action git.RebaseAction) lipgloss.Color {
+	switch action {
+	case git.RebaseReword:
+		return colorAccent
+	case git.RebaseSquash, git.RebaseFixup:
+		return colorYellow
+	case git.RebaseDrop:
+		return colorRed
+	}
+	return colorFg
+}
+
+// rebaseLines renders one line per planned commit, oldest first.
+func rebaseLines(m Model) []string {
+	lines := make([]string, 0, len(m.rebaseSteps))
+	for i, step := range m.rebaseSteps {
+		marker := "  "
+		if i == m.rebaseCursor {
+			marker = "> "
+		}
+		action := lipgloss.NewStyle().Foreground(rebaseActionColor(step.Action)).Render(fmt.Sprintf("%-6s", step.Action))
+		subject := step.Subject
+		switch step.Action {
+		case git.RebaseReword:
+			subject = step.Message
+		case git.RebaseDrop:
+			subject = sortLabelStyle.Strikethrough(true).Render(subject)
+		}
+		hash := sortLabelStyle.Render(fmt.Sprintf("%.7s", step.Hash))
+		lines = append(lines, marker+action+" "+hash+" "+subject)
+	}
+	return lines
+}
+
+func renderRebaseView(m Model) string {
+	if m.rebaseRewording {
+		step := m.rebaseSteps[m.rebaseCursor]
+		return modalLayout{
+			title:  "Reword Commit",
+			prompt: fmt.Sprintf("New subject for %.7s %s:", step.Hash, step.Subject),
+			input:  m.textInput.View(),
+			help:   "enter: set  esc: cancel",
+		}.render(m.width, m.height)
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Rebase: " + m.rebaseLabel))
+	b.WriteString("\n")
+
+	help := keyhelp.ShortHelp(keyhelp.Bindings(rebaseKeys)...)
+	switch {
+	case m.rebaseLoading:
+		b.WriteString("  Loading commits...\n")
+	case m.rebaseRunning:
+		b.WriteString("  " + m.progressLine("Rebasing...") + "\n")
+	case m.rebaseInProgress:
+		if len(m.rebaseConflicts) > 0 {
+			b.WriteString("  Rebase stopped on conflicts in:\n")
+			for _, f := range m.rebaseConflicts {
+				b.WriteString("    " + lipgloss.NewStyle().Foreground(colorRed).Render(f) + "\n")
+			}
+			b.WriteString(helpStyle.Render("  Resolve and stage them in the worktree, then continue.") + "\n")
+		} else {
+			b.WriteString("  A rebase is in progress in this worktree.\n")
+		}
+		help = keyhelp.ShortHelp(keyhelp.Bindings(rebaseConflictKeys)...)
+	case len(m.rebaseSteps) == 0 && m.rebaseErr == nil:
+		b.WriteString("  No commits to rebase\n")
+		help = keyhelp.ShortHelp(rebaseKeys.Close)
+	default:
+		lines := rebaseLines(m)
+		start := 0
+		if vp := viewportHeight(m.height); vp > 0 && len(lines) > vp {
+			start = min(max(m.rebaseCursor-vp+1, 0), len(lines)-vp)
+			lines = lines[start : start+vp]
+		}
+		clip := lipgloss.NewStyle().MaxWidth(max(m.sidebarWidth, 20))
+		for _, l := range lines {
+			b.WriteString(clip.Render(l))
+			b.WriteString("\n")
+		}
+	}
+
+	if m.rebaseErr != nil {
+		b.WriteString(renderErrorBlock(m.rebaseErr, m.width))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render(help))
+	return b.String()
+}
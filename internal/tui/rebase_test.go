@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+)
+
+func rebaseKey(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// plannerModel returns a model with the rebase planner open on three commits.
+func plannerModel() Model {
+	m := testModel()
+	m.showingRebase = true
+	m.rebasePath = "/code/repo1-feat"
+	m.rebaseLabel = "feature-x"
+	m.sidebarWidth = 60
+	steps := []git.RebaseStep{
+		{Action: git.RebasePick, Hash: "aaaaaaa1", Subject: "add a"},
+		{Action: git.RebasePick, Hash: "bbbbbbb2", Subject: "add b"},
+		{Action: git.RebasePick, Hash: "ccccccc3", Subject: "fix a"},
+	}
+	result, _ := m.Update(RebasePlanMsg{WorktreePath: m.rebasePath, Steps: steps, Onto: "base"})
+	return result.(Model)
+}
+
+func TestUpdate_ShiftR_OpensRebasePlanner(t *testing.T) {
+	m := testModel()
+	m.cursor = 2 // feature-x
+	m.runner = git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/repo1-feat:[rev-parse --git-path rebase-merge]":                         "/nonexistent/rebase-merge\n",
+			"/code/repo1-feat:[rev-parse --git-path rebase-apply]":                         "/nonexistent/rebase-apply\n",
+			"/code/repo1-feat:[merge-base origin/main HEAD]":                               "base123\n",
+			"/code/repo1-feat:[log --reverse --no-merges --format=%H%x09%s base123..HEAD]": "aaa\tadd a\nbbb\tadd b\n",
+		},
+	}
+
+	result, cmd := m.Update(rebaseKey("R"))
+	updated := result.(Model)
+	if !updated.showingRebase || !updated.rebaseLoading || cmd == nil {
+		t.Fatal("R should open the planner and load the commits")
+	}
+
+	result, _ = updated.Update(cmd())
+	updated = result.(Model)
+	if updated.rebaseLoading || len(updated.rebaseSteps) != 2 || updated.rebaseOnto != "base123" {
+		t.Errorf("plan = %+v onto %q", updated.rebaseSteps, updated.rebaseOnto)
+	}
+}
+
+func TestUpdate_RebasePlanner_EditPlan(t *testing.T) {
+	m := plannerModel()
+
+	// Move "fix a" up under "add a" and fold it in, then drop "add b".
+	for _, k := range []string{"j", "j", "K", "f", "j", "d"} {
+		result, _ := m.Update(rebaseKey(k))
+		m = result.(Model)
+	}
+
+	var got []string
+	for _, s := range m.rebaseSteps {
+		got = append(got, string(s.Action)+" "+s.Subject)
+	}
+	want := "pick add a,fixup fix a,drop add b"
+	if strings.Join(got, ",") != want {
+		t.Errorf("plan = %v, want %s", got, want)
+	}
+	if m.rebaseOriginal[1].Subject != "add b" {
+		t.Error("editing the plan must not change the original order")
+	}
+}
+
+func TestUpdate_RebasePlanner_Reword(t *testing.T) {
+	m := plannerModel()
+
+	result, _ := m.Update(rebaseKey("r"))
+	m = result.(Model)
+	if !m.rebaseRewording || m.textInput.Value() != "add a" {
+		t.Fatalf("r should prompt with the current subject, got %q", m.textInput.Value())
+	}
+	m.textInput.SetValue("Add a properly")
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+
+	if m.rebaseRewording {
+		t.Error("enter should close the reword prompt")
+	}
+	if step := m.rebaseSteps[0]; step.Action != git.RebaseReword || step.Message != "Add a properly" {
+		t.Errorf("step = %+v", step)
+	}
+	if !strings.Contains(renderRebaseView(m), "Add a properly") {
+		t.Error("the planner should show the new subject")
+	}
+}
+
+func TestUpdate_RebasePlanner_EnterUnchangedCloses(t *testing.T) {
+	m := plannerModel()
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if result.(Model).showingRebase || cmd != nil {
+		t.Error("an unchanged plan should just close the planner")
+	}
+}
+
+func TestUpdate_RebasePlanner_InvalidPlan(t *testing.T) {
+	m := plannerModel()
+
+	result, _ := m.Update(rebaseKey("s")) // squash the first commit
+	result, cmd := result.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updated := result.(Model)
+
+	if cmd != nil || updated.rebaseRunning || updated.rebaseErr == nil {
+		t.Error("squashing the first commit should be rejected before running git")
+	}
+}
+
+func TestUpdate_RebasePlanner_Run(t *testing.T) {
+	m := plannerModel()
+
+	result, _ := m.Update(rebaseKey("d"))
+	result, cmd := result.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updated := result.(Model)
+	if !updated.rebaseRunning || cmd == nil {
+		t.Fatal("enter should run the rebase")
+	}
+
+	result, _ = updated.Update(rebaseKey("q"))
+	if !result.(Model).showingRebase {
+		t.Error("the planner should stay open while the rebase runs")
+	}
+}
+
+func TestUpdate_RebaseResult_Conflicts(t *testing.T) {
+	m := plannerModel()
+	m.rebaseRunning = true
+
+	result, _ := m.Update(RebaseResultMsg{WorktreePath: m.rebasePath, Conflicts: []string{"a.txt"}})
+	updated := result.(Model)
+
+	if !updated.showingRebase || !updated.rebaseInProgress {
+		t.Fatal("a stop on conflicts should keep the planner open")
+	}
+	view := renderRebaseView(updated)
+	for _, want := range []string{"a.txt", "c: continue", "a: abort"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	result, cmd := updated.Update(rebaseKey("a"))
+	if !result.(Model).rebaseRunning || cmd == nil {
+		t.Error("a should abort the rebase")
+	}
+}
+
+func TestUpdate_RebaseResult_DoneReloads(t *testing.T) {
+	m := plannerModel()
+	m.rebaseRunning = true
+
+	result, cmd := m.Update(RebaseResultMsg{WorktreePath: m.rebasePath})
+	updated := result.(Model)
+
+	if updated.showingRebase || !updated.loading || cmd == nil {
+		t.Error("a finished rebase should close the planner and reload the sidebar")
+	}
+}
+
+func TestUpdate_RebaseResult_Error(t *testing.T) {
+	m := plannerModel()
+	m.rebaseRunning = true
+
+	result, _ := m.Update(RebaseResultMsg{WorktreePath: m.rebasePath, Err: errors.New("boom")})
+	updated := result.(Model)
+
+	if !updated.showingRebase || updated.rebaseErr == nil {
+		t.Error("a failed rebase should stay open with the error")
+	}
+}
+
+func TestRebaseResult_ClassifiesConflicts(t *testing.T) {
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{"/wt:[diff --name-only --diff-filter=U]": "a.txt\nb.txt\n"},
+	}
+
+	msg := rebaseResult(runner, "/wt", git.ErrRebaseConflict)
+	if len(msg.Conflicts) != 2 || msg.Err != nil {
+		t.Errorf("got %+v, want two conflicted files", msg)
+	}
+}
@@ -0,0 +1,11 @@
+package tui
+
+// recentLimit is how many worktrees the Recent section lists.
+const recentLimit = 5
+
+// WithRecent returns a copy of the model that lists the worktrees at paths,
+// newest first, in a Recent section above the repository groups.
+func (m Model) WithRecent(paths []string) Model {
+	m.recent = paths
+	return m
+}
@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// RepoRefreshedMsg carries the worktrees of one repository, listed again
+// after an add or archive that only touched it.
+type RepoRefreshedMsg struct {
+	RepoPath string
+	Group    model.RepoGroup
+	Err      error
+}
+
+// refreshRepoCmd lists the worktrees of the repository at repoPath with
+// their diff stats, leaving the other repositories alone.
+func refreshRepoCmd(repoDef model.RepositoryDef, baseRef string, runner git.CommandRunner, cache *git.StatCache) tea.Cmd {
+	return func() tea.Msg {
+		group, err := listRepoGroup(runner, repoDef)
+		if err != nil {
+			return RepoRefreshedMsg{RepoPath: repoDef.Path, Err: err}
+		}
+		errs := make([]error, len(group.Worktrees))
+		parallel(len(group.Worktrees), gitDataWorkers, func(i int) {
+			wt := group.Worktrees[i]
+			group.Worktrees[i].Status, errs[i] = cache.DiffStat(runner, wt.Path, wt.Head, baseRef)
+		})
+		for _, err := range errs {
+			if err != nil {
+				return RepoRefreshedMsg{RepoPath: repoDef.Path, Err: err}
+			}
+		}
+		return RepoRefreshedMsg{RepoPath: repoDef.Path, Group: group}
+	}
+}
+
+// refreshRepos re-fetches the repositories at repoPaths, or every repository
+// when one of them is not in the sidebar yet.
+func (m Model) refreshRepos(repoPaths ...string) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, path := range repoPaths {
+		i := slices.IndexFunc(m.config.Repositories, func(r model.RepositoryDef) bool { return r.Path == path })
+		shown := slices.ContainsFunc(m.groups, func(g model.RepoGroup) bool { return g.RootPath == path })
+		if i < 0 || !shown {
+			return fetchGitDataCmd(m.config, m.runner, m.statCache)
+		}
+		cmds = append(cmds, refreshRepoCmd(m.config.Repositories[i], config.BaseRef(m.config, path), m.runner, m.statCache))
+	}
+	if len(cmds) == 0 {
+		return fetchGitDataCmd(m.config, m.runner, m.statCache)
+	}
+	return tea.Batch(cmds...)
+}
+
+// applyRepoRefresh swaps in the refreshed group, keeping the cursor on its row.
+func (m Model) applyRepoRefresh(msg RepoRefreshedMsg) Model {
+	m.loading = false
+	if msg.Err != nil {
+		m.err = msg.Err
+		return m
+	}
+	i := slices.IndexFunc(m.groups, func(g model.RepoGroup) bool { return g.RootPath == msg.RepoPath })
+	if i < 0 {
+		return m
+	}
+	m.groups = slices.Clone(m.groups)
+	m.groups[i] = msg.Group
+	return rebuildItems(m)
+}
@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// refreshedModel is twoRepoConfig loaded into the sidebar without stats.
+func refreshedModel(t *testing.T) Model {
+	t.Helper()
+	m := Model{config: twoRepoConfig, runner: twoRepoRunner(), sidebarWidth: 30}
+	result, _ := m.Update(fetchGitDataCmd(twoRepoConfig, twoRepoRunner(), nil)())
+	return result.(Model)
+}
+
+func cursorTo(t *testing.T, m Model, path string) Model {
+	t.Helper()
+	for i, item := range m.items {
+		if item.Kind == model.ItemKindWorktree && item.WorktreePath == path {
+			m.cursor = i
+			return m
+		}
+	}
+	t.Fatalf("no row for %s", path)
+	return m
+}
+
+func TestRefreshRepos_OnlyListsTheAffectedRepository(t *testing.T) {
+	m := refreshedModel(t)
+	runner := twoRepoRunner()
+	delete(runner.Outputs, "/b:[worktree list --porcelain]")
+	m.runner = runner
+
+	msg, ok := m.refreshRepos("/a")().(RepoRefreshedMsg)
+	if !ok || msg.Err != nil {
+		t.Fatalf("got %+v, want a RepoRefreshedMsg for /a", msg)
+	}
+	if msg.RepoPath != "/a" || len(msg.Group.Worktrees) != 3 || msg.Group.Worktrees[2].Status.Insertions != 90 {
+		t.Errorf("group = %+v, want /a with its diff stats", msg.Group)
+	}
+}
+
+func TestRefreshRepos_UnknownRepositoryReloadsEverything(t *testing.T) {
+	m := refreshedModel(t)
+	for _, paths := range [][]string{{"/elsewhere"}, nil} {
+		msg := m.refreshRepos(paths...)()
+		if _, ok := msg.(GitDataMsg); !ok {
+			t.Errorf("refreshRepos(%v) = %T, want a full GitDataMsg", paths, msg)
+		}
+	}
+}
+
+func TestApplyRepoRefresh_KeepsCursorOnItsRow(t *testing.T) {
+	m := cursorTo(t, refreshedModel(t), "/b")
+
+	group := m.groups[0]
+	group.Worktrees = append(group.Worktrees, model.WorktreeInfo{Path: "/a-new", Branch: "new"})
+	result, _ := m.Update(RepoRefreshedMsg{RepoPath: "/a", Group: group})
+	m = result.(Model)
+
+	if len(m.groups[0].Worktrees) != 4 || len(m.groups[1].Worktrees) != 1 {
+		t.Fatalf("groups = %+v, want only /a changed", m.groups)
+	}
+	if m.items[m.cursor].WorktreePath != "/b" {
+		t.Errorf("cursor on %q, want it kept on /b", m.items[m.cursor].WorktreePath)
+	}
+}
+
+func TestApplyRepoRefresh_ArchivedRowMovesCursorToNeighbour(t *testing.T) {
+	m := cursorTo(t, refreshedModel(t), "/a-small")
+	prev := m.cursor
+
+	group := m.groups[0]
+	group.Worktrees = []model.WorktreeInfo{group.Worktrees[0], group.Worktrees[2]}
+	m.loading = true
+	result, _ := m.Update(RepoRefreshedMsg{RepoPath: "/a", Group: group})
+	m = result.(Model)
+
+	if m.loading {
+		t.Error("loading should be false once the repository is refreshed")
+	}
+	if m.cursor != prev || m.items[m.cursor].WorktreePath != "/a-big" {
+		t.Errorf("cursor = %d on %q, want %d on the next worktree", m.cursor, m.items[m.cursor].WorktreePath, prev)
+	}
+}
+
+func TestApplyRepoRefresh_Error(t *testing.T) {
+	m := refreshedModel(t)
+	m.loading = true
+	result, _ := m.Update(RepoRefreshedMsg{RepoPath: "/a", Err: errors.New("boom")})
+	m = result.(Model)
+	if m.err == nil || m.loading {
+		t.Errorf("err = %v, loading = %v, want the error shown", m.err, m.loading)
+	}
+	if len(m.groups[0].Worktrees) != 3 {
+		t.Error("a failed refresh should keep the rows shown")
+	}
+}
+
+func TestApplyDiffStats_KeepsRowsOfARefreshedRepository(t *testing.T) {
+	m := refreshedModel(t)
+	group := m.groups[0]
+	group.Worktrees = append(group.Worktrees, model.WorktreeInfo{Path: "/a-new", Branch: "new"})
+	result, _ := m.Update(RepoRefreshedMsg{RepoPath: "/a", Group: group})
+	m = result.(Model)
+
+	result, _ = m.Update(GitDataMsg{stream: m.gitData, update: true, stats: map[string]model.StatusInfo{"/a-small": {Insertions: 1}}})
+	m = result.(Model)
+	if len(m.groups[0].Worktrees) != 4 || m.groups[0].Worktrees[1].Status.Insertions != 1 {
+		t.Errorf("groups = %+v, want the refreshed rows with the streamed stat", m.groups[0].Worktrees)
+	}
+}
+
+func TestGitDataMsg_KeepsCursorAndScrollAcrossReloads(t *testing.T) {
+	var worktrees []model.WorktreeInfo
+	for i := range 30 {
+		worktrees = append(worktrees, model.WorktreeInfo{Path: fmt.Sprintf("/r/wt%02d", i), Branch: fmt.Sprintf("b%02d", i)})
+	}
+	groups := []model.RepoGroup{{Name: "r", RootPath: "/r", Worktrees: worktrees}}
+	m := Model{sidebarWidth: 30, height: 20}
+	result, _ := m.Update(GitDataMsg{Groups: groups})
+	m = cursorTo(t, result.(Model), "/r/wt25")
+	m = recomputeScroll(m)
+	scrollOff := m.scrollOff
+	if scrollOff == 0 {
+		t.Fatal("the list should be scrolled for this test")
+	}
+
+	result, _ = m.Update(GitDataMsg{Groups: cloneGroups(groups)})
+	m = result.(Model)
+	if m.items[m.cursor].WorktreePath != "/r/wt25" || m.scrollOff != scrollOff {
+		t.Errorf("cursor on %q, scrollOff %d, want /r/wt25 and %d after a reload", m.items[m.cursor].WorktreePath, m.scrollOff, scrollOff)
+	}
+
+	prev := m.cursor
+	reloaded := cloneGroups(groups)
+	reloaded[0].Worktrees = slices.Delete(reloaded[0].Worktrees, 25, 26)
+	result, _ = m.Update(GitDataMsg{Groups: reloaded})
+	m = result.(Model)
+	if m.cursor != prev || m.items[m.cursor].WorktreePath != "/r/wt26" {
+		t.Errorf("cursor = %d on %q, want it left at %d when its worktree is gone", m.cursor, m.items[m.cursor].WorktreePath, prev)
+	}
+}
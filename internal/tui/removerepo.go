@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/audit"
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+	"github.com/mikanfactory/yakumo/internal/trash"
+)
+
+// RepoRemovedMsg is sent when a repository has been removed from the
+// config, or Err why it was kept.
+type RepoRemovedMsg struct {
+	Path     string
+	Archived bool // its worktrees were archived, so it needs a refresh either way
+	Err      error
+}
+
+// startRemoveRepo asks whether to remove the repository whose header is
+// under the cursor.
+func (m Model) startRemoveRepo() Model {
+	if m.cursor >= len(m.items) {
+		return m
+	}
+	item := m.items[m.cursor]
+	if item.Kind != model.ItemKindGroupHeader || item.RepoRootPath == "" {
+		return m
+	}
+	m.removingRepo = true
+	m.removeRepoPath = item.RepoRootPath
+	m.removeRepoName = item.Label
+	m.removeRepoArchive = false
+	m.err = nil
+	return m
+}
+
+// removeRepoTargets returns the worktrees archived with the repository: all
+// but the repository's own checkout.
+func (m Model) removeRepoTargets() []CleanupCandidate {
+	var targets []CleanupCandidate
+	for _, g := range m.groups {
+		if g.RootPath != m.removeRepoPath {
+			continue
+		}
+		for _, wt := range g.Worktrees {
+			if !wt.IsBare && wt.Path != g.RootPath {
+				targets = append(targets, CleanupCandidate{RepoPath: g.RootPath, WorktreePath: wt.Path, Branch: wt.Branch})
+			}
+		}
+	}
+	return targets
+}
+
+// removeRepoCmd archives targets, then removes the repository at repoPath
+// from the config file. A worktree that could not be archived keeps the
+// repository in the config, so nothing is left unlisted.
+func removeRepoCmd(runner git.CommandRunner, tmuxRunner tmux.Runner, bin trash.Trash, auditLog audit.Log, cfg model.Config, configPath, repoPath string, targets []CleanupCandidate) tea.Cmd {
+	return func() tea.Msg {
+		var errs []error
+		for _, c := range targets {
+			if err := ArchiveWorktree(runner, tmuxRunner, bin, auditLog, cfg, c.RepoPath, c.WorktreePath); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", c.Branch, err))
+			}
+		}
+		archived := len(targets) > 0
+		if err := errors.Join(errs...); err != nil {
+			return RepoRemovedMsg{Path: repoPath, Archived: archived, Err: fmt.Errorf("repository kept: %w", err)}
+		}
+		return RepoRemovedMsg{Path: repoPath, Archived: archived, Err: config.RemoveRepository(configPath, repoPath)}
+	}
+}
+
+func (m Model) updateRemoveRepoMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.loading {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, removeRepoKeys.Cancel):
+			m.removingRepo = false
+			m.err = nil
+			return m, nil
+		case key.Matches(msg, globalKeys.ForceQuit):
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, removeRepoKeys.Archive):
+			if len(m.removeRepoTargets()) > 0 {
+				m.removeRepoArchive = !m.removeRepoArchive
+			}
+		case key.Matches(msg, removeRepoKeys.Confirm):
+			var targets []CleanupCandidate
+			if m.removeRepoArchive {
+				targets = m.removeRepoTargets()
+			}
+			m.loading = true
+			m.err = nil
+			return m.withProgress("Removing repository...", func(runner git.CommandRunner) tea.Cmd {
+				return removeRepoCmd(runner, m.tmuxRunner, m.trash, m.audit, m.config, m.configPath, m.removeRepoPath, targets)
+			})
+		}
+
+	case RepoRemovedMsg:
+		m.removingRepo = false
+		m.loading = false
+		if msg.Err != nil {
+			m, toast := m.notifyErr(msg.Err)
+			if msg.Archived {
+				return m, tea.Batch(toast, m.refreshRepos(msg.Path))
+			}
+			return m, toast
+		}
+		cfg, err := config.LoadFromFile(m.configPath)
+		if err != nil {
+			return m.notifyErr(err)
+		}
+		m.config = cfg
+		m.loading = true
+		m, toast := m.notify(SeverityInfo, fmt.Sprintf("Removed %s from the config", m.removeRepoName), "")
+		return m, tea.Batch(toast, fetchGitDataCmd(m.config, m.runner, m.statCache))
+	}
+	return m, nil
+}
+
+func renderRemoveRepoView(m Model) string {
+	if m.loading {
+		return titleStyle.Render("Remove Repository") + "\n\n  " + m.progressLine("Removing repository...")
+	}
+
+	n := len(m.removeRepoTargets())
+	noun := "worktrees"
+	if n == 1 {
+		noun = "worktree"
+	}
+	var notes []string
+	switch {
+	case n == 0:
+		notes = append(notes, "It has no worktrees to archive.")
+	case m.removeRepoArchive:
+		notes = append(notes, fmt.Sprintf("[x] Archive its %d %s too", n, noun))
+	default:
+		notes = append(notes, fmt.Sprintf("[ ] Archive its %d %s too", n, noun), "They stay on disk.")
+	}
+	notes = append(notes, "The repository itself is not touched.")
+	return modalLayout{
+		title: "Remove Repository",
+		input: fmt.Sprintf("Remove '%s' from the config?", m.removeRepoName),
+		notes: notes,
+		err:   m.err,
+		help:  keyhelp.ShortHelp(removeRepoKeys.Archive, removeRepoKeys.Confirm, removeRepoKeys.Cancel),
+	}.render(m.width, m.height)
+}
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestRemoveRepo_OnGroupHeader(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "repositories:\n  - name: repo1\n    path: /code/repo1\n  - name: repo2\n    path: /code/repo2\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := testModel()
+	m.configPath = cfgPath
+	m.cursor = 0 // the repo1 header
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	m = result.(Model)
+	if !m.removingRepo || m.removeRepoPath != "/code/repo1" {
+		t.Fatalf("D on a header should ask to remove its repository, removingRepo = %v", m.removingRepo)
+	}
+	if view := renderRemoveRepoView(m); !strings.Contains(view, "Remove 'repo1' from the config?") || !strings.Contains(view, "[ ] Archive its 1 worktree too") {
+		t.Errorf("view:\n%s", view)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = result.(Model)
+	if !m.removeRepoArchive {
+		t.Error("a should choose to archive the worktrees too")
+	}
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = result.(Model)
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	result, _ = m.Update(opMsg(t, cmd))
+	m = result.(Model)
+	if m.removingRepo {
+		t.Error("the confirmation should close once removed")
+	}
+	if len(m.config.Repositories) != 1 || m.config.Repositories[0].Name != "repo2" {
+		t.Errorf("Repositories = %+v, want only repo2", m.config.Repositories)
+	}
+	if m.toast.Text != "Removed repo1 from the config" {
+		t.Errorf("toast = %q", m.toast.Text)
+	}
+}
+
+func TestRemoveRepo_NotOnWorktree(t *testing.T) {
+	m := testModel()
+	m.cursor = 1 // the main worktree of repo1
+	if m.items[m.cursor].Kind != model.ItemKindWorktree {
+		t.Fatalf("item %d is not a worktree", m.cursor)
+	}
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	if result.(Model).removingRepo {
+		t.Error("D on a worktree should do nothing")
+	}
+}
+
+func TestRemoveRepo_Failed(t *testing.T) {
+	m := testModel()
+	m.removingRepo = true
+	m.removeRepoPath = "/code/repo1"
+	m.loading = true
+
+	result, _ := m.Update(RepoRemovedMsg{Path: "/code/repo1", Err: errors.New("no config file to remove the repository from")})
+	m = result.(Model)
+	if m.removingRepo || m.loading || m.toast.Severity != SeverityError {
+		t.Errorf("a failure should close the confirmation with an error toast, toast = %+v", m.toast)
+	}
+}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/audit"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/github"
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+// WorktreeRenamedMsg is sent when a worktree's branch, directory and tmux
+// session have been renamed.
+type WorktreeRenamedMsg struct {
+	OldPath   string
+	NewPath   string
+	NewBranch string
+}
+
+// WorktreeRenameErrMsg is sent when renaming a worktree fails.
+type WorktreeRenameErrMsg struct {
+	Err error
+}
+
+// renameWorktreeCmd renames the branch checked out in worktreePath, moves the
+// directory to match the new branch slug and renames its tmux session. The
+// main worktree keeps its directory. If the move fails the branch rename is
+// rolled back; a failed session rename is only logged. The outcome is recorded
+// in auditLog.
+func renameWorktreeCmd(runner git.CommandRunner, tmuxRunner tmux.Runner, auditLog audit.Log, repoPath, worktreePath, oldBranch, newBranch string) tea.Cmd {
+	return func() tea.Msg {
+		// Resolve the session before the rename changes the names it is matched by.
+		var oldSession string
+		if tmuxRunner != nil {
+			getBranch := func(dir string) (string, error) { return git.CurrentBranch(runner, dir) }
+			name := tmux.ResolveSessionName(tmuxRunner, worktreePath, getBranch)
+			if exists, _ := tmux.HasWorktree(tmuxRunner, name); exists {
+				oldSession = name
+			}
+		}
+
+		event := audit.Event{Op: audit.OpRename, Repo: repoPath, Path: worktreePath, Branch: oldBranch, Detail: newBranch}
+		if err := git.RenameBranch(runner, worktreePath, oldBranch, newBranch); err != nil {
+			err = fmt.Errorf("renaming branch: %w", err)
+			auditLog.Record(event, err)
+			return WorktreeRenameErrMsg{Err: err}
+		}
+
+		newPath := worktreePath
+		if slug := github.BranchSlug(newBranch); worktreePath != repoPath && slug != "" && slug != filepath.Base(worktreePath) {
+			newPath = uniqueWorktreePath(filepath.Dir(worktreePath), slug)
+			if err := git.MoveWorktree(runner, repoPath, worktreePath, newPath); err != nil {
+				if rbErr := git.RenameBranch(runner, worktreePath, newBranch, oldBranch); rbErr != nil {
+					logging.For("rename").Error("rolling back branch rename failed", "err", rbErr)
+				}
+				err = fmt.Errorf("moving worktree: %w", err)
+				auditLog.Record(event, err)
+				return WorktreeRenameErrMsg{Err: err}
+			}
+		}
+		if newPath != worktreePath {
+			event.Detail += " at " + newPath
+		}
+		auditLog.Record(event, nil)
+
+		if oldSession != "" {
+			newSession := tmux.WorktreeSessionName(newPath, filepath.Base(newPath))
+			if newSession != oldSession {
+				if err := tmux.RenameWorktree(tmuxRunner, oldSession, newSession); err != nil {
+					logging.For("rename").Warn("tmux rename-session failed (non-fatal)", "err", err)
+					newSession = oldSession
+				}
+			}
+			if newPath != worktreePath {
+				if err := tmux.SetWorktreePath(tmuxRunner, newSession, newPath); err != nil {
+					logging.For("rename").Warn("recording the moved worktree of window failed (non-fatal)", "window", newSession, "err", err)
+				}
+			}
+		}
+
+		return WorktreeRenamedMsg{OldPath: worktreePath, NewPath: newPath, NewBranch: newBranch}
+	}
+}
+
+func (m Model) updateRenameWorktreeMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEscape:
+			m.renamingWorktree = false
+			m.textInput.SetValue("")
+			return m, nil
+		case tea.KeyEnter:
+			newBranch := strings.TrimSpace(m.textInput.Value())
+			m.textInput.SetValue("")
+			m.renamingWorktree = false
+			if newBranch == "" || newBranch == m.renameBranch {
+				return m, nil
+			}
+			m.loading = true
+			m.err = nil
+			return m, renameWorktreeCmd(m.runner, m.tmuxRunner, m.audit, m.renameRepoPath, m.renamePath, m.renameBranch, newBranch)
+		case tea.KeyCtrlC:
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// skipPendingRename stops the LLM auto-rename of a worktree the user has
+// already renamed by hand.
+func (m Model) skipPendingRename(worktreePath string) {
+	if info, ok := m.branchRenames[worktreePath]; ok && info.Status == model.RenameStatusPending {
+		logging.For("branch-rename").Info("renamed manually, skipping auto-rename", "path", worktreePath)
+		info.Status = model.RenameStatusSkipped
+		m.branchRenames[worktreePath] = info
+	}
+}
+
+func renderRenameWorktreeView(m Model) string {
+	return modalLayout{
+		title:  "Rename Worktree",
+		prompt: "New branch name for " + m.renameBranch + " (the directory and tmux session follow):",
+		input:  m.textInput.View(),
+		help:   "enter: rename  esc: cancel",
+	}.render(m.width, m.height)
+}
@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/audit"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+func TestUpdate_R_OpensRenamePrefilled(t *testing.T) {
+	m := testModel()
+	m.cursor = 2 // feature-x
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	updated := result.(Model)
+
+	if !updated.renamingWorktree {
+		t.Fatal("r should open the rename prompt")
+	}
+	if updated.textInput.Value() != "feature-x" {
+		t.Errorf("input = %q, want the current branch", updated.textInput.Value())
+	}
+	if updated.renamePath != "/code/repo1-feat" || updated.renameRepoPath != "/code/repo1" {
+		t.Errorf("rename target = %q in %q", updated.renamePath, updated.renameRepoPath)
+	}
+}
+
+func TestUpdate_RenameMode_EnterUnchangedCloses(t *testing.T) {
+	m := testModel()
+	m.renamingWorktree = true
+	m.renameBranch = "feature-x"
+	m.textInput.SetValue("feature-x")
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updated := result.(Model)
+
+	if updated.renamingWorktree || updated.loading || cmd != nil {
+		t.Error("an unchanged name should just close the prompt")
+	}
+}
+
+func TestRenameWorktreeCmd(t *testing.T) {
+	parent := t.TempDir()
+	oldPath := filepath.Join(parent, "south-korea")
+	newPath := filepath.Join(parent, "fix-login")
+
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("%s:[branch -m alice/south-korea alice/fix-login]", oldPath): "",
+			fmt.Sprintf("/repo:[worktree move %s %s]", oldPath, newPath):             "",
+		},
+	}
+	tmuxRunner := &tmux.FakeRunner{
+		Outputs: map[string]string{
+			"[has-session -t =south-korea]":              "",
+			"[rename-session -t =south-korea fix-login]": "",
+		},
+	}
+
+	msg := renameWorktreeCmd(runner, tmuxRunner, audit.Log{}, "/repo", oldPath, "alice/south-korea", "alice/fix-login")()
+
+	renamed, ok := msg.(WorktreeRenamedMsg)
+	if !ok {
+		t.Fatalf("expected WorktreeRenamedMsg, got %#v", msg)
+	}
+	if renamed.NewPath != newPath || renamed.NewBranch != "alice/fix-login" {
+		t.Errorf("got %+v", renamed)
+	}
+	var sessionRenamed bool
+	for _, call := range tmuxRunner.Calls {
+		if len(call) > 0 && call[0] == "rename-session" {
+			sessionRenamed = true
+		}
+	}
+	if !sessionRenamed {
+		t.Errorf("tmux session should be renamed, calls: %v", tmuxRunner.Calls)
+	}
+}
+
+func TestRenameWorktreeCmd_MoveFailureRollsBack(t *testing.T) {
+	parent := t.TempDir()
+	oldPath := filepath.Join(parent, "south-korea")
+	newPath := filepath.Join(parent, "fix-login")
+
+	rollback := fmt.Sprintf("%s:[branch -m alice/fix-login alice/south-korea]", oldPath)
+	runner := &recordingRunner{FakeCommandRunner: git.FakeCommandRunner{
+		Outputs: map[string]string{
+			fmt.Sprintf("%s:[branch -m alice/south-korea alice/fix-login]", oldPath): "",
+			rollback: "",
+		},
+		Errors: map[string]error{
+			fmt.Sprintf("/repo:[worktree move %s %s]", oldPath, newPath): fmt.Errorf("worktree is locked"),
+		},
+	}}
+
+	msg := renameWorktreeCmd(runner, nil, audit.Log{}, "/repo", oldPath, "alice/south-korea", "alice/fix-login")()
+
+	if _, ok := msg.(WorktreeRenameErrMsg); !ok {
+		t.Fatalf("expected WorktreeRenameErrMsg, got %#v", msg)
+	}
+	if !runner.called(rollback) {
+		t.Error("branch rename should be rolled back when the move fails")
+	}
+}
+
+func TestRenameWorktreeCmd_MainWorktreeKeepsDirectory(t *testing.T) {
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[branch -m main trunk]": "",
+		},
+	}
+
+	msg := renameWorktreeCmd(runner, nil, audit.Log{}, "/repo", "/repo", "main", "trunk")()
+
+	renamed, ok := msg.(WorktreeRenamedMsg)
+	if !ok {
+		t.Fatalf("expected WorktreeRenamedMsg, got %#v", msg)
+	}
+	if renamed.NewPath != "/repo" {
+		t.Errorf("NewPath = %q, want the main worktree to stay in place", renamed.NewPath)
+	}
+}
+
+func TestUpdate_WorktreeRenamedMsg_SkipsPendingAutoRename(t *testing.T) {
+	m := testModel()
+	m.branchRenames = map[string]model.BranchRenameInfo{
+		"/code/repo1-feat": {Status: model.RenameStatusPending},
+	}
+
+	result, cmd := m.Update(WorktreeRenamedMsg{OldPath: "/code/repo1-feat", NewPath: "/code/fix", NewBranch: "fix"})
+	updated := result.(Model)
+
+	if got := updated.branchRenames["/code/repo1-feat"].Status; got != model.RenameStatusSkipped {
+		t.Errorf("auto-rename status = %v, want skipped", got)
+	}
+	if !updated.loading || cmd == nil {
+		t.Error("a rename should reload git data")
+	}
+}
+
+// recordingRunner wraps git.FakeCommandRunner and remembers the keys it ran.
+type recordingRunner struct {
+	git.FakeCommandRunner
+	keys []string
+}
+
+func (r *recordingRunner) Run(dir string, args ...string) (string, error) {
+	r.keys = append(r.keys, fmt.Sprintf("%s:%v", dir, args))
+	return r.FakeCommandRunner.Run(dir, args...)
+}
+
+func (r *recordingRunner) called(key string) bool {
+	for _, k := range r.keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/restore"
+)
+
+// SessionRestorer recreates the saved worktree sessions that are not running.
+type SessionRestorer func() (restore.Report, error)
+
+// RestoreDoneMsg is sent when the saved worktree sessions have been recreated.
+type RestoreDoneMsg struct {
+	Report restore.Report
+	Err    error
+}
+
+// WithSessionRestore returns a copy of the model that recreates the saved
+// worktree sessions with run, as after a reboot or tmux kill-server.
+func (m Model) WithSessionRestore(run SessionRestorer) Model {
+	m.restoreSessions = run
+	return m
+}
+
+func restoreCmd(run SessionRestorer) tea.Cmd {
+	return func() tea.Msg {
+		report, err := run()
+		return RestoreDoneMsg{Report: report, Err: err}
+	}
+}
+
+// startRestore opens the restore view and recreates the saved sessions.
+func (m Model) startRestore() (Model, tea.Cmd) {
+	m.showingRestore = true
+	m.restoreRunning = true
+	m.restoreReport = restore.Report{}
+	m.restoreErr = nil
+	return m, restoreCmd(m.restoreSessions)
+}
+
+func (m Model) updateRestoreMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case RestoreDoneMsg:
+		m.restoreRunning = false
+		m.restoreReport = msg.Report
+		m.restoreErr = msg.Err
+		if len(msg.Report.Sessions) == 0 {
+			return m, nil
+		}
+		m.loading = true
+		return m, fetchGitDataCmd(m.config, m.runner, m.statCache)
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, globalKeys.ForceQuit):
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, restoreKeys.Close):
+			if !m.restoreRunning {
+				m.showingRestore = false
+				m.restoreReport = restore.Report{}
+				m.restoreErr = nil
+			}
+		}
+	}
+	return m, nil
+}
+
+func renderRestoreView(m Model) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Restore Sessions"))
+	b.WriteString("\n")
+
+	if m.restoreRunning {
+		b.WriteString("  Recreating saved sessions...\n")
+	} else {
+		for _, line := range strings.Split(m.restoreReport.String(), "\n") {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	if m.restoreErr != nil {
+		b.WriteString(renderErrorBlock(m.restoreErr, m.width))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render(keyhelp.ShortHelp(restoreKeys.Close)))
+	return b.String()
+}
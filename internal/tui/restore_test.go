@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/restore"
+)
+
+func TestUpdate_O_StartsRestore(t *testing.T) {
+	result, cmd := testModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("O")})
+	if result.(Model).showingRestore || cmd != nil {
+		t.Fatal("O should do nothing without a session restorer")
+	}
+
+	ran := false
+	m := testModel().WithSessionRestore(func() (restore.Report, error) {
+		ran = true
+		return restore.Report{Sessions: []string{"feat"}}, nil
+	})
+	result, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("O")})
+	m = result.(Model)
+	if !m.showingRestore || !m.restoreRunning || cmd == nil {
+		t.Fatal("O should open the restore view and start restoring")
+	}
+	if !strings.Contains(renderRestoreView(m), "Recreating") {
+		t.Error("the view should show progress")
+	}
+	if msg := cmd().(RestoreDoneMsg); !ran || len(msg.Report.Sessions) != 1 {
+		t.Errorf("restore cmd = %+v, want the restorer's report", msg)
+	}
+
+	// Running, esc is ignored so the report is not lost.
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if !result.(Model).showingRestore {
+		t.Error("esc should not close the view while restoring")
+	}
+}
+
+func TestUpdate_RestoreDoneMsg(t *testing.T) {
+	m := testModel()
+	m.showingRestore, m.restoreRunning = true, true
+
+	result, cmd := m.Update(RestoreDoneMsg{})
+	updated := result.(Model)
+	if cmd != nil || !strings.Contains(renderRestoreView(updated), "Nothing to restore.") {
+		t.Error("an empty report should not reload the sidebar")
+	}
+
+	result, cmd = m.Update(RestoreDoneMsg{Report: restore.Report{Sessions: []string{"feat"}}, Err: errors.New("/wt/x: creating session layout: no server")})
+	updated = result.(Model)
+	view := renderRestoreView(updated)
+	if cmd == nil || !strings.Contains(view, "restored session feat") || !strings.Contains(view, "no server") {
+		t.Errorf("restored sessions should reload the sidebar and show with the error, got:\n%s", view)
+	}
+
+	result, _ = updated.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if result.(Model).showingRestore {
+		t.Error("esc should close the report")
+	}
+}
@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"slices"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/sidebar"
+)
+
+// sortModeFor returns the worktree order of the repository at repoPath: the
+// mode picked with "s" this session, else the repository's `sort` setting.
+func (m Model) sortModeFor(repoPath string) sidebar.SortMode {
+	if mode, ok := m.sortModes[repoPath]; ok {
+		return mode
+	}
+	for _, repo := range m.config.Repositories {
+		if repo.Path == repoPath {
+			mode, _ := sidebar.ParseSortMode(repo.Sort)
+			return mode
+		}
+	}
+	return sidebar.SortCreated
+}
+
+// usesActivitySort reports whether any repository is ordered by tmux
+// activity, so agent polls need to re-sort the sidebar.
+func (m Model) usesActivitySort() bool {
+	for _, group := range m.groups {
+		if m.sortModeFor(group.RootPath) == sidebar.SortActivity {
+			return true
+		}
+	}
+	return false
+}
+
+// buildItems turns m.groups into sidebar items: sorted per repository with
+// pinned worktrees first, grouped by repository or by branch prefix, narrowed
+// by the filter (or else, grouped by repository, folded per collapsed group)
+// below the Recent section, and annotated with agent and PR status.
+func buildItems(m Model) []model.NavigableItem {
+	groups := sidebar.Sort(m.groups, m.sortModeFor, m.activity, m.pinned)
+	items := sidebar.BuildItems(groups)
+	if m.groupByPrefix {
+		items = sidebar.ByBranchPrefix(groups)
+	}
+	if m.filterQuery != "" {
+		items = sidebar.Filter(items, m.filterQuery)
+	} else {
+		if !m.groupByPrefix {
+			items = sidebar.Collapse(items, m.collapsed)
+		}
+		items = append(sidebar.Recent(m.groups, m.recent, recentLimit), items...)
+	}
+	for i := range items {
+		switch items[i].Kind {
+		case model.ItemKindWorktree:
+			items[i].AgentStatus = m.agentStatus[items[i].WorktreePath]
+			items[i].PR = m.prStatusFor(items[i])
+			items[i].Marked = m.marked[items[i].WorktreePath]
+			items[i].Pinned = m.pinned[items[i].WorktreePath]
+			if block, ok := m.ports[items[i].WorktreePath]; ok {
+				items[i].Ports = block.String()
+			}
+		case model.ItemKindGroupHeader:
+			if items[i].RepoRootPath == "" {
+				continue // the Recent section
+			}
+			if mode := m.sortModeFor(items[i].RepoRootPath); mode != sidebar.SortCreated {
+				items[i].SortLabel = string(mode)
+			}
+			items[i].BaseRed = m.baseRedFor(items[i].RepoRootPath)
+		}
+	}
+	return items
+}
+
+// rebuildItems re-sorts the sidebar in place, keeping the cursor on the
+// worktree or group header it was on. When that row is gone, say the worktree
+// was archived, the cursor stays at the same place in the list.
+func rebuildItems(m Model) Model {
+	if len(m.items) == 0 {
+		m.items = buildItems(m)
+		m.cursor = FirstSelectable(m.items)
+		return recomputeScroll(m)
+	}
+	prev := min(m.cursor, len(m.items)-1)
+	current := m.items[prev]
+	m.items = buildItems(m)
+	m.cursor = nearestSelectable(m.items, prev)
+	if i := slices.IndexFunc(m.items, func(item model.NavigableItem) bool { return sameRow(item, current) }); i >= 0 {
+		m.cursor = i
+	} else if i := slices.IndexFunc(m.items, func(item model.NavigableItem) bool {
+		// The worktree dropped out of the Recent section, or into it.
+		return item.Kind == model.ItemKindWorktree && current.Kind == model.ItemKindWorktree && item.WorktreePath == current.WorktreePath
+	}); i >= 0 {
+		m.cursor = i
+	}
+	return recomputeScroll(m)
+}
+
+// toggleGroupByPrefix switches the sidebar between grouping worktrees by
+// repository and by branch prefix, keeping the cursor on the same worktree.
+func (m Model) toggleGroupByPrefix() Model {
+	var path string
+	if m.cursor < len(m.items) {
+		path = m.items[m.cursor].WorktreePath
+	}
+	m.groupByPrefix = !m.groupByPrefix
+	m.items = buildItems(m)
+	m.cursor = FirstSelectable(m.items)
+	// The last row of the worktree is the one in its group rather than in
+	// the Recent section above.
+	for i, item := range m.items {
+		if path != "" && item.WorktreePath == path {
+			m.cursor = i
+		}
+	}
+	return recomputeScroll(m)
+}
+
+// sameRow reports whether a and b are the same worktree or group header.
+func sameRow(a, b model.NavigableItem) bool {
+	switch {
+	case a.Kind != b.Kind:
+		return false
+	case a.Kind == model.ItemKindWorktree:
+		return a.WorktreePath == b.WorktreePath && a.RecentRepo == b.RecentRepo
+	case a.Kind == model.ItemKindGroupHeader:
+		return a.RepoRootPath == b.RepoRootPath && a.Label == b.Label
+	}
+	return false
+}
@@ -7,74 +7,145 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/theme"
 )
 
 // Agent status icon (U+25CF Black Circle, colored per state)
 const iconAgent = "●"
 
+// Agent state text icons (U+2733 Eight Spoked Asterisk, U+26A0 Warning Sign)
+const (
+	iconAgentRunning = "✳"
+	iconAgentWaiting = "⚠"
+)
+
+// Pinned worktree icon (U+2691 Black Flag)
+const iconPin = "⚑"
+
 var (
-	colorFg         = lipgloss.Color("#cdd6f4")
-	colorFgDim      = lipgloss.Color("#6c7086")
-	colorAccent     = lipgloss.Color("#89b4fa")
-	colorGreen      = lipgloss.Color("#a6e3a1")
-	colorRed        = lipgloss.Color("#f38ba8")
-	colorYellow     = lipgloss.Color("#f9e2af")
-	colorActionItem = lipgloss.Color("#89dceb")
+	colorFg         lipgloss.Color
+	colorFgDim      lipgloss.Color
+	colorAccent     lipgloss.Color
+	colorGreen      lipgloss.Color
+	colorRed        lipgloss.Color
+	colorYellow     lipgloss.Color
+	colorActionItem lipgloss.Color
+
+	// Agent status colors
+	colorAgentIdle    lipgloss.Color
+	colorAgentRunning lipgloss.Color
+	colorAgentWaiting lipgloss.Color
+
+	titleStyle               lipgloss.Style
+	groupHeaderStyle         lipgloss.Style
+	groupHeaderSelectedStyle lipgloss.Style
+	worktreeStyle            lipgloss.Style
+	worktreeSelectedStyle    lipgloss.Style
+	actionStyle              lipgloss.Style
+	actionSelectedStyle      lipgloss.Style
+	helpStyle                lipgloss.Style
+	descriptionStyle         lipgloss.Style
+	sortLabelStyle           lipgloss.Style
+	markStyle                lipgloss.Style
+	errorStyle               lipgloss.Style
+	hintStyle                lipgloss.Style
+	scrollIndicatorStyle     lipgloss.Style
+)
+
+func init() {
+	ApplyTheme(theme.Default())
+}
+
+// ApplyTheme sets the colors of the sidebar and rebuilds its styles from
+// them. It is called before the program starts, with the `theme` config.
+func ApplyTheme(t theme.Theme) {
+	colorFg = t.Fg
+	colorFgDim = t.FgDim
+	colorAccent = t.Accent
+	colorGreen = t.Green
+	colorRed = t.Red
+	colorYellow = t.Yellow
+	colorActionItem = t.Cyan
+
+	colorAgentIdle = colorGreen
+	colorAgentRunning = colorYellow
+	colorAgentWaiting = colorActionItem
 
 	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorFg).
-			PaddingLeft(1).
-			PaddingBottom(1)
+		Bold(true).
+		Foreground(colorFg).
+		PaddingLeft(1).
+		PaddingBottom(1)
 
 	groupHeaderStyle = lipgloss.NewStyle().
-				Foreground(colorFgDim).
-				Bold(true).
-				PaddingLeft(1)
+		Foreground(colorFgDim).
+		Bold(true).
+		PaddingLeft(1)
+
+	groupHeaderSelectedStyle = lipgloss.NewStyle().
+		Foreground(colorAccent).
+		Bold(true).
+		PaddingLeft(1)
 
 	worktreeStyle = lipgloss.NewStyle().
-			Foreground(colorFg).
-			PaddingLeft(3)
+		Foreground(colorFg).
+		PaddingLeft(3)
 
 	worktreeSelectedStyle = lipgloss.NewStyle().
-				Foreground(colorAccent).
-				Bold(true).
-				PaddingLeft(1)
+		Foreground(colorAccent).
+		Bold(true).
+		PaddingLeft(1)
 
 	actionStyle = lipgloss.NewStyle().
-			Foreground(colorActionItem).
-			PaddingLeft(1).
-			PaddingTop(1)
+		Foreground(colorActionItem).
+		PaddingLeft(1).
+		PaddingTop(1)
 
 	actionSelectedStyle = lipgloss.NewStyle().
-				Foreground(colorAccent).
-				Bold(true).
-				PaddingLeft(1).
-				PaddingTop(1)
+		Foreground(colorAccent).
+		Bold(true).
+		PaddingLeft(1).
+		PaddingTop(1)
 
 	helpStyle = lipgloss.NewStyle().
-			Foreground(colorFgDim).
-			PaddingLeft(1).
-			PaddingTop(1)
+		Foreground(colorFgDim).
+		PaddingLeft(1).
+		PaddingTop(1)
+
+	descriptionStyle = lipgloss.NewStyle().
+		Foreground(colorFgDim).
+		Italic(true).
+		PaddingLeft(5)
+
+	sortLabelStyle = lipgloss.NewStyle().
+		Foreground(colorFgDim)
+
+	markStyle = lipgloss.NewStyle().
+		Foreground(colorGreen).
+		Bold(true)
 
 	errorStyle = lipgloss.NewStyle().
-			Foreground(colorRed).
-			PaddingLeft(1)
+		Foreground(colorRed).
+		PaddingLeft(1)
 
-	// Agent status colors (Catppuccin-compatible)
-	colorAgentIdle    = colorGreen      // #a6e3a1
-	colorAgentRunning = colorYellow     // #f9e2af
-	colorAgentWaiting = colorActionItem // #89dceb (cyan)
-)
+	hintStyle = lipgloss.NewStyle().
+		Foreground(colorYellow).
+		PaddingLeft(1)
 
-// FormatStatus formats a StatusInfo as colored line change counts (e.g. "+888 -89").
-func FormatStatus(s model.StatusInfo) string {
-	if s.Insertions == 0 && s.Deletions == 0 {
-		return ""
-	}
+	scrollIndicatorStyle = lipgloss.NewStyle().
+		Foreground(colorFgDim).
+		PaddingLeft(1)
+
+	reservedRows = lipgloss.Height(titleStyle.Render(workspacesTitle)) + 1 + lipgloss.Height(helpStyle.Render(workspacesHelp()))
+}
 
+// FormatStatus formats a StatusInfo as colored line change counts followed by
+// commits ahead/behind the base ref (e.g. "+888 -89 ↑3 ↓1").
+func FormatStatus(s model.StatusInfo) string {
 	addStyle := lipgloss.NewStyle().Foreground(colorGreen)
 	delStyle := lipgloss.NewStyle().Foreground(colorRed)
+	aheadStyle := lipgloss.NewStyle().Foreground(colorAccent)
+	behindStyle := lipgloss.NewStyle().Foreground(colorYellow)
 
 	var parts []string
 	if s.Insertions > 0 {
@@ -83,6 +154,12 @@ func FormatStatus(s model.StatusInfo) string {
 	if s.Deletions > 0 {
 		parts = append(parts, delStyle.Render(fmt.Sprintf("-%d", s.Deletions)))
 	}
+	if s.Ahead > 0 {
+		parts = append(parts, aheadStyle.Render(fmt.Sprintf("↑%d", s.Ahead)))
+	}
+	if s.Behind > 0 {
+		parts = append(parts, behindStyle.Render(fmt.Sprintf("↓%d", s.Behind)))
+	}
 	return strings.Join(parts, " ")
 }
 
@@ -116,3 +193,36 @@ func AgentIcon(agents []model.AgentInfo) string {
 
 	return lipgloss.NewStyle().Foreground(color).Render(icon) + " "
 }
+
+// AgentStateText describes the agent that needs attention most, e.g.
+// "✳ running 12m" or "⚠ waiting". Returns empty string when no agent is
+// running or waiting.
+func AgentStateText(agents []model.AgentInfo) string {
+	var running *model.AgentInfo
+	for i, a := range agents {
+		switch {
+		case a.State == model.AgentStateWaiting:
+			return iconAgentWaiting + " waiting"
+		case a.State == model.AgentStateRunning && running == nil:
+			running = &agents[i]
+		}
+	}
+	if running == nil {
+		return ""
+	}
+	// Elapsed reads like "12m 30s"; its largest unit is enough here.
+	if elapsed, _, _ := strings.Cut(running.Elapsed, " "); elapsed != "" {
+		return iconAgentRunning + " running " + elapsed
+	}
+	return iconAgentRunning + " running"
+}
+
+// agentStateStyle colors AgentStateText like the icon of the same state.
+func agentStateStyle(agents []model.AgentInfo) lipgloss.Style {
+	for _, a := range agents {
+		if a.State == model.AgentStateWaiting {
+			return lipgloss.NewStyle().Foreground(colorAgentWaiting)
+		}
+	}
+	return lipgloss.NewStyle().Foreground(colorAgentRunning)
+}
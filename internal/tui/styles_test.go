@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/theme"
+)
+
+func TestApplyTheme(t *testing.T) {
+	t.Cleanup(func() { ApplyTheme(theme.Default()) })
+
+	light := theme.Presets["light"]
+	ApplyTheme(light)
+
+	if titleStyle.GetForeground() != light.Fg {
+		t.Errorf("title foreground = %v, want %v", titleStyle.GetForeground(), light.Fg)
+	}
+	if worktreeSelectedStyle.GetForeground() != light.Accent {
+		t.Errorf("selected worktree foreground = %v, want %v", worktreeSelectedStyle.GetForeground(), light.Accent)
+	}
+	if rebaseActionColor(git.RebaseDrop) != light.Red {
+		t.Errorf("drop color = %v, want %v", rebaseActionColor(git.RebaseDrop), light.Red)
+	}
+	if reservedRows != 5 {
+		t.Errorf("reservedRows = %d, want 5", reservedRows)
+	}
+}
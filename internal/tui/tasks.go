@@ -0,0 +1,389 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tasks"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+// taskPollInterval is how often the picker re-reads the status of the runs
+// that have not finished.
+const taskPollInterval = 500 * time.Millisecond
+
+// taskRow is a task of the picker: a named command, or the rb_commands
+// sequence of the repository.
+type taskRow struct {
+	Name     string
+	Commands []string
+}
+
+// TasksStartedMsg is sent once tasks of a worktree have been typed into idle
+// panes of its session, each into its own pane.
+type TasksStartedMsg struct {
+	WorktreePath string
+	Panes        map[string]string // task name -> pane it runs in
+	Failed       []string          // tasks that could not be started
+	Err          error
+}
+
+// TasksStatusMsg carries the status files of the tasks of a worktree.
+type TasksStatusMsg struct {
+	WorktreePath string
+	Gen          int // see TasksTickMsg
+	Checks       map[string][]tasks.Check
+	UpdatedAt    map[string]time.Time
+	Err          error
+}
+
+// TasksTickMsg re-reads the status of runs that have not finished. Gen is
+// the chain of reads it belongs to; each start of tasks begins a new one,
+// and reads of an earlier chain are dropped so that only one polls.
+type TasksTickMsg struct {
+	WorktreePath string
+	Gen          int
+}
+
+// repoTasks returns the task rows of the repository at repoPath: its
+// rb_commands first, when it has any, then its tasks by name.
+func repoTasks(cfg model.Config, repoPath string) []taskRow {
+	var rows []taskRow
+	for _, repo := range cfg.Repositories {
+		if repo.Path != repoPath {
+			continue
+		}
+		if len(repo.RbCommands) > 0 {
+			rows = append(rows, taskRow{Name: tasks.RBCommands, Commands: repo.RbCommands})
+		}
+		for _, name := range slices.Sorted(maps.Keys(repo.Tasks)) {
+			rows = append(rows, taskRow{Name: name, Commands: []string{repo.Tasks[name]}})
+		}
+	}
+	return rows
+}
+
+// startTasksCmd resolves the session of the worktree and types each of rows
+// into an idle pane of it, preferring the background window and leaving out
+// busy, the panes of runs still going. Tasks beyond the idle panes fail.
+func startTasksCmd(runner git.CommandRunner, tmuxRunner tmux.Runner, worktreePath string, rows []taskRow, busy []string) tea.Cmd {
+	return func() tea.Msg {
+		msg := TasksStartedMsg{WorktreePath: worktreePath, Panes: map[string]string{}}
+		for _, row := range rows {
+			msg.Failed = append(msg.Failed, row.Name)
+		}
+		getBranch := func(dir string) (string, error) { return git.CurrentBranch(runner, dir) }
+		name := tmux.ResolveSessionName(tmuxRunner, worktreePath, getBranch)
+		exists, err := tmux.HasWorktree(tmuxRunner, name)
+		if err == nil && !exists {
+			err = errNoSession
+		}
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		panes, err := tmux.ListPanes(tmuxRunner, name)
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		idle := slices.DeleteFunc(tmux.IdlePanes(panes), func(pane string) bool { return slices.Contains(busy, pane) })
+
+		msg.Failed = nil
+		var errs []error
+		for _, row := range rows {
+			if len(idle) == 0 {
+				msg.Failed = append(msg.Failed, row.Name)
+				errs = append(errs, fmt.Errorf("%s: no pane of %s is idle at a shell prompt", row.Name, name))
+				continue
+			}
+			statusPath, err := tasks.StatusPath(worktreePath, row.Name)
+			if err == nil {
+				err = tasks.Reset(statusPath)
+			}
+			if err != nil {
+				msg.Failed = append(msg.Failed, row.Name)
+				errs = append(errs, fmt.Errorf("%s: preparing the status file: %w", row.Name, err))
+				continue
+			}
+			if err := tmux.SendKeys(tmuxRunner, idle[0], tasks.Script(row.Commands, statusPath)); err != nil {
+				msg.Failed = append(msg.Failed, row.Name)
+				errs = append(errs, fmt.Errorf("%s: %w", row.Name, err))
+				continue
+			}
+			msg.Panes[row.Name] = idle[0]
+			idle = idle[1:]
+		}
+		msg.Err = errors.Join(errs...)
+		return msg
+	}
+}
+
+// readTasksCmd reads the status file of every row, so the picker shows how
+// the last run of each task in the worktree went.
+func readTasksCmd(worktreePath string, rows []taskRow, gen int) tea.Cmd {
+	return func() tea.Msg {
+		msg := TasksStatusMsg{
+			WorktreePath: worktreePath,
+			Gen:          gen,
+			Checks:       map[string][]tasks.Check{},
+			UpdatedAt:    map[string]time.Time{},
+		}
+		var errs []error
+		for _, row := range rows {
+			statusPath, err := tasks.StatusPath(worktreePath, row.Name)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			checks, err := tasks.Read(row.Commands, statusPath)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", row.Name, err))
+				continue
+			}
+			msg.Checks[row.Name] = checks
+			msg.UpdatedAt[row.Name] = tasks.UpdatedAt(statusPath)
+		}
+		msg.Err = errors.Join(errs...)
+		return msg
+	}
+}
+
+func tasksTickCmd(worktreePath string, gen int) tea.Cmd {
+	return tea.Tick(taskPollInterval, func(time.Time) tea.Msg {
+		return TasksTickMsg{WorktreePath: worktreePath, Gen: gen}
+	})
+}
+
+// pollTasks starts a new chain of reads of the status files, which
+// supersedes any chain still going.
+func (m Model) pollTasks() (Model, tea.Cmd) {
+	m.taskGen++
+	return m, readTasksCmd(m.taskPath, m.taskRows, m.taskGen)
+}
+
+// openTasks shows the task picker of the worktree under the cursor with the
+// outcome of the last run of each task.
+func (m Model) openTasks() (Model, tea.Cmd) {
+	if m.tmuxRunner == nil || m.cursor >= len(m.items) || m.items[m.cursor].Kind != model.ItemKindWorktree {
+		return m, nil
+	}
+	item := m.items[m.cursor]
+	rows := repoTasks(m.config, item.RepoRootPath)
+	if len(rows) == 0 {
+		return m.notify(SeverityWarning, "No tasks configured for "+repoNameFromConfig(m.config, item.RepoRootPath), "")
+	}
+	m.showingTasks = true
+	m.taskPath = item.WorktreePath
+	m.taskLabel = item.Label
+	m.taskRows = rows
+	m.taskCursor = 0
+	m.taskChecks = nil
+	m.taskUpdatedAt = nil
+	m.taskPanes = map[string]string{}
+	m.taskStarting = map[string]bool{}
+	m.taskErr = nil
+	return m.pollTasks()
+}
+
+// taskRunning reports whether the task called name was started from the
+// picker and has not finished.
+func (m Model) taskRunning(name string) bool {
+	if m.taskStarting[name] {
+		return true
+	}
+	_, started := m.taskPanes[name]
+	return started && !tasks.Finished(m.taskChecks[name])
+}
+
+// anyTaskRunning reports whether a run is still going, including one
+// started before the picker was opened.
+func (m Model) anyTaskRunning() bool {
+	return slices.ContainsFunc(m.taskRows, func(row taskRow) bool {
+		return m.taskRunning(row.Name) || tasks.Summarize(m.taskChecks[row.Name]).State == tasks.Running
+	})
+}
+
+// startTasks runs those of rows that are not running already, side by side,
+// each in its own pane.
+func (m Model) startTasks(rows []taskRow) (Model, tea.Cmd) {
+	rows = slices.DeleteFunc(slices.Clone(rows), func(row taskRow) bool { return m.taskRunning(row.Name) })
+	if len(rows) == 0 {
+		return m, nil
+	}
+	var busy []string
+	for name, pane := range m.taskPanes {
+		if m.taskRunning(name) {
+			busy = append(busy, pane)
+		}
+	}
+	m.taskErr = nil
+	m.taskStarting = maps.Clone(m.taskStarting)
+	m.taskPanes = maps.Clone(m.taskPanes)
+	for _, row := range rows {
+		m.taskStarting[row.Name] = true
+		delete(m.taskPanes, row.Name)
+	}
+	return m, startTasksCmd(m.runner, m.tmuxRunner, m.taskPath, rows, busy)
+}
+
+func (m Model) updateTasksMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case TasksStartedMsg:
+		if msg.WorktreePath != m.taskPath {
+			return m, nil
+		}
+		m.taskStarting = maps.Clone(m.taskStarting)
+		m.taskPanes = maps.Clone(m.taskPanes)
+		for name, pane := range msg.Panes {
+			delete(m.taskStarting, name)
+			m.taskPanes[name] = pane
+		}
+		for _, name := range msg.Failed {
+			delete(m.taskStarting, name)
+		}
+		m.taskErr = msg.Err
+		if len(msg.Panes) == 0 {
+			return m, nil
+		}
+		return m.pollTasks()
+
+	case TasksStatusMsg:
+		if msg.WorktreePath != m.taskPath || msg.Gen != m.taskGen {
+			return m, nil
+		}
+		m.taskChecks = msg.Checks
+		m.taskUpdatedAt = msg.UpdatedAt
+		if msg.Err != nil {
+			m.taskErr = msg.Err
+			return m, nil
+		}
+		if !m.anyTaskRunning() {
+			return m, nil
+		}
+		return m, tasksTickCmd(m.taskPath, m.taskGen)
+
+	case TasksTickMsg:
+		if msg.WorktreePath != m.taskPath || msg.Gen != m.taskGen {
+			return m, nil
+		}
+		return m, readTasksCmd(m.taskPath, m.taskRows, m.taskGen)
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, globalKeys.ForceQuit):
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, taskKeys.Close):
+			// The tasks keep running in their panes.
+			m.showingTasks = false
+			m.taskPath = ""
+			m.taskRows = nil
+			m.taskChecks = nil
+			m.taskErr = nil
+		case key.Matches(msg, taskKeys.Up):
+			if m.taskCursor > 0 {
+				m.taskCursor--
+			}
+		case key.Matches(msg, taskKeys.Down):
+			if m.taskCursor < len(m.taskRows)-1 {
+				m.taskCursor++
+			}
+		case key.Matches(msg, taskKeys.Run):
+			if m.taskCursor < len(m.taskRows) {
+				return m.startTasks(m.taskRows[m.taskCursor : m.taskCursor+1])
+			}
+		case key.Matches(msg, taskKeys.RunAll):
+			return m.startTasks(m.taskRows)
+		}
+	}
+	return m, nil
+}
+
+func taskCheckLine(c tasks.Check) string {
+	switch c.State {
+	case tasks.Running:
+		return lipgloss.NewStyle().Foreground(colorYellow).Render("●") + " " + c.Command
+	case tasks.Passed:
+		return lipgloss.NewStyle().Foreground(colorGreen).Render("✓") + " " + c.Command
+	case tasks.Failed:
+		return lipgloss.NewStyle().Foreground(colorRed).Render("✗") + " " + c.Command + " " + sortLabelStyle.Render(fmt.Sprintf("exit %d", c.ExitCode))
+	case tasks.Skipped:
+		return sortLabelStyle.Render("- " + c.Command + " (skipped)")
+	default:
+		return sortLabelStyle.Render("·") + " " + c.Command
+	}
+}
+
+// taskLine shows a task as its name and the outcome of its last run: when it
+// finished and the pane it runs in while it runs.
+func (m Model) taskLine(row taskRow, now time.Time) string {
+	summary := tasks.Summarize(m.taskChecks[row.Name])
+	pane := m.taskPanes[row.Name]
+	switch {
+	case m.taskStarting[row.Name]:
+		return lipgloss.NewStyle().Foreground(colorYellow).Render("●") + " " + row.Name + " " + sortLabelStyle.Render("starting")
+	case summary.State == tasks.Running || m.taskRunning(row.Name):
+		line := lipgloss.NewStyle().Foreground(colorYellow).Render("●") + " " + row.Name
+		if pane != "" {
+			line += " " + sortLabelStyle.Render("in pane "+pane)
+		}
+		return line
+	}
+	var line string
+	switch summary.State {
+	case tasks.Passed:
+		line = lipgloss.NewStyle().Foreground(colorGreen).Render("✓") + " " + row.Name
+	case tasks.Failed:
+		line = lipgloss.NewStyle().Foreground(colorRed).Render("✗") + " " + row.Name + " " + sortLabelStyle.Render(fmt.Sprintf("exit %d", summary.ExitCode))
+	default:
+		return sortLabelStyle.Render("·") + " " + row.Name + " " + sortLabelStyle.Render("never run")
+	}
+	if at := m.taskUpdatedAt[row.Name]; !at.IsZero() {
+		line += " " + sortLabelStyle.Render(formatRunDuration(now.Sub(at))+" ago")
+	}
+	return line
+}
+
+func renderTasksView(m Model) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Tasks: " + m.taskLabel))
+	b.WriteString("\n")
+
+	clip := lipgloss.NewStyle().MaxWidth(max(m.sidebarWidth, 20))
+	selectedStyle := lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
+	now := time.Now()
+	for i, row := range m.taskRows {
+		line := "  " + m.taskLine(row, now)
+		if i == m.taskCursor {
+			line = selectedStyle.Render("> ") + m.taskLine(row, now)
+		}
+		b.WriteString(clip.Render(line))
+		b.WriteString("\n")
+		// A sequence lists how far each of its commands got.
+		if len(row.Commands) > 1 && tasks.Summarize(m.taskChecks[row.Name]).State != tasks.Pending {
+			for _, c := range m.taskChecks[row.Name] {
+				b.WriteString(clip.Render("    " + taskCheckLine(c)))
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	if m.taskErr != nil {
+		b.WriteString(renderErrorBlock(m.taskErr, m.width))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render(keyhelp.ShortHelp(keyhelp.Bindings(taskKeys)...)))
+	return b.String()
+}
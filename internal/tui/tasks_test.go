@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+	"github.com/mikanfactory/yakumo/internal/tasks"
+	"github.com/mikanfactory/yakumo/internal/tmux"
+)
+
+func tasksTestModel(t *testing.T) Model {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	m := testModel()
+	m.tmuxRunner = &tmux.FakeRunner{}
+	m.config = model.Config{Repositories: []model.RepositoryDef{{
+		Name:       "repo1",
+		Path:       "/code/repo1",
+		RbCommands: []string{"make test", "make lint", "make build"},
+		Tasks:      map[string]string{"test": "go test ./...", "lint": "golangci-lint run"},
+	}}}
+	return m
+}
+
+func writeTaskStatus(t *testing.T, worktreePath, name, status string) {
+	t.Helper()
+	path, err := tasks.StatusPath(worktreePath, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tasks.Reset(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(status), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTasks_LastRun(t *testing.T) {
+	m := tasksTestModel(t)
+	writeTaskStatus(t, "/code/repo1", tasks.RBCommands, "start 0\ndone 0 0\nstart 1\ndone 1 2\n")
+	writeTaskStatus(t, "/code/repo1", "test", "start 0\ndone 0 0\n")
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	m = result.(Model)
+	if !m.showingTasks || cmd == nil {
+		t.Fatal("t should open the task picker and read the last runs")
+	}
+	if got := []string{m.taskRows[0].Name, m.taskRows[1].Name, m.taskRows[2].Name}; strings.Join(got, " ") != "rb_commands lint test" {
+		t.Errorf("rows = %v, want rb_commands first, then tasks by name", got)
+	}
+	result, cmd = m.Update(cmd())
+	m = result.(Model)
+	if cmd != nil {
+		t.Error("finished runs should not be polled")
+	}
+
+	view := renderTasksView(m)
+	for _, want := range []string{"✗ rb_commands", "exit 2", "✓ make test", "make build (skipped)", "lint never run", "✓ test", "ago"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestTasks_RunConcurrently(t *testing.T) {
+	m := tasksTestModel(t)
+	m.showingTasks = true
+	m.taskPath = "/code/repo1-feat"
+	m.taskRows = repoTasks(m.config, "/code/repo1")
+	m.taskPanes = map[string]string{}
+	m.taskStarting = map[string]bool{}
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	m = result.(Model)
+	if cmd == nil || len(m.taskStarting) != 3 {
+		t.Fatalf("a should start every task, starting = %v", m.taskStarting)
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("enter should not start a task that is starting already")
+	}
+
+	result, cmd = m.Update(TasksStartedMsg{
+		WorktreePath: m.taskPath,
+		Panes:        map[string]string{tasks.RBCommands: "%4", "lint": "%5"},
+		Failed:       []string{"test"},
+	})
+	m = result.(Model)
+	if m.taskPanes["lint"] != "%5" || m.taskStarting["test"] || cmd == nil {
+		t.Errorf("panes = %v, starting = %v; want lint in %%5 and test given up", m.taskPanes, m.taskStarting)
+	}
+
+	writeTaskStatus(t, m.taskPath, "lint", "start 0\n")
+	result, cmd = m.Update(cmd())
+	m = result.(Model)
+	if cmd == nil {
+		t.Error("runs still going should be polled again")
+	}
+	if view := renderTasksView(m); !strings.Contains(view, "in pane %5") {
+		t.Errorf("view lacks the pane of lint:\n%s", view)
+	}
+
+	// Only test is free to start; the others are running.
+	m.taskCursor = 2
+	result, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if cmd == nil || !m.taskStarting["test"] || m.taskStarting["lint"] {
+		t.Errorf("enter should start test alone, starting = %v", m.taskStarting)
+	}
+}
+
+func TestTasks_StartingAgainDropsOldPolls(t *testing.T) {
+	m := tasksTestModel(t)
+	m.showingTasks = true
+	m.taskPath = "/code/repo1-feat"
+	m.taskRows = repoTasks(m.config, "/code/repo1")
+	m.taskPanes = map[string]string{}
+	m.taskStarting = map[string]bool{}
+	writeTaskStatus(t, m.taskPath, "lint", "start 0\n")
+
+	result, first := m.Update(TasksStartedMsg{WorktreePath: m.taskPath, Panes: map[string]string{"lint": "%5"}})
+	m = result.(Model)
+	result, second := m.Update(TasksStartedMsg{WorktreePath: m.taskPath, Panes: map[string]string{"test": "%6"}})
+	m = result.(Model)
+
+	if _, cmd := m.Update(first()); cmd != nil {
+		t.Error("a read of the earlier chain should be dropped")
+	}
+	if _, cmd := m.Update(TasksTickMsg{WorktreePath: m.taskPath, Gen: m.taskGen - 1}); cmd != nil {
+		t.Error("a tick of the earlier chain should be dropped")
+	}
+	if _, cmd := m.Update(second()); cmd == nil {
+		t.Error("the latest chain should keep polling the running lint")
+	}
+}
+
+func TestStartTasksCmd_NoSession(t *testing.T) {
+	msg := startTasksCmd(git.FakeCommandRunner{}, &tmux.FakeRunner{}, "/code/repo1-feat", []taskRow{{Name: "test", Commands: []string{"go test"}}}, nil)().(TasksStartedMsg)
+	if msg.Err == nil || len(msg.Failed) != 1 || len(msg.Panes) != 0 {
+		t.Errorf("msg = %+v, want test failed with an error", msg)
+	}
+}
+
+func TestTasks_NoTasks(t *testing.T) {
+	m := testModel()
+	m.tmuxRunner = &tmux.FakeRunner{}
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	if m := result.(Model); m.showingTasks || !strings.Contains(m.toast.Text, "No tasks") {
+		t.Errorf("showingTasks = %v, toast = %q; want a warning instead", m.showingTasks, m.toast.Text)
+	}
+}
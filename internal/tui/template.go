@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// TemplateStore remembers which template each worktree was created with.
+type TemplateStore interface {
+	Set(worktreePath, name string) error
+}
+
+// WithTemplateStore returns a copy of the model that records in store the
+// template each worktree is created with, for its session to be set up from.
+func (m Model) WithTemplateStore(store TemplateStore) Model {
+	m.templateStore = store
+	return m
+}
+
+// startAddWorktree asks for a template when the config has any, then opens
+// the add-worktree prompt for repoPath.
+func (m Model) startAddWorktree(repoPath string) (tea.Model, tea.Cmd) {
+	m.addTemplate = ""
+	if len(m.config.Templates) == 0 {
+		return m.openAddWorktree(repoPath)
+	}
+	m.pickingTemplate = true
+	m.addingWorktreeRepoPath = repoPath
+	m.templateCursor = 0
+	m.err = nil
+	return m, nil
+}
+
+// templateChoices lists the picker's rows: the repository's own settings,
+// then each template.
+func (m Model) templateChoices() []string {
+	choices := []string{"default"}
+	for _, tmpl := range m.config.Templates {
+		choices = append(choices, tmpl.Name)
+	}
+	return choices
+}
+
+func (m Model) updateTemplatePickerMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch {
+	case key.Matches(keyMsg, globalKeys.ForceQuit):
+		m.quitting = true
+		return m, tea.Quit
+	case key.Matches(keyMsg, templateKeys.Up):
+		m.templateCursor = max(m.templateCursor-1, 0)
+	case key.Matches(keyMsg, templateKeys.Down):
+		m.templateCursor = min(m.templateCursor+1, len(m.config.Templates))
+	case key.Matches(keyMsg, templateKeys.Select):
+		m.pickingTemplate = false
+		if m.templateCursor > 0 {
+			m.addTemplate = m.config.Templates[m.templateCursor-1].Name
+		}
+		return m.openAddWorktree(m.addingWorktreeRepoPath)
+	case key.Matches(keyMsg, templateKeys.Close):
+		m.pickingTemplate = false
+		m.addingWorktreeRepoPath = ""
+	}
+	return m, nil
+}
+
+// templateConfig returns the config to create a worktree of repoPath with
+// under the picked template, and the template's branch prefix.
+func (m Model) templateConfig(repoPath string) (model.Config, string) {
+	cfg := m.config
+	cfg.DefaultBaseRef = config.BaseRef(cfg, repoPath)
+	tmpl, ok := config.FindTemplate(m.config, m.addTemplate)
+	if !ok {
+		return cfg, ""
+	}
+	cfg.DefaultBaseRef = cmp.Or(tmpl.BaseRef, cfg.DefaultBaseRef)
+	cfg.Repositories = slices.Clone(cfg.Repositories)
+	for i, repo := range cfg.Repositories {
+		if repo.Path == repoPath {
+			cfg.Repositories[i] = config.ApplyTemplate(repo, tmpl)
+		}
+	}
+	return cfg, tmpl.BranchPrefix
+}
+
+// recordTemplate remembers the template the worktree of msg was created with.
+func (m Model) recordTemplate(msg WorktreeAddedMsg) {
+	if msg.Template == "" || m.templateStore == nil {
+		return
+	}
+	if err := m.templateStore.Set(msg.WorktreePath, msg.Template); err != nil {
+		logging.For("template").Warn("recording the template failed (non-fatal)", "path", msg.WorktreePath, "err", err)
+	}
+}
+
+func renderTemplatePickerView(m Model) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Add Worktree"))
+	b.WriteString("\n")
+	b.WriteString("  Pick a template:\n")
+
+	clip := lipgloss.NewStyle().MaxWidth(max(m.sidebarWidth, 20))
+	selectedStyle := lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
+	for i, name := range m.templateChoices() {
+		line := "  " + name
+		if i == m.templateCursor {
+			line = selectedStyle.Render("> " + name)
+		}
+		if i > 0 {
+			if desc := templateSummary(m.config.Templates[i-1]); desc != "" {
+				line += " " + sortLabelStyle.Render(desc)
+			}
+		}
+		b.WriteString(clip.Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render(keyhelp.ShortHelp(keyhelp.Bindings(templateKeys)...)))
+	return b.String()
+}
+
+// templateSummary describes what a template changes, e.g. "off origin/release, fix/".
+func templateSummary(tmpl model.WorktreeTemplate) string {
+	var parts []string
+	if tmpl.BaseRef != "" {
+		parts = append(parts, "off "+tmpl.BaseRef)
+	}
+	if tmpl.BranchPrefix != "" {
+		parts = append(parts, tmpl.BranchPrefix+"/")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func templatesModel() Model {
+	m := testModel()
+	m.config = model.Config{
+		WorktreeBasePath: "/tmp/yakumo",
+		DefaultBaseRef:   "origin/main",
+		Repositories:     []model.RepositoryDef{{Name: "repo1", Path: "/code/repo1", CopyOnCreate: []string{".env"}}},
+		Templates: []model.WorktreeTemplate{
+			{Name: "bugfix", BaseRef: "origin/release", BranchPrefix: "fix", CopyOnCreate: []string{"config/master.key"}},
+			{Name: "experiment", StartupCommand: "make sandbox"},
+		},
+	}
+	for i, item := range m.items {
+		if item.Kind == model.ItemKindAddWorktree {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
+
+func TestUpdate_AddWorktree_PicksTemplate(t *testing.T) {
+	result, _ := templatesModel().Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := result.(Model)
+	if !m.pickingTemplate || m.addingWorktree {
+		t.Fatalf("pickingTemplate = %v, addingWorktree = %v; want the picker first", m.pickingTemplate, m.addingWorktree)
+	}
+	if got := m.templateChoices(); !slices.Equal(got, []string{"default", "bugfix", "experiment"}) {
+		t.Errorf("templateChoices = %v", got)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	result, _ = result.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(Model)
+	if m.pickingTemplate || !m.addingWorktree || m.addTemplate != "bugfix" || m.addingWorktreeRepoPath != "/code/repo1" {
+		t.Errorf("after picking: pickingTemplate = %v, addingWorktree = %v, addTemplate = %q, repo = %q",
+			m.pickingTemplate, m.addingWorktree, m.addTemplate, m.addingWorktreeRepoPath)
+	}
+}
+
+func TestUpdate_AddWorktree_TemplatePickerCancel(t *testing.T) {
+	result, _ := templatesModel().Update(tea.KeyMsg{Type: tea.KeyEnter})
+	result, _ = result.(Model).Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if m := result.(Model); m.pickingTemplate || m.addingWorktree {
+		t.Errorf("pickingTemplate = %v, addingWorktree = %v after esc", m.pickingTemplate, m.addingWorktree)
+	}
+}
+
+func TestTemplateConfig(t *testing.T) {
+	m := templatesModel()
+	if cfg, prefix := m.templateConfig("/code/repo1"); prefix != "" || cfg.DefaultBaseRef != "origin/main" {
+		t.Errorf("without a template: base ref %q, prefix %q", cfg.DefaultBaseRef, prefix)
+	}
+
+	m.addTemplate = "bugfix"
+	cfg, prefix := m.templateConfig("/code/repo1")
+	if prefix != "fix" || cfg.DefaultBaseRef != "origin/release" {
+		t.Errorf("base ref %q, prefix %q; want origin/release and fix", cfg.DefaultBaseRef, prefix)
+	}
+	if got := cfg.Repositories[0].CopyOnCreate; !slices.Equal(got, []string{".env", "config/master.key"}) {
+		t.Errorf("CopyOnCreate = %v", got)
+	}
+	if got := m.config.Repositories[0].CopyOnCreate; !slices.Equal(got, []string{".env"}) {
+		t.Errorf("the model's config changed: CopyOnCreate = %v", got)
+	}
+}
+
+func TestAddWorktreeWithNameCmd_BranchPrefix(t *testing.T) {
+	basePath := t.TempDir()
+	wantPath := filepath.Join(basePath, "myrepo", "login")
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/repo:[fetch origin release]": "",
+			fmt.Sprintf("/repo:%v", []string{"worktree", "add", wantPath, "-b", "fix/login", "origin/release"}): "",
+		},
+	}
+
+	msg := addWorktreeWithNameCmd(runner, "/repo", basePath, "myrepo", "origin/release", "fix", "login")()
+	if added, ok := msg.(WorktreeAddedMsg); !ok || added.Branch != "fix/login" {
+		t.Errorf("msg = %#v, want fix/login added without asking git for the user", msg)
+	}
+}
+
+type fakeTemplateStore map[string]string
+
+func (s fakeTemplateStore) Set(worktreePath, name string) error {
+	s[worktreePath] = name
+	return nil
+}
+
+func TestUpdate_WorktreeAddedMsg_RecordsTemplate(t *testing.T) {
+	store := fakeTemplateStore{}
+	m := testModel().WithTemplateStore(store)
+
+	m.Update(WorktreeAddedMsg{RepoPath: "/code/repo1", WorktreePath: "/wt/chile", Branch: "fix/chile", Template: "bugfix"})
+	m.Update(WorktreeAddedMsg{RepoPath: "/code/repo1", WorktreePath: "/wt/peru", Branch: "alice/peru"})
+
+	if len(store) != 1 || store["/wt/chile"] != "bugfix" {
+		t.Errorf("recorded templates = %v, want only /wt/chile as bugfix", store)
+	}
+}
@@ -0,0 +1,172 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+)
+
+// Severity is how a toast is colored. Warnings and errors are also kept in
+// the log of recent errors.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// Toast is a one-line notice shown in place of the sidebar help line, so a
+// failed add or archive doesn't take over the screen.
+type Toast struct {
+	Severity Severity
+	Text     string
+	Hint     string // what the user can do about an error, from errorHint
+	At       time.Time
+}
+
+// ToastExpiredMsg hides the toast it was scheduled for, unless a newer one
+// has replaced it.
+type ToastExpiredMsg struct {
+	ID int
+}
+
+const (
+	toastDuration = 5 * time.Second
+	// maxErrorLog is how many warnings and errors the e view keeps.
+	maxErrorLog = 50
+)
+
+// notify shows text as a toast and logs it when it is a warning or error.
+func (m Model) notify(severity Severity, text, hint string) (Model, tea.Cmd) {
+	t := Toast{Severity: severity, Text: text, Hint: hint, At: time.Now()}
+	m.toast = t
+	m.toastID++
+	if severity != SeverityInfo {
+		m.errorLog = append(m.errorLog, t)
+		if len(m.errorLog) > maxErrorLog {
+			m.errorLog = m.errorLog[len(m.errorLog)-maxErrorLog:]
+		}
+	}
+	id := m.toastID
+	return m, tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return ToastExpiredMsg{ID: id}
+	})
+}
+
+// notifyErr shows err as an error toast.
+func (m Model) notifyErr(err error) (Model, tea.Cmd) {
+	return m.notify(SeverityError, err.Error(), errorHint(err))
+}
+
+func (m Model) expireToast(msg ToastExpiredMsg) Model {
+	if msg.ID == m.toastID {
+		m.toast = Toast{}
+	}
+	return m
+}
+
+func toastStyle(severity Severity) lipgloss.Style {
+	switch severity {
+	case SeverityError:
+		return lipgloss.NewStyle().Foreground(colorRed)
+	case SeverityWarning:
+		return lipgloss.NewStyle().Foreground(colorYellow)
+	default:
+		return lipgloss.NewStyle().Foreground(colorGreen)
+	}
+}
+
+func toastIcon(severity Severity) string {
+	switch severity {
+	case SeverityError:
+		return "✗"
+	case SeverityWarning:
+		return "!"
+	default:
+		return "✓"
+	}
+}
+
+// renderToast renders the toast on one line, cut to the sidebar width, with
+// the spacing of the help line it stands in for.
+func renderToast(t Toast, width int) string {
+	line := toastIcon(t.Severity) + " " + firstLine(t.Text)
+	if width > 4 {
+		line = truncate(line, width-2)
+	}
+	return toastStyle(t.Severity).PaddingLeft(1).PaddingTop(1).Render(line)
+}
+
+func firstLine(s string) string {
+	first, _, _ := strings.Cut(s, "\n")
+	return first
+}
+
+// openErrorLog shows the e view listing recent warnings and errors.
+func (m Model) openErrorLog() Model {
+	m.showingErrorLog = true
+	m.errorLogScroll = 0
+	return m
+}
+
+func (m Model) updateErrorLogMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, globalKeys.ForceQuit):
+		m.quitting = true
+		return m, tea.Quit
+	case key.Matches(msg, errorLogKeys.Close):
+		m.showingErrorLog = false
+	case key.Matches(msg, errorLogKeys.Clear):
+		m.errorLog = nil
+		m.errorLogScroll = 0
+	case key.Matches(msg, errorLogKeys.Down):
+		m.errorLogScroll = min(m.errorLogScroll+1, max(len(m.errorLogLines())-1, 0))
+	case key.Matches(msg, errorLogKeys.Up):
+		m.errorLogScroll = max(m.errorLogScroll-1, 0)
+	}
+	return m, nil
+}
+
+// errorLogLines lists the logged toasts newest first, wrapped to the sidebar.
+func (m Model) errorLogLines() []string {
+	if len(m.errorLog) == 0 {
+		return []string{helpStyle.PaddingTop(0).Render("No errors so far.")}
+	}
+	width := max(m.sidebarWidth, 20) - 3
+	var lines []string
+	for i := len(m.errorLog) - 1; i >= 0; i-- {
+		t := m.errorLog[i]
+		text := t.At.Format("15:04:05") + " " + toastIcon(t.Severity) + " " + t.Text
+		lines = append(lines, strings.Split(toastStyle(t.Severity).Render(indentLines(wrapText(text, width), "  ")), "\n")...)
+		if t.Hint != "" {
+			lines = append(lines, strings.Split(helpStyle.PaddingTop(0).Render(indentLines(wrapText(t.Hint, width-2), "    ")), "\n")...)
+		}
+	}
+	return lines
+}
+
+func renderErrorLogView(m Model) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Recent errors"))
+	b.WriteString("\n")
+
+	lines := m.errorLogLines()
+	start := min(m.errorLogScroll, max(len(lines)-1, 0))
+	end := len(lines)
+	if vp := viewportHeight(m.height); vp > 0 && start+vp < end {
+		end = start + vp
+	}
+	for _, l := range lines[start:end] {
+		b.WriteString(l)
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpStyle.Render(keyhelp.ShortHelp(keyhelp.Bindings(errorLogKeys)...)))
+	return b.String()
+}
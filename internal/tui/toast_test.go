@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/github"
+)
+
+func TestUpdate_WorktreeAddErrMsg_ShowsToastOverList(t *testing.T) {
+	m := testModel()
+	result, cmd := m.Update(WorktreeAddErrMsg{Err: fmt.Errorf("branch already exists")})
+	m = result.(Model)
+	if cmd == nil {
+		t.Error("expected a timer to hide the toast")
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "✗ branch already exists") {
+		t.Errorf("view should show the toast:\n%s", view)
+	}
+	if !strings.Contains(view, "feature-x") || strings.Contains(view, workspacesHelp()) {
+		t.Errorf("the toast should replace the help line and keep the list:\n%s", view)
+	}
+}
+
+func TestToastExpiredMsg_OnlyHidesItsToast(t *testing.T) {
+	m := testModel()
+	m, _ = m.notifyErr(fmt.Errorf("first"))
+	m, _ = m.notify(SeverityInfo, "second", "")
+
+	result, _ := m.Update(ToastExpiredMsg{ID: m.toastID - 1})
+	m = result.(Model)
+	if m.toast.Text != "second" {
+		t.Errorf("toast = %q, a stale timer should leave the newer toast", m.toast.Text)
+	}
+
+	m.addingWorktree = true
+	result, _ = m.Update(ToastExpiredMsg{ID: m.toastID})
+	m = result.(Model)
+	if m.toast.Text != "" {
+		t.Errorf("toast = %q, want hidden even behind a prompt", m.toast.Text)
+	}
+	if len(m.errorLog) != 1 {
+		t.Errorf("errorLog has %d entries, want only the error", len(m.errorLog))
+	}
+}
+
+func TestNotify_CapsErrorLog(t *testing.T) {
+	m := testModel()
+	for i := range maxErrorLog + 5 {
+		m, _ = m.notify(SeverityWarning, fmt.Sprintf("warning %d", i), "")
+	}
+	if len(m.errorLog) != maxErrorLog || m.errorLog[0].Text != "warning 5" {
+		t.Errorf("errorLog = %d entries starting at %q, want the last %d", len(m.errorLog), m.errorLog[0].Text, maxErrorLog)
+	}
+}
+
+func TestUpdate_E_ListsRecentErrorsNewestFirst(t *testing.T) {
+	m := testModel()
+	m.sidebarWidth = 60
+	m, _ = m.notifyErr(fmt.Errorf("archiving: %w", github.ErrRateLimited))
+	m, _ = m.notify(SeverityWarning, "fetching origin failed", "")
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	if !m.showingErrorLog {
+		t.Fatal("e should open the error log")
+	}
+	view := m.View()
+	newer, older := strings.Index(view, "fetching origin failed"), strings.Index(view, "archiving:")
+	if newer < 0 || older < 0 || newer > older {
+		t.Errorf("errors should be listed newest first:\n%s", view)
+	}
+	if !strings.Contains(view, "rate limit") {
+		t.Errorf("the hint of an error should be listed under it:\n%s", view)
+	}
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if len(m.errorLog) != 0 || !strings.Contains(m.View(), "No errors so far.") {
+		t.Error("x should clear the log")
+	}
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.showingErrorLog {
+		t.Error("esc should close the error log")
+	}
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/buildinfo"
+)
+
+// UpdateCheck returns the tag of the newest yakumo release.
+type UpdateCheck func() (string, error)
+
+// UpdateCheckedMsg carries the newest release, "" when it could not be
+// looked up.
+type UpdateCheckedMsg struct {
+	Latest string
+}
+
+// WithUpdateCheck returns a copy of the model that looks up the newest
+// release with check on start and, when it is later than current, says so
+// above the help line.
+func (m Model) WithUpdateCheck(current string, check UpdateCheck) Model {
+	m.currentVersion = current
+	m.updateCheck = check
+	return m
+}
+
+// updateCheckCmd looks up the newest release. Being offline or rate limited
+// is not worth a toast, so errors just leave it unknown.
+func updateCheckCmd(check UpdateCheck) tea.Cmd {
+	return func() tea.Msg {
+		latest, err := check()
+		if err != nil {
+			return UpdateCheckedMsg{}
+		}
+		return UpdateCheckedMsg{Latest: latest}
+	}
+}
+
+// renderUpdate returns the line telling of a newer release, or "" without one.
+func (m Model) renderUpdate() string {
+	if !buildinfo.Newer(m.currentVersion, m.latestRelease) {
+		return ""
+	}
+	return hintStyle.Width(max(m.sidebarWidth, 20)).Render(fmt.Sprintf("yakumo %s is out", m.latestRelease))
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestUpdateCheck_ShowsNewerRelease(t *testing.T) {
+	m := testModel()
+	m.height = 20
+	without := m.listHeight()
+
+	m = m.WithUpdateCheck("v0.4.0", func() (string, error) { return "v0.5.0", nil })
+	result, _ := m.Update(updateCheckCmd(m.updateCheck)())
+	m = result.(Model)
+	if view := m.View(); !strings.Contains(view, "yakumo v0.5.0 is out") {
+		t.Errorf("view lacks the new release:\n%s", view)
+	}
+	if got, want := m.listHeight(), without-lipgloss.Height(m.renderUpdate()); got != want {
+		t.Errorf("listHeight = %d, want %d with the update line", got, want)
+	}
+}
+
+func TestUpdateCheck_NothingToTell(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		check   UpdateCheck
+	}{
+		{"up to date", "v0.5.0", func() (string, error) { return "v0.5.0", nil }},
+		{"dev build", "dev", func() (string, error) { return "v0.5.0", nil }},
+		{"offline", "v0.4.0", func() (string, error) { return "", errors.New("dial tcp: no route to host") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel().WithUpdateCheck(tt.current, tt.check)
+			result, _ := m.Update(updateCheckCmd(tt.check)())
+			if view := result.(Model).View(); strings.Contains(view, "is out") {
+				t.Errorf("view should not tell of an update:\n%s", view)
+			}
+		})
+	}
+}
@@ -4,21 +4,30 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/lipgloss"
 	zone "github.com/lrstanley/bubblezone"
 
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
 	"github.com/mikanfactory/yakumo/internal/model"
 )
 
-const (
-	workspacesTitle = "Workspaces"
-	workspacesHelp  = "q: quit  ↑↓/jk: move  enter/click: select  d: archive"
-)
+const workspacesTitle = "Workspaces"
+
+// workspacesHelp is the sidebar help line. It names only the essential keys,
+// so that it fits the narrow pane and "?: help" stays in view; the ? overlay
+// lists the rest. The keys come from the keymap, so they follow the
+// `keybindings` config.
+func workspacesHelp() string {
+	k := sidebarKeys
+	move := key.NewBinding(key.WithHelp(k.Up.Help().Key+" "+k.Down.Help().Key, "move"))
+	return keyhelp.ShortHelp(k.Quit, move, k.Open, k.Archive, k.Help)
+}
 
 // reservedRows is the chrome height (title + spacer + help). The title and
-// help styles are static, so this is computed once at package init rather than
-// re-rendered on every frame.
-var reservedRows = lipgloss.Height(titleStyle.Render(workspacesTitle)) + 1 + lipgloss.Height(helpStyle.Render(workspacesHelp))
+// help styles only change with the theme, so ApplyTheme computes this rather
+// than it being re-rendered on every frame.
+var reservedRows int
 
 func (m Model) View() string {
 	if m.quitting {
@@ -33,53 +42,184 @@ func (m Model) View() string {
 		return renderAddRepoView(m)
 	}
 
+	if m.pickingTemplate {
+		return renderTemplatePickerView(m)
+	}
+
 	if m.addingWorktree {
 		return renderAddWorktreeView(m)
 	}
 
+	if m.editingDescription {
+		return renderEditDescriptionView(m)
+	}
+
+	if m.sendingPrompt {
+		return renderSendPromptView(m)
+	}
+
+	if m.renamingWorktree {
+		return renderRenameWorktreeView(m)
+	}
+
+	if m.grepping {
+		return renderGrepInputView(m)
+	}
+
+	if m.showingGrep {
+		return renderGrepResultsView(m)
+	}
+
+	if m.showingWIP {
+		return renderWIPView(m)
+	}
+
 	if m.confirmingArchive {
 		return renderArchiveConfirmView(m)
 	}
 
+	if m.removingRepo {
+		return renderRemoveRepoView(m)
+	}
+
+	if m.showingCleanup {
+		return renderCleanupView(m)
+	}
+
+	if m.showingArchived {
+		return renderArchivedView(m)
+	}
+
+	if m.showingPrune {
+		return renderPruneView(m)
+	}
+
+	if m.showingRestore {
+		return renderRestoreView(m)
+	}
+
+	if m.showingRebase {
+		return renderRebaseView(m)
+	}
+
+	if m.showingHelp {
+		return renderHelpView(m)
+	}
+
+	if m.showingErrorLog {
+		return renderErrorLogView(m)
+	}
+
+	if m.showingAgentActivity {
+		return renderAgentActivityView(m)
+	}
+
+	if m.showingPanes {
+		return renderPanesView(m)
+	}
+
+	if m.showingTasks {
+		return renderTasksView(m)
+	}
+
+	if m.showingQuickDiff {
+		return renderQuickDiffView(m)
+	}
+
+	if m.showingDevLog {
+		return renderDevLogView(m)
+	}
+
+	if m.showingDebugLog {
+		return renderDebugLogView(m)
+	}
+
 	if m.loading {
-		return titleStyle.Render(workspacesTitle) + "\n\n  Loading..."
+		return titleStyle.Render(workspacesTitle) + "\n\n  " + m.progressLine("Loading...")
 	}
 
 	if m.err != nil {
-		return titleStyle.Render(workspacesTitle) + "\n\n  Error: " + m.err.Error()
+		view := titleStyle.Render(workspacesTitle) + "\n\n  Error: " + m.err.Error()
+		if hint := errorHint(m.err); hint != "" {
+			view += "\n\n  " + helpStyle.Render(hint)
+		}
+		return view
 	}
 
 	title := titleStyle.Render(workspacesTitle)
-	help := helpStyle.Render(workspacesHelp)
+	help := helpStyle.Render(workspacesHelp())
+	if m.filtering {
+		help = helpStyle.Render("/ " + m.textInput.View())
+	} else if m.selecting {
+		help = helpStyle.Render(m.selectHelp())
+	} else if m.toast.Text != "" {
+		help = renderToast(m.toast, m.sidebarWidth)
+	}
 
-	vp := viewportHeight(m.height)
+	vp, overflows := m.listLayout()
 
 	var b strings.Builder
 	b.WriteString(title)
 	b.WriteString("\n")
+	if summary := m.renderAgentSummary(); summary != "" {
+		b.WriteString(summary)
+		b.WriteString("\n")
+	}
+	if hint := m.renderHint(); hint != "" {
+		b.WriteString(hint)
+		b.WriteString("\n")
+	}
 
+	if overflows {
+		b.WriteString(renderScrollIndicator("↑", m.items[:m.scrollOff]))
+		b.WriteString("\n")
+	}
 	used := 0
-	for i := m.scrollOff; i < len(m.items); i++ {
-		item := m.items[i]
-		isSelected := i == m.cursor
+	end := m.scrollOff
+	for ; end < len(m.items); end++ {
+		item := m.items[end]
+		isSelected := end == m.cursor
 		line := renderItem(item, isSelected, m.sidebarWidth)
 		h := lipgloss.Height(line)
 		if vp > 0 && used+h > vp {
 			break
 		}
 		if item.Selectable {
-			line = zone.Mark(ZoneID(i), line)
+			line = zone.Mark(ZoneID(end), line)
 		}
 		b.WriteString(line)
 		b.WriteString("\n")
 		used += h
 	}
+	if overflows {
+		b.WriteString(renderScrollIndicator("↓", m.items[end:]))
+		b.WriteString("\n")
+	}
 
+	if update := m.renderUpdate(); update != "" {
+		b.WriteString(update)
+		b.WriteString("\n")
+	}
 	b.WriteString(help)
 
 	return zone.Scan(b.String())
 }
 
+// renderScrollIndicator counts the rows scrolled out of view in one
+// direction, such as "↓ 12 more". It is blank when none are.
+func renderScrollIndicator(arrow string, hidden []model.NavigableItem) string {
+	n := 0
+	for _, item := range hidden {
+		if item.Selectable {
+			n++
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	return scrollIndicatorStyle.Render(fmt.Sprintf("%s %d more", arrow, n))
+}
+
 // viewportHeight returns the rows available for the items section given the
 // full terminal height. Returns 0 as a sentinel meaning "size unknown — render
 // every item" so the first frames before WindowSizeMsg arrives still work.
@@ -109,7 +249,7 @@ func itemHeights(items []model.NavigableItem, cursor, sidebarWidth int) []int {
 func renderItem(item model.NavigableItem, selected bool, width int) string {
 	switch item.Kind {
 	case model.ItemKindGroupHeader:
-		return groupHeaderStyle.Render(item.Label)
+		return renderGroupHeader(item, selected)
 
 	case model.ItemKindWorktree:
 		return renderWorktree(item, selected, width)
@@ -123,30 +263,68 @@ func renderItem(item model.NavigableItem, selected bool, width int) string {
 }
 
 func renderWorktree(item model.NavigableItem, selected bool, width int) string {
+	line := renderWorktreeLine(item, selected, width)
+	if item.Description != "" {
+		description := item.Description
+		if maxLen := width - 5; maxLen > 0 && lipgloss.Width(description) > maxLen {
+			description = truncate(description, maxLen)
+		}
+		line += "\n" + descriptionStyle.Render(description)
+	}
+	// The port block is detail for the worktree under the cursor only.
+	if selected && item.Ports != "" {
+		line += "\n" + descriptionStyle.Render("ports "+item.Ports)
+	}
+	return line
+}
+
+func renderWorktreeLine(item model.NavigableItem, selected bool, width int) string {
 	agentIcon := AgentIcon(item.AgentStatus)
 	statusBadge := FormatStatus(item.Status)
+	// The pin and, in the Recent section, the repository name sit with the
+	// PR badge right after the branch name.
+	prBadge := PRBadge(item.PR)
+	if item.Pinned {
+		prBadge = " " + sortLabelStyle.Render(iconPin) + prBadge
+	}
+	if item.RecentRepo != "" {
+		prBadge += sortLabelStyle.Render(" " + item.RecentRepo)
+	}
 	branchName := item.Label
+	stateText := AgentStateText(item.AgentStatus)
 
 	// Use inline styles to avoid PaddingLeft double-application when
 	// inserting agent icon between indent and branch name.
 	selectedBranchStyle := lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
 	normalBranchStyle := lipgloss.NewStyle().Foreground(colorFg)
 
+	// Marked worktrees show a check in the first column of the indent.
+	mark := " "
+	if item.Marked {
+		mark = markStyle.Render("✓")
+	}
+
 	var leftPart string
 	if selected {
 		prefix := " > " + agentIcon
-		maxBranchLen := width - lipgloss.Width(prefix) - lipgloss.Width(statusBadge) - 1
+		maxBranchLen := width - lipgloss.Width(prefix) - lipgloss.Width(prBadge) - lipgloss.Width(statusBadge) - 1
+		branchName, stateText = fitAgentState(branchName, stateText, maxBranchLen)
 		if maxBranchLen > 0 && lipgloss.Width(branchName) > maxBranchLen {
 			branchName = truncate(branchName, maxBranchLen)
 		}
-		leftPart = selectedBranchStyle.Render(" > ") + agentIcon + selectedBranchStyle.Render(branchName)
+		leftPart = mark + selectedBranchStyle.Render("> ") + agentIcon + renderHighlighted(branchName, item.Highlight, selectedBranchStyle) + renderAgentState(stateText, item.AgentStatus) + prBadge
 	} else {
 		prefix := "   " + agentIcon
-		maxBranchLen := width - lipgloss.Width(prefix) - lipgloss.Width(statusBadge) - 1
+		maxBranchLen := width - lipgloss.Width(prefix) - lipgloss.Width(prBadge) - lipgloss.Width(statusBadge) - 1
+		highlight := item.Highlight
+		branchName, stateText = fitAgentState(branchName, stateText, maxBranchLen)
 		if maxBranchLen > 0 && lipgloss.Width(branchName) > maxBranchLen {
 			branchName = truncate(branchName, maxBranchLen)
 		}
-		leftPart = "   " + agentIcon + normalBranchStyle.Render(branchName)
+		if branchName != item.Label {
+			highlight = nil
+		}
+		leftPart = mark + "  " + agentIcon + renderHighlighted(branchName, highlight, normalBranchStyle) + renderAgentState(stateText, item.AgentStatus) + prBadge
 	}
 
 	if statusBadge == "" {
@@ -160,6 +338,60 @@ func renderWorktree(item model.NavigableItem, selected bool, width int) string {
 	return leftPart + strings.Repeat(" ", padding) + statusBadge
 }
 
+// minBranchWithState is how much of the branch name stays visible before the
+// agent state text next to it gives way.
+const minBranchWithState = 8
+
+// fitAgentState shares maxLen columns between the branch name and the agent
+// state text after it: the branch is shortened first, down to
+// minBranchWithState, then the state text, which is dropped when too little
+// of it would be left. A maxLen of 0 or less means there is no limit.
+func fitAgentState(branch, state string, maxLen int) (string, string) {
+	if state == "" || maxLen <= 0 {
+		return branch, state
+	}
+	stateLen := lipgloss.Width(state) + 1 // the space before it
+	if lipgloss.Width(branch)+stateLen <= maxLen {
+		return branch, state
+	}
+	branchLen := max(maxLen-stateLen, min(lipgloss.Width(branch), minBranchWithState))
+	if lipgloss.Width(branch) > branchLen {
+		branch = truncate(branch, branchLen)
+	}
+	room := maxLen - lipgloss.Width(branch) - 1
+	switch {
+	case room >= stateLen-1:
+		return branch, state
+	case room >= 4: // the icon, a space and a letter or two
+		return branch, truncate(state, room)
+	default:
+		return branch, ""
+	}
+}
+
+func renderAgentState(text string, agents []model.AgentInfo) string {
+	if text == "" {
+		return ""
+	}
+	return " " + agentStateStyle(agents).Render(text)
+}
+
+func renderGroupHeader(item model.NavigableItem, selected bool) string {
+	label := item.Label
+	if item.Collapsed {
+		label = "▸ " + label
+	}
+	style := groupHeaderStyle
+	if selected {
+		style = groupHeaderSelectedStyle
+	}
+	header := style.Render(label)
+	if item.SortLabel != "" {
+		header += sortLabelStyle.Render(" ↕ " + item.SortLabel)
+	}
+	return header + BaseRedBadge(item.BaseRed)
+}
+
 func renderAction(item model.NavigableItem, selected bool) string {
 	if selected {
 		return actionSelectedStyle.Render(fmt.Sprintf("> %s", item.Label))
@@ -168,86 +400,72 @@ func renderAction(item model.NavigableItem, selected bool) string {
 }
 
 func renderArchiveConfirmView(m Model) string {
-	var b strings.Builder
-
-	b.WriteString(titleStyle.Render("Archive Worktree"))
-	b.WriteString("\n\n")
-
 	if m.loading {
-		b.WriteString("  Removing worktree...")
-		return b.String()
+		return titleStyle.Render("Archive Worktree") + "\n\n  " + m.progressLine("Removing worktree...")
 	}
 
-	item := m.items[m.archiveTarget]
-	b.WriteString(fmt.Sprintf("  Remove worktree '%s'?\n", item.Label))
-	b.WriteString("  The branch will be preserved.\n")
-
-	if m.err != nil {
-		b.WriteString("\n")
-		b.WriteString(errorStyle.Render(fmt.Sprintf("  Error: %s", m.err.Error())))
-		b.WriteString("\n")
+	notes := []string{"The branch will be preserved."}
+	if m.trash.Enabled() {
+		notes = append(notes, "The worktree moves to the trash; press u to restore it.")
 	}
-
-	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("enter: confirm  esc: cancel"))
-
-	return b.String()
+	var question string
+	if len(m.archiveMarked) > 0 {
+		question = m.archiveMarkedPrompt()
+	} else {
+		question = fmt.Sprintf("Remove worktree '%s'?", m.items[m.archiveTarget].Label)
+	}
+	help := "enter: confirm  esc: cancel"
+	if m.archiveHookFailed {
+		help = "enter: retry  f: archive without the hook  esc: cancel"
+	}
+	return modalLayout{
+		title: "Archive Worktree",
+		input: question,
+		notes: notes,
+		err:   m.err,
+		help:  help,
+	}.render(m.width, m.height)
 }
 
 func renderAddRepoView(m Model) string {
-	var b strings.Builder
-
-	b.WriteString(titleStyle.Render("Add Repository"))
-	b.WriteString("\n\n")
-
 	if m.loading {
-		b.WriteString("  Validating...")
-		return b.String()
-	}
-
-	b.WriteString("  Enter the path to a git repository:\n\n")
-	b.WriteString("  ")
-	b.WriteString(m.textInput.View())
-	b.WriteString("\n")
-
-	if m.err != nil {
-		b.WriteString("\n")
-		b.WriteString(errorStyle.Render(fmt.Sprintf("  Error: %s", m.err.Error())))
-		b.WriteString("\n")
+		return titleStyle.Render("Add Repository") + "\n\n  Validating..."
 	}
 
-	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("enter: confirm  tab: complete  esc: cancel"))
-
-	return b.String()
+	return modalLayout{
+		title:  "Add Repository",
+		prompt: "Enter the path to a git repository:",
+		input:  m.textInput.View(),
+		notes:  renderPathPicker(m),
+		err:    m.err,
+		help:   "tab/shift+tab: complete  enter: confirm  esc: cancel",
+	}.render(m.width, m.height)
 }
 
 func renderAddWorktreeView(m Model) string {
-	var b strings.Builder
-
-	b.WriteString(titleStyle.Render("Add Worktree"))
-	b.WriteString("\n\n")
-
 	if m.loading {
-		b.WriteString("  Creating worktree...")
-		return b.String()
+		return titleStyle.Render("Add Worktree") + "\n\n  " + m.progressLine("Creating worktree...")
 	}
 
-	b.WriteString("  Paste a GitHub URL, enter a branch name, or press Enter for a new branch:\n\n")
-	b.WriteString("  ")
-	b.WriteString(m.textInput.View())
-	b.WriteString("\n")
-
-	if m.err != nil {
-		b.WriteString("\n")
-		b.WriteString(errorStyle.Render(fmt.Sprintf("  Error: %s", m.err.Error())))
-		b.WriteString("\n")
+	title := "Add Worktree"
+	if m.addTemplate != "" {
+		title += " (" + m.addTemplate + ")"
 	}
-
-	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("enter: confirm  esc: cancel"))
-
-	return b.String()
+	layout := modalLayout{
+		title:  title,
+		prompt: "Paste a GitHub URL, type a name for a new branch (owner/branch checks out an existing one), pick an existing branch, or press Enter for a random name:",
+		input:  m.textInput.View(),
+		err:    m.err,
+		help:   "enter: confirm  esc: cancel",
+	}
+	if m.clipboardPrefilled {
+		layout.notes = []string{helpStyle.PaddingTop(0).Render("URL from clipboard, press enter to use it")}
+	}
+	if picker := renderBranchPicker(m); len(picker) > 0 {
+		layout.notes = append(layout.notes, picker...)
+		layout.help = "↑↓: pick branch  enter: confirm  esc: cancel"
+	}
+	return layout.render(m.width, m.height)
 }
 
 func truncate(s string, maxLen int) string {
@@ -2,10 +2,13 @@ package tui
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
 	"github.com/mikanfactory/yakumo/internal/model"
 	"github.com/mikanfactory/yakumo/internal/sidebar"
@@ -269,6 +272,41 @@ func TestView_HidesItemsBeforeScrollOff(t *testing.T) {
 	}
 }
 
+func TestView_ScrollIndicators(t *testing.T) {
+	m := manyGroupsModel(5)
+	m.height = 14
+	m = recomputeScroll(m)
+
+	above, below := regexp.MustCompile(`↑ \d+ more`), regexp.MustCompile(`↓ \d+ more`)
+	view := m.View()
+	if above.MatchString(view) || !below.MatchString(view) {
+		t.Errorf("at the top only the rows below should be counted:\n%s", view)
+	}
+
+	for i := len(m.items) - 1; i >= 0; i-- {
+		if m.items[i].Selectable {
+			m.cursor = i
+			break
+		}
+	}
+	m = recomputeScroll(m)
+	view = m.View()
+	if !above.MatchString(view) || below.MatchString(view) {
+		t.Errorf("at the bottom only the rows above should be counted:\n%s", view)
+	}
+	if lines := strings.Count(view, "\n") + 1; lines > m.height {
+		t.Errorf("view has %d lines with the indicators, exceeds terminal height %d:\n%s", lines, m.height, view)
+	}
+}
+
+func TestView_NoScrollIndicatorsWhenItemsFit(t *testing.T) {
+	m := testModel()
+	m.height = 40
+	if view := m.View(); strings.Contains(view, "more") {
+		t.Errorf("a list that fits should not show scroll indicators:\n%s", view)
+	}
+}
+
 func TestView_RendersAllWhenHeightUnset(t *testing.T) {
 	m := manyGroupsModel(5)
 	// m.height = 0 means "size unknown — render every item".
@@ -318,6 +356,26 @@ func TestFormatStatus_DeletionsOnly(t *testing.T) {
 	}
 }
 
+func TestFormatStatus_AheadBehind(t *testing.T) {
+	result := FormatStatus(model.StatusInfo{Insertions: 3, Ahead: 3, Behind: 1})
+	if !strings.Contains(result, "+3") || !strings.Contains(result, "↑3") || !strings.Contains(result, "↓1") {
+		t.Errorf("should contain +3, ↑3 and ↓1, got %q", result)
+	}
+	if strings.Index(result, "+3") > strings.Index(result, "↑3") {
+		t.Errorf("line stats should come before commit counts, got %q", result)
+	}
+}
+
+func TestFormatStatus_BehindOnly(t *testing.T) {
+	result := FormatStatus(model.StatusInfo{Behind: 12})
+	if !strings.Contains(result, "↓12") {
+		t.Errorf("a stale branch without changes should still show ↓12, got %q", result)
+	}
+	if strings.Contains(result, "↑") {
+		t.Error("should not contain ahead count")
+	}
+}
+
 func TestView_ShowsClickHelp(t *testing.T) {
 	m := testModel()
 	view := m.View()
@@ -414,6 +472,48 @@ func TestAgentIcon_HighestPriority(t *testing.T) {
 	}
 }
 
+func TestAgentStateText(t *testing.T) {
+	tests := []struct {
+		name   string
+		agents []model.AgentInfo
+		want   string
+	}{
+		{"none", nil, ""},
+		{"idle", []model.AgentInfo{{State: model.AgentStateIdle}}, ""},
+		{"running", []model.AgentInfo{{State: model.AgentStateRunning, Elapsed: "12m 30s"}}, "✳ running 12m"},
+		{"running without elapsed", []model.AgentInfo{{State: model.AgentStateRunning}}, "✳ running"},
+		{"waiting wins", []model.AgentInfo{{State: model.AgentStateRunning, Elapsed: "3s"}, {State: model.AgentStateWaiting}}, "⚠ waiting"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AgentStateText(tt.agents); got != tt.want {
+				t.Errorf("AgentStateText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderWorktreeLine_AgentStateFitsWidth(t *testing.T) {
+	item := model.NavigableItem{
+		Kind:        model.ItemKindWorktree,
+		Label:       "feature/very-long-branch-name",
+		AgentStatus: []model.AgentInfo{{PaneID: "%0", State: model.AgentStateRunning, Elapsed: "12m 30s"}},
+	}
+
+	wide := renderWorktreeLine(item, false, 60)
+	if !strings.Contains(wide, "✳ running 12m") || !strings.Contains(wide, item.Label) {
+		t.Errorf("a wide sidebar should show the branch and state, got %q", wide)
+	}
+
+	for _, width := range []int{30, 20, 12} {
+		line := renderWorktreeLine(item, true, width)
+		if w := lipgloss.Width(line); w > width {
+			t.Errorf("width %d: line is %d columns: %q", width, w, line)
+		}
+	}
+}
+
 func TestView_ShowsAgentIcon(t *testing.T) {
 	groups := []model.RepoGroup{
 		{
@@ -519,6 +619,20 @@ func TestRenderWorktree_WithStatus(t *testing.T) {
 	}
 }
 
+func TestRenderWorktree_PortsWhenSelected(t *testing.T) {
+	item := model.NavigableItem{
+		Kind:  model.ItemKindWorktree,
+		Label: "feature-branch",
+		Ports: "4010-4019",
+	}
+	if result := renderWorktree(item, false, 40); strings.Contains(result, "4010") {
+		t.Errorf("ports should only show under the cursor, got %q", result)
+	}
+	if result := renderWorktree(item, true, 40); !strings.Contains(result, "ports 4010-4019") {
+		t.Errorf("selected worktree should show its ports, got %q", result)
+	}
+}
+
 func TestRenderWorktree_SingleLine_CleanStatus(t *testing.T) {
 	item := model.NavigableItem{
 		Kind:  model.ItemKindWorktree,
@@ -655,3 +769,101 @@ func TestView_AddWorktreeMode_Loading(t *testing.T) {
 		t.Errorf("view should show creating message, got:\n%s", view)
 	}
 }
+
+func TestView_ShowsBranchDescription(t *testing.T) {
+	m := testModel()
+	for i := range m.items {
+		if m.items[i].Label == "feature-x" {
+			m.items[i].Description = "Rework the login flow"
+		}
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "Rework the login flow") {
+		t.Errorf("view should show the branch description, got:\n%s", view)
+	}
+}
+
+func TestRenderHighlighted(t *testing.T) {
+	plain := lipgloss.NewStyle()
+	if got := renderHighlighted("main", nil, plain); got != "main" {
+		t.Errorf("no highlight = %q, want main", got)
+	}
+	got := renderHighlighted("main", []int{0, 2}, plain)
+	if lipgloss.Width(got) != 4 || !strings.Contains(got, "a") {
+		t.Errorf("highlighted render = %q should keep the text", got)
+	}
+}
+
+func TestUpdate_WindowSizeMsg_FitsTextInput(t *testing.T) {
+	m := testModel()
+
+	result, _ := m.Update(tea.WindowSizeMsg{Width: 30, Height: 20})
+	updated := result.(Model)
+
+	if updated.textInput.Width != 25 {
+		t.Errorf("textInput.Width = %d, want 25", updated.textInput.Width)
+	}
+}
+
+func TestView_AddWorktreeMode_WrapsToWidth(t *testing.T) {
+	m := testModel()
+	m.addingWorktree = true
+	m.textInput.Focus()
+	m.err = fmt.Errorf("creating worktree at /home/user/yakumo/a-very-long-repository-name/feature-branch: exit status 128")
+	result, _ := m.Update(tea.WindowSizeMsg{Width: 30, Height: 40})
+	m = result.(Model)
+
+	view := m.View()
+
+	for _, line := range strings.Split(view, "\n") {
+		if w := lipgloss.Width(line); w > 30 {
+			t.Errorf("line is %d columns wide, want at most 30: %q", w, line)
+		}
+	}
+	if !strings.Contains(view, "status 128") {
+		t.Errorf("wrapped error should still be shown to the end, got:\n%s", view)
+	}
+}
+
+func TestView_AddWorktreeMode_SmallHeightKeepsInput(t *testing.T) {
+	m := testModel()
+	m.addingWorktree = true
+	m.textInput.Placeholder = "branch-name"
+	m.textInput.Focus()
+	m.err = fmt.Errorf("branch or directory already exists: a fairly long explanation that wraps")
+	result, _ := m.Update(tea.WindowSizeMsg{Width: 30, Height: 5})
+	m = result.(Model)
+
+	view := m.View()
+
+	if h := lipgloss.Height(view); h > 5 {
+		t.Errorf("view is %d rows, want at most 5:\n%s", h, view)
+	}
+	if !strings.Contains(view, "ranch-name") {
+		t.Errorf("text input should stay visible, got:\n%s", view)
+	}
+	if !strings.Contains(view, "esc: cancel") {
+		t.Errorf("help line should stay visible, got:\n%s", view)
+	}
+	if strings.Contains(view, "Paste a GitHub URL") {
+		t.Errorf("prompt should be dropped first at small heights, got:\n%s", view)
+	}
+}
+
+func TestView_ConfirmArchiveMode_SmallHeight(t *testing.T) {
+	m := testModel()
+	m.confirmingArchive = true
+	m.archiveTarget = m.cursor
+	result, _ := m.Update(tea.WindowSizeMsg{Width: 30, Height: 4})
+	m = result.(Model)
+
+	view := m.View()
+
+	if h := lipgloss.Height(view); h > 4 {
+		t.Errorf("view is %d rows, want at most 4:\n%s", h, view)
+	}
+	if !strings.Contains(view, "Remove worktree") || !strings.Contains(view, "enter: confirm") {
+		t.Errorf("question and help should stay visible, got:\n%s", view)
+	}
+}
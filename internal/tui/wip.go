@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/keyhelp"
+	"github.com/mikanfactory/yakumo/internal/logging"
+	"github.com/mikanfactory/yakumo/internal/wip"
+)
+
+// WIPFoundMsg carries the auto-WIP snapshot, if any, left in a worktree the
+// user is switching to.
+type WIPFoundMsg struct {
+	Path     string
+	Snapshot wip.Snapshot
+	Found    bool
+	Err      error
+}
+
+// WIPRestoredMsg is sent when a snapshot has been put back.
+type WIPRestoredMsg struct {
+	Err error
+}
+
+// WithAutoWIP returns a copy of the model that, when switching to a worktree,
+// offers to restore the work snapshotted there when it was last left.
+func (m Model) WithAutoWIP(mode wip.Mode) Model {
+	m.autoWIP = mode
+	return m
+}
+
+// HasWorktree reports whether path is a worktree listed in the sidebar.
+func (m Model) HasWorktree(path string) bool {
+	for _, g := range m.groups {
+		for _, wt := range g.Worktrees {
+			if wt.Path == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func findWIPCmd(runner git.CommandRunner, path string) tea.Cmd {
+	return func() tea.Msg {
+		snap, found, err := wip.Find(runner, path)
+		return WIPFoundMsg{Path: path, Snapshot: snap, Found: found, Err: err}
+	}
+}
+
+func restoreWIPCmd(runner git.CommandRunner, path string, snap wip.Snapshot) tea.Cmd {
+	return func() tea.Msg {
+		return WIPRestoredMsg{Err: wip.Restore(runner, path, snap)}
+	}
+}
+
+// selectWorktree picks the worktree to switch to and quits. With auto-WIP
+// on, it first looks for a snapshot there and offers to restore it.
+func (m Model) selectWorktree(path, repoPath string) (Model, tea.Cmd) {
+	m.selectedPane = ""
+	if m.autoWIP == wip.ModeOff {
+		m.selected = path
+		m.selectedRepoPath = repoPath
+		return m, tea.Quit
+	}
+	m.wipTarget = Selection{WorktreePath: path, RepoPath: repoPath}
+	m.wipChecking = true
+	m.wipErr = nil
+	return m, findWIPCmd(m.runner, path)
+}
+
+// switchToWIPTarget finishes the selection started by selectWorktree.
+func (m Model) switchToWIPTarget() (Model, tea.Cmd) {
+	m.showingWIP = false
+	m.wipChecking = false
+	m.selected = m.wipTarget.WorktreePath
+	m.selectedRepoPath = m.wipTarget.RepoPath
+	return m, tea.Quit
+}
+
+func (m Model) updateWIPMode(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case WIPFoundMsg:
+		if msg.Path != m.wipTarget.WorktreePath {
+			return m, nil
+		}
+		m.wipChecking = false
+		if msg.Err != nil {
+			logging.For("wip").Warn("looking for a snapshot failed (non-fatal)", "path", msg.Path, "err", msg.Err)
+		}
+		if !msg.Found {
+			return m.switchToWIPTarget()
+		}
+		m.showingWIP = true
+		m.wipSnapshot = msg.Snapshot
+		return m, nil
+
+	case WIPRestoredMsg:
+		m.wipRestoring = false
+		if msg.Err != nil {
+			m.wipErr = msg.Err
+			return m, nil
+		}
+		return m.switchToWIPTarget()
+
+	case tea.KeyMsg:
+		if key.Matches(msg, globalKeys.ForceQuit) {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		if !m.showingWIP || m.wipRestoring {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, wipKeys.Restore):
+			m.wipRestoring = true
+			m.wipErr = nil
+			return m, restoreWIPCmd(m.runner, m.wipTarget.WorktreePath, m.wipSnapshot)
+		case key.Matches(msg, wipKeys.Skip):
+			return m.switchToWIPTarget()
+		case key.Matches(msg, wipKeys.Cancel):
+			m.showingWIP = false
+			m.wipErr = nil
+			m.selectedPane = ""
+		}
+	}
+	return m, nil
+}
+
+func renderWIPView(m Model) string {
+	if m.wipRestoring {
+		return titleStyle.Render("Restore Work in Progress") + "\n\n  Restoring..."
+	}
+	how := "stash"
+	if m.wipSnapshot.Mode == wip.ModeCommit {
+		how = "wip: commit"
+	}
+	return modalLayout{
+		title: "Restore Work in Progress",
+		input: "Restore the changes saved when you left this worktree?",
+		notes: []string{"Saved as a " + how + " on " + m.wipSnapshot.Time.Local().Format("2006-01-02 15:04") + "."},
+		err:   m.wipErr,
+		help:  keyhelp.ShortHelp(keyhelp.Bindings(wipKeys)...),
+	}.render(m.width, m.height)
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/wip"
+)
+
+func TestSelectWorktree_OffersToRestoreWIP(t *testing.T) {
+	m := cursorOn(t, testModel().WithAutoWIP(wip.ModeCommit), "/code/repo1-feat")
+	m.runner = git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/repo1-feat:[log -1 --format=%H%x09%s]": "abc\twip: yakumo auto-save 2025-03-04T10:00:00Z\n",
+			"/code/repo1-feat:[reset abc~1]":              "",
+		},
+	}
+
+	m, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Selected() != "" || cmd == nil {
+		t.Fatal("enter should look for a snapshot before switching")
+	}
+	result, _ := m.Update(cmd())
+	m = result.(Model)
+	if !m.showingWIP || !strings.Contains(m.View(), "Restore the changes saved") {
+		t.Fatalf("a snapshot should be offered for restore:\n%s", m.View())
+	}
+
+	m, cmd = pressKeys(t, m, runeKey("y"))
+	result, cmd = m.Update(cmd())
+	m = result.(Model)
+	if m.Selected() != "/code/repo1-feat" || cmd == nil {
+		t.Errorf("restoring should switch to the worktree, selected %q", m.Selected())
+	}
+}
+
+func TestSelectWorktree_NoSnapshotSwitchesStraightAway(t *testing.T) {
+	m := cursorOn(t, testModel().WithAutoWIP(wip.ModeStash), "/code/repo1-feat")
+	m.runner = git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/code/repo1-feat:[log -1 --format=%H%x09%s]":     "abc\tadd feature\n",
+			"/code/repo1-feat:[stash list --format=%gd\t%gs]": "",
+		},
+	}
+
+	m, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	result, cmd := m.Update(cmd())
+	if result.(Model).Selected() != "/code/repo1-feat" || cmd == nil {
+		t.Error("without a snapshot the worktree should be selected")
+	}
+}
+
+func TestWIPPrompt_RestoreFailureKeepsPromptOpen(t *testing.T) {
+	m := testModel().WithAutoWIP(wip.ModeStash)
+	m.wipTarget = Selection{WorktreePath: "/code/repo1-feat", RepoPath: "/code/repo1"}
+	m.showingWIP = true
+	m.wipRestoring = true
+
+	result, _ := m.Update(WIPRestoredMsg{Err: errors.New("conflict in README")})
+	m = result.(Model)
+	if m.Selected() != "" || m.wipErr == nil || !m.showingWIP {
+		t.Fatal("a failed restore should be reported without switching")
+	}
+
+	m, _ = pressKeys(t, m, runeKey("n"))
+	if m.Selected() != "/code/repo1-feat" {
+		t.Error("n should switch without restoring")
+	}
+}
+
+func TestWIPPrompt_EscCancels(t *testing.T) {
+	m := testModel().WithAutoWIP(wip.ModeStash)
+	m.wipTarget = Selection{WorktreePath: "/code/repo1-feat", RepoPath: "/code/repo1"}
+	m.showingWIP = true
+
+	m, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEscape})
+	if m.showingWIP || m.Selected() != "" || cmd != nil {
+		t.Error("esc should return to the sidebar")
+	}
+}
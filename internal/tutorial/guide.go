@@ -0,0 +1,92 @@
+package tutorial
+
+import (
+	"fmt"
+
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// Step is how far the user has got through the tutorial.
+type Step int
+
+const (
+	StepCreate Step = iota
+	StepLaunch
+	StepArchive
+	StepDone
+)
+
+// Guide works out the current step from the worktrees in the sidebar and
+// gives the hint shown above the list for it.
+type Guide struct {
+	// Launched is set once the user has opened a worktree's session.
+	Launched bool
+}
+
+// Step returns the step the user is on: a worktree has to be created before
+// it is launched, and archived after.
+func (g Guide) Step(groups []model.RepoGroup) Step {
+	created := linkedWorktrees(groups) > 0
+	switch {
+	case !created && !g.Launched:
+		return StepCreate
+	case created && !g.Launched:
+		return StepLaunch
+	case created:
+		return StepArchive
+	default:
+		return StepDone
+	}
+}
+
+// Hint implements tui.Hinter.
+func (g Guide) Hint(groups []model.RepoGroup) string {
+	switch g.Step(groups) {
+	case StepCreate:
+		return fmt.Sprintf("Step 1/3: move to \"+ Add worktree\" and press enter, then pick %s from the branch list and press enter again.", Branches[0])
+	case StepLaunch:
+		return "Step 2/3: move to the new worktree and press enter to launch its tmux session."
+	case StepArchive:
+		return "Step 3/3: done with it? Press d on the worktree and enter to archive it."
+	default:
+		return "All done! Press q to leave the tutorial; the sandbox is deleted on the way out."
+	}
+}
+
+// linkedWorktrees counts the worktrees added next to the main checkouts.
+func linkedWorktrees(groups []model.RepoGroup) int {
+	n := 0
+	for _, g := range groups {
+		for _, wt := range g.Worktrees {
+			if wt.Path != g.RootPath && !wt.IsBare {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Layout explains the session yakumo made for the worktree, shown after the
+// user launches it in step 2. sessionName is empty outside tmux, where no
+// session is created.
+func Layout(sessionName string) string {
+	panes := `Every worktree gets its own tmux session laid out the same way:
+
+  ┌──────────────┬──────────────┐
+  │              │  diff-ui     │
+  │   claude     ├──────────────┤
+  │              │  dev server  │
+  └──────────────┴──────────────┘
+
+  center        Claude Code, started in the worktree
+  top right     yakumo diff-ui: changed files, PR checks and comments
+  bottom right  a shell for the dev server (dev_log captures its output)
+
+A background window keeps spare panes: yakumo swap-center and
+yakumo swap-right-below swap them into the center and bottom right.
+`
+	if sessionName == "" {
+		return panes + "\nRun the tutorial inside tmux to have the session created for real.\n"
+	}
+	return panes + fmt.Sprintf("\nThe session %q was created without switching to it; have a look with\n`tmux switch-client -t %s`, then come back to this window.\n", sessionName, sessionName)
+}
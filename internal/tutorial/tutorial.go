@@ -0,0 +1,90 @@
+// Package tutorial sets up `yakumo tutorial`: a throwaway repository with a
+// few branches to practice on, and the hints that walk the user through
+// creating a worktree, launching its session and archiving it.
+package tutorial
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+// RepoName is the name of the sandbox repository in the sidebar.
+const RepoName = "yakumo-tutorial"
+
+// Branches are created in the sandbox repository next to main, so there is
+// something to pick when adding a worktree.
+var Branches = []string{"feature/login", "fix/typo"}
+
+// Sandbox is the directory holding the tutorial repository, the worktrees
+// created from it and a config listing only that repository. Removing Dir
+// removes everything the tutorial made.
+type Sandbox struct {
+	Dir string
+}
+
+// RepoPath is where the sandbox repository is checked out.
+func (s Sandbox) RepoPath() string {
+	return filepath.Join(s.Dir, RepoName)
+}
+
+// ConfigPath is the config the tutorial runs the sidebar with, so the user's
+// own config is never touched.
+func (s Sandbox) ConfigPath() string {
+	return filepath.Join(s.Dir, "config.yaml")
+}
+
+// Config lists the sandbox repository and keeps its worktrees in the sandbox.
+func (s Sandbox) Config() model.Config {
+	return model.Config{
+		DefaultBaseRef:   "main",
+		WorktreeBasePath: filepath.Join(s.Dir, "worktrees"),
+		Repositories:     []model.RepositoryDef{{Name: RepoName, Path: s.RepoPath()}},
+	}
+}
+
+// author is passed to the commits of the sandbox so they don't depend on the
+// user's git identity being set up.
+var author = []string{"-c", "user.name=yakumo", "-c", "user.email=tutorial@yakumo.invalid"}
+
+// Setup creates the sandbox in dir: a repository on main with one commit,
+// the Branches and the config.
+func Setup(runner git.CommandRunner, dir string) (Sandbox, error) {
+	s := Sandbox{Dir: dir}
+	repo := s.RepoPath()
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		return Sandbox{}, fmt.Errorf("creating sandbox repository: %w", err)
+	}
+	readme := "# yakumo tutorial\n\nA throwaway repository. Everything here is deleted when the tutorial ends.\n"
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte(readme), 0o644); err != nil {
+		return Sandbox{}, fmt.Errorf("writing README: %w", err)
+	}
+
+	commands := [][]string{
+		{"init", "--initial-branch=main"},
+		{"add", "README.md"},
+		append(append([]string{}, author...), "commit", "-m", "Initial commit"),
+	}
+	for _, b := range Branches {
+		commands = append(commands, []string{"branch", b})
+	}
+	for _, args := range commands {
+		if _, err := runner.Run(repo, args...); err != nil {
+			return Sandbox{}, fmt.Errorf("setting up sandbox repository: %w", err)
+		}
+	}
+
+	data, err := yaml.Marshal(s.Config())
+	if err != nil {
+		return Sandbox{}, fmt.Errorf("marshaling config: %w", err)
+	}
+	if err := os.WriteFile(s.ConfigPath(), data, 0o644); err != nil {
+		return Sandbox{}, fmt.Errorf("writing config: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,81 @@
+package tutorial
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mikanfactory/yakumo/internal/config"
+	"github.com/mikanfactory/yakumo/internal/git"
+	"github.com/mikanfactory/yakumo/internal/model"
+)
+
+func TestSetup(t *testing.T) {
+	dir := t.TempDir()
+	repo := Sandbox{Dir: dir}.RepoPath()
+	runner := git.FakeCommandRunner{Outputs: map[string]string{
+		repo + ":[init --initial-branch=main]": "",
+		repo + ":[add README.md]":              "",
+		repo + ":[-c user.name=yakumo -c user.email=tutorial@yakumo.invalid commit -m Initial commit]": "",
+		repo + ":[branch feature/login]": "",
+		repo + ":[branch fix/typo]":      "",
+	}}
+
+	s, err := Setup(runner, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(s.RepoPath() + "/README.md"); err != nil {
+		t.Errorf("README not written: %v", err)
+	}
+	cfg, err := config.LoadFromFile(s.ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Repositories) != 1 || cfg.Repositories[0].Path != repo || !strings.HasPrefix(cfg.WorktreeBasePath, dir) {
+		t.Errorf("config = %+v, want only the sandbox repository with worktrees in the sandbox", cfg)
+	}
+}
+
+func TestSetup_GitError(t *testing.T) {
+	if _, err := Setup(git.FakeCommandRunner{}, t.TempDir()); err == nil {
+		t.Error("expected an error when git fails")
+	}
+}
+
+func TestGuide_Step(t *testing.T) {
+	main := model.WorktreeInfo{Path: "/s/repo", Branch: "main"}
+	linked := model.WorktreeInfo{Path: "/s/worktrees/login", Branch: "feature/login"}
+	groups := func(wts ...model.WorktreeInfo) []model.RepoGroup {
+		return []model.RepoGroup{{Name: RepoName, RootPath: "/s/repo", Worktrees: wts}}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		launched bool
+		groups   []model.RepoGroup
+		want     Step
+	}{
+		{"fresh sandbox", false, groups(main), StepCreate},
+		{"worktree created", false, groups(main, linked), StepLaunch},
+		{"session launched", true, groups(main, linked), StepArchive},
+		{"worktree archived", true, groups(main), StepDone},
+	} {
+		g := Guide{Launched: tt.launched}
+		if got := g.Step(tt.groups); got != tt.want {
+			t.Errorf("%s: Step = %d, want %d", tt.name, got, tt.want)
+		}
+		if g.Hint(tt.groups) == "" {
+			t.Errorf("%s: empty hint", tt.name)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	if got := Layout("login"); !strings.Contains(got, "tmux switch-client -t login") {
+		t.Errorf("Layout should say how to look at the session:\n%s", got)
+	}
+	if got := Layout(""); strings.Contains(got, "switch-client") {
+		t.Errorf("Layout outside tmux should not mention a session:\n%s", got)
+	}
+}
@@ -0,0 +1,132 @@
+// Package wip snapshots the uncommitted work of a worktree the user switches
+// away from, and restores it when they come back.
+package wip
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+)
+
+// Mode is how a snapshot is taken.
+type Mode string
+
+const (
+	ModeOff    Mode = ""       // snapshots are disabled
+	ModeStash  Mode = "stash"  // `git stash push --include-untracked`
+	ModeCommit Mode = "commit" // a `wip:` commit on the branch
+)
+
+// ParseMode validates an `auto_wip` value from the config. The empty string
+// selects ModeOff.
+func ParseMode(s string) (Mode, bool) {
+	switch Mode(s) {
+	case ModeOff, ModeStash, ModeCommit:
+		return Mode(s), true
+	}
+	return ModeOff, false
+}
+
+const (
+	stashPrefix   = "yakumo-wip "
+	commitPrefix  = "wip: yakumo auto-save "
+	timeLayout    = time.RFC3339
+	stashListForm = "%gd\t%gs"
+)
+
+// Snapshot is saved work waiting to be restored.
+type Snapshot struct {
+	Mode Mode
+	Ref  string // stash@{n}, or the commit hash
+	Time time.Time
+}
+
+// Save snapshots the uncommitted changes in dir, including untracked files.
+// It reports false, and does nothing, when dir is clean or mode is ModeOff.
+func Save(runner git.CommandRunner, dir string, mode Mode, now time.Time) (bool, error) {
+	if mode == ModeOff {
+		return false, nil
+	}
+	out, err := runner.Run(dir, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(out) == "" {
+		return false, nil
+	}
+
+	stamp := now.UTC().Format(timeLayout)
+	switch mode {
+	case ModeStash:
+		// The stash is shared by every worktree of the repository, so the
+		// message names the worktree.
+		_, err = runner.Run(dir, "stash", "push", "--include-untracked", "-m", stashPrefix+stamp+" "+dir)
+	case ModeCommit:
+		if _, err = runner.Run(dir, "add", "-A"); err == nil {
+			_, err = runner.Run(dir, "commit", "--no-verify", "-m", commitPrefix+stamp)
+		}
+	default:
+		return false, fmt.Errorf("unknown auto_wip mode %q", mode)
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Find returns the newest snapshot of dir: a `wip:` commit at HEAD, or else
+// a stash entry saved from dir.
+func Find(runner git.CommandRunner, dir string) (Snapshot, bool, error) {
+	head, err := runner.Run(dir, "log", "-1", "--format=%H%x09%s")
+	if err != nil {
+		return Snapshot{}, false, err
+	}
+	if hash, subject, ok := strings.Cut(strings.TrimSpace(head), "\t"); ok {
+		if stamp, ok := strings.CutPrefix(subject, commitPrefix); ok {
+			if t, err := time.Parse(timeLayout, stamp); err == nil {
+				return Snapshot{Mode: ModeCommit, Ref: hash, Time: t}, true, nil
+			}
+		}
+	}
+
+	out, err := runner.Run(dir, "stash", "list", "--format="+stashListForm)
+	if err != nil {
+		return Snapshot{}, false, err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		ref, subject, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		// "On <branch>: yakumo-wip <time> <dir>"
+		_, msg, ok := strings.Cut(subject, ": "+stashPrefix)
+		if !ok {
+			continue
+		}
+		stamp, path, ok := strings.Cut(msg, " ")
+		if !ok || path != dir {
+			continue
+		}
+		if t, err := time.Parse(timeLayout, stamp); err == nil {
+			return Snapshot{Mode: ModeStash, Ref: ref, Time: t}, true, nil
+		}
+	}
+	return Snapshot{}, false, nil
+}
+
+// Restore puts a snapshot's changes back into the working tree of dir. A
+// `wip:` commit is undone with a mixed reset, so its changes come back
+// unstaged; a stash entry is popped.
+func Restore(runner git.CommandRunner, dir string, s Snapshot) error {
+	switch s.Mode {
+	case ModeCommit:
+		_, err := runner.Run(dir, "reset", s.Ref+"~1")
+		return err
+	case ModeStash:
+		_, err := runner.Run(dir, "stash", "pop", s.Ref)
+		return err
+	}
+	return fmt.Errorf("unknown snapshot mode %q", s.Mode)
+}
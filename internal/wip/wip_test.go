@@ -0,0 +1,128 @@
+package wip
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikanfactory/yakumo/internal/git"
+)
+
+// repo returns a repository on branch main with one commit, and a dirty
+// tracked file plus an untracked one.
+func repo(t *testing.T) (string, git.OSCommandRunner) {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	dir := t.TempDir()
+	runner := git.OSCommandRunner{}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if _, err := runner.Run(dir, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	writeFile(t, dir, "README", "hello\n")
+	if _, err := runner.Run(dir, "add", "README"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Run(dir, "commit", "-q", "-m", "readme"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "README", "hello, edited\n")
+	writeFile(t, dir, "notes.txt", "todo\n")
+	return dir, runner
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func TestSaveFindRestore(t *testing.T) {
+	now := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	for _, mode := range []Mode{ModeStash, ModeCommit} {
+		t.Run(string(mode), func(t *testing.T) {
+			dir, runner := repo(t)
+
+			saved, err := Save(runner, dir, mode, now)
+			if err != nil || !saved {
+				t.Fatalf("Save = %v, %v", saved, err)
+			}
+			if status, _ := runner.Run(dir, "status", "--porcelain"); status != "" {
+				t.Fatalf("Save should leave a clean working tree, got %q", status)
+			}
+
+			snap, ok, err := Find(runner, dir)
+			if err != nil || !ok || snap.Mode != mode || !snap.Time.Equal(now) {
+				t.Fatalf("Find = %+v, %v, %v", snap, ok, err)
+			}
+
+			if err := Restore(runner, dir, snap); err != nil {
+				t.Fatalf("Restore: %v", err)
+			}
+			if readFile(t, dir, "notes.txt") != "todo\n" || readFile(t, dir, "README") != "hello, edited\n" {
+				t.Error("Restore should bring back tracked and untracked changes")
+			}
+			if _, ok, _ := Find(runner, dir); ok {
+				t.Error("a restored snapshot should be gone")
+			}
+		})
+	}
+}
+
+func TestSave_CleanOrOff(t *testing.T) {
+	dir, runner := repo(t)
+	if saved, err := Save(runner, dir, ModeOff, time.Now()); saved || err != nil {
+		t.Errorf("ModeOff: Save = %v, %v", saved, err)
+	}
+
+	if _, err := runner.Run(dir, "stash", "push", "-q", "--include-untracked"); err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := Save(runner, dir, ModeStash, time.Now()); saved || err != nil {
+		t.Errorf("clean tree: Save = %v, %v", saved, err)
+	}
+}
+
+func TestFind_IgnoresOtherWorktreesStashes(t *testing.T) {
+	runner := git.FakeCommandRunner{
+		Outputs: map[string]string{
+			"/wt/a:[log -1 --format=%H%x09%s]": "abc\treadme\n",
+			"/wt/a:[stash list --format=%gd\t%gs]": "stash@{0}\tOn b: yakumo-wip 2025-03-04T10:00:00Z /wt/b\n" +
+				"stash@{1}\tOn a: hand-made stash\n" +
+				"stash@{2}\tOn a: yakumo-wip 2025-03-03T09:00:00Z /wt/a\n",
+		},
+	}
+
+	snap, ok, err := Find(runner, "/wt/a")
+	if err != nil || !ok || snap.Ref != "stash@{2}" {
+		t.Errorf("Find = %+v, %v, %v; want the stash saved from /wt/a", snap, ok, err)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]bool{"": true, "stash": true, "commit": true, "always": false} {
+		if _, ok := ParseMode(in); ok != want {
+			t.Errorf("ParseMode(%q) ok = %v, want %v", in, ok, want)
+		}
+	}
+}